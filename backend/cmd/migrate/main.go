@@ -31,49 +31,108 @@ func main() {
 	}
 	defer db.Close()
 
-	// Read migration SQL
-	migrationPath := filepath.Join("backend", "migrations", "002_add_options_columns.sql")
-	sqlBytes, err := os.ReadFile(migrationPath)
+	// Applied migrations are recorded in schema_migrations and skipped on
+	// later runs. A database migrated before the table existed has every
+	// migration applied once more (each one is safe to re-run) and recorded.
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		log.Fatalf("Failed to create schema_migrations table: %v", err)
+	}
+	applied, err := appliedMigrations(db)
 	if err != nil {
-		log.Fatalf("Failed to read migration file: %v", err)
+		log.Fatalf("Failed to read applied migrations: %v", err)
 	}
 
-	migrationSQL := string(sqlBytes)
+	// Migrations are applied in order
+	migrationFiles := []string{
+		"002_add_options_columns.sql",
+		"003_add_price_bars.sql",
+		"004_add_position_lots.sql",
+		"005_add_lot_accounting.sql",
+		"006_add_direction.sql",
+		"007_add_position_exit_tracking.sql",
+		"008_add_option_rolls.sql",
+		"009_add_option_conversions.sql",
+		"010_add_initial_risk.sql",
+		"011_add_excursions.sql",
+		"012_add_broker_fills.sql",
+		"013_add_fill_option_legs.sql",
+		"014_add_lot_initial_risk.sql",
+		"015_add_pending_broker_fills.sql",
+	}
 
-	// Split SQL into individual statements
-	statements := strings.Split(migrationSQL, ";")
-	
-	// Execute each statement individually, ignoring "duplicate column" errors
 	log.Println("Executing migration...")
 	successCount := 0
 	skippedCount := 0
-	
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" || strings.HasPrefix(stmt, "--") {
+	appliedCount := 0
+
+	for _, name := range migrationFiles {
+		if applied[name] {
 			continue
 		}
-		
-		_, err := db.Exec(stmt)
+
+		migrationPath := filepath.Join("backend", "migrations", name)
+		sqlBytes, err := os.ReadFile(migrationPath)
+		if err != nil {
+			log.Fatalf("Failed to read migration file: %v", err)
+		}
+
+		log.Printf("Applying %s", name)
+
+		// A migration and its schema_migrations row are committed together
+		tx, err := db.Begin()
 		if err != nil {
-			// Check if it's a "duplicate column" error - that's OK, column already exists
-			errStr := strings.ToLower(err.Error())
-			if strings.Contains(errStr, "duplicate column") || 
-			   strings.Contains(errStr, "already exists") {
-				// Extract column name from statement for logging
-				columnName := extractColumnName(stmt)
-				log.Printf("  ✓ Column %s already exists, skipping", columnName)
-				skippedCount++
+			log.Fatalf("Failed to begin transaction: %v", err)
+		}
+
+		// Split SQL into individual statements, after dropping comment lines
+		// so a ";" in a comment does not end a statement
+		statements := strings.Split(stripSQLComments(string(sqlBytes)), ";")
+
+		// Execute each statement individually, ignoring "duplicate column" errors
+		for _, stmt := range statements {
+			stmt = stripSQLComments(stmt)
+			if stmt == "" {
 				continue
 			}
-			// Other errors are real problems
-			log.Printf("Error executing statement: %s", stmt)
-			log.Fatalf("Migration failed: %v", err)
+
+			_, err := tx.Exec(stmt)
+			if err != nil {
+				// Check if it's a "duplicate column" error - that's OK, column already exists
+				errStr := strings.ToLower(err.Error())
+				if strings.Contains(errStr, "duplicate column") ||
+					strings.Contains(errStr, "already exists") {
+					// Extract column name from statement for logging
+					columnName := extractColumnName(stmt)
+					log.Printf("  ✓ Column %s already exists, skipping", columnName)
+					skippedCount++
+					continue
+				}
+				// Other errors are real problems
+				log.Printf("Error executing statement: %s", stmt)
+				log.Fatalf("Migration failed: %v", err)
+			}
+			successCount++
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (name) VALUES (?)`, name); err != nil {
+			log.Fatalf("Failed to record migration %s: %v", name, err)
 		}
-		successCount++
+		if err := tx.Commit(); err != nil {
+			log.Fatalf("Failed to commit migration %s: %v", name, err)
+		}
+		appliedCount++
+	}
+
+	if appliedCount == 0 {
+		log.Println("✅ Database is up to date, no migrations to apply")
+		return
 	}
 
 	log.Println("✅ Migration completed successfully!")
+	log.Printf("  - Applied %d migrations", appliedCount)
 	log.Printf("  - Added %d columns", successCount)
 	if skippedCount > 0 {
 		log.Printf("  - Skipped %d columns (already exist)", skippedCount)
@@ -85,10 +144,45 @@ func main() {
 	log.Println("  - Multi-leg options (legs_json)")
 	log.Println("  - Pyramid add-on tracking (max_units, add_step_n, add_price_1/2/3)")
 	log.Println("  - Breakout system selection (entry_lookback, exit_lookback)")
+	log.Println("  - Stored daily price bars for automatic ATR (N)")
 	log.Println("")
 	log.Println("You can now create trade sessions with options!")
 }
 
+// appliedMigrations lists the migrations recorded in schema_migrations
+func appliedMigrations(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT name FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
+
+// stripSQLComments removes full-line "--" comments so a statement that follows
+// a comment header is still executed (and a trailing comment left after a
+// ";" is not executed on its own)
+func stripSQLComments(stmt string) string {
+	lines := strings.Split(stmt, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
 // extractColumnName tries to extract the column name from an ALTER TABLE statement
 func extractColumnName(stmt string) string {
 	// Look for "ADD COLUMN <name>" pattern
@@ -47,20 +47,41 @@ func ServerCommand() {
 	heatHandler := handlers.NewHeatHandler(db, logger)
 	decisionsHandler := handlers.NewDecisionHandler(db, logger)
 	calendarHandler := handlers.NewCalendarHandler(db, logger)
+	signalsHandler := handlers.NewSignalsHandler(db, logger)
+	riskGraphHandler := handlers.NewRiskGraphHandler(db, logger)
+	equityHandler := handlers.NewEquityHandler(db, logger)
+	statsHandler := handlers.NewStatsHandler(db, logger)
+	excursionsHandler := handlers.NewExcursionsHandler(db, logger)
+	fillsHandler := handlers.NewFillsHandler(db, logger)
 
 	// Create router
 	mux := http.NewServeMux()
 
 	// API routes
 	mux.HandleFunc("/api/settings", settingsHandler.GetSettings)
+	mux.HandleFunc("/api/equity", equityHandler.GetEquity)
+	mux.HandleFunc("/api/equity/curve", equityHandler.GetEquityCurve)
 	mux.HandleFunc("/api/positions", positionsHandler.GetPositions)
+	mux.HandleFunc("/api/positions/lots", positionsHandler.GetLots)
+	mux.HandleFunc("/api/positions/add-unit", positionsHandler.AddUnit)
+	mux.HandleFunc("/api/positions/close", positionsHandler.ClosePosition)
 	mux.HandleFunc("/api/candidates", candidatesHandler.GetCandidates)
 	mux.HandleFunc("/api/candidates/scan", candidatesHandler.ScanCandidates)
 	mux.HandleFunc("/api/candidates/import", candidatesHandler.ImportCandidates)
 	mux.HandleFunc("/api/sizing/calculate", sizingHandler.CalculateSize)
+	mux.HandleFunc("/api/prices/atr", sizingHandler.GetATR)
 	mux.HandleFunc("/api/heat/check", heatHandler.CheckHeat)
 	mux.HandleFunc("/api/decisions/save", decisionsHandler.SaveDecision)
 	mux.HandleFunc("/api/calendar", calendarHandler.GetCalendar)
+	mux.HandleFunc("/api/signals", signalsHandler.GetSignals)
+	mux.HandleFunc("/api/riskgraph", riskGraphHandler.GetRiskGraph)
+	mux.HandleFunc("/api/stats", statsHandler.GetStats)
+	mux.HandleFunc("/api/excursions", excursionsHandler.GetExcursions)
+	mux.HandleFunc("/api/excursions/mark", excursionsHandler.MarkPrice)
+	mux.HandleFunc("/api/excursions/scatter", excursionsHandler.GetScatter)
+	mux.HandleFunc("/api/fills", fillsHandler.GetFills)
+	mux.HandleFunc("/api/fills/import", fillsHandler.ImportFills)
+	mux.HandleFunc("/api/fills/ignore", fillsHandler.IgnoreFill)
 
 	// Serve embedded Svelte UI
 	sfs, err := webui.Sub()
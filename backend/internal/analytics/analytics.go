@@ -0,0 +1,276 @@
+// Package analytics builds the R-multiple trade journal, the account equity curve
+// and the MAE/MFE excursion statistics of closed trades
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// Unassigned is the group of trades without a bucket or breakout system
+const Unassigned = "(none)"
+
+// Trade is one closed trade measured in R
+//
+// A rolled option position and every replacement down to the one that was
+// finally closed are a single trade: P&L is summed along the chain and R is
+// measured against the first position's initial risk.
+type Trade struct {
+	PositionID     int     `json:"position_id"` // Position that was finally closed
+	Ticker         string  `json:"ticker"`
+	Direction      string  `json:"direction"`
+	Strategy       string  `json:"strategy"` // LONG_BREAKOUT, SHORT_BREAKOUT or the options strategy
+	Bucket         string  `json:"bucket,omitempty"`
+	BreakoutSystem string  `json:"breakout_system,omitempty"`
+	InstrumentType string  `json:"instrument_type"`
+	EntryDate      string  `json:"entry_date"`
+	ExitDate       string  `json:"exit_date"`
+	InitialRisk    float64 `json:"initial_risk"` // 1R in dollars (0 = not recorded)
+	PnL            float64 `json:"pnl"`
+	RMultiple      float64 `json:"r_multiple"`
+	Rated          bool    `json:"rated"` // Has a 1R, so RMultiple is meaningful
+	Outcome        string  `json:"outcome"`
+	Rolls          int     `json:"rolls,omitempty"`
+}
+
+// Stats summarizes a set of trades
+//
+// R statistics cover the rated trades only; counts, win rate and profit
+// factor cover every trade.
+type Stats struct {
+	Trades        int     `json:"trades"`
+	Rated         int     `json:"rated"`
+	Wins          int     `json:"wins"`
+	Losses        int     `json:"losses"`
+	Scratches     int     `json:"scratches"`
+	WinRatePct    float64 `json:"win_rate_pct"`
+	NetPnL        float64 `json:"net_pnl"`
+	TotalR        float64 `json:"total_r"`
+	ExpectancyR   float64 `json:"expectancy_r"` // Mean R per trade
+	AvgWinR       float64 `json:"avg_win_r"`
+	AvgLossR      float64 `json:"avg_loss_r"` // Negative
+	LargestWinR   float64 `json:"largest_win_r"`
+	LargestLossR  float64 `json:"largest_loss_r"`
+	StdDevR       float64 `json:"std_dev_r"`
+	SQN           float64 `json:"sqn"`           // √N × expectancy / std dev of R
+	ProfitFactor  float64 `json:"profit_factor"` // Gross profit / gross loss (0 = no losses)
+	MaxWinStreak  int     `json:"max_win_streak"`
+	MaxLossStreak int     `json:"max_loss_streak"`
+}
+
+// Report is the journal with its statistics overall and per group
+type Report struct {
+	From         string           `json:"from,omitempty"` // Exit date range (inclusive)
+	To           string           `json:"to,omitempty"`
+	Overall      Stats            `json:"overall"`
+	ByStrategy   map[string]Stats `json:"by_strategy"`
+	ByBucket     map[string]Stats `json:"by_bucket"`
+	BySystem     map[string]Stats `json:"by_system"`
+	ByInstrument map[string]Stats `json:"by_instrument"`
+	Trades       []Trade          `json:"trades"`
+}
+
+// LoadJournal reads every closed trade from the positions table
+func LoadJournal(db *storage.DB) ([]Trade, error) {
+	positions, err := db.GetAllPositions("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load positions: %w", err)
+	}
+	return BuildJournal(positions), nil
+}
+
+// BuildJournal turns closed positions into trades, oldest exit first
+// Open positions, and rolled positions whose chain is still open, are left out.
+func BuildJournal(positions []storage.Position) []Trade {
+	byID := make(map[int]*storage.Position, len(positions))
+	for i := range positions {
+		byID[positions[i].ID] = &positions[i]
+	}
+
+	trades := []Trade{}
+	for i := range positions {
+		last := &positions[i]
+		if last.Status != "CLOSED" {
+			continue
+		}
+
+		first, pnl, rolls := last, last.PnL, 0
+		for first.RolledFromID != 0 {
+			prev, ok := byID[first.RolledFromID]
+			if !ok {
+				break
+			}
+			first = prev
+			pnl += prev.PnL
+			rolls++
+		}
+
+		t := Trade{
+			PositionID:     last.ID,
+			Ticker:         last.Ticker,
+			Direction:      last.Direction,
+			Strategy:       tradeStrategy(first),
+			Bucket:         first.Bucket,
+			BreakoutSystem: first.BreakoutSystem,
+			InstrumentType: first.InstrumentType,
+			EntryDate:      first.TradeDate(),
+			ExitDate:       last.ExitDate,
+			InitialRisk:    first.InitialRisk,
+			PnL:            pnl,
+			Outcome:        storage.OutcomeForPnL(pnl),
+			Rolls:          rolls,
+		}
+		if t.InstrumentType == "" {
+			t.InstrumentType = storage.InstrumentStock
+		}
+		if t.InitialRisk > 0 {
+			t.RMultiple = pnl / t.InitialRisk
+			t.Rated = true
+		}
+		trades = append(trades, t)
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool {
+		if trades[i].ExitDate != trades[j].ExitDate {
+			return trades[i].ExitDate < trades[j].ExitDate
+		}
+		return trades[i].PositionID < trades[j].PositionID
+	})
+	return trades
+}
+
+// tradeStrategy is the options strategy of an option position, otherwise
+// the breakout direction
+func tradeStrategy(p *storage.Position) string {
+	if p.InstrumentType == storage.InstrumentOption && p.OptionsStrategy != "" {
+		return p.OptionsStrategy
+	}
+	if p.Direction == storage.DirectionShort {
+		return storage.StrategyShortBreakout
+	}
+	return storage.StrategyLongBreakout
+}
+
+// FilterTrades keeps the trades that exited between from and to
+// (YYYY-MM-DD, inclusive; empty = open-ended)
+func FilterTrades(trades []Trade, from, to string) []Trade {
+	kept := []Trade{}
+	for _, t := range trades {
+		if (from == "" || t.ExitDate >= from) && (to == "" || t.ExitDate <= to) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// BuildReport computes the statistics of trades overall and per strategy,
+// bucket, breakout system and instrument type
+func BuildReport(trades []Trade) *Report {
+	report := &Report{
+		Overall:      Compute(trades),
+		ByStrategy:   groupStats(trades, func(t Trade) string { return t.Strategy }),
+		ByBucket:     groupStats(trades, func(t Trade) string { return t.Bucket }),
+		BySystem:     groupStats(trades, func(t Trade) string { return t.BreakoutSystem }),
+		ByInstrument: groupStats(trades, func(t Trade) string { return t.InstrumentType }),
+		Trades:       trades,
+	}
+	return report
+}
+
+// groupStats computes the statistics of each group of trades
+func groupStats(trades []Trade, key func(Trade) string) map[string]Stats {
+	groups := map[string][]Trade{}
+	for _, t := range trades {
+		k := key(t)
+		if k == "" {
+			k = Unassigned
+		}
+		groups[k] = append(groups[k], t)
+	}
+	stats := make(map[string]Stats, len(groups))
+	for k, g := range groups {
+		stats[k] = Compute(g)
+	}
+	return stats
+}
+
+// Compute summarizes trades given in exit order (streaks follow that order)
+//
+// A trade wins or loses by the sign of its P&L; a scratch ends both streaks.
+// SQN is Van Tharp's system quality number over the rated trades:
+//
+//	SQN = √N × mean(R) / stddev(R)
+func Compute(trades []Trade) Stats {
+	s := Stats{Trades: len(trades)}
+	if len(trades) == 0 {
+		return s
+	}
+
+	var grossProfit, grossLoss, winR, lossR float64
+	var ratedWins, ratedLosses, winStreak, lossStreak int
+	var rs []float64
+	for _, t := range trades {
+		s.NetPnL += t.PnL
+		switch {
+		case t.PnL > 0:
+			s.Wins++
+			grossProfit += t.PnL
+			winStreak, lossStreak = winStreak+1, 0
+		case t.PnL < 0:
+			s.Losses++
+			grossLoss -= t.PnL
+			winStreak, lossStreak = 0, lossStreak+1
+		default:
+			s.Scratches++
+			winStreak, lossStreak = 0, 0
+		}
+		s.MaxWinStreak = max(s.MaxWinStreak, winStreak)
+		s.MaxLossStreak = max(s.MaxLossStreak, lossStreak)
+
+		if !t.Rated {
+			continue
+		}
+		rs = append(rs, t.RMultiple)
+		s.TotalR += t.RMultiple
+		if t.PnL > 0 {
+			ratedWins++
+			winR += t.RMultiple
+			s.LargestWinR = math.Max(s.LargestWinR, t.RMultiple)
+		} else if t.PnL < 0 {
+			ratedLosses++
+			lossR += t.RMultiple
+			s.LargestLossR = math.Min(s.LargestLossR, t.RMultiple)
+		}
+	}
+
+	s.WinRatePct = float64(s.Wins) / float64(s.Trades) * 100
+	if grossLoss > 0 {
+		s.ProfitFactor = grossProfit / grossLoss
+	}
+	if ratedWins > 0 {
+		s.AvgWinR = winR / float64(ratedWins)
+	}
+	if ratedLosses > 0 {
+		s.AvgLossR = lossR / float64(ratedLosses)
+	}
+
+	s.Rated = len(rs)
+	if s.Rated == 0 {
+		return s
+	}
+	s.ExpectancyR = s.TotalR / float64(s.Rated)
+	if s.Rated > 1 {
+		var sumSq float64
+		for _, r := range rs {
+			sumSq += (r - s.ExpectancyR) * (r - s.ExpectancyR)
+		}
+		s.StdDevR = math.Sqrt(sumSq / float64(s.Rated-1))
+	}
+	if s.StdDevR > 0 {
+		s.SQN = math.Sqrt(float64(s.Rated)) * s.ExpectancyR / s.StdDevR
+	}
+	return s
+}
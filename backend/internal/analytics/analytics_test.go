@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// rated is a 1R = $100 trade closing at r
+func rated(exitDate string, r float64) Trade {
+	return Trade{ExitDate: exitDate, InitialRisk: 100, PnL: r * 100, RMultiple: r, Rated: true}
+}
+
+func TestCompute(t *testing.T) {
+	trades := []Trade{
+		rated("2025-01-10", 2),
+		rated("2025-01-20", -1),
+		rated("2025-02-03", -1),
+		rated("2025-02-10", 3),
+		rated("2025-03-01", 0),
+	}
+
+	s := Compute(trades)
+
+	assert.Equal(t, 5, s.Trades)
+	assert.Equal(t, 5, s.Rated)
+	assert.Equal(t, 2, s.Wins)
+	assert.Equal(t, 2, s.Losses)
+	assert.Equal(t, 1, s.Scratches)
+	assert.InDelta(t, 40, s.WinRatePct, 1e-9)
+	assert.InDelta(t, 300, s.NetPnL, 1e-9)
+	assert.InDelta(t, 3, s.TotalR, 1e-9)
+	assert.InDelta(t, 0.6, s.ExpectancyR, 1e-9)
+	assert.InDelta(t, 2.5, s.AvgWinR, 1e-9)
+	assert.InDelta(t, -1, s.AvgLossR, 1e-9)
+	assert.InDelta(t, 3, s.LargestWinR, 1e-9)
+	assert.InDelta(t, -1, s.LargestLossR, 1e-9)
+	assert.InDelta(t, 2.5, s.ProfitFactor, 1e-9)
+	assert.InDelta(t, math.Sqrt(3.3), s.StdDevR, 1e-9)
+	assert.InDelta(t, math.Sqrt(5)*0.6/math.Sqrt(3.3), s.SQN, 1e-9)
+	assert.Equal(t, 1, s.MaxWinStreak)
+	assert.Equal(t, 2, s.MaxLossStreak)
+}
+
+func TestCompute_UnratedTradesCountButHaveNoR(t *testing.T) {
+	trades := []Trade{
+		rated("2025-01-10", 2),
+		{ExitDate: "2025-01-20", PnL: -50},
+	}
+
+	s := Compute(trades)
+
+	assert.Equal(t, 2, s.Trades)
+	assert.Equal(t, 1, s.Rated)
+	assert.Equal(t, 1, s.Losses)
+	assert.InDelta(t, 2, s.ExpectancyR, 1e-9)
+	assert.Zero(t, s.AvgLossR)
+	assert.Zero(t, s.SQN) // One rated trade has no spread
+}
+
+func TestCompute_Empty(t *testing.T) {
+	assert.Equal(t, Stats{}, Compute(nil))
+}
+
+func TestBuildJournal(t *testing.T) {
+	positions := []storage.Position{
+		// Stock trade: 1R $150, closed for +$300
+		{ID: 1, Ticker: "AAPL", Direction: "LONG", Status: "CLOSED", Bucket: "Tech/Comm",
+			BreakoutSystem: storage.SystemOne, EntryDate: "2025-01-02", ExitDate: "2025-01-20",
+			InitialRisk: 150, PnL: 300},
+		// Option rolled once: -$80 on the first leg, +$280 on the replacement
+		{ID: 2, Ticker: "SPY", Direction: "LONG", Status: storage.StatusRolled, InstrumentType: storage.InstrumentOption,
+			OptionsStrategy: "BULL_CALL_SPREAD", EntryDate: "2025-01-05", ExitDate: "2025-02-01",
+			InitialRisk: 400, PnL: -80, RolledToID: 3},
+		{ID: 3, Ticker: "SPY", Direction: "LONG", Status: "CLOSED", InstrumentType: storage.InstrumentOption,
+			OptionsStrategy: "BULL_CALL_SPREAD", EntryDate: "2025-02-01", ExitDate: "2025-01-15",
+			InitialRisk: 250, PnL: 280, RolledFromID: 2},
+		// Still open, and a short with no recorded 1R
+		{ID: 4, Ticker: "MSFT", Direction: "LONG", Status: "OPEN", InitialRisk: 100},
+		{ID: 5, Ticker: "XOM", Direction: "SHORT", Status: "CLOSED", EntryDate: "2025-01-03", ExitDate: "2025-01-25", PnL: -60},
+	}
+
+	trades := BuildJournal(positions)
+	require.Len(t, trades, 3)
+
+	spy := trades[0]
+	assert.Equal(t, 3, spy.PositionID)
+	assert.Equal(t, "BULL_CALL_SPREAD", spy.Strategy)
+	assert.Equal(t, storage.InstrumentOption, spy.InstrumentType)
+	assert.Equal(t, "2025-01-05", spy.EntryDate)
+	assert.Equal(t, 1, spy.Rolls)
+	assert.InDelta(t, 200, spy.PnL, 1e-9)
+	assert.InDelta(t, 0.5, spy.RMultiple, 1e-9) // Against the first leg's $400
+
+	aapl := trades[1]
+	assert.Equal(t, storage.StrategyLongBreakout, aapl.Strategy)
+	assert.Equal(t, storage.InstrumentStock, aapl.InstrumentType)
+	assert.InDelta(t, 2, aapl.RMultiple, 1e-9)
+	assert.Equal(t, storage.OutcomeWin, aapl.Outcome)
+
+	xom := trades[2]
+	assert.Equal(t, storage.StrategyShortBreakout, xom.Strategy)
+	assert.False(t, xom.Rated)
+	assert.Equal(t, storage.OutcomeLoss, xom.Outcome)
+}
+
+func TestBuildReport(t *testing.T) {
+	trades := []Trade{
+		{Strategy: "LONG_BREAKOUT", Bucket: "Tech/Comm", BreakoutSystem: "SYSTEM_1", InstrumentType: "STOCK",
+			ExitDate: "2025-01-10", InitialRisk: 100, PnL: 200, RMultiple: 2, Rated: true},
+		{Strategy: "LONG_BREAKOUT", Bucket: "Energy", InstrumentType: "STOCK",
+			ExitDate: "2025-02-10", InitialRisk: 100, PnL: -100, RMultiple: -1, Rated: true},
+		{Strategy: "IRON_CONDOR", Bucket: "Tech/Comm", BreakoutSystem: "SYSTEM_2", InstrumentType: "OPTION",
+			ExitDate: "2025-03-10", InitialRisk: 300, PnL: 150, RMultiple: 0.5, Rated: true},
+	}
+
+	report := BuildReport(trades)
+
+	assert.Equal(t, 3, report.Overall.Trades)
+	assert.Equal(t, 2, report.ByStrategy["LONG_BREAKOUT"].Trades)
+	assert.Equal(t, 2, report.ByBucket["Tech/Comm"].Trades)
+	assert.InDelta(t, 1.25, report.ByBucket["Tech/Comm"].ExpectancyR, 1e-9)
+	assert.Equal(t, 1, report.BySystem[Unassigned].Trades)
+	assert.Equal(t, 1, report.ByInstrument["OPTION"].Wins)
+
+	filtered := FilterTrades(trades, "2025-02-01", "2025-02-28")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Energy", filtered[0].Bucket)
+}
@@ -0,0 +1,348 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+const dateLayout = "2006-01-02"
+
+// EquityDay is the account at the end of one calendar day
+type EquityDay struct {
+	Date        string  `json:"date"`
+	Realized    float64 `json:"realized"`   // Cumulative realized P&L
+	Unrealized  float64 `json:"unrealized"` // Open positions marked to market (0 without marks)
+	Equity      float64 `json:"equity"`
+	Peak        float64 `json:"peak"`
+	Drawdown    float64 `json:"drawdown"`
+	DrawdownPct float64 `json:"drawdown_pct"` // Percent below the peak
+}
+
+// PeriodReturn is the account's return over a month (YYYY-MM) or year (YYYY)
+type PeriodReturn struct {
+	Period      string  `json:"period"`
+	StartEquity float64 `json:"start_equity"`
+	EndEquity   float64 `json:"end_equity"`
+	PnL         float64 `json:"pnl"`
+	ReturnPct   float64 `json:"return_pct"`
+}
+
+// EquityCurveRequest describes the account to chart
+type EquityCurveRequest struct {
+	Starting   float64            `json:"starting"`             // Equity at inception
+	Start      string             `json:"start"`                // Inception date (YYYY-MM-DD)
+	End        string             `json:"end"`                  // Last date charted
+	Realized   []storage.DailyPnL `json:"realized"`             // Realized P&L by exit date
+	Unrealized map[string]float64 `json:"unrealized,omitempty"` // Date -> open P&L (nil = realized only)
+}
+
+// EquityCurve is the daily equity series with its drawdown and return statistics
+type EquityCurve struct {
+	Starting       float64 `json:"starting"`
+	Start          string  `json:"start"`
+	End            string  `json:"end"`
+	Final          float64 `json:"final"`
+	TotalReturnPct float64 `json:"total_return_pct"`
+	MarkToMarket   bool    `json:"mark_to_market"`
+
+	MaxDrawdown       float64 `json:"max_drawdown"`
+	MaxDrawdownPct    float64 `json:"max_drawdown_pct"`
+	MaxDrawdownPeak   string  `json:"max_drawdown_peak,omitempty"`   // Date of the peak it fell from
+	MaxDrawdownTrough string  `json:"max_drawdown_trough,omitempty"` // Date of the low
+
+	LongestDrawdownDays  int    `json:"longest_drawdown_days"` // Calendar days from a peak to its recovery
+	LongestDrawdownStart string `json:"longest_drawdown_start,omitempty"`
+	LongestDrawdownEnd   string `json:"longest_drawdown_end,omitempty"` // Empty = not recovered yet
+	CurrentDrawdownDays  int    `json:"current_drawdown_days"`
+
+	Daily   []EquityDay       `json:"daily"`
+	Monthly []PeriodReturn    `json:"monthly"`
+	Yearly  []PeriodReturn    `json:"yearly"`
+	Missing map[string]string `json:"missing,omitempty"` // Open positions that could not be marked
+}
+
+// BuildEquityCurve builds the daily equity series from Start to End
+//
+// Each day's equity is Starting plus the realized P&L booked up to that day
+// plus that day's Unrealized mark. Realized P&L dated before Start is
+// counted on Start; after End it is ignored.
+func BuildEquityCurve(req EquityCurveRequest) (*EquityCurve, error) {
+	if req.Starting <= 0 {
+		return nil, fmt.Errorf("starting equity must be positive, got %.2f", req.Starting)
+	}
+	start, err := time.Parse(dateLayout, req.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q: %w", req.Start, err)
+	}
+	end, err := time.Parse(dateLayout, req.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date %q: %w", req.End, err)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", req.End, req.Start)
+	}
+
+	booked := map[string]float64{}
+	for _, d := range req.Realized {
+		date := d.Date
+		if date < req.Start {
+			date = req.Start
+		}
+		booked[date] += d.PnL
+	}
+
+	curve := &EquityCurve{
+		Starting:     req.Starting,
+		Start:        req.Start,
+		End:          req.End,
+		MarkToMarket: req.Unrealized != nil,
+		Daily:        []EquityDay{},
+	}
+
+	realized, peak := 0.0, req.Starting
+	peakDate, underSince := req.Start, ""
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		realized += booked[date]
+		point := EquityDay{Date: date, Realized: realized, Unrealized: req.Unrealized[date]}
+		point.Equity = req.Starting + point.Realized + point.Unrealized
+
+		if point.Equity > peak || (point.Equity == peak && underSince != "") {
+			// A drawdown is dated from the day the peak was first set,
+			// so flat days at the high do not move it
+			if underSince != "" {
+				curve.recordDrawdownPeriod(underSince, date, true)
+				underSince = ""
+			}
+			peak, peakDate = point.Equity, date
+		} else if point.Equity < peak && underSince == "" {
+			underSince = peakDate
+		}
+		point.Peak = peak
+		point.Drawdown = peak - point.Equity
+		point.DrawdownPct = point.Drawdown / peak * 100
+
+		if point.Drawdown > curve.MaxDrawdown {
+			curve.MaxDrawdown = point.Drawdown
+			curve.MaxDrawdownPeak = peakDate
+			curve.MaxDrawdownTrough = date
+		}
+		curve.MaxDrawdownPct = math.Max(curve.MaxDrawdownPct, point.DrawdownPct)
+		curve.Daily = append(curve.Daily, point)
+	}
+	if underSince != "" {
+		curve.CurrentDrawdownDays = daysBetween(underSince, req.End)
+		curve.recordDrawdownPeriod(underSince, req.End, false)
+	}
+
+	curve.Final = curve.Daily[len(curve.Daily)-1].Equity
+	curve.TotalReturnPct = (curve.Final/req.Starting - 1) * 100
+	curve.Monthly = periodReturns(req.Starting, curve.Daily, 7)
+	curve.Yearly = periodReturns(req.Starting, curve.Daily, 4)
+	return curve, nil
+}
+
+// recordDrawdownPeriod keeps the longest time spent below a peak
+func (c *EquityCurve) recordDrawdownPeriod(peakDate, until string, recovered bool) {
+	days := daysBetween(peakDate, until)
+	if days <= c.LongestDrawdownDays {
+		return
+	}
+	c.LongestDrawdownDays = days
+	c.LongestDrawdownStart = peakDate
+	c.LongestDrawdownEnd = ""
+	if recovered {
+		c.LongestDrawdownEnd = until
+	}
+}
+
+// daysBetween counts the calendar days from one date to another
+func daysBetween(from, to string) int {
+	a, _ := time.Parse(dateLayout, from)
+	b, _ := time.Parse(dateLayout, to)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// periodReturns groups the daily series by the first prefix characters of
+// the date (7 = month, 4 = year); each period starts from the previous
+// period's closing equity
+func periodReturns(starting float64, daily []EquityDay, prefix int) []PeriodReturn {
+	periods := []PeriodReturn{}
+	open := starting
+	for i, d := range daily {
+		last := i == len(daily)-1 || daily[i+1].Date[:prefix] != d.Date[:prefix]
+		if !last {
+			continue
+		}
+		periods = append(periods, PeriodReturn{
+			Period:      d.Date[:prefix],
+			StartEquity: open,
+			EndEquity:   d.Equity,
+			PnL:         d.Equity - open,
+			ReturnPct:   (d.Equity/open - 1) * 100,
+		})
+		open = d.Equity
+	}
+	return periods
+}
+
+// MarkOpenPositions values the open stock positions at each day's close
+// from Start to End
+//
+// A position is marked from its entry date at the last close on or before
+// each day (weekends and holidays carry the previous close). Option
+// positions, and tickers without bars, are left at cost and listed in the
+// returned map with the reason.
+func MarkOpenPositions(positions []storage.Position, provider domain.BarProvider, start, end string) (map[string]float64, map[string]string) {
+	marks := map[string]float64{}
+	missing := map[string]string{}
+
+	for _, p := range positions {
+		if p.Status != "OPEN" || p.Shares == 0 {
+			continue
+		}
+		if p.InstrumentType == storage.InstrumentOption {
+			missing[p.Ticker] = "option positions count at realized P&L only"
+			continue
+		}
+		bars, err := provider.GetDailyBars(p.Ticker)
+		if err != nil || len(bars) == 0 {
+			missing[p.Ticker] = "no price bars"
+			continue
+		}
+		sort.Slice(bars, func(i, j int) bool { return bars[i].Date < bars[j].Date })
+
+		from := p.TradeDate()
+		if from < start {
+			from = start
+		}
+		first, err := time.Parse(dateLayout, from)
+		if err != nil {
+			continue
+		}
+		last, _ := time.Parse(dateLayout, end)
+
+		sign := domain.DirectionSign(p.Direction)
+		i, price := 0, 0.0
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			date := day.Format(dateLayout)
+			for i < len(bars) && bars[i].Date <= date {
+				price = bars[i].Close
+				i++
+			}
+			if price > 0 {
+				marks[date] += sign * (price - p.EntryPrice) * float64(p.Shares)
+			}
+		}
+	}
+	return marks, missing
+}
+
+// EquityCurveOptions selects the range and marking of LoadEquityCurve
+type EquityCurveOptions struct {
+	End          string             // Last date (default: today)
+	MarkToMarket bool               // Mark open stock positions with Provider's closes
+	Provider     domain.BarProvider // Price source for MarkToMarket
+}
+
+// LoadEquityCurve builds the account's equity curve from the database
+//
+// The curve starts at the first position's entry (or first exit) with
+// StartingEquity, or Equity_E when StartingEquity is not set. Realized P&L
+// comes from the exits recorded in trade_history.
+func LoadEquityCurve(db *storage.DB, opts EquityCurveOptions) (*EquityCurve, error) {
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	starting, _ := strconv.ParseFloat(settings[string(domain.SettingStartingEquity)], 64)
+	if starting <= 0 {
+		if starting, err = strconv.ParseFloat(settings[string(domain.SettingEquity)], 64); err != nil {
+			return nil, fmt.Errorf("invalid Equity_E setting: %w", err)
+		}
+	}
+
+	realized, err := db.GetRealizedPnLByDate()
+	if err != nil {
+		return nil, err
+	}
+	positions, err := db.GetAllPositions("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load positions: %w", err)
+	}
+
+	req := EquityCurveRequest{Starting: starting, Realized: realized, End: opts.End}
+	if req.End == "" {
+		req.End = time.Now().Format(dateLayout)
+	}
+	req.Start = req.End
+	for _, p := range positions {
+		if d := p.TradeDate(); d < req.Start {
+			req.Start = d
+		}
+	}
+	if len(realized) > 0 && realized[0].Date < req.Start {
+		req.Start = realized[0].Date
+	}
+
+	var missing map[string]string
+	if opts.MarkToMarket {
+		if opts.Provider == nil {
+			return nil, fmt.Errorf("mark-to-market needs a price source")
+		}
+		req.Unrealized, missing = MarkOpenPositions(positions, opts.Provider, req.Start, req.End)
+	}
+
+	curve, err := BuildEquityCurve(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		curve.Missing = missing
+	}
+	return curve, nil
+}
+
+// WriteEquityCurveCSV writes the daily equity series as CSV with a header row
+func WriteEquityCurveCSV(w io.Writer, daily []EquityDay) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "realized", "unrealized", "equity", "peak", "drawdown", "drawdown_pct"})
+	for _, d := range daily {
+		cw.Write([]string{
+			d.Date,
+			fmt.Sprintf("%.2f", d.Realized),
+			fmt.Sprintf("%.2f", d.Unrealized),
+			fmt.Sprintf("%.2f", d.Equity),
+			fmt.Sprintf("%.2f", d.Peak),
+			fmt.Sprintf("%.2f", d.Drawdown),
+			fmt.Sprintf("%.2f", d.DrawdownPct),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteReturnsCSV writes monthly or yearly returns as CSV with a header row
+func WriteReturnsCSV(w io.Writer, periods []PeriodReturn) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"period", "start_equity", "end_equity", "pnl", "return_pct"})
+	for _, p := range periods {
+		cw.Write([]string{
+			p.Period,
+			fmt.Sprintf("%.2f", p.StartEquity),
+			fmt.Sprintf("%.2f", p.EndEquity),
+			fmt.Sprintf("%.2f", p.PnL),
+			fmt.Sprintf("%.2f", p.ReturnPct),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// mapBarProvider serves bars from a map keyed by ticker
+type mapBarProvider map[string][]domain.Bar
+
+func (m mapBarProvider) GetDailyBars(ticker string) ([]domain.Bar, error) {
+	bars, ok := m[ticker]
+	if !ok {
+		return nil, fmt.Errorf("no bars for %s", ticker)
+	}
+	return bars, nil
+}
+
+func TestBuildEquityCurve(t *testing.T) {
+	curve, err := BuildEquityCurve(EquityCurveRequest{
+		Starting: 10000,
+		Start:    "2025-01-30",
+		End:      "2025-03-03",
+		Realized: []storage.DailyPnL{
+			{Date: "2025-01-31", PnL: 1000},
+			{Date: "2025-02-10", PnL: -2000},
+			{Date: "2025-02-20", PnL: 2500},
+			{Date: "2025-03-02", PnL: -500},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, curve.Daily, 33)
+	assert.Equal(t, "2025-01-30", curve.Daily[0].Date)
+	assert.Equal(t, 10000.0, curve.Daily[0].Equity)
+	assert.Equal(t, 11000.0, curve.Final)
+	assert.InDelta(t, 10, curve.TotalReturnPct, 1e-9)
+	assert.False(t, curve.MarkToMarket)
+
+	assert.Equal(t, 2000.0, curve.MaxDrawdown)
+	assert.InDelta(t, 2000.0/11000*100, curve.MaxDrawdownPct, 1e-9)
+	assert.Equal(t, "2025-01-31", curve.MaxDrawdownPeak)
+	assert.Equal(t, "2025-02-10", curve.MaxDrawdownTrough)
+
+	// Under the 1/31 peak until the 2/20 recovery, then under again from 2/20
+	assert.Equal(t, 20, curve.LongestDrawdownDays)
+	assert.Equal(t, "2025-01-31", curve.LongestDrawdownStart)
+	assert.Equal(t, "2025-02-20", curve.LongestDrawdownEnd)
+	assert.Equal(t, 11, curve.CurrentDrawdownDays)
+
+	require.Len(t, curve.Monthly, 3)
+	assert.Equal(t, "2025-01", curve.Monthly[0].Period)
+	assert.Equal(t, 10000.0, curve.Monthly[0].StartEquity)
+	assert.Equal(t, 11000.0, curve.Monthly[0].EndEquity)
+	assert.InDelta(t, 10, curve.Monthly[0].ReturnPct, 1e-9)
+	assert.Equal(t, "2025-02", curve.Monthly[1].Period)
+	assert.InDelta(t, 500.0/11000*100, curve.Monthly[1].ReturnPct, 1e-9)
+	assert.InDelta(t, -500.0/11500*100, curve.Monthly[2].ReturnPct, 1e-9)
+	require.Len(t, curve.Yearly, 1)
+	assert.InDelta(t, 10, curve.Yearly[0].ReturnPct, 1e-9)
+}
+
+func TestBuildEquityCurve_UnrealizedAndEarlyExits(t *testing.T) {
+	curve, err := BuildEquityCurve(EquityCurveRequest{
+		Starting:   10000,
+		Start:      "2025-01-02",
+		End:        "2025-01-03",
+		Realized:   []storage.DailyPnL{{Date: "2024-12-31", PnL: 300}},
+		Unrealized: map[string]float64{"2025-01-03": -150},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, curve.MarkToMarket)
+	assert.Equal(t, 10300.0, curve.Daily[0].Equity) // Exit before inception lands on day one
+	assert.Equal(t, 10150.0, curve.Daily[1].Equity)
+	assert.Equal(t, -150.0, curve.Daily[1].Unrealized)
+	assert.Equal(t, 150.0, curve.MaxDrawdown)
+}
+
+func TestBuildEquityCurve_Errors(t *testing.T) {
+	_, err := BuildEquityCurve(EquityCurveRequest{Starting: 0, Start: "2025-01-02", End: "2025-01-03"})
+	assert.Error(t, err)
+
+	_, err = BuildEquityCurve(EquityCurveRequest{Starting: 1000, Start: "2025-01-03", End: "2025-01-02"})
+	assert.Error(t, err)
+
+	_, err = BuildEquityCurve(EquityCurveRequest{Starting: 1000, Start: "01/02/2025", End: "2025-01-02"})
+	assert.Error(t, err)
+}
+
+func TestMarkOpenPositions(t *testing.T) {
+	provider := mapBarProvider{
+		"AAPL": {
+			{Date: "2025-01-02", Close: 110},
+			{Date: "2025-01-03", Close: 112},
+			{Date: "2025-01-06", Close: 108},
+		},
+		"XOM": {{Date: "2025-01-03", Close: 48}},
+	}
+	positions := []storage.Position{
+		{Ticker: "AAPL", Direction: "LONG", Status: "OPEN", EntryPrice: 100, Shares: 10, EntryDate: "2025-01-02"},
+		{Ticker: "XOM", Direction: "SHORT", Status: "OPEN", EntryPrice: 50, Shares: 5, EntryDate: "2025-01-02"},
+		{Ticker: "SPY", Status: "OPEN", Shares: 1, InstrumentType: storage.InstrumentOption, EntryDate: "2025-01-02"},
+		{Ticker: "MSFT", Direction: "LONG", Status: "OPEN", EntryPrice: 400, Shares: 3, EntryDate: "2025-01-02"},
+		{Ticker: "NVDA", Direction: "LONG", Status: "CLOSED", EntryPrice: 100, Shares: 0},
+	}
+
+	marks, missing := MarkOpenPositions(positions, provider, "2025-01-01", "2025-01-06")
+
+	assert.NotContains(t, marks, "2025-01-01") // Before entry
+	assert.Equal(t, 100.0, marks["2025-01-02"])
+	assert.Equal(t, 130.0, marks["2025-01-03"]) // 10 × 12 long + 5 × 2 short
+	assert.Equal(t, 130.0, marks["2025-01-05"]) // Weekend carries Friday's close
+	assert.Equal(t, 90.0, marks["2025-01-06"])
+	assert.Len(t, missing, 2)
+	assert.Contains(t, missing, "SPY")
+	assert.Contains(t, missing, "MSFT")
+}
+
+func TestWriteEquityCurveCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteEquityCurveCSV(&buf, []EquityDay{
+		{Date: "2025-01-02", Realized: 100, Equity: 10100, Peak: 10100},
+	}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "date,realized,unrealized,equity,peak,drawdown,drawdown_pct", lines[0])
+	assert.Equal(t, "2025-01-02,100.00,0.00,10100.00,10100.00,0.00,0.00", lines[1])
+}
@@ -0,0 +1,134 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// PositionExcursion is a position's tracked price range and its MAE/MFE
+type PositionExcursion struct {
+	PositionID int     `json:"position_id"`
+	Ticker     string  `json:"ticker"`
+	Direction  string  `json:"direction"`
+	Status     string  `json:"status"`
+	EntryPrice float64 `json:"entry_price"`
+	Low        float64 `json:"low"`
+	High       float64 `json:"high"`
+	Through    string  `json:"through,omitempty"` // Last bar or mark applied
+	domain.Excursion
+}
+
+// MeasureExcursion computes the MAE/MFE of a position from its stored
+// range; false when the range was never tracked or the position is an
+// option (its range is the underlying's, not the premium's)
+func MeasureExcursion(p storage.Position) (PositionExcursion, bool) {
+	if p.ExcursionLow <= 0 || p.InstrumentType == storage.InstrumentOption {
+		return PositionExcursion{}, false
+	}
+	return PositionExcursion{
+		PositionID: p.ID,
+		Ticker:     p.Ticker,
+		Direction:  p.Direction,
+		Status:     p.Status,
+		EntryPrice: p.EntryPrice,
+		Low:        p.ExcursionLow,
+		High:       p.ExcursionHigh,
+		Through:    p.ExcursionDate,
+		Excursion: domain.CalculateExcursion(domain.ExcursionRequest{
+			Direction:   p.Direction,
+			EntryPrice:  p.EntryPrice,
+			Low:         p.ExcursionLow,
+			High:        p.ExcursionHigh,
+			Shares:      p.Shares,
+			ATR:         p.ATR,
+			InitialRisk: p.InitialRisk,
+		}),
+	}, true
+}
+
+// ExcursionPoint is one closed trade on the MAE vs final R scatter plot
+type ExcursionPoint struct {
+	PositionID int     `json:"position_id"`
+	Ticker     string  `json:"ticker"`
+	Direction  string  `json:"direction"`
+	ExitDate   string  `json:"exit_date"`
+	Outcome    string  `json:"outcome"`
+	MAER       float64 `json:"mae_r"`
+	MFER       float64 `json:"mfe_r"`
+	MAEN       float64 `json:"mae_n,omitempty"`
+	MFEN       float64 `json:"mfe_n,omitempty"`
+	FinalR     float64 `json:"final_r"`
+}
+
+// ExcursionScatter is the MAE vs final R data of closed stock trades
+//
+// Winners whose MAE came close to the stop distance (1R, or K in N) say
+// the stop is about as tight as it can be; losers with a large MFE say
+// profits were given back.
+type ExcursionScatter struct {
+	Points        []ExcursionPoint `json:"points"`
+	Untracked     int              `json:"untracked"` // Closed stock trades without a range or 1R
+	Winners       int              `json:"winners"`
+	AvgWinnerMAER float64          `json:"avg_winner_mae_r"`
+	MaxWinnerMAER float64          `json:"max_winner_mae_r"`
+	MaxWinnerMAEN float64          `json:"max_winner_mae_n,omitempty"`
+	Losers        int              `json:"losers"`
+	AvgLoserMFER  float64          `json:"avg_loser_mfe_r"`
+}
+
+// BuildExcursionScatter collects the closed stock trades that have both a
+// tracked range and a recorded 1R, ordered by exit date
+func BuildExcursionScatter(positions []storage.Position) ExcursionScatter {
+	scatter := ExcursionScatter{Points: []ExcursionPoint{}}
+	var winnerMAE, loserMFE float64
+
+	for _, p := range positions {
+		if p.Status != "CLOSED" || p.InstrumentType == storage.InstrumentOption {
+			continue
+		}
+		ex, ok := MeasureExcursion(p)
+		if !ok || p.InitialRisk <= 0 {
+			scatter.Untracked++
+			continue
+		}
+
+		point := ExcursionPoint{
+			PositionID: p.ID,
+			Ticker:     p.Ticker,
+			Direction:  p.Direction,
+			ExitDate:   p.ExitDate,
+			Outcome:    p.Outcome,
+			MAER:       ex.MAER,
+			MFER:       ex.MFER,
+			MAEN:       ex.MAEN,
+			MFEN:       ex.MFEN,
+			FinalR:     p.PnL / p.InitialRisk,
+		}
+		scatter.Points = append(scatter.Points, point)
+
+		switch {
+		case point.FinalR > 0:
+			scatter.Winners++
+			winnerMAE += point.MAER
+			scatter.MaxWinnerMAER = math.Max(scatter.MaxWinnerMAER, point.MAER)
+			scatter.MaxWinnerMAEN = math.Max(scatter.MaxWinnerMAEN, point.MAEN)
+		case point.FinalR < 0:
+			scatter.Losers++
+			loserMFE += point.MFER
+		}
+	}
+
+	if scatter.Winners > 0 {
+		scatter.AvgWinnerMAER = winnerMAE / float64(scatter.Winners)
+	}
+	if scatter.Losers > 0 {
+		scatter.AvgLoserMFER = loserMFE / float64(scatter.Losers)
+	}
+	sort.SliceStable(scatter.Points, func(i, j int) bool {
+		return scatter.Points[i].ExitDate < scatter.Points[j].ExitDate
+	})
+	return scatter
+}
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestMeasureExcursion(t *testing.T) {
+	ex, ok := MeasureExcursion(storage.Position{
+		ID: 1, Ticker: "AAPL", Direction: "LONG", Status: "OPEN", EntryPrice: 100, Shares: 50,
+		ATR: 2, InitialRisk: 200, ExcursionLow: 97, ExcursionHigh: 110, ExcursionDate: "2025-01-10",
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 3, ex.MAE, 1e-9)
+	assert.InDelta(t, 1.5, ex.MAEN, 1e-9)
+	assert.InDelta(t, 0.75, ex.MAER, 1e-9)
+	assert.InDelta(t, 2.5, ex.MFER, 1e-9)
+	assert.Equal(t, "2025-01-10", ex.Through)
+
+	_, ok = MeasureExcursion(storage.Position{Ticker: "MSFT", EntryPrice: 400})
+	assert.False(t, ok, "untracked range")
+
+	_, ok = MeasureExcursion(storage.Position{Ticker: "SPY", InstrumentType: storage.InstrumentOption, ExcursionLow: 1, ExcursionHigh: 2})
+	assert.False(t, ok, "options are not measured")
+}
+
+func TestBuildExcursionScatter(t *testing.T) {
+	positions := []storage.Position{
+		// Winner that dipped 0.5R first: +2R
+		{ID: 1, Ticker: "AAPL", Direction: "LONG", Status: "CLOSED", ExitDate: "2025-02-10", Outcome: "WIN",
+			EntryPrice: 100, Shares: 50, ATR: 2, InitialRisk: 200, PnL: 400, ExcursionLow: 98, ExcursionHigh: 110},
+		// Short stopped out after running 1R in favour: -1R
+		{ID: 2, Ticker: "XOM", Direction: "SHORT", Status: "CLOSED", ExitDate: "2025-01-20", Outcome: "LOSS",
+			EntryPrice: 50, Shares: 100, ATR: 1, InitialRisk: 200, PnL: -200, ExcursionLow: 48, ExcursionHigh: 52},
+		// Untracked, open and option positions stay off the plot
+		{ID: 3, Ticker: "MSFT", Direction: "LONG", Status: "CLOSED", InitialRisk: 100, PnL: 50},
+		{ID: 4, Ticker: "NVDA", Direction: "LONG", Status: "OPEN", ExcursionLow: 90, ExcursionHigh: 95},
+		{ID: 5, Ticker: "SPY", Status: "CLOSED", InstrumentType: storage.InstrumentOption, ExcursionLow: 1, ExcursionHigh: 2},
+	}
+
+	scatter := BuildExcursionScatter(positions)
+
+	require.Len(t, scatter.Points, 2)
+	assert.Equal(t, 1, scatter.Untracked)
+
+	xom := scatter.Points[0]
+	assert.Equal(t, "XOM", xom.Ticker)
+	assert.InDelta(t, -1, xom.FinalR, 1e-9)
+	assert.InDelta(t, 1, xom.MAER, 1e-9)
+	assert.InDelta(t, 1, xom.MFER, 1e-9)
+
+	aapl := scatter.Points[1]
+	assert.InDelta(t, 2, aapl.FinalR, 1e-9)
+	assert.InDelta(t, 0.5, aapl.MAER, 1e-9)
+	assert.InDelta(t, 1, aapl.MAEN, 1e-9)
+
+	assert.Equal(t, 1, scatter.Winners)
+	assert.InDelta(t, 0.5, scatter.AvgWinnerMAER, 1e-9)
+	assert.InDelta(t, 1, scatter.MaxWinnerMAEN, 1e-9)
+	assert.Equal(t, 1, scatter.Losers)
+	assert.InDelta(t, 1, scatter.AvgLoserMFER, 1e-9)
+}
@@ -36,6 +36,7 @@ type SaveDecisionRequest struct {
 	Contracts    int     `json:"contracts"`
 	Sector       string  `json:"sector"`
 	Strategy     string  `json:"strategy"`
+	Direction    string  `json:"direction"` // LONG or SHORT (default: from strategy)
 	RiskDollars  float64 `json:"risk_dollars"`
 	Decision     string  `json:"decision"` // "GO" or "NO-GO"
 	Notes        string  `json:"notes"`
@@ -88,6 +89,15 @@ func (h *DecisionHandler) SaveDecision(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Direction == "" {
+		req.Direction = storage.DirectionForStrategy(req.Strategy)
+	}
+	direction, err := storage.ParseDirection(req.Direction)
+	if err != nil {
+		responses.BadRequest(w, err)
+		return
+	}
+
 	// For GO decisions, validate all gates passed
 	if req.Decision == "GO" {
 		if !req.BannerGreen {
@@ -118,6 +128,7 @@ func (h *DecisionHandler) SaveDecision(w http.ResponseWriter, r *http.Request) {
 		Date:        timestamp.Format("2006-01-02"),
 		Ticker:      req.Ticker,
 		Action:      req.Decision,
+		Direction:   direction,
 		Entry:       req.Entry,
 		ATR:         req.ATR,
 		Shares:      req.Shares,
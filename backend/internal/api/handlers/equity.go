@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// EquityHandler reports the account's raw, peak and sizing equity
+type EquityHandler struct {
+	db     *storage.DB
+	logger *log.Logger
+}
+
+// NewEquityHandler creates a new equity handler
+func NewEquityHandler(db *storage.DB, logger *log.Logger) *EquityHandler {
+	return &EquityHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetEquity handles GET /api/equity
+//
+// Returns domain.EquityStatus: STATIC when StartingEquity is not set,
+// otherwise the tracked curve with the drawdown-adjusted sizing equity.
+func (h *EquityHandler) GetEquity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	status, err := marketdata.ResolveEquity(h.db)
+	if err != nil {
+		h.logger.Printf("Error resolving equity: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	responses.Success(w, status)
+}
+
+// GetEquityCurve handles GET /api/equity/curve?end=2025-12-31&mtm=true
+//
+// Returns analytics.EquityCurve: the daily equity series from realized P&L
+// with drawdown statistics and monthly returns. With mtm=true, open stock
+// positions are marked from the CSV files in marketdata.DefaultPriceDir.
+func (h *EquityHandler) GetEquityCurve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	opts := analytics.EquityCurveOptions{End: r.URL.Query().Get("end")}
+	if r.URL.Query().Get("mtm") == "true" {
+		opts.MarkToMarket = true
+		opts.Provider = marketdata.NewCSVBarProvider(marketdata.DefaultPriceDir)
+	}
+
+	curve, err := analytics.LoadEquityCurve(h.db, opts)
+	if err != nil {
+		h.logger.Printf("Error building equity curve: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	responses.Success(w, curve)
+}
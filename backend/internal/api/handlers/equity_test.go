@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TestEquityHandler_GetEquity tests the tracked equity with a drawdown cut
+func TestEquityHandler_GetEquity(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	if err := db.SetSetting("StartingEquity", "100000"); err != nil {
+		t.Fatalf("Failed to set StartingEquity: %v", err)
+	}
+	for _, e := range []storage.TradeHistoryEntry{
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", EntryDate: "2025-01-02", ExitDate: "2025-02-03", Status: "CLOSED", PnL: 20000, Outcome: "WIN"},
+		{Ticker: "MSFT", Strategy: "LONG_BREAKOUT", EntryDate: "2025-02-04", ExitDate: "2025-03-03", Status: "CLOSED", PnL: -15000, Outcome: "LOSS"},
+	} {
+		if err := db.AddTradeToHistory(&e); err != nil {
+			t.Fatalf("Failed to add trade: %v", err)
+		}
+	}
+
+	handler := NewEquityHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	w := httptest.NewRecorder()
+	handler.GetEquity(w, httptest.NewRequest(http.MethodGet, "/api/equity", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data domain.EquityStatus `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	status := response.Data
+	if status.Mode != domain.EquityModeTracked {
+		t.Errorf("Expected TRACKED mode, got %s", status.Mode)
+	}
+	if status.Raw != 105000 || status.Peak != 120000 {
+		t.Errorf("Expected raw $105,000 and peak $120,000, got $%.2f / $%.2f", status.Raw, status.Peak)
+	}
+	if status.Steps != 1 || status.Adjusted != 96000 {
+		t.Errorf("Expected 1 step and adjusted $96,000, got %d / $%.2f", status.Steps, status.Adjusted)
+	}
+	if len(status.Curve) != 2 {
+		t.Errorf("Expected 2 curve points, got %d", len(status.Curve))
+	}
+}
+
+// TestEquityHandler_GetEquityCurve tests the daily curve built from the trade history
+func TestEquityHandler_GetEquityCurve(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	if err := db.SetSetting("StartingEquity", "100000"); err != nil {
+		t.Fatalf("Failed to set StartingEquity: %v", err)
+	}
+	for _, e := range []storage.TradeHistoryEntry{
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", EntryDate: "2025-01-02", ExitDate: "2025-01-31", Status: "CLOSED", PnL: 10000, Outcome: "WIN"},
+		{Ticker: "MSFT", Strategy: "LONG_BREAKOUT", EntryDate: "2025-02-03", ExitDate: "2025-02-14", Status: "CLOSED", PnL: -5500, Outcome: "LOSS"},
+	} {
+		if err := db.AddTradeToHistory(&e); err != nil {
+			t.Fatalf("Failed to add trade: %v", err)
+		}
+	}
+
+	handler := NewEquityHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	w := httptest.NewRecorder()
+	handler.GetEquityCurve(w, httptest.NewRequest(http.MethodGet, "/api/equity/curve?end=2025-02-28", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data analytics.EquityCurve `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	curve := response.Data
+	if curve.Start != "2025-01-31" || len(curve.Daily) != 29 {
+		t.Errorf("Expected 29 days from 2025-01-31, got %d from %s", len(curve.Daily), curve.Start)
+	}
+	if curve.Final != 104500 || curve.MaxDrawdown != 5500 {
+		t.Errorf("Expected final $104,500 and max drawdown $5,500, got $%.2f / $%.2f", curve.Final, curve.MaxDrawdown)
+	}
+	if len(curve.Monthly) != 2 || curve.Monthly[0].ReturnPct < 9.99 || curve.Monthly[0].ReturnPct > 10.01 {
+		t.Errorf("Expected January return of 10%%, got %+v", curve.Monthly)
+	}
+}
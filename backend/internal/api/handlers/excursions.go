@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// ExcursionsHandler serves the MAE/MFE of positions
+type ExcursionsHandler struct {
+	db     *storage.DB
+	logger *log.Logger
+}
+
+// NewExcursionsHandler creates a new excursions handler
+func NewExcursionsHandler(db *storage.DB, logger *log.Logger) *ExcursionsHandler {
+	return &ExcursionsHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetExcursions handles GET /api/excursions?ticker=AAPL
+//
+// Returns the analytics.PositionExcursion of every open stock position with
+// a tracked range, or only the one for ticker.
+func (h *ExcursionsHandler) GetExcursions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var positions []storage.Position
+	if ticker := r.URL.Query().Get("ticker"); ticker != "" {
+		position, err := h.db.GetPositionByTicker(ticker)
+		if err != nil {
+			h.logger.Printf("Error getting position %s: %v", ticker, err)
+			responses.NotFound(w, err)
+			return
+		}
+		positions = []storage.Position{*position}
+	} else {
+		open, err := h.db.GetOpenPositions()
+		if err != nil {
+			h.logger.Printf("Error getting positions: %v", err)
+			responses.InternalError(w, err)
+			return
+		}
+		positions = open
+	}
+
+	excursions := []analytics.PositionExcursion{}
+	for _, p := range positions {
+		if ex, ok := analytics.MeasureExcursion(p); ok {
+			excursions = append(excursions, ex)
+		}
+	}
+
+	responses.Success(w, excursions)
+}
+
+// MarkPriceRequest is a manual price mark for an open position
+type MarkPriceRequest struct {
+	Ticker string  `json:"ticker"`
+	Price  float64 `json:"price"`
+	Date   string  `json:"date,omitempty"` // YYYY-MM-DD (default: today)
+}
+
+// MarkPrice handles POST /api/excursions/mark
+//
+// Widens the open position's price range with the mark and returns its
+// updated analytics.PositionExcursion.
+func (h *ExcursionsHandler) MarkPrice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req MarkPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Error parsing mark request: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+	if req.Ticker == "" || req.Price <= 0 {
+		responses.BadRequest(w, fmt.Errorf("ticker and a positive price are required"))
+		return
+	}
+	if req.Date == "" {
+		req.Date = time.Now().Format("2006-01-02")
+	}
+
+	position, err := h.db.GetPositionByTicker(req.Ticker)
+	if err != nil {
+		h.logger.Printf("Error getting position %s: %v", req.Ticker, err)
+		responses.NotFound(w, err)
+		return
+	}
+	if _, err := marketdata.MarkExcursion(h.db, position, req.Price, req.Date); err != nil {
+		h.logger.Printf("Error marking %s: %v", req.Ticker, err)
+		responses.BadRequest(w, err)
+		return
+	}
+
+	position, err = h.db.GetPosition(position.ID)
+	if err != nil {
+		h.logger.Printf("Error reloading position %s: %v", req.Ticker, err)
+		responses.InternalError(w, err)
+		return
+	}
+	ex, _ := analytics.MeasureExcursion(*position)
+
+	responses.Success(w, ex)
+}
+
+// GetScatter handles GET /api/excursions/scatter
+//
+// Returns analytics.ExcursionScatter: MAE and MFE against the final R of
+// every closed stock trade with a tracked range and a recorded 1R.
+func (h *ExcursionsHandler) GetScatter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	positions, err := h.db.GetAllPositions("CLOSED")
+	if err != nil {
+		h.logger.Printf("Error getting positions: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	responses.Success(w, analytics.BuildExcursionScatter(positions))
+}
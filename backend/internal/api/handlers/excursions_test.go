@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TestExcursionsHandler tests manual marks through to the closed-trade scatter
+func TestExcursionsHandler(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// 25 shares at $180 with N = 1.5 and a $177 stop: 1R = $75
+	if _, err := db.SaveDecision(storage.Decision{
+		Date: time.Now().Format("2006-01-02"), Ticker: "AAPL", Action: "GO",
+		Entry: 180, ATR: 1.5, StopDistance: 3, InitialStop: 177, Shares: 25, RiskDollars: 75,
+		Banner: "GREEN", Method: "stock",
+	}); err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	if _, err := db.OpenPosition("AAPL"); err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+
+	handler := NewExcursionsHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	for _, price := range []float64{178.5, 186} {
+		body, _ := json.Marshal(MarkPriceRequest{Ticker: "AAPL", Price: price})
+		w := httptest.NewRecorder()
+		handler.MarkPrice(w, httptest.NewRequest(http.MethodPost, "/api/excursions/mark", bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d marking %.2f, got %d: %s", http.StatusOK, price, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.GetExcursions(w, httptest.NewRequest(http.MethodGet, "/api/excursions?ticker=AAPL", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var excursions struct {
+		Data []analytics.PositionExcursion `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&excursions); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(excursions.Data) != 1 {
+		t.Fatalf("Expected 1 excursion, got %d", len(excursions.Data))
+	}
+	ex := excursions.Data[0]
+	if ex.Low != 178.5 || ex.High != 186 {
+		t.Errorf("Expected range 178.50-186.00, got %.2f-%.2f", ex.Low, ex.High)
+	}
+	if math.Abs(ex.MAEN-1) > 1e-9 || math.Abs(ex.MAER-0.5) > 1e-9 || math.Abs(ex.MFER-2) > 1e-9 {
+		t.Errorf("Expected MAE 1N / 0.5R and MFE 2R, got %.2fN / %.2fR and %.2fR", ex.MAEN, ex.MAER, ex.MFER)
+	}
+
+	if err := db.ClosePosition("AAPL", 189, ""); err != nil {
+		t.Fatalf("Failed to close position: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetScatter(w, httptest.NewRequest(http.MethodGet, "/api/excursions/scatter", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var scatter struct {
+		Data analytics.ExcursionScatter `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&scatter); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(scatter.Data.Points) != 1 {
+		t.Fatalf("Expected 1 scatter point, got %d", len(scatter.Data.Points))
+	}
+	point := scatter.Data.Points[0]
+	if math.Abs(point.FinalR-3) > 1e-9 || math.Abs(point.MAER-0.5) > 1e-9 {
+		t.Errorf("Expected final 3R with 0.5R MAE, got %.2fR with %.2fR", point.FinalR, point.MAER)
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/broker"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// FillsHandler imports broker fills and serves the fill log
+type FillsHandler struct {
+	db     *storage.DB
+	logger *log.Logger
+}
+
+// NewFillsHandler creates a new fills handler
+func NewFillsHandler(db *storage.DB, logger *log.Logger) *FillsHandler {
+	return &FillsHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetFills handles GET /api/fills?status=UNMATCHED
+//
+// Returns imported storage.BrokerFill records in trade order, optionally
+// only those with status (APPLIED, UNMATCHED, IGNORED or PENDING).
+func (h *FillsHandler) GetFills(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	fills, err := h.db.ListBrokerFills(r.URL.Query().Get("status"))
+	if err != nil {
+		h.logger.Printf("Error listing fills: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	responses.Success(w, fills)
+}
+
+// ImportFillsRequest is an uploaded IBKR Flex Query trade report, or a
+// broker CSV export read with a built-in profile
+type ImportFillsRequest struct {
+	Content string `json:"content"`           // The report as exported
+	Format  string `json:"format,omitempty"`  // xml or csv (default: detected)
+	Profile string `json:"profile,omitempty"` // Built-in profile name (schwab, fidelity, ...)
+	Source  string `json:"source,omitempty"`  // File name, kept with each fill
+	DryRun  bool   `json:"dry_run,omitempty"` // Only report the position changes
+}
+
+// ImportFills handles POST /api/fills/import
+//
+// Applies the report's fills to positions and returns the
+// broker.ImportReport. Re-importing the same report is safe; with dry_run
+// nothing is saved and the report lists the position changes.
+func (h *FillsHandler) ImportFills(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req ImportFillsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Error parsing import request: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+	if req.Content == "" {
+		responses.BadRequest(w, fmt.Errorf("content is required"))
+		return
+	}
+
+	brokerName := broker.BrokerIBKR
+	var fills []broker.Fill
+	var err error
+	if req.Profile != "" {
+		profile, perr := builtinProfile(req.Profile)
+		if perr != nil {
+			responses.BadRequest(w, perr)
+			return
+		}
+		brokerName = profile.BrokerName()
+		fills, err = profile.ParseCSV(strings.NewReader(req.Content))
+	} else {
+		fills, err = broker.ParseFlex([]byte(req.Content), req.Format)
+	}
+	if err != nil {
+		h.logger.Printf("Error parsing fills: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+	for i := range fills {
+		fills[i].Source = req.Source
+	}
+
+	apply := broker.ApplyFills
+	if req.DryRun {
+		apply = broker.PreviewFills
+	}
+	report, err := apply(h.db, brokerName, fills)
+	if err != nil {
+		h.logger.Printf("Error applying fills: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	if report.DryRun {
+		h.logger.Printf("Previewed %d %s fills: %d position changes", report.Fills, brokerName, len(report.Changes))
+		responses.Success(w, report)
+		return
+	}
+	h.logger.Printf("Imported %d fills: %d applied, %d unmatched, %d already imported",
+		report.Fills, report.Applied, report.Unmatched, report.Duplicates)
+	responses.Success(w, report)
+}
+
+// builtinProfile loads a built-in profile; profile files on the server
+// are not reachable through the API
+func builtinProfile(name string) (*broker.Profile, error) {
+	for _, builtin := range broker.BuiltinProfiles() {
+		if strings.EqualFold(name, builtin) {
+			return broker.LoadProfile(builtin)
+		}
+	}
+	return nil, fmt.Errorf("unknown profile %q (built-in: %s)", name, strings.Join(broker.BuiltinProfiles(), ", "))
+}
+
+// IgnoreFillRequest marks an unmatched fill as reviewed
+type IgnoreFillRequest struct {
+	ID     int    `json:"id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// IgnoreFill handles POST /api/fills/ignore
+//
+// Leaves an unmatched fill out of later imports.
+func (h *FillsHandler) IgnoreFill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req IgnoreFillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Error parsing ignore request: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+
+	if err := h.db.IgnoreBrokerFill(req.ID, req.Reason); err != nil {
+		h.logger.Printf("Error ignoring fill %d: %v", req.ID, err)
+		responses.NotFound(w, err)
+		return
+	}
+
+	responses.Success(w, map[string]interface{}{"id": req.ID, "status": storage.FillIgnored})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/trading-engine/internal/broker"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TestFillsHandler tests a Flex CSV upload through to review, and a
+// profile dry run
+func TestFillsHandler(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	if _, err := db.SaveDecision(storage.Decision{
+		Date: "2025-01-02", Ticker: "AAPL", Action: "GO", Entry: 180, ATR: 1.5,
+		StopDistance: 3, InitialStop: 177, Shares: 25, RiskDollars: 75,
+		Banner: "GREEN", Method: "stock",
+	}); err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+
+	handler := NewFillsHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	report := `Symbol,IBExecID,DateTime,Quantity,TradePrice,IBCommission,Buy/Sell
+AAPL,e1,20250103;093000,25,181,-1,BUY
+TSLA,e2,20250103;094500,5,400,-1,BUY
+`
+	body, _ := json.Marshal(ImportFillsRequest{Content: report, Source: "trades.csv"})
+	w := httptest.NewRecorder()
+	handler.ImportFills(w, httptest.NewRequest(http.MethodPost, "/api/fills/import", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var imported struct {
+		Data broker.ImportReport `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&imported); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if imported.Data.Applied != 1 || imported.Data.Unmatched != 1 {
+		t.Errorf("Expected 1 applied and 1 unmatched, got %d and %d", imported.Data.Applied, imported.Data.Unmatched)
+	}
+
+	position, err := db.GetPositionByTicker("AAPL")
+	if err != nil {
+		t.Fatalf("Expected an open AAPL position: %v", err)
+	}
+	if position.EntryPrice != 181 || position.CurrentStop != 178 {
+		t.Errorf("Expected entry 181.00 with stop 178.00, got %.2f and %.2f", position.EntryPrice, position.CurrentStop)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetFills(w, httptest.NewRequest(http.MethodGet, "/api/fills?status=UNMATCHED", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var unmatched struct {
+		Data []storage.BrokerFill `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&unmatched); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(unmatched.Data) != 1 || unmatched.Data[0].Ticker != "TSLA" || unmatched.Data[0].Source != "trades.csv" {
+		t.Fatalf("Expected the TSLA fill unmatched, got %+v", unmatched.Data)
+	}
+
+	body, _ = json.Marshal(IgnoreFillRequest{ID: unmatched.Data[0].ID, Reason: "test trade"})
+	w = httptest.NewRecorder()
+	handler.IgnoreFill(w, httptest.NewRequest(http.MethodPost, "/api/fills/ignore", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// A Schwab export previewed with its profile: the sell would close the
+	// AAPL position, and nothing is saved
+	schwab := `"Date","Action","Symbol","Description","Quantity","Price","Fees & Comm","Amount"
+"01/06/2025","Sell","AAPL","APPLE INC","25","$185.00","$0.02","$4,624.98"
+`
+	body, _ = json.Marshal(ImportFillsRequest{Content: schwab, Profile: "Schwab", DryRun: true})
+	w = httptest.NewRecorder()
+	handler.ImportFills(w, httptest.NewRequest(http.MethodPost, "/api/fills/import", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var preview struct {
+		Data broker.ImportReport `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&preview); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !preview.Data.DryRun || preview.Data.Broker != "SCHWAB" || len(preview.Data.Changes) != 1 ||
+		preview.Data.Changes[0].After.Status != "CLOSED" {
+		t.Fatalf("Expected a dry run closing AAPL, got %+v", preview.Data)
+	}
+	if open, _ := db.GetPositionByTicker("AAPL"); open == nil {
+		t.Error("Expected the dry run to leave the AAPL position open")
+	}
+
+	body, _ = json.Marshal(ImportFillsRequest{Content: schwab, Profile: "/etc/profile.yaml"})
+	w = httptest.NewRecorder()
+	handler.ImportFills(w, httptest.NewRequest(http.MethodPost, "/api/fills/import", bytes.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a profile file, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	body, _ = json.Marshal(ImportFillsRequest{Content: "not a report"})
+	w = httptest.NewRecorder()
+	handler.ImportFills(w, httptest.NewRequest(http.MethodPost, "/api/fills/import", bytes.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a bad report, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/yourusername/trading-engine/internal/api/responses"
 	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/marketdata"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -55,6 +56,12 @@ func (h *HeatHandler) CheckHeat(w http.ResponseWriter, r *http.Request) {
 		responses.InternalError(w, err)
 		return
 	}
+	equity, err := marketdata.SizingEquity(h.db)
+	if err != nil {
+		h.logger.Printf("Error resolving equity: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
 
 	// Get open positions
 	positions, err := h.db.GetOpenPositions()
@@ -80,7 +87,7 @@ func (h *HeatHandler) CheckHeat(w http.ResponseWriter, r *http.Request) {
 
 	// Build domain heat request
 	heatReq := domain.HeatRequest{
-		Equity:           settings.Equity,
+		Equity:           equity,
 		HeatCapPct:       settings.PortfolioCap / 100, // Convert from % to decimal (4.0 -> 0.04)
 		BucketHeatCapPct: settings.BucketCap / 100,    // Convert from % to decimal (1.5 -> 0.015)
 		AddRiskDollars:   req.AddRiskDollars,
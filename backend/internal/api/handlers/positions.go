@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/marketdata"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -43,3 +47,274 @@ func (h *PositionsHandler) GetPositions(w http.ResponseWriter, r *http.Request)
 
 	responses.Success(w, positions)
 }
+
+// GetLots handles GET /api/positions/lots?ticker=AAPL
+// Returns every fill of the position, including lots closed out by partial exits.
+func (h *PositionsHandler) GetLots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	ticker := r.URL.Query().Get("ticker")
+	if ticker == "" {
+		responses.BadRequest(w, fmt.Errorf("ticker is required"))
+		return
+	}
+
+	position, err := h.db.GetPositionByTicker(ticker)
+	if err != nil {
+		h.logger.Printf("Error getting position %s: %v", ticker, err)
+		responses.NotFound(w, err)
+		return
+	}
+
+	lots, err := h.db.GetPositionLots(position.ID)
+	if err != nil {
+		h.logger.Printf("Error getting lots: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	responses.Success(w, lots)
+}
+
+// AddUnitRequest contains the API request for a pyramid add-on
+type AddUnitRequest struct {
+	Ticker string  `json:"ticker"`
+	Price  float64 `json:"price"`            // Fill price of the new unit
+	Shares int     `json:"shares,omitempty"` // Default: size of unit 1
+}
+
+// AddUnitResponse contains the plan, heat check and updated position
+type AddUnitResponse struct {
+	Plan     *domain.AddUnitPlan `json:"plan"`
+	Heat     *domain.HeatResult  `json:"heat"`
+	Position *storage.Position   `json:"position"`
+}
+
+// AddUnit handles POST /api/positions/add-unit
+func (h *PositionsHandler) AddUnit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req AddUnitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Error parsing add-unit request: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+	if req.Ticker == "" || req.Price <= 0 {
+		responses.BadRequest(w, fmt.Errorf("ticker and a positive price are required"))
+		return
+	}
+
+	position, err := h.db.GetPositionByTicker(req.Ticker)
+	if err != nil {
+		h.logger.Printf("Error getting position %s: %v", req.Ticker, err)
+		responses.NotFound(w, err)
+		return
+	}
+
+	lots, err := h.db.GetPositionLots(position.ID)
+	if err != nil {
+		h.logger.Printf("Error getting lots: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	kStr, err := h.db.GetSetting("StopMultiple_K")
+	if err != nil {
+		h.logger.Printf("Error getting StopMultiple_K: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+	var k float64
+	fmt.Sscanf(kStr, "%f", &k)
+
+	addReq := domain.AddUnitRequest{
+		Ticker:      position.Ticker,
+		Direction:   position.Direction,
+		Price:       req.Price,
+		Shares:      req.Shares,
+		N:           position.ATR,
+		K:           k,
+		AddStepN:    position.AddStepN,
+		MaxUnits:    position.MaxUnits,
+		AddPrices:   []float64{position.AddPrice1, position.AddPrice2, position.AddPrice3},
+		CurrentStop: position.CurrentStop,
+		CurrentRisk: position.RiskDollars,
+	}
+	for _, lot := range lots {
+		if lot.RemainingShares == 0 {
+			continue // Scaled out
+		}
+		addReq.Units = append(addReq.Units, domain.PyramidUnit{
+			UnitNum:    lot.UnitNum,
+			Shares:     lot.RemainingShares,
+			EntryPrice: lot.EntryPrice,
+		})
+	}
+
+	plan, err := domain.PlanAddUnit(addReq)
+	if err != nil {
+		h.logger.Printf("Add-on rejected for %s: %v", req.Ticker, err)
+		responses.BadRequest(w, err)
+		return
+	}
+
+	settings, err := h.db.GetSettings()
+	if err != nil {
+		h.logger.Printf("Error getting settings: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	open, err := h.db.GetOpenPositions()
+	if err != nil {
+		h.logger.Printf("Error getting open positions: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+	domainPositions := make([]domain.Position, len(open))
+	for i, p := range open {
+		domainPositions[i] = domain.Position{
+			Ticker:      p.Ticker,
+			Bucket:      p.Bucket,
+			RiskDollars: p.RiskDollars,
+			UnitsOpen:   max(1, p.CurrentUnits),
+			Status:      "Open",
+		}
+	}
+
+	equity, err := marketdata.SizingEquity(h.db)
+	if err != nil {
+		h.logger.Printf("Error resolving equity: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	heat, err := domain.CheckAddUnitHeat(plan, position.Bucket, equity,
+		settings.PortfolioCap/100, settings.BucketCap/100, domainPositions)
+	if err != nil {
+		h.logger.Printf("Add-on rejected by heat check for %s: %v", req.Ticker, err)
+		responses.BadRequest(w, err)
+		return
+	}
+
+	// An add-on is a new unit, so the Turtle unit limits apply to it
+	limits, err := marketdata.EvaluateUnitLimits(h.db, position.Ticker, position.Direction, position.Bucket)
+	if err != nil {
+		h.logger.Printf("Error checking unit limits: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+	if !limits.Allowed {
+		h.logger.Printf("Add-on rejected by unit limits for %s: %s", req.Ticker, limits.Reason)
+		responses.BadRequest(w, fmt.Errorf("%s", limits.Reason))
+		return
+	}
+
+	updated, err := h.db.AddUnit(position.ID, storage.UnitFill{
+		Price:   plan.FillPrice,
+		Shares:  plan.Shares,
+		NewStop: plan.NewStop,
+	})
+	if err != nil {
+		h.logger.Printf("Error recording add-on: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	h.logger.Printf("Unit %d added to %s at $%.2f, stop moved to $%.2f",
+		plan.UnitNum, req.Ticker, plan.FillPrice, plan.NewStop)
+
+	responses.Success(w, AddUnitResponse{Plan: plan, Heat: heat, Position: updated})
+}
+
+// ClosePositionRequest contains the API request for a full or partial exit
+type ClosePositionRequest struct {
+	Ticker    string  `json:"ticker"`
+	ExitPrice float64 `json:"exit_price"`
+	Shares    int     `json:"shares,omitempty"`  // Partial exit: shares to sell
+	Pct       float64 `json:"pct,omitempty"`     // Partial exit: percent of open shares (1-100)
+	Method    string  `json:"method,omitempty"`  // FIFO (default), LIFO or AVERAGE
+	Outcome   string  `json:"outcome,omitempty"` // Default: from total P&L when the position closes
+}
+
+// ClosePositionResponse contains the exit (partial exits only) and the position after it
+type ClosePositionResponse struct {
+	Exit     *storage.LotCloseResult `json:"exit,omitempty"`
+	Position *storage.Position       `json:"position"`
+}
+
+// ClosePosition handles POST /api/positions/close
+// Without shares or pct every remaining share is sold.
+func (h *PositionsHandler) ClosePosition(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	var req ClosePositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Error parsing close request: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+	if req.Ticker == "" || req.ExitPrice <= 0 {
+		responses.BadRequest(w, fmt.Errorf("ticker and a positive exit_price are required"))
+		return
+	}
+	if req.Shares > 0 && req.Pct > 0 {
+		responses.BadRequest(w, fmt.Errorf("use either shares or pct, not both"))
+		return
+	}
+
+	method, err := storage.ParseCostMethod(req.Method)
+	if err != nil {
+		responses.BadRequest(w, err)
+		return
+	}
+
+	position, err := h.db.GetPositionByTicker(req.Ticker)
+	if err != nil {
+		h.logger.Printf("Error getting position %s: %v", req.Ticker, err)
+		responses.NotFound(w, err)
+		return
+	}
+
+	shares := req.Shares
+	if req.Pct > 0 {
+		if shares, err = storage.SharesForPct(position.Shares, req.Pct); err != nil {
+			responses.BadRequest(w, err)
+			return
+		}
+	}
+
+	var result *storage.LotCloseResult
+	if shares > 0 && shares < position.Shares {
+		result, err = h.db.PartialClose(req.Ticker, shares, req.ExitPrice, method)
+	} else {
+		err = h.db.ClosePosition(req.Ticker, req.ExitPrice, req.Outcome)
+	}
+	if err != nil {
+		h.logger.Printf("Error closing %s: %v", req.Ticker, err)
+		responses.BadRequest(w, err)
+		return
+	}
+
+	updated, err := h.db.GetPosition(position.ID)
+	if err != nil {
+		h.logger.Printf("Error reloading position: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	h.logger.Printf("Exit recorded for %s at $%.2f: status=%s pnl=%.2f",
+		req.Ticker, req.ExitPrice, updated.Status, updated.PnL)
+
+	responses.Success(w, ClosePositionResponse{Exit: result, Position: updated})
+}
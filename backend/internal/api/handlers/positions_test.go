@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yourusername/trading-engine/internal/storage"
 )
@@ -140,3 +142,269 @@ func TestPositionsHandler_GetPositions_DatabaseError(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 }
+
+// TestPositionsHandler_AddUnit tests the POST /api/positions/add-unit endpoint
+func TestPositionsHandler_AddUnit(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	db, err := storage.New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// 25 shares at $180, N = 1.5, 2N stop
+	_, err = db.SaveDecision(storage.Decision{
+		Date:         time.Now().Format("2006-01-02"),
+		Ticker:       "AAPL",
+		Action:       "GO",
+		Entry:        180.0,
+		ATR:          1.5,
+		StopDistance: 3.0,
+		InitialStop:  177.0,
+		Shares:       25,
+		RiskDollars:  75.0,
+		Banner:       "GREEN",
+		Method:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	if _, err := db.OpenPosition("AAPL"); err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	handler := NewPositionsHandler(db, logger)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/positions/add-unit", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.AddUnit(w, req)
+		return w
+	}
+
+	t.Run("Rejects price below the add level", func(t *testing.T) {
+		w := post(`{"ticker": "AAPL", "price": 180.5}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("Adds unit and raises stop", func(t *testing.T) {
+		w := post(`{"ticker": "AAPL", "price": 180.75}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var response struct {
+			Data AddUnitResponse `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if response.Data.Plan.UnitNum != 2 {
+			t.Errorf("Expected unit 2, got %d", response.Data.Plan.UnitNum)
+		}
+		if response.Data.Position.CurrentStop != 177.75 {
+			t.Errorf("Expected stop 177.75, got %.2f", response.Data.Position.CurrentStop)
+		}
+		if response.Data.Position.Shares != 50 || response.Data.Position.CurrentUnits != 2 {
+			t.Errorf("Expected 50 shares in 2 units, got %d in %d",
+				response.Data.Position.Shares, response.Data.Position.CurrentUnits)
+		}
+	})
+
+	t.Run("Rejects an add past the unit limits", func(t *testing.T) {
+		if err := db.SetSetting("UnitLimit_Direction", "2"); err != nil {
+			t.Fatalf("Failed to set unit limit: %v", err)
+		}
+		defer db.SetSetting("UnitLimit_Direction", "12")
+
+		w := post(`{"ticker": "AAPL", "price": 181.5}`)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "limit 2") {
+			t.Fatalf("Expected status %d for the direction limit, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+		position, err := db.GetPositionByTicker("AAPL")
+		if err != nil {
+			t.Fatalf("Failed to get position: %v", err)
+		}
+		if position.CurrentUnits != 2 {
+			t.Errorf("Expected the position to keep 2 units, got %d", position.CurrentUnits)
+		}
+	})
+
+	t.Run("Unknown ticker returns 404", func(t *testing.T) {
+		w := post(`{"ticker": "MSFT", "price": 100}`)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("Method not allowed for GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/positions/add-unit", nil)
+		w := httptest.NewRecorder()
+		handler.AddUnit(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	})
+}
+
+// TestPositionsHandler_GetLots tests the GET /api/positions/lots endpoint
+func TestPositionsHandler_GetLots(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	db, err := storage.New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	_, err = db.SaveDecision(storage.Decision{
+		Date:         time.Now().Format("2006-01-02"),
+		Ticker:       "AAPL",
+		Action:       "GO",
+		Entry:        180.0,
+		ATR:          1.5,
+		StopDistance: 3.0,
+		InitialStop:  177.0,
+		Shares:       25,
+		RiskDollars:  75.0,
+		Banner:       "GREEN",
+		Method:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	position, err := db.OpenPosition("AAPL")
+	if err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+	if _, err := db.AddUnit(position.ID, storage.UnitFill{Price: 182, Shares: 25, NewStop: 179}); err != nil {
+		t.Fatalf("Failed to add unit: %v", err)
+	}
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	handler := NewPositionsHandler(db, logger)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/positions/lots?ticker=AAPL", nil)
+	w := httptest.NewRecorder()
+	handler.GetLots(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data []storage.PositionLot `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[1].RemainingShares != 25 {
+		t.Errorf("Expected 2 open lots, got %+v", response.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/positions/lots", nil)
+	w = httptest.NewRecorder()
+	handler.GetLots(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without ticker, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// TestPositionsHandler_ClosePosition tests partial and full exits via POST /api/positions/close
+func TestPositionsHandler_ClosePosition(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	db, err := storage.New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// 50 shares at $180, $177 stop
+	_, err = db.SaveDecision(storage.Decision{
+		Date:         time.Now().Format("2006-01-02"),
+		Ticker:       "AAPL",
+		Action:       "GO",
+		Entry:        180.0,
+		ATR:          1.5,
+		StopDistance: 3.0,
+		InitialStop:  177.0,
+		Shares:       50,
+		RiskDollars:  150.0,
+		Banner:       "GREEN",
+		Method:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	if _, err := db.OpenPosition("AAPL"); err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+
+	logger := log.New(os.Stdout, "[TEST] ", log.LstdFlags)
+	handler := NewPositionsHandler(db, logger)
+
+	post := func(body string) (*httptest.ResponseRecorder, ClosePositionResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/positions/close", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ClosePosition(w, req)
+
+		var response struct {
+			Data ClosePositionResponse `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return w, response.Data
+	}
+
+	t.Run("Half off keeps the position open", func(t *testing.T) {
+		w, data := post(`{"ticker": "AAPL", "exit_price": 186, "pct": 50}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if data.Exit == nil || data.Exit.Shares != 25 || data.Exit.RealizedPnL != 150 {
+			t.Errorf("Expected 25 shares sold for $150, got %+v", data.Exit)
+		}
+		if data.Position.Status != "OPEN" || data.Position.Shares != 25 || data.Position.RiskDollars != 75 {
+			t.Errorf("Expected OPEN with 25 shares and $75 risk, got %+v", data.Position)
+		}
+	})
+
+	t.Run("Rejects shares and pct together", func(t *testing.T) {
+		w, _ := post(`{"ticker": "AAPL", "exit_price": 186, "shares": 5, "pct": 10}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("Full close closes the position", func(t *testing.T) {
+		w, data := post(`{"ticker": "AAPL", "exit_price": 184}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		// 150 + 25 × 4
+		if data.Position.Status != "CLOSED" || data.Position.PnL != 250 || data.Position.Outcome != "WIN" {
+			t.Errorf("Expected CLOSED WIN with P&L 250, got %+v", data.Position)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// RiskGraphHandler serves payoff diagram data for option legs
+type RiskGraphHandler struct {
+	db     *storage.DB
+	logger *log.Logger
+}
+
+// NewRiskGraphHandler creates a new risk graph handler
+func NewRiskGraphHandler(db *storage.DB, logger *log.Logger) *RiskGraphHandler {
+	return &RiskGraphHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetRiskGraph handles
+//
+//	GET  /api/riskgraph?position_id=7&future_date=2025-12-01&spot=181.50
+//	POST /api/riskgraph  (options.RiskGraphRequest, for legs not yet traded)
+//
+// For a position, the legs come from the position, the stop is its current
+// stop and the spot defaults to the last stored close (or the underlying
+// at entry when no bars are stored).
+func (h *RiskGraphHandler) GetRiskGraph(w http.ResponseWriter, r *http.Request) {
+	var req options.RiskGraphRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			responses.BadRequest(w, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	case http.MethodGet:
+		positionReq, status, err := h.positionRiskGraphRequest(r)
+		if err != nil {
+			responses.Error(w, status, err)
+			return
+		}
+		req = *positionReq
+	default:
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+	if req.Market.Rate == 0 {
+		req.Market.Rate = options.DefaultRiskFreeRate
+	}
+
+	graph, err := options.BuildRiskGraph(req)
+	if err != nil {
+		responses.BadRequest(w, err)
+		return
+	}
+
+	h.logger.Printf("Risk graph: legs=%d spot=%.2f curves=%d", len(req.Legs), graph.Spot, len(graph.Curves))
+
+	responses.Success(w, graph)
+}
+
+// positionRiskGraphRequest builds the request for GET from a stored position
+func (h *RiskGraphHandler) positionRiskGraphRequest(r *http.Request) (*options.RiskGraphRequest, int, error) {
+	query := r.URL.Query()
+	id, err := strconv.Atoi(query.Get("position_id"))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("position_id is required")
+	}
+
+	position, err := h.db.GetPosition(id)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	var legs []options.Leg
+	if position.LegsJSON != "" {
+		if err := json.Unmarshal([]byte(position.LegsJSON), &legs); err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("invalid legs for position %d: %w", id, err)
+		}
+	}
+	if len(legs) == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("position %d has no option legs", id)
+	}
+
+	req := &options.RiskGraphRequest{
+		Legs:       legs,
+		Stop:       position.CurrentStop,
+		FutureDate: query.Get("future_date"),
+		Market:     options.Market{AsOf: query.Get("as_of")},
+	}
+	req.Market.Spot, _ = strconv.ParseFloat(query.Get("spot"), 64)
+	req.Market.Vol, _ = strconv.ParseFloat(query.Get("iv"), 64)
+	if req.Market.Spot <= 0 {
+		req.Market.Spot = position.UnderlyingAtEntry
+		if bars, err := marketdata.NewDBBarProvider(h.db).GetDailyBars(position.Ticker); err == nil {
+			req.Market.Spot = bars[len(bars)-1].Close
+		}
+	}
+	return req, http.StatusOK, nil
+}
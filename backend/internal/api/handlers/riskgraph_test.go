@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TestRiskGraphHandler_PostLegs tests charting legs that are not yet a position
+func TestRiskGraphHandler_PostLegs(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	handler := NewRiskGraphHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+
+	body, _ := json.Marshal(options.RiskGraphRequest{
+		Legs: []options.Leg{
+			{Type: "CALL", Strike: 180, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 3.50},
+			{Type: "CALL", Strike: 185, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 1.25},
+		},
+		Market: options.Market{Spot: 181, AsOf: "2025-11-01"},
+		Stop:   176,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/riskgraph", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.GetRiskGraph(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data options.RiskGraph `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	graph := response.Data
+	if len(graph.Curves) != 3 {
+		t.Fatalf("Expected expiry, today and future curves, got %d", len(graph.Curves))
+	}
+	if graph.Payoff.MaxProfit != 275 || graph.Payoff.MaxLoss != 225 {
+		t.Errorf("Expected max profit $275 / max loss $225, got $%.2f / $%.2f",
+			graph.Payoff.MaxProfit, graph.Payoff.MaxLoss)
+	}
+	if graph.Stop != 176 || graph.Spot != 181 {
+		t.Errorf("Expected spot 181 and stop 176, got %.2f / %.2f", graph.Spot, graph.Stop)
+	}
+}
+
+// TestRiskGraphHandler_Errors tests bad requests and positions without legs
+func TestRiskGraphHandler_Errors(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	// A stock position has no legs to chart
+	_, err = db.SaveDecision(storage.Decision{
+		Date:         time.Now().Format("2006-01-02"),
+		Ticker:       "AAPL",
+		Action:       "GO",
+		Entry:        180.0,
+		ATR:          1.5,
+		StopDistance: 3.0,
+		InitialStop:  177.0,
+		Shares:       25,
+		RiskDollars:  75.0,
+		Banner:       "GREEN",
+		Method:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	position, err := db.OpenPosition("AAPL")
+	if err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+
+	handler := NewRiskGraphHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{"Stock position", http.MethodGet, fmt.Sprintf("/api/riskgraph?position_id=%d", position.ID), "", http.StatusBadRequest},
+		{"Unknown position", http.MethodGet, "/api/riskgraph?position_id=999", "", http.StatusNotFound},
+		{"Missing position_id", http.MethodGet, "/api/riskgraph", "", http.StatusBadRequest},
+		{"No legs", http.MethodPost, "/api/riskgraph", `{"legs":[]}`, http.StatusBadRequest},
+		{"Invalid body", http.MethodPost, "/api/riskgraph", `{`, http.StatusBadRequest},
+		{"Too many points", http.MethodPost, "/api/riskgraph",
+			`{"legs":[{"type":"CALL","strike":180,"exp":"2025-12-19","qty":1,"action":"BUY","price":3.5}],"market":{"spot":181,"as_of":"2025-11-01"},"points":100000000}`,
+			http.StatusBadRequest},
+		{"Method not allowed (DELETE)", http.MethodDelete, "/api/riskgraph", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			handler.GetRiskGraph(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// SignalsHandler handles breakout signal API requests
+type SignalsHandler struct {
+	db     *storage.DB
+	logger *log.Logger
+}
+
+// NewSignalsHandler creates a new signals handler
+func NewSignalsHandler(db *storage.DB, logger *log.Logger) *SignalsHandler {
+	return &SignalsHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetSignals handles GET /api/signals?system=SYSTEM_1&tickers=AAPL,MSFT&triggered=true
+// Without tickers, every ticker in the price store is scanned
+func (h *SignalsHandler) GetSignals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	query := r.URL.Query()
+	params, err := domain.GetBreakoutParams(query.Get("system"))
+	if err != nil {
+		responses.BadRequest(w, err)
+		return
+	}
+
+	var tickers []string
+	if raw := query.Get("tickers"); raw != "" {
+		tickers = strings.Split(raw, ",")
+	} else {
+		tickers, err = h.db.GetPriceTickers()
+		if err != nil {
+			h.logger.Printf("Error listing price tickers: %v", err)
+			responses.InternalError(w, err)
+			return
+		}
+	}
+
+	signals := domain.ScanBreakouts(marketdata.NewDBBarProvider(h.db), tickers, params)
+
+	if query.Get("triggered") == "true" {
+		triggered := make([]domain.BreakoutSignal, 0, len(signals))
+		for _, s := range signals {
+			if s.Triggered {
+				triggered = append(triggered, s)
+			}
+		}
+		signals = triggered
+	}
+
+	h.logger.Printf("Breakout scan: system=%s tickers=%d returned=%d", params.System, len(tickers), len(signals))
+
+	responses.Success(w, signals)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TestSignalsHandler_GetSignals tests the GET /api/signals endpoint
+func TestSignalsHandler_GetSignals(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+
+	// AAPL: flat range then a close above the 20-bar high
+	seedFlatBars(t, db, "AAPL", 25)
+	if _, err := db.SavePriceBars("AAPL", []storage.PriceBar{
+		{Date: "2025-02-03", Open: 101, High: 103, Low: 100.5, Close: 102.5},
+	}); err != nil {
+		t.Fatalf("Failed to seed breakout bar: %v", err)
+	}
+	// MSFT: flat range, no breakout
+	seedFlatBars(t, db, "MSFT", 25)
+
+	handler := NewSignalsHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"All stored tickers", http.MethodGet, "/api/signals?system=SYSTEM_1", http.StatusOK, 2},
+		{"Triggered only", http.MethodGet, "/api/signals?system=SYSTEM_1&triggered=true", http.StatusOK, 1},
+		{"Explicit tickers", http.MethodGet, "/api/signals?system=SYSTEM_1&tickers=msft", http.StatusOK, 1},
+		{"Unknown system", http.MethodGet, "/api/signals?system=SYSTEM_9", http.StatusBadRequest, 0},
+		{"Method not allowed (POST)", http.MethodPost, "/api/signals", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetSignals(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Data) != tt.expectedCount {
+				t.Errorf("Expected %d signals, got %d", tt.expectedCount, len(response.Data))
+			}
+			for _, s := range response.Data {
+				if s["ticker"] == "AAPL" && s["direction"] != "LONG" {
+					t.Errorf("Expected AAPL LONG breakout, got %v", s["direction"])
+				}
+			}
+		})
+	}
+}
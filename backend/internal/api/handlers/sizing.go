@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/yourusername/trading-engine/internal/api/responses"
 	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/marketdata"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -42,6 +44,13 @@ func (h *SizingHandler) CalculateSize(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Printf("Sizing request: %+v", req)
 
+	// Fill N from stored price bars when the caller omitted it
+	if _, err := domain.ResolveSizingATR(marketdata.NewDBBarProvider(h.db), &req); err != nil {
+		h.logger.Printf("Error resolving stored ATR: %v", err)
+		responses.BadRequest(w, err)
+		return
+	}
+
 	// Calculate position size
 	result, err := domain.CalculatePositionSize(req)
 	if err != nil {
@@ -55,3 +64,34 @@ func (h *SizingHandler) CalculateSize(w http.ResponseWriter, r *http.Request) {
 	// Return result
 	responses.Success(w, result)
 }
+
+// GetATR handles GET /api/prices/atr?ticker=AAPL
+// Returns the 20-day Wilder ATR (N) computed from stored daily bars
+func (h *SizingHandler) GetATR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	ticker := strings.ToUpper(r.URL.Query().Get("ticker"))
+	if ticker == "" {
+		responses.BadRequest(w, domain.ErrNoTickerForATR)
+		return
+	}
+
+	bars, err := marketdata.NewDBBarProvider(h.db).GetDailyBars(ticker)
+	if err != nil {
+		h.logger.Printf("Error loading price bars for %s: %v", ticker, err)
+		responses.NotFound(w, err)
+		return
+	}
+
+	result, err := domain.CalculateATR(bars, domain.DefaultATRPeriod)
+	if err != nil {
+		h.logger.Printf("Error calculating ATR for %s: %v", ticker, err)
+		responses.BadRequest(w, err)
+		return
+	}
+
+	responses.Success(w, result)
+}
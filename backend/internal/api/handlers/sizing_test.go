@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -160,3 +161,87 @@ func TestSizingHandler_CalculateSize(t *testing.T) {
 		})
 	}
 }
+
+// seedFlatBars stores n daily bars with a constant 2.00 true range
+func seedFlatBars(t *testing.T, db *storage.DB, ticker string, n int) {
+	t.Helper()
+	bars := make([]storage.PriceBar, n)
+	for i := range bars {
+		bars[i] = storage.PriceBar{
+			Date:  fmt.Sprintf("2025-01-%02d", i+1),
+			Open:  100,
+			High:  101,
+			Low:   99,
+			Close: 100,
+		}
+	}
+	if _, err := db.SavePriceBars(ticker, bars); err != nil {
+		t.Fatalf("Failed to seed price bars: %v", err)
+	}
+}
+
+// TestSizingHandler_CalculateSize_StoredATR tests sizing with N filled from stored bars
+func TestSizingHandler_CalculateSize_StoredATR(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	seedFlatBars(t, db, "AAPL", 25)
+
+	handler := NewSizingHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"ticker":   "AAPL",
+		"entry":    180.0,
+		"method":   "stock",
+		"k":        2,
+		"risk_pct": 0.0075,
+		"equity":   100000.0,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sizing/calculate", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.CalculateSize(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// N = 2.00, stop distance = 4.00, shares = 750 / 4
+	if response.Data["atr_n"] != 2.0 {
+		t.Errorf("Expected atr_n 2.0, got %v", response.Data["atr_n"])
+	}
+	if response.Data["atr_date"] != "2025-01-25" {
+		t.Errorf("Expected atr_date 2025-01-25, got %v", response.Data["atr_date"])
+	}
+	if response.Data["shares"] != 187.0 {
+		t.Errorf("Expected 187 shares, got %v", response.Data["shares"])
+	}
+
+	// GET /api/prices/atr reports the same N
+	atrReq := httptest.NewRequest(http.MethodGet, "/api/prices/atr?ticker=aapl", nil)
+	atrW := httptest.NewRecorder()
+	handler.GetATR(atrW, atrReq)
+	if atrW.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from GetATR, got %d. Body: %s", atrW.Code, atrW.Body.String())
+	}
+
+	// Unknown ticker has no stored bars
+	missingReq := httptest.NewRequest(http.MethodGet, "/api/prices/atr?ticker=NOPE", nil)
+	missingW := httptest.NewRecorder()
+	handler.GetATR(missingW, missingReq)
+	if missingW.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown ticker, got %d", missingW.Code)
+	}
+}
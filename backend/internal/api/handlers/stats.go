@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/api/responses"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// StatsHandler serves the R-multiple trade journal statistics
+type StatsHandler struct {
+	db     *storage.DB
+	logger *log.Logger
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(db *storage.DB, logger *log.Logger) *StatsHandler {
+	return &StatsHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetStats handles GET /api/stats?from=2025-01-01&to=2025-12-31
+//
+// Returns analytics.Report for the closed trades that exited in the range
+// (both bounds optional).
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responses.Error(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	trades, err := analytics.LoadJournal(h.db)
+	if err != nil {
+		h.logger.Printf("Error loading trade journal: %v", err)
+		responses.InternalError(w, err)
+		return
+	}
+
+	report := analytics.BuildReport(analytics.FilterTrades(trades, from, to))
+	report.From, report.To = from, to
+
+	responses.Success(w, report)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TestStatsHandler_GetStats tests R being measured against the entry risk
+// after the stop has moved
+func TestStatsHandler_GetStats(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// 25 shares at $180 with a $177 stop: 1R = $75
+	if _, err := db.SaveDecision(storage.Decision{
+		Date: time.Now().Format("2006-01-02"), Ticker: "AAPL", Action: "GO",
+		Entry: 180, ATR: 1.5, StopDistance: 3, InitialStop: 177, Shares: 25, RiskDollars: 75,
+		Banner: "GREEN", Method: "stock",
+	}); err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	if _, err := db.OpenPosition("AAPL"); err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+	if err := db.UpdateStop("AAPL", 185); err != nil {
+		t.Fatalf("Failed to move stop: %v", err)
+	}
+	if err := db.ClosePosition("AAPL", 189, ""); err != nil {
+		t.Fatalf("Failed to close position: %v", err)
+	}
+
+	handler := NewStatsHandler(db, log.New(os.Stdout, "[TEST] ", log.LstdFlags))
+	w := httptest.NewRecorder()
+	handler.GetStats(w, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data analytics.Report `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	report := response.Data
+	if report.Overall.Trades != 1 || len(report.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", report.Overall.Trades)
+	}
+	// $225 profit over the $75 entry risk, not the $0 left after the stop moved
+	if trade := report.Trades[0]; trade.InitialRisk != 75 || trade.RMultiple != 3 {
+		t.Errorf("Expected 1R $75 and 3R, got $%.2f and %.2fR", trade.InitialRisk, trade.RMultiple)
+	}
+	if report.ByStrategy["LONG_BREAKOUT"].Wins != 1 {
+		t.Errorf("Expected the win under LONG_BREAKOUT, got %+v", report.ByStrategy)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetStats(w, httptest.NewRequest(http.MethodGet, "/api/stats?to=2000-01-01", nil))
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Overall.Trades != 0 {
+		t.Errorf("Expected no trades before 2000, got %d", response.Data.Overall.Trades)
+	}
+}
@@ -0,0 +1,454 @@
+// Package backtest replays daily bars through the production sizing, heat and cooldown rules
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// Exit reasons (STOP and CHANNEL match the breakout replay)
+const (
+	ExitReasonStop    = domain.ExitReasonStop
+	ExitReasonChannel = domain.ExitReasonChannel
+	ExitReasonEnd     = "END" // Closed at the last bar of the test
+)
+
+// Rejection reasons counted in Result.Rejections
+const (
+	RejectHeatPortfolio = "heat_portfolio"
+	RejectHeatBucket    = "heat_bucket"
+	RejectCooldown      = "cooldown"
+	RejectSkipFilter    = "skip_filter"
+	RejectZeroShares    = "zero_shares"
+)
+
+const dateLayout = "2006-01-02"
+
+// Config holds the account settings and breakout system for one run
+type Config struct {
+	Equity           float64               `json:"equity"`
+	RiskPct          float64               `json:"risk_pct"`            // Decimal (0.0075 = 0.75%)
+	HeatCapPct       float64               `json:"heat_cap_pct"`        // Decimal (0.04 = 4%)
+	BucketHeatCapPct float64               `json:"bucket_heat_cap_pct"` // Decimal (0.015 = 1.5%)
+	K                float64               `json:"k"`
+	Params           domain.BreakoutParams `json:"params"`
+	Buckets          map[string]string     `json:"buckets,omitempty"` // Ticker -> sector bucket
+	Start            string                `json:"start,omitempty"`   // First date entries are allowed (YYYY-MM-DD)
+	End              string                `json:"end,omitempty"`     // Last date processed (YYYY-MM-DD)
+	SkipFilter       bool                  `json:"skip_filter"`       // Apply the System 1 last-breakout filter
+}
+
+// Trade is one completed round trip
+type Trade struct {
+	Ticker      string  `json:"ticker"`
+	Bucket      string  `json:"bucket,omitempty"`
+	Direction   string  `json:"direction"`
+	EntryDate   string  `json:"entry_date"`
+	EntryPrice  float64 `json:"entry_price"`
+	Shares      int     `json:"shares"`
+	N           float64 `json:"atr_n"`
+	InitialStop float64 `json:"initial_stop"`
+	RiskDollars float64 `json:"risk_dollars"`
+	ExitDate    string  `json:"exit_date"`
+	ExitPrice   float64 `json:"exit_price"`
+	ExitReason  string  `json:"exit_reason"`
+	PnL         float64 `json:"pnl"`
+	RMultiple   float64 `json:"r_multiple"`
+}
+
+// EquityPoint is the marked-to-market account value at one date
+type EquityPoint struct {
+	Date        string  `json:"date"`
+	Equity      float64 `json:"equity"`
+	OpenRisk    float64 `json:"open_risk"`
+	Positions   int     `json:"positions"`
+	Drawdown    float64 `json:"drawdown"`
+	DrawdownPct float64 `json:"drawdown_pct"`
+}
+
+// Result is the outcome of a backtest run
+type Result struct {
+	Config         Config         `json:"config"`
+	StartDate      string         `json:"start_date"`
+	EndDate        string         `json:"end_date"`
+	InitialEquity  float64        `json:"initial_equity"`
+	FinalEquity    float64        `json:"final_equity"`
+	TotalReturnPct float64        `json:"total_return_pct"`
+	CAGRPct        float64        `json:"cagr_pct"`
+	MaxDrawdown    float64        `json:"max_drawdown"`
+	MaxDrawdownPct float64        `json:"max_drawdown_pct"`
+	TradeCount     int            `json:"trade_count"`
+	Wins           int            `json:"wins"`
+	Losses         int            `json:"losses"`
+	WinRatePct     float64        `json:"win_rate_pct"`
+	Rejections     map[string]int `json:"rejections"`
+	Trades         []Trade        `json:"trades"`
+	EquityCurve    []EquityPoint  `json:"equity_curve"`
+}
+
+// Backtest errors
+var (
+	ErrNoBars = errors.New("no price bars to backtest")
+)
+
+// ConfigFromSettings builds a Config from the settings table values
+// Missing keys fall back to the bootstrap defaults in storage/schema.go.
+func ConfigFromSettings(settings map[string]string) Config {
+	cfg := Config{
+		Equity:           10000,
+		RiskPct:          0.0075,
+		HeatCapPct:       0.04,
+		BucketHeatCapPct: 0.015,
+		K:                2,
+	}
+	if val, ok := settings["Equity_E"]; ok {
+		fmt.Sscanf(val, "%f", &cfg.Equity)
+	}
+	if val, ok := settings["RiskPct_r"]; ok {
+		fmt.Sscanf(val, "%f", &cfg.RiskPct)
+	}
+	if val, ok := settings["HeatCap_H_pct"]; ok {
+		fmt.Sscanf(val, "%f", &cfg.HeatCapPct)
+	}
+	if val, ok := settings["BucketHeatCap_pct"]; ok {
+		fmt.Sscanf(val, "%f", &cfg.BucketHeatCapPct)
+	}
+	if val, ok := settings["StopMultiple_K"]; ok {
+		fmt.Sscanf(val, "%f", &cfg.K)
+	}
+	cfg.Params, _ = domain.GetBreakoutParams("")
+	return cfg
+}
+
+// series holds one ticker's bars with precomputed lookups
+type series struct {
+	ticker string
+	bucket string
+	bars   []domain.Bar
+	atr    []float64
+	index  map[string]int
+}
+
+// openPosition is a position held by the simulator
+type openPosition struct {
+	trade     Trade
+	lastClose float64
+}
+
+// Run replays the bars day by day and returns the simulated account
+//
+// Each date in the union of all tickers' bars is processed in order:
+//  1. Exits: open positions that trade through their K×N stop or the
+//     Donchian exit channel are closed (filled at the open on a gap). A
+//     losing exit starts the 24-hour bucket cooldown from storage.
+//  2. Entries: tickers are scanned alphabetically with domain.DetectBreakout.
+//     Long breakouts are sized with domain.CalculatePositionSize using
+//     realized equity, then must pass the System 1 skip filter (when
+//     enabled), the bucket cooldown and domain.CalculateHeat, exactly as
+//     the save-decision gates do. Entries fill at the signal bar's close.
+//  3. Mark to market: equity = realized equity + open P&L at the close.
+//
+// Bars before cfg.Start are used for channel and N history only.
+// Positions still open at the end are closed at the last close (END).
+func Run(cfg Config, bars map[string][]domain.Bar) (*Result, error) {
+	if cfg.Equity <= 0 {
+		return nil, domain.ErrInvalidEquity
+	}
+	if cfg.Params.EntryLookback <= 0 || cfg.Params.ExitLookback <= 0 {
+		return nil, fmt.Errorf("breakout lookbacks must be positive, got %d/%d",
+			cfg.Params.EntryLookback, cfg.Params.ExitLookback)
+	}
+
+	all := make([]*series, 0, len(bars))
+	dateSet := map[string]bool{}
+	for ticker, tickerBars := range bars {
+		if len(tickerBars) == 0 {
+			continue
+		}
+		ticker = strings.ToUpper(ticker)
+		s := &series{
+			ticker: ticker,
+			bucket: cfg.Buckets[ticker],
+			bars:   tickerBars,
+			atr:    domain.ATRSeries(tickerBars, domain.DefaultATRPeriod),
+			index:  make(map[string]int, len(tickerBars)),
+		}
+		for i, b := range tickerBars {
+			s.index[b.Date] = i
+			if cfg.End == "" || b.Date <= cfg.End {
+				dateSet[b.Date] = true
+			}
+		}
+		all = append(all, s)
+	}
+	if len(dateSet) == 0 {
+		return nil, ErrNoBars
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ticker < all[j].ticker })
+
+	dates := make([]string, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	result := &Result{
+		Config:        cfg,
+		InitialEquity: cfg.Equity,
+		Rejections:    map[string]int{},
+		Trades:        []Trade{},
+		EquityCurve:   []EquityPoint{},
+	}
+
+	realized := cfg.Equity
+	open := map[string]*openPosition{}
+	cooldowns := map[string]time.Time{} // Bucket -> expires at
+
+	closePosition := func(pos *openPosition, date string, price float64, reason string) {
+		t := pos.trade
+		t.ExitDate = date
+		t.ExitPrice = price
+		t.ExitReason = reason
+		t.PnL = float64(t.Shares) * (price - t.EntryPrice)
+		if t.RiskDollars > 0 {
+			t.RMultiple = t.PnL / t.RiskDollars
+		}
+		realized += t.PnL
+		result.Trades = append(result.Trades, t)
+		delete(open, t.Ticker)
+
+		if t.PnL < 0 && t.Bucket != "" {
+			if lossTime, err := time.Parse(dateLayout, date); err == nil {
+				cooldowns[t.Bucket] = storage.CooldownExpiresAt(lossTime)
+			}
+		}
+	}
+
+	for _, date := range dates {
+		now, err := time.Parse(dateLayout, date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bar date %q: %w", date, err)
+		}
+
+		// 1. Exits
+		for _, s := range all {
+			pos, held := open[s.ticker]
+			i, ok := s.index[date]
+			if !held || !ok {
+				continue
+			}
+			bar := s.bars[i]
+			_, exitLow, err := domain.DonchianChannel(s.bars, i, min(cfg.Params.ExitLookback, i))
+			if err != nil {
+				continue
+			}
+			stop := pos.trade.InitialStop
+			switch {
+			case bar.Low <= stop && stop >= exitLow:
+				closePosition(pos, date, math.Min(bar.Open, stop), ExitReasonStop)
+			case bar.Low < exitLow:
+				closePosition(pos, date, math.Min(bar.Open, exitLow), ExitReasonChannel)
+			default:
+				pos.lastClose = bar.Close
+			}
+		}
+
+		// 2. Entries
+		if cfg.Start == "" || date >= cfg.Start {
+			for _, s := range all {
+				if _, held := open[s.ticker]; held {
+					continue
+				}
+				i, ok := s.index[date]
+				if !ok || i < cfg.Params.EntryLookback || s.atr[i] <= 0 {
+					continue
+				}
+
+				window := s.bars[max(0, i-max(cfg.Params.EntryLookback, cfg.Params.ExitLookback)) : i+1]
+				signal, err := domain.DetectBreakout(s.ticker, window, cfg.Params)
+				if err != nil || !signal.Triggered || signal.Direction != domain.SignalLong {
+					continue
+				}
+
+				if cfg.SkipFilter && cfg.Params.System == domain.BreakoutSystemOne {
+					filter, err := domain.EvaluateLastBreakoutFilter(s.ticker, s.bars[:i+1], nil, cfg.K)
+					if err == nil && filter.Skip {
+						result.Rejections[RejectSkipFilter]++
+						continue
+					}
+				}
+
+				if expires, ok := cooldowns[s.bucket]; ok && s.bucket != "" && storage.CooldownActiveAt(expires, now) {
+					result.Rejections[RejectCooldown]++
+					continue
+				}
+
+				sizing, err := domain.CalculatePositionSize(domain.SizingRequest{
+					Ticker:  s.ticker,
+					Equity:  realized,
+					RiskPct: cfg.RiskPct,
+					Entry:   signal.Close,
+					ATR:     s.atr[i],
+					ATRDate: date,
+					K:       cfg.K,
+					Method:  "stock",
+				})
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: sizing failed: %w", s.ticker, date, err)
+				}
+				if sizing.Shares <= 0 {
+					result.Rejections[RejectZeroShares]++
+					continue
+				}
+
+				heat, err := domain.CalculateHeat(domain.HeatRequest{
+					Equity:           realized,
+					HeatCapPct:       cfg.HeatCapPct,
+					BucketHeatCapPct: cfg.BucketHeatCapPct,
+					AddRiskDollars:   sizing.ActualRisk,
+					AddBucket:        s.bucket,
+					OpenPositions:    heatPositions(open),
+				})
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: heat check failed: %w", s.ticker, date, err)
+				}
+				// Same rule as DBGateChecker.CheckHeatCaps: bucket cap only applies to named buckets
+				if heat.PortfolioCapExceeded {
+					result.Rejections[RejectHeatPortfolio]++
+					continue
+				}
+				if s.bucket != "" && heat.BucketCapExceeded {
+					result.Rejections[RejectHeatBucket]++
+					continue
+				}
+
+				open[s.ticker] = &openPosition{
+					trade: Trade{
+						Ticker:      s.ticker,
+						Bucket:      s.bucket,
+						Direction:   domain.SignalLong,
+						EntryDate:   date,
+						EntryPrice:  signal.Close,
+						Shares:      sizing.Shares,
+						N:           s.atr[i],
+						InitialStop: sizing.InitialStop,
+						RiskDollars: sizing.ActualRisk,
+					},
+					lastClose: signal.Close,
+				}
+			}
+		}
+
+		// 3. Mark to market (warm-up history before Start is not reported)
+		if cfg.Start != "" && date < cfg.Start {
+			continue
+		}
+		equity := realized
+		openRisk := 0.0
+		for _, pos := range open {
+			equity += float64(pos.trade.Shares) * (pos.lastClose - pos.trade.EntryPrice)
+			openRisk += pos.trade.RiskDollars
+		}
+		result.EquityCurve = append(result.EquityCurve, EquityPoint{
+			Date:      date,
+			Equity:    equity,
+			OpenRisk:  openRisk,
+			Positions: len(open),
+		})
+	}
+
+	// Close anything still open at its last close
+	last := dates[len(dates)-1]
+	for _, s := range all {
+		if pos, held := open[s.ticker]; held {
+			closePosition(pos, last, pos.lastClose, ExitReasonEnd)
+		}
+	}
+	if n := len(result.EquityCurve); n > 0 {
+		result.EquityCurve[n-1].Equity = realized
+		result.EquityCurve[n-1].OpenRisk = 0
+		result.EquityCurve[n-1].Positions = 0
+	}
+
+	summarize(result, realized)
+	return result, nil
+}
+
+// heatPositions converts simulator positions to the domain heat input
+func heatPositions(open map[string]*openPosition) []domain.Position {
+	positions := make([]domain.Position, 0, len(open))
+	for _, pos := range open {
+		positions = append(positions, domain.Position{
+			Ticker:      pos.trade.Ticker,
+			Bucket:      pos.trade.Bucket,
+			RiskDollars: pos.trade.RiskDollars,
+			UnitsOpen:   1,
+			Status:      "Open",
+		})
+	}
+	return positions
+}
+
+// summarize fills the headline statistics from the trades and equity curve
+func summarize(result *Result, finalEquity float64) {
+	result.FinalEquity = finalEquity
+	result.TradeCount = len(result.Trades)
+	result.TotalReturnPct = (finalEquity/result.InitialEquity - 1) * 100
+
+	for _, t := range result.Trades {
+		if t.PnL > 0 {
+			result.Wins++
+		} else {
+			result.Losses++
+		}
+	}
+	if result.TradeCount > 0 {
+		result.WinRatePct = float64(result.Wins) / float64(result.TradeCount) * 100
+	}
+
+	curve := result.EquityCurve
+	if len(curve) == 0 {
+		return
+	}
+	result.StartDate = curve[0].Date
+	result.EndDate = curve[len(curve)-1].Date
+
+	peak := result.InitialEquity
+	for i := range curve {
+		peak = math.Max(peak, curve[i].Equity)
+		curve[i].Drawdown = peak - curve[i].Equity
+		if peak > 0 {
+			curve[i].DrawdownPct = curve[i].Drawdown / peak * 100
+		}
+		if curve[i].Drawdown > result.MaxDrawdown {
+			result.MaxDrawdown = curve[i].Drawdown
+		}
+		if curve[i].DrawdownPct > result.MaxDrawdownPct {
+			result.MaxDrawdownPct = curve[i].DrawdownPct
+		}
+	}
+
+	result.CAGRPct = CAGR(result.InitialEquity, finalEquity, result.StartDate, result.EndDate)
+}
+
+// CAGR returns the compound annual growth rate in percent between two dates
+// Returns 0 when the span is not positive or the account was wiped out.
+func CAGR(initial, final float64, start, end string) float64 {
+	from, err1 := time.Parse(dateLayout, start)
+	to, err2 := time.Parse(dateLayout, end)
+	if err1 != nil || err2 != nil || initial <= 0 || final <= 0 {
+		return 0
+	}
+	years := to.Sub(from).Hours() / 24 / 365.25
+	if years <= 0 {
+		return 0
+	}
+	return (math.Pow(final/initial, 1/years) - 1) * 100
+}
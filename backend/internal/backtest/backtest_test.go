@@ -0,0 +1,192 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/domain"
+)
+
+// makeBars builds daily bars from closes, each trading ±0.5 around its close
+func makeBars(start string, closes ...float64) []domain.Bar {
+	day, _ := time.Parse(dateLayout, start)
+	bars := make([]domain.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = domain.Bar{
+			Date:  day.AddDate(0, 0, i).Format(dateLayout),
+			Open:  c,
+			High:  c + 0.5,
+			Low:   c - 0.5,
+			Close: c,
+		}
+	}
+	return bars
+}
+
+// flat returns n closes of 100
+func flat(n int) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = 100
+	}
+	return closes
+}
+
+// breakoutRun is 25 flat bars, a rally to 124, then a drop through the 10-bar exit channel
+func breakoutRun() []float64 {
+	closes := flat(25)
+	for c := 102.0; c <= 124; c += 2 {
+		closes = append(closes, c)
+	}
+	return append(closes, 123, 105)
+}
+
+func testConfig() Config {
+	params, _ := domain.GetBreakoutParams(domain.BreakoutSystemOne)
+	return Config{
+		Equity:           10000,
+		RiskPct:          0.01,
+		HeatCapPct:       0.04,
+		BucketHeatCapPct: 0.015,
+		K:                2,
+		Params:           params,
+	}
+}
+
+func TestRun_SingleWinningTrade(t *testing.T) {
+	bars := map[string][]domain.Bar{"AAPL": makeBars("2025-01-01", breakoutRun()...)}
+
+	result, err := Run(testConfig(), bars)
+	require.NoError(t, err)
+	require.Len(t, result.Trades, 1)
+
+	trade := result.Trades[0]
+	assert.Equal(t, "2025-01-26", trade.EntryDate)
+	assert.Equal(t, 102.0, trade.EntryPrice)
+	assert.Equal(t, ExitReasonChannel, trade.ExitReason)
+	assert.Equal(t, 105.0, trade.ExitPrice) // Gapped through the channel, filled at the open
+	assert.Greater(t, trade.PnL, 0.0)
+
+	// Sizing comes from domain.CalculatePositionSize with the run's N
+	sizing, err := domain.CalculatePositionSize(domain.SizingRequest{
+		Equity: 10000, RiskPct: 0.01, Entry: 102, ATR: trade.N, K: 2, Method: "stock",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sizing.Shares, trade.Shares)
+	assert.InDelta(t, sizing.InitialStop, trade.InitialStop, 1e-9)
+
+	assert.InDelta(t, 10000+trade.PnL, result.FinalEquity, 1e-9)
+	assert.Equal(t, 1, result.Wins)
+	assert.Equal(t, 100.0, result.WinRatePct)
+	assert.Greater(t, result.MaxDrawdown, 0.0) // Gave back open profit on the way out
+	assert.Len(t, result.EquityCurve, len(bars["AAPL"]))
+}
+
+func TestRun_StopLossStartsBucketCooldown(t *testing.T) {
+	// AAPL breaks out then collapses through its 2N stop; MSFT breaks out the same day
+	aapl := append(flat(25), 102, 90, 90)
+	msft := append(flat(25), 100, 102, 104)
+
+	cfg := testConfig()
+	cfg.Buckets = map[string]string{"AAPL": "Tech", "MSFT": "Tech"}
+
+	result, err := Run(cfg, map[string][]domain.Bar{
+		"AAPL": makeBars("2025-01-01", aapl...),
+		"MSFT": makeBars("2025-01-01", msft...),
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, result.Trades)
+	stopped := result.Trades[0]
+	assert.Equal(t, "AAPL", stopped.Ticker)
+	assert.Equal(t, ExitReasonStop, stopped.ExitReason)
+	assert.Equal(t, 90.0, stopped.ExitPrice) // Gapped below the stop, filled at the open
+	assert.Less(t, stopped.RMultiple, -1.0)
+
+	// MSFT's breakout on the loss day is blocked by the Tech cooldown
+	assert.Equal(t, 1, result.Rejections[RejectCooldown])
+	for _, trade := range result.Trades {
+		if trade.Ticker == "MSFT" {
+			assert.NotEqual(t, stopped.ExitDate, trade.EntryDate)
+		}
+	}
+}
+
+func TestRun_HeatCapRejectsEntries(t *testing.T) {
+	cfg := testConfig()
+	cfg.HeatCapPct = 0.015 // Room for one 1% trade only
+
+	bars := map[string][]domain.Bar{}
+	for _, ticker := range []string{"AAA", "BBB", "CCC"} {
+		bars[ticker] = makeBars("2025-01-01", breakoutRun()...)
+	}
+
+	result, err := Run(cfg, bars)
+	require.NoError(t, err)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, "AAA", result.Trades[0].Ticker)
+	// BBB and CCC are rejected on the breakout bar and every new high after it
+	assert.GreaterOrEqual(t, result.Rejections[RejectHeatPortfolio], 2)
+}
+
+func TestRun_StartAndEndWindow(t *testing.T) {
+	cfg := testConfig()
+	cfg.Start = "2025-01-27" // After the first breakout bar
+	cfg.End = "2025-02-03"
+
+	result, err := Run(cfg, map[string][]domain.Bar{"AAPL": makeBars("2025-01-01", breakoutRun()...)})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-27", result.StartDate)
+	assert.Equal(t, "2025-02-03", result.EndDate)
+	for _, trade := range result.Trades {
+		assert.GreaterOrEqual(t, trade.EntryDate, cfg.Start)
+		assert.LessOrEqual(t, trade.ExitDate, cfg.End)
+	}
+}
+
+func TestRun_ClosesOpenPositionsAtEnd(t *testing.T) {
+	closes := append(flat(25), 102, 104, 106)
+	result, err := Run(testConfig(), map[string][]domain.Bar{"AAPL": makeBars("2025-01-01", closes...)})
+	require.NoError(t, err)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, ExitReasonEnd, result.Trades[0].ExitReason)
+	assert.Equal(t, 106.0, result.Trades[0].ExitPrice)
+}
+
+func TestRun_Errors(t *testing.T) {
+	_, err := Run(testConfig(), map[string][]domain.Bar{})
+	assert.ErrorIs(t, err, ErrNoBars)
+
+	cfg := testConfig()
+	cfg.Equity = 0
+	_, err = Run(cfg, map[string][]domain.Bar{"AAPL": makeBars("2025-01-01", flat(30)...)})
+	assert.ErrorIs(t, err, domain.ErrInvalidEquity)
+}
+
+func TestCAGR(t *testing.T) {
+	assert.InDelta(t, 10.0, CAGR(10000, 11000, "2024-01-01", "2024-12-31"), 0.1)
+	assert.InDelta(t, 0.0, CAGR(10000, 11000, "2024-01-01", "2024-01-01"), 1e-9)
+	assert.Equal(t, 0.0, CAGR(10000, 0, "2024-01-01", "2025-01-01"))
+}
+
+func TestConfigFromSettings(t *testing.T) {
+	cfg := ConfigFromSettings(map[string]string{
+		"Equity_E":          "50000",
+		"RiskPct_r":         "0.005",
+		"HeatCap_H_pct":     "0.06",
+		"BucketHeatCap_pct": "0.02",
+		"StopMultiple_K":    "2.5",
+	})
+	assert.Equal(t, 50000.0, cfg.Equity)
+	assert.Equal(t, 0.005, cfg.RiskPct)
+	assert.Equal(t, 0.06, cfg.HeatCapPct)
+	assert.Equal(t, 0.02, cfg.BucketHeatCapPct)
+	assert.Equal(t, 2.5, cfg.K)
+	assert.Equal(t, domain.BreakoutSystemTwo, cfg.Params.System)
+
+	defaults := ConfigFromSettings(map[string]string{})
+	assert.Equal(t, 10000.0, defaults.Equity)
+	assert.Equal(t, 2.0, defaults.K)
+}
@@ -0,0 +1,504 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+)
+
+// Ranking keys for OptimizeOptions.RankBy
+const (
+	RankByMAR    = "mar"    // CAGR ÷ max drawdown (default)
+	RankByCAGR   = "cagr"   // Highest CAGR
+	RankByReturn = "return" // Highest total return
+)
+
+// minDrawdownPct keeps MAR finite for runs that never drew down
+const minDrawdownPct = 1.0
+
+// Optimizer errors
+var (
+	ErrEmptyGrid       = errors.New("parameter grid produced no combinations")
+	ErrNoWindows       = errors.New("date range too short for one walk-forward window")
+	ErrInvalidRankBy   = errors.New("rank-by must be 'mar', 'cagr' or 'return'")
+	ErrInvalidGridStep = errors.New("grid values must be positive")
+)
+
+// Grid lists the values to sweep; an empty list keeps the base config's value
+type Grid struct {
+	Ks                []float64 `json:"k,omitempty"`
+	RiskPcts          []float64 `json:"risk_pct,omitempty"`
+	Systems           []string  `json:"systems,omitempty"`
+	HeatCapPcts       []float64 `json:"heat_cap_pct,omitempty"`
+	BucketHeatCapPcts []float64 `json:"bucket_heat_cap_pct,omitempty"`
+}
+
+// Window is one walk-forward split: optimize on in-sample, verify on out-of-sample
+type Window struct {
+	InSampleStart  string `json:"in_sample_start"`
+	InSampleEnd    string `json:"in_sample_end"`
+	OutSampleStart string `json:"out_sample_start"`
+	OutSampleEnd   string `json:"out_sample_end"`
+}
+
+// Metrics are the headline statistics compared across combinations
+type Metrics struct {
+	TotalReturnPct float64 `json:"total_return_pct"`
+	CAGRPct        float64 `json:"cagr_pct"`
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"`
+	TradeCount     int     `json:"trade_count"`
+	WinRatePct     float64 `json:"win_rate_pct"`
+	MAR            float64 `json:"mar"`
+}
+
+// WindowResult holds one combination's metrics for one walk-forward window
+type WindowResult struct {
+	Window      Window  `json:"window"`
+	InSample    Metrics `json:"in_sample"`
+	OutOfSample Metrics `json:"out_of_sample"`
+	Selected    bool    `json:"selected,omitempty"` // Best in-sample score of the window
+}
+
+// Ranking is one row of the optimizer output
+type Ranking struct {
+	Rank             int            `json:"rank"`
+	System           string         `json:"system"`
+	K                float64        `json:"k"`
+	RiskPct          float64        `json:"risk_pct"`
+	HeatCapPct       float64        `json:"heat_cap_pct"`
+	BucketHeatCapPct float64        `json:"bucket_heat_cap_pct"`
+	InSample         Metrics        `json:"in_sample"`                  // Full period when not walking forward, else averaged across windows
+	OutOfSample      *Metrics       `json:"out_of_sample,omitempty"`    // Averaged across the windows it was selected in
+	Selected         int            `json:"selected_windows,omitempty"` // Windows it had the best in-sample score in
+	Windows          []WindowResult `json:"windows,omitempty"`
+}
+
+// OptimizeOptions controls how the sweep is run and ranked
+type OptimizeOptions struct {
+	Workers int      // Concurrent backtests (default: number of CPUs)
+	RankBy  string   // RankByMAR, RankByCAGR or RankByReturn
+	Windows []Window // Walk-forward splits; empty runs each combination once over Start..End
+}
+
+// Combinations expands a grid into one Config per combination
+// Systems are resolved with domain.GetBreakoutParams so "1"/"S1" work too.
+func Combinations(base Config, grid Grid) ([]Config, error) {
+	ks := grid.Ks
+	if len(ks) == 0 {
+		ks = []float64{base.K}
+	}
+	risks := grid.RiskPcts
+	if len(risks) == 0 {
+		risks = []float64{base.RiskPct}
+	}
+	heatCaps := grid.HeatCapPcts
+	if len(heatCaps) == 0 {
+		heatCaps = []float64{base.HeatCapPct}
+	}
+	bucketCaps := grid.BucketHeatCapPcts
+	if len(bucketCaps) == 0 {
+		bucketCaps = []float64{base.BucketHeatCapPct}
+	}
+	params := []domain.BreakoutParams{base.Params}
+	if len(grid.Systems) > 0 {
+		params = params[:0]
+		for _, system := range grid.Systems {
+			p, err := domain.GetBreakoutParams(system)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, p)
+		}
+	}
+
+	for _, values := range [][]float64{ks, risks, heatCaps, bucketCaps} {
+		for _, v := range values {
+			if v <= 0 {
+				return nil, fmt.Errorf("%w: got %g", ErrInvalidGridStep, v)
+			}
+		}
+	}
+
+	configs := []Config{}
+	for _, p := range params {
+		for _, k := range ks {
+			for _, risk := range risks {
+				for _, heatCap := range heatCaps {
+					for _, bucketCap := range bucketCaps {
+						cfg := base
+						cfg.Params = p
+						cfg.K = k
+						cfg.RiskPct = risk
+						cfg.HeatCapPct = heatCap
+						cfg.BucketHeatCapPct = bucketCap
+						configs = append(configs, cfg)
+					}
+				}
+			}
+		}
+	}
+	if len(configs) == 0 {
+		return nil, ErrEmptyGrid
+	}
+	return configs, nil
+}
+
+// WalkForwardWindows splits start..end into rolling in-sample/out-of-sample windows
+//
+// Each window optimizes on inMonths and tests on the following outMonths;
+// the next window starts outMonths later, so out-of-sample periods tile the
+// range without overlapping. The last out-of-sample period is cut at end.
+func WalkForwardWindows(start, end string, inMonths, outMonths int) ([]Window, error) {
+	if inMonths <= 0 || outMonths <= 0 {
+		return nil, fmt.Errorf("walk-forward months must be positive, got %d/%d", inMonths, outMonths)
+	}
+	from, err := time.Parse(dateLayout, start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q: %w", start, err)
+	}
+	to, err := time.Parse(dateLayout, end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date %q: %w", end, err)
+	}
+
+	windows := []Window{}
+	for isStart := from; ; isStart = isStart.AddDate(0, outMonths, 0) {
+		oosStart := isStart.AddDate(0, inMonths, 0)
+		if oosStart.After(to) {
+			break
+		}
+		oosEnd := oosStart.AddDate(0, outMonths, -1)
+		if oosEnd.After(to) {
+			oosEnd = to
+		}
+		windows = append(windows, Window{
+			InSampleStart:  isStart.Format(dateLayout),
+			InSampleEnd:    oosStart.AddDate(0, 0, -1).Format(dateLayout),
+			OutSampleStart: oosStart.Format(dateLayout),
+			OutSampleEnd:   oosEnd.Format(dateLayout),
+		})
+	}
+	if len(windows) == 0 {
+		return nil, ErrNoWindows
+	}
+	return windows, nil
+}
+
+// DateRange returns the first and last bar dates across all tickers
+func DateRange(bars map[string][]domain.Bar) (first, last string) {
+	for _, tickerBars := range bars {
+		if len(tickerBars) == 0 {
+			continue
+		}
+		if first == "" || tickerBars[0].Date < first {
+			first = tickerBars[0].Date
+		}
+		if d := tickerBars[len(tickerBars)-1].Date; d > last {
+			last = d
+		}
+	}
+	return first, last
+}
+
+// MetricsFromResult extracts the comparison metrics from a backtest result
+func MetricsFromResult(result *Result) Metrics {
+	return Metrics{
+		TotalReturnPct: result.TotalReturnPct,
+		CAGRPct:        result.CAGRPct,
+		MaxDrawdownPct: result.MaxDrawdownPct,
+		TradeCount:     result.TradeCount,
+		WinRatePct:     result.WinRatePct,
+		MAR:            result.CAGRPct / math.Max(result.MaxDrawdownPct, minDrawdownPct),
+	}
+}
+
+// job is one backtest in the sweep
+type job struct {
+	combo  int
+	window int // -1 for a single full-period run
+	oos    bool
+	cfg    Config
+}
+
+// Optimize runs every grid combination through Run concurrently and ranks them
+//
+// Without windows each combination is backtested once over base Start..End
+// and ranked on those metrics. With walk-forward windows each combination is
+// backtested on every in-sample and out-of-sample period; each window selects
+// the combination with the best in-sample score, and only those selections
+// are judged out of sample (see selectWalkForward). Combinations that were
+// selected rank first, on their out-of-sample averages (mean return, CAGR
+// and win rate, worst drawdown, total trades); the rest follow on their
+// in-sample averages.
+func Optimize(base Config, grid Grid, bars map[string][]domain.Bar, opts OptimizeOptions) ([]Ranking, error) {
+	if opts.RankBy == "" {
+		opts.RankBy = RankByMAR
+	}
+	if opts.RankBy != RankByMAR && opts.RankBy != RankByCAGR && opts.RankBy != RankByReturn {
+		return nil, fmt.Errorf("%w: got %q", ErrInvalidRankBy, opts.RankBy)
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	configs, err := Combinations(base, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []job{}
+	for i, cfg := range configs {
+		if len(opts.Windows) == 0 {
+			jobs = append(jobs, job{combo: i, window: -1, cfg: cfg})
+			continue
+		}
+		for w, window := range opts.Windows {
+			is, oos := cfg, cfg
+			is.Start, is.End = window.InSampleStart, window.InSampleEnd
+			oos.Start, oos.End = window.OutSampleStart, window.OutSampleEnd
+			jobs = append(jobs, job{combo: i, window: w, cfg: is}, job{combo: i, window: w, oos: true, cfg: oos})
+		}
+	}
+
+	metrics := make([]Metrics, len(jobs))
+	errs := make([]error, len(jobs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				result, err := Run(jobs[j].cfg, bars)
+				if err != nil {
+					errs[j] = err
+					continue
+				}
+				metrics[j] = MetricsFromResult(result)
+			}
+		}()
+	}
+	for j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	for j, err := range errs {
+		if err != nil && !errors.Is(err, ErrNoBars) {
+			return nil, fmt.Errorf("%s K=%g risk=%g: %w", jobs[j].cfg.Params.System, jobs[j].cfg.K, jobs[j].cfg.RiskPct, err)
+		}
+	}
+
+	rankings := make([]Ranking, len(configs))
+	for i, cfg := range configs {
+		rankings[i] = Ranking{
+			System:           cfg.Params.System,
+			K:                cfg.K,
+			RiskPct:          cfg.RiskPct,
+			HeatCapPct:       cfg.HeatCapPct,
+			BucketHeatCapPct: cfg.BucketHeatCapPct,
+		}
+		if len(opts.Windows) > 0 {
+			rankings[i].Windows = make([]WindowResult, len(opts.Windows))
+			for w, window := range opts.Windows {
+				rankings[i].Windows[w].Window = window
+			}
+		}
+	}
+	for j, jb := range jobs {
+		r := &rankings[jb.combo]
+		switch {
+		case jb.window < 0:
+			r.InSample = metrics[j]
+		case jb.oos:
+			r.Windows[jb.window].OutOfSample = metrics[j]
+		default:
+			r.Windows[jb.window].InSample = metrics[j]
+		}
+	}
+	if len(opts.Windows) > 0 {
+		selectWalkForward(rankings, opts.RankBy)
+	}
+
+	sortRankings(rankings, opts.RankBy)
+	return rankings, nil
+}
+
+// selectWalkForward picks, in every window, the combination with the best
+// in-sample score (the first in grid order on a tie) and averages each
+// combination's out-of-sample metrics over the windows it was picked in
+//
+// A combination never picked has no out-of-sample result: its out-of-sample
+// runs are kept per window but were not a choice the optimizer made.
+func selectWalkForward(rankings []Ranking, rankBy string) {
+	if len(rankings) == 0 {
+		return
+	}
+	for w := range rankings[0].Windows {
+		best := 0
+		for i := range rankings {
+			if metricScore(rankings[i].Windows[w].InSample, rankBy) > metricScore(rankings[best].Windows[w].InSample, rankBy) {
+				best = i
+			}
+		}
+		rankings[best].Windows[w].Selected = true
+	}
+
+	for i := range rankings {
+		r := &rankings[i]
+		is := make([]Metrics, 0, len(r.Windows))
+		oos := []Metrics{}
+		for _, wr := range r.Windows {
+			is = append(is, wr.InSample)
+			if wr.Selected {
+				oos = append(oos, wr.OutOfSample)
+			}
+		}
+		r.InSample = aggregate(is)
+		r.Selected = len(oos)
+		r.OutOfSample = nil
+		if len(oos) > 0 {
+			agg := aggregate(oos)
+			r.OutOfSample = &agg
+		}
+	}
+}
+
+// sortRankings orders rankings and numbers them: combinations with an
+// out-of-sample result first, each group by score
+func sortRankings(rankings []Ranking, rankBy string) {
+	sort.SliceStable(rankings, func(a, b int) bool {
+		ra, rb := rankings[a], rankings[b]
+		if (ra.OutOfSample != nil) != (rb.OutOfSample != nil) {
+			return ra.OutOfSample != nil
+		}
+		return score(ra, rankBy) > score(rb, rankBy)
+	})
+	for i := range rankings {
+		rankings[i].Rank = i + 1
+	}
+}
+
+// WalkForwardOutOfSample aggregates the out-of-sample metrics of the
+// combination selected in each window: how the walk-forward procedure
+// itself would have done
+func WalkForwardOutOfSample(rankings []Ranking) Metrics {
+	selected := []Metrics{}
+	for _, r := range rankings {
+		for _, wr := range r.Windows {
+			if wr.Selected {
+				selected = append(selected, wr.OutOfSample)
+			}
+		}
+	}
+	return aggregate(selected)
+}
+
+// aggregate combines per-window metrics: means, worst drawdown, total trades
+func aggregate(windows []Metrics) Metrics {
+	agg := Metrics{}
+	if len(windows) == 0 {
+		return agg
+	}
+	for _, m := range windows {
+		agg.TotalReturnPct += m.TotalReturnPct
+		agg.CAGRPct += m.CAGRPct
+		agg.WinRatePct += m.WinRatePct
+		agg.TradeCount += m.TradeCount
+		agg.MaxDrawdownPct = math.Max(agg.MaxDrawdownPct, m.MaxDrawdownPct)
+	}
+	n := float64(len(windows))
+	agg.TotalReturnPct /= n
+	agg.CAGRPct /= n
+	agg.WinRatePct /= n
+	agg.MAR = agg.CAGRPct / math.Max(agg.MaxDrawdownPct, minDrawdownPct)
+	return agg
+}
+
+// score picks the metric a ranking is sorted on
+func score(r Ranking, rankBy string) float64 {
+	m := r.InSample
+	if r.OutOfSample != nil {
+		m = *r.OutOfSample
+	}
+	return metricScore(m, rankBy)
+}
+
+// metricScore is the rankBy metric of m
+func metricScore(m Metrics, rankBy string) float64 {
+	switch rankBy {
+	case RankByCAGR:
+		return m.CAGRPct
+	case RankByReturn:
+		return m.TotalReturnPct
+	default:
+		return m.MAR
+	}
+}
+
+// WriteRankingsCSV writes the ranked table with a header row
+// Selection and out-of-sample columns are only written for walk-forward
+// rankings; out-of-sample values are blank for combinations never selected.
+func WriteRankingsCSV(w io.Writer, rankings []Ranking) error {
+	walkForward := len(rankings) > 0 && len(rankings[0].Windows) > 0
+
+	header := []string{"rank", "system", "k", "risk_pct", "heat_cap_pct", "bucket_heat_cap_pct"}
+	header = append(header, metricColumns("is_")...)
+	if walkForward {
+		header = append(header, "selected_windows")
+		header = append(header, metricColumns("oos_")...)
+		header = append(header, "windows")
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, r := range rankings {
+		row := []string{
+			fmt.Sprintf("%d", r.Rank),
+			r.System,
+			fmt.Sprintf("%g", r.K),
+			fmt.Sprintf("%g", r.RiskPct),
+			fmt.Sprintf("%g", r.HeatCapPct),
+			fmt.Sprintf("%g", r.BucketHeatCapPct),
+		}
+		row = append(row, metricValues(r.InSample)...)
+		if walkForward {
+			row = append(row, fmt.Sprintf("%d", r.Selected))
+			if r.OutOfSample != nil {
+				row = append(row, metricValues(*r.OutOfSample)...)
+			} else {
+				row = append(row, make([]string, len(metricColumns("")))...)
+			}
+			row = append(row, fmt.Sprintf("%d", len(r.Windows)))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func metricColumns(prefix string) []string {
+	return []string{prefix + "return_pct", prefix + "cagr_pct", prefix + "max_drawdown_pct",
+		prefix + "trades", prefix + "win_rate_pct", prefix + "mar"}
+}
+
+func metricValues(m Metrics) []string {
+	return []string{
+		fmt.Sprintf("%.2f", m.TotalReturnPct),
+		fmt.Sprintf("%.2f", m.CAGRPct),
+		fmt.Sprintf("%.2f", m.MaxDrawdownPct),
+		fmt.Sprintf("%d", m.TradeCount),
+		fmt.Sprintf("%.1f", m.WinRatePct),
+		fmt.Sprintf("%.3f", m.MAR),
+	}
+}
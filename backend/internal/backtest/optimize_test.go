@@ -0,0 +1,158 @@
+package backtest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/domain"
+)
+
+// sweepBars repeats the breakout/exit cycle so every combination trades
+func sweepBars() map[string][]domain.Bar {
+	closes := []float64{}
+	for cycle := 0; cycle < 4; cycle++ {
+		closes = append(closes, breakoutRun()...)
+	}
+	return map[string][]domain.Bar{
+		"AAPL": makeBars("2024-01-01", closes...),
+		"MSFT": makeBars("2024-01-03", closes...),
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	grid := Grid{
+		Ks:       []float64{1.5, 2, 2.5, 3},
+		RiskPcts: []float64{0.005, 0.0075, 0.01},
+		Systems:  []string{"SYSTEM_1", "SYSTEM_2"},
+	}
+	configs, err := Combinations(testConfig(), grid)
+	require.NoError(t, err)
+	assert.Len(t, configs, 24)
+	assert.Equal(t, domain.BreakoutSystemOne, configs[0].Params.System)
+	assert.Equal(t, 1.5, configs[0].K)
+	assert.Equal(t, 0.04, configs[0].HeatCapPct) // Not swept, kept from base
+
+	_, err = Combinations(testConfig(), Grid{Ks: []float64{0}})
+	assert.ErrorIs(t, err, ErrInvalidGridStep)
+
+	_, err = Combinations(testConfig(), Grid{Systems: []string{"SYSTEM_9"}})
+	assert.Error(t, err)
+}
+
+func TestWalkForwardWindows(t *testing.T) {
+	windows, err := WalkForwardWindows("2020-01-01", "2021-12-31", 12, 6)
+	require.NoError(t, err)
+	require.Len(t, windows, 2)
+	assert.Equal(t, Window{
+		InSampleStart:  "2020-01-01",
+		InSampleEnd:    "2020-12-31",
+		OutSampleStart: "2021-01-01",
+		OutSampleEnd:   "2021-06-30",
+	}, windows[0])
+	assert.Equal(t, "2021-07-01", windows[1].OutSampleStart)
+	assert.Equal(t, "2021-12-31", windows[1].OutSampleEnd)
+
+	_, err = WalkForwardWindows("2020-01-01", "2020-06-30", 12, 6)
+	assert.ErrorIs(t, err, ErrNoWindows)
+}
+
+func TestOptimize_RanksFullPeriod(t *testing.T) {
+	bars := sweepBars()
+	grid := Grid{Ks: []float64{1.5, 2, 3}, RiskPcts: []float64{0.005, 0.01}}
+
+	rankings, err := Optimize(testConfig(), grid, bars, OptimizeOptions{Workers: 3, RankBy: RankByCAGR})
+	require.NoError(t, err)
+	require.Len(t, rankings, 6)
+
+	for i, r := range rankings {
+		assert.Equal(t, i+1, r.Rank)
+		assert.Nil(t, r.OutOfSample)
+		assert.Greater(t, r.InSample.TradeCount, 0)
+		if i > 0 {
+			assert.GreaterOrEqual(t, rankings[i-1].InSample.CAGRPct, r.InSample.CAGRPct)
+		}
+	}
+
+	// Concurrent results match a direct run of the same config
+	cfg := testConfig()
+	cfg.K, cfg.RiskPct = rankings[0].K, rankings[0].RiskPct
+	direct, err := Run(cfg, bars)
+	require.NoError(t, err)
+	assert.Equal(t, MetricsFromResult(direct), rankings[0].InSample)
+}
+
+func TestOptimize_WalkForward(t *testing.T) {
+	bars := sweepBars()
+	first, last := DateRange(bars)
+	assert.Equal(t, "2024-01-01", first)
+
+	windows, err := WalkForwardWindows(first, last, 2, 1)
+	require.NoError(t, err)
+
+	rankings, err := Optimize(testConfig(), Grid{Ks: []float64{2, 3}}, bars, OptimizeOptions{Windows: windows})
+	require.NoError(t, err)
+	require.Len(t, rankings, 2)
+	selected := 0
+	for _, r := range rankings {
+		assert.Len(t, r.Windows, len(windows))
+		selected += r.Selected
+	}
+	assert.Equal(t, len(windows), selected) // One combination per window
+	require.NotNil(t, rankings[0].OutOfSample)
+	assert.Greater(t, rankings[0].Selected, 0)
+
+	_, err = Optimize(testConfig(), Grid{}, bars, OptimizeOptions{RankBy: "sharpe"})
+	assert.ErrorIs(t, err, ErrInvalidRankBy)
+}
+
+// The combination with the best in-sample score is the one judged out of
+// sample, even when another did better on the out-of-sample data
+func TestSelectWalkForward(t *testing.T) {
+	window := func(isMAR, oosMAR float64) WindowResult {
+		// No drawdown: MAR is CAGR ÷ 1%, as aggregate recomputes it
+		return WindowResult{InSample: Metrics{MAR: isMAR, CAGRPct: isMAR}, OutOfSample: Metrics{MAR: oosMAR, CAGRPct: oosMAR}}
+	}
+	rankings := []Ranking{
+		{K: 2, Windows: []WindowResult{window(3, 0.5), window(3, 0.5)}}, // Fits the past, fails after
+		{K: 3, Windows: []WindowResult{window(1, 4), window(1, 4)}},     // Best out of sample, never chosen
+		{K: 4, Windows: []WindowResult{window(2, 1), window(3.5, 1.5)}}, // Chosen in window 2
+	}
+
+	selectWalkForward(rankings, RankByMAR)
+	sortRankings(rankings, RankByMAR)
+
+	assert.Equal(t, []float64{4, 2, 3}, []float64{rankings[0].K, rankings[1].K, rankings[2].K})
+	assert.Equal(t, 1, rankings[0].Selected)
+	assert.Equal(t, 1.5, rankings[0].OutOfSample.MAR)
+	assert.Equal(t, 1, rankings[1].Selected)
+	assert.Equal(t, 0.5, rankings[1].OutOfSample.MAR)
+	assert.Nil(t, rankings[2].OutOfSample) // Ranked last despite its 4.0 out of sample
+	assert.Equal(t, 1.0, rankings[2].InSample.MAR)
+
+	assert.Equal(t, 1.0, WalkForwardOutOfSample(rankings).CAGRPct) // Mean of 0.5 and 1.5
+}
+
+func TestWriteRankingsCSV(t *testing.T) {
+	oos := Metrics{CAGRPct: 5, MaxDrawdownPct: 2, MAR: 2.5}
+	rankings := []Ranking{
+		{Rank: 1, System: "SYSTEM_2", K: 2.5, RiskPct: 0.0075, OutOfSample: &oos, Selected: 2,
+			Windows: []WindowResult{{Selected: true}, {Selected: true}}},
+		{Rank: 2, System: "SYSTEM_2", K: 3, RiskPct: 0.0075, Windows: []WindowResult{{}, {}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRankingsCSV(&buf, rankings))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "oos_mar", rows[0][len(rows[0])-2])
+	assert.Equal(t, "2.5", rows[1][2])
+	assert.Equal(t, "2.500", rows[1][len(rows[1])-2])
+	assert.Equal(t, "2", rows[1][len(rows[1])-1])
+	assert.Len(t, rows[2], len(rows[0])) // Never selected: blank out-of-sample columns
+	assert.Equal(t, "", rows[2][len(rows[2])-2])
+}
@@ -0,0 +1,86 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+)
+
+// LoadBars reads every ticker's bars from a provider, keyed by upper-case ticker
+func LoadBars(provider domain.BarProvider, tickers []string) (map[string][]domain.Bar, error) {
+	bars := make(map[string][]domain.Bar, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" {
+			continue
+		}
+		tickerBars, err := provider.GetDailyBars(ticker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bars for %s: %w", ticker, err)
+		}
+		bars[ticker] = tickerBars
+	}
+	return bars, nil
+}
+
+// ParseBuckets parses "AAPL=Tech/Comm,XOM=Energy" into a ticker -> bucket map
+func ParseBuckets(spec string) (map[string]string, error) {
+	buckets := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		ticker, bucket, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(ticker) == "" || strings.TrimSpace(bucket) == "" {
+			return nil, fmt.Errorf("invalid bucket mapping %q (use TICKER=Bucket)", pair)
+		}
+		buckets[strings.ToUpper(strings.TrimSpace(ticker))] = strings.TrimSpace(bucket)
+	}
+	return buckets, nil
+}
+
+// WriteTradesCSV writes the trade list as CSV with a header row
+func WriteTradesCSV(w io.Writer, trades []Trade) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ticker", "bucket", "direction", "entry_date", "entry_price", "shares", "atr_n",
+		"initial_stop", "risk_dollars", "exit_date", "exit_price", "exit_reason", "pnl", "r_multiple"})
+	for _, t := range trades {
+		cw.Write([]string{
+			t.Ticker, t.Bucket, t.Direction, t.EntryDate,
+			fmt.Sprintf("%.4f", t.EntryPrice),
+			fmt.Sprintf("%d", t.Shares),
+			fmt.Sprintf("%.4f", t.N),
+			fmt.Sprintf("%.4f", t.InitialStop),
+			fmt.Sprintf("%.2f", t.RiskDollars),
+			t.ExitDate,
+			fmt.Sprintf("%.4f", t.ExitPrice),
+			t.ExitReason,
+			fmt.Sprintf("%.2f", t.PnL),
+			fmt.Sprintf("%.2f", t.RMultiple),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEquityCSV writes the equity curve as CSV with a header row
+func WriteEquityCSV(w io.Writer, curve []EquityPoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "equity", "open_risk", "positions", "drawdown", "drawdown_pct"})
+	for _, p := range curve {
+		cw.Write([]string{
+			p.Date,
+			fmt.Sprintf("%.2f", p.Equity),
+			fmt.Sprintf("%.2f", p.OpenRisk),
+			fmt.Sprintf("%d", p.Positions),
+			fmt.Sprintf("%.2f", p.Drawdown),
+			fmt.Sprintf("%.2f", p.DrawdownPct),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,252 @@
+// Package broker imports executions from broker trade reports and applies
+// them to positions, so the journal records what was actually filled
+package broker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// DecisionWindowDays is how many days before a fill a GO decision may be
+// dated and still be matched to it
+const DecisionWindowDays = 5
+
+// Fill is one execution read from a broker report
+type Fill struct {
+	ExecID     string             `json:"exec_id"` // Broker execution id; re-imports are skipped by it
+	Account    string             `json:"account,omitempty"`
+	Ticker     string             `json:"ticker"`
+	AssetClass string             `json:"asset_class,omitempty"` // STK, OPT, ... (empty = stock)
+	Leg        *storage.OptionLeg `json:"leg,omitempty"`         // Option fills: the contract, with Ticker its underlying
+	Side       string             `json:"side"`                  // BUY or SELL
+	Quantity   int                `json:"quantity"`              // Shares, always positive
+	Price      float64            `json:"price"`
+	Commission float64            `json:"commission,omitempty"` // Paid, always positive
+	Date       string             `json:"date"`                 // YYYY-MM-DD
+	Time       string             `json:"time,omitempty"`       // HH:MM:SS
+	OpenClose  string             `json:"open_close,omitempty"` // O or C when the broker reports it
+	Source     string             `json:"source,omitempty"`     // Report file name
+}
+
+// FilledAt is the fill's date and time in local time (midnight when the
+// report has no time)
+func (f Fill) FilledAt() (time.Time, error) {
+	if f.Time == "" {
+		return time.ParseInLocation("2006-01-02", f.Date, time.Local)
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", f.Date+" "+f.Time, time.Local)
+}
+
+// FillResult is what an import did with one fill
+type FillResult struct {
+	storage.BrokerFill
+	Duplicate bool `json:"duplicate,omitempty"` // Already imported; left as it was
+}
+
+// ImportReport summarises an import
+type ImportReport struct {
+	Broker     string           `json:"broker"`
+	DryRun     bool             `json:"dry_run,omitempty"` // Nothing was changed (PreviewFills)
+	Fills      int              `json:"fills"`
+	Applied    int              `json:"applied"`
+	Unmatched  int              `json:"unmatched"`
+	Duplicates int              `json:"duplicates"`
+	Results    []FillResult     `json:"results"`
+	Changes    []PositionChange `json:"changes,omitempty"` // Dry runs: positions that would change
+}
+
+// ApplyFills applies broker fills to positions in trade order and records
+// each one in the fill log
+//
+//   - A fill on the closing side of an open position sells shares from it
+//     (FIFO), closing it when the last share goes
+//   - A fill on the same side as an open position adds a unit, moving every
+//     unit's stop to K × N from the fill unless that would loosen it
+//   - Any other opening fill opens a position from the latest unused GO
+//     decision in the same direction dated up to DecisionWindowDays before it
+//   - An option fill is never applied: options sessions open option
+//     positions and roll or expire close them. It is recorded as UNMATCHED
+//     with its leg and the option position it belongs to, for review
+//
+// Fills are applied at their own price, time and commission. Fills that
+// cannot be matched (no decision, more shares than are open, options, ...)
+// are recorded as UNMATCHED with the reason, for review.
+//
+// Re-running on the same report is safe: fills already applied or ignored
+// are skipped by execution id, and unmatched ones are tried again. Each fill
+// is logged as PENDING before it is applied; one left PENDING by an
+// interrupted import is skipped too, since it may already have changed its
+// position.
+func ApplyFills(db *storage.DB, broker string, fills []Fill) (*ImportReport, error) {
+	sorted := make([]Fill, len(fills))
+	copy(sorted, fills)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Date != sorted[j].Date {
+			return sorted[i].Date < sorted[j].Date
+		}
+		return sorted[i].Time < sorted[j].Time
+	})
+
+	k, err := stopMultiple(db)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Broker: broker, Fills: len(sorted), Results: []FillResult{}}
+	for _, fill := range sorted {
+		existing, err := db.GetBrokerFill(broker, fill.ExecID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.Status != storage.FillUnmatched {
+			report.Duplicates++
+			report.Results = append(report.Results, FillResult{BrokerFill: *existing, Duplicate: true})
+			continue
+		}
+
+		record := storage.BrokerFill{
+			Broker:     broker,
+			ExecID:     fill.ExecID,
+			Account:    fill.Account,
+			Ticker:     fill.Ticker,
+			AssetClass: fill.AssetClass,
+			Leg:        fill.Leg,
+			Side:       fill.Side,
+			Quantity:   fill.Quantity,
+			Price:      fill.Price,
+			Commission: fill.Commission,
+			TradeDate:  fill.Date,
+			TradeTime:  fill.Time,
+			Source:     fill.Source,
+		}
+		// Logged as pending first, so a fill interrupted while it is being
+		// applied is held for review rather than applied again
+		record.Status = storage.FillPending
+		if err := db.SaveBrokerFill(&record); err != nil {
+			return nil, err
+		}
+
+		action, positionID, detail, err := applyFill(db, fill, k)
+		if err != nil {
+			record.Status, record.Detail = storage.FillUnmatched, err.Error()
+			report.Unmatched++
+		} else {
+			record.Status, record.Action, record.PositionID, record.Detail = storage.FillApplied, action, positionID, detail
+			report.Applied++
+		}
+
+		if err := db.SaveBrokerFill(&record); err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, FillResult{BrokerFill: record})
+	}
+
+	return report, nil
+}
+
+// applyFill applies one fill, returning the action taken, the position it
+// was applied to and a description, or why it could not be matched
+func applyFill(db *storage.DB, fill Fill, k float64) (string, int, string, error) {
+	if fill.Quantity <= 0 || fill.Price <= 0 {
+		return "", 0, "", fmt.Errorf("fill needs a positive quantity and price")
+	}
+	if fill.Leg != nil {
+		return "", 0, "", optionFillReason(db, fill)
+	}
+	if fill.AssetClass != "" && fill.AssetClass != "STK" {
+		return "", 0, "", fmt.Errorf("%s fills are not matched automatically", fill.AssetClass)
+	}
+	filledAt, err := fill.FilledAt()
+	if err != nil {
+		return "", 0, "", fmt.Errorf("invalid fill date %q %q", fill.Date, fill.Time)
+	}
+
+	// A fill that opens a position is a BUY for a long, a SELL for a short
+	direction, err := domain.NormalizeDirection(fill.Side)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	position, err := db.FindOpenPosition(fill.Ticker)
+	if err != nil {
+		return "", 0, "", err
+	}
+	if position == nil {
+		if fill.OpenClose == "C" {
+			return "", 0, "", fmt.Errorf("no open %s position to close", fill.Ticker)
+		}
+		from := filledAt.AddDate(0, 0, -DecisionWindowDays).Format("2006-01-02")
+		decision, err := db.FindDecisionForFill(fill.Ticker, direction, from, fill.Date)
+		if err != nil {
+			return "", 0, "", err
+		}
+		if decision == nil {
+			return "", 0, "", fmt.Errorf("no open %s position and no unused %s GO decision from %s to %s",
+				fill.Ticker, direction, from, fill.Date)
+		}
+		opened, err := db.OpenPositionFromFill(decision, fill.Quantity, fill.Price, fill.Commission, filledAt)
+		if err != nil {
+			return "", 0, "", err
+		}
+		return storage.FillActionOpen, opened.ID,
+			fmt.Sprintf("opened %d @ %.2f from the %s decision, stop %.2f", fill.Quantity, fill.Price, decision.Date, opened.CurrentStop), nil
+	}
+
+	if position.InstrumentType == storage.InstrumentOption {
+		return "", 0, "", fmt.Errorf("open %s position is an option position", fill.Ticker)
+	}
+	if fill.Date < position.TradeDate() {
+		return "", 0, "", fmt.Errorf("fill is dated before the open %s position (entered %s)", fill.Ticker, position.TradeDate())
+	}
+
+	if direction != position.Direction {
+		if fill.Quantity > position.Shares {
+			return "", 0, "", fmt.Errorf("fill of %d shares exceeds the %d open in %s", fill.Quantity, position.Shares, fill.Ticker)
+		}
+		result, err := db.ExitPositionFill(position.ID, fill.Quantity, fill.Price, fill.Commission, filledAt)
+		if err != nil {
+			return "", 0, "", err
+		}
+		if result.Closed {
+			return storage.FillActionClose, position.ID,
+				fmt.Sprintf("closed %d @ %.2f, P&L %.2f (%s)", fill.Quantity, fill.Price, result.TotalPnL, result.Outcome), nil
+		}
+		return storage.FillActionExit, position.ID,
+			fmt.Sprintf("exited %d @ %.2f, %d left", fill.Quantity, fill.Price, result.RemainingShares), nil
+	}
+
+	// Add-on: the Turtle stop moves to K × N from the new fill, never looser
+	stop := position.CurrentStop
+	if position.ATR > 0 && k > 0 {
+		addStop := domain.StopFor(position.Direction, fill.Price, k*position.ATR)
+		if domain.DirectionSign(position.Direction)*(addStop-stop) > 0 {
+			stop = addStop
+		}
+	}
+	if _, err := db.AddUnit(position.ID, storage.UnitFill{
+		Price:      fill.Price,
+		Shares:     fill.Quantity,
+		NewStop:    stop,
+		Commission: fill.Commission,
+		FilledAt:   filledAt,
+	}); err != nil {
+		return "", 0, "", err
+	}
+	return storage.FillActionAdd, position.ID,
+		fmt.Sprintf("added %d @ %.2f, stop %.2f", fill.Quantity, fill.Price, stop), nil
+}
+
+// stopMultiple reads the StopMultiple_K setting
+func stopMultiple(db *storage.DB) (float64, error) {
+	kStr, err := db.GetSetting(string(domain.SettingStopMultiple))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get K multiple from database: %w", err)
+	}
+	var k float64
+	fmt.Sscanf(kStr, "%f", &k)
+	return k, nil
+}
@@ -0,0 +1,146 @@
+package broker
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestApplyFills(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	// Planned: AAPL long at 100 with N = 2 and a 2N stop
+	_, err = db.SaveDecision(storage.Decision{
+		Date: "2025-01-02", Ticker: "AAPL", Action: "GO", Entry: 100, ATR: 2,
+		StopDistance: 4, InitialStop: 96, Shares: 50, RiskDollars: 200,
+		Banner: "GREEN", Method: "stock",
+	})
+	require.NoError(t, err)
+
+	fills, err := ParseFlexFile("testdata/flex_trades.xml")
+	require.NoError(t, err)
+
+	report, err := ApplyFills(db, BrokerIBKR, fills)
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Fills)
+	assert.Equal(t, 4, report.Applied)
+	assert.Equal(t, 2, report.Unmatched) // MSFT without a decision, the option
+	assert.Zero(t, report.Duplicates)
+
+	actions := map[string]string{}
+	for _, r := range report.Results {
+		actions[r.ExecID] = r.Action
+	}
+	assert.Equal(t, storage.FillActionOpen, actions["0000e0d5.6575c7a0.01.01"])
+	assert.Equal(t, storage.FillActionAdd, actions["0000e0d5.6575c7a2.01.01"])
+	assert.Equal(t, storage.FillActionExit, actions["0000e0d5.6575c7a4.01.01"])
+	assert.Equal(t, storage.FillActionClose, actions["0000e0d5.6575c7a5.01.01"])
+
+	closed, err := db.GetAllPositions("CLOSED")
+	require.NoError(t, err)
+	require.Len(t, closed, 1)
+	aapl := closed[0]
+	assert.Equal(t, "2025-01-03", aapl.EntryDate)
+	assert.Equal(t, 101.25, aapl.EntryPrice) // Average of the two units
+	// The add-on moved the stop to 2N below its fill: 102 - 4
+	assert.Equal(t, 98.0, aapl.CurrentStop)
+	assert.Equal(t, 4.0, aapl.Commissions)
+	// 50 × 4.50 + 50 × 2.00 gross, less $4 of commissions
+	assert.InDelta(t, 321, aapl.PnL, 1e-9)
+
+	// Re-running the same report changes nothing
+	report, err = ApplyFills(db, BrokerIBKR, fills)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Duplicates)
+	assert.Equal(t, 2, report.Unmatched)
+	assert.Zero(t, report.Applied)
+
+	// Unmatched fills are retried once the decision exists
+	_, err = db.SaveDecision(storage.Decision{
+		Date: "2025-01-03", Ticker: "MSFT", Action: "GO", Entry: 418, ATR: 8,
+		StopDistance: 16, InitialStop: 402, Shares: 10, RiskDollars: 160,
+		Banner: "GREEN", Method: "stock",
+	})
+	require.NoError(t, err)
+	report, err = ApplyFills(db, BrokerIBKR, fills)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Applied)
+	assert.Equal(t, 1, report.Unmatched)
+
+	msft, err := db.GetPositionByTicker("MSFT")
+	require.NoError(t, err)
+	assert.Equal(t, 404.0, msft.CurrentStop) // Decision's 16.00 distance from the 420 fill
+
+	unmatched, err := db.ListBrokerFills(storage.FillUnmatched)
+	require.NoError(t, err)
+	require.Len(t, unmatched, 1)
+	assert.Equal(t, "OPT", unmatched[0].AssetClass)
+}
+
+func TestApplyFills_Unmatched(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	report, err := ApplyFills(db, BrokerIBKR, []Fill{
+		{ExecID: "1", Ticker: "NVDA", Side: "SELL", Quantity: 10, Price: 130, Date: "2025-01-03", OpenClose: "C"},
+		{ExecID: "2", Ticker: "NVDA", Side: "BUY", Quantity: 10, Price: 130, Date: "2025-01-03"},
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, 2, report.Unmatched)
+	assert.Contains(t, report.Results[0].Detail, "no open NVDA position to close")
+	assert.Contains(t, report.Results[1].Detail, "no unused LONG GO decision")
+}
+
+func TestApplyFills_PendingIsNotReapplied(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	_, err = db.SaveDecision(storage.Decision{
+		Date: "2025-01-02", Ticker: "AAPL", Action: "GO", Entry: 100, ATR: 2,
+		StopDistance: 4, InitialStop: 96, Shares: 50, RiskDollars: 200,
+		Banner: "GREEN", Method: "stock",
+	})
+	require.NoError(t, err)
+
+	// An import stopped after logging the fill, before recording the result
+	fill := Fill{ExecID: "1", Ticker: "AAPL", Side: "BUY", Quantity: 50, Price: 100, Date: "2025-01-03"}
+	require.NoError(t, db.SaveBrokerFill(&storage.BrokerFill{
+		Broker: BrokerIBKR, ExecID: "1", Ticker: "AAPL", Side: "BUY", Quantity: 50, Price: 100,
+		TradeDate: "2025-01-03", Status: storage.FillPending,
+	}))
+
+	report, err := ApplyFills(db, BrokerIBKR, []Fill{fill})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Zero(t, report.Applied)
+	positions, err := db.GetAllPositions("")
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	// Once reviewed it stays out of later imports
+	pending, err := db.ListBrokerFills(storage.FillPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.NoError(t, db.IgnoreBrokerFill(pending[0].ID, "entered by hand"))
+
+	// A completed import leaves nothing pending
+	fill.ExecID = "2"
+	report, err = ApplyFills(db, BrokerIBKR, []Fill{fill})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Applied)
+	pending, err = db.ListBrokerFills(storage.FillPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
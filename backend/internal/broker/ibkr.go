@@ -0,0 +1,358 @@
+package broker
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BrokerIBKR is the fill log name of Interactive Brokers imports
+const BrokerIBKR = "IBKR"
+
+// Flex Query report formats
+const (
+	FormatXML = "xml"
+	FormatCSV = "csv"
+)
+
+// flexTrade is one row of the Trades section of a Flex Query report, by the
+// report's field names
+type flexTrade struct {
+	Account       string `xml:"accountId,attr"`
+	AssetCategory string `xml:"assetCategory,attr"`
+	Symbol        string `xml:"symbol,attr"`
+	TradeID       string `xml:"tradeID,attr"`
+	IBExecID      string `xml:"ibExecID,attr"`
+	TradeDate     string `xml:"tradeDate,attr"`
+	DateTime      string `xml:"dateTime,attr"`
+	Quantity      string `xml:"quantity,attr"`
+	TradePrice    string `xml:"tradePrice,attr"`
+	IBCommission  string `xml:"ibCommission,attr"`
+	BuySell       string `xml:"buySell,attr"`
+	OpenClose     string `xml:"openCloseIndicator,attr"`
+	LevelOfDetail string `xml:"levelOfDetail,attr"`
+}
+
+// ParseFlexFile reads the trades of an IBKR Flex Query report saved as XML
+// or CSV, telling the two apart by extension or content
+func ParseFlexFile(path string) ([]Fill, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	fills, err := ParseFlex(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	for i := range fills {
+		fills[i].Source = filepath.Base(path)
+	}
+	return fills, nil
+}
+
+// ParseFlex reads the trades of an IBKR Flex Query report in format (xml or
+// csv; anything else is detected from the content)
+func ParseFlex(data []byte, format string) ([]Fill, error) {
+	if format != FormatXML && format != FormatCSV {
+		format = FormatCSV
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+			format = FormatXML
+		}
+	}
+	if format == FormatXML {
+		return ParseFlexXML(bytes.NewReader(data))
+	}
+	return ParseFlexCSV(bytes.NewReader(data))
+}
+
+// ParseFlexXML reads the <Trade> elements of a Flex Query XML report
+//
+// Only execution-level rows are read; order and closed-lot summaries of
+// the same trades are skipped.
+func ParseFlexXML(r io.Reader) ([]Fill, error) {
+	decoder := xml.NewDecoder(r)
+	fills := []Fill{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Flex XML: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Trade" {
+			continue
+		}
+
+		var trade flexTrade
+		if err := decoder.DecodeElement(&trade, &start); err != nil {
+			return nil, fmt.Errorf("invalid Flex trade: %w", err)
+		}
+		fill, ok, err := trade.fill()
+		if err != nil {
+			return nil, fmt.Errorf("trade %s: %w", trade.id(), err)
+		}
+		if ok {
+			fills = append(fills, fill)
+		}
+	}
+	return fills, nil
+}
+
+// flexColumns maps Flex CSV headers (lower case, letters and digits only)
+// to trade fields
+var flexColumns = map[string]func(*flexTrade) *string{
+	"accountid":          func(t *flexTrade) *string { return &t.Account },
+	"clientaccountid":    func(t *flexTrade) *string { return &t.Account },
+	"assetclass":         func(t *flexTrade) *string { return &t.AssetCategory },
+	"assetcategory":      func(t *flexTrade) *string { return &t.AssetCategory },
+	"symbol":             func(t *flexTrade) *string { return &t.Symbol },
+	"tradeid":            func(t *flexTrade) *string { return &t.TradeID },
+	"ibexecid":           func(t *flexTrade) *string { return &t.IBExecID },
+	"tradedate":          func(t *flexTrade) *string { return &t.TradeDate },
+	"datetime":           func(t *flexTrade) *string { return &t.DateTime },
+	"quantity":           func(t *flexTrade) *string { return &t.Quantity },
+	"tradeprice":         func(t *flexTrade) *string { return &t.TradePrice },
+	"ibcommission":       func(t *flexTrade) *string { return &t.IBCommission },
+	"buysell":            func(t *flexTrade) *string { return &t.BuySell },
+	"opencloseindicator": func(t *flexTrade) *string { return &t.OpenClose },
+	"levelofdetail":      func(t *flexTrade) *string { return &t.LevelOfDetail },
+}
+
+// ParseFlexCSV reads a Flex Query CSV report of trades
+//
+// Headers are matched case- and punctuation-insensitively ("Buy/Sell",
+// "IBCommission", ...). Reports of several accounts repeat the header
+// row, and reports with header and trailer records (BOF, HEADER, DATA,
+// EOF, ...) are read from their HEADER and DATA rows.
+func ParseFlexCSV(r io.Reader) ([]Fill, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	fills := []Fill{}
+	var header []string
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Flex CSV: %w", err)
+		}
+		line++
+
+		switch strings.TrimSpace(record[0]) {
+		case "BOF", "BOA", "BOS", "EOS", "EOA", "EOF":
+			continue
+		case "HEADER":
+			header = normalizeHeaders(record[1:])
+			continue
+		case "DATA":
+			record = record[1:]
+		default:
+			if headers := normalizeHeaders(record); containsHeader(headers, "symbol") {
+				header = headers
+				continue
+			}
+		}
+		if header == nil {
+			return nil, fmt.Errorf("line %d: no header row before the first trade", line)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		var trade flexTrade
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			if field, ok := flexColumns[header[i]]; ok {
+				*field(&trade) = strings.TrimSpace(value)
+			}
+		}
+		fill, ok, err := trade.fill()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			fills = append(fills, fill)
+		}
+	}
+	if header == nil {
+		return nil, fmt.Errorf("no trades header found (expected a Symbol column)")
+	}
+	return fills, nil
+}
+
+// normalizeHeaders lower-cases headers and drops everything but letters
+// and digits
+func normalizeHeaders(record []string) []string {
+	headers := make([]string, len(record))
+	for i, h := range record {
+		headers[i] = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+				return r
+			case r >= 'A' && r <= 'Z':
+				return r + 'a' - 'A'
+			}
+			return -1
+		}, h)
+	}
+	return headers
+}
+
+func containsHeader(headers []string, name string) bool {
+	for _, h := range headers {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
+
+// id names a trade in error messages
+func (t flexTrade) id() string {
+	if t.IBExecID != "" {
+		return t.IBExecID
+	}
+	if t.TradeID != "" {
+		return t.TradeID
+	}
+	return t.Symbol
+}
+
+// fill converts a Flex trade row, reporting false for rows that are not
+// executions (order summaries, cancellations)
+func (t flexTrade) fill() (Fill, bool, error) {
+	if level := strings.ToUpper(t.LevelOfDetail); level != "" && level != "EXECUTION" {
+		return Fill{}, false, nil
+	}
+	side := strings.ToUpper(t.BuySell)
+	if strings.Contains(side, "(CA.)") {
+		return Fill{}, false, nil // Cancelled by the broker
+	}
+	if t.Symbol == "" {
+		return Fill{}, false, fmt.Errorf("missing symbol")
+	}
+
+	quantity, err := strconv.ParseFloat(strings.ReplaceAll(t.Quantity, ",", ""), 64)
+	if err != nil || quantity == 0 {
+		return Fill{}, false, fmt.Errorf("invalid quantity %q", t.Quantity)
+	}
+	if quantity != math.Trunc(quantity) {
+		return Fill{}, false, fmt.Errorf("fractional quantity %s is not supported", t.Quantity)
+	}
+	price, err := strconv.ParseFloat(strings.ReplaceAll(t.TradePrice, ",", ""), 64)
+	if err != nil || price <= 0 {
+		return Fill{}, false, fmt.Errorf("invalid trade price %q", t.TradePrice)
+	}
+	var commission float64
+	if t.IBCommission != "" {
+		if commission, err = strconv.ParseFloat(t.IBCommission, 64); err != nil {
+			return Fill{}, false, fmt.Errorf("invalid commission %q", t.IBCommission)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(side, "BUY"):
+		side = "BUY"
+	case strings.HasPrefix(side, "SELL"):
+		side = "SELL"
+	case quantity > 0:
+		side = "BUY"
+	default:
+		side = "SELL"
+	}
+
+	date, clock, err := parseFlexDateTime(t.DateTime)
+	if err != nil || date == "" {
+		if date, err = parseFlexDate(t.TradeDate); err != nil {
+			return Fill{}, false, err
+		}
+	}
+
+	fill := Fill{
+		ExecID:     t.IBExecID,
+		Account:    t.Account,
+		Ticker:     strings.ToUpper(t.Symbol),
+		AssetClass: strings.ToUpper(t.AssetCategory),
+		Side:       side,
+		Quantity:   int(math.Abs(quantity)),
+		Price:      price,
+		Commission: math.Abs(commission),
+		Date:       date,
+		Time:       clock,
+		OpenClose:  strings.ToUpper(strings.TrimSpace(t.OpenClose)),
+	}
+	if fill.AssetClass == "OPT" {
+		if underlying, leg, ok := ParseOptionSymbol(t.Symbol); ok {
+			leg.Qty, leg.Action, leg.Price = fill.Quantity, fill.Side, fill.Price
+			fill.Ticker, fill.Leg = underlying, &leg
+		}
+	}
+	if fill.ExecID == "" {
+		fill.ExecID = t.TradeID
+	}
+	if fill.ExecID == "" {
+		fill.ExecID = syntheticExecID(fill)
+	}
+	return fill, true, nil
+}
+
+// syntheticExecID identifies a fill from a report without execution ids by
+// its own details
+func syntheticExecID(fill Fill) string {
+	ticker := fill.Ticker
+	if fill.Leg != nil {
+		ticker = fmt.Sprintf("%s %s %.2f %s", fill.Ticker, fill.Leg.Exp, fill.Leg.Strike, fill.Leg.Type)
+	}
+	return fmt.Sprintf("%s/%s/%s %s/%s/%d/%.4f",
+		fill.Account, ticker, fill.Date, fill.Time, fill.Side, fill.Quantity, fill.Price)
+}
+
+// parseFlexDate reads a Flex date (20250102 or 2025-01-02) as YYYY-MM-DD
+func parseFlexDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"20060102", "2006-01-02"} {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid trade date %q", s)
+}
+
+// parseFlexDateTime splits a Flex date/time ("20250102;093512",
+// "2025-01-02, 09:35:12", ...) into YYYY-MM-DD and HH:MM:SS
+func parseFlexDateTime(s string) (string, string, error) {
+	parts := strings.Fields(strings.NewReplacer(";", " ", ",", " ", "T", " ").Replace(s))
+	if len(parts) == 0 {
+		return "", "", nil
+	}
+	date, err := parseFlexDate(parts[0])
+	if err != nil {
+		return "", "", err
+	}
+	if len(parts) == 1 {
+		return date, "", nil
+	}
+	clock, err := time.Parse("150405", strings.ReplaceAll(parts[1], ":", ""))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid trade time %q", parts[1])
+	}
+	return date, clock.Format("15:04:05"), nil
+}
@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlexFile_XML(t *testing.T) {
+	fills, err := ParseFlexFile("testdata/flex_trades.xml")
+	require.NoError(t, err)
+	// The ORDER summary row is skipped
+	require.Len(t, fills, 6)
+
+	first := fills[0]
+	assert.Equal(t, "0000e0d5.6575c7a0.01.01", first.ExecID)
+	assert.Equal(t, "U1234567", first.Account)
+	assert.Equal(t, "AAPL", first.Ticker)
+	assert.Equal(t, "STK", first.AssetClass)
+	assert.Equal(t, "BUY", first.Side)
+	assert.Equal(t, 50, first.Quantity)
+	assert.Equal(t, 100.5, first.Price)
+	assert.Equal(t, 1.0, first.Commission)
+	assert.Equal(t, "2025-01-03", first.Date)
+	assert.Equal(t, "09:35:12", first.Time)
+	assert.Equal(t, "O", first.OpenClose)
+	assert.Equal(t, "flex_trades.xml", first.Source)
+
+	sell := fills[4]
+	assert.Equal(t, "SELL", sell.Side)
+	assert.Equal(t, 50, sell.Quantity)
+	assert.Equal(t, "C", sell.OpenClose)
+}
+
+func TestParseFlexFile_CSV(t *testing.T) {
+	fills, err := ParseFlexFile("testdata/flex_trades.csv")
+	require.NoError(t, err)
+	// The cancellation is skipped; the repeated header starts a second account
+	require.Len(t, fills, 3)
+
+	assert.Equal(t, "0000e0d5.6575c7a0.01.01", fills[0].ExecID)
+	assert.Equal(t, "SELL", fills[1].Side)
+	assert.Equal(t, 50, fills[1].Quantity)
+
+	// No ids, side or time stamp separators: the side comes from the
+	// quantity sign and the id from the fill itself
+	spy := fills[2]
+	assert.Equal(t, "U7654321", spy.Account)
+	assert.Equal(t, "BUY", spy.Side)
+	assert.Equal(t, 1000, spy.Quantity)
+	assert.Equal(t, "2025-01-09", spy.Date)
+	assert.Equal(t, "10:15:00", spy.Time)
+	assert.NotEmpty(t, spy.ExecID)
+}
+
+func TestParseFlexCSV_HeaderTrailerRecords(t *testing.T) {
+	data := `"BOF","U1234567","Trades","1","20250102","20250110"
+"HEADER","TRNT","Symbol","IBExecID","TradeDate","Quantity","TradePrice","IBCommission"
+"DATA","TRNT","AAPL","e1","20250103","50","100.5","-1"
+"EOF","1"
+`
+	fills, err := ParseFlexCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, fills, 1)
+	assert.Equal(t, "e1", fills[0].ExecID)
+	assert.Equal(t, "2025-01-03", fills[0].Date)
+	assert.Empty(t, fills[0].Time)
+}
+
+func TestParseFlex_Errors(t *testing.T) {
+	_, err := ParseFlex([]byte("Date,Price\n2025-01-02,1\n"), "")
+	assert.ErrorContains(t, err, "header")
+
+	_, err = ParseFlex([]byte("Symbol,Quantity,TradePrice,TradeDate\nAAPL,1.5,100,20250103\n"), FormatCSV)
+	assert.ErrorContains(t, err, "fractional")
+
+	_, err = ParseFlex([]byte(`<Trades><Trade symbol="AAPL" quantity="5" tradePrice="100" tradeDate="yesterday"/></Trades>`), "")
+	assert.ErrorContains(t, err, "trade date")
+
+	_, err = ParseFlex([]byte(`<Trades><Trade`), FormatXML)
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+var (
+	// OCC symbology: root, YYMMDD, C/P, strike × 1000 in 8 digits
+	// ("AAPL  250117C00110000"); exports that drop the padding or write the
+	// strike as a plain number ("-AAPL250117C110") are read the same way
+	occSymbol = regexp.MustCompile(`^([A-Z][A-Z0-9.]{0,5})(\d{6})([CP])(\d+(?:\.\d+)?)$`)
+
+	// Spelled out: "AAPL 01/17/2025 110.00 C"
+	spelledSymbol = regexp.MustCompile(`^([A-Z][A-Z0-9.]*) (\d{2}/\d{2}/\d{4}) (\d+(?:\.\d+)?) ([CP])$`)
+)
+
+// ParseOptionSymbol reads an option contract symbol into its underlying and
+// a leg with the type, strike and expiration set, reporting false for
+// anything else (stock tickers)
+func ParseOptionSymbol(symbol string) (string, storage.OptionLeg, bool) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+	s = strings.TrimLeft(s, "-.")
+
+	if m := spelledSymbol.FindStringSubmatch(strings.Join(strings.Fields(s), " ")); m != nil {
+		exp, err := time.Parse("01/02/2006", m[2])
+		strike, serr := strconv.ParseFloat(m[3], 64)
+		if err != nil || serr != nil || strike <= 0 {
+			return "", storage.OptionLeg{}, false
+		}
+		return m[1], storage.OptionLeg{Type: optionType(m[4]), Strike: strike, Exp: exp.Format("2006-01-02")}, true
+	}
+
+	m := occSymbol.FindStringSubmatch(strings.ReplaceAll(s, " ", ""))
+	if m == nil {
+		return "", storage.OptionLeg{}, false
+	}
+	exp, err := time.Parse("060102", m[2])
+	if err != nil {
+		return "", storage.OptionLeg{}, false
+	}
+	strike, err := strconv.ParseFloat(m[4], 64)
+	if err != nil || strike <= 0 {
+		return "", storage.OptionLeg{}, false
+	}
+	if len(m[4]) == 8 && !strings.Contains(m[4], ".") {
+		strike /= 1000 // OCC: strike in thousandths
+	}
+	return m[1], storage.OptionLeg{Type: optionType(m[3]), Strike: strike, Exp: exp.Format("2006-01-02")}, true
+}
+
+func optionType(cp string) string {
+	if cp == "P" {
+		return "PUT"
+	}
+	return "CALL"
+}
+
+// describeLeg names a leg for fill details: "BUY 2 AAPL 2025-01-17 110.00 CALL"
+func describeLeg(underlying string, leg storage.OptionLeg) string {
+	return fmt.Sprintf("%s %d %s %s %.2f %s", leg.Action, leg.Qty, underlying, leg.Exp, leg.Strike, leg.Type)
+}
+
+// optionFillReason says why an option fill is left for review
+//
+// Option positions are opened from an options session (which sizes them
+// by max loss) and closed with roll or expire (which book the legs' P&L
+// and trade_history), so the importer never opens, adds or closes legs
+// itself. Every option fill is recorded UNMATCHED with its parsed leg; the
+// reason names the open option position it belongs to, if any, so it can
+// be checked and marked reviewed.
+func optionFillReason(db *storage.DB, fill Fill) error {
+	leg := *fill.Leg
+	position, err := db.FindOpenPosition(fill.Ticker)
+	if err != nil {
+		return err
+	}
+	if position == nil || position.InstrumentType != storage.InstrumentOption {
+		return fmt.Errorf("option leg %s: no open %s option position (open it from an options session)",
+			describeLeg(fill.Ticker, leg), fill.Ticker)
+	}
+
+	var legs []storage.OptionLeg
+	if err := json.Unmarshal([]byte(position.LegsJSON), &legs); err != nil {
+		return fmt.Errorf("option position #%d has no readable legs: %w", position.ID, err)
+	}
+	for _, l := range legs {
+		if l.Type != leg.Type || l.Strike != leg.Strike || l.Exp != leg.Exp {
+			continue
+		}
+		if l.Action != leg.Action {
+			return fmt.Errorf("option leg %s closes a leg of option position #%d (record it with roll or expire)",
+				describeLeg(fill.Ticker, leg), position.ID)
+		}
+		return fmt.Errorf("option leg %s is an entry leg of option position #%d (entered from its options session)",
+			describeLeg(fill.Ticker, leg), position.ID)
+	}
+	return fmt.Errorf("option leg %s is not a leg of option position #%d",
+		describeLeg(fill.Ticker, leg), position.ID)
+}
@@ -0,0 +1,102 @@
+package broker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// PositionState is what an import can change about a position
+type PositionState struct {
+	Status      string  `json:"status"`
+	Shares      int     `json:"shares"`
+	EntryPrice  float64 `json:"entry_price"`
+	CurrentStop float64 `json:"current_stop"`
+	PnL         float64 `json:"pnl"`
+	Commissions float64 `json:"commissions,omitempty"`
+}
+
+// PositionChange is one position an import would open or change
+type PositionChange struct {
+	PositionID int            `json:"position_id"`
+	Ticker     string         `json:"ticker"`
+	Before     *PositionState `json:"before,omitempty"` // Nil for a new position
+	After      PositionState  `json:"after"`
+}
+
+// PreviewFills reports what ApplyFills would do without changing the
+// database: the fills are applied to a snapshot of it, and the report
+// lists every position they would open or change
+func PreviewFills(db *storage.DB, broker string, fills []Fill) (*ImportReport, error) {
+	dir, err := os.MkdirTemp("", "tf-fills-preview")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preview folder: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "preview.db")
+	if err := db.Snapshot(path); err != nil {
+		return nil, err
+	}
+	preview, err := storage.New(path)
+	if err != nil {
+		return nil, err
+	}
+	defer preview.Close()
+
+	before, err := db.GetAllPositions("")
+	if err != nil {
+		return nil, err
+	}
+	report, err := ApplyFills(preview, broker, fills)
+	if err != nil {
+		return nil, err
+	}
+	after, err := preview.GetAllPositions("")
+	if err != nil {
+		return nil, err
+	}
+
+	report.DryRun = true
+	report.Changes = DiffPositions(before, after)
+	return report, nil
+}
+
+// DiffPositions lists the positions in after that are new or differ from
+// before, in id order
+func DiffPositions(before, after []storage.Position) []PositionChange {
+	old := make(map[int]PositionState, len(before))
+	for _, p := range before {
+		old[p.ID] = positionState(p)
+	}
+
+	changes := []PositionChange{}
+	for _, p := range after {
+		state := positionState(p)
+		prev, ok := old[p.ID]
+		if ok && prev == state {
+			continue
+		}
+		change := PositionChange{PositionID: p.ID, Ticker: p.Ticker, After: state}
+		if ok {
+			change.Before = &prev
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].PositionID < changes[j].PositionID })
+	return changes
+}
+
+func positionState(p storage.Position) PositionState {
+	return PositionState{
+		Status:      p.Status,
+		Shares:      p.Shares,
+		EntryPrice:  p.EntryPrice,
+		CurrentStop: p.CurrentStop,
+		PnL:         p.PnL,
+		Commissions: p.Commissions,
+	}
+}
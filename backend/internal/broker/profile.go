@@ -0,0 +1,373 @@
+package broker
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/*.yaml
+var builtinProfiles embed.FS
+
+// Profile maps the columns of a broker's CSV export to fills
+//
+// Profiles are YAML or JSON files; see the built-in ones in profiles/.
+// Column names are matched case- and punctuation-insensitively.
+type Profile struct {
+	Name             string         `yaml:"name"`
+	Broker           string         `yaml:"broker"` // Fill log name (default: Name in upper case)
+	Columns          ProfileColumns `yaml:"columns"`
+	DateFormats      []string       `yaml:"date_formats"`       // Go layouts (default: common date formats)
+	Sides            []SideRule     `yaml:"sides"`              // Default: values starting with BUY or SELL
+	OptionPriceScale float64        `yaml:"option_price_scale"` // Option price multiplier, e.g. 0.01 when priced per contract (default 1)
+}
+
+// ProfileColumns names the export's columns
+type ProfileColumns struct {
+	ExecID       string   `yaml:"exec_id"` // Default: derived from the fill
+	Account      string   `yaml:"account"`
+	Date         string   `yaml:"date"`
+	Time         string   `yaml:"time"`
+	Symbol       string   `yaml:"symbol"`
+	Side         string   `yaml:"side"`     // Default: the sign of the quantity
+	Quantity     string   `yaml:"quantity"` // Shares, or contracts for options
+	Price        string   `yaml:"price"`
+	Fees         []string `yaml:"fees"`          // Summed into the commission
+	OptionSymbol string   `yaml:"option_symbol"` // OCC or spelled-out contract symbol
+}
+
+// SideRule maps side column values starting with Match (ignoring case) to
+// a fill side and, when the broker says so, whether it opens or closes
+type SideRule struct {
+	Match     string `yaml:"match"`
+	Side      string `yaml:"side"`       // BUY or SELL
+	OpenClose string `yaml:"open_close"` // O, C or empty
+}
+
+var defaultSideRules = []SideRule{{Match: "BUY", Side: "BUY"}, {Match: "SELL", Side: "SELL"}}
+
+var defaultDateFormats = []string{
+	"2006-01-02", "01/02/2006", "1/2/2006", "20060102",
+	"2006-01-02 15:04:05", "2006-01-02T15:04:05Z07:00", "2006-01-02T15:04:05-0700",
+}
+
+// BuiltinProfiles lists the names of the profiles shipped with the engine
+func BuiltinProfiles() []string {
+	entries, _ := builtinProfiles.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProfile returns the built-in profile called name, or reads the
+// profile file at name
+func LoadProfile(name string) (*Profile, error) {
+	data, err := builtinProfiles.ReadFile("profiles/" + strings.ToLower(name) + ".yaml")
+	if err != nil {
+		if data, err = os.ReadFile(name); err != nil {
+			return nil, fmt.Errorf("unknown profile %q (built-in: %s)", name, strings.Join(BuiltinProfiles(), ", "))
+		}
+	}
+	return ParseProfile(data)
+}
+
+// ParseProfile reads a YAML or JSON profile and checks it
+func ParseProfile(data []byte) (*Profile, error) {
+	var p Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("profile needs a name")
+	}
+	c := p.Columns
+	if c.Date == "" || c.Symbol == "" || c.Quantity == "" || c.Price == "" {
+		return nil, fmt.Errorf("profile %s: date, symbol, quantity and price columns are required", p.Name)
+	}
+	for _, rule := range p.Sides {
+		if rule.Match == "" || (rule.Side != "BUY" && rule.Side != "SELL") {
+			return nil, fmt.Errorf("profile %s: side rule %q needs a match and a side of BUY or SELL", p.Name, rule.Match)
+		}
+		if rule.OpenClose != "" && rule.OpenClose != "O" && rule.OpenClose != "C" {
+			return nil, fmt.Errorf("profile %s: side rule %q has open_close %q (must be O or C)", p.Name, rule.Match, rule.OpenClose)
+		}
+	}
+	return &p, nil
+}
+
+// BrokerName is the name fills imported with the profile are logged under
+func (p *Profile) BrokerName() string {
+	if p.Broker != "" {
+		return p.Broker
+	}
+	return strings.ToUpper(p.Name)
+}
+
+// ParseFile reads the fills of a CSV export with the profile
+func (p *Profile) ParseFile(path string) ([]Fill, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	fills, err := p.ParseCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	for i := range fills {
+		fills[i].Source = filepath.Base(path)
+	}
+	return fills, nil
+}
+
+// profileIndex is where each mapped column is in the header row
+type profileIndex struct {
+	execID, account, date, clock, symbol, side, quantity, price, optionSymbol int
+	fees                                                                      []int
+}
+
+// ParseCSV reads the fills of a CSV export with the profile
+//
+// Rows before the header (the first row with the date, symbol, quantity
+// and price columns) are skipped, as are rows whose side matches no rule:
+// transfers, dividends, totals and disclaimers. Fills without an execution
+// id column get one from their details, numbered when the same fill
+// appears twice.
+func (p *Profile) ParseCSV(r io.Reader) ([]Fill, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	fills := []Fill{}
+	seen := map[string]int{}
+	var idx *profileIndex
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line++
+
+		if idx == nil {
+			idx = p.index(normalizeHeaders(record))
+			continue
+		}
+
+		fill, ok, err := p.fill(record, idx)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !ok {
+			continue
+		}
+		if fill.ExecID == "" {
+			fill.ExecID = syntheticExecID(fill)
+			seen[fill.ExecID]++
+			if n := seen[fill.ExecID]; n > 1 {
+				fill.ExecID += "#" + strconv.Itoa(n)
+			}
+		}
+		fills = append(fills, fill)
+	}
+	if idx == nil {
+		return nil, fmt.Errorf("no header row with the %q, %q, %q and %q columns",
+			p.Columns.Date, p.Columns.Symbol, p.Columns.Quantity, p.Columns.Price)
+	}
+	return fills, nil
+}
+
+// index locates the profile's columns in a header row, or returns nil if
+// the row is not the header
+func (p *Profile) index(headers []string) *profileIndex {
+	find := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		want := normalizeHeaders([]string{name})[0]
+		for i, h := range headers {
+			if h == want {
+				return i
+			}
+		}
+		return -1
+	}
+
+	c := p.Columns
+	idx := &profileIndex{
+		execID:       find(c.ExecID),
+		account:      find(c.Account),
+		date:         find(c.Date),
+		clock:        find(c.Time),
+		symbol:       find(c.Symbol),
+		side:         find(c.Side),
+		quantity:     find(c.Quantity),
+		price:        find(c.Price),
+		optionSymbol: find(c.OptionSymbol),
+	}
+	if idx.date < 0 || idx.symbol < 0 || idx.quantity < 0 || idx.price < 0 {
+		return nil
+	}
+	for _, fee := range c.Fees {
+		if i := find(fee); i >= 0 {
+			idx.fees = append(idx.fees, i)
+		}
+	}
+	return idx
+}
+
+// fill converts one export row, reporting false for rows that are not
+// trades
+func (p *Profile) fill(record []string, idx *profileIndex) (Fill, bool, error) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	symbol := cell(idx.symbol)
+	if symbol == "" {
+		return Fill{}, false, nil
+	}
+
+	var fill Fill
+	if idx.side >= 0 {
+		rule, ok := p.sideRule(cell(idx.side))
+		if !ok {
+			return Fill{}, false, nil
+		}
+		fill.Side, fill.OpenClose = rule.Side, rule.OpenClose
+	} else if cell(idx.quantity) == "" {
+		return Fill{}, false, nil
+	}
+
+	quantity, err := parseAmount(cell(idx.quantity))
+	if err != nil || quantity == 0 {
+		return Fill{}, false, fmt.Errorf("invalid quantity %q", cell(idx.quantity))
+	}
+	if fill.Side == "" {
+		fill.Side = "BUY"
+		if quantity < 0 {
+			fill.Side = "SELL"
+		}
+	}
+	if quantity != math.Trunc(quantity) {
+		return Fill{}, false, fmt.Errorf("fractional quantity %s is not supported", cell(idx.quantity))
+	}
+	price, err := parseAmount(cell(idx.price))
+	if err != nil || price == 0 {
+		return Fill{}, false, fmt.Errorf("invalid price %q", cell(idx.price))
+	}
+	for _, i := range idx.fees {
+		if cell(i) == "" {
+			continue
+		}
+		fee, err := parseAmount(cell(i))
+		if err != nil {
+			return Fill{}, false, fmt.Errorf("invalid fee %q", cell(i))
+		}
+		fill.Commission += math.Abs(fee)
+	}
+
+	fill.Date, fill.Time, err = p.parseDate(cell(idx.date))
+	if err != nil {
+		return Fill{}, false, err
+	}
+	if t := cell(idx.clock); t != "" {
+		clock, err := time.Parse("150405", strings.ReplaceAll(t, ":", ""))
+		if err != nil {
+			return Fill{}, false, fmt.Errorf("invalid time %q", t)
+		}
+		fill.Time = clock.Format("15:04:05")
+	}
+
+	fill.ExecID = cell(idx.execID)
+	fill.Account = cell(idx.account)
+	fill.Quantity = int(math.Abs(quantity))
+	fill.Price = math.Abs(price)
+	fill.Ticker = strings.ToUpper(symbol)
+	if underlying, leg, ok := ParseOptionSymbol(cell(idx.optionSymbol)); ok {
+		if p.OptionPriceScale > 0 {
+			fill.Price *= p.OptionPriceScale
+		}
+		leg.Qty, leg.Action, leg.Price = fill.Quantity, fill.Side, fill.Price
+		fill.Ticker, fill.AssetClass, fill.Leg = underlying, "OPT", &leg
+	}
+	return fill, true, nil
+}
+
+// sideRule returns the first rule the side value starts with
+func (p *Profile) sideRule(value string) (SideRule, bool) {
+	rules := p.Sides
+	if len(rules) == 0 {
+		rules = defaultSideRules
+	}
+	value = strings.ToUpper(strings.Join(strings.Fields(value), " "))
+	for _, rule := range rules {
+		if value != "" && strings.HasPrefix(value, strings.ToUpper(rule.Match)) {
+			return rule, true
+		}
+	}
+	return SideRule{}, false
+}
+
+// parseDate reads a date cell with the profile's layouts as YYYY-MM-DD and,
+// when the layout has one, HH:MM:SS; text after the date ("01/03/2025 as
+// of 01/02/2025") is ignored
+func (p *Profile) parseDate(value string) (string, string, error) {
+	layouts := p.DateFormats
+	if len(layouts) == 0 {
+		layouts = defaultDateFormats
+	}
+	candidates := []string{value}
+	if fields := strings.Fields(value); len(fields) > 1 {
+		candidates = append(candidates, fields[0])
+	}
+	for _, s := range candidates {
+		for _, layout := range layouts {
+			t, err := time.Parse(layout, s)
+			if err != nil {
+				continue
+			}
+			clock := ""
+			if strings.Contains(layout, "15") {
+				clock = t.Format("15:04:05")
+			}
+			return t.Format("2006-01-02"), clock, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid date %q", value)
+}
+
+// parseAmount reads a number as exported by brokers: "$1,234.50",
+// "-$5.00" or "(5.00)"
+func parseAmount(s string) (float64, error) {
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.NewReplacer("$", "", ",", "", "(", "", ")", "", " ", "").Replace(s)
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
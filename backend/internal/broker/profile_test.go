@@ -0,0 +1,236 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestParseOptionSymbol(t *testing.T) {
+	tests := []struct {
+		symbol     string
+		underlying string
+		leg        storage.OptionLeg
+	}{
+		{"AAPL  250117C00110000", "AAPL", storage.OptionLeg{Type: "CALL", Strike: 110, Exp: "2025-01-17"}},
+		{"SPY250221P00582500", "SPY", storage.OptionLeg{Type: "PUT", Strike: 582.5, Exp: "2025-02-21"}},
+		{" -SPY250221P580", "SPY", storage.OptionLeg{Type: "PUT", Strike: 580, Exp: "2025-02-21"}},
+		{"BRK.B 03/21/2025 480.00 C", "BRK.B", storage.OptionLeg{Type: "CALL", Strike: 480, Exp: "2025-03-21"}},
+	}
+	for _, tt := range tests {
+		underlying, leg, ok := ParseOptionSymbol(tt.symbol)
+		require.True(t, ok, tt.symbol)
+		assert.Equal(t, tt.underlying, underlying, tt.symbol)
+		assert.Equal(t, tt.leg, leg, tt.symbol)
+	}
+
+	for _, symbol := range []string{"AAPL", "BRK B", "", "AAPL 250117X00110000"} {
+		_, _, ok := ParseOptionSymbol(symbol)
+		assert.False(t, ok, symbol)
+	}
+}
+
+// The built-in profiles read the same four trades from each broker's export:
+// 50 AAPL bought and sold, and a SPY 580/575 bull put spread opened
+func TestBuiltinProfiles(t *testing.T) {
+	assert.Equal(t, []string{"fidelity", "schwab", "tastytrade"}, BuiltinProfiles())
+
+	files := map[string]string{
+		"schwab":     "testdata/schwab_transactions.csv",
+		"fidelity":   "testdata/fidelity_history.csv",
+		"tastytrade": "testdata/tastytrade_transactions.csv",
+	}
+	for name, file := range files {
+		t.Run(name, func(t *testing.T) {
+			profile, err := LoadProfile(name)
+			require.NoError(t, err)
+			assert.Equal(t, strings.ToUpper(name), profile.BrokerName())
+
+			fills, err := profile.ParseFile(file)
+			require.NoError(t, err)
+			require.Len(t, fills, 4)
+
+			buy := fills[0]
+			assert.Equal(t, "AAPL", buy.Ticker)
+			assert.Equal(t, "BUY", buy.Side)
+			assert.Equal(t, 50, buy.Quantity)
+			assert.Equal(t, 100.5, buy.Price)
+			assert.Equal(t, "2025-01-06", buy.Date)
+			assert.Nil(t, buy.Leg)
+			assert.NotEmpty(t, buy.ExecID)
+			assert.Equal(t, filepath.Base(file), buy.Source)
+
+			short := fills[1]
+			assert.Equal(t, "SPY", short.Ticker)
+			assert.Equal(t, "OPT", short.AssetClass)
+			require.NotNil(t, short.Leg)
+			assert.Equal(t, storage.OptionLeg{Type: "PUT", Strike: 580, Exp: "2025-02-21", Qty: 1, Action: "SELL", Price: 2}, *short.Leg)
+			assert.Equal(t, "O", short.OpenClose)
+			assert.Greater(t, short.Commission, 0.0)
+
+			long := fills[2]
+			require.NotNil(t, long.Leg)
+			assert.Equal(t, 575.0, long.Leg.Strike)
+			assert.Equal(t, "BUY", long.Leg.Action)
+			assert.InDelta(t, 0.8, long.Leg.Price, 1e-9)
+
+			sell := fills[3]
+			assert.Equal(t, "SELL", sell.Side)
+			assert.Equal(t, 50, sell.Quantity)
+			assert.Equal(t, 104.0, sell.Price)
+			assert.Equal(t, "2025-01-10", sell.Date)
+		})
+	}
+
+	tasty, err := LoadProfile("tastytrade")
+	require.NoError(t, err)
+	fills, err := tasty.ParseFile(files["tastytrade"])
+	require.NoError(t, err)
+	assert.Equal(t, "09:35:12", fills[0].Time)
+	assert.InDelta(t, 1.14, fills[1].Commission, 1e-9)
+}
+
+func TestParseProfile(t *testing.T) {
+	// JSON works as well as YAML; no side column: the quantity's sign decides
+	profile, err := ParseProfile([]byte(`{
+		"name": "mybroker",
+		"columns": {"exec_id": "Ref", "date": "Trade Date", "time": "Time", "symbol": "Ticker",
+		            "quantity": "Qty", "price": "Fill Price", "fees": ["Comm"]}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "MYBROKER", profile.BrokerName())
+
+	data := `Ref,Trade Date,Time,Ticker,Qty,Fill Price,Comm
+r1,2025-01-06,09:30:05,msft,10,420.00,-1.00
+r2,2025-01-07,14:00:00,MSFT,-10,"$1,425.00",
+,,,,,,
+`
+	fills, err := profile.ParseCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, fills, 2)
+	assert.Equal(t, Fill{ExecID: "r1", Ticker: "MSFT", Side: "BUY", Quantity: 10, Price: 420, Commission: 1,
+		Date: "2025-01-06", Time: "09:30:05"}, fills[0])
+	assert.Equal(t, "SELL", fills[1].Side)
+	assert.Equal(t, 1425.0, fills[1].Price)
+
+	// Without an id column, repeated fills get distinct ids
+	profile.Columns.ExecID = ""
+	fills, err = profile.ParseCSV(strings.NewReader("Trade Date,Ticker,Qty,Fill Price\n2025-01-06,MSFT,5,420\n2025-01-06,MSFT,5,420\n"))
+	require.NoError(t, err)
+	require.Len(t, fills, 2)
+	assert.NotEqual(t, fills[0].ExecID, fills[1].ExecID)
+
+	_, err = profile.ParseCSV(strings.NewReader("Trade Date,Ticker,Qty,Fill Price\nyesterday,MSFT,5,420\n"))
+	assert.ErrorContains(t, err, "line 2")
+	_, err = profile.ParseCSV(strings.NewReader("Date,Symbol\n"))
+	assert.ErrorContains(t, err, "no header row")
+
+	_, err = ParseProfile([]byte("name: x\ncolumns: {date: Date}\n"))
+	assert.ErrorContains(t, err, "required")
+	_, err = ParseProfile([]byte("name: x\ncolumns: {date: D, symbol: S, quantity: Q, price: P}\nsides: [{match: B, side: LONG}]\n"))
+	assert.ErrorContains(t, err, "BUY or SELL")
+	_, err = LoadProfile("nosuchbroker")
+	assert.ErrorContains(t, err, "built-in: fidelity, schwab, tastytrade")
+}
+
+func TestPreviewFills(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	_, err = db.SaveDecision(storage.Decision{
+		Date: "2025-01-03", Ticker: "AAPL", Action: "GO", Entry: 100, ATR: 2,
+		StopDistance: 4, InitialStop: 96, Shares: 50, RiskDollars: 200,
+		Banner: "GREEN", Method: "stock",
+	})
+	require.NoError(t, err)
+
+	// The SPY bull put spread was entered from an options session
+	spreadDecision, err := db.SaveDecision(storage.Decision{
+		Date: "2025-01-07", Ticker: "SPY", Action: "GO", Entry: 590,
+		InitialStop: 570, RiskDollars: 380, Banner: "GREEN", Method: "options",
+	})
+	require.NoError(t, err)
+	legs := storage.BuildBullPutSpread(575, 580, "2025-02-21", 1, 0.80, 2.00)
+	legsJSON, err := json.Marshal(legs)
+	require.NoError(t, err)
+	spread, err := db.CreatePositionFromSession(&storage.TradeSession{
+		Ticker:                "SPY",
+		Direction:             storage.DirectionLong,
+		InstrumentType:        storage.InstrumentOption,
+		OptionsStrategy:       storage.StrategyBullPutSpread,
+		EntryDate:             "2025-01-07",
+		PrimaryExpirationDate: "2025-02-21",
+		LegsJSON:              string(legsJSON),
+		NetDebit:              storage.CalculateNetDebit(legs),
+		MaxLoss:               380,
+		UnderlyingAtEntry:     590,
+		SizingCompleted:       true,
+		SizingEntryPrice:      590,
+		SizingInitialStop:     570,
+		SizingRiskDollars:     380,
+		EntryDecision:         "GO",
+		EntryDecisionID:       &spreadDecision,
+	})
+	require.NoError(t, err)
+
+	profile, err := LoadProfile("schwab")
+	require.NoError(t, err)
+	fills, err := profile.ParseFile("testdata/schwab_transactions.csv")
+	require.NoError(t, err)
+
+	report, err := PreviewFills(db, profile.BrokerName(), fills)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Applied)
+	assert.Equal(t, 2, report.Unmatched)
+
+	// The spread's legs are left for review, naming the position they belong to
+	assert.Equal(t, storage.FillUnmatched, report.Results[1].Status)
+	assert.Empty(t, report.Results[1].Action)
+	assert.Contains(t, report.Results[1].Detail, fmt.Sprintf("option position #%d", spread.ID))
+
+	// Only the AAPL round trip changes positions; the spread was already there
+	require.Len(t, report.Changes, 1)
+	change := report.Changes[0]
+	assert.Equal(t, "AAPL", change.Ticker)
+	assert.Nil(t, change.Before)
+	assert.Equal(t, "CLOSED", change.After.Status)
+	assert.InDelta(t, 50*3.5-0.04, change.After.PnL, 1e-9)
+
+	// Nothing was written
+	positions, err := db.GetAllPositions("")
+	require.NoError(t, err)
+	assert.Len(t, positions, 1)
+	logged, err := db.ListBrokerFills("")
+	require.NoError(t, err)
+	assert.Empty(t, logged)
+
+	// Applying for real matches the preview
+	applied, err := ApplyFills(db, profile.BrokerName(), fills)
+	require.NoError(t, err)
+	assert.Equal(t, report.Applied, applied.Applied)
+	assert.Equal(t, report.Unmatched, applied.Unmatched)
+	logged, err = db.ListBrokerFills(storage.FillApplied)
+	require.NoError(t, err)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "SCHWAB", logged[0].Broker)
+	logged, err = db.ListBrokerFills(storage.FillUnmatched)
+	require.NoError(t, err)
+	require.Len(t, logged, 2)
+	require.NotNil(t, logged[0].Leg)
+	assert.Equal(t, 580.0, logged[0].Leg.Strike)
+
+	// The spread is untouched
+	position, err := db.GetPosition(spread.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "OPEN", position.Status)
+}
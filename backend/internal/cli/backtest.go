@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/backtest"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewBacktestCommand creates the backtest command
+func NewBacktestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backtest",
+		Short: "Replay historical bars through the live sizing, heat and cooldown rules",
+		Long: `Backtest the current settings against daily bars from CSV files.
+
+Bars are replayed day by day. Donchian breakouts are sized with the same
+position sizing code as 'size', checked against the same portfolio and
+bucket heat caps as 'save-decision', and a losing exit puts its bucket in
+the same 24-hour cooldown. Positions exit on the K×N stop or the Donchian
+exit channel.
+
+Settings (Equity_E, RiskPct_r, HeatCap_H_pct, BucketHeatCap_pct,
+StopMultiple_K) are read from the database; any of them can be
+overridden with flags to try alternatives without changing the live
+settings.
+
+The JSON output contains the summary and trade list. Use --equity-csv
+to write the daily equity curve and --trades-csv for a spreadsheet-ready
+trade list.
+
+Examples:
+  # Backtest System 2 on every CSV in ./data/prices
+  tf-engine backtest
+
+  # System 1 with sector buckets over a date range
+  tf-engine backtest --system SYSTEM_1 --start 2015-01-01 --end 2024-12-31 \
+    --buckets AAPL=Tech/Comm,MSFT=Tech/Comm,XOM=Energy
+
+  # Try a higher heat cap and save the equity curve
+  tf-engine backtest --heat-cap 0.06 --equity-csv equity.csv`,
+		RunE: runBacktest,
+	}
+
+	cmd.Flags().String("dir", marketdata.DefaultPriceDir, "Folder of <TICKER>.csv files")
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: every CSV in --dir)")
+	cmd.Flags().String("system", domain.BreakoutSystemTwo, "Breakout system: SYSTEM_1 or SYSTEM_2")
+	cmd.Flags().String("start", "", "First date entries are allowed (YYYY-MM-DD)")
+	cmd.Flags().String("end", "", "Last date to replay (YYYY-MM-DD)")
+	cmd.Flags().String("buckets", "", "Ticker buckets, e.g. AAPL=Tech/Comm,XOM=Energy")
+	cmd.Flags().Bool("skip-filter", true, "Apply the System 1 last-breakout skip filter")
+	cmd.Flags().Float64("equity", 0, "Starting equity (default: Equity_E setting)")
+	cmd.Flags().Float64("risk", 0, "Risk per trade as decimal, e.g. 0.0075 (default: RiskPct_r)")
+	cmd.Flags().Float64("heat-cap", 0, "Portfolio heat cap as decimal (default: HeatCap_H_pct)")
+	cmd.Flags().Float64("bucket-cap", 0, "Bucket heat cap as decimal (default: BucketHeatCap_pct)")
+	cmd.Flags().Float64("k", 0, "Stop multiple K (default: StopMultiple_K)")
+	cmd.Flags().String("trades-csv", "", "Write the trade list to this CSV file")
+	cmd.Flags().String("equity-csv", "", "Write the daily equity curve to this CSV file")
+
+	return cmd
+}
+
+func runBacktest(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	cfg, bars, err := backtestInputs(cmd, dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to prepare backtest")
+		return err
+	}
+
+	system, _ := cmd.Flags().GetString("system")
+	if cfg.Params, err = domain.GetBreakoutParams(system); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("risk") {
+		cfg.RiskPct, _ = cmd.Flags().GetFloat64("risk")
+	}
+	if cmd.Flags().Changed("heat-cap") {
+		cfg.HeatCapPct, _ = cmd.Flags().GetFloat64("heat-cap")
+	}
+	if cmd.Flags().Changed("bucket-cap") {
+		cfg.BucketHeatCapPct, _ = cmd.Flags().GetFloat64("bucket-cap")
+	}
+	if cmd.Flags().Changed("k") {
+		cfg.K, _ = cmd.Flags().GetFloat64("k")
+	}
+
+	log.WithField("system", cfg.Params.System).WithField("tickers", len(bars)).Info("Running backtest")
+
+	result, err := backtest.Run(cfg, bars)
+	if err != nil {
+		log.WithError(err).Error("Backtest failed")
+		return fmt.Errorf("backtest failed: %w", err)
+	}
+
+	tradesCSV, _ := cmd.Flags().GetString("trades-csv")
+	equityCSV, _ := cmd.Flags().GetString("equity-csv")
+	if tradesCSV != "" {
+		if err := writeCSVFile(tradesCSV, func(f *os.File) error { return backtest.WriteTradesCSV(f, result.Trades) }); err != nil {
+			return err
+		}
+	}
+	if equityCSV != "" {
+		if err := writeCSVFile(equityCSV, func(f *os.File) error { return backtest.WriteEquityCSV(f, result.EquityCurve) }); err != nil {
+			return err
+		}
+	}
+
+	PrintHumanf(format, "Backtest %s  %s → %s  (%d tickers)\n", cfg.Params.System, result.StartDate, result.EndDate, len(bars))
+	PrintHumanf(format, "  Equity:       $%.2f → $%.2f (%+.2f%%)\n", result.InitialEquity, result.FinalEquity, result.TotalReturnPct)
+	PrintHumanf(format, "  CAGR:         %.2f%%\n", result.CAGRPct)
+	PrintHumanf(format, "  Max drawdown: $%.2f (%.2f%%)\n", result.MaxDrawdown, result.MaxDrawdownPct)
+	PrintHumanf(format, "  Trades:       %d (%d wins, %d losses, %.1f%% win rate)\n",
+		result.TradeCount, result.Wins, result.Losses, result.WinRatePct)
+	if len(result.Rejections) > 0 {
+		reasons := make([]string, 0, len(result.Rejections))
+		for reason, count := range result.Rejections {
+			reasons = append(reasons, fmt.Sprintf("%s=%d", reason, count))
+		}
+		sort.Strings(reasons)
+		PrintHumanf(format, "  Rejected:     %s\n", strings.Join(reasons, ", "))
+	}
+	PrintHuman(format, "")
+
+	log.WithField("trades", result.TradeCount).WithField("cagr_pct", result.CAGRPct).Info("Backtest completed")
+
+	// The equity curve goes to --equity-csv; keep stdout readable
+	summary := *result
+	summary.EquityCurve = nil
+	if err := PrintJSON(summary); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
+
+// backtestInputs builds the base config from settings and the flags shared
+// by backtest and optimize (dir, tickers, buckets, start, end, skip-filter,
+// equity), and loads the bars
+func backtestInputs(cmd *cobra.Command, dbPath string) (backtest.Config, map[string][]domain.Bar, error) {
+	dir, _ := cmd.Flags().GetString("dir")
+	tickersFlag, _ := cmd.Flags().GetString("tickers")
+	bucketsFlag, _ := cmd.Flags().GetString("buckets")
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return backtest.Config{}, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return backtest.Config{}, nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	cfg := backtest.ConfigFromSettings(settings)
+
+	if cfg.Buckets, err = backtest.ParseBuckets(bucketsFlag); err != nil {
+		return cfg, nil, err
+	}
+	cfg.Start, _ = cmd.Flags().GetString("start")
+	cfg.End, _ = cmd.Flags().GetString("end")
+	cfg.SkipFilter, _ = cmd.Flags().GetBool("skip-filter")
+
+	if cmd.Flags().Changed("equity") {
+		cfg.Equity, _ = cmd.Flags().GetFloat64("equity")
+	}
+
+	provider := marketdata.NewCSVBarProvider(dir)
+	var tickers []string
+	if tickersFlag != "" {
+		tickers = strings.Split(tickersFlag, ",")
+	} else if tickers, err = provider.Tickers(); err != nil {
+		return cfg, nil, fmt.Errorf("failed to list price files: %w", err)
+	}
+
+	bars, err := backtest.LoadBars(provider, tickers)
+	if err != nil {
+		return cfg, nil, err
+	}
+	return cfg, bars, nil
+}
+
+// writeCSVFile creates path and fills it with write
+func writeCSVFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
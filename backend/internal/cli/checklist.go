@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/trading-engine/internal/domain"
 	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -39,7 +41,13 @@ Examples:
   tf-engine checklist --ticker AAPL --from-preset --trend-pass --liquidity-pass --tv-confirm --earnings-ok
 
   # Two items missing (RED)
-  tf-engine checklist --ticker AAPL --from-preset --trend-pass --liquidity-pass`,
+  tf-engine checklist --ticker AAPL --from-preset --trend-pass --liquidity-pass
+
+  # Tick TrendPass automatically if AAPL closed above its 55-bar high
+  tf-engine checklist --ticker AAPL --auto-trend --from-preset --liquidity-pass --tv-confirm --earnings-ok --journal-ok
+
+  # Short setup: TrendPass ticks only if AAPL closed below its 55-bar low
+  tf-engine checklist --ticker AAPL --direction SHORT --auto-trend --from-preset --liquidity-pass --tv-confirm --earnings-ok --journal-ok`,
 		RunE: runChecklist,
 	}
 
@@ -55,6 +63,11 @@ Examples:
 	cmd.Flags().Bool("earnings-ok", false, "No earnings in next 7 days")
 	cmd.Flags().Bool("journal-ok", false, "Trade thesis documented in journal")
 
+	// Breakout signal flags
+	cmd.Flags().String("direction", domain.DirectionLong, "Trade direction: LONG or SHORT")
+	cmd.Flags().Bool("auto-trend", false, "Tick TrendPass if stored bars show a Donchian breakout in --direction")
+	cmd.Flags().String("system", domain.BreakoutSystemTwo, "Breakout system for --auto-trend: SYSTEM_1 or SYSTEM_2")
+
 	return cmd
 }
 
@@ -74,10 +87,17 @@ func runChecklist(cmd *cobra.Command, args []string) error {
 	tvConfirm, _ := cmd.Flags().GetBool("tv-confirm")
 	earningsOK, _ := cmd.Flags().GetBool("earnings-ok")
 	journalOK, _ := cmd.Flags().GetBool("journal-ok")
+	autoTrend, _ := cmd.Flags().GetBool("auto-trend")
+	system, _ := cmd.Flags().GetString("system")
+	direction, err := domain.NormalizeDirection(cmd.Flag("direction").Value.String())
+	if err != nil {
+		return err
+	}
 
 	// Build request
 	req := domain.ChecklistRequest{
 		Ticker:        ticker,
+		Direction:     direction,
 		FromPreset:    fromPreset,
 		TrendPass:     trendPass,
 		LiquidityPass: liquidityPass,
@@ -86,6 +106,25 @@ func runChecklist(cmd *cobra.Command, args []string) error {
 		JournalOK:     journalOK,
 	}
 
+	// Auto-tick TrendPass from a stored-bar breakout
+	if autoTrend && !req.TrendPass {
+		signal, err := detectTickerBreakout(dbPath, ticker, system)
+		if err != nil {
+			log.WithError(err).Error("Breakout check failed")
+			return fmt.Errorf("breakout check failed: %w", err)
+		}
+		if !domain.ApplyBreakoutToChecklist(&req, signal) {
+			PrintHumanf(format, "  TrendPass not set: no %s %s breakout on %s\n",
+				strings.ToLower(direction), signal.System, signal.Date)
+		} else if direction == domain.DirectionShort {
+			PrintHumanf(format, "✓ TrendPass: %s closed $%.2f below %d-bar low $%.2f\n",
+				ticker, signal.Close, signal.EntryLookback, signal.ChannelLow)
+		} else {
+			PrintHumanf(format, "✓ TrendPass: %s closed $%.2f above %d-bar high $%.2f\n",
+				ticker, signal.Close, signal.EntryLookback, signal.ChannelHigh)
+		}
+	}
+
 	log.WithField("request", req).Info("Evaluating checklist")
 
 	// Evaluate checklist
@@ -128,3 +167,24 @@ func runChecklist(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// detectTickerBreakout checks one ticker's stored bars for a Donchian breakout
+func detectTickerBreakout(dbPath, ticker, system string) (*domain.BreakoutSignal, error) {
+	params, err := domain.GetBreakoutParams(system)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	bars, err := marketdata.NewDBBarProvider(db).GetDailyBars(strings.ToUpper(ticker))
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.DetectBreakout(strings.ToUpper(ticker), bars, params)
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewEquityCommand creates the equity command
+func NewEquityCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "equity",
+		Short: "Show raw, peak and sizing equity",
+		Long: `Show the account equity that sizing and heat caps use.
+
+Without a StartingEquity setting, equity is Equity_E as entered. With it,
+equity is StartingEquity plus the realized P&L of every exit in the trade
+history (plus open stock positions at their last stored close when
+EquityMarkToMarket is 1). For every DrawdownStep_pct the account is below
+its peak, sizing equity is the peak cut by another DrawdownCut_pct
+(Turtle rule: 20% less for every 10% drawdown), never more than the raw
+equity.
+
+Examples:
+  # Start tracking from a $100,000 account
+  tf-engine set-setting --key StartingEquity --value 100000
+  tf-engine equity
+
+  # With the equity curve
+  tf-engine equity --curve`,
+		RunE: runEquity,
+	}
+
+	cmd.Flags().Bool("curve", false, "Also list equity, peak and drawdown per exit date")
+
+	return cmd
+}
+
+func runEquity(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	showCurve, _ := cmd.Flags().GetBool("curve")
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	status, err := marketdata.ResolveEquity(db)
+	if err != nil {
+		log.WithError(err).Error("Failed to resolve equity")
+		return fmt.Errorf("failed to resolve equity: %w", err)
+	}
+
+	log.WithFields(map[string]interface{}{
+		"mode":     status.Mode,
+		"raw":      status.Raw,
+		"peak":     status.Peak,
+		"adjusted": status.Adjusted,
+	}).Info("Equity resolved")
+
+	PrintHumanf(format, "Account Equity (%s)\n", status.Mode)
+	PrintHuman(format, "=====================")
+	if status.Mode == domain.EquityModeTracked {
+		PrintHumanf(format, "Starting:      $%.2f\n", status.Starting)
+		PrintHumanf(format, "Realized P&L:  $%.2f\n", status.Realized)
+		if status.Unrealized != 0 {
+			PrintHumanf(format, "Unrealized:    $%.2f\n", status.Unrealized)
+		}
+	}
+	PrintHumanf(format, "Raw Equity:    $%.2f\n", status.Raw)
+	PrintHumanf(format, "Peak Equity:   $%.2f\n", status.Peak)
+	PrintHumanf(format, "Drawdown:      %.1f%% (%d steps)\n", status.DrawdownPct*100, status.Steps)
+	PrintHumanf(format, "Sizing Equity: $%.2f\n", status.Adjusted)
+
+	if showCurve && len(status.Curve) > 0 {
+		PrintHuman(format, "")
+		PrintHumanf(format, "%-12s %14s %14s %9s\n", "DATE", "EQUITY", "PEAK", "DRAWDOWN")
+		for _, p := range status.Curve {
+			PrintHumanf(format, "%-12s %14.2f %14.2f %8.1f%%\n", p.Date, p.Equity, p.Peak, p.DrawdownPct*100)
+		}
+	}
+
+	PrintHuman(format, "")
+
+	// JSON output (always)
+	PrintJSON(status)
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewEquityCurveCommand creates the equity-curve command
+func NewEquityCurveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "equity-curve",
+		Short: "Show the account's daily equity curve, drawdowns and monthly returns",
+		Long: `Show how the account has done over time.
+
+The daily series starts at the first position's entry with StartingEquity
+(or Equity_E when StartingEquity is not set) and adds the realized P&L of
+every exit in the trade history on its exit date. With --mtm, open stock
+positions are also marked at each day's close from the CSV files in --dir
+(one <TICKER>.csv per ticker); option positions count at realized P&L only.
+
+Reports max drawdown, the longest time below a peak, the current drawdown
+and a monthly returns table.
+
+Examples:
+  # Realized P&L only, through today
+  tf-engine equity-curve
+
+  # Marked to market, exported for a spreadsheet
+  tf-engine equity-curve --mtm --equity-csv equity.csv --monthly-csv monthly.csv
+
+  # Full series as JSON
+  tf-engine equity-curve --json-file equity.json`,
+		RunE: runEquityCurve,
+	}
+
+	cmd.Flags().String("end", "", "Last date of the curve (YYYY-MM-DD, default: today)")
+	cmd.Flags().Bool("mtm", false, "Mark open stock positions to market from --dir")
+	cmd.Flags().String("dir", marketdata.DefaultPriceDir, "Directory of <TICKER>.csv daily bars for --mtm")
+	cmd.Flags().String("equity-csv", "", "Write the daily equity curve to this CSV file")
+	cmd.Flags().String("monthly-csv", "", "Write the monthly returns to this CSV file")
+	cmd.Flags().String("json-file", "", "Write the full curve, daily series included, to this JSON file")
+
+	return cmd
+}
+
+func runEquityCurve(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	end, _ := cmd.Flags().GetString("end")
+	mtm, _ := cmd.Flags().GetBool("mtm")
+	dir, _ := cmd.Flags().GetString("dir")
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	opts := analytics.EquityCurveOptions{End: end, MarkToMarket: mtm}
+	if mtm {
+		opts.Provider = marketdata.NewCSVBarProvider(dir)
+	}
+	curve, err := analytics.LoadEquityCurve(db, opts)
+	if err != nil {
+		log.WithError(err).Error("Failed to build equity curve")
+		return fmt.Errorf("failed to build equity curve: %w", err)
+	}
+
+	equityCSV, _ := cmd.Flags().GetString("equity-csv")
+	monthlyCSV, _ := cmd.Flags().GetString("monthly-csv")
+	jsonFile, _ := cmd.Flags().GetString("json-file")
+	if equityCSV != "" {
+		if err := writeCSVFile(equityCSV, func(f *os.File) error { return analytics.WriteEquityCurveCSV(f, curve.Daily) }); err != nil {
+			return err
+		}
+	}
+	if monthlyCSV != "" {
+		if err := writeCSVFile(monthlyCSV, func(f *os.File) error { return analytics.WriteReturnsCSV(f, curve.Monthly) }); err != nil {
+			return err
+		}
+	}
+	if jsonFile != "" {
+		err := writeCSVFile(jsonFile, func(f *os.File) error {
+			enc := json.NewEncoder(f)
+			enc.SetIndent("", "  ")
+			return enc.Encode(curve)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(map[string]interface{}{
+		"days":             len(curve.Daily),
+		"final":            curve.Final,
+		"max_drawdown_pct": curve.MaxDrawdownPct,
+	}).Info("Equity curve built")
+
+	PrintHumanf(format, "Equity Curve  %s → %s\n", curve.Start, curve.End)
+	PrintHuman(format, "=====================")
+	PrintHumanf(format, "Equity:           $%.2f → $%.2f (%+.2f%%)\n", curve.Starting, curve.Final, curve.TotalReturnPct)
+	if curve.MaxDrawdown > 0 {
+		PrintHumanf(format, "Max Drawdown:     $%.2f (%.2f%%) from %s to %s\n",
+			curve.MaxDrawdown, curve.MaxDrawdownPct, curve.MaxDrawdownPeak, curve.MaxDrawdownTrough)
+		recovery := "not recovered"
+		if curve.LongestDrawdownEnd != "" {
+			recovery = "recovered " + curve.LongestDrawdownEnd
+		}
+		PrintHumanf(format, "Longest Drawdown: %d days from %s (%s)\n", curve.LongestDrawdownDays, curve.LongestDrawdownStart, recovery)
+	} else {
+		PrintHuman(format, "Max Drawdown:     none")
+	}
+	if curve.CurrentDrawdownDays > 0 {
+		PrintHumanf(format, "Current Drawdown: %d days\n", curve.CurrentDrawdownDays)
+	}
+	if len(curve.Missing) > 0 {
+		tickers := make([]string, 0, len(curve.Missing))
+		for ticker, reason := range curve.Missing {
+			tickers = append(tickers, fmt.Sprintf("%s (%s)", ticker, reason))
+		}
+		sort.Strings(tickers)
+		PrintHumanf(format, "Not marked:       %s\n", strings.Join(tickers, ", "))
+	}
+
+	PrintHuman(format, "")
+	PrintHuman(format, "Monthly Returns (%)")
+	printReturnsGrid(format, curve.Monthly, curve.Yearly)
+	PrintHuman(format, "")
+
+	// The daily series goes to --equity-csv / --json-file; keep stdout readable
+	summary := *curve
+	summary.Daily = nil
+	if err := PrintJSON(summary); err != nil {
+		log.WithError(err).Error("Failed to marshal equity curve to JSON")
+		return fmt.Errorf("failed to marshal equity curve: %w", err)
+	}
+
+	return nil
+}
+
+// printReturnsGrid prints monthly returns as a year × month table with the
+// year's return in the last column
+func printReturnsGrid(format OutputFormat, monthly, yearly []analytics.PeriodReturn) {
+	months := []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	PrintHumanf(format, "%-6s", "YEAR")
+	for _, m := range months {
+		PrintHumanf(format, " %6s", m)
+	}
+	PrintHumanf(format, " %7s\n", "YEAR")
+
+	byMonth := map[string]float64{}
+	for _, m := range monthly {
+		byMonth[m.Period] = m.ReturnPct
+	}
+	for _, y := range yearly {
+		PrintHumanf(format, "%-6s", y.Period)
+		for i := range months {
+			if pct, ok := byMonth[fmt.Sprintf("%s-%02d", y.Period, i+1)]; ok {
+				PrintHumanf(format, " %6.2f", pct)
+			} else {
+				PrintHumanf(format, " %6s", "")
+			}
+		}
+		PrintHumanf(format, " %7.2f\n", y.ReturnPct)
+	}
+}
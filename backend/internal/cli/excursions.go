@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewUpdateExcursionsCommand creates the update-excursions command
+func NewUpdateExcursionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-excursions",
+		Short: "Track how far positions went against and in favour of entry (MAE/MFE)",
+		Long: `Widen each stock position's tracked price range with daily highs and
+lows, or with a manual price mark.
+
+The lowest and highest price since entry give the maximum adverse
+excursion (MAE) and maximum favourable excursion (MFE), shown by
+get-position and summarised by excursions. Ranges only ever widen, so
+re-running over the same bars is safe. import-prices updates open
+positions automatically; run this to backfill closed positions or after
+entering marks.
+
+Bars are read from the price store (import-prices), or from
+<TICKER>.csv files when --dir is given.
+
+Examples:
+  # Update open positions from stored bars
+  tf-engine update-excursions
+
+  # Backfill closed positions too, from a CSV folder
+  tf-engine update-excursions --all --dir ./data/prices
+
+  # Record an intraday low seen on the broker's screen
+  tf-engine update-excursions --ticker AAPL --price 97.20`,
+		RunE: runUpdateExcursions,
+	}
+
+	cmd.Flags().Bool("all", false, "Include closed positions")
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: all positions)")
+	cmd.Flags().String("dir", "", "Folder of <TICKER>.csv daily bars (default: the price store)")
+	cmd.Flags().String("ticker", "", "Open position to mark with --price")
+	cmd.Flags().Float64("price", 0, "Manual price mark for --ticker")
+	cmd.Flags().String("date", "", "Date of the --price mark (YYYY-MM-DD, default: today)")
+
+	return cmd
+}
+
+func runUpdateExcursions(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	all, _ := cmd.Flags().GetBool("all")
+	tickers, _ := cmd.Flags().GetString("tickers")
+	dir, _ := cmd.Flags().GetString("dir")
+	ticker, _ := cmd.Flags().GetString("ticker")
+	price, _ := cmd.Flags().GetFloat64("price")
+	date, _ := cmd.Flags().GetString("date")
+
+	if (ticker == "") != (price == 0) {
+		return fmt.Errorf("--ticker and --price go together")
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var updates []marketdata.ExcursionUpdate
+	if ticker != "" {
+		if date == "" {
+			date = time.Now().Format("2006-01-02")
+		}
+		position, err := db.GetPositionByTicker(strings.ToUpper(ticker))
+		if err != nil {
+			log.WithError(err).Error("Failed to get position")
+			return fmt.Errorf("failed to get position: %w", err)
+		}
+		update, err := marketdata.MarkExcursion(db, position, price, date)
+		if err != nil {
+			log.WithError(err).Error("Failed to mark excursion")
+			return fmt.Errorf("failed to mark excursion: %w", err)
+		}
+		updates = append(updates, *update)
+	} else {
+		status := "OPEN"
+		if all {
+			status = ""
+		}
+		positions, err := db.GetAllPositions(status)
+		if err != nil {
+			log.WithError(err).Error("Failed to get positions")
+			return fmt.Errorf("failed to get positions: %w", err)
+		}
+
+		var provider domain.BarProvider = marketdata.NewDBBarProvider(db)
+		if dir != "" {
+			provider = marketdata.NewCSVBarProvider(dir)
+		}
+		updates, err = marketdata.UpdateExcursions(db, provider, filterPositions(positions, tickers))
+		if err != nil {
+			log.WithError(err).Error("Failed to update excursions")
+			return fmt.Errorf("failed to update excursions: %w", err)
+		}
+	}
+
+	log.WithField("positions", len(updates)).Info("Excursions updated")
+
+	PrintHuman(format, "Excursion Ranges")
+	PrintHuman(format, "================")
+	if len(updates) == 0 {
+		PrintHuman(format, "No positions to update")
+	}
+	for _, u := range updates {
+		if u.Skipped != "" {
+			PrintHumanf(format, "– %-8s skipped: %s\n", u.Ticker, u.Skipped)
+			continue
+		}
+		PrintHumanf(format, "✓ %-8s %.2f – %.2f through %s\n", u.Ticker, u.Low, u.High, u.Through)
+	}
+	PrintHuman(format, "")
+
+	// JSON output (always)
+	PrintJSON(updates)
+
+	return nil
+}
+
+// NewExcursionsCommand creates the excursions command
+func NewExcursionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "excursions",
+		Short: "Show MAE/MFE against the final R of closed trades",
+		Long: `Show how far each closed stock trade went against (MAE) and in favour
+of (MFE) its entry, in R and in N, next to the R it closed at.
+
+Use it to tune StopMultiple_K: if winners rarely dip more than a fraction
+of their stop distance, the stop may be looser than it needs to be; if
+many winners came within a hair of 1R (or K in N) first, it may be too
+tight. Losers with a large MFE are trades that gave back open profit.
+
+Only trades with a tracked range (update-excursions) and a recorded 1R
+are listed.
+
+Examples:
+  tf-engine excursions`,
+		RunE: runExcursions,
+	}
+
+	return cmd
+}
+
+func runExcursions(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	positions, err := db.GetAllPositions("CLOSED")
+	if err != nil {
+		log.WithError(err).Error("Failed to get positions")
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+	scatter := analytics.BuildExcursionScatter(positions)
+
+	log.WithField("trades", len(scatter.Points)).Info("Excursions computed")
+
+	PrintHuman(format, "MAE / MFE vs Final R")
+	PrintHuman(format, "====================")
+	if len(scatter.Points) == 0 {
+		PrintHuman(format, "No closed trades with a tracked range (run update-excursions --all)")
+	} else {
+		PrintHumanf(format, "%-6s %-8s %-5s %-10s %7s %7s %7s %7s %7s\n", "ID", "TICKER", "DIR", "EXIT", "MAE R", "MAE N", "MFE R", "MFE N", "FINAL R")
+		for _, p := range scatter.Points {
+			PrintHumanf(format, "%-6d %-8s %-5s %-10s %7.2f %7.2f %7.2f %7.2f %7.2f\n",
+				p.PositionID, p.Ticker, p.Direction, p.ExitDate, p.MAER, p.MAEN, p.MFER, p.MFEN, p.FinalR)
+		}
+		PrintHuman(format, "")
+		PrintHumanf(format, "Winners: %d  avg MAE %.2fR, max MAE %.2fR / %.2fN\n",
+			scatter.Winners, scatter.AvgWinnerMAER, scatter.MaxWinnerMAER, scatter.MaxWinnerMAEN)
+		PrintHumanf(format, "Losers:  %d  avg MFE %.2fR\n", scatter.Losers, scatter.AvgLoserMFER)
+	}
+	if scatter.Untracked > 0 {
+		PrintHumanf(format, "Without a range or 1R: %d\n", scatter.Untracked)
+	}
+	PrintHuman(format, "")
+
+	// JSON output (always)
+	PrintJSON(scatter)
+
+	return nil
+}
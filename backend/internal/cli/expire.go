@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// ExpireRecord is the settlement of one expired option position
+type ExpireRecord struct {
+	domain.ExpirationResult
+	PositionID      int    `json:"position_id"`
+	SettlementDate  string `json:"settlement_date"` // Bar the settlement price came from
+	Outcome         string `json:"outcome"`
+	StockPositionID int    `json:"stock_position_id,omitempty"`
+	Processed       bool   `json:"processed"`
+	ProcessError    string `json:"process_error,omitempty"`
+}
+
+// ExpireReport is the output of expire
+type ExpireReport struct {
+	AsOfDate  string         `json:"as_of_date"`
+	Checked   int            `json:"checked"`
+	Positions []ExpireRecord `json:"positions"`
+	Errors    map[int]string `json:"errors,omitempty"` // Position ID -> why it was skipped
+	Processed int            `json:"processed"`
+	HeatAfter float64        `json:"heat_after"`
+}
+
+// NewExpireCommand creates the expire command
+func NewExpireCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expire",
+		Short: "Settle option positions at expiration, with assignment and exercise",
+		Long: `Settle every open option position whose nearest expiration is on or
+before --as-of at the underlying's settlement price:
+
+  EXPIRED    - out of the money, worthless
+  EXERCISED  - long leg in the money by a cent or more
+  ASSIGNED   - short leg in the money by a cent or more
+
+Exercised and assigned legs trade 100 shares a contract at the strike (a
+call buys, a put sells). The option position is closed with its P&L
+marked at the settlement price and written to trade_history; a loss
+starts the bucket cooldown. Any net shares left over (an assigned put, a
+covered call that was not called away) open a STOCK position in the same
+bucket, entered at the settlement price with the option position's stop
+(or --stop). Its cost basis keeps what the shares actually cost: strike
+less the premium collected.
+
+Positions with legs expiring later (calendars, diagonals) must be closed
+or rolled first.
+
+The settlement price is --settlement, or the stored close on the
+expiration date (see import-prices). --settlement is one underlying's
+price, so it needs --position or a single ticker in --tickers. Without
+--execute the settlements are only listed.
+
+Examples:
+  # List what expired
+  tf-engine expire
+
+  # Settle one position at a known price
+  tf-engine expire --position 12 --settlement 93.40 --execute
+
+  # Settle everything from stored closes, with a new stop for assigned stock
+  tf-engine expire --tickers AAPL --stop 88 --execute`,
+		RunE: runExpire,
+	}
+
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: all open option positions)")
+	cmd.Flags().Int("position", 0, "Only this position ID")
+	cmd.Flags().String("as-of", "", "Settle expirations on or before this date, YYYY-MM-DD (default: today)")
+	cmd.Flags().Float64("settlement", 0, "Underlying settlement price (default: stored close on the expiration date)")
+	cmd.Flags().Float64("stop", 0, "Stop for stock left by assignment or exercise (default: the position's stop)")
+	cmd.Flags().Bool("execute", false, "Close the expired positions and open any stock they leave")
+
+	return cmd
+}
+
+func runExpire(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	tickersFlag, _ := cmd.Flags().GetString("tickers")
+	positionID, _ := cmd.Flags().GetInt("position")
+	asOf, _ := cmd.Flags().GetString("as-of")
+	settlement, _ := cmd.Flags().GetFloat64("settlement")
+	stop, _ := cmd.Flags().GetFloat64("stop")
+	execute, _ := cmd.Flags().GetBool("execute")
+
+	// One settlement price can only be right for one underlying
+	if settlement > 0 && positionID == 0 && (strings.TrimSpace(tickersFlag) == "" || strings.Contains(tickersFlag, ",")) {
+		return fmt.Errorf("--settlement needs --position or a single ticker in --tickers")
+	}
+
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		return fmt.Errorf("invalid --as-of date %q (use YYYY-MM-DD)", asOf)
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		log.WithError(err).Error("Failed to get open positions")
+		return fmt.Errorf("failed to get open positions: %w", err)
+	}
+	positions = filterPositions(positions, tickersFlag)
+
+	var optionPositions []storage.Position
+	for _, p := range positions {
+		if p.InstrumentType == storage.InstrumentOption && (positionID == 0 || p.ID == positionID) {
+			optionPositions = append(optionPositions, p)
+		}
+	}
+	if positionID != 0 && len(optionPositions) == 0 {
+		return fmt.Errorf("no open option position with ID %d", positionID)
+	}
+
+	log.WithField("positions", len(optionPositions)).WithField("execute", execute).Info("Checking expirations")
+
+	provider := marketdata.NewDBBarProvider(db)
+	report := ExpireReport{
+		AsOfDate:  asOf,
+		Checked:   len(optionPositions),
+		Positions: []ExpireRecord{},
+	}
+	skip := func(position *storage.Position, err error) {
+		log.WithError(err).WithField("ticker", position.Ticker).WithField("position_id", position.ID).Warn("Skipping position")
+		if report.Errors == nil {
+			report.Errors = map[int]string{}
+		}
+		report.Errors[position.ID] = err.Error()
+	}
+
+	for i := range optionPositions {
+		position := &optionPositions[i]
+
+		var legs []options.Leg
+		if err := json.Unmarshal([]byte(position.LegsJSON), &legs); err != nil {
+			skip(position, fmt.Errorf("invalid legs: %w", err))
+			continue
+		}
+		expiration := options.NearestExpiration(legs)
+		if expiration == "" || expiration > asOf {
+			continue
+		}
+
+		price, priceDate := settlement, expiration
+		if price <= 0 {
+			if price, priceDate, err = settlementClose(provider, position.Ticker, expiration); err != nil {
+				skip(position, err)
+				continue
+			}
+		}
+		stockStop := stop
+		if stockStop <= 0 {
+			stockStop = position.CurrentStop
+		}
+
+		result, err := domain.SettleExpiration(domain.ExpirationRequest{
+			Ticker:     position.Ticker,
+			Legs:       legs,
+			Expiration: expiration,
+			Settlement: price,
+			Stop:       stockStop,
+		})
+		if err != nil {
+			skip(position, err)
+			continue
+		}
+
+		record := ExpireRecord{
+			ExpirationResult: *result,
+			PositionID:       position.ID,
+			SettlementDate:   priceDate,
+			Outcome:          storage.OutcomeForPnL(position.PnL + result.RealizedPnL),
+		}
+
+		if execute {
+			expiry := storage.OptionExpiration{
+				SettlementDate:  expiration,
+				SettlementPrice: price,
+				RealizedPnL:     result.RealizedPnL,
+				Detail:          result.Detail,
+			}
+			if result.Shares != 0 {
+				expiry.Stock = &storage.StockDelivery{
+					Direction:   result.StockDirection,
+					Shares:      max(result.Shares, -result.Shares),
+					EntryPrice:  result.StockEntry,
+					Stop:        result.StockStop,
+					RiskDollars: result.StockRisk,
+					CostBasis:   result.CostBasis,
+				}
+			}
+
+			stock, err := db.ExpirePosition(position.ID, expiry)
+			if err != nil {
+				record.ProcessError = err.Error()
+				log.WithError(err).WithField("ticker", position.Ticker).Error("Failed to settle expiration")
+			} else {
+				record.Processed = true
+				report.Processed++
+				if stock != nil {
+					record.StockPositionID = stock.ID
+				}
+				log.WithFields(map[string]interface{}{
+					"ticker":       position.Ticker,
+					"settlement":   price,
+					"realized_pnl": result.RealizedPnL,
+					"shares":       result.Shares,
+				}).Info("Expiration settled")
+			}
+		}
+
+		report.Positions = append(report.Positions, record)
+	}
+
+	if report.HeatAfter, err = db.CalculatePortfolioHeat(); err != nil {
+		return fmt.Errorf("failed to calculate portfolio heat: %w", err)
+	}
+
+	for _, r := range report.Positions {
+		mark := "✗"
+		if r.Processed {
+			mark = "✓"
+		}
+		switch {
+		case r.ProcessError != "":
+			PrintHumanf(format, "⚠️  %-6s #%d %s\n", r.Ticker, r.PositionID, r.ProcessError)
+		default:
+			PrintHumanf(format, "%s %-6s #%d %s %s\n", mark, r.Ticker, r.PositionID, r.Outcome, r.Detail)
+			if r.StockPositionID != 0 {
+				PrintHumanf(format, "    stock position #%d: stop $%.2f, risk $%.2f, cost basis $%.2f\n",
+					r.StockPositionID, r.StockStop, r.StockRisk, r.CostBasis)
+			}
+		}
+	}
+	tickers := make(map[int]string, len(optionPositions))
+	for _, p := range optionPositions {
+		tickers[p.ID] = p.Ticker
+	}
+	skipped := make([]int, 0, len(report.Errors))
+	for id := range report.Errors {
+		skipped = append(skipped, id)
+	}
+	sort.Ints(skipped)
+	for _, id := range skipped {
+		PrintHumanf(format, "⚠️  %-6s #%d %s\n", tickers[id], id, report.Errors[id])
+	}
+	PrintHumanf(format, "\n%d of %d option positions expired, %d settled (as of %s)\n",
+		len(report.Positions), report.Checked, report.Processed, report.AsOfDate)
+	PrintHumanf(format, "Portfolio heat: $%.2f\n", report.HeatAfter)
+
+	log.WithField("expired", len(report.Positions)).WithField("processed", report.Processed).Info("Expiration check completed")
+
+	if err := PrintJSON(report); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
+
+// settlementClose is the stored close on the expiration date, or the last
+// close before it when the market was shut that day
+func settlementClose(provider domain.BarProvider, ticker, expiration string) (float64, string, error) {
+	bars, err := provider.GetDailyBars(ticker)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to load bars (pass --settlement or import prices): %w", err)
+	}
+	for i := len(bars) - 1; i >= 0; i-- {
+		if bars[i].Date <= expiration {
+			if bars[i].Date < expiration && i == len(bars)-1 {
+				return 0, "", fmt.Errorf("no stored close on %s yet (last bar %s); pass --settlement", expiration, bars[i].Date)
+			}
+			return bars[i].Close, bars[i].Date, nil
+		}
+	}
+	return 0, "", fmt.Errorf("no stored close on or before %s; pass --settlement", expiration)
+}
@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/broker"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewImportFillsCommand creates the import-fills command
+func NewImportFillsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-fills",
+		Short: "Import broker fills and apply them to positions",
+		Long: `Import executions from an Interactive Brokers Flex Query trade report
+(XML or CSV), or from any broker's CSV export with --profile, and apply
+them to positions at the real fill prices, times and commissions.
+
+Each fill is matched by ticker and date:
+  - A fill against an open position exits shares from it (FIFO), closing
+    it when the last share goes
+  - A fill with an open position adds a unit, moving the stop to K × N
+    from the fill unless that would loosen it
+  - Any other fill opens a position from the latest unused GO decision
+    for the ticker dated up to 5 days before it
+
+  - An option fill is left for review with the option position it
+    belongs to: option positions are opened from an options session and
+    closed with roll or expire, never from fills
+
+Fills that cannot be matched are recorded for review-fills. Re-running on
+the same report is safe: fills already applied are skipped by execution
+id and unmatched ones are tried again. --dry-run applies the fills to a
+copy of the database and prints the positions they would open or change.
+
+The Flex Query needs the Trades section at execution level, with at
+least Symbol, IBExecID (or TradeID), TradeDate or DateTime, Quantity,
+TradePrice, IBCommission and Buy/Sell.
+
+A profile maps another broker's CSV columns to fills: date, symbol,
+side, quantity, price, fees and the OCC option symbol. Use a built-in
+profile by name (` + strings.Join(broker.BuiltinProfiles(), ", ") + `) or a YAML/JSON profile file.
+
+Examples:
+  tf-engine import-fills --file ~/Downloads/trades.xml
+  tf-engine import-fills --file ~/Downloads/trades.csv
+  tf-engine import-fills --profile schwab --file ~/Downloads/transactions.csv --dry-run
+  tf-engine import-fills --profile ~/brokers/mybroker.yaml --file export.csv`,
+		RunE: runImportFills,
+	}
+
+	cmd.Flags().String("file", "", "Flex Query trade report (.xml or .csv), or a CSV export with --profile")
+	cmd.Flags().String("profile", "", "Column profile for the CSV: a built-in name or a YAML/JSON file")
+	cmd.Flags().Bool("dry-run", false, "Show the position changes without saving anything")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runImportFills(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	file, _ := cmd.Flags().GetString("file")
+	profileName, _ := cmd.Flags().GetString("profile")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	brokerName := broker.BrokerIBKR
+	var fills []broker.Fill
+	var err error
+	if profileName != "" {
+		profile, perr := broker.LoadProfile(profileName)
+		if perr != nil {
+			log.WithError(perr).Error("Failed to load profile")
+			return fmt.Errorf("failed to load profile: %w", perr)
+		}
+		brokerName = profile.BrokerName()
+		fills, err = profile.ParseFile(file)
+	} else {
+		fills, err = broker.ParseFlexFile(file)
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to read fills")
+		return fmt.Errorf("failed to read fills: %w", err)
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	apply := broker.ApplyFills
+	if dryRun {
+		apply = broker.PreviewFills
+	}
+	report, err := apply(db, brokerName, fills)
+	if err != nil {
+		log.WithError(err).Error("Failed to apply fills")
+		return fmt.Errorf("failed to apply fills: %w", err)
+	}
+
+	log.WithFields(map[string]interface{}{
+		"file":       file,
+		"broker":     brokerName,
+		"dry_run":    dryRun,
+		"applied":    report.Applied,
+		"unmatched":  report.Unmatched,
+		"duplicates": report.Duplicates,
+	}).Info("Fills imported")
+
+	printImportReport(format, report)
+	if dryRun {
+		printPositionChanges(format, report.Changes)
+	}
+
+	// JSON output (always)
+	PrintJSON(report)
+
+	return nil
+}
+
+// printImportReport prints one line per fill and the totals
+func printImportReport(format OutputFormat, report *broker.ImportReport) {
+	PrintHuman(format, "Fill Import")
+	PrintHuman(format, "===========")
+	for _, r := range report.Results {
+		mark := "✓"
+		switch {
+		case r.Duplicate:
+			mark = "="
+		case r.Status == storage.FillUnmatched:
+			mark = "?"
+		}
+		PrintHumanf(format, "%s %s %-8s %-4s %6d @ %9.2f  %s\n",
+			mark, r.TradeDate, r.Ticker, r.Side, r.Quantity, r.Price, fillNote(r))
+	}
+	PrintHuman(format, "")
+	PrintHumanf(format, "Applied: %d  Unmatched: %d  Already imported: %d\n",
+		report.Applied, report.Unmatched, report.Duplicates)
+	if report.Unmatched > 0 {
+		PrintHuman(format, "Run review-fills to see what needs attention")
+	}
+	PrintHuman(format, "")
+}
+
+// printPositionChanges prints the dry-run diff: + for a new position, ~ for
+// a changed one with its fields before → after
+func printPositionChanges(format OutputFormat, changes []broker.PositionChange) {
+	PrintHuman(format, "Dry run: nothing was saved. Position changes:")
+	if len(changes) == 0 {
+		PrintHuman(format, "  (none)")
+	}
+	for _, c := range changes {
+		after := c.After
+		if c.Before == nil {
+			PrintHumanf(format, "+ #%-4d %-8s new %s: %d @ %.2f, stop %.2f, P&L %.2f\n",
+				c.PositionID, c.Ticker, after.Status, after.Shares, after.EntryPrice, after.CurrentStop, after.PnL)
+			continue
+		}
+		before := *c.Before
+		var diffs []string
+		if before.Status != after.Status {
+			diffs = append(diffs, fmt.Sprintf("status %s → %s", before.Status, after.Status))
+		}
+		if before.Shares != after.Shares {
+			diffs = append(diffs, fmt.Sprintf("shares %d → %d", before.Shares, after.Shares))
+		}
+		if before.EntryPrice != after.EntryPrice {
+			diffs = append(diffs, fmt.Sprintf("entry %.2f → %.2f", before.EntryPrice, after.EntryPrice))
+		}
+		if before.CurrentStop != after.CurrentStop {
+			diffs = append(diffs, fmt.Sprintf("stop %.2f → %.2f", before.CurrentStop, after.CurrentStop))
+		}
+		if before.PnL != after.PnL {
+			diffs = append(diffs, fmt.Sprintf("P&L %.2f → %.2f", before.PnL, after.PnL))
+		}
+		if before.Commissions != after.Commissions {
+			diffs = append(diffs, fmt.Sprintf("commissions %.2f → %.2f", before.Commissions, after.Commissions))
+		}
+		PrintHumanf(format, "~ #%-4d %-8s %s\n", c.PositionID, c.Ticker, strings.Join(diffs, ", "))
+	}
+	PrintHuman(format, "")
+}
+
+func fillNote(r broker.FillResult) string {
+	if r.Duplicate && r.Status == storage.FillPending {
+		return "pending from an interrupted import, see review-fills"
+	}
+	if r.Duplicate {
+		return "already imported"
+	}
+	return r.Detail
+}
+
+// NewReviewFillsCommand creates the review-fills command
+func NewReviewFillsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review-fills",
+		Short: "List imported fills that need review",
+		Long: `List broker fills that import-fills could not match to a position or
+decision, with the reason.
+
+Fix the cause (save the missing decision, enter the position) and
+re-import the report, or mark a fill as reviewed with --ignore so it is
+left out of later imports.
+
+A PENDING fill was being applied when an import was interrupted. Check
+whether its position changed, then mark it as reviewed with --ignore.
+
+Examples:
+  tf-engine review-fills
+  tf-engine review-fills --all
+  tf-engine review-fills --ignore 12 --reason "closed before tracking"`,
+		RunE: runReviewFills,
+	}
+
+	cmd.Flags().Bool("all", false, "List every imported fill, not only unmatched ones")
+	cmd.Flags().Int("ignore", 0, "Mark the unmatched or pending fill with this id as reviewed")
+	cmd.Flags().String("reason", "", "Note to keep with --ignore")
+
+	return cmd
+}
+
+func runReviewFills(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	all, _ := cmd.Flags().GetBool("all")
+	ignore, _ := cmd.Flags().GetInt("ignore")
+	reason, _ := cmd.Flags().GetString("reason")
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if ignore > 0 {
+		if err := db.IgnoreBrokerFill(ignore, reason); err != nil {
+			log.WithError(err).Error("Failed to ignore fill")
+			return fmt.Errorf("failed to ignore fill: %w", err)
+		}
+		log.WithField("fill_id", ignore).Info("Fill ignored")
+		PrintHumanf(format, "✓ Fill %d marked as reviewed\n\n", ignore)
+	}
+
+	// Unmatched fills, and any left pending by an interrupted import
+	statuses := []string{storage.FillUnmatched, storage.FillPending}
+	if all {
+		statuses = []string{""}
+	}
+	fills := []storage.BrokerFill{}
+	for _, status := range statuses {
+		listed, err := db.ListBrokerFills(status)
+		if err != nil {
+			log.WithError(err).Error("Failed to list fills")
+			return fmt.Errorf("failed to list fills: %w", err)
+		}
+		fills = append(fills, listed...)
+	}
+
+	PrintHuman(format, "Broker Fills")
+	PrintHuman(format, "============")
+	if len(fills) == 0 {
+		PrintHuman(format, "Nothing to review")
+	} else {
+		PrintHumanf(format, "%-5s %-10s %-8s %-4s %6s %9s %-9s %s\n", "ID", "DATE", "TICKER", "SIDE", "QTY", "PRICE", "STATUS", "DETAIL")
+		for _, f := range fills {
+			PrintHumanf(format, "%-5d %-10s %-8s %-4s %6d %9.2f %-9s %s\n",
+				f.ID, f.TradeDate, f.Ticker, f.Side, f.Quantity, f.Price, f.Status, f.Detail)
+		}
+	}
+	PrintHuman(format, "")
+
+	// JSON output (always)
+	PrintJSON(fills)
+
+	return nil
+}
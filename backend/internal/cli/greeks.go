@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewGreeksCommand creates the greeks command
+func NewGreeksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "greeks",
+		Short: "Price option legs and show delta, gamma, theta, vega and IV",
+		Long: `Price option legs with Black-Scholes (with a continuous dividend yield).
+
+Legs use the same JSON as a session's legs: type, strike, exp, qty, action
+and price. IV is solved from each leg's price unless --iv is given. Each
+leg's Greeks are shown for the position (× qty × 100, negative for SELL
+legs), followed by the position total. JSON output also has the per-share
+Greeks of every leg.
+
+The underlying price is --spot, or the last stored close for --ticker
+(see import-prices).
+
+Examples:
+  # Bull call spread
+  tf-engine greeks --spot 180 --legs '[
+    {"type":"CALL","strike":180,"exp":"2025-03-21","qty":1,"action":"BUY","price":6.10},
+    {"type":"CALL","strike":190,"exp":"2025-03-21","qty":1,"action":"SELL","price":2.40}]'
+
+  # Spot from stored bars, fixed volatility
+  tf-engine greeks --ticker AAPL --iv 0.28 --legs '[{"type":"PUT","strike":170,"exp":"2025-03-21","qty":2,"action":"BUY"}]'`,
+		RunE: runGreeks,
+	}
+
+	cmd.Flags().String("legs", "", "Option legs as JSON (required)")
+	cmd.Flags().Float64("spot", 0, "Underlying price (default: last stored close for --ticker)")
+	cmd.Flags().String("ticker", "", "Ticker used to look up the underlying price when --spot is omitted")
+	cmd.Flags().Float64("iv", 0, "Volatility as decimal for every leg (default: solved from leg prices)")
+	cmd.Flags().Float64("rate", options.DefaultRiskFreeRate, "Risk-free rate as decimal")
+	cmd.Flags().Float64("div-yield", 0, "Dividend yield as decimal")
+	cmd.Flags().String("as-of", "", "Pricing date YYYY-MM-DD (default: today)")
+
+	cmd.MarkFlagRequired("legs")
+
+	return cmd
+}
+
+func runGreeks(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	legsJSON, _ := cmd.Flags().GetString("legs")
+	spot, _ := cmd.Flags().GetFloat64("spot")
+	ticker, _ := cmd.Flags().GetString("ticker")
+	iv, _ := cmd.Flags().GetFloat64("iv")
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	divYield, _ := cmd.Flags().GetFloat64("div-yield")
+	asOf, _ := cmd.Flags().GetString("as-of")
+
+	var legs []options.Leg
+	if err := json.Unmarshal([]byte(legsJSON), &legs); err != nil {
+		return fmt.Errorf("invalid --legs JSON: %w", err)
+	}
+
+	if spot <= 0 {
+		if ticker == "" {
+			return fmt.Errorf("--spot or --ticker is required")
+		}
+		db, err := storage.New(dbPath)
+		if err != nil {
+			log.WithError(err).Error("Failed to open database")
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		bars, err := marketdata.NewDBBarProvider(db).GetDailyBars(strings.ToUpper(ticker))
+		if err != nil {
+			return fmt.Errorf("failed to load bars (pass --spot or import prices): %w", err)
+		}
+		spot = bars[len(bars)-1].Close
+		log.WithField("spot", spot).WithField("date", bars[len(bars)-1].Date).Info("Using last stored close")
+	}
+
+	market := options.Market{Spot: spot, Rate: rate, DivYield: divYield, Vol: iv, AsOf: asOf}
+	result, err := options.PricePosition(legs, market)
+	if err != nil {
+		log.WithError(err).Error("Failed to price legs")
+		return fmt.Errorf("failed to price legs: %w", err)
+	}
+
+	log.WithField("legs", len(result.Legs)).WithField("delta", result.Total.Delta).Info("Priced option legs")
+
+	if format == FormatJSON {
+		return PrintJSON(result)
+	}
+
+	PrintHumanf(format, "Spot $%.2f  as of %s\n\n", result.Spot, result.AsOf)
+	PrintHumanf(format, "%-5s %-4s %8s %-10s %4s %6s %8s %7s %7s %8s %7s\n",
+		"ACT", "TYPE", "STRIKE", "EXP", "QTY", "IV", "VALUE", "DELTA", "GAMMA", "THETA", "VEGA")
+	for _, lg := range result.Legs {
+		PrintHumanf(format, "%-5s %-4s %8.2f %-10s %4d %5.1f%% %8.2f %7.2f %7.3f %8.2f %7.2f\n",
+			lg.Leg.Action, lg.Leg.Type, lg.Leg.Strike, lg.Leg.Exp, lg.Leg.Qty, lg.IV*100,
+			lg.Position.Price, lg.Position.Delta, lg.Position.Gamma, lg.Position.Theta, lg.Position.Vega)
+	}
+	PrintHumanf(format, "%-43s %8.2f %7.2f %7.3f %8.2f %7.2f\n", "TOTAL",
+		result.Total.Price, result.Total.Delta, result.Total.Gamma, result.Total.Theta, result.Total.Vega)
+
+	return nil
+}
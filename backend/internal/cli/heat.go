@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/trading-engine/internal/domain"
 	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -17,6 +21,21 @@ func NewCheckHeatCommand() *cobra.Command {
 		Short: "Check portfolio and bucket heat",
 		Long: `Check current portfolio heat and bucket heat status.
 
+With --greeks, heat also nets delta, gamma and vega dollars per bucket and
+for the whole portfolio from each position's stored legs (stock counts as
+delta only), valued at the last stored close. The proposed trade's
+exposure comes from --legs at --spot (or the last close of --ticker), and
+each measure is checked against its cap setting (DeltaHeatCap_pct,
+GammaHeatCap_pct, VegaHeatCap_pct and their Bucket* versions) as well as
+the dollar caps.
+
+With --correlation, the open positions' tickers and --ticker are clustered
+by their rolling daily return correlation (--corr-lookback returns from
+the bars stored by import-prices, the same bars the heat gate of
+save-decision clusters with). Tickers correlated at CorrelationThreshold
+or more share a cluster whatever their bucket, and the risk across the
+proposed trade's cluster is capped at ClusterHeatCap_pct of equity.
+
 Examples:
   # Check current heat with no new trade
   tf-engine heat
@@ -25,12 +44,28 @@ Examples:
   tf-engine heat --risk 75 --bucket "Tech/Comm"
 
   # With JSON output
-  tf-engine heat --risk 75 --format json`,
+  tf-engine heat --risk 75 --format json
+
+  # Greeks heat for a proposed short strangle
+  tf-engine heat --greeks --risk 300 --bucket "Tech/Comm" --ticker AAPL \
+    --legs '[{"type":"PUT","strike":170,"exp":"2025-03-21","qty":2,"action":"SELL","price":2.10},
+             {"type":"CALL","strike":200,"exp":"2025-03-21","qty":2,"action":"SELL","price":1.85}]'
+
+  # Correlated-cluster check for a new NVDA trade
+  tf-engine heat --correlation --ticker NVDA --risk 75 --bucket "Tech/Comm"`,
 		RunE: runCheckHeat,
 	}
 
 	cmd.Flags().Float64("risk", 0, "Risk dollars for proposed new trade")
 	cmd.Flags().String("bucket", "", "Bucket for proposed new trade")
+	cmd.Flags().Bool("greeks", false, "Also check net delta, gamma and vega dollars against their caps")
+	cmd.Flags().String("legs", "", "Legs of the proposed new trade as JSON (with --greeks)")
+	cmd.Flags().String("ticker", "", "Underlying of the proposed new trade, for its last stored close")
+	cmd.Flags().Float64("spot", 0, "Underlying price of the proposed new trade (default: last stored close of --ticker)")
+	cmd.Flags().Float64("iv", 0, "Volatility as decimal for every option leg (default: solved from leg prices)")
+	cmd.Flags().Float64("rate", options.DefaultRiskFreeRate, "Risk-free rate as decimal")
+	cmd.Flags().Bool("correlation", false, "Cap risk across the proposed ticker's correlated cluster")
+	cmd.Flags().Int("corr-lookback", domain.DefaultCorrelationLookback, "Daily returns in the rolling correlation window")
 
 	return cmd
 }
@@ -43,10 +78,24 @@ func runCheckHeat(cmd *cobra.Command, args []string) error {
 
 	addRisk, _ := cmd.Flags().GetFloat64("risk")
 	addBucket, _ := cmd.Flags().GetString("bucket")
+	greeks, _ := cmd.Flags().GetBool("greeks")
+	legsJSON, _ := cmd.Flags().GetString("legs")
+	ticker, _ := cmd.Flags().GetString("ticker")
+	spot, _ := cmd.Flags().GetFloat64("spot")
+	iv, _ := cmd.Flags().GetFloat64("iv")
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	correlation, _ := cmd.Flags().GetBool("correlation")
+	corrLookback, _ := cmd.Flags().GetInt("corr-lookback")
+
+	if legsJSON != "" && !greeks {
+		return fmt.Errorf("--legs needs --greeks")
+	}
 
 	log.WithFields(map[string]interface{}{
-		"add_risk":   addRisk,
-		"add_bucket": addBucket,
+		"add_risk":    addRisk,
+		"add_bucket":  addBucket,
+		"greeks":      greeks,
+		"correlation": correlation,
 	}).Info("Checking heat")
 
 	db, err := storage.New(dbPath)
@@ -63,9 +112,9 @@ func runCheckHeat(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get settings: %w", err)
 	}
 
-	equity, err := strconv.ParseFloat(settings["Equity_E"], 64)
+	equity, err := marketdata.SizingEquity(db)
 	if err != nil {
-		return fmt.Errorf("invalid Equity_E setting: %w", err)
+		return fmt.Errorf("failed to resolve equity: %w", err)
 	}
 
 	heatCapPct, err := strconv.ParseFloat(settings["HeatCap_H_pct"], 64)
@@ -107,6 +156,66 @@ func runCheckHeat(cmd *cobra.Command, args []string) error {
 		OpenPositions:    openPositions,
 	}
 
+	provider := marketdata.NewDBBarProvider(db)
+	var matrix *domain.CorrelationMatrix
+	var threshold float64
+	if correlation {
+		var capPct float64
+		if threshold, capPct, err = domain.ClusterHeatFromSettings(settings); err != nil {
+			return err
+		}
+
+		tickers := []string{}
+		if ticker != "" {
+			tickers = append(tickers, ticker)
+		}
+		for _, p := range positions {
+			tickers = append(tickers, p.Ticker)
+		}
+		if matrix, err = domain.CorrelateTickers(provider, tickers, corrLookback); err != nil {
+			return err
+		}
+		heatReq.AddTicker = strings.ToUpper(ticker)
+		heatReq.ClusterHeatCapPct = capPct
+		heatReq.Clusters = matrix.Clusters(threshold)
+	}
+
+	if greeks {
+		heatReq.Mode = domain.HeatModeGreeks
+		if heatReq.GreeksCaps, err = domain.GreeksHeatCapsFromSettings(settings); err != nil {
+			return err
+		}
+
+		for i := range positions {
+			exposure, err := marketdata.PositionExposure(provider, &positions[i], iv, rate)
+			if err != nil {
+				return fmt.Errorf("%s (position %d): %w", positions[i].Ticker, positions[i].ID, err)
+			}
+			openPositions[i].Exposure = exposure
+		}
+
+		if legsJSON != "" {
+			var legs []options.Leg
+			if err := json.Unmarshal([]byte(legsJSON), &legs); err != nil {
+				return fmt.Errorf("invalid --legs JSON: %w", err)
+			}
+			if spot <= 0 {
+				if ticker == "" {
+					return fmt.Errorf("--spot or --ticker is required with --legs")
+				}
+				bars, err := provider.GetDailyBars(strings.ToUpper(ticker))
+				if err != nil || len(bars) == 0 {
+					return fmt.Errorf("no stored close for %s (pass --spot or import prices)", ticker)
+				}
+				spot = bars[len(bars)-1].Close
+			}
+			market := options.Market{Spot: spot, Rate: rate, Vol: iv}
+			if heatReq.AddExposure, err = domain.OptionExposure(legs, 0, "", market); err != nil {
+				return fmt.Errorf("failed to value --legs: %w", err)
+			}
+		}
+	}
+
 	result, err := domain.CalculateHeat(heatReq)
 	if err != nil {
 		log.WithError(err).Error("Failed to calculate heat")
@@ -157,11 +266,66 @@ func runCheckHeat(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if matrix != nil {
+		PrintHuman(format, "")
+		PrintHumanf(format, "Correlated Clusters (%d-day returns, threshold %.2f)\n", matrix.Lookback, threshold)
+		PrintHuman(format, "=====================")
+		if len(heatReq.Clusters) == 0 {
+			PrintHuman(format, "No correlated tickers")
+		}
+		for _, c := range heatReq.Clusters {
+			PrintHumanf(format, "[%s]\n", strings.Join(c.Tickers, ", "))
+			for _, l := range c.Links {
+				PrintHumanf(format, "    %s/%s %.2f (%d returns)\n", l.A, l.B, l.Correlation, l.Observations)
+			}
+		}
+		for name, why := range matrix.Missing {
+			PrintHumanf(format, "⚠️  %s: %s\n", name, why)
+		}
+		if c := result.Cluster; c != nil {
+			PrintHuman(format, "")
+			PrintHumanf(format, "Cluster Heat: [%s]\n", strings.Join(c.Tickers, ", "))
+			PrintHumanf(format, "Current Heat:  $%.2f\n", c.CurrentHeat)
+			PrintHumanf(format, "New Heat:      $%.2f\n", c.NewHeat)
+			PrintHumanf(format, "Cluster Cap:   $%.2f (%.1f%% of equity)\n", c.Cap, heatReq.ClusterHeatCapPct*100)
+			if c.Exceeded {
+				PrintHumanf(format, "❌ EXCEEDED by $%.2f\n", c.Overage)
+			} else {
+				PrintHuman(format, "✓ Within limits")
+			}
+		}
+	}
+
+	if result.Greeks != nil {
+		g := result.Greeks
+		PrintHuman(format, "")
+		PrintHuman(format, "Greeks Exposure (net, with the new trade)")
+		PrintHuman(format, "=========================================")
+		PrintHumanf(format, "%-16s %12s %12s %12s\n", "", "DELTA $", "GAMMA $/1%", "VEGA $/pt")
+		PrintHumanf(format, "%-16s %12.2f %12.2f %12.2f\n", "Portfolio",
+			g.Portfolio.DeltaDollars, g.Portfolio.GammaDollars, g.Portfolio.VegaDollars)
+		for _, name := range g.BucketNames() {
+			b := g.Buckets[name]
+			PrintHumanf(format, "%-16s %12.2f %12.2f %12.2f\n", name, b.DeltaDollars, b.GammaDollars, b.VegaDollars)
+		}
+		if len(g.Checks) > 0 {
+			PrintHuman(format, "")
+		}
+		for _, c := range g.Checks {
+			mark := "✓"
+			if c.Exceeded {
+				mark = "❌"
+			}
+			PrintHumanf(format, "%s %-9s %-5s $%.2f -> $%.2f (cap $%.2f)\n",
+				mark, c.Scope, c.Measure, c.Current, c.New, c.Cap)
+		}
+	}
+
 	PrintHuman(format, "")
 	if result.Allowed {
 		PrintHuman(format, "✓ Trade ALLOWED")
 	} else {
-		PrintHumanf(format, "❌ Trade REJECTED: %s\n", result.RejectionReason)
+		PrintHumanf(format, "❌ Trade REJECTED (%s): %s\n", result.RejectedMeasure, result.RejectionReason)
 	}
 
 	// List open positions
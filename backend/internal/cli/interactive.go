@@ -153,7 +153,7 @@ func printFinalStats(count int, pages int, date string, preset string) {
 	fmt.Println("║  2. Check Dashboard for today's candidates               ║")
 	fmt.Println("║  3. Evaluate trades using Trade Entry sheet              ║")
 	fmt.Println("║                                                          ║")
-	fmt.Println("║  💪 The 5 Hard Gates will enforce discipline!            ║")
+	fmt.Println("║  💪 The 7 Hard Gates will enforce discipline!            ║")
 	fmt.Println("║  🚫 Only trade tickers from today's candidates!          ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════╝")
 	fmt.Println()
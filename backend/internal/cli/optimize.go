@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/backtest"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+)
+
+// NewOptimizeCommand creates the optimize command
+func NewOptimizeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "optimize",
+		Short: "Sweep risk settings over historical bars and rank the results",
+		Long: `Run the backtester over a grid of settings and rank every combination.
+
+Each combination (K × risk × system × heat caps) is a full backtest using
+the live sizing, heat and cooldown rules (see 'backtest'). Combinations run
+concurrently on --workers goroutines. Settings not listed in a grid flag
+keep their current database value.
+
+Walk-forward mode splits the date range into rolling windows: each
+combination is tested on --in-sample-months of history, the one with the
+best in-sample score is chosen for the window, and the chosen one is then
+judged on the following --out-sample-months. Combinations that were chosen
+rank first, on their out-of-sample averages; the rest follow on their
+in-sample averages. The walk-forward line (every window's choice, out of
+sample) is the number to trust before running set-setting.
+
+Rankings are sorted by MAR (CAGR ÷ max drawdown) unless --rank-by says
+otherwise, printed as JSON and optionally written to --csv.
+
+Examples:
+  # K against risk against system over all CSVs in ./data/prices
+  tf-engine optimize --k 1.5,2,2.5,3 --risk 0.005,0.0075,0.01 --systems SYSTEM_1,SYSTEM_2
+
+  # Walk forward: 3 years in-sample, 1 year out-of-sample, save the table
+  tf-engine optimize --k 1.5,2,2.5,3 --walk-forward --in-sample-months 36 \
+    --out-sample-months 12 --csv ranking.csv
+
+  # Then adopt the winner
+  tf-engine set-setting --key StopMultiple_K --value 2.5`,
+		RunE: runOptimize,
+	}
+
+	cmd.Flags().String("dir", marketdata.DefaultPriceDir, "Folder of <TICKER>.csv files")
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: every CSV in --dir)")
+	cmd.Flags().String("start", "", "First date to test (YYYY-MM-DD, default: first bar)")
+	cmd.Flags().String("end", "", "Last date to test (YYYY-MM-DD, default: last bar)")
+	cmd.Flags().String("buckets", "", "Ticker buckets, e.g. AAPL=Tech/Comm,XOM=Energy")
+	cmd.Flags().Bool("skip-filter", true, "Apply the System 1 last-breakout skip filter")
+	cmd.Flags().Float64("equity", 0, "Starting equity (default: Equity_E setting)")
+	cmd.Flags().String("k", "", "Stop multiples to try, e.g. 1.5,2,2.5,3")
+	cmd.Flags().String("risk", "", "Risk per trade decimals to try, e.g. 0.005,0.0075,0.01")
+	cmd.Flags().String("systems", "", "Breakout systems to try, e.g. SYSTEM_1,SYSTEM_2 (default: SYSTEM_2)")
+	cmd.Flags().String("heat-cap", "", "Portfolio heat caps to try, e.g. 0.04,0.06")
+	cmd.Flags().String("bucket-cap", "", "Bucket heat caps to try, e.g. 0.015,0.02")
+	cmd.Flags().Bool("walk-forward", false, "Choose on rolling in-sample windows, judge out of sample")
+	cmd.Flags().Int("in-sample-months", 24, "Walk-forward in-sample window length")
+	cmd.Flags().Int("out-sample-months", 6, "Walk-forward out-of-sample window length")
+	cmd.Flags().Int("workers", 0, "Concurrent backtests (default: number of CPUs)")
+	cmd.Flags().String("rank-by", backtest.RankByMAR, "Rank by: mar, cagr or return")
+	cmd.Flags().Int("top", 10, "Rows to print in the human summary (0 = all)")
+	cmd.Flags().String("csv", "", "Write the ranked table to this CSV file")
+
+	return cmd
+}
+
+func runOptimize(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	base, bars, err := backtestInputs(cmd, dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to prepare optimizer")
+		return err
+	}
+
+	grid, err := optimizeGrid(cmd)
+	if err != nil {
+		return err
+	}
+
+	walkForward, _ := cmd.Flags().GetBool("walk-forward")
+	workers, _ := cmd.Flags().GetInt("workers")
+	rankBy, _ := cmd.Flags().GetString("rank-by")
+	top, _ := cmd.Flags().GetInt("top")
+	csvPath, _ := cmd.Flags().GetString("csv")
+
+	opts := backtest.OptimizeOptions{Workers: workers, RankBy: rankBy}
+	if walkForward {
+		inMonths, _ := cmd.Flags().GetInt("in-sample-months")
+		outMonths, _ := cmd.Flags().GetInt("out-sample-months")
+
+		first, last := backtest.DateRange(bars)
+		if base.Start != "" {
+			first = base.Start
+		}
+		if base.End != "" {
+			last = base.End
+		}
+		opts.Windows, err = backtest.WalkForwardWindows(first, last, inMonths, outMonths)
+		if err != nil {
+			log.WithError(err).Error("Failed to build walk-forward windows")
+			return fmt.Errorf("failed to build walk-forward windows: %w", err)
+		}
+	}
+
+	log.WithField("tickers", len(bars)).WithField("windows", len(opts.Windows)).Info("Running optimizer")
+
+	rankings, err := backtest.Optimize(base, grid, bars, opts)
+	if err != nil {
+		log.WithError(err).Error("Optimizer failed")
+		return fmt.Errorf("optimizer failed: %w", err)
+	}
+
+	if csvPath != "" {
+		if err := writeCSVFile(csvPath, func(f *os.File) error { return backtest.WriteRankingsCSV(f, rankings) }); err != nil {
+			return err
+		}
+	}
+
+	if walkForward {
+		PrintHumanf(format, "Walk-forward ranking (%d windows, chosen on in-sample %s, judged out of sample)\n", len(opts.Windows), rankBy)
+		wf := backtest.WalkForwardOutOfSample(rankings)
+		PrintHumanf(format, "Walk-forward out of sample: CAGR %.2f%%, max drawdown %.2f%%, MAR %.2f, %d trades\n",
+			wf.CAGRPct, wf.MaxDrawdownPct, wf.MAR, wf.TradeCount)
+	} else {
+		PrintHumanf(format, "Full-period ranking (ranked on %s)\n", rankBy)
+	}
+	header := fmt.Sprintf("%4s  %-8s  %4s  %6s  %6s  %6s  %8s  %8s  %6s  %6s",
+		"Rank", "System", "K", "Risk%", "Heat%", "Bkt%", "CAGR%", "MaxDD%", "MAR", "Trades")
+	if walkForward {
+		header += fmt.Sprintf("  %6s  %6s", "IS MAR", "Chosen")
+	}
+	PrintHuman(format, header)
+	for _, r := range rankings {
+		if top > 0 && r.Rank > top {
+			break
+		}
+		row := fmt.Sprintf("%4d  %-8s  %4g  %6.2f  %6.2f  %6.2f",
+			r.Rank, r.System, r.K, r.RiskPct*100, r.HeatCapPct*100, r.BucketHeatCapPct*100)
+		switch {
+		case !walkForward:
+			m := r.InSample
+			row += fmt.Sprintf("  %8.2f  %8.2f  %6.2f  %6d", m.CAGRPct, m.MaxDrawdownPct, m.MAR, m.TradeCount)
+		case r.OutOfSample != nil:
+			m := *r.OutOfSample
+			row += fmt.Sprintf("  %8.2f  %8.2f  %6.2f  %6d", m.CAGRPct, m.MaxDrawdownPct, m.MAR, m.TradeCount)
+		default:
+			// Never chosen, so never judged out of sample
+			row += fmt.Sprintf("  %8s  %8s  %6s  %6s", "-", "-", "-", "-")
+		}
+		if walkForward {
+			row += fmt.Sprintf("  %6.2f  %6d", r.InSample.MAR, r.Selected)
+		}
+		PrintHuman(format, row)
+	}
+	PrintHuman(format, "")
+
+	log.WithField("combinations", len(rankings)).Info("Optimizer completed")
+
+	if err := PrintJSON(rankings); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
+
+// optimizeGrid parses the comma-separated grid flags
+func optimizeGrid(cmd *cobra.Command) (backtest.Grid, error) {
+	grid := backtest.Grid{}
+	lists := []struct {
+		flag   string
+		values *[]float64
+	}{
+		{"k", &grid.Ks},
+		{"risk", &grid.RiskPcts},
+		{"heat-cap", &grid.HeatCapPcts},
+		{"bucket-cap", &grid.BucketHeatCapPcts},
+	}
+	for _, l := range lists {
+		raw, _ := cmd.Flags().GetString(l.flag)
+		values, err := parseFloatList(raw)
+		if err != nil {
+			return grid, fmt.Errorf("invalid --%s: %w", l.flag, err)
+		}
+		*l.values = values
+	}
+
+	systems, _ := cmd.Flags().GetString("systems")
+	for _, s := range strings.Split(systems, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			grid.Systems = append(grid.Systems, s)
+		}
+	}
+	return grid, nil
+}
+
+// parseFloatList parses "1.5,2,2.5" into floats; an empty string is an empty list
+func parseFloatList(raw string) ([]float64, error) {
+	var values []float64
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/analytics"
 	"github.com/yourusername/trading-engine/internal/logx"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
@@ -66,7 +67,7 @@ func runOpenPosition(cmd *cobra.Command, args []string) error {
 		output, _ := json.MarshalIndent(position, "", "  ")
 		fmt.Println(string(output))
 	} else {
-		fmt.Printf("✓ Position opened: %s\n", ticker)
+		fmt.Printf("✓ Position opened: %s %s\n", ticker, position.Direction)
 		fmt.Printf("  Entry:  $%.2f\n", position.EntryPrice)
 		fmt.Printf("  Stop:   $%.2f\n", position.CurrentStop)
 		fmt.Printf("  Shares: %d\n", position.Shares)
@@ -86,6 +87,8 @@ func NewUpdateStopCommand() *cobra.Command {
 		Short: "Update position stop price",
 		Long: `Update the stop loss price for an open position.
 
+Stops only move in the trade's favour: up for a long, down for a short.
+
 Examples:
   # Update stop for AAPL to $179
   tf-engine update-stop --ticker AAPL --new-stop 179
@@ -156,6 +159,13 @@ func NewClosePositionCommand() *cobra.Command {
 		Short: "Close an open position",
 		Long: `Close a position and record outcome (WIN/LOSS/SCRATCH).
 
+With --shares or --pct only part of the position is sold (scale-out). The
+shares come out of the position's lots by --method (FIFO, LIFO or
+AVERAGE cost); P&L is realized on them, the remaining risk and heat are
+recomputed from the lots left, and the exit is written to trade history.
+The position stays open until the last share is sold; that exit closes it
+with the outcome of its total P&L unless --outcome is given.
+
 Examples:
   # Close AAPL position with exit at $185, outcome WIN
   tf-engine close-position --ticker AAPL --exit 185 --outcome WIN
@@ -163,6 +173,12 @@ Examples:
   # Close with LOSS
   tf-engine close-position --ticker AAPL --exit 176 --outcome LOSS
 
+  # Take half off at 2R and trail the rest
+  tf-engine close-position --ticker AAPL --exit 186 --pct 50
+
+  # Sell 30 shares from the newest units first
+  tf-engine close-position --ticker AAPL --exit 186 --shares 30 --method LIFO
+
   # With JSON output
   tf-engine close-position --ticker AAPL --exit 180 --outcome SCRATCH --json`,
 		RunE: runClosePosition,
@@ -170,12 +186,14 @@ Examples:
 
 	cmd.Flags().String("ticker", "", "Ticker symbol (required)")
 	cmd.Flags().Float64("exit", 0, "Exit price (required)")
-	cmd.Flags().String("outcome", "", "Outcome: WIN/LOSS/SCRATCH (required)")
+	cmd.Flags().String("outcome", "", "Outcome: WIN/LOSS/SCRATCH (required when closing everything)")
+	cmd.Flags().Int("shares", 0, "Sell only this many shares")
+	cmd.Flags().Float64("pct", 0, "Sell only this percent of the open shares (1-100)")
+	cmd.Flags().String("method", string(storage.CostMethodFIFO), "Lots to sell first: FIFO, LIFO or AVERAGE")
 	cmd.Flags().Bool("json", false, "Output in JSON format")
 
 	cmd.MarkFlagRequired("ticker")
 	cmd.MarkFlagRequired("exit")
-	cmd.MarkFlagRequired("outcome")
 
 	return cmd
 }
@@ -188,17 +206,32 @@ func runClosePosition(cmd *cobra.Command, args []string) error {
 	ticker, _ := cmd.Flags().GetString("ticker")
 	exit, _ := cmd.Flags().GetFloat64("exit")
 	outcome, _ := cmd.Flags().GetString("outcome")
+	shares, _ := cmd.Flags().GetInt("shares")
+	pct, _ := cmd.Flags().GetFloat64("pct")
+	methodName, _ := cmd.Flags().GetString("method")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 
+	partial := shares > 0 || pct > 0
+	if shares > 0 && pct > 0 {
+		return fmt.Errorf("use either --shares or --pct, not both")
+	}
+
 	// Validate outcome
-	if outcome != "WIN" && outcome != "LOSS" && outcome != "SCRATCH" {
+	if (outcome != "" || !partial) && outcome != "WIN" && outcome != "LOSS" && outcome != "SCRATCH" {
 		return fmt.Errorf("outcome must be WIN, LOSS, or SCRATCH, got: %s", outcome)
 	}
 
+	method, err := storage.ParseCostMethod(methodName)
+	if err != nil {
+		return err
+	}
+
 	log.WithFields(map[string]interface{}{
 		"ticker":  ticker,
 		"exit":    exit,
 		"outcome": outcome,
+		"shares":  shares,
+		"pct":     pct,
 	}).Info("Closing position")
 
 	db, err := storage.New(dbPath)
@@ -215,41 +248,72 @@ func runClosePosition(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get position: %w", err)
 	}
 
-	// Close position
-	err = db.ClosePosition(ticker, exit, outcome)
+	if pct > 0 {
+		if shares, err = storage.SharesForPct(position.Shares, pct); err != nil {
+			return err
+		}
+	}
+
+	var result *storage.LotCloseResult
+	if partial && shares < position.Shares {
+		result, err = db.PartialClose(ticker, shares, exit, method)
+	} else {
+		err = db.ClosePosition(ticker, exit, outcome)
+	}
 	if err != nil {
 		log.WithError(err).Error("Failed to close position")
 		return fmt.Errorf("failed to close position: %w", err)
 	}
 
-	// Calculate P&L for display
-	pnl := float64(position.Shares) * (exit - position.EntryPrice)
+	// Re-read for the P&L realized over every exit
+	closed, err := db.GetPosition(position.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to reload position")
+		return fmt.Errorf("failed to reload position: %w", err)
+	}
+	if result == nil {
+		outcome = closed.Outcome
+	}
 
 	log.WithFields(map[string]interface{}{
 		"ticker":  ticker,
-		"outcome": outcome,
-		"pnl":     pnl,
-	}).Info("Position closed")
+		"status":  closed.Status,
+		"outcome": closed.Outcome,
+		"pnl":     closed.PnL,
+	}).Info("Position exit recorded")
+
+	if result != nil {
+		if jsonOutput {
+			jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(jsonBytes))
+		} else {
+			fmt.Printf("✓ Partial exit: %s\n", ticker)
+			fmt.Printf("  Sold:      %d @ $%.2f (%s, cost $%.2f)\n", result.Shares, exit, result.Method, result.CostBasis)
+			fmt.Printf("  P&L:       $%.2f (trade so far: $%.2f)\n", result.RealizedPnL, result.TotalPnL)
+			fmt.Printf("  Remaining: %d shares @ $%.2f, risk $%.2f\n", closed.Shares, closed.EntryPrice, closed.RiskDollars)
+		}
+		return nil
+	}
 
 	if jsonOutput {
 		result := map[string]interface{}{
-			"ticker":      ticker,
-			"entry":       position.EntryPrice,
-			"exit":        exit,
-			"shares":      position.Shares,
-			"pnl":         pnl,
-			"outcome":     outcome,
-			"bucket":      position.Bucket,
-			"cooldown":    outcome == "LOSS" && position.Bucket != "",
+			"ticker":   ticker,
+			"entry":    closed.EntryPrice,
+			"exit":     exit,
+			"shares":   closed.Shares,
+			"pnl":      closed.PnL,
+			"outcome":  outcome,
+			"bucket":   position.Bucket,
+			"cooldown": outcome == "LOSS" && position.Bucket != "",
 		}
 		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(jsonBytes))
 	} else {
 		fmt.Printf("✓ Position closed: %s\n", ticker)
-		fmt.Printf("  Entry:   $%.2f\n", position.EntryPrice)
+		fmt.Printf("  Entry:   $%.2f\n", closed.EntryPrice)
 		fmt.Printf("  Exit:    $%.2f\n", exit)
-		fmt.Printf("  Shares:  %d\n", position.Shares)
-		fmt.Printf("  P&L:     $%.2f\n", pnl)
+		fmt.Printf("  Shares:  %d\n", closed.Shares)
+		fmt.Printf("  P&L:     $%.2f\n", closed.PnL)
 		fmt.Printf("  Outcome: %s\n", outcome)
 
 		if outcome == "LOSS" && position.Bucket != "" {
@@ -327,7 +391,7 @@ func runListPositions(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Positions: %d\n\n", len(positions))
 
 	for _, p := range positions {
-		fmt.Printf("• %s (%s)\n", p.Ticker, p.Status)
+		fmt.Printf("• %s %s (%s)\n", p.Ticker, p.Direction, p.Status)
 		fmt.Printf("  Entry: $%.2f | Stop: $%.2f | Shares: %d\n",
 			p.EntryPrice, p.CurrentStop, p.Shares)
 		fmt.Printf("  Risk: $%.2f", p.RiskDollars)
@@ -395,14 +459,24 @@ func runGetPosition(cmd *cobra.Command, args []string) error {
 
 	log.Info("Position retrieved")
 
+	ex, tracked := analytics.MeasureExcursion(*position)
+
 	if jsonOutput {
-		output, _ := json.MarshalIndent(position, "", "  ")
-		fmt.Println(string(output))
+		output := struct {
+			*storage.Position
+			Excursion *analytics.PositionExcursion `json:"excursion,omitempty"`
+		}{Position: position}
+		if tracked {
+			output.Excursion = &ex
+		}
+		data, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(data))
 		return nil
 	}
 
 	fmt.Printf("Position: %s\n\n", ticker)
 	fmt.Printf("Status:        %s\n", position.Status)
+	fmt.Printf("Direction:     %s\n", position.Direction)
 	fmt.Printf("Entry:         $%.2f\n", position.EntryPrice)
 	fmt.Printf("Current Stop:  $%.2f\n", position.CurrentStop)
 	fmt.Printf("Initial Stop:  $%.2f\n", position.InitialStop)
@@ -412,6 +486,15 @@ func runGetPosition(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Bucket:        %s\n", position.Bucket)
 	}
 	fmt.Printf("Opened:        %s\n", position.OpenedAt.Format("2006-01-02 15:04"))
+	if position.Commissions > 0 {
+		fmt.Printf("Commissions:   $%.2f\n", position.Commissions)
+	}
+
+	if tracked {
+		fmt.Printf("\nRange:         $%.2f – $%.2f (through %s)\n", ex.Low, ex.High, ex.Through)
+		fmt.Printf("MAE:           $%.2f/share  %.2fN  %.2fR\n", ex.MAE, ex.MAEN, ex.MAER)
+		fmt.Printf("MFE:           $%.2f/share  %.2fN  %.2fR\n", ex.MFE, ex.MFEN, ex.MFER)
+	}
 
 	if position.Status == "CLOSED" {
 		fmt.Printf("\nExit:          $%.2f\n", position.ExitPrice)
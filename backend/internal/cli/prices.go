@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewImportPricesCommand creates the import-prices command
+func NewImportPricesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import-prices",
+		Short: "Import daily OHLCV bars from CSV files",
+		Long: `Import daily price bars into the local price store.
+
+Each CSV file is named after its ticker (AAPL.csv) and has a header row
+with Date, Open, High, Low, Close and optionally Volume. Exports from most
+data vendors work as-is; extra columns are ignored. Re-importing a file
+overwrites bars for the same dates.
+
+Stored bars are used to compute the 20-day Wilder ATR (N) automatically
+when size or save-decision are run without --atr, and the daily highs and
+lows widen the MAE/MFE range of open positions in the imported tickers.
+
+Examples:
+  # Import every CSV in the default folder (./data/prices)
+  tf-engine import-prices
+
+  # Import from a different folder
+  tf-engine import-prices --dir ~/Downloads/bars
+
+  # Import a single file
+  tf-engine import-prices --file ./data/prices/AAPL.csv`,
+		RunE: runImportPrices,
+	}
+
+	cmd.Flags().String("dir", marketdata.DefaultPriceDir, "Folder of <TICKER>.csv files")
+	cmd.Flags().String("file", "", "Import a single CSV file instead of a folder")
+
+	return cmd
+}
+
+func runImportPrices(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	log := logx.WithCorrelationID(corrID)
+
+	dir, _ := cmd.Flags().GetString("dir")
+	file, _ := cmd.Flags().GetString("file")
+
+	// Open database
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var results []marketdata.ImportResult
+	if file != "" {
+		log.WithField("file", file).Info("Importing price file")
+		result, err := marketdata.ImportFile(db, file)
+		if err != nil {
+			log.WithError(err).Error("Failed to import price file")
+			return fmt.Errorf("failed to import prices: %w", err)
+		}
+		results = append(results, *result)
+	} else {
+		log.WithField("dir", dir).Info("Importing price folder")
+		results, err = marketdata.ImportDir(db, dir)
+		if err != nil {
+			log.WithError(err).Error("Failed to import price folder")
+			return fmt.Errorf("failed to import prices: %w", err)
+		}
+	}
+
+	for _, r := range results {
+		fmt.Printf("✓ %s: %d bars (%s → %s)\n", r.Ticker, r.Bars, r.FirstBar, r.LastBar)
+	}
+
+	// Widen the MAE/MFE range of open positions in the imported tickers
+	if err := updateImportedExcursions(db, results); err != nil {
+		log.WithError(err).Warn("Failed to update position excursions")
+	}
+
+	jsonResult, _ := json.MarshalIndent(results, "", "  ")
+	fmt.Println(string(jsonResult))
+
+	log.WithField("files", len(results)).Info("Price import completed")
+
+	return nil
+}
+
+// updateImportedExcursions updates the excursion range of the open
+// positions whose tickers were just imported
+func updateImportedExcursions(db *storage.DB, results []marketdata.ImportResult) error {
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		return err
+	}
+	imported := make([]string, len(results))
+	for i, r := range results {
+		imported[i] = r.Ticker
+	}
+	positions = filterPositions(positions, strings.Join(imported, ","))
+	if len(positions) == 0 {
+		return nil
+	}
+
+	updates, err := marketdata.UpdateExcursions(db, marketdata.NewDBBarProvider(db), positions)
+	if err != nil {
+		return err
+	}
+	for _, u := range updates {
+		if u.Skipped == "" {
+			fmt.Printf("  %s range %.2f – %.2f\n", u.Ticker, u.Low, u.High)
+		}
+	}
+	return nil
+}
+
+// NewATRCommand creates the atr command
+func NewATRCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "atr",
+		Short: "Show the stored ATR (N) for a ticker",
+		Long: `Compute the 20-day Wilder ATR (N) from stored daily bars.
+
+Examples:
+  tf-engine atr --ticker AAPL
+  tf-engine atr --ticker AAPL --period 55`,
+		RunE: runATR,
+	}
+
+	cmd.Flags().String("ticker", "", "Ticker symbol (required)")
+	cmd.Flags().Int("period", domain.DefaultATRPeriod, "ATR lookback period")
+
+	cmd.MarkFlagRequired("ticker")
+
+	return cmd
+}
+
+func runATR(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	log := logx.WithCorrelationID(corrID)
+
+	ticker, _ := cmd.Flags().GetString("ticker")
+	period, _ := cmd.Flags().GetInt("period")
+	ticker = strings.ToUpper(ticker)
+
+	// Open database
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	bars, err := marketdata.NewDBBarProvider(db).GetDailyBars(ticker)
+	if err != nil {
+		log.WithError(err).Error("Failed to load price bars")
+		return fmt.Errorf("failed to load price bars: %w", err)
+	}
+
+	result, err := domain.CalculateATR(bars, period)
+	if err != nil {
+		log.WithError(err).Error("ATR calculation failed")
+		return fmt.Errorf("ATR calculation failed: %w", err)
+	}
+
+	log.WithField("ticker", ticker).WithField("atr", result.ATR).Info("ATR computed")
+
+	jsonResult, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(jsonResult))
+
+	return nil
+}
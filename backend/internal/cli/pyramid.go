@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewAddUnitCommand creates the add-unit command
+func NewAddUnitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-unit",
+		Short: "Add a pyramid unit to an open position",
+		Long: `Add one unit to an open position per the Turtle pyramiding rules.
+
+The add-on is only recorded when:
+  - the position holds fewer than its max units (default 4)
+  - price has reached the next add level (planned add price, or
+    add_step_n × N beyond the last fill: above it for a long, below it
+    for a short)
+  - the extra risk still fits under the portfolio and bucket heat caps
+
+Every unit's stop then moves to K × N from the new fill (below it for a
+long, above it for a short; never loosened) and the fill is stored as its
+own lot.
+
+Examples:
+  # Add unit 2 to AAPL at $181.50 (same size as unit 1)
+  tf-engine add-unit --ticker AAPL --price 181.50
+
+  # Smaller add-on, JSON output
+  tf-engine add-unit --ticker AAPL --price 181.50 --shares 10 --json`,
+		RunE: runAddUnit,
+	}
+
+	cmd.Flags().String("ticker", "", "Ticker symbol (required)")
+	cmd.Flags().Float64("price", 0, "Fill price of the new unit (required)")
+	cmd.Flags().Int("shares", 0, "Shares in the new unit (default: size of unit 1)")
+	cmd.Flags().Float64("atr", 0, "N to use for levels and stops (default: N at entry)")
+	cmd.Flags().Bool("json", false, "Output in JSON format")
+
+	cmd.MarkFlagRequired("ticker")
+	cmd.MarkFlagRequired("price")
+
+	return cmd
+}
+
+func runAddUnit(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	log := logx.WithCorrelationID(corrID)
+
+	ticker, _ := cmd.Flags().GetString("ticker")
+	price, _ := cmd.Flags().GetFloat64("price")
+	shares, _ := cmd.Flags().GetInt("shares")
+	atr, _ := cmd.Flags().GetFloat64("atr")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	log.WithFields(map[string]interface{}{
+		"ticker": ticker,
+		"price":  price,
+	}).Info("Adding pyramid unit")
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	position, err := db.GetPositionByTicker(ticker)
+	if err != nil {
+		log.WithError(err).Error("Failed to load position")
+		return fmt.Errorf("failed to load position: %w", err)
+	}
+
+	req, err := addUnitRequest(db, position)
+	if err != nil {
+		log.WithError(err).Error("Failed to build add-on request")
+		return err
+	}
+	req.Price = price
+	req.Shares = shares
+	if atr > 0 {
+		req.N = atr
+	}
+
+	plan, err := domain.PlanAddUnit(req)
+	if err != nil {
+		log.WithError(err).Warn("Add-on rejected")
+		return fmt.Errorf("add-on rejected: %w", err)
+	}
+
+	settings, err := db.GetSettings()
+	if err != nil {
+		log.WithError(err).Error("Failed to get settings")
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	open, err := db.GetOpenPositions()
+	if err != nil {
+		log.WithError(err).Error("Failed to get open positions")
+		return fmt.Errorf("failed to get open positions: %w", err)
+	}
+
+	equity, err := marketdata.SizingEquity(db)
+	if err != nil {
+		return fmt.Errorf("failed to resolve equity: %w", err)
+	}
+
+	heat, err := domain.CheckAddUnitHeat(plan, position.Bucket, equity,
+		settings.PortfolioCap/100, settings.BucketCap/100, marketdata.HeatPositions(open))
+	if err != nil {
+		log.WithError(err).Warn("Add-on rejected by heat check")
+		return fmt.Errorf("add-on rejected: %w", err)
+	}
+
+	// An add-on is a new unit, so the Turtle unit limits apply to it
+	limits, err := marketdata.EvaluateUnitLimits(db, position.Ticker, position.Direction, position.Bucket)
+	if err != nil {
+		log.WithError(err).Error("Failed to check unit limits")
+		return fmt.Errorf("failed to check unit limits: %w", err)
+	}
+	if !limits.Allowed {
+		log.WithField("reason", limits.Reason).Warn("Add-on rejected by unit limits")
+		return fmt.Errorf("add-on rejected: %s", limits.Reason)
+	}
+
+	updated, err := db.AddUnit(position.ID, storage.UnitFill{
+		Price:   plan.FillPrice,
+		Shares:  plan.Shares,
+		NewStop: plan.NewStop,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to record add-on")
+		return fmt.Errorf("failed to record add-on: %w", err)
+	}
+
+	log.WithFields(map[string]interface{}{
+		"ticker":   ticker,
+		"unit":     plan.UnitNum,
+		"new_stop": plan.NewStop,
+		"risk":     updated.RiskDollars,
+	}).Info("Pyramid unit added")
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"plan":     plan,
+			"heat":     heat,
+			"position": updated,
+		}
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		fmt.Printf("✓ Unit %d added: %s\n", plan.UnitNum, ticker)
+		fmt.Printf("  Fill:       %d @ $%.2f (level $%.2f)\n", plan.Shares, plan.FillPrice, plan.AddLevel)
+		fmt.Printf("  Stop:       $%.2f → $%.2f (all units)\n", plan.PreviousStop, plan.NewStop)
+		fmt.Printf("  Position:   %d shares @ $%.2f avg, %d/%d units\n",
+			updated.Shares, updated.EntryPrice, updated.CurrentUnits, req.MaxUnits)
+		fmt.Printf("  Risk:       $%.2f (%+.2f)\n", updated.RiskDollars, plan.IncrementalRisk)
+		fmt.Printf("  Heat:       $%.2f / $%.2f\n", heat.NewPortfolioHeat, heat.PortfolioCap)
+		if plan.NextAddLevel > 0 {
+			fmt.Printf("  Next add:   $%.2f\n", plan.NextAddLevel)
+		}
+	}
+
+	return nil
+}
+
+// addUnitRequest builds the pyramid request for an open position from its
+// lots and the StopMultiple_K setting (price and shares are left to the caller)
+func addUnitRequest(db *storage.DB, position *storage.Position) (domain.AddUnitRequest, error) {
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		return domain.AddUnitRequest{}, fmt.Errorf("failed to load lots: %w", err)
+	}
+
+	kStr, err := db.GetSetting("StopMultiple_K")
+	if err != nil {
+		return domain.AddUnitRequest{}, fmt.Errorf("failed to get K multiple from database: %w", err)
+	}
+	var k float64
+	fmt.Sscanf(kStr, "%f", &k)
+
+	req := domain.AddUnitRequest{
+		Ticker:      position.Ticker,
+		Direction:   position.Direction,
+		N:           position.ATR,
+		K:           k,
+		AddStepN:    position.AddStepN,
+		MaxUnits:    position.MaxUnits,
+		AddPrices:   []float64{position.AddPrice1, position.AddPrice2, position.AddPrice3},
+		CurrentStop: position.CurrentStop,
+		CurrentRisk: position.RiskDollars,
+	}
+	if req.MaxUnits <= 0 {
+		req.MaxUnits = domain.DefaultMaxUnits
+	}
+	for _, lot := range lots {
+		if lot.RemainingShares == 0 {
+			continue // Scaled out
+		}
+		req.Units = append(req.Units, domain.PyramidUnit{
+			UnitNum:    lot.UnitNum,
+			Shares:     lot.RemainingShares,
+			EntryPrice: lot.EntryPrice,
+		})
+	}
+	return req, nil
+}
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// ReconcileRecord is a pre-filled close record for a position the bars say is out
+type ReconcileRecord struct {
+	domain.ReconcileExit
+	PositionID     int    `json:"position_id"`
+	InstrumentType string `json:"instrument_type,omitempty"`
+	Outcome        string `json:"outcome,omitempty"`       // From the projected P&L
+	CloseCommand   string `json:"close_command,omitempty"` // close-position call that records the exit
+	AutoClose      bool   `json:"auto_close"`              // Eligible for --close
+	Closed         bool   `json:"closed"`
+	CloseError     string `json:"close_error,omitempty"`
+}
+
+// ReconcileReport is the output of reconcile
+type ReconcileReport struct {
+	AsOfDate string            `json:"as_of_date"`
+	Checked  int               `json:"checked"`
+	Exits    []ReconcileRecord `json:"exits"`
+	Errors   map[string]string `json:"errors,omitempty"` // Ticker -> why it was skipped
+	Closed   int               `json:"closed"`
+}
+
+// NewReconcileCommand creates the reconcile command
+func NewReconcileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Find open positions whose stop, exit channel or expiration has passed",
+		Long: `Check every open position against its daily bars and list the ones
+that should already be closed:
+
+  STOP        - a bar traded through current_stop after it was set
+  CHANNEL     - a bar broke the position's exit-lookback Donchian exit
+  EXPIRATION  - primary_expiration_date is on or before --as-of
+
+Each exit comes with a pre-filled close record at the breached level and
+the outcome of the projected P&L. A bar that opened through the level is
+flagged as a gap (the fill was probably worse).
+
+With --close, STOP and CHANNEL exits on stock positions are recorded
+through close-position, so the outcome and any loss cooldown are applied
+as usual. Option positions and expirations are listed only: they must be
+closed at the option price.
+
+Bars are read from <TICKER>.csv files in --dir.
+
+Examples:
+  # List breached stops and exits
+  tf-engine reconcile --dir ./data/prices
+
+  # Close them
+  tf-engine reconcile --dir ./data/prices --close`,
+		RunE: runReconcile,
+	}
+
+	cmd.Flags().String("dir", "", "Folder of <TICKER>.csv daily bars (required)")
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: all open positions)")
+	cmd.Flags().String("as-of", "", "Date for expiration checks, YYYY-MM-DD (default: today)")
+	cmd.Flags().Bool("close", false, "Close stock positions whose stop or exit channel was hit")
+	cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	dir, _ := cmd.Flags().GetString("dir")
+	tickersFlag, _ := cmd.Flags().GetString("tickers")
+	asOf, _ := cmd.Flags().GetString("as-of")
+	closeExits, _ := cmd.Flags().GetBool("close")
+
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		return fmt.Errorf("invalid --as-of date %q (use YYYY-MM-DD)", asOf)
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		log.WithError(err).Error("Failed to get open positions")
+		return fmt.Errorf("failed to get open positions: %w", err)
+	}
+	positions = filterPositions(positions, tickersFlag)
+
+	log.WithField("positions", len(positions)).WithField("close", closeExits).Info("Reconciling positions")
+
+	provider := marketdata.NewCSVBarProvider(dir)
+	report := ReconcileReport{
+		AsOfDate: asOf,
+		Checked:  len(positions),
+		Exits:    []ReconcileRecord{},
+	}
+
+	for i := range positions {
+		position := &positions[i]
+
+		exit, err := reconcilePosition(db, provider, position, asOf)
+		if err != nil {
+			log.WithError(err).WithField("ticker", position.Ticker).Warn("Skipping position")
+			if report.Errors == nil {
+				report.Errors = map[string]string{}
+			}
+			report.Errors[position.Ticker] = err.Error()
+			continue
+		}
+		if exit == nil {
+			continue
+		}
+
+		record := ReconcileRecord{
+			ReconcileExit:  *exit,
+			PositionID:     position.ID,
+			InstrumentType: position.InstrumentType,
+			AutoClose:      exit.Reason != domain.ExitReasonExpiration && position.InstrumentType != "OPTION",
+		}
+		if exit.Reason != domain.ExitReasonExpiration {
+			record.Outcome = storage.OutcomeForPnL(exit.ProjectedPnL)
+			record.CloseCommand = fmt.Sprintf("tf-engine close-position --ticker %s --exit %.2f --outcome %s",
+				position.Ticker, exit.ExitPrice, record.Outcome)
+		}
+
+		if closeExits && record.AutoClose {
+			if err := db.ClosePosition(position.Ticker, exit.ExitPrice, record.Outcome); err != nil {
+				record.CloseError = err.Error()
+				log.WithError(err).WithField("ticker", position.Ticker).Error("Failed to close position")
+			} else {
+				record.Closed = true
+				report.Closed++
+				log.WithFields(map[string]interface{}{
+					"ticker":  position.Ticker,
+					"reason":  exit.Reason,
+					"exit":    exit.ExitPrice,
+					"outcome": record.Outcome,
+				}).Info("Position closed by reconciliation")
+			}
+		}
+
+		report.Exits = append(report.Exits, record)
+	}
+
+	for _, r := range report.Exits {
+		switch {
+		case r.CloseError != "":
+			PrintHumanf(format, "⚠️  %-6s %s: %s\n", r.Ticker, r.Reason, r.CloseError)
+		case r.Closed:
+			PrintHumanf(format, "✓ %-6s %-10s closed at $%.2f, %s $%.2f  [%s]\n",
+				r.Ticker, r.Reason, r.ExitPrice, r.Outcome, r.ProjectedPnL, r.Detail)
+		case r.Reason == domain.ExitReasonExpiration:
+			PrintHumanf(format, "✗ %-6s %-10s %s\n", r.Ticker, r.Reason, r.Detail)
+		default:
+			PrintHumanf(format, "✗ %-6s %-10s %s, projected %s $%.2f\n", r.Ticker, r.Reason, r.Detail, r.Outcome, r.ProjectedPnL)
+			PrintHumanf(format, "    %s\n", r.CloseCommand)
+		}
+	}
+	for ticker, msg := range report.Errors {
+		PrintHumanf(format, "⚠️  %-6s %s\n", ticker, msg)
+	}
+	PrintHumanf(format, "\n%d of %d open positions need closing, %d closed (as of %s)\n",
+		len(report.Exits), report.Checked, report.Closed, report.AsOfDate)
+
+	log.WithField("exits", len(report.Exits)).WithField("closed", report.Closed).Info("Reconciliation completed")
+
+	if err := PrintJSON(report); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
+
+// reconcilePosition checks one open position against its bars
+func reconcilePosition(db *storage.DB, provider domain.BarProvider, position *storage.Position, asOf string) (*domain.ReconcileExit, error) {
+	bars, err := provider.GetDailyBars(position.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := openUnits(db, position)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.ReconcilePosition(domain.ReconcileRequest{
+		Ticker:         position.Ticker,
+		Direction:      position.Direction,
+		EntryDate:      position.TradeDate(),
+		StopDate:       position.StopDate,
+		CurrentStop:    position.CurrentStop,
+		ExitLookback:   position.ExitLookback,
+		ExpirationDate: position.PrimaryExpirationDate,
+		AsOfDate:       asOf,
+		Units:          units,
+		RealizedPnL:    position.PnL,
+	}, bars)
+}
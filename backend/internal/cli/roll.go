@@ -0,0 +1,354 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// RollRecord is the time exit plan for one open option position
+type RollRecord struct {
+	domain.RollPlan
+	PositionID    int                `json:"position_id"`
+	Bucket        string             `json:"bucket,omitempty"`
+	Spot          float64            `json:"spot,omitempty"`
+	Heat          *domain.HeatResult `json:"heat,omitempty"` // Heat check of the new risk (ROLL only)
+	NewPositionID int                `json:"new_position_id,omitempty"`
+	Rolled        bool               `json:"rolled"`
+	RollError     string             `json:"roll_error,omitempty"`
+}
+
+// RollReport is the output of roll
+type RollReport struct {
+	AsOfDate  string         `json:"as_of_date"`
+	Checked   int            `json:"checked"`
+	Due       int            `json:"due"`
+	Positions []RollRecord   `json:"positions"`
+	Errors    map[int]string `json:"errors,omitempty"` // Position ID -> why it was skipped
+	Rolled    int            `json:"rolled"`
+}
+
+// NewRollCommand creates the roll command
+func NewRollCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "roll",
+		Short: "List option positions at their time exit and roll them",
+		Long: `Check every open option position against its time exit: a position is
+due when its nearest expiration is roll_threshold_dte days or fewer away
+(21 by default). What happens then depends on the position's
+time_exit_mode, copied from its trade session:
+
+  None   - nothing (HOLD)
+  Close  - close every leg (CLOSE, listed only: record it with close-position)
+  Roll   - close the expiring legs and reopen them at the next monthly
+           expiration, or --to-exp (ROLL)
+
+Legs are valued with Black-Scholes at the underlying price, using --iv or
+the volatility implied by each leg's entry fill. Pass --close-prices and
+--open-prices (one per closing/opening leg, with --position) to use the
+actual fills instead.
+
+With --execute, each ROLL is recorded as a linked close/open pair: the old
+position is marked ROLLED with the realized P&L of the closed legs, and the
+new one carries the stop, bucket and time exit settings with the roll's
+credit or debit added to its cost basis. The new legs' max loss must pass
+the portfolio and bucket heat caps (with the old position's risk released)
+or the roll is refused.
+
+The underlying price is --spot, or the last stored close (see import-prices).
+
+Examples:
+  # List positions at their time exit
+  tf-engine roll
+
+  # Roll one position at its actual fills
+  tf-engine roll --position 12 --spot 101.50 --close-prices 0.35,1.10 --open-prices 0.90,2.15 --execute
+
+  # Roll everything due, priced at 28% volatility
+  tf-engine roll --iv 0.28 --execute`,
+		RunE: runRoll,
+	}
+
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: all open option positions)")
+	cmd.Flags().Int("position", 0, "Only this position ID")
+	cmd.Flags().String("as-of", "", "Date for DTE and pricing, YYYY-MM-DD (default: today)")
+	cmd.Flags().String("to-exp", "", "Expiration to roll to, YYYY-MM-DD (default: next monthly)")
+	cmd.Flags().Float64("spot", 0, "Underlying price (default: last stored close)")
+	cmd.Flags().Float64("iv", 0, "Volatility as decimal for every leg (default: solved from entry fills)")
+	cmd.Flags().Float64("rate", options.DefaultRiskFreeRate, "Risk-free rate as decimal")
+	cmd.Flags().String("close-prices", "", "Fills of the closing legs, comma-separated (requires --position)")
+	cmd.Flags().String("open-prices", "", "Fills of the new legs, comma-separated (requires --position)")
+	cmd.Flags().Bool("execute", false, "Record the rolls that pass the heat check")
+
+	return cmd
+}
+
+func runRoll(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	tickersFlag, _ := cmd.Flags().GetString("tickers")
+	positionID, _ := cmd.Flags().GetInt("position")
+	asOf, _ := cmd.Flags().GetString("as-of")
+	toExp, _ := cmd.Flags().GetString("to-exp")
+	spot, _ := cmd.Flags().GetFloat64("spot")
+	iv, _ := cmd.Flags().GetFloat64("iv")
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	closePricesFlag, _ := cmd.Flags().GetString("close-prices")
+	openPricesFlag, _ := cmd.Flags().GetString("open-prices")
+	execute, _ := cmd.Flags().GetBool("execute")
+
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		return fmt.Errorf("invalid --as-of date %q (use YYYY-MM-DD)", asOf)
+	}
+	closePrices, err := parseFloatList(closePricesFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --close-prices: %w", err)
+	}
+	openPrices, err := parseFloatList(openPricesFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --open-prices: %w", err)
+	}
+	if (len(closePrices) > 0 || len(openPrices) > 0) && positionID == 0 {
+		return fmt.Errorf("--close-prices and --open-prices need --position")
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		log.WithError(err).Error("Failed to get open positions")
+		return fmt.Errorf("failed to get open positions: %w", err)
+	}
+	positions = filterPositions(positions, tickersFlag)
+
+	var optionPositions []storage.Position
+	for _, p := range positions {
+		if p.InstrumentType == storage.InstrumentOption && (positionID == 0 || p.ID == positionID) {
+			optionPositions = append(optionPositions, p)
+		}
+	}
+	if positionID != 0 && len(optionPositions) == 0 {
+		return fmt.Errorf("no open option position with ID %d", positionID)
+	}
+
+	log.WithField("positions", len(optionPositions)).WithField("execute", execute).Info("Checking time exits")
+
+	provider := marketdata.NewDBBarProvider(db)
+	report := RollReport{
+		AsOfDate:  asOf,
+		Checked:   len(optionPositions),
+		Positions: []RollRecord{},
+	}
+	skip := func(position *storage.Position, err error) {
+		log.WithError(err).WithField("ticker", position.Ticker).WithField("position_id", position.ID).Warn("Skipping position")
+		if report.Errors == nil {
+			report.Errors = map[int]string{}
+		}
+		report.Errors[position.ID] = err.Error()
+	}
+
+	for i := range optionPositions {
+		position := &optionPositions[i]
+
+		var legs []options.Leg
+		if err := json.Unmarshal([]byte(position.LegsJSON), &legs); err != nil {
+			skip(position, fmt.Errorf("invalid legs: %w", err))
+			continue
+		}
+
+		underlying := spot
+		if underlying <= 0 {
+			// Without a price only the DTE check is possible; pricing a due
+			// position then fails with a hint to pass --spot
+			if bars, err := provider.GetDailyBars(position.Ticker); err == nil && len(bars) > 0 {
+				underlying = bars[len(bars)-1].Close
+			}
+		}
+
+		plan, err := domain.PlanRoll(domain.RollRequest{
+			Ticker:       position.Ticker,
+			Legs:         legs,
+			ThresholdDTE: position.RollThresholdDTE,
+			Mode:         position.TimeExitMode,
+			CostBasis:    position.CostBasis,
+			RiskDollars:  position.RiskDollars,
+			EntryDate:    position.TradeDate(),
+			EntrySpot:    position.UnderlyingAtEntry,
+			Market:       options.Market{Spot: underlying, Rate: rate, Vol: iv, AsOf: asOf},
+			ToExpiration: toExp,
+			ClosePrices:  closePrices,
+			OpenPrices:   openPrices,
+		})
+		if err != nil {
+			skip(position, err)
+			continue
+		}
+
+		record := RollRecord{
+			RollPlan:   *plan,
+			PositionID: position.ID,
+			Bucket:     position.Bucket,
+			Spot:       underlying,
+		}
+		if plan.Due {
+			report.Due++
+		}
+
+		if plan.Action == domain.RollActionRoll {
+			record.Heat, err = rollHeatCheck(db, position, plan.NewRisk)
+			if err != nil {
+				record.RollError = err.Error()
+			} else if execute && record.Heat.Allowed {
+				next, err := db.RollPosition(position.ID, storage.OptionRoll{
+					RollDate:        asOf,
+					UnderlyingPrice: underlying,
+					Legs:            toStorageLegs(plan.NewLegs),
+					RealizedPnL:     plan.RealizedPnL,
+					RollDebit:       plan.RollDebit,
+					RiskDollars:     plan.NewRisk,
+					Payoff:          plan.Payoff,
+				})
+				if err != nil {
+					record.RollError = err.Error()
+					log.WithError(err).WithField("ticker", position.Ticker).Error("Failed to roll position")
+				} else {
+					record.Rolled = true
+					record.NewPositionID = next.ID
+					report.Rolled++
+					log.WithFields(map[string]interface{}{
+						"ticker":       position.Ticker,
+						"from":         position.ID,
+						"to":           next.ID,
+						"roll_debit":   plan.RollDebit,
+						"cost_basis":   next.CostBasis,
+						"realized_pnl": plan.RealizedPnL,
+					}).Info("Position rolled")
+				}
+			}
+		}
+
+		report.Positions = append(report.Positions, record)
+	}
+
+	for _, r := range report.Positions {
+		switch {
+		case r.RollError != "":
+			PrintHumanf(format, "⚠️  %-6s #%d %s: %s\n", r.Ticker, r.PositionID, r.Action, r.RollError)
+		case r.Rolled:
+			PrintHumanf(format, "✓ %-6s #%d rolled to #%d  [%s]\n", r.Ticker, r.PositionID, r.NewPositionID, r.Detail)
+			PrintHumanf(format, "    cost basis $%.2f, risk $%.2f\n", r.CostBasis, r.NewRisk)
+		case r.Action == domain.RollActionRoll:
+			PrintHumanf(format, "✗ %-6s #%d %-5s %s\n", r.Ticker, r.PositionID, r.Action, r.Detail)
+			PrintHumanf(format, "    cost basis $%.2f, new risk $%.2f, heat $%.2f → $%.2f",
+				r.CostBasis, r.NewRisk, r.Heat.CurrentPortfolioHeat, r.Heat.NewPortfolioHeat)
+			if r.Heat.Allowed {
+				PrintHumanf(format, " ✓\n")
+			} else {
+				PrintHumanf(format, " ❌ %s\n", r.Heat.RejectionReason)
+			}
+		case r.Action == domain.RollActionClose:
+			PrintHumanf(format, "✗ %-6s #%d %-5s %s\n", r.Ticker, r.PositionID, r.Action, r.Detail)
+		default:
+			PrintHumanf(format, "  %-6s #%d %-5s %s\n", r.Ticker, r.PositionID, r.Action, r.Detail)
+		}
+	}
+	tickers := make(map[int]string, len(optionPositions))
+	for _, p := range optionPositions {
+		tickers[p.ID] = p.Ticker
+	}
+	skipped := make([]int, 0, len(report.Errors))
+	for id := range report.Errors {
+		skipped = append(skipped, id)
+	}
+	sort.Ints(skipped)
+	for _, id := range skipped {
+		PrintHumanf(format, "⚠️  %-6s #%d %s\n", tickers[id], id, report.Errors[id])
+	}
+	PrintHumanf(format, "\n%d of %d option positions at their time exit, %d rolled (as of %s)\n",
+		report.Due, report.Checked, report.Rolled, report.AsOfDate)
+
+	log.WithField("due", report.Due).WithField("rolled", report.Rolled).Info("Time exit check completed")
+
+	if err := PrintJSON(report); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
+
+// rollHeatCheck checks the replacement's risk against the heat caps, with
+// the rolled position's own risk released
+func rollHeatCheck(db *storage.DB, rolled *storage.Position, newRisk float64) (*domain.HeatResult, error) {
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	equity, err := marketdata.SizingEquity(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve equity: %w", err)
+	}
+	heatCapPct, err := strconv.ParseFloat(settings["HeatCap_H_pct"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HeatCap_H_pct setting: %w", err)
+	}
+	bucketHeatCapPct, err := strconv.ParseFloat(settings["BucketHeatCap_pct"], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BucketHeatCap_pct setting: %w", err)
+	}
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open positions: %w", err)
+	}
+	var openPositions []domain.Position
+	for _, p := range positions {
+		if p.ID == rolled.ID {
+			continue
+		}
+		openPositions = append(openPositions, domain.Position{
+			Ticker:      p.Ticker,
+			Bucket:      p.Bucket,
+			RiskDollars: p.RiskDollars,
+			UnitsOpen:   p.Shares,
+			Status:      "Open",
+		})
+	}
+
+	return domain.CalculateHeat(domain.HeatRequest{
+		Equity:           equity,
+		HeatCapPct:       heatCapPct,
+		BucketHeatCapPct: bucketHeatCapPct,
+		AddRiskDollars:   newRisk,
+		AddBucket:        rolled.Bucket,
+		OpenPositions:    openPositions,
+	})
+}
+
+// toStorageLegs converts pricing legs back to the stored leg format
+func toStorageLegs(legs []options.Leg) []storage.OptionLeg {
+	stored := make([]storage.OptionLeg, len(legs))
+	for i, leg := range legs {
+		stored[i] = storage.OptionLeg(leg)
+	}
+	return stored
+}
@@ -3,20 +3,25 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/yourusername/trading-engine/internal/domain"
 	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
 // DBGateChecker implements domain.GateChecker using database queries
 type DBGateChecker struct {
-	db     *storage.DB
-	log    *logrus.Entry
-	equity float64
+	db        *storage.DB
+	log       *logrus.Entry
+	equity    float64
+	system    string          // Breakout system of the trade (SYSTEM_1 runs the skip filter)
+	direction string          // Trade direction, for the per-direction unit limits
+	exposure  domain.Exposure // New trade's Greeks exposure, for the HeatGreeks caps
 }
 
 // CheckBannerGreen verifies the banner is GREEN for the ticker
@@ -61,38 +66,12 @@ func (c *DBGateChecker) CheckBucketCooldown(bucket string) error {
 	return c.db.CheckBucketCooldown(bucket)
 }
 
-// CheckHeatCaps verifies portfolio and bucket heat caps
-func (c *DBGateChecker) CheckHeatCaps(addRisk float64, bucket string) error {
-	c.log.WithField("gate", "heat_caps").WithField("add_risk", addRisk).WithField("bucket", bucket).Info("Checking heat caps gate")
+// CheckHeatCaps verifies portfolio, bucket and correlated-cluster heat caps
+// (and the Greeks caps with HeatGreeks set), counting the open positions
+func (c *DBGateChecker) CheckHeatCaps(ticker string, addRisk float64, bucket string) error {
+	c.log.WithField("gate", "heat_caps").WithField("ticker", ticker).WithField("add_risk", addRisk).WithField("bucket", bucket).Info("Checking heat caps gate")
 
-	// Get settings for heat caps
-	heatCapPctStr, err := c.db.GetSetting("HeatCap_H_pct")
-	if err != nil {
-		return fmt.Errorf("failed to get heat cap setting: %w", err)
-	}
-
-	bucketHeatCapPctStr, err := c.db.GetSetting("BucketHeatCap_pct")
-	if err != nil {
-		return fmt.Errorf("failed to get bucket heat cap setting: %w", err)
-	}
-
-	// Parse settings
-	var heatCapPct, bucketHeatCapPct float64
-	fmt.Sscanf(heatCapPctStr, "%f", &heatCapPct)
-	fmt.Sscanf(bucketHeatCapPctStr, "%f", &bucketHeatCapPct)
-
-	// Calculate heat request
-	req := domain.HeatRequest{
-		Equity:            c.equity,
-		HeatCapPct:        heatCapPct,
-		BucketHeatCapPct:  bucketHeatCapPct,
-		AddRiskDollars:    addRisk,
-		AddBucket:         bucket,
-		OpenPositions:     []domain.Position{}, // Empty for now - would query from DB
-	}
-
-	// Calculate heat
-	result, err := domain.CalculateHeat(req)
+	result, err := marketdata.EvaluateHeat(c.db, c.equity, ticker, bucket, addRisk, c.exposure)
 	if err != nil {
 		return fmt.Errorf("failed to calculate heat: %w", err)
 	}
@@ -111,6 +90,62 @@ func (c *DBGateChecker) CheckHeatCaps(addRisk float64, bucket string) error {
 			result.NewBucketHeat, result.BucketCap, overage)
 	}
 
+	// Check correlated-cluster and Greeks caps
+	if cluster := result.Cluster; cluster != nil && cluster.Exceeded {
+		return fmt.Errorf("correlated cluster [%s] heat ($%.2f) exceeds cap ($%.2f) by $%.2f",
+			strings.Join(cluster.Tickers, ", "), cluster.NewHeat, cluster.Cap, cluster.Overage)
+	}
+	if result.Greeks != nil {
+		for _, check := range result.Greeks.Checks {
+			if check.Exceeded {
+				return fmt.Errorf("%s net %s ($%.2f) exceeds cap ($%.2f)",
+					strings.ToLower(check.Scope), strings.ToLower(check.Measure), check.New, check.Cap)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckLastBreakoutFilter applies the System 1 "last breakout was a winner" skip rule
+func (c *DBGateChecker) CheckLastBreakoutFilter(ticker, date string) error {
+	if c.system != storage.SystemOne {
+		return nil
+	}
+	c.log.WithField("gate", "last_breakout_filter").WithField("ticker", ticker).Info("Checking System 1 skip filter")
+
+	k := domain.DefaultFilterStopMultiple
+	if kStr, err := c.db.GetSetting("StopMultiple_K"); err == nil {
+		fmt.Sscanf(kStr, "%f", &k)
+	}
+
+	result, err := marketdata.EvaluateLastBreakout(c.db, ticker, date, k)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate the System 1 skip filter: %w", err)
+	}
+
+	c.log.WithField("skip", result.Skip).WithField("reason", result.Reason).Info("System 1 skip filter evaluated")
+
+	if result.Skip {
+		return fmt.Errorf("%s", result.Reason)
+	}
+	return nil
+}
+
+// CheckUnitLimits applies the Turtle unit limits to one new unit
+func (c *DBGateChecker) CheckUnitLimits(ticker, bucket string) error {
+	c.log.WithField("gate", "unit_limits").WithField("ticker", ticker).WithField("bucket", bucket).Info("Checking unit limits gate")
+
+	result, err := marketdata.EvaluateUnitLimits(c.db, ticker, c.direction, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check unit limits: %w", err)
+	}
+
+	c.log.WithField("allowed", result.Allowed).WithField("checks", len(result.Checks)).Info("Unit limits evaluated")
+
+	if !result.Allowed {
+		return fmt.Errorf("%s", result.Reason)
+	}
 	return nil
 }
 
@@ -118,15 +153,21 @@ func (c *DBGateChecker) CheckHeatCaps(addRisk float64, bucket string) error {
 func NewSaveDecisionCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "save-decision",
-		Short: "Save a trading decision with 5 hard gates enforcement",
+		Short: "Save a trading decision with 7 hard gates enforcement",
 		Long: `Save a trading decision (GO or NO-GO) with full discipline enforcement.
 
-For GO decisions, all 5 hard gates must pass:
+For GO decisions, all 7 hard gates must pass:
   1. Banner GREEN (all 6 checklist items satisfied)
   2. Ticker in today's candidates (from FINVIZ screen)
   3. 2-minute impulse brake expired
   4. Bucket not in cooldown (24hr after loss)
-  5. Heat caps not exceeded (4% portfolio, 1.5% bucket)
+  5. Heat caps not exceeded (4% portfolio, 1.5% bucket, ClusterHeatCap_pct
+     across the correlated cluster, counting the open positions; with
+     HeatGreeks set to 1 the delta, gamma and vega caps too)
+  6. System 1 last-breakout skip filter (System 1 only)
+  7. Turtle unit limits (UnitLimit_* settings): 4 units per ticker, 6 per
+     correlated cluster, 10 per bucket and 12 per direction, counting the
+     open positions' current units
 
 For NO-GO decisions, gates are not checked (just recording the decision).
 
@@ -134,12 +175,21 @@ Examples:
   # Save GO decision (stock)
   tf-engine save-decision --ticker AAPL --entry 180 --atr 1.5 --action GO
 
+  # Save GO decision for a short (stop goes above entry)
+  tf-engine save-decision --ticker AAPL --entry 180 --atr 1.5 --action GO --direction SHORT
+
+  # Save GO decision with N computed from stored price bars
+  tf-engine save-decision --ticker AAPL --entry 180 --action GO
+
   # Save GO decision (options delta-ATR)
   tf-engine save-decision --ticker AAPL --entry 5.50 --atr 1.50 --delta 0.30 --method opt-delta-atr --action GO
 
   # Save GO decision (options max-loss)
   tf-engine save-decision --ticker AAPL --max-loss 0.75 --method opt-maxloss --action GO
 
+  # Save System 1 GO decision (skipped if the last 20-bar breakout was a winner)
+  tf-engine save-decision --ticker AAPL --entry 180 --action GO --system SYSTEM_1
+
   # Save NO-GO decision
   tf-engine save-decision --ticker AAPL --action NO-GO --reason "Bad setup"`,
 		RunE: runSaveDecision,
@@ -147,14 +197,16 @@ Examples:
 
 	cmd.Flags().String("ticker", "", "Ticker symbol (required)")
 	cmd.Flags().String("action", "", "GO or NO-GO (required)")
+	cmd.Flags().String("direction", domain.DirectionLong, "Trade direction: LONG or SHORT")
 	cmd.Flags().Float64("entry", 0, "Entry price (required for GO)")
-	cmd.Flags().Float64("atr", 0, "ATR value (required for stock and opt-delta-atr)")
+	cmd.Flags().Float64("atr", 0, "ATR value (default: computed from stored bars for stock and opt-delta-atr)")
 	cmd.Flags().String("method", "stock", "Method: stock, opt-delta-atr, opt-maxloss")
 	cmd.Flags().Float64("delta", 0, "Option delta (for opt-delta-atr)")
 	cmd.Flags().Float64("max-loss", 0, "Maximum loss per contract (for opt-maxloss)")
 	cmd.Flags().String("bucket", "", "Sector bucket")
 	cmd.Flags().String("reason", "", "Reason (required for NO-GO)")
 	cmd.Flags().String("date", "", "Date in YYYY-MM-DD format (defaults to today)")
+	cmd.Flags().String("system", "", "Breakout system: SYSTEM_1 applies the last-breakout skip filter")
 
 	cmd.MarkFlagRequired("ticker")
 	cmd.MarkFlagRequired("action")
@@ -170,6 +222,7 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 	// Get flags
 	ticker, _ := cmd.Flags().GetString("ticker")
 	action, _ := cmd.Flags().GetString("action")
+	direction, _ := cmd.Flags().GetString("direction")
 	entry, _ := cmd.Flags().GetFloat64("entry")
 	atr, _ := cmd.Flags().GetFloat64("atr")
 	method, _ := cmd.Flags().GetString("method")
@@ -178,6 +231,7 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 	bucket, _ := cmd.Flags().GetString("bucket")
 	reason, _ := cmd.Flags().GetString("reason")
 	dateStr, _ := cmd.Flags().GetString("date")
+	system, _ := cmd.Flags().GetString("system")
 
 	if dateStr == "" {
 		dateStr = time.Now().Format("2006-01-02")
@@ -187,23 +241,18 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 
 	// Build request
 	req := domain.SaveDecisionRequest{
-		Ticker:  ticker,
-		Action:  action,
-		Entry:   entry,
-		ATR:     atr,
-		Method:  method,
-		Delta:   delta,
-		MaxLoss: maxLoss,
-		Bucket:  bucket,
-		Reason:  reason,
-		Date:    dateStr,
-		CorrID:  corrID,
-	}
-
-	// Validate request
-	if err := domain.ValidateSaveDecisionRequest(req); err != nil {
-		log.WithError(err).Error("Invalid save decision request")
-		return fmt.Errorf("validation failed: %w", err)
+		Ticker:    ticker,
+		Action:    action,
+		Direction: direction,
+		Entry:     entry,
+		ATR:       atr,
+		Method:    method,
+		Delta:     delta,
+		MaxLoss:   maxLoss,
+		Bucket:    bucket,
+		Reason:    reason,
+		Date:      dateStr,
+		CorrID:    corrID,
 	}
 
 	// Open database
@@ -214,6 +263,25 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 	}
 	defer db.Close()
 
+	// Fill N from stored price bars when a GO decision omits --atr
+	var atrDate string
+	if action == "GO" {
+		atrReq := domain.SizingRequest{Ticker: ticker, ATR: atr, Method: method}
+		if _, err := domain.ResolveSizingATR(marketdata.NewDBBarProvider(db), &atrReq); err != nil {
+			log.WithError(err).Error("Failed to compute ATR from stored bars")
+			return fmt.Errorf("failed to compute ATR (pass --atr or import prices): %w", err)
+		}
+		atr, atrDate = atrReq.ATR, atrReq.ATRDate
+		req.ATR = atr
+	}
+
+	// Validate request
+	if err := domain.ValidateSaveDecisionRequest(req); err != nil {
+		log.WithError(err).Error("Invalid save decision request")
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	direction, _ = domain.NormalizeDirection(direction)
+
 	// Check for duplicate decision
 	hasDuplicate, err := db.CheckForDuplicateDecision(ticker, dateStr)
 	if err != nil {
@@ -224,19 +292,18 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("you already have a decision for %s today (date: %s)", ticker, dateStr)
 	}
 
-	// Get equity for calculations
-	equityStr, err := db.GetSetting("Equity_E")
+	// Get equity for calculations (drawdown-adjusted when tracked)
+	equity, err := marketdata.SizingEquity(db)
 	if err != nil {
 		log.WithError(err).Error("Failed to get equity")
 		return fmt.Errorf("failed to get equity: %w", err)
 	}
-	var equity float64
-	fmt.Sscanf(equityStr, "%f", &equity)
 
 	var decision storage.Decision
 	decision.Date = dateStr
 	decision.Ticker = ticker
 	decision.Action = action
+	decision.Direction = direction
 	decision.Method = method
 	decision.Bucket = bucket
 	decision.Reason = reason
@@ -258,14 +325,15 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 		fmt.Sscanf(kStr, "%f", &k)
 
 		sizingReq := domain.SizingRequest{
-			Equity:  equity,
-			RiskPct: riskPct,
-			Entry:   entry,
-			ATR:     atr,
-			K:       int(k),
-			Method:  method,
-			Delta:   delta,
-			MaxLoss: maxLoss,
+			Direction: direction,
+			Equity:    equity,
+			RiskPct:   riskPct,
+			Entry:     entry,
+			ATR:       atr,
+			K:         k,
+			Method:    method,
+			Delta:     delta,
+			MaxLoss:   maxLoss,
 		}
 
 		var result *domain.SizingResult
@@ -291,8 +359,12 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 		stopDistance = result.StopDistance
 		initialStop = result.InitialStop
 
-		// Check all 5 hard gates
-		checker := &DBGateChecker{db: db, log: log, equity: equity}
+		// Check all 7 hard gates
+		checker := &DBGateChecker{db: db, log: log, equity: equity, system: strings.ToUpper(system), direction: direction}
+		if method == "stock" {
+			// An option trade's Greeks are only known once its legs are, in its options session
+			checker.exposure = domain.StockExposure(direction, shares, entry)
+		}
 		gatesResult, err := domain.ValidateHardGates(checker, ticker, bucket, riskDollars, dateStr)
 		if err != nil {
 			log.WithError(err).Error("Failed to validate gates")
@@ -311,6 +383,7 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 		// All gates passed - populate decision
 		decision.Entry = entry
 		decision.ATR = atr
+		decision.ATRDate = atrDate
 		decision.StopDistance = stopDistance
 		decision.InitialStop = initialStop
 		decision.Shares = shares
@@ -320,7 +393,7 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 		decision.Delta = delta
 		decision.MaxLoss = maxLoss
 
-		log.Info("All 5 hard gates passed")
+		log.Info("All 7 hard gates passed")
 	} else {
 		// NO-GO decision - no gates checked
 		decision.Banner = "NO-GO"
@@ -340,8 +413,8 @@ func runSaveDecision(cmd *cobra.Command, args []string) error {
 	// Output result
 	if action == "GO" {
 		if method == "stock" {
-			fmt.Printf("✓ Decision saved: %s GO %d shares @ $%.2f (stop: $%.2f, risk: $%.2f)\n",
-				ticker, decision.Shares, entry, decision.InitialStop, decision.RiskDollars)
+			fmt.Printf("✓ Decision saved: %s GO %s %d shares @ $%.2f (stop: $%.2f, risk: $%.2f)\n",
+				ticker, direction, decision.Shares, entry, decision.InitialStop, decision.RiskDollars)
 		} else {
 			fmt.Printf("✓ Decision saved: %s GO %d contracts (risk: $%.2f)\n",
 				ticker, decision.Contracts, decision.RiskDollars)
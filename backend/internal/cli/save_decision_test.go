@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestCheckLastBreakoutFilterRejectsOnStoreError(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	db.Close()
+
+	checker := &DBGateChecker{db: db, log: logx.WithCorrelationID("test"), system: storage.SystemOne}
+	err = checker.CheckLastBreakoutFilter("AAPL", "2025-03-03")
+	if err == nil || !strings.Contains(err.Error(), "System 1 skip filter") {
+		t.Errorf("Expected the gate to reject when bars cannot be read, got %v", err)
+	}
+
+	// System 2 trades never run the filter
+	checker.system = storage.SystemTwo
+	if err := checker.CheckLastBreakoutFilter("AAPL", "2025-03-03"); err != nil {
+		t.Errorf("Expected System 2 to skip the filter, got %v", err)
+	}
+}
+
+func TestCheckHeatCapsCountsOpenPositions(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// $350 of open risk: a $75 trade passes an empty book but not this one
+	decision := storage.Decision{
+		Date: "2025-03-03", Ticker: "AAPL", Action: "GO", Entry: 100, InitialStop: 90,
+		Shares: 35, RiskDollars: 350, Banner: "GREEN", Method: "stock",
+	}
+	if decision.ID, err = db.SaveDecision(decision); err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	if _, err := db.OpenPositionFromFill(&decision, 35, 100, 0, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+
+	checker := &DBGateChecker{db: db, log: logx.WithCorrelationID("test"), equity: 10000}
+	err = checker.CheckHeatCaps("MSFT", 75, "")
+	if err == nil || !strings.Contains(err.Error(), "portfolio heat ($425.00) exceeds cap ($400.00)") {
+		t.Errorf("Expected the open position to push portfolio heat over the cap, got %v", err)
+	}
+	if err := checker.CheckHeatCaps("MSFT", 25, ""); err != nil {
+		t.Errorf("Expected $375 of heat to pass, got %v", err)
+	}
+}
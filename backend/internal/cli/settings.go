@@ -91,6 +91,28 @@ Valid settings:
   - HeatCap_H_pct: Portfolio heat cap as decimal (must be between 0 and 1)
   - BucketHeatCap_pct: Bucket heat cap as decimal (must be between 0 and 1)
   - StopMultiple_K: ATR stop multiple (must be positive)
+  - DeltaHeatCap_pct, BucketDeltaHeatCap_pct: Net delta dollars cap as
+    decimal of equity for heat --greeks (default 1.0 / 0.5, 0 = no cap)
+  - GammaHeatCap_pct, BucketGammaHeatCap_pct: Net gamma dollars cap
+    (default 0.10 / 0.05)
+  - VegaHeatCap_pct, BucketVegaHeatCap_pct: Net vega dollars cap
+    (default 0.01 / 0.005)
+  - HeatGreeks: 1 also checks the delta, gamma and vega caps in the heat
+    gate of every GO decision (default 0)
+  - ClusterHeatCap_pct: Risk cap across a correlated cluster, checked by
+    the GO decision heat gate and heat --correlation, as decimal of equity
+    (default 0.02, 0 = no cap)
+  - CorrelationThreshold: Return correlation at which tickers cluster
+    (default 0.7)
+  - UnitLimit_Market, UnitLimit_Closely, UnitLimit_Loosely,
+    UnitLimit_Direction: Turtle unit limits per ticker, correlated
+    cluster, bucket and direction (default 4 / 6 / 10 / 12, 0 = no limit)
+  - StartingEquity: Starting balance for equity tracking; equity is then
+    this plus realized P&L (default 0 = use Equity_E as entered)
+  - DrawdownStep_pct, DrawdownCut_pct: Cut sizing equity by the cut for
+    every full step of drawdown from the peak (default 0.10 / 0.20)
+  - EquityMarkToMarket: 1 adds open stock positions' unrealized P&L at
+    the last stored close (default 0)
 
 Examples:
   # Update account equity
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewSignalsCommand creates the signals command
+func NewSignalsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signals",
+		Short: "Scan daily bars for Donchian breakouts (System 1 / System 2)",
+		Long: `Scan tickers for Turtle Donchian channel breakouts.
+
+A LONG signal fires when the latest close is above the highest high of
+the prior entry-lookback bars; SHORT when it closes below the lowest low.
+Each signal includes the matching exit level (the exit-lookback low for
+longs, high for shorts).
+
+Systems:
+  SYSTEM_1 - 20-bar entry, 10-bar exit
+  SYSTEM_2 - 55-bar entry, 10-bar exit (default)
+
+Bars come from the price store (see import-prices) unless --dir points
+at a folder of <TICKER>.csv files.
+
+Examples:
+  # Scan every ticker in the price store with System 2
+  tf-engine signals
+
+  # System 1 on a few tickers, only show breakouts
+  tf-engine signals --system SYSTEM_1 --tickers AAPL,MSFT,NVDA --triggered-only
+
+  # Read bars straight from CSV files
+  tf-engine signals --dir ./data/prices`,
+		RunE: runSignals,
+	}
+
+	cmd.Flags().String("system", domain.BreakoutSystemTwo, "Breakout system: SYSTEM_1 or SYSTEM_2")
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: all tickers with bars)")
+	cmd.Flags().String("dir", "", "Read bars from <TICKER>.csv files in this folder instead of the price store")
+	cmd.Flags().Bool("triggered-only", false, "Only output tickers that broke out")
+
+	return cmd
+}
+
+func runSignals(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	system, _ := cmd.Flags().GetString("system")
+	tickersFlag, _ := cmd.Flags().GetString("tickers")
+	dir, _ := cmd.Flags().GetString("dir")
+	triggeredOnly, _ := cmd.Flags().GetBool("triggered-only")
+
+	params, err := domain.GetBreakoutParams(system)
+	if err != nil {
+		return err
+	}
+
+	var tickers []string
+	if tickersFlag != "" {
+		tickers = strings.Split(tickersFlag, ",")
+	}
+
+	var provider domain.BarProvider
+	if dir != "" {
+		csvProvider := marketdata.NewCSVBarProvider(dir)
+		if len(tickers) == 0 {
+			tickers, err = csvProvider.Tickers()
+			if err != nil {
+				log.WithError(err).Error("Failed to list price files")
+				return fmt.Errorf("failed to list price files: %w", err)
+			}
+		}
+		provider = csvProvider
+	} else {
+		db, err := storage.New(dbPath)
+		if err != nil {
+			log.WithError(err).Error("Failed to open database")
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		if len(tickers) == 0 {
+			tickers, err = db.GetPriceTickers()
+			if err != nil {
+				log.WithError(err).Error("Failed to list price tickers")
+				return fmt.Errorf("failed to list price tickers: %w", err)
+			}
+		}
+		provider = marketdata.NewDBBarProvider(db)
+	}
+
+	log.WithField("system", params.System).WithField("tickers", len(tickers)).Info("Scanning for breakouts")
+
+	signals := domain.ScanBreakouts(provider, tickers, params)
+
+	output := make([]domain.BreakoutSignal, 0, len(signals))
+	triggered := 0
+	for _, s := range signals {
+		if s.Triggered {
+			triggered++
+		}
+		if triggeredOnly && !s.Triggered {
+			continue
+		}
+		output = append(output, s)
+	}
+
+	for _, s := range output {
+		switch {
+		case s.Error != "":
+			PrintHumanf(format, "⚠️  %-6s %s\n", s.Ticker, s.Error)
+		case s.Triggered:
+			PrintHumanf(format, "✓ %-6s %-5s close $%.2f broke %d-bar channel ($%.2f / $%.2f), exit $%.2f\n",
+				s.Ticker, s.Direction, s.Close, s.EntryLookback, s.ChannelHigh, s.ChannelLow, s.ExitLevel)
+		default:
+			PrintHumanf(format, "  %-6s no breakout (close $%.2f inside $%.2f - $%.2f)\n",
+				s.Ticker, s.Close, s.ChannelLow, s.ChannelHigh)
+		}
+	}
+	PrintHumanf(format, "\n%d of %d tickers broke out (%s)\n", triggered, len(signals), params.System)
+
+	log.WithField("triggered", triggered).Info("Breakout scan completed")
+
+	if err := PrintJSON(output); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
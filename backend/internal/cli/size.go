@@ -3,10 +3,13 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/trading-engine/internal/domain"
 	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -21,43 +24,73 @@ This command calculates the number of shares or contracts to trade based on:
 - Account equity
 - Risk per trade (as % of equity)
 - Entry price
-- ATR (Average True Range)
+- ATR (Average True Range), computed from stored bars when --atr is omitted
 - K multiple (stop distance multiplier)
 
 The system enforces Van Tharp's position sizing rules:
   Risk dollars (R) = Equity × RiskPct
   Stop distance = K × ATR
-  Initial stop = Entry - Stop distance
+  Initial stop = Entry - Stop distance (Entry + Stop distance for --direction SHORT)
   Shares = floor(R ÷ Stop distance)
 
+When --atr is omitted, N is the 20-day Wilder ATR computed from the daily
+bars stored for --ticker (see import-prices). The output records the bar
+date N was taken from in "atr_date".
+
+For opt-delta-atr, --delta may be omitted when the option leg is given with
+--strike and --exp: the leg is priced with Black-Scholes (using --entry as
+the underlying price) and delta is derived from it. IV is solved from
+--premium unless --iv is given. The output records "delta" and "iv".
+
 Examples:
   # Stock position sizing
   tf-engine size --entry 180 --atr 1.5 --k 2 --method stock
 
+  # Short position: stop goes 2N above entry
+  tf-engine size --entry 180 --atr 1.5 --k 2 --method stock --direction SHORT
+
+  # Stock position sizing with N from stored price bars
+  tf-engine size --ticker AAPL --entry 180 --method stock
+
   # Use custom equity and risk
   tf-engine size --entry 180 --atr 1.5 --equity 20000 --risk 0.01 --method stock
 
   # Option sizing (delta-ATR method)
-  tf-engine size --entry 5.00 --atr 0.50 --delta 0.50 --method opt-delta-atr`,
+  tf-engine size --entry 5.00 --atr 0.50 --delta 0.50 --method opt-delta-atr
+
+  # Option sizing with delta derived from the leg's premium
+  tf-engine size --entry 180 --atr 1.5 --method opt-delta-atr \
+    --option-type CALL --strike 185 --exp 2025-03-21 --premium 4.20`,
 		RunE: runSize,
 	}
 
 	// Required flags
 	cmd.Flags().Float64("entry", 0, "Entry price (required)")
-	cmd.Flags().Float64("atr", 0, "Average True Range / ATR (required)")
+	cmd.Flags().Float64("atr", 0, "Average True Range / ATR (default: computed from stored bars for --ticker)")
+	cmd.Flags().String("ticker", "", "Ticker used to look up stored bars when --atr is omitted")
 	cmd.Flags().String("method", "stock", "Sizing method: stock, opt-delta-atr, opt-maxloss")
+	cmd.Flags().String("direction", domain.DirectionLong, "Trade direction: LONG or SHORT")
 
 	// Optional flags (will use settings from DB if not provided)
 	cmd.Flags().Float64("equity", 0, "Account equity (default: from database)")
 	cmd.Flags().Float64("risk", 0, "Risk per trade as decimal (default: from database)")
-	cmd.Flags().Int("k", 0, "Stop multiple (default: from database)")
+	cmd.Flags().Float64("k", 0, "Stop multiple (default: from database)")
 
 	// Option-specific flags
-	cmd.Flags().Float64("delta", 0, "Option delta (required for opt-delta-atr)")
+	cmd.Flags().Float64("delta", 0, "Option delta (required for opt-delta-atr unless --strike is given)")
 	cmd.Flags().Float64("maxloss", 0, "Max loss per contract (required for opt-maxloss)")
 
+	// Option leg flags (derive delta for opt-delta-atr)
+	cmd.Flags().String("option-type", options.Call, "Option type: CALL or PUT")
+	cmd.Flags().Float64("strike", 0, "Option strike; derives delta from the leg")
+	cmd.Flags().String("exp", "", "Option expiration (YYYY-MM-DD)")
+	cmd.Flags().Float64("premium", 0, "Option price per share, used to solve IV")
+	cmd.Flags().Float64("iv", 0, "Implied volatility as decimal (default: solved from --premium)")
+	cmd.Flags().Float64("rate", options.DefaultRiskFreeRate, "Risk-free rate as decimal")
+	cmd.Flags().Float64("div-yield", 0, "Dividend yield as decimal")
+	cmd.Flags().String("as-of", "", "Pricing date YYYY-MM-DD (default: today)")
+
 	cmd.MarkFlagRequired("entry")
-	cmd.MarkFlagRequired("atr")
 
 	return cmd
 }
@@ -80,21 +113,31 @@ func runSize(cmd *cobra.Command, args []string) error {
 	// Get flags
 	entry, _ := cmd.Flags().GetFloat64("entry")
 	atr, _ := cmd.Flags().GetFloat64("atr")
+	ticker, _ := cmd.Flags().GetString("ticker")
 	method, _ := cmd.Flags().GetString("method")
 	equity, _ := cmd.Flags().GetFloat64("equity")
 	risk, _ := cmd.Flags().GetFloat64("risk")
-	k, _ := cmd.Flags().GetInt("k")
+	k, _ := cmd.Flags().GetFloat64("k")
 	delta, _ := cmd.Flags().GetFloat64("delta")
 	maxloss, _ := cmd.Flags().GetFloat64("maxloss")
+	direction, _ := cmd.Flags().GetString("direction")
+	optionType, _ := cmd.Flags().GetString("option-type")
+	strike, _ := cmd.Flags().GetFloat64("strike")
+	exp, _ := cmd.Flags().GetString("exp")
+	premium, _ := cmd.Flags().GetFloat64("premium")
+	iv, _ := cmd.Flags().GetFloat64("iv")
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	divYield, _ := cmd.Flags().GetFloat64("div-yield")
+	asOf, _ := cmd.Flags().GetString("as-of")
 
 	// Load settings from database if not provided
 	if equity == 0 {
-		equityStr, err := db.GetSetting("Equity_E")
+		sizing, err := marketdata.SizingEquity(db)
 		if err != nil {
-			log.WithError(err).Error("Failed to get Equity_E from database")
+			log.WithError(err).Error("Failed to get equity from database")
 			return fmt.Errorf("failed to get equity from database: %w", err)
 		}
-		fmt.Sscanf(equityStr, "%f", &equity)
+		equity = sizing
 		log.WithField("equity", equity).Debug("Loaded equity from database")
 	}
 
@@ -114,20 +157,48 @@ func runSize(cmd *cobra.Command, args []string) error {
 			log.WithError(err).Error("Failed to get StopMultiple_K from database")
 			return fmt.Errorf("failed to get K multiple from database: %w", err)
 		}
-		fmt.Sscanf(kStr, "%d", &k)
+		fmt.Sscanf(kStr, "%f", &k)
 		log.WithField("k", k).Debug("Loaded K multiple from database")
 	}
 
 	// Build request
 	req := domain.SizingRequest{
-		Equity:  equity,
-		RiskPct: risk,
-		Entry:   entry,
-		ATR:     atr,
-		K:       k,
-		Method:  method,
-		Delta:   delta,
-		MaxLoss: maxloss,
+		Equity:    equity,
+		RiskPct:   risk,
+		Entry:     entry,
+		ATR:       atr,
+		K:         k,
+		Method:    method,
+		Delta:     delta,
+		MaxLoss:   maxloss,
+		Ticker:    strings.ToUpper(ticker),
+		Direction: direction,
+		Pricing: options.Market{
+			Rate:     rate,
+			DivYield: divYield,
+			Vol:      iv,
+			AsOf:     asOf,
+		},
+	}
+	if strike > 0 {
+		req.Leg = &options.Leg{
+			Type:   optionType,
+			Strike: strike,
+			Exp:    exp,
+			Qty:    1,
+			Action: "BUY",
+			Price:  premium,
+		}
+	}
+
+	// Fill N from stored price bars when not supplied
+	atrResult, err := domain.ResolveSizingATR(marketdata.NewDBBarProvider(db), &req)
+	if err != nil {
+		log.WithError(err).Error("Failed to compute ATR from stored bars")
+		return fmt.Errorf("failed to compute ATR (pass --atr or import prices): %w", err)
+	}
+	if atrResult != nil {
+		log.WithField("atr", atrResult.ATR).WithField("atr_date", atrResult.AsOfDate).Info("Using stored ATR (N)")
 	}
 
 	log.WithField("request", req).Info("Calculating position size")
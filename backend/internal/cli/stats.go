@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// NewStatsCommand creates the stats command
+func NewStatsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show R-multiple expectancy statistics of closed trades",
+		Long: `Show the R-multiple trade journal statistics of closed positions.
+
+Each closed trade is measured in R: its P&L over the initial risk it was
+sized with (plus each pyramid unit's risk at its fill). Moving the stop
+does not change R. A rolled option position and its replacements count as
+one trade measured against the first position's risk. Trades without a
+recorded initial risk count toward win rate and profit factor but not
+toward the R statistics.
+
+Reports expectancy, win rate, average win and loss in R, profit factor,
+SQN and the longest win and loss streaks, overall and broken down by
+strategy, bucket, breakout system and instrument type.
+
+Examples:
+  # All closed trades
+  tf-engine stats
+
+  # Trades that exited in 2025, with the journal
+  tf-engine stats --from 2025-01-01 --to 2025-12-31 --trades
+
+  # Only the bucket breakdown
+  tf-engine stats --by bucket`,
+		RunE: runStats,
+	}
+
+	cmd.Flags().String("from", "", "First exit date to include (YYYY-MM-DD)")
+	cmd.Flags().String("to", "", "Last exit date to include (YYYY-MM-DD)")
+	cmd.Flags().String("by", "all", "Breakdown to show: strategy, bucket, system, instrument, all or none")
+	cmd.Flags().Bool("trades", false, "Also list every trade with its R-multiple")
+
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	by, _ := cmd.Flags().GetString("by")
+	showTrades, _ := cmd.Flags().GetBool("trades")
+
+	switch by {
+	case "strategy", "bucket", "system", "instrument", "all", "none":
+	default:
+		return fmt.Errorf("--by must be strategy, bucket, system, instrument, all or none, got %q", by)
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	trades, err := analytics.LoadJournal(db)
+	if err != nil {
+		log.WithError(err).Error("Failed to load trade journal")
+		return err
+	}
+	report := analytics.BuildReport(analytics.FilterTrades(trades, from, to))
+	report.From, report.To = from, to
+
+	log.WithFields(map[string]interface{}{
+		"trades":       report.Overall.Trades,
+		"expectancy_r": report.Overall.ExpectancyR,
+	}).Info("Trade statistics computed")
+
+	PrintHuman(format, "R-Multiple Statistics")
+	PrintHuman(format, "=====================")
+	if report.Overall.Trades == 0 {
+		PrintHuman(format, "No closed trades")
+	} else {
+		printStats(format, report.Overall)
+	}
+
+	breakdowns := []struct {
+		name   string
+		title  string
+		groups map[string]analytics.Stats
+	}{
+		{"strategy", "By Strategy", report.ByStrategy},
+		{"bucket", "By Bucket", report.ByBucket},
+		{"system", "By Breakout System", report.BySystem},
+		{"instrument", "By Instrument", report.ByInstrument},
+	}
+	for _, b := range breakdowns {
+		if report.Overall.Trades == 0 || (by != "all" && by != b.name) {
+			continue
+		}
+		PrintHuman(format, "")
+		PrintHuman(format, b.title)
+		PrintHumanf(format, "%-20s %6s %7s %8s %8s %8s %6s %6s\n", "", "TRADES", "WIN %", "EXP R", "AVG W", "AVG L", "PF", "SQN")
+		names := make([]string, 0, len(b.groups))
+		for name := range b.groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s := b.groups[name]
+			PrintHumanf(format, "%-20s %6d %6.1f%% %8.2f %8.2f %8.2f %6.2f %6.2f\n",
+				name, s.Trades, s.WinRatePct, s.ExpectancyR, s.AvgWinR, s.AvgLossR, s.ProfitFactor, s.SQN)
+		}
+	}
+
+	if showTrades && len(report.Trades) > 0 {
+		PrintHuman(format, "")
+		PrintHuman(format, "Trades")
+		PrintHumanf(format, "%-6s %-8s %-18s %-10s %-10s %10s %10s %7s\n", "ID", "TICKER", "STRATEGY", "ENTRY", "EXIT", "1R", "P&L", "R")
+		for _, t := range report.Trades {
+			r := "n/a"
+			if t.Rated {
+				r = fmt.Sprintf("%.2f", t.RMultiple)
+			}
+			PrintHumanf(format, "%-6d %-8s %-18s %-10s %-10s %10.2f %10.2f %7s\n",
+				t.PositionID, t.Ticker, t.Strategy, t.EntryDate, t.ExitDate, t.InitialRisk, t.PnL, r)
+		}
+	}
+
+	PrintHuman(format, "")
+
+	// JSON output (always)
+	PrintJSON(report)
+
+	return nil
+}
+
+// printStats prints the headline statistics of a set of trades
+func printStats(format OutputFormat, s analytics.Stats) {
+	PrintHumanf(format, "Trades:         %d (%d W / %d L / %d scratch)\n", s.Trades, s.Wins, s.Losses, s.Scratches)
+	if s.Rated < s.Trades {
+		PrintHumanf(format, "Without 1R:     %d (left out of the R statistics)\n", s.Trades-s.Rated)
+	}
+	PrintHumanf(format, "Win Rate:       %.1f%%\n", s.WinRatePct)
+	PrintHumanf(format, "Net P&L:        $%.2f\n", s.NetPnL)
+	PrintHumanf(format, "Total:          %.2fR\n", s.TotalR)
+	PrintHumanf(format, "Expectancy:     %.2fR per trade\n", s.ExpectancyR)
+	PrintHumanf(format, "Avg Win / Loss: %.2fR / %.2fR\n", s.AvgWinR, s.AvgLossR)
+	PrintHumanf(format, "Largest W / L:  %.2fR / %.2fR\n", s.LargestWinR, s.LargestLossR)
+	PrintHumanf(format, "Profit Factor:  %.2f\n", s.ProfitFactor)
+	PrintHumanf(format, "SQN:            %.2f\n", s.SQN)
+	PrintHumanf(format, "Streaks:        %d wins / %d losses\n", s.MaxWinStreak, s.MaxLossStreak)
+}
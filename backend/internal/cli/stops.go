@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// TrailStopsReport is the output of trail-stops
+type TrailStopsReport struct {
+	Rule       string                      `json:"rule"`
+	Applied    bool                        `json:"applied"`
+	Positions  []domain.TrailingStopResult `json:"positions"`
+	Errors     map[string]string           `json:"errors,omitempty"` // Ticker -> why it was skipped
+	Changed    int                         `json:"changed"`
+	HeatBefore float64                     `json:"heat_before"`
+	HeatAfter  float64                     `json:"heat_after"` // Projected when not applied
+}
+
+// NewTrailStopsCommand creates the trail-stops command
+func NewTrailStopsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trail-stops",
+		Short: "Ratchet stops on open positions from daily bars",
+		Long: `Compute a new stop for every open position from its daily bars and
+propose (or, with --apply, record) the move through update-stop.
+
+Rules:
+  DONCHIAN   - exit-lookback low for longs, high for shorts (default)
+  CHANDELIER - highest close since entry - K × N (lowest close + K × N for shorts)
+  BREAKEVEN  - entry price once a close has gone 1R in favour
+
+Stops only ratchet toward price: a rule level that would loosen the
+current stop is ignored. Each line shows the change in risk dollars,
+and the summary shows portfolio heat before and after. Option positions
+are left to roll and expire.
+
+Bars are read from <TICKER>.csv files in --dir.
+
+Examples:
+  # Preview 10-bar Donchian stops
+  tf-engine trail-stops --dir ./data/prices
+
+  # Apply 3N chandelier stops
+  tf-engine trail-stops --dir ./data/prices --rule CHANDELIER --k 3 --apply`,
+		RunE: runTrailStops,
+	}
+
+	cmd.Flags().String("dir", "", "Folder of <TICKER>.csv daily bars (required)")
+	cmd.Flags().String("rule", domain.StopRuleDonchian, "Stop rule: DONCHIAN, CHANDELIER or BREAKEVEN")
+	cmd.Flags().Int("lookback", domain.DefaultExitLookback, "Exit lookback in bars for DONCHIAN")
+	cmd.Flags().Float64("k", 0, "N multiple for CHANDELIER (default: StopMultiple_K)")
+	cmd.Flags().String("tickers", "", "Comma-separated tickers (default: all open positions)")
+	cmd.Flags().Bool("apply", false, "Record the new stops (default: preview only)")
+	cmd.MarkFlagRequired("dir")
+
+	return cmd
+}
+
+func runTrailStops(cmd *cobra.Command, args []string) error {
+	dbPath := cmd.Flag("db").Value.String()
+	corrID := cmd.Flag("corr-id").Value.String()
+	format := GetOutputFormat(cmd)
+	log := logx.WithCorrelationID(corrID)
+
+	dir, _ := cmd.Flags().GetString("dir")
+	ruleFlag, _ := cmd.Flags().GetString("rule")
+	lookback, _ := cmd.Flags().GetInt("lookback")
+	k, _ := cmd.Flags().GetFloat64("k")
+	tickersFlag, _ := cmd.Flags().GetString("tickers")
+	apply, _ := cmd.Flags().GetBool("apply")
+
+	rule, err := domain.ParseStopRule(ruleFlag)
+	if err != nil {
+		return err
+	}
+	if lookback <= 0 {
+		return fmt.Errorf("lookback must be positive, got %d", lookback)
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		log.WithError(err).Error("Failed to open database")
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if k <= 0 {
+		kStr, err := db.GetSetting("StopMultiple_K")
+		if err != nil {
+			return fmt.Errorf("failed to get K multiple from database: %w", err)
+		}
+		fmt.Sscanf(kStr, "%f", &k)
+	}
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		log.WithError(err).Error("Failed to get open positions")
+		return fmt.Errorf("failed to get open positions: %w", err)
+	}
+
+	positions = filterPositions(positions, tickersFlag)
+
+	// Option positions risk their premium, not shares to a stop on the
+	// underlying: roll and expire manage them
+	var stockPositions []storage.Position
+	for _, p := range positions {
+		if p.InstrumentType != storage.InstrumentOption {
+			stockPositions = append(stockPositions, p)
+		}
+	}
+	positions = stockPositions
+
+	heatBefore, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		return fmt.Errorf("failed to calculate portfolio heat: %w", err)
+	}
+
+	log.WithField("rule", rule).WithField("positions", len(positions)).WithField("apply", apply).Info("Trailing stops")
+
+	provider := marketdata.NewCSVBarProvider(dir)
+	report := TrailStopsReport{
+		Rule:       rule,
+		Applied:    apply,
+		Positions:  []domain.TrailingStopResult{},
+		HeatBefore: heatBefore,
+		HeatAfter:  heatBefore,
+	}
+
+	for i := range positions {
+		position := &positions[i]
+
+		result, err := trailPosition(db, provider, position, rule, lookback, k)
+		if err != nil {
+			log.WithError(err).WithField("ticker", position.Ticker).Warn("Skipping position")
+			if report.Errors == nil {
+				report.Errors = map[string]string{}
+			}
+			report.Errors[position.Ticker] = err.Error()
+			continue
+		}
+
+		if result.Changed {
+			report.Changed++
+			if apply {
+				if err := db.UpdateStop(position.Ticker, result.NewStop); err != nil {
+					result.ApplyError = err.Error()
+				} else if updated, err := db.GetPosition(position.ID); err == nil {
+					result.Applied = true
+					result.NewRisk = updated.RiskDollars
+					result.RiskChange = result.NewRisk - result.CurrentRisk
+				}
+			}
+			if !apply || result.Applied {
+				report.HeatAfter += result.RiskChange
+			}
+		}
+		report.Positions = append(report.Positions, *result)
+	}
+
+	if apply {
+		if report.HeatAfter, err = db.CalculatePortfolioHeat(); err != nil {
+			return fmt.Errorf("failed to calculate portfolio heat: %w", err)
+		}
+	}
+
+	for _, r := range report.Positions {
+		switch {
+		case r.ApplyError != "":
+			PrintHumanf(format, "⚠️  %-6s %s\n", r.Ticker, r.ApplyError)
+		case r.Changed:
+			verb := "→"
+			if r.Applied {
+				verb = "moved to"
+			}
+			PrintHumanf(format, "✓ %-6s %-5s stop $%.2f %s $%.2f  risk $%.2f → $%.2f (%+.2f)  [%s]\n",
+				r.Ticker, r.Direction, r.CurrentStop, verb, r.NewStop, r.CurrentRisk, r.NewRisk, r.RiskChange, r.Reason)
+		default:
+			PrintHumanf(format, "  %-6s %-5s stop $%.2f unchanged  [%s]\n", r.Ticker, r.Direction, r.CurrentStop, r.Reason)
+		}
+	}
+	for ticker, msg := range report.Errors {
+		PrintHumanf(format, "⚠️  %-6s %s\n", ticker, msg)
+	}
+
+	action := "would move"
+	if apply {
+		action = "moved"
+	}
+	PrintHumanf(format, "\n%d of %d stops %s (%s)\n", report.Changed, len(positions), action, rule)
+	PrintHumanf(format, "Portfolio heat: $%.2f → $%.2f\n", report.HeatBefore, report.HeatAfter)
+
+	log.WithField("changed", report.Changed).WithField("heat_after", report.HeatAfter).Info("Trailing stops completed")
+
+	if err := PrintJSON(report); err != nil {
+		log.WithError(err).Error("Failed to marshal result to JSON")
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return nil
+}
+
+// trailPosition computes the rule's stop for one open position from its bars
+func trailPosition(db *storage.DB, provider domain.BarProvider, position *storage.Position, rule string, lookback int, k float64) (*domain.TrailingStopResult, error) {
+	bars, err := provider.GetDailyBars(position.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := trailingStopRequest(db, position)
+	if err != nil {
+		return nil, err
+	}
+	req.Rule = rule
+	req.ExitLookback = lookback
+	req.K = k
+
+	return domain.ComputeTrailingStop(req, bars)
+}
+
+// trailingStopRequest builds the trailing stop request for an open position
+// from its open lots (rule and parameters are left to the caller)
+func trailingStopRequest(db *storage.DB, position *storage.Position) (domain.TrailingStopRequest, error) {
+	units, err := openUnits(db, position)
+	if err != nil {
+		return domain.TrailingStopRequest{}, err
+	}
+
+	return domain.TrailingStopRequest{
+		Ticker:      position.Ticker,
+		Direction:   position.Direction,
+		EntryPrice:  position.EntryPrice,
+		EntryDate:   position.TradeDate(),
+		InitialStop: position.InitialStop,
+		CurrentStop: position.CurrentStop,
+		CurrentRisk: position.RiskDollars,
+		Units:       units,
+		N:           position.ATR,
+	}, nil
+}
+
+// openUnits returns the open shares of a position per lot
+func openUnits(db *storage.DB, position *storage.Position) ([]domain.PyramidUnit, error) {
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lots: %w", err)
+	}
+
+	var units []domain.PyramidUnit
+	for _, lot := range lots {
+		if lot.RemainingShares == 0 {
+			continue // Scaled out
+		}
+		units = append(units, domain.PyramidUnit{
+			UnitNum:    lot.UnitNum,
+			Shares:     lot.RemainingShares,
+			EntryPrice: lot.EntryPrice,
+		})
+	}
+	if len(units) == 0 {
+		// Position predates lot tracking
+		units = []domain.PyramidUnit{{UnitNum: 1, Shares: position.Shares, EntryPrice: position.EntryPrice}}
+	}
+	return units, nil
+}
+
+// filterPositions keeps the positions in a comma-separated ticker list
+// (all of them when the list is empty)
+func filterPositions(positions []storage.Position, tickers string) []storage.Position {
+	if tickers == "" {
+		return positions
+	}
+	wanted := map[string]bool{}
+	for _, t := range strings.Split(tickers, ",") {
+		wanted[strings.ToUpper(strings.TrimSpace(t))] = true
+	}
+	filtered := positions[:0]
+	for _, p := range positions {
+		if wanted[p.Ticker] {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DefaultATRPeriod is the Turtle N lookback (20-day Wilder ATR)
+const DefaultATRPeriod = 20
+
+// Bar represents one daily OHLCV price bar
+type Bar struct {
+	Date   string  `json:"date"` // YYYY-MM-DD
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
+}
+
+// ATRResult contains a computed ATR (N) and the bar it was taken from
+type ATRResult struct {
+	ATR      float64 `json:"atr_n"`
+	Period   int     `json:"period"`
+	AsOfDate string  `json:"as_of_date"` // Date of the last bar used
+	BarsUsed int     `json:"bars_used"`
+}
+
+// BarProvider supplies stored daily bars for a ticker (oldest first)
+type BarProvider interface {
+	GetDailyBars(ticker string) ([]Bar, error)
+}
+
+// ATR errors
+var (
+	ErrInvalidATRPeriod = errors.New("ATR period must be positive")
+	ErrInsufficientBars = errors.New("not enough price bars to compute ATR")
+	ErrNoTickerForATR   = errors.New("ticker is required to look up stored ATR")
+)
+
+// TrueRange returns the Wilder true range of a bar given the previous close
+//
+//	TR = max(High - Low, |High - PrevClose|, |Low - PrevClose|)
+func TrueRange(bar Bar, prevClose float64) float64 {
+	tr := bar.High - bar.Low
+	tr = math.Max(tr, math.Abs(bar.High-prevClose))
+	tr = math.Max(tr, math.Abs(bar.Low-prevClose))
+	return tr
+}
+
+// CalculateATR computes Wilder's average true range over the given period
+//
+// Wilder smoothing (from the original Turtle rules):
+//  1. TR for each bar after the first uses the previous close
+//  2. Seed N = simple average of the first `period` true ranges
+//  3. Each later bar: N = ((period - 1) × prevN + TR) ÷ period
+//
+// Bars must be sorted oldest first. At least period+1 bars are required
+// so every true range has a previous close.
+func CalculateATR(bars []Bar, period int) (*ATRResult, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidATRPeriod, period)
+	}
+	if len(bars) < period+1 {
+		return nil, fmt.Errorf("%w: need %d, got %d", ErrInsufficientBars, period+1, len(bars))
+	}
+
+	// Seed with simple average of first `period` true ranges
+	sum := 0.0
+	for i := 1; i <= period; i++ {
+		sum += TrueRange(bars[i], bars[i-1].Close)
+	}
+	atr := sum / float64(period)
+
+	// Wilder smoothing for the remaining bars
+	for i := period + 1; i < len(bars); i++ {
+		tr := TrueRange(bars[i], bars[i-1].Close)
+		atr = (atr*float64(period-1) + tr) / float64(period)
+	}
+
+	return &ATRResult{
+		ATR:      atr,
+		Period:   period,
+		AsOfDate: bars[len(bars)-1].Date,
+		BarsUsed: len(bars),
+	}, nil
+}
+
+// ATRSeries returns the Wilder ATR as of each bar using the same smoothing
+// as CalculateATR. Entries before period+1 bars of history are 0. Use this
+// when walking bars day by day instead of recomputing N from scratch.
+func ATRSeries(bars []Bar, period int) []float64 {
+	series := make([]float64, len(bars))
+	if period <= 0 || len(bars) < period+1 {
+		return series
+	}
+
+	sum := 0.0
+	for i := 1; i <= period; i++ {
+		sum += TrueRange(bars[i], bars[i-1].Close)
+	}
+	atr := sum / float64(period)
+	series[period] = atr
+
+	for i := period + 1; i < len(bars); i++ {
+		atr = (atr*float64(period-1) + TrueRange(bars[i], bars[i-1].Close)) / float64(period)
+		series[i] = atr
+	}
+	return series
+}
+
+// ResolveSizingATR fills SizingRequest.ATR from stored bars when the caller omitted it
+//
+// A manually supplied ATR always wins, and opt-maxloss sizing does not use N,
+// so both cases return a nil result without touching the provider. Otherwise
+// the 20-day Wilder ATR is computed for req.Ticker and the request's ATR and
+// ATRDate are set from it.
+func ResolveSizingATR(provider BarProvider, req *SizingRequest) (*ATRResult, error) {
+	if req.ATR > 0 || req.Method == "opt-maxloss" {
+		return nil, nil
+	}
+	if req.Ticker == "" {
+		return nil, ErrNoTickerForATR
+	}
+
+	bars, err := provider.GetDailyBars(req.Ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bars for %s: %w", req.Ticker, err)
+	}
+
+	result, err := CalculateATR(bars, DefaultATRPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", req.Ticker, err)
+	}
+
+	req.ATR = result.ATR
+	req.ATRDate = result.AsOfDate
+	return result, nil
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flatBars builds n bars with a constant 2.00 range around a 100 close
+func flatBars(n int) []Bar {
+	bars := make([]Bar, n)
+	for i := range bars {
+		bars[i] = Bar{
+			Date:  fmt.Sprintf("2025-01-%02d", i+1),
+			Open:  100,
+			High:  101,
+			Low:   99,
+			Close: 100,
+		}
+	}
+	return bars
+}
+
+type stubBarProvider struct {
+	bars  []Bar
+	err   error
+	calls int
+}
+
+func (s *stubBarProvider) GetDailyBars(ticker string) ([]Bar, error) {
+	s.calls++
+	return s.bars, s.err
+}
+
+func TestTrueRange(t *testing.T) {
+	bar := Bar{High: 105, Low: 102, Close: 104}
+
+	// Range inside previous close: high-low wins
+	assert.Equal(t, 3.0, TrueRange(bar, 103))
+	// Gap up: high - prevClose wins
+	assert.Equal(t, 7.0, TrueRange(bar, 98))
+	// Gap down: prevClose - low wins
+	assert.Equal(t, 8.0, TrueRange(bar, 110))
+}
+
+func TestCalculateATR_ConstantRange(t *testing.T) {
+	result, err := CalculateATR(flatBars(30), DefaultATRPeriod)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2.0, result.ATR, 1e-9)
+	assert.Equal(t, 20, result.Period)
+	assert.Equal(t, "2025-01-30", result.AsOfDate)
+	assert.Equal(t, 30, result.BarsUsed)
+}
+
+func TestCalculateATR_WilderSmoothing(t *testing.T) {
+	// Period 2: seed = avg(TR1, TR2), then N = (N + TR) / 2
+	bars := []Bar{
+		{Date: "d0", High: 10, Low: 9, Close: 10},
+		{Date: "d1", High: 11, Low: 10, Close: 11}, // TR = 1
+		{Date: "d2", High: 12, Low: 9, Close: 12},  // TR = 3
+		{Date: "d3", High: 16, Low: 14, Close: 15}, // TR = 4 (gap from 12)
+	}
+
+	result, err := CalculateATR(bars, 2)
+	require.NoError(t, err)
+
+	// Seed = (1 + 3) / 2 = 2; smoothed = (2×1 + 4) / 2 = 3
+	assert.InDelta(t, 3.0, result.ATR, 1e-9)
+	assert.Equal(t, "d3", result.AsOfDate)
+}
+
+func TestCalculateATR_Errors(t *testing.T) {
+	_, err := CalculateATR(flatBars(20), DefaultATRPeriod)
+	assert.True(t, errors.Is(err, ErrInsufficientBars))
+
+	_, err = CalculateATR(flatBars(5), 0)
+	assert.True(t, errors.Is(err, ErrInvalidATRPeriod))
+}
+
+func TestResolveSizingATR_FillsMissingATR(t *testing.T) {
+	provider := &stubBarProvider{bars: flatBars(25)}
+	req := SizingRequest{Ticker: "AAPL", Method: "stock"}
+
+	result, err := ResolveSizingATR(provider, &req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.InDelta(t, 2.0, req.ATR, 1e-9)
+	assert.Equal(t, "2025-01-25", req.ATRDate)
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestResolveSizingATR_ManualATRWins(t *testing.T) {
+	provider := &stubBarProvider{bars: flatBars(25)}
+	req := SizingRequest{Ticker: "AAPL", Method: "stock", ATR: 1.5}
+
+	result, err := ResolveSizingATR(provider, &req)
+	require.NoError(t, err)
+
+	assert.Nil(t, result)
+	assert.Equal(t, 1.5, req.ATR)
+	assert.Empty(t, req.ATRDate)
+	assert.Equal(t, 0, provider.calls)
+}
+
+func TestResolveSizingATR_MaxLossSkipsLookup(t *testing.T) {
+	provider := &stubBarProvider{}
+	req := SizingRequest{Ticker: "AAPL", Method: "opt-maxloss", MaxLoss: 250}
+
+	result, err := ResolveSizingATR(provider, &req)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, 0, provider.calls)
+}
+
+func TestResolveSizingATR_Errors(t *testing.T) {
+	_, err := ResolveSizingATR(&stubBarProvider{}, &SizingRequest{Method: "stock"})
+	assert.True(t, errors.Is(err, ErrNoTickerForATR))
+
+	_, err = ResolveSizingATR(&stubBarProvider{bars: flatBars(3)}, &SizingRequest{Ticker: "AAPL", Method: "stock"})
+	assert.True(t, errors.Is(err, ErrInsufficientBars))
+}
+
+func TestCalculatePositionSize_CarriesATRDate(t *testing.T) {
+	req := SizingRequest{
+		Equity:  10000,
+		RiskPct: 0.0075,
+		Entry:   180,
+		ATR:     1.5,
+		ATRDate: "2025-01-30",
+		K:       2,
+		Method:  "stock",
+	}
+
+	result, err := CalculatePositionSize(req)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, result.ATR)
+	assert.Equal(t, "2025-01-30", result.ATRDate)
+}
@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// Breakout outcome sources
+const (
+	OutcomeSourceReplay  = "REPLAY"        // Hypothetical trade replayed from bars
+	OutcomeSourceHistory = "TRADE_HISTORY" // Actual trade from trade_history
+)
+
+// Breakout exit reasons
+const (
+	ExitReasonStop    = "STOP"    // Hit the K×N protective stop
+	ExitReasonChannel = "CHANNEL" // Broke the Donchian exit channel
+	ExitReasonOpen    = "OPEN"    // Still open at the last bar
+)
+
+// DefaultFilterStopMultiple is the Turtle 2N stop used when replaying breakouts
+const DefaultFilterStopMultiple = 2.0
+
+// BreakoutOutcome describes how one breakout played out
+type BreakoutOutcome struct {
+	Direction    string  `json:"direction"` // LONG or SHORT
+	BreakoutDate string  `json:"breakout_date"`
+	EntryPrice   float64 `json:"entry_price"`
+	StopPrice    float64 `json:"stop_price,omitempty"`
+	ExitDate     string  `json:"exit_date,omitempty"`
+	ExitPrice    float64 `json:"exit_price,omitempty"`
+	ExitReason   string  `json:"exit_reason,omitempty"` // Replay only
+	PnLPerShare  float64 `json:"pnl_per_share"`
+	Winner       bool    `json:"winner"`
+	Source       string  `json:"source"`
+}
+
+// LastBreakoutFilterResult is the System 1 skip decision for a ticker
+type LastBreakoutFilterResult struct {
+	Ticker     string           `json:"ticker"`
+	SignalDate string           `json:"signal_date"`
+	Direction  string           `json:"direction"`
+	Applies    bool             `json:"applies"` // False when there is no System 1 breakout today
+	Skip       bool             `json:"skip"`
+	Prior      *BreakoutOutcome `json:"prior,omitempty"`
+	Reason     string           `json:"reason"`
+}
+
+// ReplayBreakouts walks the bars and simulates every breakout in one direction
+//
+// Replay rules (one hypothetical unit at a time):
+//   - Enter at the close of a bar that closes through the EntryLookback channel
+//   - Protective stop at entry ∓ stopMultiple × N (skipped until N is available)
+//   - Exit when price trades through the ExitLookback channel or the stop,
+//     filling at the open if the bar gapped past the level
+//
+// The last outcome may still be open (ExitReason OPEN) at the final bar.
+func ReplayBreakouts(bars []Bar, params BreakoutParams, stopMultiple float64, direction string) []BreakoutOutcome {
+	outcomes := []BreakoutOutcome{}
+	if params.EntryLookback <= 0 || params.ExitLookback <= 0 {
+		return outcomes
+	}
+
+	long := direction != SignalShort
+	atr := ATRSeries(bars, DefaultATRPeriod)
+
+	var open *BreakoutOutcome
+	for i := params.EntryLookback; i < len(bars); i++ {
+		bar := bars[i]
+
+		if open != nil {
+			exitHigh, exitLow, err := DonchianChannel(bars, i, min(params.ExitLookback, i))
+			if err == nil {
+				exitPrice, reason := 0.0, ""
+				if long {
+					if open.StopPrice > 0 && bar.Low <= open.StopPrice && open.StopPrice >= exitLow {
+						exitPrice, reason = math.Min(bar.Open, open.StopPrice), ExitReasonStop
+					} else if bar.Low < exitLow {
+						exitPrice, reason = math.Min(bar.Open, exitLow), ExitReasonChannel
+					}
+				} else {
+					if open.StopPrice > 0 && bar.High >= open.StopPrice && open.StopPrice <= exitHigh {
+						exitPrice, reason = math.Max(bar.Open, open.StopPrice), ExitReasonStop
+					} else if bar.High > exitHigh {
+						exitPrice, reason = math.Max(bar.Open, exitHigh), ExitReasonChannel
+					}
+				}
+
+				if reason != "" {
+					open.ExitDate = bar.Date
+					open.ExitPrice = exitPrice
+					open.ExitReason = reason
+					if long {
+						open.PnLPerShare = exitPrice - open.EntryPrice
+					} else {
+						open.PnLPerShare = open.EntryPrice - exitPrice
+					}
+					open.Winner = open.PnLPerShare > 0
+					outcomes = append(outcomes, *open)
+					open = nil
+				}
+			}
+			continue
+		}
+
+		high, low, err := DonchianChannel(bars, i, params.EntryLookback)
+		if err != nil {
+			continue
+		}
+		if (long && bar.Close > high) || (!long && bar.Close < low) {
+			open = &BreakoutOutcome{
+				Direction:    SignalLong,
+				BreakoutDate: bar.Date,
+				EntryPrice:   bar.Close,
+				ExitReason:   ExitReasonOpen,
+				Source:       OutcomeSourceReplay,
+			}
+			if !long {
+				open.Direction = SignalShort
+			}
+			if atr[i] > 0 && stopMultiple > 0 {
+				if long {
+					open.StopPrice = bar.Close - stopMultiple*atr[i]
+				} else {
+					open.StopPrice = bar.Close + stopMultiple*atr[i]
+				}
+			}
+		}
+	}
+
+	if open != nil {
+		last := bars[len(bars)-1]
+		if long {
+			open.PnLPerShare = last.Close - open.EntryPrice
+		} else {
+			open.PnLPerShare = open.EntryPrice - last.Close
+		}
+		outcomes = append(outcomes, *open)
+	}
+
+	return outcomes
+}
+
+// EvaluateLastBreakoutFilter applies the Turtle System 1 skip rule
+//
+// System 1 skips a 20-bar breakout when the previous breakout in the same
+// direction would have been a winner, whether or not it was actually traded.
+// The previous breakout is the most recent of:
+//   - the last completed breakout replayed from the bars before today
+//   - the last closed System 1 trade in trade_history (history)
+//
+// If the previous breakout was a loser, is still open, or there is none,
+// the signal is taken. System 2 (55-bar) breakouts are never skipped.
+func EvaluateLastBreakoutFilter(ticker string, bars []Bar, history []BreakoutOutcome, stopMultiple float64) (*LastBreakoutFilterResult, error) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+
+	signal, err := DetectBreakout(ticker, bars, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LastBreakoutFilterResult{
+		Ticker:     ticker,
+		SignalDate: signal.Date,
+		Direction:  signal.Direction,
+	}
+
+	if !signal.Triggered {
+		result.Reason = fmt.Sprintf("no System 1 breakout on %s, filter does not apply", signal.Date)
+		return result, nil
+	}
+	result.Applies = true
+
+	// Replay everything before today's signal bar
+	var prior *BreakoutOutcome
+	replayed := ReplayBreakouts(bars[:len(bars)-1], params, stopMultiple, signal.Direction)
+	for i := len(replayed) - 1; i >= 0; i-- {
+		if replayed[i].ExitReason != ExitReasonOpen {
+			prior = &replayed[i]
+			break
+		}
+	}
+	openReplay := len(replayed) > 0 && replayed[len(replayed)-1].ExitReason == ExitReasonOpen
+
+	// An actual trade that closed more recently wins over the replay
+	for i := range history {
+		h := history[i]
+		if h.Direction != signal.Direction || h.ExitDate == "" || h.ExitDate >= signal.Date {
+			continue
+		}
+		if prior == nil || h.BreakoutDate > prior.BreakoutDate {
+			prior = &h
+		}
+	}
+
+	switch {
+	case openReplay && (prior == nil || prior.BreakoutDate < replayed[len(replayed)-1].BreakoutDate):
+		open := replayed[len(replayed)-1]
+		result.Prior = &open
+		result.Reason = fmt.Sprintf("previous %s breakout on %s has not exited yet, taking System 1 signal",
+			open.Direction, open.BreakoutDate)
+	case prior == nil:
+		result.Reason = "no previous System 1 breakout found, taking signal"
+	case prior.Winner:
+		result.Prior = prior
+		result.Skip = true
+		result.Reason = fmt.Sprintf("System 1 skip: previous %s breakout on %s at $%.2f was a winner (exit $%.2f on %s, %+.2f/share, %s); take the 55-bar breakout instead",
+			prior.Direction, prior.BreakoutDate, prior.EntryPrice, prior.ExitPrice, prior.ExitDate, prior.PnLPerShare, sourceLabel(prior.Source))
+	default:
+		result.Prior = prior
+		result.Reason = fmt.Sprintf("previous %s breakout on %s at $%.2f was a loser (exit $%.2f on %s, %+.2f/share, %s), taking System 1 signal",
+			prior.Direction, prior.BreakoutDate, prior.EntryPrice, prior.ExitPrice, prior.ExitDate, prior.PnLPerShare, sourceLabel(prior.Source))
+	}
+
+	return result, nil
+}
+
+func sourceLabel(source string) string {
+	if source == OutcomeSourceHistory {
+		return "from trade history"
+	}
+	return "replayed"
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendCloses adds bars with a 1.00 range around each close
+func appendCloses(bars []Bar, start int, closes ...float64) []Bar {
+	for i, c := range closes {
+		bars = append(bars, Bar{
+			Date:  fmt.Sprintf("2025-03-%02d", start+i),
+			Open:  c,
+			High:  c + 0.5,
+			Low:   c - 0.5,
+			Close: c,
+		})
+	}
+	return bars
+}
+
+// winnerThenBreakout: breakout at 102, runs to 110, exits on 10-bar low, then a fresh breakout
+func winnerThenBreakout() []Bar {
+	bars := flatBars(25)
+	bars = appendCloses(bars, 1, 102, 104, 106, 108, 110)
+	// Fall back through the 10-bar low (channel exit well above entry)
+	bars = appendCloses(bars, 6, 109, 108, 107, 106, 105, 104, 103)
+	// Last bar: new 20-bar high
+	bars = appendCloses(bars, 13, 111)
+	return bars
+}
+
+// loserThenBreakout: breakout at 102 that immediately fails back through the 10-bar low
+func loserThenBreakout() []Bar {
+	bars := flatBars(25)
+	bars = appendCloses(bars, 1, 102, 98, 97)
+	bars = appendCloses(bars, 4, 98, 99, 100, 101)
+	bars = appendCloses(bars, 8, 103)
+	return bars
+}
+
+func TestReplayBreakouts_Winner(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	bars := winnerThenBreakout()
+
+	outcomes := ReplayBreakouts(bars[:len(bars)-1], params, 2.0, SignalLong)
+	require.Len(t, outcomes, 1)
+
+	o := outcomes[0]
+	assert.Equal(t, "2025-03-01", o.BreakoutDate)
+	assert.Equal(t, 102.0, o.EntryPrice)
+	assert.Equal(t, ExitReasonChannel, o.ExitReason)
+	assert.True(t, o.Winner)
+	assert.Greater(t, o.PnLPerShare, 0.0)
+}
+
+func TestReplayBreakouts_Loser(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	bars := loserThenBreakout()
+
+	outcomes := ReplayBreakouts(bars[:len(bars)-1], params, 2.0, SignalLong)
+	require.NotEmpty(t, outcomes)
+
+	o := outcomes[0]
+	// 10-bar low (99) sits above the 2N stop, so the channel exit fires first;
+	// the bar gapped open at 98, below the level, so it fills at the open
+	assert.Equal(t, ExitReasonChannel, o.ExitReason)
+	assert.Equal(t, 98.0, o.ExitPrice)
+	assert.False(t, o.Winner)
+	assert.InDelta(t, -4.0, o.PnLPerShare, 1e-9)
+}
+
+func TestReplayBreakouts_StopBeforeChannel(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	bars := flatBars(25)
+	// Tight 1N stop sits above the 10-bar low of 99
+	bars = appendCloses(bars, 1, 102, 100)
+
+	outcomes := ReplayBreakouts(bars, params, 1.0, SignalLong)
+	require.Len(t, outcomes, 1)
+
+	o := outcomes[0]
+	assert.Equal(t, ExitReasonStop, o.ExitReason)
+	assert.InDelta(t, o.StopPrice, o.ExitPrice, 1e-9)
+	assert.False(t, o.Winner)
+}
+
+func TestEvaluateLastBreakoutFilter_SkipsAfterWinner(t *testing.T) {
+	result, err := EvaluateLastBreakoutFilter("AAPL", winnerThenBreakout(), nil, 2.0)
+	require.NoError(t, err)
+
+	assert.True(t, result.Applies)
+	assert.True(t, result.Skip)
+	require.NotNil(t, result.Prior)
+	assert.Equal(t, "2025-03-01", result.Prior.BreakoutDate)
+	assert.Contains(t, result.Reason, "System 1 skip")
+}
+
+func TestEvaluateLastBreakoutFilter_TakesAfterLoser(t *testing.T) {
+	result, err := EvaluateLastBreakoutFilter("AAPL", loserThenBreakout(), nil, 2.0)
+	require.NoError(t, err)
+
+	assert.True(t, result.Applies)
+	assert.False(t, result.Skip)
+	assert.Contains(t, result.Reason, "loser")
+}
+
+func TestEvaluateLastBreakoutFilter_HistoryOverridesReplay(t *testing.T) {
+	// Replay says the last breakout lost, but a later real trade won
+	history := []BreakoutOutcome{{
+		Direction:    SignalLong,
+		BreakoutDate: "2025-03-05",
+		EntryPrice:   99,
+		ExitDate:     "2025-03-07",
+		ExitPrice:    101,
+		PnLPerShare:  2,
+		Winner:       true,
+		Source:       OutcomeSourceHistory,
+	}}
+
+	result, err := EvaluateLastBreakoutFilter("AAPL", loserThenBreakout(), history, 2.0)
+	require.NoError(t, err)
+
+	assert.True(t, result.Skip)
+	assert.Equal(t, OutcomeSourceHistory, result.Prior.Source)
+	assert.Contains(t, result.Reason, "trade history")
+}
+
+func TestEvaluateLastBreakoutFilter_NoSignal(t *testing.T) {
+	result, err := EvaluateLastBreakoutFilter("AAPL", flatBars(30), nil, 2.0)
+	require.NoError(t, err)
+
+	assert.False(t, result.Applies)
+	assert.False(t, result.Skip)
+}
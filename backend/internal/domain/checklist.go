@@ -9,6 +9,7 @@ import (
 // ChecklistRequest contains checklist evaluation input
 type ChecklistRequest struct {
 	Ticker        string `json:"ticker"`
+	Direction     string `json:"direction,omitempty"` // LONG (default) or SHORT; side a breakout must take
 	FromPreset    bool   `json:"from_preset"`
 	TrendPass     bool   `json:"trend_pass"`
 	LiquidityPass bool   `json:"liquidity_pass"`
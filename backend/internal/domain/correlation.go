@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Correlation defaults
+const (
+	DefaultCorrelationLookback  = 60   // Daily returns in the rolling window
+	DefaultCorrelationThreshold = 0.7  // Tickers at or above this move together
+	DefaultClusterHeatCapPct    = 0.02 // Half the default portfolio cap
+)
+
+// ClusterHeatFromSettings reads CorrelationThreshold and ClusterHeatCap_pct,
+// using the defaults for any that were never set
+func ClusterHeatFromSettings(settings map[string]string) (threshold, capPct float64, err error) {
+	threshold, capPct = DefaultCorrelationThreshold, DefaultClusterHeatCapPct
+	if value, ok := settings[string(SettingCorrelationThreshold)]; ok {
+		if threshold, err = strconv.ParseFloat(value, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid CorrelationThreshold setting: %w", err)
+		}
+	}
+	if value, ok := settings[string(SettingClusterHeatCap)]; ok {
+		if capPct, err = strconv.ParseFloat(value, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid ClusterHeatCap_pct setting: %w", err)
+		}
+	}
+	return threshold, capPct, nil
+}
+
+// CorrelationPair is the return correlation of two tickers
+type CorrelationPair struct {
+	A            string  `json:"a"`
+	B            string  `json:"b"`
+	Correlation  float64 `json:"correlation"`
+	Observations int     `json:"observations"` // Daily returns on common dates
+}
+
+// CorrelationMatrix holds the pairwise correlations of a set of tickers
+type CorrelationMatrix struct {
+	Lookback int               `json:"lookback"`
+	Tickers  []string          `json:"tickers"`
+	Pairs    []CorrelationPair `json:"pairs"`
+	Missing  map[string]string `json:"missing,omitempty"` // Ticker -> why it has no correlations
+}
+
+// CorrelationCluster is a group of tickers that move together
+type CorrelationCluster struct {
+	Tickers []string          `json:"tickers"`
+	Links   []CorrelationPair `json:"links"` // Pairs at or above the threshold that joined it
+}
+
+// Contains reports whether ticker is in the cluster
+func (c CorrelationCluster) Contains(ticker string) bool {
+	for _, t := range c.Tickers {
+		if strings.EqualFold(t, ticker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturnCorrelation is the Pearson correlation of two tickers' daily log
+// returns over the last lookback returns they have in common
+//
+// Bars are matched by date, so a holiday in one market drops that day from
+// both. At least half the window must be available.
+func ReturnCorrelation(a, b []Bar, lookback int) (float64, int, error) {
+	if lookback < 2 {
+		return 0, 0, fmt.Errorf("correlation lookback must be at least 2, got %d", lookback)
+	}
+
+	closes := make(map[string]float64, len(b))
+	for _, bar := range b {
+		closes[bar.Date] = bar.Close
+	}
+	var pa, pb []float64
+	for _, bar := range a {
+		if close, ok := closes[bar.Date]; ok && bar.Close > 0 && close > 0 {
+			pa = append(pa, bar.Close)
+			pb = append(pb, close)
+		}
+	}
+	if len(pa) > lookback+1 {
+		pa, pb = pa[len(pa)-lookback-1:], pb[len(pb)-lookback-1:]
+	}
+
+	n := len(pa) - 1
+	if n < lookback/2 || n < 2 {
+		return 0, max(n, 0), fmt.Errorf("only %d common daily returns, need %d", max(n, 0), max(lookback/2, 2))
+	}
+
+	ra, rb := make([]float64, n), make([]float64, n)
+	var meanA, meanB float64
+	for i := 0; i < n; i++ {
+		ra[i] = math.Log(pa[i+1] / pa[i])
+		rb[i] = math.Log(pb[i+1] / pb[i])
+		meanA += ra[i]
+		meanB += rb[i]
+	}
+	meanA /= float64(n)
+	meanB /= float64(n)
+
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		da, db := ra[i]-meanA, rb[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0, n, fmt.Errorf("flat prices, correlation undefined")
+	}
+	return cov / math.Sqrt(varA*varB), n, nil
+}
+
+// CorrelateTickers computes the pairwise return correlations of tickers
+// from the provider's daily bars
+//
+// A ticker without bars, or a pair without enough common history, is
+// recorded in Missing rather than failing the whole matrix: it simply does
+// not join any cluster.
+func CorrelateTickers(provider BarProvider, tickers []string, lookback int) (*CorrelationMatrix, error) {
+	if lookback < 2 {
+		return nil, fmt.Errorf("correlation lookback must be at least 2, got %d", lookback)
+	}
+
+	matrix := &CorrelationMatrix{Lookback: lookback, Pairs: []CorrelationPair{}}
+	seen := map[string]bool{}
+	for _, t := range tickers {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			matrix.Tickers = append(matrix.Tickers, t)
+		}
+	}
+	sort.Strings(matrix.Tickers)
+
+	missing := func(ticker, why string) {
+		if matrix.Missing == nil {
+			matrix.Missing = map[string]string{}
+		}
+		matrix.Missing[ticker] = why
+	}
+
+	bars := map[string][]Bar{}
+	for _, t := range matrix.Tickers {
+		b, err := provider.GetDailyBars(t)
+		if err != nil {
+			missing(t, err.Error())
+			continue
+		}
+		bars[t] = b
+	}
+
+	for i, a := range matrix.Tickers {
+		for _, b := range matrix.Tickers[i+1:] {
+			if bars[a] == nil || bars[b] == nil {
+				continue
+			}
+			corr, n, err := ReturnCorrelation(bars[a], bars[b], lookback)
+			if err != nil {
+				missing(a+"/"+b, err.Error())
+				continue
+			}
+			matrix.Pairs = append(matrix.Pairs, CorrelationPair{A: a, B: b, Correlation: corr, Observations: n})
+		}
+	}
+	return matrix, nil
+}
+
+// Clusters groups the tickers linked by a correlation at or above threshold
+//
+// Linking is transitive (single linkage): if A moves with B and B with C,
+// all three form one cluster even when A and C are less correlated. Only
+// clusters of two or more tickers are returned, sorted by first ticker.
+func (m *CorrelationMatrix) Clusters(threshold float64) []CorrelationCluster {
+	parent := map[string]string{}
+	for _, t := range m.Tickers {
+		parent[t] = t
+	}
+	var find func(string) string
+	find = func(t string) string {
+		if parent[t] != t {
+			parent[t] = find(parent[t])
+		}
+		return parent[t]
+	}
+
+	var links []CorrelationPair
+	for _, p := range m.Pairs {
+		if p.Correlation >= threshold {
+			links = append(links, p)
+			parent[find(p.B)] = find(p.A)
+		}
+	}
+
+	byRoot := map[string]*CorrelationCluster{}
+	for _, p := range links {
+		root := find(p.A)
+		if byRoot[root] == nil {
+			byRoot[root] = &CorrelationCluster{}
+		}
+		byRoot[root].Links = append(byRoot[root].Links, p)
+	}
+	for _, t := range m.Tickers {
+		if c := byRoot[find(t)]; c != nil {
+			c.Tickers = append(c.Tickers, t)
+		}
+	}
+
+	clusters := make([]CorrelationCluster, 0, len(byRoot))
+	for _, c := range byRoot {
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Tickers[0] < clusters[j].Tickers[0] })
+	return clusters
+}
+
+// ClusterHeat is the risk of the new trade's correlated cluster
+type ClusterHeat struct {
+	Tickers     []string          `json:"tickers"`
+	Links       []CorrelationPair `json:"links"`
+	CurrentHeat float64           `json:"current_heat"`
+	NewHeat     float64           `json:"new_heat"`
+	Cap         float64           `json:"cap"`
+	Exceeded    bool              `json:"exceeded"`
+	Overage     float64           `json:"overage"`
+}
+
+// calculateClusterHeat sums the risk of the open positions in AddTicker's
+// cluster and adds the new trade; nil when there is no cluster cap or the
+// ticker is not correlated with anything
+func calculateClusterHeat(req HeatRequest) *ClusterHeat {
+	if req.ClusterHeatCapPct <= 0 || req.AddTicker == "" {
+		return nil
+	}
+	for _, c := range req.Clusters {
+		if !c.Contains(req.AddTicker) {
+			continue
+		}
+		heat := &ClusterHeat{
+			Tickers: c.Tickers,
+			Links:   c.Links,
+			Cap:     req.Equity * req.ClusterHeatCapPct,
+		}
+		for _, pos := range req.OpenPositions {
+			if pos.Status == "Open" && c.Contains(pos.Ticker) {
+				heat.CurrentHeat += math.Max(0, pos.RiskDollars)
+			}
+		}
+		heat.NewHeat = heat.CurrentHeat + req.AddRiskDollars
+		if heat.NewHeat > heat.Cap {
+			heat.Exceeded = true
+			heat.Overage = heat.NewHeat - heat.Cap
+		}
+		return heat
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// barsFromReturns builds daily closes from 100 following the given returns
+func barsFromReturns(returns []float64) []Bar {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	bars := []Bar{{Date: day.Format("2006-01-02"), Close: 100}}
+	for i, r := range returns {
+		close := bars[i].Close * math.Exp(r)
+		bars = append(bars, Bar{Date: day.AddDate(0, 0, i+1).Format("2006-01-02"), Close: close})
+	}
+	return bars
+}
+
+// wave is a deterministic return series; different periods are nearly uncorrelated
+func wave(n int, period float64, scale float64) []float64 {
+	r := make([]float64, n)
+	for i := range r {
+		r[i] = scale * math.Sin(2*math.Pi*float64(i)/period)
+	}
+	return r
+}
+
+func TestReturnCorrelation(t *testing.T) {
+	base := wave(80, 7, 0.01)
+	scaled := make([]float64, len(base))
+	inverse := make([]float64, len(base))
+	for i, r := range base {
+		scaled[i] = 2 * r
+		inverse[i] = -r
+	}
+
+	corr, n, err := ReturnCorrelation(barsFromReturns(base), barsFromReturns(scaled), 60)
+	require.NoError(t, err)
+	assert.Equal(t, 60, n)
+	assert.InDelta(t, 1.0, corr, 1e-9)
+
+	corr, _, err = ReturnCorrelation(barsFromReturns(base), barsFromReturns(inverse), 60)
+	require.NoError(t, err)
+	assert.InDelta(t, -1.0, corr, 1e-9)
+
+	corr, _, err = ReturnCorrelation(barsFromReturns(base), barsFromReturns(wave(80, 13, 0.01)), 60)
+	require.NoError(t, err)
+	assert.Less(t, math.Abs(corr), 0.3)
+}
+
+func TestReturnCorrelation_MatchesDates(t *testing.T) {
+	a := barsFromReturns(wave(80, 7, 0.01))
+	b := append([]Bar{}, a...)
+	b = append(b[:10], b[11:]...) // b misses a day
+
+	corr, n, err := ReturnCorrelation(a, b, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 79, n)
+	assert.InDelta(t, 1.0, corr, 1e-9)
+
+	_, _, err = ReturnCorrelation(a[:20], b[:20], 60)
+	assert.Error(t, err, "too little common history")
+	_, _, err = ReturnCorrelation(a, b, 1)
+	assert.Error(t, err)
+}
+
+func TestCorrelateTickersClusters(t *testing.T) {
+	tech := wave(80, 7, 0.01)
+	nvda := wave(80, 13, 0.004) // Tech plus its own moves
+	for i := range nvda {
+		nvda[i] += 1.5 * tech[i]
+	}
+	provider := mapBarProvider{
+		"AAPL": barsFromReturns(tech),
+		"MSFT": barsFromReturns(tech),
+		"NVDA": barsFromReturns(nvda),
+		"XOM":  barsFromReturns(wave(80, 13, 0.01)),
+		"GLD":  barsFromReturns(wave(80, 13, 0.005)),
+	}
+
+	matrix, err := CorrelateTickers(provider, []string{"msft", "AAPL", "NVDA", "XOM", "GLD", "NOPE", "AAPL"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "GLD", "MSFT", "NOPE", "NVDA", "XOM"}, matrix.Tickers)
+	assert.Len(t, matrix.Pairs, 10)
+	assert.Contains(t, matrix.Missing, "NOPE")
+
+	clusters := matrix.Clusters(DefaultCorrelationThreshold)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, []string{"AAPL", "MSFT", "NVDA"}, clusters[0].Tickers)
+	assert.Equal(t, []string{"GLD", "XOM"}, clusters[1].Tickers)
+	assert.True(t, clusters[0].Contains("nvda"))
+	assert.NotEmpty(t, clusters[0].Links)
+}
+
+func TestCalculateHeat_ClusterCapRejects(t *testing.T) {
+	// AAPL and MSFT sit in different buckets but move together
+	req := HeatRequest{
+		Equity:            10000,
+		HeatCapPct:        0.04,
+		BucketHeatCapPct:  0.015,
+		AddRiskDollars:    75,
+		AddBucket:         "Tech/Comm",
+		AddTicker:         "MSFT",
+		ClusterHeatCapPct: 0.02,
+		Clusters: []CorrelationCluster{
+			{Tickers: []string{"AAPL", "MSFT"}, Links: []CorrelationPair{{A: "AAPL", B: "MSFT", Correlation: 0.85}}},
+		},
+		OpenPositions: []Position{
+			{Ticker: "AAPL", Bucket: "Consumer", RiskDollars: 140, Status: "Open"},
+			{Ticker: "XOM", Bucket: "Energy", RiskDollars: 100, Status: "Open"},
+		},
+	}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.BucketCapExceeded)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, HeatMeasureCluster, result.RejectedMeasure)
+	require.NotNil(t, result.Cluster)
+	assert.Equal(t, 140.0, result.Cluster.CurrentHeat)
+	assert.Equal(t, 215.0, result.Cluster.NewHeat)
+	assert.Equal(t, 200.0, result.Cluster.Cap)
+	assert.Equal(t, 15.0, result.Cluster.Overage)
+	assert.Equal(t, "Correlated cluster [AAPL, MSFT] heat ($215.00) exceeds cap ($200.00) by $15.00", result.RejectionReason)
+
+	// An uncorrelated ticker has no cluster
+	req.AddTicker = "XOM"
+	result, err = CalculateHeat(req)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Nil(t, result.Cluster)
+
+	// No cap, no cluster check
+	req.AddTicker = "MSFT"
+	req.ClusterHeatCapPct = 0
+	result, err = CalculateHeat(req)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	req.ClusterHeatCapPct = 1.5
+	_, err = CalculateHeat(req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Trade directions, shared with breakout signals
+const (
+	DirectionLong  = SignalLong
+	DirectionShort = SignalShort
+)
+
+// ErrInvalidDirection is returned for a direction other than LONG or SHORT
+var ErrInvalidDirection = errors.New("direction must be 'LONG' or 'SHORT'")
+
+// NormalizeDirection parses a direction; empty means LONG
+func NormalizeDirection(s string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", DirectionLong, "BUY":
+		return DirectionLong, nil
+	case DirectionShort, "SELL":
+		return DirectionShort, nil
+	default:
+		return "", fmt.Errorf("%w: got '%s'", ErrInvalidDirection, s)
+	}
+}
+
+// DirectionSign is +1 for longs and -1 for shorts
+func DirectionSign(direction string) float64 {
+	if direction == DirectionShort {
+		return -1
+	}
+	return 1
+}
+
+// StopFor places a stop `distance` away from entry on the losing side:
+// below entry for a long, above it for a short
+func StopFor(direction string, entry, distance float64) float64 {
+	return entry - DirectionSign(direction)*distance
+}
+
+// PnLPerShare is the profit per share of a trade from entry to exit
+func PnLPerShare(direction string, entry, exit float64) float64 {
+	return DirectionSign(direction) * (exit - entry)
+}
+
+// RiskPerShare is the loss per share if a trade from entry is stopped out
+// (negative once the stop locks in a profit)
+func RiskPerShare(direction string, entry, stop float64) float64 {
+	return DirectionSign(direction) * (entry - stop)
+}
+
+// OpenRiskPerShare is the heat a share carries: what it loses if stopped
+// out, and nothing once the stop is at or past entry
+func OpenRiskPerShare(direction string, entry, stop float64) float64 {
+	return math.Max(0, RiskPerShare(direction, entry, stop))
+}
+
+// TighterStop returns whichever stop carries less risk: the higher for a
+// long, the lower for a short (stops only ratchet toward price)
+func TighterStop(direction string, current, proposed float64) float64 {
+	if DirectionSign(direction)*(proposed-current) > 0 {
+		return proposed
+	}
+	return current
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDirection(t *testing.T) {
+	for in, want := range map[string]string{"": DirectionLong, "long": DirectionLong, "BUY": DirectionLong, " short ": DirectionShort, "sell": DirectionShort} {
+		got, err := NormalizeDirection(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := NormalizeDirection("FLAT")
+	assert.ErrorIs(t, err, ErrInvalidDirection)
+}
+
+func TestDirectionMath(t *testing.T) {
+	// Long: stop below entry, profit when price rises
+	assert.Equal(t, 177.0, StopFor(DirectionLong, 180, 3))
+	assert.Equal(t, 5.0, PnLPerShare(DirectionLong, 180, 185))
+	assert.Equal(t, 3.0, RiskPerShare(DirectionLong, 180, 177))
+
+	// Short: stop above entry, profit when price falls
+	assert.Equal(t, 183.0, StopFor(DirectionShort, 180, 3))
+	assert.Equal(t, -5.0, PnLPerShare(DirectionShort, 180, 185))
+	assert.Equal(t, 3.0, RiskPerShare(DirectionShort, 180, 183))
+}
+
+func TestTighterStop(t *testing.T) {
+	assert.Equal(t, 178.0, TighterStop(DirectionLong, 177, 178))
+	assert.Equal(t, 177.0, TighterStop(DirectionLong, 177, 176))
+	assert.Equal(t, 182.0, TighterStop(DirectionShort, 183, 182))
+	assert.Equal(t, 183.0, TighterStop(DirectionShort, 183, 184))
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Equity modes
+const (
+	EquityModeStatic  = "STATIC"  // Equity_E as entered
+	EquityModeTracked = "TRACKED" // StartingEquity plus realized (and marked) P&L
+)
+
+// Turtle drawdown rule defaults: cut sizing equity 20% for every 10% drawdown
+const (
+	DefaultDrawdownStepPct = 0.10
+	DefaultDrawdownCutPct  = 0.20
+)
+
+// EquityEvent is realized P&L booked on one date
+type EquityEvent struct {
+	Date string  `json:"date"` // YYYY-MM-DD
+	PnL  float64 `json:"pnl"`
+}
+
+// EquityPoint is the account after one date's realized P&L
+type EquityPoint struct {
+	Date        string  `json:"date"`
+	Equity      float64 `json:"equity"`
+	Peak        float64 `json:"peak"`
+	DrawdownPct float64 `json:"drawdown_pct"` // From the peak, e.g. 0.12 = 12%
+}
+
+// EquityRequest describes the account to track
+type EquityRequest struct {
+	Starting   float64       `json:"starting"`
+	Realized   []EquityEvent `json:"realized"`   // Any order; several per date are summed
+	Unrealized float64       `json:"unrealized"` // Open positions marked to market (0 = realized only)
+	StepPct    float64       `json:"step_pct"`   // Drawdown per step (0 = no scaling)
+	CutPct     float64       `json:"cut_pct"`    // Sizing equity cut per step
+}
+
+// EquityStatus is the tracked account with its sizing equity
+type EquityStatus struct {
+	Mode        string        `json:"mode"` // STATIC or TRACKED
+	Starting    float64       `json:"starting"`
+	Realized    float64       `json:"realized"`
+	Unrealized  float64       `json:"unrealized"`
+	Raw         float64       `json:"raw"`  // Starting + Realized + Unrealized
+	Peak        float64       `json:"peak"` // Highest equity reached, Raw included
+	DrawdownPct float64       `json:"drawdown_pct"`
+	Steps       int           `json:"steps"`    // Full drawdown steps below the peak
+	Adjusted    float64       `json:"adjusted"` // Equity to size and cap heat with
+	Curve       []EquityPoint `json:"curve,omitempty"`
+}
+
+// StaticEquity is the status of an account whose equity is entered by hand
+func StaticEquity(equity float64) *EquityStatus {
+	return &EquityStatus{
+		Mode:     EquityModeStatic,
+		Starting: equity,
+		Raw:      equity,
+		Peak:     equity,
+		Adjusted: equity,
+	}
+}
+
+// TrackEquity builds the equity curve from realized P&L and applies the
+// Turtle drawdown rule to it
+//
+// Raw equity is Starting plus every realized P&L event, plus Unrealized.
+// The peak is the highest equity at the end of any date (Starting and Raw
+// included). For every full StepPct below the peak, sizing equity is the
+// peak cut by another CutPct (10% down trades as 80% of the peak, 20% down
+// as 64%), and never more than Raw:
+//
+//	Adjusted = min(Raw, Peak × (1 − CutPct)^steps)
+//
+// Example: Starting $100,000, peak $120,000, Raw $105,000 (12.5% drawdown)
+// → 1 step → Adjusted = min($105,000, $96,000) = $96,000
+func TrackEquity(req EquityRequest) (*EquityStatus, error) {
+	if req.Starting <= 0 {
+		return nil, fmt.Errorf("starting equity must be positive, got %.2f", req.Starting)
+	}
+	if req.StepPct < 0 || req.StepPct >= 1 {
+		return nil, fmt.Errorf("drawdown step must be between 0 and 1, got %.4f", req.StepPct)
+	}
+	if req.CutPct < 0 || req.CutPct >= 1 {
+		return nil, fmt.Errorf("drawdown cut must be between 0 and 1, got %.4f", req.CutPct)
+	}
+
+	byDate := map[string]float64{}
+	for _, e := range req.Realized {
+		if e.Date == "" {
+			return nil, fmt.Errorf("realized P&L of %.2f has no date", e.PnL)
+		}
+		byDate[e.Date] += e.PnL
+	}
+	dates := make([]string, 0, len(byDate))
+	for d := range byDate {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	status := &EquityStatus{
+		Mode:       EquityModeTracked,
+		Starting:   req.Starting,
+		Unrealized: req.Unrealized,
+		Curve:      make([]EquityPoint, 0, len(dates)),
+	}
+	equity, peak := req.Starting, req.Starting
+	for _, d := range dates {
+		equity += byDate[d]
+		status.Realized += byDate[d]
+		peak = math.Max(peak, equity)
+		status.Curve = append(status.Curve, EquityPoint{
+			Date:        d,
+			Equity:      equity,
+			Peak:        peak,
+			DrawdownPct: (peak - equity) / peak,
+		})
+	}
+
+	status.Raw = equity + req.Unrealized
+	status.Peak = math.Max(peak, status.Raw)
+	if status.Raw <= 0 {
+		return nil, fmt.Errorf("account equity is gone ($%.2f); reset StartingEquity", status.Raw)
+	}
+	status.DrawdownPct = (status.Peak - status.Raw) / status.Peak
+	status.Adjusted, status.Steps = DrawdownAdjustedEquity(status.Raw, status.Peak, req.StepPct, req.CutPct)
+	return status, nil
+}
+
+// DrawdownAdjustedEquity applies the drawdown rule to raw equity below a peak
+func DrawdownAdjustedEquity(raw, peak, stepPct, cutPct float64) (float64, int) {
+	if stepPct <= 0 || peak <= 0 || raw >= peak {
+		return raw, 0
+	}
+	// Round so an exact 10.0% drawdown is a full step despite float error
+	steps := int(math.Floor(math.Round((peak-raw)/peak/stepPct*1e9) / 1e9))
+	if steps == 0 {
+		return raw, 0
+	}
+	return math.Min(raw, peak*math.Pow(1-cutPct, float64(steps))), steps
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackEquity_CurveAndPeak(t *testing.T) {
+	status, err := TrackEquity(EquityRequest{
+		Starting: 100000,
+		Realized: []EquityEvent{
+			{Date: "2025-03-03", PnL: -15000},
+			{Date: "2025-02-03", PnL: 12000},
+			{Date: "2025-02-03", PnL: 8000},
+		},
+		StepPct: DefaultDrawdownStepPct,
+		CutPct:  DefaultDrawdownCutPct,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, EquityModeTracked, status.Mode)
+	require.Len(t, status.Curve, 2)
+	assert.Equal(t, EquityPoint{Date: "2025-02-03", Equity: 120000, Peak: 120000}, status.Curve[0])
+	assert.Equal(t, "2025-03-03", status.Curve[1].Date)
+	assert.InDelta(t, 0.125, status.Curve[1].DrawdownPct, 1e-9)
+
+	assert.Equal(t, 5000.0, status.Realized)
+	assert.Equal(t, 105000.0, status.Raw)
+	assert.Equal(t, 120000.0, status.Peak)
+	assert.Equal(t, 1, status.Steps)
+	assert.InDelta(t, 96000, status.Adjusted, 0.01)
+}
+
+func TestTrackEquity_Unrealized(t *testing.T) {
+	status, err := TrackEquity(EquityRequest{
+		Starting:   100000,
+		Unrealized: 5000,
+		StepPct:    DefaultDrawdownStepPct,
+		CutPct:     DefaultDrawdownCutPct,
+	})
+	require.NoError(t, err)
+
+	// An open gain lifts the peak as well as the raw equity
+	assert.Equal(t, 105000.0, status.Raw)
+	assert.Equal(t, 105000.0, status.Peak)
+	assert.Equal(t, 105000.0, status.Adjusted)
+	assert.Empty(t, status.Curve)
+}
+
+func TestTrackEquity_Errors(t *testing.T) {
+	_, err := TrackEquity(EquityRequest{Starting: 0})
+	assert.Error(t, err)
+
+	_, err = TrackEquity(EquityRequest{Starting: 100000, StepPct: 1})
+	assert.Error(t, err)
+
+	_, err = TrackEquity(EquityRequest{Starting: 100000, Realized: []EquityEvent{{PnL: 100}}})
+	assert.Error(t, err)
+
+	_, err = TrackEquity(EquityRequest{Starting: 10000, Realized: []EquityEvent{{Date: "2025-01-02", PnL: -10000}}})
+	assert.Error(t, err)
+}
+
+func TestDrawdownAdjustedEquity(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      float64
+		wantEq   float64
+		wantStep int
+	}{
+		{"at peak", 100000, 100000, 0},
+		{"under one step", 95000, 95000, 0},
+		{"exactly 10%", 90000, 80000, 1},
+		{"15% down", 85000, 80000, 1},
+		{"exactly 20%", 80000, 64000, 2},
+		{"35% down", 65000, 51200, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, steps := DrawdownAdjustedEquity(tt.raw, 100000, 0.10, 0.20)
+			assert.InDelta(t, tt.wantEq, got, 0.01)
+			assert.Equal(t, tt.wantStep, steps)
+		})
+	}
+
+	// A zero step turns the rule off
+	got, steps := DrawdownAdjustedEquity(70000, 100000, 0, 0.20)
+	assert.Equal(t, 70000.0, got)
+	assert.Equal(t, 0, steps)
+}
+
+func TestStaticEquity(t *testing.T) {
+	status := StaticEquity(25000)
+	assert.Equal(t, EquityModeStatic, status.Mode)
+	assert.Equal(t, 25000.0, status.Raw)
+	assert.Equal(t, 25000.0, status.Peak)
+	assert.Equal(t, 25000.0, status.Adjusted)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import "math"
+
+// ExcursionRequest describes a position and the price range it has traded in
+type ExcursionRequest struct {
+	Direction   string  `json:"direction"`    // LONG or SHORT
+	EntryPrice  float64 `json:"entry_price"`  // Average entry
+	Low         float64 `json:"low"`          // Lowest price seen since entry
+	High        float64 `json:"high"`         // Highest price seen since entry
+	Shares      int     `json:"shares"`       // Shares filled
+	ATR         float64 `json:"atr_n"`        // N at entry (0 = no N units)
+	InitialRisk float64 `json:"initial_risk"` // 1R in dollars (0 = no R units)
+}
+
+// Excursion is how far a position went against (MAE) and in favour of
+// (MFE) its entry, per share and in dollars, N and R
+type Excursion struct {
+	MAE        float64 `json:"mae"` // Per share, >= 0
+	MFE        float64 `json:"mfe"` // Per share, >= 0
+	MAEDollars float64 `json:"mae_dollars"`
+	MFEDollars float64 `json:"mfe_dollars"`
+	MAEN       float64 `json:"mae_n,omitempty"` // MAE / N: compare with StopMultiple_K
+	MFEN       float64 `json:"mfe_n,omitempty"`
+	MAER       float64 `json:"mae_r,omitempty"` // MAE dollars / initial risk
+	MFER       float64 `json:"mfe_r,omitempty"`
+}
+
+// CalculateExcursion measures MAE and MFE from the range a position traded in
+//
+// For a long the adverse side is the low and the favourable side the high;
+// a short is the reverse. A range that never crossed entry has zero
+// excursion on that side.
+func CalculateExcursion(req ExcursionRequest) Excursion {
+	adverse, favorable := req.Low, req.High
+	if req.Direction == DirectionShort {
+		adverse, favorable = req.High, req.Low
+	}
+	sign := DirectionSign(req.Direction)
+
+	ex := Excursion{
+		MAE: math.Max(0, sign*(req.EntryPrice-adverse)),
+		MFE: math.Max(0, sign*(favorable-req.EntryPrice)),
+	}
+	ex.MAEDollars = ex.MAE * float64(req.Shares)
+	ex.MFEDollars = ex.MFE * float64(req.Shares)
+	if req.ATR > 0 {
+		ex.MAEN = ex.MAE / req.ATR
+		ex.MFEN = ex.MFE / req.ATR
+	}
+	if req.InitialRisk > 0 {
+		ex.MAER = ex.MAEDollars / req.InitialRisk
+		ex.MFER = ex.MFEDollars / req.InitialRisk
+	}
+	return ex
+}
+
+// ExtendPriceRange widens low/high with the bars dated from..through
+// (inclusive, YYYY-MM-DD; empty bounds are open) and returns the date of the
+// last bar used. A zero low or high is treated as not yet set.
+func ExtendPriceRange(low, high float64, bars []Bar, from, through string) (float64, float64, string) {
+	last := ""
+	for _, b := range bars {
+		if (from != "" && b.Date < from) || (through != "" && b.Date > through) {
+			continue
+		}
+		if b.Low > 0 && (low == 0 || b.Low < low) {
+			low = b.Low
+		}
+		if b.High > 0 && b.High > high {
+			high = b.High
+		}
+		if b.Date > last {
+			last = b.Date
+		}
+	}
+	return low, high, last
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateExcursion_Long(t *testing.T) {
+	// Entry 100, 2N stop at 96 on 50 shares: 1R = $200
+	ex := CalculateExcursion(ExcursionRequest{
+		Direction:   DirectionLong,
+		EntryPrice:  100,
+		Low:         97,
+		High:        112,
+		Shares:      50,
+		ATR:         2,
+		InitialRisk: 200,
+	})
+
+	assert.InDelta(t, 3, ex.MAE, 1e-9)
+	assert.InDelta(t, 12, ex.MFE, 1e-9)
+	assert.InDelta(t, 150, ex.MAEDollars, 1e-9)
+	assert.InDelta(t, 1.5, ex.MAEN, 1e-9)
+	assert.InDelta(t, 6, ex.MFEN, 1e-9)
+	assert.InDelta(t, 0.75, ex.MAER, 1e-9)
+	assert.InDelta(t, 3, ex.MFER, 1e-9)
+}
+
+func TestCalculateExcursion_Short(t *testing.T) {
+	ex := CalculateExcursion(ExcursionRequest{
+		Direction:  DirectionShort,
+		EntryPrice: 50,
+		Low:        44,
+		High:       51,
+		Shares:     10,
+	})
+
+	assert.InDelta(t, 1, ex.MAE, 1e-9)
+	assert.InDelta(t, 6, ex.MFE, 1e-9)
+	assert.InDelta(t, 60, ex.MFEDollars, 1e-9)
+	assert.Zero(t, ex.MAEN) // No N recorded
+	assert.Zero(t, ex.MAER) // No 1R recorded
+}
+
+func TestCalculateExcursion_NeverCrossedEntry(t *testing.T) {
+	ex := CalculateExcursion(ExcursionRequest{Direction: DirectionLong, EntryPrice: 100, Low: 101, High: 105, Shares: 1})
+
+	assert.Zero(t, ex.MAE)
+	assert.InDelta(t, 5, ex.MFE, 1e-9)
+}
+
+func TestExtendPriceRange(t *testing.T) {
+	bars := []Bar{
+		{Date: "2025-01-02", Low: 90, High: 99},
+		{Date: "2025-01-03", Low: 97, High: 104},
+		{Date: "2025-01-06", Low: 95, High: 108},
+		{Date: "2025-01-07", Low: 80, High: 120},
+	}
+
+	low, high, last := ExtendPriceRange(0, 0, bars, "2025-01-03", "2025-01-06")
+	assert.Equal(t, 95.0, low)
+	assert.Equal(t, 108.0, high)
+	assert.Equal(t, "2025-01-06", last)
+
+	// An existing range (from a manual mark) only widens
+	low, high, _ = ExtendPriceRange(94, 106, bars, "2025-01-03", "2025-01-06")
+	assert.Equal(t, 94.0, low)
+	assert.Equal(t, 108.0, high)
+
+	_, _, last = ExtendPriceRange(94, 106, bars, "2025-02-01", "")
+	assert.Empty(t, last)
+}
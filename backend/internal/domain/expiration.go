@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+// ExpirationRequest describes an option position to settle at its expiration
+type ExpirationRequest struct {
+	Ticker     string        `json:"ticker"`
+	Legs       []options.Leg `json:"legs"`                 // Prices are the entry fills
+	Expiration string        `json:"expiration,omitempty"` // Default: the legs' nearest expiration
+	Settlement float64       `json:"settlement"`           // Underlying's settlement price
+	Stop       float64       `json:"stop,omitempty"`       // Stop for any stock the legs turn into
+}
+
+// ExpirationResult is an option position settled at expiration
+//
+// When the exercised and assigned legs (with any stock legs) leave a net
+// share count, those shares become a stock position entered at the
+// settlement price.
+type ExpirationResult struct {
+	Ticker      string                  `json:"ticker"`
+	Expiration  string                  `json:"expiration"`
+	Settlement  float64                 `json:"settlement"`
+	Legs        []options.LegSettlement `json:"legs"`
+	RealizedPnL float64                 `json:"realized_pnl"` // Whole position, marked at the settlement price
+
+	Shares         int     `json:"shares"`                    // Stock left after settlement (0 = none)
+	StockDirection string  `json:"stock_direction,omitempty"` // LONG or SHORT
+	StockEntry     float64 `json:"stock_entry,omitempty"`     // The settlement price
+	StockStop      float64 `json:"stock_stop,omitempty"`
+	StockRisk      float64 `json:"stock_risk,omitempty"`
+	CostBasis      float64 `json:"cost_basis,omitempty"`     // Net cash paid for the shares (negative = received)
+	CostPerShare   float64 `json:"cost_per_share,omitempty"` // Strike (or stock entry) adjusted for premium
+
+	Detail string `json:"detail"`
+}
+
+// SettleExpiration settles every leg of an option position at expiration
+//
+// Legs in the money are exercised or assigned at their strike, the rest
+// expire worthless (see options.SettleLegs), and stock legs stay stock.
+// The position closes with its P&L marked at the settlement price: the
+// premium of every leg plus the stock delivered at the strikes, valued at
+// Settlement. Net shares left over carry on as a stock position entered at
+// Settlement with Stop, so its own P&L starts from there; CostBasis keeps
+// what the shares actually cost (an assigned short put at 95 sold for 2.00
+// is 93 a share).
+//
+// Legs expiring after Expiration must be closed or rolled first.
+func SettleExpiration(req ExpirationRequest) (*ExpirationResult, error) {
+	exp := req.Expiration
+	if exp == "" {
+		exp = options.NearestExpiration(req.Legs)
+	}
+	if exp == "" {
+		return nil, fmt.Errorf("%s: position has no option legs to settle", req.Ticker)
+	}
+
+	var later []string
+	for _, leg := range req.Legs {
+		if leg.Type != options.Stock && leg.Exp > exp {
+			later = append(later, fmt.Sprintf("%s %.2f %s", leg.Type, leg.Strike, leg.Exp))
+		}
+	}
+	if len(later) > 0 {
+		return nil, fmt.Errorf("%s: legs expiring after %s are still open (%s); close or roll them first",
+			req.Ticker, exp, strings.Join(later, ", "))
+	}
+
+	settled, err := options.SettleLegs(req.Legs, exp, req.Settlement)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", req.Ticker, err)
+	}
+
+	result := &ExpirationResult{
+		Ticker:     req.Ticker,
+		Expiration: exp,
+		Settlement: req.Settlement,
+		Legs:       settled,
+	}
+
+	// Cash in and out: premiums, stock traded at the strikes, stock legs at cost
+	cash := 0.0
+	for _, s := range settled {
+		sign, _ := options.ActionSign(s.Leg.Action) // Validated by SettleLegs
+		cash -= sign * s.Leg.Price * float64(s.Leg.Qty) * options.ContractMultiplier
+		cash -= float64(s.Shares) * s.Leg.Strike
+		result.Shares += s.Shares
+	}
+	for _, leg := range req.Legs {
+		if leg.Type != options.Stock {
+			continue
+		}
+		sign, err := options.ActionSign(leg.Action)
+		if err != nil {
+			return nil, err
+		}
+		shares := int(sign) * leg.Qty
+		cash -= float64(shares) * leg.Price
+		result.Shares += shares
+	}
+
+	result.RealizedPnL = math.Round((cash+float64(result.Shares)*req.Settlement)*100) / 100
+	if result.Shares == 0 {
+		result.Detail = fmt.Sprintf("settled at $%.2f on %s: %s, realized $%.2f",
+			req.Settlement, exp, describeSettlement(settled), result.RealizedPnL)
+		return result, nil
+	}
+
+	result.StockDirection = DirectionLong
+	if result.Shares < 0 {
+		result.StockDirection = DirectionShort
+	}
+	shares := math.Abs(float64(result.Shares))
+	result.StockEntry = req.Settlement
+	result.CostBasis = -cash
+	result.CostPerShare = result.CostBasis / float64(result.Shares)
+
+	// The stop must still protect the new shares
+	risk := (req.Settlement - req.Stop) * shares
+	if result.StockDirection == DirectionShort {
+		risk = (req.Stop - req.Settlement) * shares
+	}
+	if req.Stop <= 0 || risk <= 0 {
+		return nil, fmt.Errorf("%s: stop $%.2f does not protect the %d %s shares at $%.2f; give a stop for them",
+			req.Ticker, req.Stop, int(shares), strings.ToLower(result.StockDirection), req.Settlement)
+	}
+	result.StockStop = req.Stop
+	result.StockRisk = risk
+
+	result.Detail = fmt.Sprintf("settled at $%.2f on %s: %s, realized $%.2f; %s %d shares at $%.2f (cost $%.2f a share)",
+		req.Settlement, exp, describeSettlement(settled), result.RealizedPnL,
+		strings.ToLower(result.StockDirection), int(shares), req.Settlement, result.CostPerShare)
+	return result, nil
+}
+
+// describeSettlement lists each leg's outcome, e.g. "SELL PUT 95.00 ASSIGNED, BUY PUT 90.00 EXPIRED"
+func describeSettlement(settled []options.LegSettlement) string {
+	parts := make([]string, len(settled))
+	for i, s := range settled {
+		parts[i] = fmt.Sprintf("%s %s %.2f %s", s.Leg.Action, s.Leg.Type, s.Leg.Strike, s.Outcome)
+	}
+	return strings.Join(parts, ", ")
+}
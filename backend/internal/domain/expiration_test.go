@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+func TestSettleExpiration_CashSecuredPutAssigned(t *testing.T) {
+	result, err := SettleExpiration(ExpirationRequest{
+		Ticker:     "AAPL",
+		Legs:       []options.Leg{{Type: "PUT", Strike: 95, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.00}},
+		Settlement: 90,
+		Stop:       85,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Legs, 1)
+	assert.Equal(t, options.SettleAssigned, result.Legs[0].Outcome)
+	assert.InDelta(t, -300, result.RealizedPnL, 1e-9) // Sold for 2.00, worth 5.00
+
+	assert.Equal(t, 100, result.Shares)
+	assert.Equal(t, DirectionLong, result.StockDirection)
+	assert.InDelta(t, 90, result.StockEntry, 1e-9)
+	assert.InDelta(t, 500, result.StockRisk, 1e-9)
+	assert.InDelta(t, 9300, result.CostBasis, 1e-9)
+	assert.InDelta(t, 93, result.CostPerShare, 1e-9) // Strike less the premium
+}
+
+func TestSettleExpiration_CoveredCallCalledAway(t *testing.T) {
+	legs := []options.Leg{
+		{Type: options.Stock, Qty: 100, Action: "BUY", Price: 100},
+		{Type: "CALL", Strike: 105, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.00},
+	}
+
+	result, err := SettleExpiration(ExpirationRequest{Ticker: "AAPL", Legs: legs, Settlement: 108})
+	require.NoError(t, err)
+	assert.Zero(t, result.Shares)
+	assert.Empty(t, result.StockDirection)
+	assert.InDelta(t, 700, result.RealizedPnL, 1e-9) // 5 on the stock + 2 premium
+
+	// Below the strike the call expires and the shares stay, now entered at 103
+	result, err = SettleExpiration(ExpirationRequest{Ticker: "AAPL", Legs: legs, Settlement: 103, Stop: 97})
+	require.NoError(t, err)
+	assert.Equal(t, options.SettleExpired, result.Legs[0].Outcome)
+	assert.Equal(t, 100, result.Shares)
+	assert.InDelta(t, 500, result.RealizedPnL, 1e-9)
+	assert.InDelta(t, 98, result.CostPerShare, 1e-9)
+	assert.InDelta(t, 600, result.StockRisk, 1e-9)
+}
+
+func TestSettleExpiration_SpreadThroughBothStrikes(t *testing.T) {
+	result, err := SettleExpiration(ExpirationRequest{
+		Ticker: "AAPL",
+		Legs: []options.Leg{
+			{Type: "PUT", Strike: 95, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.00},
+			{Type: "PUT", Strike: 90, Exp: "2025-01-17", Qty: 1, Action: "BUY", Price: 0.80},
+		},
+		Settlement: 88,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, options.SettleAssigned, result.Legs[0].Outcome)
+	assert.Equal(t, options.SettleExercised, result.Legs[1].Outcome)
+	assert.Zero(t, result.Shares) // Bought at 95, sold at 90
+	assert.InDelta(t, -380, result.RealizedPnL, 1e-9)
+}
+
+func TestSettleExpiration_ShortStockFromCall(t *testing.T) {
+	result, err := SettleExpiration(ExpirationRequest{
+		Ticker:     "TSLA",
+		Legs:       []options.Leg{{Type: "CALL", Strike: 200, Exp: "2025-01-17", Qty: 2, Action: "SELL", Price: 6.00}},
+		Settlement: 204,
+		Stop:       215,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, -200, result.Shares)
+	assert.Equal(t, DirectionShort, result.StockDirection)
+	assert.InDelta(t, 400, result.RealizedPnL, 1e-9)
+	assert.InDelta(t, -41200, result.CostBasis, 1e-9) // Received 200 × 200 + 1200 premium
+	assert.InDelta(t, 206, result.CostPerShare, 1e-9)
+	assert.InDelta(t, 2200, result.StockRisk, 1e-9)
+}
+
+func TestSettleExpiration_Errors(t *testing.T) {
+	put := options.Leg{Type: "PUT", Strike: 95, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.00}
+
+	// Stop through the settlement price
+	_, err := SettleExpiration(ExpirationRequest{Ticker: "AAPL", Legs: []options.Leg{put}, Settlement: 90, Stop: 92})
+	assert.Error(t, err)
+
+	// A calendar's far leg is still open
+	far := options.Leg{Type: "PUT", Strike: 95, Exp: "2025-02-21", Qty: 1, Action: "BUY", Price: 3.00}
+	_, err = SettleExpiration(ExpirationRequest{Ticker: "AAPL", Legs: []options.Leg{put, far}, Settlement: 100})
+	assert.Error(t, err)
+
+	_, err = SettleExpiration(ExpirationRequest{Ticker: "AAPL", Legs: []options.Leg{put}})
+	assert.Error(t, err)
+	_, err = SettleExpiration(ExpirationRequest{Ticker: "AAPL"})
+	assert.Error(t, err)
+}
@@ -5,7 +5,7 @@ import (
 	"time"
 )
 
-// HardGatesResult represents the result of checking all 5 hard gates
+// HardGatesResult represents the result of checking all 7 hard gates
 type HardGatesResult struct {
 	AllPassed      bool     `json:"all_passed"`
 	FailedGates    []string `json:"failed_gates,omitempty"`
@@ -18,18 +18,30 @@ type GateChecker interface {
 	CheckTickerInCandidates(ticker, date string) error
 	CheckImpulseBrake(ticker string) error
 	CheckBucketCooldown(bucket string) error
-	CheckHeatCaps(addRisk float64, bucket string) error
+	CheckHeatCaps(ticker string, addRisk float64, bucket string) error
+	CheckLastBreakoutFilter(ticker, date string) error
+	CheckUnitLimits(ticker, bucket string) error
 }
 
-// ValidateHardGates checks all 5 hard gates for a GO decision
+// ValidateHardGates checks all 7 hard gates for a GO decision
 // This is the core discipline enforcement mechanism
 //
-// The 5 Hard Gates:
+// The 7 Hard Gates:
 //  1. Banner GREEN (all 6 checklist items satisfied)
 //  2. Ticker in today's candidates (from FINVIZ screen)
 //  3. 2-minute impulse brake expired
 //  4. Bucket not in cooldown (24hr after loss)
-//  5. Heat caps not exceeded (4% portfolio, 1.5% bucket)
+//  5. Heat caps not exceeded (4% portfolio, 1.5% bucket, correlated cluster)
+//  6. System 1 last-breakout skip filter
+//  7. Turtle unit limits
+//
+// Gate 6 is the Turtle "last breakout was a winner" filter; checkers return
+// nil for systems other than System 1. The skip reasoning is reported in
+// FailureReasons like any other gate.
+//
+// Gate 7 caps units per ticker, correlated cluster, bucket and direction,
+// so a trade that fits the dollar heat caps is still blocked when it would
+// break one (see CheckUnitLimits).
 //
 // All gates must pass for a GO decision to be saved.
 func ValidateHardGates(checker GateChecker, ticker, bucket string, riskDollars float64, date string) (*HardGatesResult, error) {
@@ -70,28 +82,43 @@ func ValidateHardGates(checker GateChecker, ticker, bucket string, riskDollars f
 	}
 
 	// Gate 5: Heat caps
-	if err := checker.CheckHeatCaps(riskDollars, bucket); err != nil {
+	if err := checker.CheckHeatCaps(ticker, riskDollars, bucket); err != nil {
 		result.AllPassed = false
 		result.FailedGates = append(result.FailedGates, "HeatCaps")
 		result.FailureReasons = append(result.FailureReasons, err.Error())
 	}
 
+	// Gate 6: System 1 last-breakout skip filter
+	if err := checker.CheckLastBreakoutFilter(ticker, date); err != nil {
+		result.AllPassed = false
+		result.FailedGates = append(result.FailedGates, "LastBreakoutFilter")
+		result.FailureReasons = append(result.FailureReasons, err.Error())
+	}
+
+	// Gate 7: Turtle unit limits
+	if err := checker.CheckUnitLimits(ticker, bucket); err != nil {
+		result.AllPassed = false
+		result.FailedGates = append(result.FailedGates, "UnitLimits")
+		result.FailureReasons = append(result.FailureReasons, err.Error())
+	}
+
 	return result, nil
 }
 
 // SaveDecisionRequest represents a request to save a trading decision
 type SaveDecisionRequest struct {
-	Ticker   string
-	Action   string  // GO or NO-GO
-	Entry    float64 // Required for GO
-	ATR      float64 // Required for GO (stock or opt-delta-atr)
-	Method   string  // "stock", "opt-delta-atr", "opt-maxloss"
-	Delta    float64 // For opt-delta-atr
-	MaxLoss  float64 // For opt-maxloss
-	Bucket   string
-	Reason   string // Required for NO-GO
-	Date     string // Defaults to today
-	CorrID   string
+	Ticker    string
+	Action    string  // GO or NO-GO
+	Direction string  // LONG (default) or SHORT
+	Entry     float64 // Required for GO
+	ATR       float64 // Required for GO (stock or opt-delta-atr)
+	Method    string  // "stock", "opt-delta-atr", "opt-maxloss"
+	Delta     float64 // For opt-delta-atr
+	MaxLoss   float64 // For opt-maxloss
+	Bucket    string
+	Reason    string // Required for NO-GO
+	Date      string // Defaults to today
+	CorrID    string
 }
 
 // SaveDecisionResult represents the result of saving a decision
@@ -120,6 +147,10 @@ func ValidateSaveDecisionRequest(req SaveDecisionRequest) error {
 		return fmt.Errorf("action must be GO or NO-GO, got: %s", req.Action)
 	}
 
+	if _, err := NormalizeDirection(req.Direction); err != nil {
+		return err
+	}
+
 	if req.Action == "GO" {
 		if req.Entry <= 0 {
 			return fmt.Errorf("entry price must be positive for GO decision")
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -153,6 +154,8 @@ type MockGateChecker struct {
 	ImpulseError       error
 	CooldownError      error
 	HeatError          error
+	LastBreakoutError  error
+	UnitLimitsError    error
 }
 
 func (m *MockGateChecker) CheckBannerGreen(ticker string) error {
@@ -171,10 +174,18 @@ func (m *MockGateChecker) CheckBucketCooldown(bucket string) error {
 	return m.CooldownError
 }
 
-func (m *MockGateChecker) CheckHeatCaps(addRisk float64, bucket string) error {
+func (m *MockGateChecker) CheckHeatCaps(ticker string, addRisk float64, bucket string) error {
 	return m.HeatError
 }
 
+func (m *MockGateChecker) CheckLastBreakoutFilter(ticker, date string) error {
+	return m.LastBreakoutError
+}
+
+func (m *MockGateChecker) CheckUnitLimits(ticker, bucket string) error {
+	return m.UnitLimitsError
+}
+
 func TestValidateHardGates_AllPass(t *testing.T) {
 	checker := &MockGateChecker{}
 	result, err := ValidateHardGates(checker, "AAPL", "Tech/Comm", 75.0, "2025-10-27")
@@ -223,3 +234,27 @@ func TestValidateHardGates_HeatCapsFail(t *testing.T) {
 	assert.False(t, result.AllPassed)
 	assert.Contains(t, result.FailedGates, "HeatCaps")
 }
+
+func TestValidateHardGates_LastBreakoutFilterFails(t *testing.T) {
+	checker := &MockGateChecker{
+		LastBreakoutError: errors.New("System 1 skip: previous LONG breakout was a winner"),
+	}
+	result, err := ValidateHardGates(checker, "AAPL", "Tech/Comm", 75.0, "2025-10-27")
+
+	assert.NoError(t, err)
+	assert.False(t, result.AllPassed)
+	assert.Equal(t, []string{"LastBreakoutFilter"}, result.FailedGates)
+	assert.Contains(t, result.FailureReasons[0], "System 1 skip")
+}
+
+func TestValidateHardGates_UnitLimitsFail(t *testing.T) {
+	checker := &MockGateChecker{
+		UnitLimitsError: errors.New("Unit limit: all LONG positions would hold 13 units (limit 12)"),
+	}
+	result, err := ValidateHardGates(checker, "AAPL", "Tech/Comm", 75.0, "2025-10-27")
+
+	assert.NoError(t, err)
+	assert.False(t, result.AllPassed)
+	assert.Equal(t, []string{"UnitLimits"}, result.FailedGates)
+	assert.Contains(t, result.FailureReasons[0], "Unit limit")
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"fmt"
+	"math"
+	"strings"
 )
 
 // HeatRequest contains input for heat calculation
@@ -12,15 +14,26 @@ type HeatRequest struct {
 	AddRiskDollars   float64    `json:"add_risk_dollars"` // New trade risk
 	AddBucket        string     `json:"add_bucket"`        // Bucket for new trade
 	OpenPositions    []Position `json:"open_positions"`
+
+	// GREEKS mode: exposure caps on top of the dollar caps
+	Mode        string         `json:"mode,omitempty"` // DOLLAR (default) or GREEKS
+	GreeksCaps  GreeksHeatCaps `json:"greeks_caps"`
+	AddExposure Exposure       `json:"add_exposure"` // New trade exposure
+
+	// Correlated-cluster cap: risk across tickers that move together
+	AddTicker         string               `json:"add_ticker,omitempty"`           // Ticker of the new trade
+	ClusterHeatCapPct float64              `json:"cluster_heat_cap_pct,omitempty"` // 0 = no cluster cap
+	Clusters          []CorrelationCluster `json:"clusters,omitempty"`             // See CorrelationMatrix.Clusters
 }
 
 // Position represents an open trading position
 type Position struct {
-	Ticker      string  `json:"ticker"`
-	Bucket      string  `json:"bucket"`
-	RiskDollars float64 `json:"risk_dollars"`
-	UnitsOpen   int     `json:"units_open"`
-	Status      string  `json:"status"` // "Open", "Closed"
+	Ticker      string   `json:"ticker"`
+	Bucket      string   `json:"bucket"`
+	RiskDollars float64  `json:"risk_dollars"`
+	UnitsOpen   int      `json:"units_open"`
+	Status      string   `json:"status"`   // "Open", "Closed"
+	Exposure    Exposure `json:"exposure"` // Greeks in dollars (GREEKS mode)
 }
 
 // HeatResult contains heat calculation output
@@ -41,9 +54,17 @@ type HeatResult struct {
 	BucketCapExceeded bool    `json:"bucket_cap_exceeded"`
 	BucketOverage     float64 `json:"bucket_overage"`
 
+	// Correlated cluster of the new trade's ticker (nil = none)
+	Cluster *ClusterHeat `json:"cluster,omitempty"`
+
+	// Exposure (GREEKS mode)
+	Mode   string      `json:"mode"`
+	Greeks *GreeksHeat `json:"greeks,omitempty"`
+
 	// Decision
 	Allowed         bool   `json:"allowed"`
 	RejectionReason string `json:"rejection_reason,omitempty"`
+	RejectedMeasure string `json:"rejected_measure,omitempty"` // RISK, CLUSTER, DELTA, GAMMA or VEGA
 }
 
 // CalculateHeat computes portfolio and bucket heat
@@ -55,6 +76,17 @@ type HeatResult struct {
 //   - Bucket cap = Equity × BucketHeatCapPct (1.5% = $150 for $10k account)
 //   - Any trade exceeding either cap is rejected
 //
+// With ClusterHeatCapPct set, the risk of every open position whose ticker
+// is in the same correlated cluster as AddTicker is capped as well, whatever
+// bucket it was tagged with (the Turtle limit on closely correlated
+// markets; see calculateClusterHeat).
+//
+// In GREEKS mode the net delta, gamma and vega dollars of the positions'
+// Exposure are also checked against GreeksCaps, for the portfolio and the
+// new trade's bucket (see calculateGreeksHeat). RejectedMeasure names the
+// measure that caused a rejection; the dollar caps are checked first, then
+// the cluster cap.
+//
 // Example:
 //   Given: Equity=$10,000, PortfolioHeat=$350, TechBucketHeat=$125, NewTrade=$75
 //   NewPortfolioHeat = $350 + $75 = $425 > $400 (cap) → REJECT
@@ -72,6 +104,16 @@ func CalculateHeat(req HeatRequest) (*HeatResult, error) {
 	if req.AddRiskDollars < 0 {
 		return nil, fmt.Errorf("add_risk_dollars must be non-negative, got %.2f", req.AddRiskDollars)
 	}
+	if req.ClusterHeatCapPct < 0 || req.ClusterHeatCapPct > 1 {
+		return nil, fmt.Errorf("cluster_heat_cap_pct must be between 0 and 1, got %.4f", req.ClusterHeatCapPct)
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = HeatModeDollar
+	}
+	if mode != HeatModeDollar && mode != HeatModeGreeks {
+		return nil, fmt.Errorf("heat mode must be %s or %s, got '%s'", HeatModeDollar, HeatModeGreeks, req.Mode)
+	}
 
 	// Calculate caps
 	portfolioCap := req.Equity * req.HeatCapPct
@@ -81,7 +123,7 @@ func CalculateHeat(req HeatRequest) (*HeatResult, error) {
 	currentPortfolioHeat := 0.0
 	for _, pos := range req.OpenPositions {
 		if pos.Status == "Open" {
-			currentPortfolioHeat += pos.RiskDollars
+			currentPortfolioHeat += math.Max(0, pos.RiskDollars)
 		}
 	}
 
@@ -89,7 +131,7 @@ func CalculateHeat(req HeatRequest) (*HeatResult, error) {
 	currentBucketHeat := 0.0
 	for _, pos := range req.OpenPositions {
 		if pos.Status == "Open" && pos.Bucket == req.AddBucket {
-			currentBucketHeat += pos.RiskDollars
+			currentBucketHeat += math.Max(0, pos.RiskDollars)
 		}
 	}
 
@@ -118,6 +160,10 @@ func CalculateHeat(req HeatRequest) (*HeatResult, error) {
 	// Determine if trade is allowed
 	allowed := !portfolioCapExceeded && !bucketCapExceeded
 	rejectionReason := ""
+	rejectedMeasure := ""
+	if !allowed {
+		rejectedMeasure = HeatMeasureRisk
+	}
 
 	if portfolioCapExceeded {
 		rejectionReason = fmt.Sprintf(
@@ -131,6 +177,36 @@ func CalculateHeat(req HeatRequest) (*HeatResult, error) {
 		)
 	}
 
+	cluster := calculateClusterHeat(req)
+	if allowed && cluster != nil && cluster.Exceeded {
+		allowed = false
+		rejectedMeasure = HeatMeasureCluster
+		rejectionReason = fmt.Sprintf(
+			"Correlated cluster [%s] heat ($%.2f) exceeds cap ($%.2f) by $%.2f",
+			strings.Join(cluster.Tickers, ", "), cluster.NewHeat, cluster.Cap, cluster.Overage,
+		)
+	}
+
+	var greeks *GreeksHeat
+	if mode == HeatModeGreeks {
+		var err error
+		if greeks, err = calculateGreeksHeat(req); err != nil {
+			return nil, err
+		}
+		if exceeded := greeks.firstExceeded(); allowed && exceeded != nil {
+			allowed = false
+			rejectedMeasure = exceeded.Measure
+			scope := "Portfolio"
+			if exceeded.Scope == HeatScopeBucket {
+				scope = fmt.Sprintf("Bucket '%s'", req.AddBucket)
+			}
+			rejectionReason = fmt.Sprintf(
+				"%s net %s ($%.2f) exceeds cap ($%.2f) by $%.2f",
+				scope, strings.ToLower(exceeded.Measure), exceeded.New, exceeded.Cap, math.Abs(exceeded.New)-exceeded.Cap,
+			)
+		}
+	}
+
 	return &HeatResult{
 		CurrentPortfolioHeat: currentPortfolioHeat,
 		NewPortfolioHeat:     newPortfolioHeat,
@@ -144,7 +220,11 @@ func CalculateHeat(req HeatRequest) (*HeatResult, error) {
 		BucketCap:            bucketCap,
 		BucketCapExceeded:    bucketCapExceeded,
 		BucketOverage:        bucketOverage,
+		Cluster:              cluster,
+		Mode:                 mode,
+		Greeks:               greeks,
 		Allowed:              allowed,
 		RejectionReason:      rejectionReason,
+		RejectedMeasure:      rejectedMeasure,
 	}, nil
 }
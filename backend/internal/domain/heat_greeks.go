@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+// Heat modes
+const (
+	HeatModeDollar = "DOLLAR" // Sum of RiskDollars against the dollar caps (default)
+	HeatModeGreeks = "GREEKS" // Dollar caps plus net delta, gamma and vega caps
+)
+
+// Heat measures, as named by HeatResult.RejectedMeasure
+const (
+	HeatMeasureRisk    = "RISK"
+	HeatMeasureCluster = "CLUSTER" // Risk across a correlated cluster
+	HeatMeasureDelta   = "DELTA"
+	HeatMeasureGamma   = "GAMMA"
+	HeatMeasureVega    = "VEGA"
+)
+
+// Heat scopes of an exposure check
+const (
+	HeatScopePortfolio = "PORTFOLIO"
+	HeatScopeBucket    = "BUCKET"
+)
+
+// Exposure is a position's Greeks in dollars
+type Exposure struct {
+	DeltaDollars float64 `json:"delta_dollars"` // Share-equivalent delta × underlying price
+	GammaDollars float64 `json:"gamma_dollars"` // Change in DeltaDollars for a 1% move in the underlying
+	VegaDollars  float64 `json:"vega_dollars"`  // P&L for a 1 point rise in volatility
+}
+
+// Add returns the sum of two exposures
+func (e Exposure) Add(other Exposure) Exposure {
+	return Exposure{
+		DeltaDollars: e.DeltaDollars + other.DeltaDollars,
+		GammaDollars: e.GammaDollars + other.GammaDollars,
+		VegaDollars:  e.VegaDollars + other.VegaDollars,
+	}
+}
+
+// GreeksHeatCaps caps net exposure as a fraction of equity (0 = no cap)
+type GreeksHeatCaps struct {
+	DeltaCapPct       float64 `json:"delta_cap_pct"`        // |Delta dollars|, e.g. 1.0 = 100% of equity
+	BucketDeltaCapPct float64 `json:"bucket_delta_cap_pct"` // Same, within one bucket
+	GammaCapPct       float64 `json:"gamma_cap_pct"`        // |Gamma dollars|
+	BucketGammaCapPct float64 `json:"bucket_gamma_cap_pct"`
+	VegaCapPct        float64 `json:"vega_cap_pct"` // |Vega dollars|
+	BucketVegaCapPct  float64 `json:"bucket_vega_cap_pct"`
+}
+
+// DefaultGreeksHeatCaps are the caps used for settings that were never set
+var DefaultGreeksHeatCaps = GreeksHeatCaps{
+	DeltaCapPct:       1.0,
+	BucketDeltaCapPct: 0.5,
+	GammaCapPct:       0.10,
+	BucketGammaCapPct: 0.05,
+	VegaCapPct:        0.01,
+	BucketVegaCapPct:  0.005,
+}
+
+// GreeksHeatCapsFromSettings reads the GREEKS heat caps from settings,
+// using DefaultGreeksHeatCaps for any that were never set
+func GreeksHeatCapsFromSettings(settings map[string]string) (GreeksHeatCaps, error) {
+	caps := DefaultGreeksHeatCaps
+	for _, c := range []struct {
+		key SettingKey
+		pct *float64
+	}{
+		{SettingDeltaHeatCap, &caps.DeltaCapPct},
+		{SettingBucketDeltaHeatCap, &caps.BucketDeltaCapPct},
+		{SettingGammaHeatCap, &caps.GammaCapPct},
+		{SettingBucketGammaHeatCap, &caps.BucketGammaCapPct},
+		{SettingVegaHeatCap, &caps.VegaCapPct},
+		{SettingBucketVegaHeatCap, &caps.BucketVegaCapPct},
+	} {
+		value, ok := settings[string(c.key)]
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return caps, fmt.Errorf("invalid %s setting: %w", c.key, err)
+		}
+		*c.pct = parsed
+	}
+	return caps, nil
+}
+
+// ExposureCheck is one exposure measure against its cap
+type ExposureCheck struct {
+	Measure  string  `json:"measure"` // DELTA, GAMMA or VEGA
+	Scope    string  `json:"scope"`   // PORTFOLIO or BUCKET
+	Current  float64 `json:"current"` // Net, before the new trade
+	New      float64 `json:"new"`
+	Cap      float64 `json:"cap"` // Dollars
+	Exceeded bool    `json:"exceeded"`
+}
+
+// GreeksHeat is the exposure side of a GREEKS heat check
+type GreeksHeat struct {
+	Portfolio Exposure            `json:"portfolio"` // Including the new trade
+	Buckets   map[string]Exposure `json:"buckets"`   // Including the new trade
+	Checks    []ExposureCheck     `json:"checks"`    // Portfolio first, then the new trade's bucket (capped measures only)
+}
+
+// calculateGreeksHeat nets the open positions' exposure per bucket and for
+// the portfolio, adds the new trade and checks every capped measure
+//
+// Exposure is signed, so long and short deltas offset. A measure is
+// exceeded when its new net value is over the cap and further from zero
+// than before: a trade that reduces an exposure already over its cap is
+// not rejected for it.
+func calculateGreeksHeat(req HeatRequest) (*GreeksHeat, error) {
+	caps := req.GreeksCaps
+	for _, c := range []struct {
+		name string
+		pct  float64
+	}{
+		{"delta_cap_pct", caps.DeltaCapPct},
+		{"bucket_delta_cap_pct", caps.BucketDeltaCapPct},
+		{"gamma_cap_pct", caps.GammaCapPct},
+		{"bucket_gamma_cap_pct", caps.BucketGammaCapPct},
+		{"vega_cap_pct", caps.VegaCapPct},
+		{"bucket_vega_cap_pct", caps.BucketVegaCapPct},
+	} {
+		if c.pct < 0 {
+			return nil, fmt.Errorf("%s must not be negative, got %.4f", c.name, c.pct)
+		}
+	}
+
+	var portfolio Exposure
+	buckets := map[string]Exposure{}
+	for _, pos := range req.OpenPositions {
+		if pos.Status != "Open" {
+			continue
+		}
+		portfolio = portfolio.Add(pos.Exposure)
+		buckets[pos.Bucket] = buckets[pos.Bucket].Add(pos.Exposure)
+	}
+	currentPortfolio, currentBucket := portfolio, buckets[req.AddBucket]
+
+	heat := &GreeksHeat{
+		Portfolio: portfolio.Add(req.AddExposure),
+		Buckets:   buckets,
+	}
+	if req.AddBucket != "" {
+		heat.Buckets[req.AddBucket] = currentBucket.Add(req.AddExposure)
+	}
+
+	check := func(measure, scope string, current, next, capPct float64) {
+		if capPct <= 0 {
+			return
+		}
+		c := ExposureCheck{
+			Measure: measure,
+			Scope:   scope,
+			Current: current,
+			New:     next,
+			Cap:     req.Equity * capPct,
+		}
+		c.Exceeded = math.Abs(next) > c.Cap && math.Abs(next) > math.Abs(current)
+		heat.Checks = append(heat.Checks, c)
+	}
+	check(HeatMeasureDelta, HeatScopePortfolio, currentPortfolio.DeltaDollars, heat.Portfolio.DeltaDollars, caps.DeltaCapPct)
+	check(HeatMeasureGamma, HeatScopePortfolio, currentPortfolio.GammaDollars, heat.Portfolio.GammaDollars, caps.GammaCapPct)
+	check(HeatMeasureVega, HeatScopePortfolio, currentPortfolio.VegaDollars, heat.Portfolio.VegaDollars, caps.VegaCapPct)
+	if req.AddBucket != "" {
+		newBucket := heat.Buckets[req.AddBucket]
+		check(HeatMeasureDelta, HeatScopeBucket, currentBucket.DeltaDollars, newBucket.DeltaDollars, caps.BucketDeltaCapPct)
+		check(HeatMeasureGamma, HeatScopeBucket, currentBucket.GammaDollars, newBucket.GammaDollars, caps.BucketGammaCapPct)
+		check(HeatMeasureVega, HeatScopeBucket, currentBucket.VegaDollars, newBucket.VegaDollars, caps.BucketVegaCapPct)
+	}
+
+	return heat, nil
+}
+
+// firstExceeded returns the first exposure check over its cap, or nil
+func (g *GreeksHeat) firstExceeded() *ExposureCheck {
+	for i := range g.Checks {
+		if g.Checks[i].Exceeded {
+			return &g.Checks[i]
+		}
+	}
+	return nil
+}
+
+// BucketNames lists the buckets with exposure, sorted
+func (g *GreeksHeat) BucketNames() []string {
+	names := make([]string, 0, len(g.Buckets))
+	for name := range g.Buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StockExposure is the exposure of shares: delta only
+func StockExposure(direction string, shares int, price float64) Exposure {
+	delta := float64(shares) * price
+	if direction == DirectionShort {
+		delta = -delta
+	}
+	return Exposure{DeltaDollars: delta}
+}
+
+// OptionExposure is the exposure of a position's legs (stock legs included)
+// valued at m
+//
+// Each option leg's volatility is Market.Vol, or the IV solved from its
+// entry fill at entrySpot on entryDate (see legVols), or failing that the
+// IV solved from its fill at the current spot.
+func OptionExposure(legs []options.Leg, entrySpot float64, entryDate string, m options.Market) (Exposure, error) {
+	if m.Spot <= 0 {
+		return Exposure{}, fmt.Errorf("an underlying price is needed to value the legs")
+	}
+	vols, err := legVols(legs, entrySpot, entryDate, m)
+	if err != nil {
+		return Exposure{}, err
+	}
+
+	var delta, gamma, vega float64
+	for i, leg := range legs {
+		sign, err := options.ActionSign(leg.Action)
+		if err != nil {
+			return Exposure{}, err
+		}
+		if leg.Type == options.Stock {
+			delta += sign * float64(leg.Qty)
+			continue
+		}
+		market := m
+		if vols[i] > 0 {
+			market.Vol = vols[i]
+		}
+		priced, err := options.PriceLeg(leg, market)
+		if err != nil {
+			return Exposure{}, err
+		}
+		delta += priced.Position.Delta
+		gamma += priced.Position.Gamma
+		vega += priced.Position.Vega
+	}
+
+	return Exposure{
+		DeltaDollars: delta * m.Spot,
+		GammaDollars: gamma * m.Spot * m.Spot * 0.01,
+		VegaDollars:  vega,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+// greeksHeatRequest is a $10k account in GREEKS mode: delta capped at 100%
+// of equity (50% per bucket), gamma at 10% (5%), vega at 1% (0.5%)
+func greeksHeatRequest() HeatRequest {
+	return HeatRequest{
+		Equity:           10000,
+		HeatCapPct:       0.04,
+		BucketHeatCapPct: 0.015,
+		AddRiskDollars:   50,
+		AddBucket:        "Tech/Comm",
+		Mode:             HeatModeGreeks,
+		GreeksCaps: GreeksHeatCaps{
+			DeltaCapPct:       1.0,
+			BucketDeltaCapPct: 0.5,
+			GammaCapPct:       0.10,
+			BucketGammaCapPct: 0.05,
+			VegaCapPct:        0.01,
+			BucketVegaCapPct:  0.005,
+		},
+		OpenPositions: []Position{
+			{Ticker: "AAPL", Bucket: "Tech/Comm", RiskDollars: 50, Status: "Open",
+				Exposure: Exposure{DeltaDollars: 4000, GammaDollars: 120, VegaDollars: 20}},
+			{Ticker: "XOM", Bucket: "Energy", RiskDollars: 50, Status: "Open",
+				Exposure: Exposure{DeltaDollars: -1500, VegaDollars: -30}},
+		},
+	}
+}
+
+func TestCalculateHeat_GreeksBucketDeltaRejects(t *testing.T) {
+	req := greeksHeatRequest()
+	req.AddExposure = Exposure{DeltaDollars: 2000}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.PortfolioCapExceeded)
+	assert.False(t, result.BucketCapExceeded)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, HeatMeasureDelta, result.RejectedMeasure)
+	assert.Contains(t, result.RejectionReason, "Bucket 'Tech/Comm' net delta ($6000.00) exceeds cap ($5000.00)")
+
+	require.NotNil(t, result.Greeks)
+	assert.Equal(t, HeatModeGreeks, result.Mode)
+	assert.InDelta(t, 4500, result.Greeks.Portfolio.DeltaDollars, 1e-9) // Net of the XOM short delta
+	assert.InDelta(t, -10, result.Greeks.Portfolio.VegaDollars, 1e-9)
+	assert.InDelta(t, 6000, result.Greeks.Buckets["Tech/Comm"].DeltaDollars, 1e-9)
+	assert.InDelta(t, -1500, result.Greeks.Buckets["Energy"].DeltaDollars, 1e-9)
+	assert.Equal(t, []string{"Energy", "Tech/Comm"}, result.Greeks.BucketNames())
+	assert.Len(t, result.Greeks.Checks, 6)
+}
+
+func TestCalculateHeat_GreeksPortfolioVegaRejects(t *testing.T) {
+	// A short strangle elsewhere adds -$120 of vega: net -$130 against a $100 cap
+	req := greeksHeatRequest()
+	req.AddBucket = "Finance"
+	req.AddExposure = Exposure{DeltaDollars: 100, GammaDollars: -80, VegaDollars: -120}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.Allowed)
+	assert.Equal(t, HeatMeasureVega, result.RejectedMeasure)
+	assert.Contains(t, result.RejectionReason, "Portfolio net vega ($-130.00) exceeds cap ($100.00) by $30.00")
+}
+
+func TestCalculateHeat_GreeksHedgeAllowedOverCap(t *testing.T) {
+	// Tech delta is already over its cap; a trade that reduces it is not rejected for it
+	req := greeksHeatRequest()
+	req.OpenPositions[0].Exposure.DeltaDollars = 7000
+	req.AddExposure = Exposure{DeltaDollars: -1000}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.True(t, result.Allowed)
+	assert.Empty(t, result.RejectedMeasure)
+}
+
+func TestCalculateHeat_DollarCapsComeFirst(t *testing.T) {
+	req := greeksHeatRequest()
+	req.AddRiskDollars = 120 // Tech bucket $170 > $150
+	req.AddExposure = Exposure{DeltaDollars: 2000}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.Allowed)
+	assert.Equal(t, HeatMeasureRisk, result.RejectedMeasure)
+	assert.Contains(t, result.RejectionReason, "Bucket 'Tech/Comm' heat")
+}
+
+func TestCalculateHeat_DollarModeIgnoresExposure(t *testing.T) {
+	req := greeksHeatRequest()
+	req.Mode = ""
+	req.AddExposure = Exposure{DeltaDollars: 50000}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.True(t, result.Allowed)
+	assert.Equal(t, HeatModeDollar, result.Mode)
+	assert.Nil(t, result.Greeks)
+}
+
+func TestCalculateHeat_GreeksErrors(t *testing.T) {
+	req := greeksHeatRequest()
+	req.Mode = "VAR"
+	_, err := CalculateHeat(req)
+	assert.Error(t, err)
+
+	req = greeksHeatRequest()
+	req.GreeksCaps.VegaCapPct = -0.01
+	_, err = CalculateHeat(req)
+	assert.Error(t, err)
+}
+
+func TestOptionExposure(t *testing.T) {
+	m := options.Market{Spot: 100, Rate: 0.05, Vol: 0.2, AsOf: "2024-01-01"}
+	call := options.Leg{Type: "CALL", Strike: 100, Exp: "2024-12-31", Qty: 1, Action: "BUY"}
+
+	exposure, err := OptionExposure([]options.Leg{call}, 0, "", m)
+	require.NoError(t, err)
+	assert.InDelta(t, 6368, exposure.DeltaDollars, 1)  // 0.6368 × 100 shares × $100
+	assert.InDelta(t, 187.6, exposure.GammaDollars, 1) // Γ 0.01876 × 100 × $100² × 1%
+	assert.InDelta(t, 37.5, exposure.VegaDollars, 0.1)
+
+	// Covered call: 100 shares less the short call's delta
+	short := call
+	short.Action = "SELL"
+	covered, err := OptionExposure([]options.Leg{{Type: options.Stock, Qty: 100, Action: "BUY", Price: 100}, short}, 0, "", m)
+	require.NoError(t, err)
+	assert.InDelta(t, 10000-6368, covered.DeltaDollars, 1)
+	assert.InDelta(t, -exposure.VegaDollars, covered.VegaDollars, 1e-9)
+
+	_, err = OptionExposure([]options.Leg{call}, 0, "", options.Market{Vol: 0.2})
+	assert.Error(t, err)
+}
+
+func TestStockExposure(t *testing.T) {
+	assert.Equal(t, Exposure{DeltaDollars: 4500}, StockExposure(DirectionLong, 25, 180))
+	assert.Equal(t, Exposure{DeltaDollars: -4500}, StockExposure(DirectionShort, 25, 180))
+}
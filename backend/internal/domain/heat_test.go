@@ -38,6 +38,29 @@ func TestCalculateHeat_NoExistingPositions(t *testing.T) {
 	assert.Empty(t, result.RejectionReason)
 }
 
+func TestCalculateHeat_ProfitLockedPositionHidesNoHeat(t *testing.T) {
+	// AAPL's stop is past entry; its locked-in profit must not offset MSFT
+	req := HeatRequest{
+		Equity:           10000,
+		HeatCapPct:       0.04,
+		BucketHeatCapPct: 0.015,
+		AddRiskDollars:   75,
+		AddBucket:        "Tech/Comm",
+		OpenPositions: []Position{
+			{Ticker: "AAPL", Bucket: "Tech/Comm", RiskDollars: -125, Status: "Open"},
+			{Ticker: "MSFT", Bucket: "Tech/Comm", RiskDollars: 100, Status: "Open"},
+		},
+	}
+
+	result, err := CalculateHeat(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 100.0, result.CurrentPortfolioHeat)
+	assert.Equal(t, 175.0, result.NewBucketHeat)
+	assert.True(t, result.BucketCapExceeded)
+	assert.False(t, result.Allowed)
+}
+
 func TestCalculateHeat_PortfolioApproachingCap(t *testing.T) {
 	// 4 positions at $75 each = $300 heat
 	// Adding to Finance bucket (no bucket heat conflict)
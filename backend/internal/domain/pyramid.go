@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Pyramiding defaults (Turtle rules)
+const (
+	DefaultMaxUnits = 4   // Maximum units per position
+	DefaultAddStepN = 0.5 // Add one unit every ½N of favourable movement
+)
+
+// Pyramiding errors
+var (
+	ErrMaxUnitsReached    = errors.New("position already holds the maximum number of units")
+	ErrAddLevelNotReached = errors.New("price has not reached the next add level")
+	ErrPyramidNoATR       = errors.New("N (ATR at entry) is required to compute add levels and stops")
+	ErrNoUnitsFilled      = errors.New("position has no filled units")
+	ErrAddUnitHeat        = errors.New("adding unit exceeds heat cap")
+)
+
+// PyramidUnit is one filled unit of a pyramided position
+type PyramidUnit struct {
+	UnitNum    int     `json:"unit_num"`
+	Shares     int     `json:"shares"`
+	EntryPrice float64 `json:"entry_price"`
+}
+
+// AddUnitRequest describes an open position and a proposed add-on fill
+type AddUnitRequest struct {
+	Ticker      string        `json:"ticker"`
+	Direction   string        `json:"direction,omitempty"`  // LONG (default) or SHORT
+	Price       float64       `json:"price"`                // Fill price of the new unit
+	Shares      int           `json:"shares,omitempty"`     // Default: same size as unit 1
+	N           float64       `json:"atr_n"`                // N at entry; add levels and stops are multiples of it
+	K           float64       `json:"k"`                    // Stop multiple
+	AddStepN    float64       `json:"add_step_n"`           // Default 0.5
+	MaxUnits    int           `json:"max_units"`            // Default 4
+	AddPrices   []float64     `json:"add_prices,omitempty"` // Planned levels for units 2..MaxUnits (0 = not planned)
+	Units       []PyramidUnit `json:"units"`                // Filled units, oldest first
+	CurrentStop float64       `json:"current_stop"`
+	CurrentRisk float64       `json:"current_risk"` // Position risk_dollars before the add
+}
+
+// AddUnitPlan is the result of a successful add-on check
+type AddUnitPlan struct {
+	Ticker          string  `json:"ticker"`
+	UnitNum         int     `json:"unit_num"`
+	Shares          int     `json:"shares"`
+	FillPrice       float64 `json:"fill_price"`
+	AddLevel        float64 `json:"add_level"` // Level the price had to reach
+	PreviousStop    float64 `json:"previous_stop"`
+	NewStop         float64 `json:"new_stop"` // Applied to every unit
+	TotalShares     int     `json:"total_shares"`
+	AverageEntry    float64 `json:"average_entry"`
+	NewRiskDollars  float64 `json:"new_risk_dollars"`
+	IncrementalRisk float64 `json:"incremental_risk"` // Change in position risk (negative when the tighter stop frees heat)
+	NextAddLevel    float64 `json:"next_add_level,omitempty"`
+}
+
+// NextAddLevel returns the price unit number `unitNum` may be added at
+//
+// Planned levels (AddPrice1..3 from sizing) win when present; otherwise the
+// level is AddStepN × N beyond the most recent fill in the trade's favour
+// (above it for a long, below it for a short), as in the Turtle rules.
+func NextAddLevel(req AddUnitRequest, unitNum int) float64 {
+	if idx := unitNum - 2; idx >= 0 && idx < len(req.AddPrices) && req.AddPrices[idx] > 0 {
+		return req.AddPrices[idx]
+	}
+	step := req.AddStepN
+	if step <= 0 {
+		step = DefaultAddStepN
+	}
+	last := req.Units[len(req.Units)-1].EntryPrice
+	return last + DirectionSign(req.Direction)*step*req.N
+}
+
+// PlanAddUnit checks a pyramid add-on and computes the resulting position
+//
+// Turtle pyramiding rules:
+//   - At most MaxUnits units per position
+//   - A unit may only be added once price reaches the next add level
+//   - Each unit is the same size as the first unless Shares says otherwise
+//   - When a unit is added, the stop for every unit moves to K × N from
+//     the new fill (below it for a long, above it for a short); a stop is
+//     never loosened
+//
+// Risk is recomputed over all units against the new common stop, so the
+// incremental risk can be negative when the tighter stop frees heat.
+func PlanAddUnit(req AddUnitRequest) (*AddUnitPlan, error) {
+	if len(req.Units) == 0 {
+		return nil, ErrNoUnitsFilled
+	}
+	direction, err := NormalizeDirection(req.Direction)
+	if err != nil {
+		return nil, err
+	}
+	req.Direction = direction
+	if req.N <= 0 {
+		return nil, ErrPyramidNoATR
+	}
+	if req.Price <= 0 {
+		return nil, fmt.Errorf("%w: got %.2f", ErrInvalidEntry, req.Price)
+	}
+	if req.K <= 0 {
+		return nil, fmt.Errorf("%w: got %.2f", ErrInvalidK, req.K)
+	}
+	maxUnits := req.MaxUnits
+	if maxUnits <= 0 {
+		maxUnits = DefaultMaxUnits
+	}
+
+	unitNum := len(req.Units) + 1
+	if unitNum > maxUnits {
+		return nil, fmt.Errorf("%w (%d of %d)", ErrMaxUnitsReached, len(req.Units), maxUnits)
+	}
+
+	level := NextAddLevel(req, unitNum)
+	if PnLPerShare(direction, level, req.Price) < 0 {
+		return nil, fmt.Errorf("%w: unit %d adds at $%.2f, price is $%.2f", ErrAddLevelNotReached, unitNum, level, req.Price)
+	}
+
+	shares := req.Shares
+	if shares <= 0 {
+		shares = req.Units[0].Shares
+	}
+	if shares <= 0 {
+		return nil, fmt.Errorf("add-on shares must be positive, got %d", shares)
+	}
+
+	newStop := TighterStop(direction, req.CurrentStop, StopFor(direction, req.Price, req.K*req.N))
+
+	plan := &AddUnitPlan{
+		Ticker:       req.Ticker,
+		UnitNum:      unitNum,
+		Shares:       shares,
+		FillPrice:    req.Price,
+		AddLevel:     level,
+		PreviousStop: req.CurrentStop,
+		NewStop:      newStop,
+	}
+
+	cost := 0.0
+	units := append(append([]PyramidUnit{}, req.Units...), PyramidUnit{UnitNum: unitNum, Shares: shares, EntryPrice: req.Price})
+	for _, u := range units {
+		plan.TotalShares += u.Shares
+		cost += float64(u.Shares) * u.EntryPrice
+		plan.NewRiskDollars += float64(u.Shares) * OpenRiskPerShare(direction, u.EntryPrice, newStop)
+	}
+	plan.AverageEntry = cost / float64(plan.TotalShares)
+	plan.IncrementalRisk = plan.NewRiskDollars - req.CurrentRisk
+
+	if unitNum < maxUnits {
+		next := req
+		next.Units = units
+		plan.NextAddLevel = NextAddLevel(next, unitNum+1)
+	}
+
+	return plan, nil
+}
+
+// CheckAddUnitHeat re-runs the heat caps for the extra risk an add-on brings
+// openPositions must already include this position at its current risk.
+// Uses the same rule as the save-decision gate: the bucket cap only applies
+// when the position has a bucket.
+func CheckAddUnitHeat(plan *AddUnitPlan, bucket string, equity, heatCapPct, bucketHeatCapPct float64, openPositions []Position) (*HeatResult, error) {
+	result, err := CalculateHeat(HeatRequest{
+		Equity:           equity,
+		HeatCapPct:       heatCapPct,
+		BucketHeatCapPct: bucketHeatCapPct,
+		AddRiskDollars:   math.Max(0, plan.IncrementalRisk),
+		AddBucket:        bucket,
+		OpenPositions:    openPositions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.PortfolioCapExceeded || (bucket != "" && result.BucketCapExceeded) {
+		return result, fmt.Errorf("%w: %s", ErrAddUnitHeat, result.RejectionReason)
+	}
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pyramidRequest is one 25-share unit at $180 with N = 1.5 and a 2N stop
+func pyramidRequest(price float64) AddUnitRequest {
+	return AddUnitRequest{
+		Ticker:      "AAPL",
+		Price:       price,
+		N:           1.5,
+		K:           2,
+		Units:       []PyramidUnit{{UnitNum: 1, Shares: 25, EntryPrice: 180}},
+		CurrentStop: 177,
+		CurrentRisk: 75,
+	}
+}
+
+func TestPlanAddUnit_RaisesStopForAllUnits(t *testing.T) {
+	plan, err := PlanAddUnit(pyramidRequest(180.75))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, plan.UnitNum)
+	assert.Equal(t, 25, plan.Shares)
+	assert.InDelta(t, 180.75, plan.AddLevel, 1e-9)
+	assert.InDelta(t, 177.75, plan.NewStop, 1e-9) // 180.75 - 2 × 1.5
+	assert.Equal(t, 50, plan.TotalShares)
+	assert.InDelta(t, 180.375, plan.AverageEntry, 1e-9)
+	assert.InDelta(t, 131.25, plan.NewRiskDollars, 1e-9) // 25 × 2.25 + 25 × 3
+	assert.InDelta(t, 56.25, plan.IncrementalRisk, 1e-9)
+	assert.InDelta(t, 181.5, plan.NextAddLevel, 1e-9)
+}
+
+func TestPlanAddUnit_LevelNotReached(t *testing.T) {
+	_, err := PlanAddUnit(pyramidRequest(180.5))
+	assert.ErrorIs(t, err, ErrAddLevelNotReached)
+}
+
+func TestPlanAddUnit_UsesPlannedAddPrices(t *testing.T) {
+	req := pyramidRequest(181)
+	req.AddPrices = []float64{181.5, 183, 184.5}
+
+	_, err := PlanAddUnit(req)
+	assert.ErrorIs(t, err, ErrAddLevelNotReached)
+
+	req.Price = 181.5
+	plan, err := PlanAddUnit(req)
+	require.NoError(t, err)
+	assert.InDelta(t, 181.5, plan.AddLevel, 1e-9)
+	assert.InDelta(t, 183, plan.NextAddLevel, 1e-9)
+}
+
+func TestPlanAddUnit_NeverLowersStop(t *testing.T) {
+	req := pyramidRequest(181)
+	req.CurrentStop = 179.5 // Already trailed above 181 - 2N
+
+	plan, err := PlanAddUnit(req)
+	require.NoError(t, err)
+	assert.InDelta(t, 179.5, plan.NewStop, 1e-9)
+}
+
+// shortPyramidRequest is one 25-share unit sold short at $180 with a 2N stop at $183
+func shortPyramidRequest(price float64) AddUnitRequest {
+	req := pyramidRequest(price)
+	req.Direction = DirectionShort
+	req.CurrentStop = 183
+	return req
+}
+
+func TestPlanAddUnit_ShortLowersStopForAllUnits(t *testing.T) {
+	_, err := PlanAddUnit(shortPyramidRequest(180.75))
+	assert.ErrorIs(t, err, ErrAddLevelNotReached) // Shorts add as price falls
+
+	plan, err := PlanAddUnit(shortPyramidRequest(179.25))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 179.25, plan.AddLevel, 1e-9)
+	assert.InDelta(t, 182.25, plan.NewStop, 1e-9) // 179.25 + 2 × 1.5
+	assert.InDelta(t, 179.625, plan.AverageEntry, 1e-9)
+	assert.InDelta(t, 131.25, plan.NewRiskDollars, 1e-9) // 25 × 2.25 + 25 × 3
+	assert.InDelta(t, 56.25, plan.IncrementalRisk, 1e-9)
+	assert.InDelta(t, 178.5, plan.NextAddLevel, 1e-9)
+}
+
+func TestPlanAddUnit_ShortNeverRaisesStop(t *testing.T) {
+	req := shortPyramidRequest(179)
+	req.CurrentStop = 180.5 // Already trailed below 179 + 2N
+
+	plan, err := PlanAddUnit(req)
+	require.NoError(t, err)
+	assert.InDelta(t, 180.5, plan.NewStop, 1e-9)
+}
+
+func TestPlanAddUnit_MaxUnits(t *testing.T) {
+	req := pyramidRequest(190)
+	req.MaxUnits = 2
+	req.Units = append(req.Units, PyramidUnit{UnitNum: 2, Shares: 25, EntryPrice: 180.75})
+
+	_, err := PlanAddUnit(req)
+	assert.ErrorIs(t, err, ErrMaxUnitsReached)
+}
+
+func TestPlanAddUnit_InvalidInputs(t *testing.T) {
+	req := pyramidRequest(181)
+	req.N = 0
+	_, err := PlanAddUnit(req)
+	assert.ErrorIs(t, err, ErrPyramidNoATR)
+
+	req = pyramidRequest(181)
+	req.Units = nil
+	_, err = PlanAddUnit(req)
+	assert.ErrorIs(t, err, ErrNoUnitsFilled)
+}
+
+func TestCheckAddUnitHeat(t *testing.T) {
+	plan, err := PlanAddUnit(pyramidRequest(180.75))
+	require.NoError(t, err)
+
+	open := []Position{{Ticker: "AAPL", Bucket: "Tech/Comm", RiskDollars: 75, UnitsOpen: 1, Status: "Open"}}
+
+	// $10,000 × 4% = $400 portfolio, × 1.5% = $150 bucket: 75 + 56.25 fits
+	result, err := CheckAddUnitHeat(plan, "Tech/Comm", 10000, 0.04, 0.015, open)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// A 1% bucket cap ($100) is exceeded by the add-on
+	_, err = CheckAddUnitHeat(plan, "Tech/Comm", 10000, 0.04, 0.01, open)
+	assert.ErrorIs(t, err, ErrAddUnitHeat)
+}
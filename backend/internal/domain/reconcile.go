@@ -0,0 +1,140 @@
+package domain
+
+import "fmt"
+
+// ExitReasonExpiration marks an option position past its primary expiration
+const ExitReasonExpiration = "EXPIRATION"
+
+// ReconcileRequest describes an open position to check against its bars
+type ReconcileRequest struct {
+	Ticker         string        `json:"ticker"`
+	Direction      string        `json:"direction"`           // LONG (default) or SHORT
+	EntryDate      string        `json:"entry_date"`          // Channel exits are checked on later bars
+	StopDate       string        `json:"stop_date,omitempty"` // Day the stop was set (default EntryDate)
+	CurrentStop    float64       `json:"current_stop"`
+	ExitLookback   int           `json:"exit_lookback,omitempty"` // Default 10; negative disables channel exits
+	ExpirationDate string        `json:"expiration_date,omitempty"`
+	AsOfDate       string        `json:"as_of_date,omitempty"` // Expiration check date (default last bar)
+	Units          []PyramidUnit `json:"units"`                // Open shares per lot, for the projected P&L
+	RealizedPnL    float64       `json:"realized_pnl"`         // From earlier partial exits
+}
+
+// ReconcileExit is an exit that the bars show should already have happened
+type ReconcileExit struct {
+	Ticker       string  `json:"ticker"`
+	Direction    string  `json:"direction"`
+	Reason       string  `json:"reason"` // STOP, CHANNEL or EXPIRATION
+	Date         string  `json:"date"`   // Bar (or expiration) that triggered the exit
+	Level        float64 `json:"level"`  // Stop or channel level that was breached
+	ExitPrice    float64 `json:"exit_price"`
+	Gapped       bool    `json:"gapped,omitempty"` // Bar opened through the level
+	BarOpen      float64 `json:"bar_open,omitempty"`
+	Shares       int     `json:"shares"`
+	ProjectedPnL float64 `json:"projected_pnl"` // Total P&L if closed at ExitPrice
+	Detail       string  `json:"detail"`
+}
+
+// ReconcilePosition finds the first exit the bars say has already happened
+//
+// Long side (shorts mirror it), matching the backtester:
+//   - STOP: a bar after StopDate traded at or below CurrentStop, unless the
+//     channel exit was higher and so hit first
+//   - CHANNEL: a bar after EntryDate traded below the low of the prior
+//     ExitLookback bars
+//   - EXPIRATION: ExpirationDate is on or before AsOfDate
+//
+// The exit is pre-filled at the breached level; when the bar gapped through
+// it, Gapped is set and BarOpen shows the likely fill. An expiration has no
+// exit price: the legs must be priced before the position is closed.
+// Returns nil when the position should still be open.
+func ReconcilePosition(req ReconcileRequest, bars []Bar) (*ReconcileExit, error) {
+	direction, err := NormalizeDirection(req.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	lookback := req.ExitLookback
+	if lookback == 0 {
+		lookback = DefaultExitLookback
+	}
+	stopDate := req.StopDate
+	if stopDate == "" || stopDate < req.EntryDate {
+		stopDate = req.EntryDate
+	}
+
+	exit := &ReconcileExit{Ticker: req.Ticker, Direction: direction}
+	for _, u := range req.Units {
+		exit.Shares += u.Shares
+	}
+
+	long := direction == DirectionLong
+	for i, bar := range bars {
+		if bar.Date <= req.EntryDate {
+			continue
+		}
+
+		stopHit := false
+		if req.CurrentStop > 0 && bar.Date > stopDate {
+			stopHit = (long && bar.Low <= req.CurrentStop) || (!long && bar.High >= req.CurrentStop)
+		}
+
+		channelHit, channel := false, 0.0
+		if lookback > 0 && i > 0 {
+			high, low, err := DonchianChannel(bars, i, min(lookback, i))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", req.Ticker, err)
+			}
+			channel = low
+			channelHit = bar.Low < low
+			if !long {
+				channel = high
+				channelHit = bar.High > high
+			}
+		}
+
+		switch {
+		case stopHit && (!channelHit || TighterStop(direction, channel, req.CurrentStop) == req.CurrentStop):
+			exit.Reason, exit.Level = ExitReasonStop, req.CurrentStop
+			exit.Detail = fmt.Sprintf("%s traded through the $%.2f stop", bar.Date, req.CurrentStop)
+		case channelHit:
+			exit.Reason, exit.Level = ExitReasonChannel, channel
+			exit.Detail = fmt.Sprintf("%s broke the %d-bar exit channel at $%.2f", bar.Date, min(lookback, i), channel)
+		default:
+			continue
+		}
+
+		exit.Date = bar.Date
+		exit.ExitPrice = exit.Level
+		if PnLPerShare(direction, exit.Level, bar.Open) < 0 {
+			exit.Gapped = true
+			exit.BarOpen = bar.Open
+			exit.Detail += fmt.Sprintf(" (gapped: opened $%.2f)", bar.Open)
+		}
+		if req.ExpirationDate != "" && req.ExpirationDate < exit.Date {
+			break // Expired before the bars say it was stopped out
+		}
+		exit.ProjectedPnL = req.RealizedPnL
+		for _, u := range req.Units {
+			exit.ProjectedPnL += float64(u.Shares) * PnLPerShare(direction, u.EntryPrice, exit.ExitPrice)
+		}
+		return exit, nil
+	}
+
+	asOf := req.AsOfDate
+	if asOf == "" && len(bars) > 0 {
+		asOf = bars[len(bars)-1].Date
+	}
+	if req.ExpirationDate != "" && asOf != "" && req.ExpirationDate <= asOf {
+		return &ReconcileExit{
+			Ticker:       req.Ticker,
+			Direction:    direction,
+			Reason:       ExitReasonExpiration,
+			Date:         req.ExpirationDate,
+			Shares:       exit.Shares,
+			ProjectedPnL: req.RealizedPnL,
+			Detail:       fmt.Sprintf("%s expired %s; price the legs before closing", req.Ticker, req.ExpirationDate),
+		}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reconcileRequest is 25 shares long at $100 from 2024-01-01 with a $97 stop
+// and channel exits disabled
+func reconcileRequest() ReconcileRequest {
+	return ReconcileRequest{
+		Ticker:       "AAPL",
+		EntryDate:    "2024-01-01",
+		CurrentStop:  97,
+		ExitLookback: -1,
+		Units:        []PyramidUnit{{UnitNum: 1, Shares: 25, EntryPrice: 100}},
+	}
+}
+
+func TestReconcilePosition_StopHit(t *testing.T) {
+	exit, err := ReconcilePosition(reconcileRequest(), trailBars(100, 101, 99, 97.2, 95))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+
+	assert.Equal(t, ExitReasonStop, exit.Reason)
+	assert.Equal(t, "2024-01-04", exit.Date) // Low 96.7, first bar through the stop
+	assert.InDelta(t, 97, exit.ExitPrice, 1e-9)
+	assert.False(t, exit.Gapped)
+	assert.Equal(t, 25, exit.Shares)
+	assert.InDelta(t, -75, exit.ProjectedPnL, 1e-9) // 25 × (97 - 100)
+}
+
+func TestReconcilePosition_GapThroughStop(t *testing.T) {
+	exit, err := ReconcilePosition(reconcileRequest(), trailBars(100, 101, 96))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+
+	assert.InDelta(t, 97, exit.ExitPrice, 1e-9) // Pre-filled at the stop
+	assert.True(t, exit.Gapped)
+	assert.InDelta(t, 96, exit.BarOpen, 1e-9)
+}
+
+func TestReconcilePosition_IgnoresBarsBeforeStopWasSet(t *testing.T) {
+	req := reconcileRequest()
+	req.StopDate = "2024-01-04"
+
+	// The 01-03 low was below 97, but the stop was only raised there on 01-04
+	exit, err := ReconcilePosition(req, trailBars(100, 101, 96.5, 102, 103))
+	require.NoError(t, err)
+	assert.Nil(t, exit)
+}
+
+func TestReconcilePosition_ChannelExit(t *testing.T) {
+	req := reconcileRequest()
+	req.CurrentStop = 90
+	req.ExitLookback = 2
+
+	exit, err := ReconcilePosition(req, trailBars(100, 102, 103, 101.8))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+
+	assert.Equal(t, ExitReasonChannel, exit.Reason)
+	assert.Equal(t, "2024-01-04", exit.Date)
+	assert.InDelta(t, 101.5, exit.ExitPrice, 1e-9) // 2-bar low before 01-04
+	assert.InDelta(t, 37.5, exit.ProjectedPnL, 1e-9)
+}
+
+func TestReconcilePosition_HigherLevelHitFirst(t *testing.T) {
+	req := reconcileRequest()
+	req.CurrentStop = 101
+	req.ExitLookback = 2
+
+	// Low 99.5 breaks both the 101 stop and the 101.5 channel: the channel is hit first
+	exit, err := ReconcilePosition(req, trailBars(100, 102, 103, 100))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+
+	assert.Equal(t, ExitReasonChannel, exit.Reason)
+	assert.InDelta(t, 101.5, exit.ExitPrice, 1e-9)
+	assert.True(t, exit.Gapped)
+}
+
+func TestReconcilePosition_ShortStop(t *testing.T) {
+	req := reconcileRequest()
+	req.Direction = DirectionShort
+	req.CurrentStop = 103
+
+	exit, err := ReconcilePosition(req, trailBars(100, 99, 102.8))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+
+	assert.Equal(t, ExitReasonStop, exit.Reason)
+	assert.InDelta(t, 103, exit.ExitPrice, 1e-9) // High 103.3
+	assert.False(t, exit.Gapped)
+	assert.InDelta(t, -75, exit.ProjectedPnL, 1e-9) // 25 × (100 - 103)
+}
+
+func TestReconcilePosition_Expiration(t *testing.T) {
+	req := reconcileRequest()
+	req.ExpirationDate = "2024-01-03"
+
+	exit, err := ReconcilePosition(req, trailBars(100, 101, 102, 103))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+	assert.Equal(t, ExitReasonExpiration, exit.Reason)
+	assert.Equal(t, "2024-01-03", exit.Date)
+	assert.Zero(t, exit.ExitPrice)
+
+	// Expired before the bar that went through the stop
+	exit, err = ReconcilePosition(req, trailBars(100, 101, 102, 96))
+	require.NoError(t, err)
+	require.NotNil(t, exit)
+	assert.Equal(t, ExitReasonExpiration, exit.Reason)
+
+	req.ExpirationDate = "2024-02-16"
+	exit, err = ReconcilePosition(req, trailBars(100, 101, 102, 103))
+	require.NoError(t, err)
+	assert.Nil(t, exit)
+}
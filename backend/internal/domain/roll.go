@@ -0,0 +1,283 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+// DefaultRollThresholdDTE is the DTE at which an option position is rolled or closed
+const DefaultRollThresholdDTE = 21
+
+// Time exit modes of an option position (as stored on the trade session)
+const (
+	TimeExitNone  = "None"  // Leave the position alone
+	TimeExitClose = "Close" // Close the position at the threshold
+	TimeExitRoll  = "Roll"  // Roll the expiring legs to the next expiration
+)
+
+// Roll plan actions
+const (
+	RollActionHold  = "HOLD"  // Not due, or time exits are off
+	RollActionClose = "CLOSE" // Close every leg
+	RollActionRoll  = "ROLL"  // Close the expiring legs and reopen them later
+)
+
+// RollRequest describes an open option position to check against its time exit
+type RollRequest struct {
+	Ticker       string         `json:"ticker"`
+	Legs         []options.Leg  `json:"legs"`          // Prices are the entry fills
+	ThresholdDTE int            `json:"threshold_dte"` // Default 21
+	Mode         string         `json:"mode"`          // None, Close or Roll (default Close)
+	CostBasis    float64        `json:"cost_basis"`    // Net debit including earlier rolls (default: the legs' net debit)
+	RiskDollars  float64        `json:"risk_dollars"`  // Kept as the risk when the new legs' loss is unlimited
+	EntryDate    string         `json:"entry_date,omitempty"`
+	EntrySpot    float64        `json:"entry_spot,omitempty"` // Underlying at entry, to solve each leg's IV from its fill
+	Market       options.Market `json:"market"`               // Spot and date the legs are valued at
+
+	ToExpiration string    `json:"to_expiration,omitempty"` // Default: the next monthly expiration
+	ClosePrices  []float64 `json:"close_prices,omitempty"`  // Fills for the closed legs (default: model value)
+	OpenPrices   []float64 `json:"open_prices,omitempty"`   // Fills for the new legs (default: model value)
+}
+
+// RollPlan is what the time exit asks for, priced
+type RollPlan struct {
+	Ticker         string `json:"ticker"`
+	Mode           string `json:"mode"`
+	Action         string `json:"action"` // HOLD, CLOSE or ROLL
+	Due            bool   `json:"due"`    // At or under the DTE threshold
+	DTE            int    `json:"dte"`
+	ThresholdDTE   int    `json:"threshold_dte"`
+	FromExpiration string `json:"from_expiration"`
+	ToExpiration   string `json:"to_expiration,omitempty"`
+
+	Closing []options.Leg `json:"closing,omitempty"`  // Legs closed, Price is the exit fill
+	Opening []options.Leg `json:"opening,omitempty"`  // Replacement legs, Price is the entry fill
+	NewLegs []options.Leg `json:"new_legs,omitempty"` // The position after a roll
+
+	CloseDebit  float64 `json:"close_debit"`  // Paid (negative: received) to close
+	OpenDebit   float64 `json:"open_debit"`   // Paid (negative: received) to open the replacements
+	RollDebit   float64 `json:"roll_debit"`   // CloseDebit + OpenDebit; negative is a net credit
+	RealizedPnL float64 `json:"realized_pnl"` // On the closed legs
+	CostBasis   float64 `json:"cost_basis"`   // Net debit of the whole trade after this step
+
+	Payoff  *options.Payoff `json:"payoff,omitempty"` // Of the new legs
+	NewRisk float64         `json:"new_risk"`         // Max loss of the new legs, for the heat check
+	Detail  string          `json:"detail"`
+}
+
+// NormalizeTimeExitMode canonicalizes a time exit mode (empty = Close)
+func NormalizeTimeExitMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "close":
+		return TimeExitClose, nil
+	case "roll":
+		return TimeExitRoll, nil
+	case "none":
+		return TimeExitNone, nil
+	default:
+		return "", fmt.Errorf("time exit mode must be None, Close or Roll, got '%s'", mode)
+	}
+}
+
+// PlanRoll checks an option position against its time exit and prices it
+//
+// The position is due when its nearest expiration is ThresholdDTE days or
+// fewer away. A due position in Close mode closes every leg; in Roll mode
+// the legs expiring first are closed and reopened at ToExpiration (the
+// next monthly expiration by default) while later legs are kept. Legs are
+// priced at ClosePrices/OpenPrices when given, otherwise with Black-Scholes
+// at Market.Vol, or at the IV solved from each leg's entry fill (with
+// EntrySpot on EntryDate) when no volatility is given. A replacement leg
+// uses the volatility of the leg it replaces.
+//
+// The roll's net debit carries into CostBasis. NewRisk is the new legs'
+// max loss, or RiskDollars when that loss is unlimited.
+func PlanRoll(req RollRequest) (*RollPlan, error) {
+	mode, err := NormalizeTimeExitMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	m := req.Market
+	if m.AsOf == "" {
+		m.AsOf = time.Now().Format("2006-01-02")
+	}
+	expiry := options.NearestExpiration(req.Legs)
+	if expiry == "" {
+		return nil, fmt.Errorf("%s: position has no option legs to roll", req.Ticker)
+	}
+	dte, err := options.DaysToExpiration(expiry, m.AsOf)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RollPlan{
+		Ticker:         req.Ticker,
+		Mode:           mode,
+		Action:         RollActionHold,
+		DTE:            dte,
+		ThresholdDTE:   req.ThresholdDTE,
+		FromExpiration: expiry,
+		CostBasis:      req.CostBasis,
+	}
+	if plan.ThresholdDTE <= 0 {
+		plan.ThresholdDTE = DefaultRollThresholdDTE
+	}
+	if plan.CostBasis == 0 {
+		plan.CostBasis = options.NetDebit(req.Legs)
+	}
+	plan.Due = dte <= plan.ThresholdDTE
+
+	switch {
+	case !plan.Due:
+		plan.Detail = fmt.Sprintf("%d DTE, time exit at %d DTE", dte, plan.ThresholdDTE)
+		return plan, nil
+	case mode == TimeExitNone:
+		plan.Detail = fmt.Sprintf("%d DTE (threshold %d), time exits are off", dte, plan.ThresholdDTE)
+		return plan, nil
+	case mode == TimeExitClose:
+		plan.Action = RollActionClose
+		plan.Closing = append([]options.Leg(nil), req.Legs...)
+	default:
+		plan.Action = RollActionRoll
+		plan.ToExpiration = req.ToExpiration
+		if plan.ToExpiration == "" {
+			if plan.ToExpiration, err = options.NextMonthlyExpiration(expiry); err != nil {
+				return nil, err
+			}
+		}
+		if plan.ToExpiration <= expiry {
+			return nil, fmt.Errorf("roll expiration %s must be after %s", plan.ToExpiration, expiry)
+		}
+		plan.Closing, plan.Opening, plan.NewLegs = options.RollLegs(req.Legs, plan.ToExpiration)
+	}
+
+	vols, err := legVols(req.Legs, req.EntrySpot, req.EntryDate, m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", req.Ticker, err)
+	}
+
+	closeVols := vols
+	if plan.Action == RollActionRoll {
+		closeVols = nil
+		for i, leg := range req.Legs {
+			if leg.Type != options.Stock && leg.Exp == expiry {
+				closeVols = append(closeVols, vols[i])
+			}
+		}
+	}
+	entries := make([]float64, len(plan.Closing))
+	for i, leg := range plan.Closing {
+		entries[i] = leg.Price
+	}
+	if err := fillLegs(plan.Closing, req.ClosePrices, closeVols, m, "close"); err != nil {
+		return nil, err
+	}
+	// Closing sells what was bought and buys back what was sold
+	for i, leg := range plan.Closing {
+		sign, err := options.ActionSign(leg.Action)
+		if err != nil {
+			return nil, err
+		}
+		shares := float64(leg.Qty) * options.LegMultiplier(leg)
+		plan.CloseDebit -= sign * leg.Price * shares
+		plan.RealizedPnL += sign * (leg.Price - entries[i]) * shares
+	}
+	plan.RollDebit = plan.CloseDebit
+
+	if plan.Action == RollActionClose {
+		plan.CostBasis += plan.CloseDebit
+		plan.Detail = fmt.Sprintf("%d DTE (threshold %d): close for %s, realized $%.2f",
+			dte, plan.ThresholdDTE, formatDebit(plan.CloseDebit), plan.RealizedPnL)
+		return plan, nil
+	}
+
+	if err := fillLegs(plan.Opening, req.OpenPrices, closeVols, m, "open"); err != nil {
+		return nil, err
+	}
+	plan.OpenDebit = options.NetDebit(plan.Opening)
+	plan.NewLegs = append(plan.NewLegs, plan.Opening...) // After the kept legs
+	plan.RollDebit += plan.OpenDebit
+	plan.CostBasis += plan.RollDebit
+
+	if plan.Payoff, err = options.AnalyzePayoff(plan.NewLegs, m); err != nil {
+		return nil, err
+	}
+	plan.NewRisk = plan.Payoff.MaxLoss
+	if plan.Payoff.UnlimitedLoss {
+		plan.NewRisk = req.RiskDollars
+	}
+
+	plan.Detail = fmt.Sprintf("%d DTE (threshold %d): roll %s → %s for %s, realized $%.2f",
+		dte, plan.ThresholdDTE, expiry, plan.ToExpiration, formatDebit(plan.RollDebit), plan.RealizedPnL)
+	return plan, nil
+}
+
+// legVols is the volatility of each leg: Market.Vol, or solved from the
+// leg's entry fill when the entry spot and date are known (0 otherwise, so
+// the leg's own price is used when it is valued)
+func legVols(legs []options.Leg, entrySpot float64, entryDate string, m options.Market) ([]float64, error) {
+	vols := make([]float64, len(legs))
+	for i, leg := range legs {
+		if leg.Type == options.Stock {
+			continue
+		}
+		if vols[i] = m.Vol; vols[i] > 0 || entrySpot <= 0 || entryDate == "" {
+			continue
+		}
+		priced, err := options.PriceLeg(leg, options.Market{
+			Spot:     entrySpot,
+			Rate:     m.Rate,
+			DivYield: m.DivYield,
+			AsOf:     entryDate,
+		})
+		if err != nil {
+			return nil, err
+		}
+		vols[i] = priced.IV
+	}
+	return vols, nil
+}
+
+// fillLegs sets each leg's Price to its fill, or to its model value
+func fillLegs(legs []options.Leg, fills, vols []float64, m options.Market, side string) error {
+	if len(fills) > 0 && len(fills) != len(legs) {
+		return fmt.Errorf("%d %s prices given for %d legs", len(fills), side, len(legs))
+	}
+	for i := range legs {
+		if len(fills) > 0 {
+			legs[i].Price = fills[i]
+			continue
+		}
+		if legs[i].Type == options.Stock {
+			legs[i].Price = m.Spot
+			continue
+		}
+		if m.Spot <= 0 {
+			return fmt.Errorf("an underlying price is needed to value the legs (or give the %s prices)", side)
+		}
+		market := m
+		if i < len(vols) && vols[i] > 0 {
+			market.Vol = vols[i]
+		} else if market.Vol <= 0 && side == "open" {
+			return fmt.Errorf("a volatility is needed to price the new legs (or give the open prices)")
+		}
+		priced, err := options.PriceLeg(legs[i], market)
+		if err != nil {
+			return err
+		}
+		legs[i].Price = math.Round(priced.PerShare.Price*100) / 100
+	}
+	return nil
+}
+
+// formatDebit shows a net debit as "$1.20 debit" or "$0.80 credit"
+func formatDebit(debit float64) string {
+	if debit < 0 {
+		return fmt.Sprintf("$%.2f credit", -debit)
+	}
+	return fmt.Sprintf("$%.2f debit", debit)
+}
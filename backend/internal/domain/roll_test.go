@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+// putSpreadRoll is a 95/90 bull put spread opened for a $1.20 credit,
+// 21 days before its 2025-01-17 expiration
+func putSpreadRoll() RollRequest {
+	return RollRequest{
+		Ticker: "AAPL",
+		Legs: []options.Leg{
+			{Type: "PUT", Strike: 95, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.00},
+			{Type: "PUT", Strike: 90, Exp: "2025-01-17", Qty: 1, Action: "BUY", Price: 0.80},
+		},
+		ThresholdDTE: 21,
+		Mode:         TimeExitRoll,
+		RiskDollars:  380,
+		Market:       options.Market{Spot: 100, Rate: 0.04, AsOf: "2024-12-27"},
+		ClosePrices:  []float64{0.60, 0.15},
+		OpenPrices:   []float64{1.90, 0.70},
+	}
+}
+
+func TestPlanRoll_RollCarriesCreditIntoCostBasis(t *testing.T) {
+	plan, err := PlanRoll(putSpreadRoll())
+	require.NoError(t, err)
+
+	assert.Equal(t, RollActionRoll, plan.Action)
+	assert.True(t, plan.Due)
+	assert.Equal(t, 21, plan.DTE)
+	assert.Equal(t, "2025-01-17", plan.FromExpiration)
+	assert.Equal(t, "2025-02-21", plan.ToExpiration) // Next monthly
+
+	assert.InDelta(t, 45, plan.CloseDebit, 1e-9)  // Buy back 0.60, sell 0.15
+	assert.InDelta(t, -120, plan.OpenDebit, 1e-9) // Sell 1.90, buy 0.70
+	assert.InDelta(t, -75, plan.RollDebit, 1e-9)  // Net credit
+	assert.InDelta(t, 75, plan.RealizedPnL, 1e-9) // 140 on the short, -65 on the long
+	assert.InDelta(t, -195, plan.CostBasis, 1e-9) // -120 at entry, -75 on the roll
+
+	require.Len(t, plan.NewLegs, 2)
+	for _, leg := range plan.NewLegs {
+		assert.Equal(t, "2025-02-21", leg.Exp)
+	}
+	assert.InDelta(t, 1.90, plan.NewLegs[0].Price, 1e-9)
+	assert.InDelta(t, 380, plan.NewRisk, 1e-9) // (5 - 1.20) × 100
+}
+
+func TestPlanRoll_NotDue(t *testing.T) {
+	req := putSpreadRoll()
+	req.Market.AsOf = "2024-12-20"
+
+	plan, err := PlanRoll(req)
+	require.NoError(t, err)
+
+	assert.False(t, plan.Due)
+	assert.Equal(t, RollActionHold, plan.Action)
+	assert.Equal(t, 28, plan.DTE)
+	assert.Empty(t, plan.Closing)
+}
+
+func TestPlanRoll_ModeNoneHolds(t *testing.T) {
+	req := putSpreadRoll()
+	req.Mode = "none"
+
+	plan, err := PlanRoll(req)
+	require.NoError(t, err)
+
+	assert.True(t, plan.Due)
+	assert.Equal(t, TimeExitNone, plan.Mode)
+	assert.Equal(t, RollActionHold, plan.Action)
+}
+
+func TestPlanRoll_CloseMode(t *testing.T) {
+	req := putSpreadRoll()
+	req.Mode = ""
+
+	plan, err := PlanRoll(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, TimeExitClose, plan.Mode)
+	assert.Equal(t, RollActionClose, plan.Action)
+	assert.Len(t, plan.Closing, 2)
+	assert.Empty(t, plan.Opening)
+	assert.InDelta(t, 45, plan.CloseDebit, 1e-9)
+	assert.InDelta(t, 75, plan.RealizedPnL, 1e-9)
+	assert.InDelta(t, -75, plan.CostBasis, 1e-9) // Total P&L is -CostBasis
+}
+
+func TestPlanRoll_ModelPricesWithVolatility(t *testing.T) {
+	req := putSpreadRoll()
+	req.ClosePrices, req.OpenPrices = nil, nil
+	req.Market.Vol = 0.25
+
+	plan, err := PlanRoll(req)
+	require.NoError(t, err)
+
+	// The replacement has more time left, so is worth more than the leg it replaces
+	for i := range plan.Closing {
+		assert.Greater(t, plan.Closing[i].Price, 0.0)
+		assert.Greater(t, plan.Opening[i].Price, plan.Closing[i].Price)
+	}
+	assert.Less(t, plan.OpenDebit, 0.0) // Still a credit spread
+}
+
+func TestPlanRoll_CalendarKeepsFarLeg(t *testing.T) {
+	req := RollRequest{
+		Ticker: "MSFT",
+		Legs: []options.Leg{
+			{Type: "CALL", Strike: 100, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.10},
+			{Type: "CALL", Strike: 100, Exp: "2025-03-21", Qty: 1, Action: "BUY", Price: 4.60},
+		},
+		Mode:         TimeExitRoll,
+		ToExpiration: "2025-02-21",
+		Market:       options.Market{Spot: 100, Rate: 0.04, AsOf: "2025-01-03"},
+		ClosePrices:  []float64{0.90},
+		OpenPrices:   []float64{2.20},
+	}
+
+	plan, err := PlanRoll(req)
+	require.NoError(t, err)
+
+	require.Len(t, plan.NewLegs, 2)
+	assert.Equal(t, req.Legs[1], plan.NewLegs[0]) // Far leg kept at its entry fill
+	assert.Equal(t, "2025-02-21", plan.NewLegs[1].Exp)
+	assert.InDelta(t, 120, plan.RealizedPnL, 1e-9) // Short call: 2.10 → 0.90
+	assert.InDelta(t, -130, plan.RollDebit, 1e-9)  // Pay 0.90, collect 2.20
+	assert.InDelta(t, 120, plan.CostBasis, 1e-9)   // 250 debit at entry
+}
+
+func TestPlanRoll_Errors(t *testing.T) {
+	req := putSpreadRoll()
+	req.Mode = "sometimes"
+	_, err := PlanRoll(req)
+	assert.Error(t, err)
+
+	req = putSpreadRoll()
+	req.OpenPrices = []float64{1.90}
+	_, err = PlanRoll(req)
+	assert.Error(t, err)
+
+	req = putSpreadRoll()
+	req.ToExpiration = "2025-01-10"
+	_, err = PlanRoll(req)
+	assert.Error(t, err)
+
+	req = putSpreadRoll()
+	req.Legs = nil
+	_, err = PlanRoll(req)
+	assert.Error(t, err)
+}
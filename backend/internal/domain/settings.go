@@ -2,6 +2,7 @@ package domain
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 )
 
@@ -14,6 +15,31 @@ const (
 	SettingHeatCap       SettingKey = "HeatCap_H_pct"
 	SettingBucketHeatCap SettingKey = "BucketHeatCap_pct"
 	SettingStopMultiple  SettingKey = "StopMultiple_K"
+
+	// GREEKS heat caps (see GreeksHeatCaps); 0 turns a cap off
+	SettingDeltaHeatCap       SettingKey = "DeltaHeatCap_pct"
+	SettingBucketDeltaHeatCap SettingKey = "BucketDeltaHeatCap_pct"
+	SettingGammaHeatCap       SettingKey = "GammaHeatCap_pct"
+	SettingBucketGammaHeatCap SettingKey = "BucketGammaHeatCap_pct"
+	SettingVegaHeatCap        SettingKey = "VegaHeatCap_pct"
+	SettingBucketVegaHeatCap  SettingKey = "BucketVegaHeatCap_pct"
+	SettingHeatGreeks         SettingKey = "HeatGreeks" // 1 = the GO decision heat gate checks them too
+
+	// Correlated-cluster heat (see CorrelationMatrix.Clusters); 0 turns the cap off
+	SettingClusterHeatCap       SettingKey = "ClusterHeatCap_pct"
+	SettingCorrelationThreshold SettingKey = "CorrelationThreshold"
+
+	// Turtle unit limits (see UnitLimits); 0 turns a limit off
+	SettingUnitLimitMarket    SettingKey = "UnitLimit_Market"
+	SettingUnitLimitClosely   SettingKey = "UnitLimit_Closely"
+	SettingUnitLimitLoosely   SettingKey = "UnitLimit_Loosely"
+	SettingUnitLimitDirection SettingKey = "UnitLimit_Direction"
+
+	// Equity tracking (see TrackEquity); StartingEquity 0 keeps Equity_E as entered
+	SettingStartingEquity     SettingKey = "StartingEquity"
+	SettingDrawdownStep       SettingKey = "DrawdownStep_pct"
+	SettingDrawdownCut        SettingKey = "DrawdownCut_pct"
+	SettingEquityMarkToMarket SettingKey = "EquityMarkToMarket"
 )
 
 // ValidSettingKeys lists all valid setting keys
@@ -23,18 +49,43 @@ var ValidSettingKeys = []SettingKey{
 	SettingHeatCap,
 	SettingBucketHeatCap,
 	SettingStopMultiple,
+	SettingDeltaHeatCap,
+	SettingBucketDeltaHeatCap,
+	SettingGammaHeatCap,
+	SettingBucketGammaHeatCap,
+	SettingVegaHeatCap,
+	SettingBucketVegaHeatCap,
+	SettingHeatGreeks,
+	SettingClusterHeatCap,
+	SettingCorrelationThreshold,
+	SettingUnitLimitMarket,
+	SettingUnitLimitClosely,
+	SettingUnitLimitLoosely,
+	SettingUnitLimitDirection,
+	SettingStartingEquity,
+	SettingDrawdownStep,
+	SettingDrawdownCut,
+	SettingEquityMarkToMarket,
 }
 
 // ValidateSetting validates a setting key and value
 //
 // Validation Rules:
-//   - Key must be one of the 5 valid settings
+//   - Key must be one of the valid settings
 //   - Value must be numeric
 //   - Equity_E must be positive
 //   - RiskPct_r must be between 0 and 1
 //   - HeatCap_H_pct must be between 0 and 1
 //   - BucketHeatCap_pct must be between 0 and 1
 //   - StopMultiple_K must be positive
+//   - Delta, gamma and vega heat caps must not be negative (0 = no cap)
+//   - HeatGreeks must be 0 or 1
+//   - ClusterHeatCap_pct must be between 0 and 1 (0 = no cap)
+//   - CorrelationThreshold must be between 0 and 1
+//   - Unit limits must be whole numbers, not negative (0 = no limit)
+//   - StartingEquity must not be negative (0 = use Equity_E as entered)
+//   - DrawdownStep_pct and DrawdownCut_pct must be between 0 and 1 (step 0 = no scaling)
+//   - EquityMarkToMarket must be 0 or 1
 func ValidateSetting(key, value string) error {
 	// Check if key is valid
 	valid := false
@@ -80,6 +131,49 @@ func ValidateSetting(key, value string) error {
 		if floatVal <= 0 {
 			return fmt.Errorf("StopMultiple_K must be positive, got %.2f", floatVal)
 		}
+
+	case SettingDeltaHeatCap, SettingBucketDeltaHeatCap,
+		SettingGammaHeatCap, SettingBucketGammaHeatCap,
+		SettingVegaHeatCap, SettingBucketVegaHeatCap:
+		if floatVal < 0 {
+			return fmt.Errorf("%s must not be negative, got %.4f", key, floatVal)
+		}
+
+	case SettingHeatGreeks:
+		if floatVal != 0 && floatVal != 1 {
+			return fmt.Errorf("HeatGreeks must be 0 or 1, got %s", value)
+		}
+
+	case SettingClusterHeatCap:
+		if floatVal < 0 || floatVal > 1 {
+			return fmt.Errorf("ClusterHeatCap_pct must be between 0 and 1, got %.4f", floatVal)
+		}
+
+	case SettingCorrelationThreshold:
+		if floatVal <= 0 || floatVal > 1 {
+			return fmt.Errorf("CorrelationThreshold must be between 0 and 1, got %.4f", floatVal)
+		}
+
+	case SettingUnitLimitMarket, SettingUnitLimitClosely,
+		SettingUnitLimitLoosely, SettingUnitLimitDirection:
+		if floatVal < 0 || floatVal != math.Trunc(floatVal) {
+			return fmt.Errorf("%s must be a whole number of units, got %s", key, value)
+		}
+
+	case SettingStartingEquity:
+		if floatVal < 0 {
+			return fmt.Errorf("StartingEquity must not be negative, got %.2f", floatVal)
+		}
+
+	case SettingDrawdownStep, SettingDrawdownCut:
+		if floatVal < 0 || floatVal >= 1 {
+			return fmt.Errorf("%s must be between 0 and 1, got %.4f", key, floatVal)
+		}
+
+	case SettingEquityMarkToMarket:
+		if floatVal != 0 && floatVal != 1 {
+			return fmt.Errorf("EquityMarkToMarket must be 0 or 1, got %s", value)
+		}
 	}
 
 	return nil
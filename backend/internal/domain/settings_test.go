@@ -129,3 +129,45 @@ func TestValidateSetting_AllValidKeys(t *testing.T) {
 		assert.NoError(t, err, "Key %s should be valid", key)
 	}
 }
+
+func TestValidateSetting_GreeksHeatCaps(t *testing.T) {
+	assert.NoError(t, ValidateSetting("DeltaHeatCap_pct", "1.5"))
+	assert.NoError(t, ValidateSetting("BucketVegaHeatCap_pct", "0"))
+	assert.NoError(t, ValidateSetting("HeatGreeks", "1"))
+	assert.Error(t, ValidateSetting("HeatGreeks", "2"))
+
+	err := ValidateSetting("GammaHeatCap_pct", "-0.1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must not be negative")
+}
+
+func TestValidateSetting_Correlation(t *testing.T) {
+	assert.NoError(t, ValidateSetting("ClusterHeatCap_pct", "0.02"))
+	assert.NoError(t, ValidateSetting("ClusterHeatCap_pct", "0"))
+	assert.NoError(t, ValidateSetting("CorrelationThreshold", "0.7"))
+
+	assert.Error(t, ValidateSetting("ClusterHeatCap_pct", "1.5"))
+	assert.Error(t, ValidateSetting("CorrelationThreshold", "0"))
+}
+
+func TestValidateSetting_UnitLimits(t *testing.T) {
+	assert.NoError(t, ValidateSetting("UnitLimit_Market", "4"))
+	assert.NoError(t, ValidateSetting("UnitLimit_Direction", "0"))
+
+	err := ValidateSetting("UnitLimit_Closely", "6.5")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "whole number")
+	assert.Error(t, ValidateSetting("UnitLimit_Loosely", "-1"))
+}
+
+func TestValidateSetting_EquityTracking(t *testing.T) {
+	assert.NoError(t, ValidateSetting("StartingEquity", "100000"))
+	assert.NoError(t, ValidateSetting("StartingEquity", "0"))
+	assert.NoError(t, ValidateSetting("DrawdownStep_pct", "0.10"))
+	assert.NoError(t, ValidateSetting("DrawdownCut_pct", "0.20"))
+	assert.NoError(t, ValidateSetting("EquityMarkToMarket", "1"))
+
+	assert.Error(t, ValidateSetting("StartingEquity", "-5"))
+	assert.Error(t, ValidateSetting("DrawdownCut_pct", "1"))
+	assert.Error(t, ValidateSetting("EquityMarkToMarket", "0.5"))
+}
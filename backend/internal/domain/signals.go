@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Breakout system names (match storage.SystemOne / storage.SystemTwo)
+const (
+	BreakoutSystemOne = "SYSTEM_1" // 20-bar breakout, 10-bar exit
+	BreakoutSystemTwo = "SYSTEM_2" // 55-bar breakout, 10-bar exit (DEFAULT)
+)
+
+// Signal directions
+const (
+	SignalLong  = "LONG"
+	SignalShort = "SHORT"
+	SignalNone  = "NONE"
+)
+
+// BreakoutParams holds the Donchian lookbacks for a breakout system
+type BreakoutParams struct {
+	System        string `json:"system"`
+	EntryLookback int    `json:"entry_lookback"`
+	ExitLookback  int    `json:"exit_lookback"`
+}
+
+// BreakoutSignal is the result of checking one ticker for a Donchian breakout
+type BreakoutSignal struct {
+	Ticker        string  `json:"ticker"`
+	Date          string  `json:"date"`      // Date of the bar that was checked
+	Direction     string  `json:"direction"` // LONG, SHORT, NONE
+	Triggered     bool    `json:"triggered"`
+	Close         float64 `json:"close"`
+	System        string  `json:"system"`
+	EntryLookback int     `json:"entry_lookback"`
+	ExitLookback  int     `json:"exit_lookback"`
+	ChannelHigh   float64 `json:"channel_high"` // Highest high of prior EntryLookback bars
+	ChannelLow    float64 `json:"channel_low"`  // Lowest low of prior EntryLookback bars
+	ExitLevel     float64 `json:"exit_level"`   // Donchian exit (ExitLookback low for longs, high for shorts)
+	ATR           float64 `json:"atr_n,omitempty"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// GetBreakoutParams returns the lookbacks for a named breakout system
+// An empty name selects System 2 (the default)
+func GetBreakoutParams(system string) (BreakoutParams, error) {
+	switch strings.ToUpper(strings.TrimSpace(system)) {
+	case BreakoutSystemOne, "1", "S1":
+		return BreakoutParams{System: BreakoutSystemOne, EntryLookback: 20, ExitLookback: 10}, nil
+	case BreakoutSystemTwo, "2", "S2", "":
+		return BreakoutParams{System: BreakoutSystemTwo, EntryLookback: 55, ExitLookback: 10}, nil
+	default:
+		return BreakoutParams{}, fmt.Errorf("unknown breakout system %q (use SYSTEM_1 or SYSTEM_2)", system)
+	}
+}
+
+// DonchianChannel returns the highest high and lowest low of the `lookback`
+// bars immediately before index `at` (the bar at `at` is excluded)
+func DonchianChannel(bars []Bar, at, lookback int) (high, low float64, err error) {
+	if lookback <= 0 {
+		return 0, 0, fmt.Errorf("lookback must be positive, got %d", lookback)
+	}
+	if at < lookback || at > len(bars) {
+		return 0, 0, fmt.Errorf("%w: need %d prior bars, got %d", ErrInsufficientBars, lookback, at)
+	}
+
+	high = bars[at-lookback].High
+	low = bars[at-lookback].Low
+	for _, b := range bars[at-lookback+1 : at] {
+		if b.High > high {
+			high = b.High
+		}
+		if b.Low < low {
+			low = b.Low
+		}
+	}
+	return high, low, nil
+}
+
+// DetectBreakout checks whether the latest bar closed through the Donchian channel
+//
+// Turtle breakout rules:
+//   - LONG when the close is above the highest high of the prior EntryLookback bars
+//   - SHORT when the close is below the lowest low of the prior EntryLookback bars
+//   - Exit level is the ExitLookback low (longs) or high (shorts), including
+//     the signal bar, i.e. the level price must not trade through tomorrow
+//
+// Bars must be sorted oldest first. A non-triggered signal still carries
+// the channel levels so callers can see how far price is from a breakout.
+func DetectBreakout(ticker string, bars []Bar, params BreakoutParams) (*BreakoutSignal, error) {
+	if params.ExitLookback <= 0 {
+		return nil, fmt.Errorf("exit lookback must be positive, got %d", params.ExitLookback)
+	}
+
+	last := len(bars) - 1
+	high, low, err := DonchianChannel(bars, last, params.EntryLookback)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ticker, err)
+	}
+
+	bar := bars[last]
+	signal := &BreakoutSignal{
+		Ticker:        ticker,
+		Date:          bar.Date,
+		Direction:     SignalNone,
+		Close:         bar.Close,
+		System:        params.System,
+		EntryLookback: params.EntryLookback,
+		ExitLookback:  params.ExitLookback,
+		ChannelHigh:   high,
+		ChannelLow:    low,
+	}
+
+	switch {
+	case bar.Close > high:
+		signal.Direction = SignalLong
+		signal.Triggered = true
+	case bar.Close < low:
+		signal.Direction = SignalShort
+		signal.Triggered = true
+	}
+
+	// Exit channel includes the signal bar
+	exitHigh, exitLow, err := DonchianChannel(bars, len(bars), min(params.ExitLookback, len(bars)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ticker, err)
+	}
+	if signal.Direction == SignalShort {
+		signal.ExitLevel = exitHigh
+	} else {
+		signal.ExitLevel = exitLow
+	}
+
+	// Attach N when there is enough history; sizing can use it directly
+	if atr, err := CalculateATR(bars, DefaultATRPeriod); err == nil {
+		signal.ATR = atr.ATR
+	}
+
+	return signal, nil
+}
+
+// ScanBreakouts runs DetectBreakout for each ticker using stored bars
+// Tickers that cannot be evaluated are returned with Error set rather than
+// aborting the scan, so one missing file does not hide every other signal.
+func ScanBreakouts(provider BarProvider, tickers []string, params BreakoutParams) []BreakoutSignal {
+	signals := make([]BreakoutSignal, 0, len(tickers))
+	for _, ticker := range tickers {
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" {
+			continue
+		}
+
+		bars, err := provider.GetDailyBars(ticker)
+		if err == nil {
+			var signal *BreakoutSignal
+			signal, err = DetectBreakout(ticker, bars, params)
+			if err == nil {
+				signals = append(signals, *signal)
+				continue
+			}
+		}
+
+		signals = append(signals, BreakoutSignal{
+			Ticker:        ticker,
+			Direction:     SignalNone,
+			System:        params.System,
+			EntryLookback: params.EntryLookback,
+			ExitLookback:  params.ExitLookback,
+			Error:         err.Error(),
+		})
+	}
+	return signals
+}
+
+// ApplyBreakoutToChecklist ticks TrendPass when the signal breaks out in the
+// checklist's direction: an upside break for a long (the default), a
+// downside break for a short. A break the other way does not count.
+// Returns true if TrendPass was set by the signal.
+func ApplyBreakoutToChecklist(req *ChecklistRequest, signal *BreakoutSignal) bool {
+	direction, err := NormalizeDirection(req.Direction)
+	if err != nil || signal == nil || !signal.Triggered || signal.Direction != direction {
+		return false
+	}
+	req.TrendPass = true
+	return true
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeBars builds n bars trading between 99 and 101, then appends the given closes
+func rangeBars(n int, closes ...float64) []Bar {
+	bars := flatBars(n)
+	for i, c := range closes {
+		bars = append(bars, Bar{
+			Date:  fmt.Sprintf("2025-02-%02d", i+1),
+			Open:  c,
+			High:  c + 0.5,
+			Low:   c - 0.5,
+			Close: c,
+		})
+	}
+	return bars
+}
+
+func TestGetBreakoutParams(t *testing.T) {
+	s1, err := GetBreakoutParams("SYSTEM_1")
+	require.NoError(t, err)
+	assert.Equal(t, 20, s1.EntryLookback)
+	assert.Equal(t, 10, s1.ExitLookback)
+
+	s2, err := GetBreakoutParams("")
+	require.NoError(t, err)
+	assert.Equal(t, BreakoutSystemTwo, s2.System)
+	assert.Equal(t, 55, s2.EntryLookback)
+
+	_, err = GetBreakoutParams("SYSTEM_3")
+	assert.Error(t, err)
+}
+
+func TestDonchianChannel_ExcludesCurrentBar(t *testing.T) {
+	bars := rangeBars(20, 110)
+
+	high, low, err := DonchianChannel(bars, len(bars)-1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 101.0, high)
+	assert.Equal(t, 99.0, low)
+
+	_, _, err = DonchianChannel(bars, 5, 20)
+	assert.True(t, errors.Is(err, ErrInsufficientBars))
+}
+
+func TestDetectBreakout_Long(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	bars := rangeBars(25, 102)
+
+	signal, err := DetectBreakout("AAPL", bars, params)
+	require.NoError(t, err)
+
+	assert.True(t, signal.Triggered)
+	assert.Equal(t, SignalLong, signal.Direction)
+	assert.Equal(t, "2025-02-01", signal.Date)
+	assert.Equal(t, 101.0, signal.ChannelHigh)
+	// 10-bar exit low includes the flat range
+	assert.Equal(t, 99.0, signal.ExitLevel)
+	assert.Greater(t, signal.ATR, 0.0)
+}
+
+func TestDetectBreakout_Short(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	bars := rangeBars(25, 98)
+
+	signal, err := DetectBreakout("AAPL", bars, params)
+	require.NoError(t, err)
+
+	assert.True(t, signal.Triggered)
+	assert.Equal(t, SignalShort, signal.Direction)
+	assert.Equal(t, 101.0, signal.ExitLevel)
+}
+
+func TestDetectBreakout_NoSignalInsideChannel(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	// Touching the high intraday is not enough: the close must break it
+	bars := rangeBars(25)
+	bars = append(bars, Bar{Date: "2025-02-01", Open: 100, High: 101.5, Low: 99.5, Close: 100.8})
+
+	signal, err := DetectBreakout("AAPL", bars, params)
+	require.NoError(t, err)
+	assert.False(t, signal.Triggered)
+	assert.Equal(t, SignalNone, signal.Direction)
+}
+
+func TestDetectBreakout_InsufficientBars(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemTwo)
+	_, err := DetectBreakout("AAPL", rangeBars(30, 105), params)
+	assert.True(t, errors.Is(err, ErrInsufficientBars))
+}
+
+type mapBarProvider map[string][]Bar
+
+func (m mapBarProvider) GetDailyBars(ticker string) ([]Bar, error) {
+	bars, ok := m[ticker]
+	if !ok {
+		return nil, fmt.Errorf("no bars for %s", ticker)
+	}
+	return bars, nil
+}
+
+func TestScanBreakouts(t *testing.T) {
+	params, _ := GetBreakoutParams(BreakoutSystemOne)
+	provider := mapBarProvider{
+		"AAPL": rangeBars(25, 102),
+		"MSFT": rangeBars(25, 100),
+	}
+
+	signals := ScanBreakouts(provider, []string{"aapl", "MSFT", "NVDA", " "}, params)
+	require.Len(t, signals, 3)
+
+	assert.True(t, signals[0].Triggered)
+	assert.False(t, signals[1].Triggered)
+	assert.Equal(t, "NVDA", signals[2].Ticker)
+	assert.NotEmpty(t, signals[2].Error)
+}
+
+func TestApplyBreakoutToChecklist(t *testing.T) {
+	req := ChecklistRequest{Ticker: "AAPL"}
+
+	assert.False(t, ApplyBreakoutToChecklist(&req, nil))
+	assert.False(t, ApplyBreakoutToChecklist(&req, &BreakoutSignal{Triggered: true, Direction: SignalShort}))
+	assert.False(t, req.TrendPass)
+
+	assert.True(t, ApplyBreakoutToChecklist(&req, &BreakoutSignal{Triggered: true, Direction: SignalLong}))
+	assert.True(t, req.TrendPass)
+}
+
+func TestApplyBreakoutToChecklist_Short(t *testing.T) {
+	req := ChecklistRequest{Ticker: "AAPL", Direction: DirectionShort}
+
+	assert.False(t, ApplyBreakoutToChecklist(&req, &BreakoutSignal{Triggered: true, Direction: SignalLong}))
+	assert.False(t, req.TrendPass)
+
+	assert.True(t, ApplyBreakoutToChecklist(&req, &BreakoutSignal{Triggered: true, Direction: SignalShort}))
+	assert.True(t, req.TrendPass)
+}
@@ -3,18 +3,28 @@ package domain
 import (
 	"errors"
 	"fmt"
+
+	"github.com/yourusername/trading-engine/internal/options"
 )
 
 // SizingRequest contains input parameters for position sizing
 type SizingRequest struct {
+	Ticker    string  `json:"ticker,omitempty"`    // Used to look up stored N when ATR is omitted
+	Direction string  `json:"direction,omitempty"` // LONG (default) or SHORT; sets the side of the stop
 	Equity    float64 `json:"equity"`
 	RiskPct   float64 `json:"risk_pct"`
 	Entry     float64 `json:"entry"`
 	ATR       float64 `json:"atr_n"`
-	K         int     `json:"k"`
+	K         float64 `json:"k"`
 	Method    string  `json:"method"` // "stock", "opt-delta-atr", "opt-maxloss"
 	Delta     float64 `json:"delta,omitempty"`
 	MaxLoss   float64 `json:"max_loss,omitempty"`
+	ATRDate   string  `json:"atr_date,omitempty"` // Bar date of stored N (empty when entered by hand)
+
+	// Option leg for opt-delta-atr: when Delta is 0 it is taken from the
+	// leg's Black-Scholes delta (spot defaults to Entry)
+	Leg     *options.Leg   `json:"leg,omitempty"`
+	Pricing options.Market `json:"pricing"`
 }
 
 // SizingResult contains calculated position sizing
@@ -26,6 +36,11 @@ type SizingResult struct {
 	Contracts    int     `json:"contracts"`
 	ActualRisk   float64 `json:"actual_risk"`
 	Method       string  `json:"method"`
+	Direction    string  `json:"direction"`
+	ATR          float64 `json:"atr_n,omitempty"`
+	ATRDate      string  `json:"atr_date,omitempty"`
+	Delta        float64 `json:"delta,omitempty"` // Delta used by opt-delta-atr
+	IV           float64 `json:"iv,omitempty"`    // Volatility of the leg when Delta came from it
 }
 
 // Validation errors
@@ -55,21 +70,43 @@ func validateCommonInputs(req SizingRequest) error {
 		return fmt.Errorf("%w: got %.2f", ErrInvalidATR, req.ATR)
 	}
 	if req.K <= 0 {
-		return fmt.Errorf("%w: got %d", ErrInvalidK, req.K)
+		return fmt.Errorf("%w: got %.2f", ErrInvalidK, req.K)
+	}
+	if _, err := NormalizeDirection(req.Direction); err != nil {
+		return err
 	}
 	return nil
 }
 
 // CalculatePositionSize routes to the appropriate sizing method
 func CalculatePositionSize(req SizingRequest) (*SizingResult, error) {
+	var result *SizingResult
+	direction, err := NormalizeDirection(req.Direction)
+	if err != nil {
+		return nil, err
+	}
+
 	switch req.Method {
 	case "stock":
-		return CalculateStockPosition(req)
+		result, err = CalculateStockPosition(req)
 	case "opt-delta-atr":
-		return CalculateOptionDeltaATRPosition(req)
+		result, err = CalculateOptionDeltaATRPosition(req)
 	case "opt-maxloss":
-		return CalculateOptionMaxLossPosition(req)
+		result, err = CalculateOptionMaxLossPosition(req)
 	default:
 		return nil, fmt.Errorf("%w: got '%s'", ErrInvalidMethod, req.Method)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Direction = direction
+
+	// Carry N provenance through so callers can record it
+	if req.Method != "opt-maxloss" {
+		result.ATR = req.ATR
+		result.ATRDate = req.ATRDate
+	}
+
+	return result, nil
 }
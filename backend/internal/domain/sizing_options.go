@@ -3,8 +3,35 @@ package domain
 import (
 	"fmt"
 	"math"
+
+	"github.com/yourusername/trading-engine/internal/options"
 )
 
+// ResolveSizingDelta fills SizingRequest.Delta from the selected option leg
+//
+// A manually supplied delta always wins, and only opt-delta-atr sizing uses
+// delta, so both cases (and a request without a leg) return nil. Otherwise
+// the leg is priced with Black-Scholes, solving IV from the leg's price
+// unless req.Pricing.Vol is set, and the size of its delta is used: puts
+// and calls size the same way.
+func ResolveSizingDelta(req *SizingRequest) (*options.LegGreeks, error) {
+	if req.Delta != 0 || req.Method != "opt-delta-atr" || req.Leg == nil {
+		return nil, nil
+	}
+
+	market := req.Pricing
+	if market.Spot <= 0 {
+		market.Spot = req.Entry
+	}
+	leg, err := options.PriceLeg(*req.Leg, market)
+	if err != nil {
+		return nil, fmt.Errorf("failed to price option leg: %w", err)
+	}
+
+	req.Delta = math.Abs(leg.PerShare.Delta)
+	return leg, nil
+}
+
 // CalculateOptionDeltaATRPosition implements position sizing for options using delta-adjusted ATR
 //
 // The Delta-ATR method sizes options positions to risk the same dollar amount
@@ -32,6 +59,12 @@ func CalculateOptionDeltaATRPosition(req SizingRequest) (*SizingResult, error) {
 		return nil, err
 	}
 
+	// Derive delta from the selected leg when it was not given
+	leg, err := ResolveSizingDelta(&req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate delta
 	if req.Delta <= 0 || req.Delta > 1 {
 		return nil, fmt.Errorf("%w: got %.2f", ErrInvalidDelta, req.Delta)
@@ -41,10 +74,11 @@ func CalculateOptionDeltaATRPosition(req SizingRequest) (*SizingResult, error) {
 	riskDollars := req.Equity * req.RiskPct
 
 	// Step 2: Calculate stop distance
-	stopDistance := float64(req.K) * req.ATR
+	stopDistance := req.K * req.ATR
 
-	// Step 3: Calculate initial stop (for underlying)
-	initialStop := req.Entry - stopDistance
+	// Step 3: Calculate initial stop (for underlying, above entry for a short)
+	direction, _ := NormalizeDirection(req.Direction)
+	initialStop := StopFor(direction, req.Entry, stopDistance)
 
 	// Step 4: Calculate stock-equivalent shares
 	stockShares := math.Floor(riskDollars / stopDistance)
@@ -59,7 +93,7 @@ func CalculateOptionDeltaATRPosition(req SizingRequest) (*SizingResult, error) {
 	// ActualRisk = contracts × 100 × (K × ATR × delta)
 	actualRisk := float64(contracts) * 100 * stopDistance * req.Delta
 
-	return &SizingResult{
+	result := &SizingResult{
 		RiskDollars:  riskDollars,
 		StopDistance: stopDistance,
 		InitialStop:  initialStop,
@@ -67,7 +101,13 @@ func CalculateOptionDeltaATRPosition(req SizingRequest) (*SizingResult, error) {
 		Contracts:    contracts,
 		ActualRisk:   actualRisk,
 		Method:       "opt-delta-atr",
-	}, nil
+		Direction:    direction,
+		Delta:        req.Delta,
+	}
+	if leg != nil {
+		result.IV = leg.IV
+	}
+	return result, nil
 }
 
 // CalculateOptionMaxLossPosition implements position sizing for options using max loss method
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yourusername/trading-engine/internal/options"
 )
 
 func TestCalculateOptionDeltaATRPosition_LowDelta(t *testing.T) {
@@ -56,6 +57,25 @@ func TestCalculateOptionDeltaATRPosition_MediumDelta(t *testing.T) {
 	assert.Equal(t, 0.0, result.ActualRisk)
 }
 
+func TestCalculateOptionDeltaATRPosition_ShortUnderlyingStop(t *testing.T) {
+	// A bearish (put) position stops out when the underlying rallies K × N
+	req := SizingRequest{
+		Direction: DirectionShort,
+		Equity:    10000,
+		RiskPct:   0.0075,
+		Entry:     50,
+		ATR:       1.0,
+		K:         2,
+		Method:    "opt-delta-atr",
+		Delta:     0.70,
+	}
+
+	result, err := CalculateOptionDeltaATRPosition(req)
+	require.NoError(t, err)
+	assert.Equal(t, 52.0, result.InitialStop)
+	assert.Equal(t, DirectionShort, result.Direction)
+}
+
 func TestCalculateOptionDeltaATRPosition_HighDelta80(t *testing.T) {
 	// High delta (0.80) with larger ATR yields contracts
 	// StockShares = floor(75 / 4) = 18
@@ -238,6 +258,66 @@ func TestCalculateOptionDeltaATRPosition_ActualRiskNeverExceedsTarget(t *testing
 	}
 }
 
+func TestCalculateOptionDeltaATRPosition_DeltaFromLeg(t *testing.T) {
+	// ATM one-year call priced at $10.45 with r = 5%: IV 20%, delta 0.6368
+	req := SizingRequest{
+		Equity:  100000,
+		RiskPct: 0.01,
+		Entry:   100,
+		ATR:     2,
+		K:       2,
+		Method:  "opt-delta-atr",
+		Leg:     &options.Leg{Type: "CALL", Strike: 100, Exp: "2024-12-31", Action: "BUY", Price: 10.4506},
+		Pricing: options.Market{Rate: 0.05, AsOf: "2024-01-01"},
+	}
+
+	result, err := CalculatePositionSize(req)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.6368, result.Delta, 1e-4)
+	assert.InDelta(t, 0.2, result.IV, 1e-4)
+	assert.Equal(t, 1, result.Contracts) // floor(250 × 0.6368 ÷ 100)
+	assert.InDelta(t, 254.7, result.ActualRisk, 0.1)
+
+	// Puts size on the magnitude of their delta (0.3632)
+	req.Leg = &options.Leg{Type: "PUT", Strike: 100, Exp: "2024-12-31", Action: "BUY", Price: 5.5735}
+	result, err = CalculatePositionSize(req)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3632, result.Delta, 1e-4)
+	assert.Equal(t, 0, result.Contracts)
+
+	// A delta typed in wins over the leg
+	req.Delta = 0.9
+	result, err = CalculatePositionSize(req)
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, result.Delta)
+	assert.Zero(t, result.IV)
+	assert.Equal(t, 2, result.Contracts)
+}
+
+func TestCalculateOptionDeltaATRPosition_LegWithoutPrice(t *testing.T) {
+	req := SizingRequest{
+		Equity:  100000,
+		RiskPct: 0.01,
+		Entry:   100,
+		ATR:     2,
+		K:       2,
+		Method:  "opt-delta-atr",
+		Leg:     &options.Leg{Type: "CALL", Strike: 100, Exp: "2024-12-31", Action: "BUY"},
+		Pricing: options.Market{AsOf: "2024-01-01"},
+	}
+
+	_, err := CalculatePositionSize(req)
+	assert.ErrorIs(t, err, options.ErrNoVol)
+
+	// A volatility prices the leg without a premium
+	req.Pricing.Vol = 0.2
+	req.Pricing.Rate = 0.05
+	result, err := CalculatePositionSize(req)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6368, result.Delta, 1e-4)
+}
+
 func TestCalculatePositionSize_RouterOptionDeltaATR(t *testing.T) {
 	req := SizingRequest{
 		Equity:  100000,
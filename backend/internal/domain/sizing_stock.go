@@ -9,7 +9,7 @@ import (
 // The Van Tharp method calculates position size based on risk per trade:
 // 1. Calculate risk dollars: R = Equity × RiskPct (0.75%)
 // 2. Calculate stop distance: StopDist = K × ATR (K=2)
-// 3. Calculate initial stop: InitStop = Entry - StopDist
+// 3. Calculate initial stop: InitStop = Entry - StopDist (Entry + StopDist for a short)
 // 4. Calculate shares: Shares = floor(R ÷ StopDist)
 // 5. Verify: ActualRisk = Shares × StopDist ≤ R
 //
@@ -32,11 +32,12 @@ func CalculateStockPosition(req SizingRequest) (*SizingResult, error) {
 
 	// Step 2: Calculate stop distance
 	// StopDistance = K × ATR
-	stopDistance := float64(req.K) * req.ATR
+	stopDistance := req.K * req.ATR
 
 	// Step 3: Calculate initial stop
-	// InitialStop = Entry - StopDistance
-	initialStop := req.Entry - stopDistance
+	// InitialStop = Entry - StopDistance (above entry for a short)
+	direction, _ := NormalizeDirection(req.Direction)
+	initialStop := StopFor(direction, req.Entry, stopDistance)
 
 	// Step 4: Calculate shares (round down)
 	// Shares = floor(R ÷ StopDistance)
@@ -54,5 +55,6 @@ func CalculateStockPosition(req SizingRequest) (*SizingResult, error) {
 		Contracts:    0, // Stocks don't use contracts
 		ActualRisk:   actualRisk,
 		Method:       "stock",
+		Direction:    direction,
 	}, nil
 }
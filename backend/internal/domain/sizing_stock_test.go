@@ -33,6 +33,24 @@ func TestCalculateStockPosition_BasicExample(t *testing.T) {
 	assert.Equal(t, "stock", result.Method)
 }
 
+func TestCalculateStockPosition_FractionalK(t *testing.T) {
+	// K=1.5: StopDist = 1.5 × $2 = $3, same as K=2 with ATR $1.50
+	req := SizingRequest{
+		Equity:  10000,
+		RiskPct: 0.0075,
+		Entry:   180,
+		ATR:     2,
+		K:       1.5,
+		Method:  "stock",
+	}
+
+	result, err := CalculateStockPosition(req)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, result.StopDistance)
+	assert.Equal(t, 177.0, result.InitialStop)
+	assert.Equal(t, 25, result.Shares)
+}
+
 func TestCalculateStockPosition_PriceRanges(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -221,7 +239,7 @@ func TestCalculateStockPosition_ActualRiskNeverExceedsTarget(t *testing.T) {
 				result.ActualRisk, result.RiskDollars, entry, atr)
 
 			// Verify calculation consistency
-			expectedStopDistance := float64(req.K) * req.ATR
+			expectedStopDistance := req.K * req.ATR
 			assert.Equal(t, expectedStopDistance, result.StopDistance)
 
 			expectedInitialStop := req.Entry - expectedStopDistance
@@ -266,3 +284,35 @@ func TestCalculatePositionSize_InvalidMethod(t *testing.T) {
 	assert.Nil(t, result)
 	assert.ErrorIs(t, err, ErrInvalidMethod)
 }
+
+func TestCalculateStockPosition_Short(t *testing.T) {
+	// Same trade as the basic example, sold short: the stop goes 2N above entry
+	req := SizingRequest{
+		Direction: DirectionShort,
+		Equity:    10000,
+		RiskPct:   0.0075,
+		Entry:     180,
+		ATR:       1.5,
+		K:         2,
+		Method:    "stock",
+	}
+
+	result, err := CalculatePositionSize(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3.0, result.StopDistance)
+	assert.Equal(t, 183.0, result.InitialStop)
+	assert.Equal(t, 25, result.Shares)
+	assert.Equal(t, 75.0, result.ActualRisk)
+	assert.Equal(t, DirectionShort, result.Direction)
+
+	req.Direction = ""
+	result, err = CalculatePositionSize(req)
+	require.NoError(t, err)
+	assert.Equal(t, 177.0, result.InitialStop)
+	assert.Equal(t, DirectionLong, result.Direction)
+
+	req.Direction = "SIDEWAYS"
+	_, err = CalculatePositionSize(req)
+	assert.ErrorIs(t, err, ErrInvalidDirection)
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Trailing stop rules
+const (
+	StopRuleDonchian   = "DONCHIAN"   // N-bar low (high for shorts), N = exit lookback
+	StopRuleChandelier = "CHANDELIER" // Highest close since entry - K × ATR (lowest close + K × ATR for shorts)
+	StopRuleBreakeven  = "BREAKEVEN"  // Move to entry once price has gone 1R in favour
+)
+
+// DefaultExitLookback is the Turtle 10-bar Donchian exit
+const DefaultExitLookback = 10
+
+// Trailing stop errors
+var (
+	ErrInvalidStopRule  = errors.New("stop rule must be DONCHIAN, CHANDELIER or BREAKEVEN")
+	ErrNoBarsSinceEntry = errors.New("no price bars since entry")
+)
+
+// ParseStopRule parses a trailing stop rule name; empty means DONCHIAN
+func ParseStopRule(s string) (string, error) {
+	switch rule := strings.ToUpper(strings.TrimSpace(s)); rule {
+	case "":
+		return StopRuleDonchian, nil
+	case StopRuleDonchian, StopRuleChandelier, StopRuleBreakeven:
+		return rule, nil
+	case "N-BAR", "EXIT":
+		return StopRuleDonchian, nil
+	case "ATR", "2N":
+		return StopRuleChandelier, nil
+	case "BE", "1R":
+		return StopRuleBreakeven, nil
+	default:
+		return "", fmt.Errorf("%w: got '%s'", ErrInvalidStopRule, s)
+	}
+}
+
+// TrailingStopRequest describes an open position to trail
+type TrailingStopRequest struct {
+	Ticker       string        `json:"ticker"`
+	Direction    string        `json:"direction"` // LONG (default) or SHORT
+	Rule         string        `json:"rule"`
+	EntryPrice   float64       `json:"entry_price"`  // Average entry of the open shares
+	EntryDate    string        `json:"entry_date"`   // YYYY-MM-DD; bars before it are ignored by CHANDELIER and BREAKEVEN
+	InitialStop  float64       `json:"initial_stop"` // Defines 1R for BREAKEVEN
+	CurrentStop  float64       `json:"current_stop"`
+	CurrentRisk  float64       `json:"current_risk"`
+	Units        []PyramidUnit `json:"units"`                   // Open shares per lot, for the new risk
+	ExitLookback int           `json:"exit_lookback,omitempty"` // DONCHIAN (default 10)
+	K            float64       `json:"k,omitempty"`             // CHANDELIER multiple
+	N            float64       `json:"atr_n,omitempty"`         // Fallback N when bars are too short for ATR
+}
+
+// TrailingStopResult is the stop a rule proposes for one position
+type TrailingStopResult struct {
+	Ticker      string  `json:"ticker"`
+	Direction   string  `json:"direction"`
+	Rule        string  `json:"rule"`
+	AsOfDate    string  `json:"as_of_date"` // Last bar used
+	LastClose   float64 `json:"last_close"`
+	RuleStop    float64 `json:"rule_stop,omitempty"` // Level the rule computed (0 = rule not triggered)
+	CurrentStop float64 `json:"current_stop"`
+	NewStop     float64 `json:"new_stop"` // Never looser than CurrentStop
+	Changed     bool    `json:"changed"`
+	ATR         float64 `json:"atr_n,omitempty"`
+	CurrentRisk float64 `json:"current_risk"`
+	NewRisk     float64 `json:"new_risk"`
+	RiskChange  float64 `json:"risk_change"` // Negative when the tighter stop frees heat
+	Reason      string  `json:"reason"`
+	Applied     bool    `json:"applied"` // Set by callers that write the stop
+	ApplyError  string  `json:"apply_error,omitempty"`
+}
+
+// ComputeTrailingStop proposes a new stop for a position from its daily bars
+//
+// Rules (long side; shorts mirror them):
+//   - DONCHIAN: lowest low of the last ExitLookback bars
+//   - CHANDELIER: highest close since entry - K × ATR(20), with ATR taken
+//     from the bars (or N when there are too few bars)
+//   - BREAKEVEN: entry price once a close since entry has reached
+//     entry + 1R, where R = |entry - initial stop|
+//
+// The rule's level is ratcheted against the current stop, so a stop is
+// never loosened. Risk is recomputed over the open units at the new stop.
+func ComputeTrailingStop(req TrailingStopRequest, bars []Bar) (*TrailingStopResult, error) {
+	rule, err := ParseStopRule(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	direction, err := NormalizeDirection(req.Direction)
+	if err != nil {
+		return nil, err
+	}
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("%w: %s has no bars", ErrInsufficientBars, req.Ticker)
+	}
+
+	last := bars[len(bars)-1]
+	result := &TrailingStopResult{
+		Ticker:      req.Ticker,
+		Direction:   direction,
+		Rule:        rule,
+		AsOfDate:    last.Date,
+		LastClose:   last.Close,
+		CurrentStop: req.CurrentStop,
+		CurrentRisk: req.CurrentRisk,
+	}
+
+	since := barsSince(bars, req.EntryDate)
+	long := direction == DirectionLong
+	side, extremeName, op := "low", "highest", "-"
+	if !long {
+		side, extremeName, op = "high", "lowest", "+"
+	}
+
+	switch rule {
+	case StopRuleDonchian:
+		lookback := req.ExitLookback
+		if lookback <= 0 {
+			lookback = DefaultExitLookback
+		}
+		high, low, err := DonchianChannel(bars, len(bars), lookback)
+		if err != nil {
+			return nil, err
+		}
+		result.RuleStop = low
+		if !long {
+			result.RuleStop = high
+		}
+		result.Reason = fmt.Sprintf("%d-bar %s $%.2f", lookback, side, result.RuleStop)
+
+	case StopRuleChandelier:
+		if req.K <= 0 {
+			return nil, fmt.Errorf("%w: got %.2f", ErrInvalidK, req.K)
+		}
+		if len(since) == 0 {
+			return nil, fmt.Errorf("%w: %s entered %s", ErrNoBarsSinceEntry, req.Ticker, req.EntryDate)
+		}
+		atr := req.N
+		if atrResult, err := CalculateATR(bars, DefaultATRPeriod); err == nil {
+			atr = atrResult.ATR
+		}
+		if atr <= 0 {
+			return nil, fmt.Errorf("%w: got %.2f", ErrInvalidATR, atr)
+		}
+		result.ATR = atr
+
+		extreme := since[0].Close
+		for _, b := range since[1:] {
+			if (long && b.Close > extreme) || (!long && b.Close < extreme) {
+				extreme = b.Close
+			}
+		}
+		result.RuleStop = StopFor(direction, extreme, req.K*atr)
+		result.Reason = fmt.Sprintf("%s close since entry $%.2f %s %.1f × N $%.2f",
+			extremeName, extreme, op, req.K, atr)
+
+	case StopRuleBreakeven:
+		r := math.Abs(req.EntryPrice - req.InitialStop)
+		if req.EntryPrice <= 0 || r == 0 {
+			return nil, fmt.Errorf("breakeven rule needs an entry and an initial stop (entry %.2f, stop %.2f)",
+				req.EntryPrice, req.InitialStop)
+		}
+		target := req.EntryPrice + DirectionSign(direction)*r
+		best := 0.0
+		for _, b := range since {
+			if move := PnLPerShare(direction, req.EntryPrice, b.Close); move > best {
+				best = move
+			}
+		}
+		if best >= r {
+			result.RuleStop = req.EntryPrice
+			result.Reason = fmt.Sprintf("closed through 1R target $%.2f, stop to breakeven $%.2f", target, req.EntryPrice)
+		} else {
+			result.Reason = fmt.Sprintf("1R target $%.2f not reached (best %.2fR)", target, best/r)
+		}
+	}
+
+	result.NewStop = req.CurrentStop
+	if result.RuleStop > 0 {
+		result.NewStop = TighterStop(direction, req.CurrentStop, result.RuleStop)
+	}
+	result.Changed = result.NewStop != req.CurrentStop
+	if result.RuleStop > 0 && !result.Changed {
+		result.Reason += fmt.Sprintf(", current stop $%.2f is already tighter", req.CurrentStop)
+	}
+
+	result.NewRisk = req.CurrentRisk
+	if result.Changed {
+		result.NewRisk = 0
+		for _, u := range req.Units {
+			result.NewRisk += float64(u.Shares) * OpenRiskPerShare(direction, u.EntryPrice, result.NewStop)
+		}
+	}
+	result.RiskChange = result.NewRisk - req.CurrentRisk
+
+	return result, nil
+}
+
+// barsSince returns the bars on or after date (all bars when date is empty)
+func barsSince(bars []Bar, date string) []Bar {
+	if date == "" {
+		return bars
+	}
+	for i, b := range bars {
+		if b.Date >= date {
+			return bars[i:]
+		}
+	}
+	return nil
+}
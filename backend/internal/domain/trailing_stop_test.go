@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trailBars builds daily bars from closes, each with a $1 range around the close
+func trailBars(closes ...float64) []Bar {
+	bars := make([]Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = Bar{
+			Date:  fmt.Sprintf("2024-01-%02d", i+1),
+			Open:  c,
+			High:  c + 0.5,
+			Low:   c - 0.5,
+			Close: c,
+		}
+	}
+	return bars
+}
+
+// trailRequest is one 25-share unit long at $100 with a $97 stop (N = 1.5)
+func trailRequest(rule string) TrailingStopRequest {
+	return TrailingStopRequest{
+		Ticker:       "AAPL",
+		Rule:         rule,
+		EntryPrice:   100,
+		EntryDate:    "2024-01-01",
+		InitialStop:  97,
+		CurrentStop:  97,
+		CurrentRisk:  75,
+		Units:        []PyramidUnit{{UnitNum: 1, Shares: 25, EntryPrice: 100}},
+		ExitLookback: 3,
+		K:            2,
+		N:            1.5,
+	}
+}
+
+func TestParseStopRule(t *testing.T) {
+	rule, err := ParseStopRule("")
+	require.NoError(t, err)
+	assert.Equal(t, StopRuleDonchian, rule)
+
+	rule, err = ParseStopRule("chandelier")
+	require.NoError(t, err)
+	assert.Equal(t, StopRuleChandelier, rule)
+
+	_, err = ParseStopRule("parabolic")
+	assert.ErrorIs(t, err, ErrInvalidStopRule)
+}
+
+func TestComputeTrailingStop_DonchianRaisesStop(t *testing.T) {
+	result, err := ComputeTrailingStop(trailRequest(StopRuleDonchian), trailBars(100, 101, 102, 103, 104))
+	require.NoError(t, err)
+
+	assert.Equal(t, "2024-01-05", result.AsOfDate)
+	assert.InDelta(t, 101.5, result.RuleStop, 1e-9) // Lowest low of the last 3 bars
+	assert.InDelta(t, 101.5, result.NewStop, 1e-9)
+	assert.True(t, result.Changed)
+	assert.Zero(t, result.NewRisk) // 25 × (100 - 101.5) is profit locked in, not negative risk
+	assert.InDelta(t, -75, result.RiskChange, 1e-9)
+}
+
+func TestComputeTrailingStop_NeverLoosens(t *testing.T) {
+	req := trailRequest(StopRuleDonchian)
+	req.CurrentStop = 102
+	req.CurrentRisk = 0
+
+	// Price pulled back: the 3-bar low (99.5) is below the current stop
+	result, err := ComputeTrailingStop(req, trailBars(100, 103, 102, 101, 100))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 99.5, result.RuleStop, 1e-9)
+	assert.InDelta(t, 102, result.NewStop, 1e-9)
+	assert.False(t, result.Changed)
+	assert.Zero(t, result.NewRisk)
+	assert.Zero(t, result.RiskChange)
+}
+
+func TestComputeTrailingStop_DonchianShort(t *testing.T) {
+	req := trailRequest(StopRuleDonchian)
+	req.Direction = DirectionShort
+	req.InitialStop = 103
+	req.CurrentStop = 103
+
+	result, err := ComputeTrailingStop(req, trailBars(100, 99, 98, 97, 96))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 98.5, result.NewStop, 1e-9) // Highest high of the last 3 bars
+	assert.True(t, result.Changed)
+	assert.Zero(t, result.NewRisk) // 25 × (98.5 - 100) is profit locked in
+}
+
+func TestComputeTrailingStop_Chandelier(t *testing.T) {
+	// Too few bars for ATR(20), so N = 1.5 is used: 106 - 2 × 1.5
+	result, err := ComputeTrailingStop(trailRequest(StopRuleChandelier), trailBars(100, 102, 106, 104))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 1.5, result.ATR, 1e-9)
+	assert.InDelta(t, 103, result.NewStop, 1e-9)
+	// A stop past entry locks in profit; it carries no risk rather than negative risk
+	assert.Zero(t, result.NewRisk)
+	assert.InDelta(t, -75, result.RiskChange, 1e-9)
+}
+
+func TestComputeTrailingStop_ChandelierIgnoresBarsBeforeEntry(t *testing.T) {
+	req := trailRequest(StopRuleChandelier)
+	req.EntryDate = "2024-01-03"
+
+	// The 110 close came before entry
+	result, err := ComputeTrailingStop(req, trailBars(110, 100, 100, 101))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 98, result.NewStop, 1e-9) // 101 - 3
+}
+
+func TestComputeTrailingStop_Breakeven(t *testing.T) {
+	// 1R = 3; best close 102.5 has not reached 103
+	result, err := ComputeTrailingStop(trailRequest(StopRuleBreakeven), trailBars(100, 102.5, 101))
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+	assert.Zero(t, result.RuleStop)
+
+	result, err = ComputeTrailingStop(trailRequest(StopRuleBreakeven), trailBars(100, 103, 101))
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.InDelta(t, 100, result.NewStop, 1e-9)
+	assert.Zero(t, result.NewRisk)
+	assert.InDelta(t, -75, result.RiskChange, 1e-9)
+}
+
+func TestComputeTrailingStop_BreakevenShort(t *testing.T) {
+	req := trailRequest(StopRuleBreakeven)
+	req.Direction = DirectionShort
+	req.InitialStop = 103
+	req.CurrentStop = 103
+
+	result, err := ComputeTrailingStop(req, trailBars(100, 98, 96.9))
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.InDelta(t, 100, result.NewStop, 1e-9)
+}
+
+func TestComputeTrailingStop_Errors(t *testing.T) {
+	_, err := ComputeTrailingStop(trailRequest(StopRuleDonchian), trailBars(100, 101))
+	assert.ErrorIs(t, err, ErrInsufficientBars)
+
+	req := trailRequest(StopRuleChandelier)
+	req.K = 0
+	_, err = ComputeTrailingStop(req, trailBars(100, 101))
+	assert.ErrorIs(t, err, ErrInvalidK)
+
+	req = trailRequest(StopRuleChandelier)
+	req.EntryDate = "2024-02-01"
+	_, err = ComputeTrailingStop(req, trailBars(100, 101))
+	assert.ErrorIs(t, err, ErrNoBarsSinceEntry)
+
+	_, err = ComputeTrailingStop(trailRequest("parabolic"), trailBars(100))
+	assert.ErrorIs(t, err, ErrInvalidStopRule)
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unit limit rules, as named by UnitLimitCheck.Rule
+const (
+	UnitRuleMarket    = "MARKET"    // One ticker
+	UnitRuleClosely   = "CLOSELY"   // Correlated cluster, one direction
+	UnitRuleLoosely   = "LOOSELY"   // Bucket, one direction
+	UnitRuleDirection = "DIRECTION" // Whole portfolio, one direction
+)
+
+// UnitLimits caps the units held at once (0 = no limit)
+type UnitLimits struct {
+	Market    int `json:"market"`    // Per ticker
+	Closely   int `json:"closely"`   // Per closely correlated group in one direction
+	Loosely   int `json:"loosely"`   // Per loosely correlated group in one direction
+	Direction int `json:"direction"` // Long or short across the portfolio
+}
+
+// DefaultUnitLimits are the original Turtle rules: 4 / 6 / 10 / 12
+var DefaultUnitLimits = UnitLimits{Market: 4, Closely: 6, Loosely: 10, Direction: 12}
+
+// UnitLimitsFromSettings reads the UnitLimit_* settings, using
+// DefaultUnitLimits for any that were never set
+func UnitLimitsFromSettings(settings map[string]string) (UnitLimits, error) {
+	limits := DefaultUnitLimits
+	for _, l := range []struct {
+		key   SettingKey
+		units *int
+	}{
+		{SettingUnitLimitMarket, &limits.Market},
+		{SettingUnitLimitClosely, &limits.Closely},
+		{SettingUnitLimitLoosely, &limits.Loosely},
+		{SettingUnitLimitDirection, &limits.Direction},
+	} {
+		value, ok := settings[string(l.key)]
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return limits, fmt.Errorf("invalid %s setting: %w", l.key, err)
+		}
+		*l.units = int(parsed)
+	}
+	return limits, nil
+}
+
+// UnitPosition is an open position's unit count
+type UnitPosition struct {
+	Ticker    string `json:"ticker"`
+	Bucket    string `json:"bucket"`
+	Direction string `json:"direction"` // LONG or SHORT
+	Units     int    `json:"units"`     // Units filled (0 counts as 1)
+	MaxUnits  int    `json:"max_units"` // The position's own pyramid limit (0 = none)
+}
+
+// UnitLimitRequest describes a unit about to be added
+type UnitLimitRequest struct {
+	Ticker        string               `json:"ticker"`
+	Direction     string               `json:"direction"` // LONG (default) or SHORT
+	Bucket        string               `json:"bucket"`    // Loosely correlated group ("" = none)
+	AddUnits      int                  `json:"add_units"` // Default 1
+	Limits        UnitLimits           `json:"limits"`
+	Clusters      []CorrelationCluster `json:"clusters,omitempty"` // Closely correlated groups
+	OpenPositions []UnitPosition       `json:"open_positions"`
+}
+
+// UnitLimitCheck is one unit rule against its limit
+type UnitLimitCheck struct {
+	Rule     string   `json:"rule"`
+	Group    []string `json:"group"` // Tickers or bucket the rule counts
+	Current  int      `json:"current"`
+	New      int      `json:"new"`
+	Limit    int      `json:"limit"`
+	Exceeded bool     `json:"exceeded"`
+}
+
+// UnitLimitResult is the outcome of the unit limit checks
+type UnitLimitResult struct {
+	Checks  []UnitLimitCheck `json:"checks"`
+	Allowed bool             `json:"allowed"`
+	Reason  string           `json:"reason,omitempty"`
+}
+
+// CheckUnitLimits applies the Turtle unit limits to a new unit
+//
+// Rules (each skipped when its limit is 0):
+//   - Market: units in the ticker, capped by the lower of Limits.Market and
+//     the position's own MaxUnits
+//   - Closely correlated: units in the same direction across the ticker's
+//     correlated cluster (only when it has one)
+//   - Loosely correlated: units in the same direction within the bucket
+//   - Direction: units in the same direction across the portfolio
+//
+// Dollar heat is checked separately; a trade can pass heat and still break
+// a unit limit.
+func CheckUnitLimits(req UnitLimitRequest) (*UnitLimitResult, error) {
+	if req.Ticker == "" {
+		return nil, fmt.Errorf("ticker is required")
+	}
+	direction, err := NormalizeDirection(req.Direction)
+	if err != nil {
+		return nil, err
+	}
+	limits := req.Limits
+	if limits.Market < 0 || limits.Closely < 0 || limits.Loosely < 0 || limits.Direction < 0 {
+		return nil, fmt.Errorf("unit limits must not be negative, got %+v", limits)
+	}
+	add := req.AddUnits
+	if add <= 0 {
+		add = 1
+	}
+
+	var cluster *CorrelationCluster
+	for i := range req.Clusters {
+		if req.Clusters[i].Contains(req.Ticker) {
+			cluster = &req.Clusters[i]
+			break
+		}
+	}
+
+	result := &UnitLimitResult{Checks: []UnitLimitCheck{}, Allowed: true}
+	check := func(rule string, group []string, limit int, counts func(UnitPosition) bool) {
+		if limit <= 0 {
+			return
+		}
+		c := UnitLimitCheck{Rule: rule, Group: group, Limit: limit}
+		for _, pos := range req.OpenPositions {
+			if counts(pos) {
+				c.Current += max(pos.Units, 1)
+			}
+		}
+		c.New = c.Current + add
+		c.Exceeded = c.New > c.Limit
+		result.Checks = append(result.Checks, c)
+
+		if c.Exceeded && result.Allowed {
+			result.Allowed = false
+			result.Reason = fmt.Sprintf("%s would hold %d units (limit %d)", describeUnitGroup(c, direction), c.New, c.Limit)
+		}
+	}
+	sameDirection := func(pos UnitPosition) bool {
+		d, err := NormalizeDirection(pos.Direction)
+		return err == nil && d == direction
+	}
+
+	marketLimit := limits.Market
+	for _, pos := range req.OpenPositions {
+		if strings.EqualFold(pos.Ticker, req.Ticker) && pos.MaxUnits > 0 && (marketLimit <= 0 || pos.MaxUnits < marketLimit) {
+			marketLimit = pos.MaxUnits
+		}
+	}
+	check(UnitRuleMarket, []string{strings.ToUpper(req.Ticker)}, marketLimit, func(pos UnitPosition) bool {
+		return strings.EqualFold(pos.Ticker, req.Ticker)
+	})
+	if cluster != nil {
+		check(UnitRuleClosely, cluster.Tickers, limits.Closely, func(pos UnitPosition) bool {
+			return sameDirection(pos) && cluster.Contains(pos.Ticker)
+		})
+	}
+	if req.Bucket != "" {
+		check(UnitRuleLoosely, []string{req.Bucket}, limits.Loosely, func(pos UnitPosition) bool {
+			return sameDirection(pos) && pos.Bucket == req.Bucket
+		})
+	}
+	check(UnitRuleDirection, nil, limits.Direction, sameDirection)
+
+	return result, nil
+}
+
+// describeUnitGroup names the group a unit check counts, for failure reasons
+func describeUnitGroup(c UnitLimitCheck, direction string) string {
+	switch c.Rule {
+	case UnitRuleMarket:
+		return fmt.Sprintf("Unit limit: %s", c.Group[0])
+	case UnitRuleClosely:
+		return fmt.Sprintf("Unit limit: closely correlated [%s] %s", strings.Join(c.Group, ", "), direction)
+	case UnitRuleLoosely:
+		return fmt.Sprintf("Unit limit: bucket '%s' %s", c.Group[0], direction)
+	default:
+		return fmt.Sprintf("Unit limit: all %s positions", direction)
+	}
+}
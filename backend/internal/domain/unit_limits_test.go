@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unitLimitRequest() UnitLimitRequest {
+	return UnitLimitRequest{
+		Ticker:    "MSFT",
+		Direction: DirectionLong,
+		Bucket:    "Tech/Comm",
+		Limits:    DefaultUnitLimits,
+		Clusters: []CorrelationCluster{
+			{Tickers: []string{"AAPL", "MSFT", "NVDA"}},
+		},
+		OpenPositions: []UnitPosition{
+			{Ticker: "AAPL", Bucket: "Consumer", Direction: DirectionLong, Units: 3, MaxUnits: 4},
+			{Ticker: "NVDA", Bucket: "Tech/Comm", Direction: DirectionLong, Units: 2, MaxUnits: 4},
+			{Ticker: "XOM", Bucket: "Energy", Direction: DirectionShort, Units: 4, MaxUnits: 4},
+		},
+	}
+}
+
+func TestCheckUnitLimits_Allowed(t *testing.T) {
+	result, err := CheckUnitLimits(unitLimitRequest())
+	require.NoError(t, err)
+
+	assert.True(t, result.Allowed)
+	assert.Empty(t, result.Reason)
+	require.Len(t, result.Checks, 4)
+
+	byRule := map[string]UnitLimitCheck{}
+	for _, c := range result.Checks {
+		byRule[c.Rule] = c
+	}
+	assert.Equal(t, 1, byRule[UnitRuleMarket].New)
+	assert.Equal(t, 6, byRule[UnitRuleClosely].New)   // AAPL 3 + NVDA 2 + 1, whatever the bucket
+	assert.Equal(t, 3, byRule[UnitRuleLoosely].New)   // NVDA 2 + 1
+	assert.Equal(t, 6, byRule[UnitRuleDirection].New) // The XOM short does not count
+}
+
+func TestCheckUnitLimits_CloselyCorrelatedFails(t *testing.T) {
+	req := unitLimitRequest()
+	req.OpenPositions[0].Units = 4
+
+	result, err := CheckUnitLimits(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "Unit limit: closely correlated [AAPL, MSFT, NVDA] LONG would hold 7 units (limit 6)", result.Reason)
+}
+
+func TestCheckUnitLimits_MarketUsesPositionMaxUnits(t *testing.T) {
+	req := unitLimitRequest()
+	req.Ticker = "NVDA"
+	req.OpenPositions[1].MaxUnits = 2
+
+	result, err := CheckUnitLimits(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "Unit limit: NVDA would hold 3 units (limit 2)", result.Reason)
+}
+
+func TestCheckUnitLimits_DirectionFails(t *testing.T) {
+	req := unitLimitRequest()
+	req.Ticker = "CVX"
+	req.Direction = DirectionShort
+	req.Bucket = "Energy"
+	req.Limits.Market = 0
+	req.Limits.Direction = 4
+
+	result, err := CheckUnitLimits(req)
+	require.NoError(t, err)
+
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "Unit limit: all SHORT positions would hold 5 units (limit 4)", result.Reason)
+	assert.Len(t, result.Checks, 2, "no market limit or cluster for CVX")
+}
+
+func TestCheckUnitLimits_Errors(t *testing.T) {
+	req := unitLimitRequest()
+	req.Ticker = ""
+	_, err := CheckUnitLimits(req)
+	assert.Error(t, err)
+
+	req = unitLimitRequest()
+	req.Direction = "SIDEWAYS"
+	_, err = CheckUnitLimits(req)
+	assert.Error(t, err)
+
+	req = unitLimitRequest()
+	req.Limits.Loosely = -1
+	_, err = CheckUnitLimits(req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package marketdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// EvaluateLastBreakout runs the System 1 skip filter for a ticker as of
+// date (YYYY-MM-DD; empty = the latest stored bar) using stored bars and
+// the ticker's closed System 1 trades in trade_history
+//
+// Bars after date and trades that exited after it are left out, so a
+// decision saved for an earlier day is filtered against that day's
+// breakout. Without enough bars up to date the filter is not evaluated:
+// the result does not apply and its reason says so. Any other error (the
+// store failing) is returned, so the gate never passes on a failed read.
+func EvaluateLastBreakout(db *storage.DB, ticker, date string, stopMultiple float64) (*domain.LastBreakoutFilterResult, error) {
+	ticker = strings.ToUpper(ticker)
+
+	stored, err := db.GetPriceBars(ticker, 0)
+	if err != nil {
+		return nil, err
+	}
+	bars := FromStorage(stored)
+	if date != "" {
+		cut := sort.Search(len(bars), func(i int) bool { return bars[i].Date > date })
+		bars = bars[:cut]
+	}
+	if len(bars) == 0 {
+		asOf := "stored"
+		if date != "" {
+			asOf = "on or before " + date
+		}
+		return &domain.LastBreakoutFilterResult{
+			Ticker:     ticker,
+			SignalDate: date,
+			Reason:     fmt.Sprintf("no bars for %s %s, filter not evaluated", ticker, asOf),
+		}, nil
+	}
+
+	trades, err := db.GetClosedTradesForTicker(ticker, storage.SystemOne)
+	if err != nil {
+		return nil, err
+	}
+	if date != "" {
+		known := trades[:0]
+		for _, t := range trades {
+			if t.ExitDate <= date {
+				known = append(known, t)
+			}
+		}
+		trades = known
+	}
+
+	result, err := domain.EvaluateLastBreakoutFilter(ticker, bars, HistoryOutcomes(trades), stopMultiple)
+	if errors.Is(err, domain.ErrInsufficientBars) {
+		return &domain.LastBreakoutFilterResult{
+			Ticker:     ticker,
+			SignalDate: bars[len(bars)-1].Date,
+			Reason:     fmt.Sprintf("too few bars for %s (%d), filter not evaluated", ticker, len(bars)),
+		}, nil
+	}
+	return result, err
+}
+
+// HistoryOutcomes converts closed trade_history rows to breakout outcomes
+func HistoryOutcomes(trades []storage.TradeHistoryEntry) []domain.BreakoutOutcome {
+	outcomes := make([]domain.BreakoutOutcome, 0, len(trades))
+	for _, t := range trades {
+		direction := domain.SignalLong
+		if strings.HasPrefix(t.Strategy, "SHORT") {
+			direction = domain.SignalShort
+		}
+
+		perShare := 0.0
+		if t.EntryPrice > 0 && t.ExitPrice > 0 {
+			perShare = t.ExitPrice - t.EntryPrice
+			if direction == domain.SignalShort {
+				perShare = -perShare
+			}
+		}
+
+		winner := t.PnL > 0
+		if t.Outcome != "" {
+			winner = t.Outcome == "WIN"
+		}
+
+		outcomes = append(outcomes, domain.BreakoutOutcome{
+			Direction:    direction,
+			BreakoutDate: t.EntryDate,
+			EntryPrice:   t.EntryPrice,
+			ExitDate:     t.ExitDate,
+			ExitPrice:    t.ExitPrice,
+			PnLPerShare:  perShare,
+			Winner:       winner,
+			Source:       domain.OutcomeSourceHistory,
+		})
+	}
+	return outcomes
+}
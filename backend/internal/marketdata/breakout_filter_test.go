@@ -0,0 +1,81 @@
+package marketdata
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestEvaluateLastBreakout_AsOfDate(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	result, err := EvaluateLastBreakout(db, "aapl", "2025-03-03", 2)
+	require.NoError(t, err)
+	assert.False(t, result.Applies)
+	assert.Contains(t, result.Reason, "no bars for AAPL on or before 2025-03-03, filter not evaluated")
+
+	// 30 quiet days, a close through the 20-day high, then a pullback
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := []storage.PriceBar{}
+	for i := 0; i < 35; i++ {
+		close := 100.0
+		switch {
+		case i == 30:
+			close = 110
+		case i > 30:
+			close = 105
+		}
+		bars = append(bars, storage.PriceBar{
+			Date: start.AddDate(0, 0, i).Format("2006-01-02"),
+			Open: close, High: close + 1, Low: close - 1, Close: close,
+		})
+	}
+	_, err = db.SavePriceBars("AAPL", bars)
+	require.NoError(t, err)
+	breakout := bars[30].Date
+
+	result, err = EvaluateLastBreakout(db, "AAPL", breakout, 2)
+	require.NoError(t, err)
+	assert.Equal(t, breakout, result.SignalDate)
+	assert.True(t, result.Applies)
+
+	// The latest bar has no breakout
+	result, err = EvaluateLastBreakout(db, "AAPL", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, bars[34].Date, result.SignalDate)
+	assert.False(t, result.Applies)
+
+	result, err = EvaluateLastBreakout(db, "AAPL", "2024-12-31", 2)
+	require.NoError(t, err)
+	assert.Contains(t, result.Reason, "filter not evaluated")
+}
+
+func TestEvaluateLastBreakout_Errors(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	require.NoError(t, db.Initialize())
+
+	// A few bars cannot show a 20-day breakout: not evaluated, not an error
+	_, err = db.SavePriceBars("AAPL", []storage.PriceBar{
+		{Date: "2025-01-02", Open: 100, High: 101, Low: 99, Close: 100},
+		{Date: "2025-01-03", Open: 100, High: 101, Low: 99, Close: 100},
+	})
+	require.NoError(t, err)
+	result, err := EvaluateLastBreakout(db, "AAPL", "", 2)
+	require.NoError(t, err)
+	assert.False(t, result.Applies)
+	assert.Contains(t, result.Reason, "too few bars for AAPL (2), filter not evaluated")
+
+	// A store that cannot be read is an error, not a pass
+	require.NoError(t, db.Close())
+	_, err = EvaluateLastBreakout(db, "AAPL", "", 2)
+	assert.Error(t, err)
+}
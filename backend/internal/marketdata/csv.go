@@ -0,0 +1,220 @@
+// Package marketdata loads daily OHLCV bars from local CSV files and the price store
+package marketdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+)
+
+// DefaultPriceDir is where daily bar CSV files are dropped (one file per ticker)
+const DefaultPriceDir = "./data/prices"
+
+// dateLayouts are the date formats accepted in the Date column
+var dateLayouts = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"1/2/2006",
+	"2006/01/02",
+	"20060102",
+}
+
+// ParseCSV reads daily bars from CSV data with a header row
+//
+// Expected columns (case-insensitive, any order):
+//
+//	Date, Open, High, Low, Close, Volume
+//
+// Extra columns such as "Adj Close" are ignored, Volume is optional, and
+// rows with blank or "null" prices (holidays in some exports) are skipped.
+// Bars are returned oldest first with duplicate dates collapsed to the
+// last occurrence.
+func ParseCSV(r io.Reader) ([]domain.Bar, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		cols[key] = i
+	}
+	for _, required := range []string{"date", "open", "high", "low", "close"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("CSV header missing %q column", required)
+		}
+	}
+
+	byDate := map[string]domain.Bar{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		bar, ok, err := parseRecord(record, cols)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			byDate[bar.Date] = bar
+		}
+	}
+
+	bars := make([]domain.Bar, 0, len(byDate))
+	for _, bar := range byDate {
+		bars = append(bars, bar)
+	}
+	sort.Slice(bars, func(i, j int) bool { return bars[i].Date < bars[j].Date })
+
+	return bars, nil
+}
+
+// parseRecord converts one CSV row; ok is false for rows that should be skipped
+func parseRecord(record []string, cols map[string]int) (domain.Bar, bool, error) {
+	field := func(name string) string {
+		idx, ok := cols[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	rawDate := field("date")
+	if rawDate == "" {
+		return domain.Bar{}, false, nil
+	}
+	date, err := parseDate(rawDate)
+	if err != nil {
+		return domain.Bar{}, false, err
+	}
+
+	prices := make([]float64, 4)
+	for i, name := range []string{"open", "high", "low", "close"} {
+		raw := field(name)
+		if raw == "" || strings.EqualFold(raw, "null") {
+			return domain.Bar{}, false, nil
+		}
+		v, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+		if err != nil {
+			return domain.Bar{}, false, fmt.Errorf("invalid %s %q", name, raw)
+		}
+		prices[i] = v
+	}
+
+	var volume int64
+	if raw := field("volume"); raw != "" && !strings.EqualFold(raw, "null") {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+		if err != nil {
+			return domain.Bar{}, false, fmt.Errorf("invalid volume %q", raw)
+		}
+		volume = int64(v)
+	}
+
+	bar := domain.Bar{
+		Date:   date,
+		Open:   prices[0],
+		High:   prices[1],
+		Low:    prices[2],
+		Close:  prices[3],
+		Volume: volume,
+	}
+
+	if bar.High < bar.Low {
+		return domain.Bar{}, false, fmt.Errorf("%s: high %.2f below low %.2f", date, bar.High, bar.Low)
+	}
+
+	return bar, true, nil
+}
+
+// parseDate normalizes any accepted date layout to YYYY-MM-DD
+func parseDate(raw string) (string, error) {
+	// Some exports include a time component ("2025-01-02 00:00:00")
+	if idx := strings.IndexAny(raw, " T"); idx > 0 {
+		raw = raw[:idx]
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("unrecognized date %q", raw)
+}
+
+// LoadFile reads daily bars from a single CSV file
+func LoadFile(path string) ([]domain.Bar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	bars, err := ParseCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return bars, nil
+}
+
+// TickerFromPath derives the ticker from a file name ("data/prices/aapl.csv" -> "AAPL")
+func TickerFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.ToUpper(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// ListFiles returns the CSV files in a price directory, sorted by name
+func ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price directory: %w", err)
+	}
+
+	files := []string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".csv") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// LoadDir reads every <TICKER>.csv in a directory, keyed by ticker
+func LoadDir(dir string) (map[string][]domain.Bar, error) {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]domain.Bar, len(files))
+	for _, path := range files {
+		bars, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		result[TickerFromPath(path)] = bars
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package marketdata
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestParseCSV(t *testing.T) {
+	data := `date,open,high,low,close,volume
+01/03/2025,101,103,100,102,"1,200"
+2025-01-02,100,102,99,101,1000
+2025-01-06,null,null,null,null,null
+2025-01-03,101,104,100,103,1300
+`
+	bars, err := ParseCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, bars, 2)
+
+	assert.Equal(t, "2025-01-02", bars[0].Date)
+	// Duplicate date keeps the last row
+	assert.Equal(t, "2025-01-03", bars[1].Date)
+	assert.Equal(t, 104.0, bars[1].High)
+	assert.Equal(t, int64(1300), bars[1].Volume)
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = ParseCSV(strings.NewReader("Date,Open,High,Close\n2025-01-02,1,2,1\n"))
+	assert.ErrorContains(t, err, "low")
+
+	_, err = ParseCSV(strings.NewReader("Date,Open,High,Low,Close\nyesterday,1,2,1,1\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = ParseCSV(strings.NewReader("Date,Open,High,Low,Close\n2025-01-02,1,1,2,1\n"))
+	assert.ErrorContains(t, err, "below low")
+}
+
+func TestTickerFromPath(t *testing.T) {
+	assert.Equal(t, "AAPL", TickerFromPath(filepath.Join("data", "prices", "aapl.csv")))
+	assert.Equal(t, "BRK.B", TickerFromPath("BRK.B.csv"))
+}
+
+func TestImportDirAndProvider(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	results, err := ImportDir(db, "testdata")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "SPY", results[0].Ticker)
+	assert.Equal(t, 30, results[0].Bars)
+
+	provider := NewDBBarProvider(db)
+	req := domain.SizingRequest{Ticker: "spy", Method: "stock"}
+	atr, err := domain.ResolveSizingATR(provider, &req)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2.0, req.ATR, 1e-9)
+	assert.Equal(t, results[0].LastBar, req.ATRDate)
+	assert.Equal(t, results[0].LastBar, atr.AsOfDate)
+
+	_, err = provider.GetDailyBars("NOPE")
+	assert.ErrorContains(t, err, "no stored price bars")
+}
+
+func TestCSVBarProvider(t *testing.T) {
+	provider := NewCSVBarProvider("testdata")
+
+	tickers, err := provider.Tickers()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SPY"}, tickers)
+
+	bars, err := provider.GetDailyBars("spy")
+	require.NoError(t, err)
+	assert.Len(t, bars, 30)
+
+	_, err = provider.GetDailyBars("NOPE")
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package marketdata
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// ResolveEquity works out the account's equity from settings
+//
+// Without a StartingEquity setting the account is STATIC: Equity_E is used
+// as entered. Otherwise equity is tracked from StartingEquity plus the
+// realized P&L in trade_history (and, with EquityMarkToMarket, open stock
+// positions at their last stored close; option positions count at realized
+// P&L only), and the drawdown rule gives the adjusted equity to size with.
+func ResolveEquity(db *storage.DB) (*domain.EquityStatus, error) {
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	setting := func(key domain.SettingKey, def float64) (float64, error) {
+		value, ok := settings[string(key)]
+		if !ok {
+			return def, nil
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s setting: %w", key, err)
+		}
+		return parsed, nil
+	}
+
+	starting, err := setting(domain.SettingStartingEquity, 0)
+	if err != nil {
+		return nil, err
+	}
+	if starting <= 0 {
+		equity, err := setting(domain.SettingEquity, 0)
+		if err != nil {
+			return nil, err
+		}
+		if equity <= 0 {
+			return nil, fmt.Errorf("Equity_E setting is missing or not positive")
+		}
+		return domain.StaticEquity(equity), nil
+	}
+
+	req := domain.EquityRequest{Starting: starting}
+	if req.StepPct, err = setting(domain.SettingDrawdownStep, domain.DefaultDrawdownStepPct); err != nil {
+		return nil, err
+	}
+	if req.CutPct, err = setting(domain.SettingDrawdownCut, domain.DefaultDrawdownCutPct); err != nil {
+		return nil, err
+	}
+
+	days, err := db.GetRealizedPnLByDate()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range days {
+		req.Realized = append(req.Realized, domain.EquityEvent{Date: d.Date, PnL: d.PnL})
+	}
+
+	markToMarket, err := setting(domain.SettingEquityMarkToMarket, 0)
+	if err != nil {
+		return nil, err
+	}
+	if markToMarket == 1 {
+		if req.Unrealized, err = unrealizedStockPnL(db); err != nil {
+			return nil, err
+		}
+	}
+
+	return domain.TrackEquity(req)
+}
+
+// SizingEquity is the equity to size trades and cap heat with: the
+// drawdown-adjusted equity when tracking, Equity_E otherwise
+func SizingEquity(db *storage.DB) (float64, error) {
+	status, err := ResolveEquity(db)
+	if err != nil {
+		return 0, err
+	}
+	return status.Adjusted, nil
+}
+
+// unrealizedStockPnL marks open stock positions to their last stored close
+// Positions without stored bars are left at cost.
+func unrealizedStockPnL(db *storage.DB) (float64, error) {
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get open positions: %w", err)
+	}
+
+	provider := NewDBBarProvider(db)
+	total := 0.0
+	for _, p := range positions {
+		if p.InstrumentType == storage.InstrumentOption || p.Shares == 0 {
+			continue
+		}
+		bars, err := provider.GetDailyBars(p.Ticker)
+		if err != nil || len(bars) == 0 {
+			continue
+		}
+		total += domain.DirectionSign(p.Direction) * (bars[len(bars)-1].Close - p.EntryPrice) * float64(p.Shares)
+	}
+	return total, nil
+}
@@ -0,0 +1,92 @@
+package marketdata
+
+import (
+	"fmt"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// ExcursionUpdate reports the price range stored for one position
+type ExcursionUpdate struct {
+	PositionID int     `json:"position_id"`
+	Ticker     string  `json:"ticker"`
+	Low        float64 `json:"low,omitempty"`
+	High       float64 `json:"high,omitempty"`
+	Through    string  `json:"through,omitempty"`
+	Skipped    string  `json:"skipped,omitempty"` // Why the range was left as it was
+}
+
+// UpdateExcursions widens the stored price range of each stock position
+// with the provider's daily highs and lows, from its entry date through its
+// exit date (open positions: the latest bar)
+//
+// Ranges only ever widen, so re-running over the same bars is safe and
+// manual marks are kept. Option positions and tickers without bars are
+// reported as skipped.
+func UpdateExcursions(db *storage.DB, provider domain.BarProvider, positions []storage.Position) ([]ExcursionUpdate, error) {
+	updates := make([]ExcursionUpdate, 0, len(positions))
+	for _, p := range positions {
+		update := ExcursionUpdate{PositionID: p.ID, Ticker: p.Ticker}
+		if p.InstrumentType == storage.InstrumentOption {
+			update.Skipped = "option position"
+			updates = append(updates, update)
+			continue
+		}
+		bars, err := provider.GetDailyBars(p.Ticker)
+		if err != nil {
+			update.Skipped = err.Error()
+			updates = append(updates, update)
+			continue
+		}
+
+		low, high := excursionRange(p)
+		low, high, last := domain.ExtendPriceRange(low, high, bars, p.TradeDate(), p.ExitDate)
+		if last == "" {
+			update.Skipped = "no bars since entry"
+			updates = append(updates, update)
+			continue
+		}
+		if last < p.ExcursionDate {
+			last = p.ExcursionDate
+		}
+
+		if err := db.SetPositionExcursion(p.ID, low, high, last); err != nil {
+			return nil, err
+		}
+		update.Low, update.High, update.Through = low, high, last
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+// MarkExcursion widens a position's stored price range with a price seen
+// on date (YYYY-MM-DD), e.g. an intraday low read off the broker's screen
+func MarkExcursion(db *storage.DB, p *storage.Position, price float64, date string) (*ExcursionUpdate, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("price must be positive, got %.2f", price)
+	}
+	if p.InstrumentType == storage.InstrumentOption {
+		return nil, fmt.Errorf("excursions are tracked for stock positions only")
+	}
+
+	low, high := excursionRange(*p)
+	low, high, _ = domain.ExtendPriceRange(low, high, []domain.Bar{{Date: date, Low: price, High: price}}, "", "")
+	if date < p.ExcursionDate {
+		date = p.ExcursionDate
+	}
+
+	if err := db.SetPositionExcursion(p.ID, low, high, date); err != nil {
+		return nil, err
+	}
+	return &ExcursionUpdate{PositionID: p.ID, Ticker: p.Ticker, Low: low, High: high, Through: date}, nil
+}
+
+// excursionRange is the position's stored range, or its entry price when
+// the range has never been tracked
+func excursionRange(p storage.Position) (float64, float64) {
+	if p.ExcursionLow > 0 {
+		return p.ExcursionLow, p.ExcursionHigh
+	}
+	return p.EntryPrice, p.EntryPrice
+}
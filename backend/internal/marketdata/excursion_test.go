@@ -0,0 +1,71 @@
+package marketdata
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+func TestUpdateExcursions(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	// Long AAPL at 100 with a 96 stop, opened today
+	today := time.Now().Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	_, err = db.SaveDecision(storage.Decision{
+		Date: today, Ticker: "AAPL", Action: "GO", Entry: 100, InitialStop: 96,
+		Shares: 50, RiskDollars: 200, Banner: "GREEN", Method: "stock",
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.ImportCandidates(today, []string{"AAPL"}, nil, "Technology", "Tech/Comm"))
+	position, err := db.OpenPosition("AAPL")
+	require.NoError(t, err)
+
+	_, err = db.SavePriceBars("AAPL", []storage.PriceBar{
+		{Date: yesterday, Open: 90, High: 95, Low: 85, Close: 94}, // Before entry: ignored
+		{Date: today, Open: 100, High: 104, Low: 98.5, Close: 103},
+	})
+	require.NoError(t, err)
+
+	updates, err := UpdateExcursions(db, NewDBBarProvider(db), []storage.Position{*position})
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Empty(t, updates[0].Skipped)
+	assert.Equal(t, 98.5, updates[0].Low)
+	assert.Equal(t, 104.0, updates[0].High)
+	assert.Equal(t, today, updates[0].Through)
+
+	// A manual mark widens the range and survives another bar update
+	position, err = db.GetPosition(position.ID)
+	require.NoError(t, err)
+	_, err = MarkExcursion(db, position, 97, today)
+	require.NoError(t, err)
+
+	position, err = db.GetPosition(position.ID)
+	require.NoError(t, err)
+	_, err = UpdateExcursions(db, NewDBBarProvider(db), []storage.Position{*position})
+	require.NoError(t, err)
+
+	position, err = db.GetPosition(position.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 97.0, position.ExcursionLow)
+	assert.Equal(t, 104.0, position.ExcursionHigh)
+
+	// Tickers without bars are skipped, not failed
+	updates, err = UpdateExcursions(db, NewDBBarProvider(db), []storage.Position{{ID: 99, Ticker: "NOPE"}})
+	require.NoError(t, err)
+	assert.Contains(t, updates[0].Skipped, "no stored price bars")
+
+	_, err = MarkExcursion(db, position, 0, today)
+	assert.Error(t, err)
+	_, err = MarkExcursion(db, &storage.Position{InstrumentType: storage.InstrumentOption}, 5, today)
+	assert.Error(t, err)
+}
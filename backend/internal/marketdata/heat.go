@@ -0,0 +1,131 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// HeatPositions converts open storage positions for the domain heat check
+func HeatPositions(positions []storage.Position) []domain.Position {
+	result := make([]domain.Position, len(positions))
+	for i, p := range positions {
+		result[i] = domain.Position{
+			Ticker:      p.Ticker,
+			Bucket:      p.Bucket,
+			RiskDollars: p.RiskDollars,
+			UnitsOpen:   max(1, p.CurrentUnits),
+			Status:      "Open",
+		}
+	}
+	return result
+}
+
+// PositionExposure values an open position's Greeks at the last stored
+// close of its underlying, or its entry price when there is none
+func PositionExposure(provider domain.BarProvider, position *storage.Position, iv, rate float64) (domain.Exposure, error) {
+	spot := position.EntryPrice
+	if position.InstrumentType == storage.InstrumentOption {
+		spot = position.UnderlyingAtEntry
+	}
+	if bars, err := provider.GetDailyBars(position.Ticker); err == nil && len(bars) > 0 {
+		spot = bars[len(bars)-1].Close
+	}
+
+	if position.InstrumentType != storage.InstrumentOption {
+		return domain.StockExposure(position.Direction, position.Shares, spot), nil
+	}
+
+	var legs []options.Leg
+	if err := json.Unmarshal([]byte(position.LegsJSON), &legs); err != nil {
+		return domain.Exposure{}, fmt.Errorf("invalid legs: %w", err)
+	}
+	market := options.Market{Spot: spot, Rate: rate, Vol: iv}
+	return domain.OptionExposure(legs, position.UnderlyingAtEntry, position.TradeDate(), market)
+}
+
+// EvaluateHeat checks a new trade in ticker against the heat caps in
+// settings, counting the open positions
+//
+// The correlated-cluster cap clusters the open tickers and the new one
+// from stored bars (a ticker without bars joins no cluster). With
+// HeatGreeks set to 1 the Greeks caps are checked as well: the open
+// positions are valued at the last stored close and addExposure is the
+// new trade's own exposure.
+func EvaluateHeat(db *storage.DB, equity float64, ticker, bucket string, addRisk float64, addExposure domain.Exposure) (*domain.HeatResult, error) {
+	ticker = strings.ToUpper(ticker)
+
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	heatCapPct, err := strconv.ParseFloat(settings[string(domain.SettingHeatCap)], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HeatCap_H_pct setting: %w", err)
+	}
+	bucketHeatCapPct, err := strconv.ParseFloat(settings[string(domain.SettingBucketHeatCap)], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BucketHeatCap_pct setting: %w", err)
+	}
+	threshold, clusterCapPct, err := domain.ClusterHeatFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open positions: %w", err)
+	}
+
+	req := domain.HeatRequest{
+		Equity:            equity,
+		HeatCapPct:        heatCapPct,
+		BucketHeatCapPct:  bucketHeatCapPct,
+		AddRiskDollars:    addRisk,
+		AddBucket:         bucket,
+		OpenPositions:     HeatPositions(positions),
+		AddTicker:         ticker,
+		ClusterHeatCapPct: clusterCapPct,
+	}
+
+	provider := NewDBBarProvider(db)
+	if clusterCapPct > 0 && len(positions) > 0 {
+		tickers := []string{ticker}
+		for _, p := range positions {
+			tickers = append(tickers, p.Ticker)
+		}
+		matrix, err := domain.CorrelateTickers(provider, tickers, domain.DefaultCorrelationLookback)
+		if err != nil {
+			return nil, err
+		}
+		req.Clusters = matrix.Clusters(threshold)
+	}
+
+	greeks := 0.0
+	if value, ok := settings[string(domain.SettingHeatGreeks)]; ok {
+		if greeks, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("invalid HeatGreeks setting: %w", err)
+		}
+	}
+	if greeks == 1 {
+		req.Mode = domain.HeatModeGreeks
+		if req.GreeksCaps, err = domain.GreeksHeatCapsFromSettings(settings); err != nil {
+			return nil, err
+		}
+		req.AddExposure = addExposure
+		for i := range positions {
+			exposure, err := PositionExposure(provider, &positions[i], 0, options.DefaultRiskFreeRate)
+			if err != nil {
+				return nil, fmt.Errorf("%s (position %d): %w", positions[i].Ticker, positions[i].ID, err)
+			}
+			req.OpenPositions[i].Exposure = exposure
+		}
+	}
+
+	return domain.CalculateHeat(req)
+}
@@ -0,0 +1,113 @@
+package marketdata
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// openHeatPosition opens a long stock position of 20 shares at 100 with
+// riskDollars of risk, tagged with bucket
+func openHeatPosition(t *testing.T, db *storage.DB, ticker, date, bucket string, riskDollars float64) {
+	t.Helper()
+	decision := storage.Decision{
+		Date: date, Ticker: ticker, Action: "GO", Entry: 100, InitialStop: 100 - riskDollars/20,
+		Shares: 20, RiskDollars: riskDollars, Banner: "GREEN", Method: "stock",
+	}
+	var err error
+	decision.ID, err = db.SaveDecision(decision)
+	require.NoError(t, err)
+	require.NoError(t, db.ImportCandidates(date, []string{ticker}, nil, "", bucket))
+	filledAt, err := time.Parse("2006-01-02", date)
+	require.NoError(t, err)
+	_, err = db.OpenPositionFromFill(&decision, 20, 100, 0, filledAt)
+	require.NoError(t, err)
+}
+
+// saveMovingTogether stores the same 70 daily closes for every ticker
+func saveMovingTogether(t *testing.T, db *storage.DB, tickers ...string) {
+	t.Helper()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := []storage.PriceBar{}
+	for i := 0; i < 70; i++ {
+		close := 100 + float64(i%5)
+		bars = append(bars, storage.PriceBar{
+			Date: start.AddDate(0, 0, i).Format("2006-01-02"),
+			Open: close, High: close + 1, Low: close - 1, Close: close,
+		})
+	}
+	for _, ticker := range tickers {
+		_, err := db.SavePriceBars(ticker, bars)
+		require.NoError(t, err)
+	}
+}
+
+func TestEvaluateHeat_CountsOpenPositionsAndClusters(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	openHeatPosition(t, db, "AAPL", "2025-03-03", "Tech/Comm", 100)
+	openHeatPosition(t, db, "MSFT", "2025-03-04", "Software", 100)
+
+	// NVDA has no bars yet, so it joins no cluster: only the dollar caps apply
+	result, err := EvaluateHeat(db, 10000, "nvda", "Semis", 50, domain.Exposure{})
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, result.CurrentPortfolioHeat)
+	assert.Equal(t, 250.0, result.NewPortfolioHeat)
+	assert.Nil(t, result.Cluster)
+	assert.True(t, result.Allowed)
+
+	// Moving together, the three tickers share a cluster whatever their bucket
+	saveMovingTogether(t, db, "AAPL", "MSFT", "NVDA")
+	result, err = EvaluateHeat(db, 10000, "NVDA", "Semis", 50, domain.Exposure{})
+	require.NoError(t, err)
+	require.NotNil(t, result.Cluster)
+	assert.ElementsMatch(t, []string{"AAPL", "MSFT", "NVDA"}, result.Cluster.Tickers)
+	assert.Equal(t, 250.0, result.Cluster.NewHeat)
+	assert.Equal(t, 200.0, result.Cluster.Cap)
+	assert.True(t, result.Cluster.Exceeded)
+	assert.Equal(t, domain.HeatMeasureCluster, result.RejectedMeasure)
+
+	// A zero cluster cap turns the check off
+	require.NoError(t, db.SetSetting(string(domain.SettingClusterHeatCap), "0"))
+	result, err = EvaluateHeat(db, 10000, "NVDA", "Semis", 50, domain.Exposure{})
+	require.NoError(t, err)
+	assert.Nil(t, result.Cluster)
+	assert.True(t, result.Allowed)
+}
+
+func TestEvaluateHeat_GreeksCaps(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Initialize())
+
+	openHeatPosition(t, db, "AAPL", "2025-03-03", "Tech/Comm", 100)
+	openHeatPosition(t, db, "MSFT", "2025-03-04", "Software", 100)
+	saveMovingTogether(t, db, "AAPL", "MSFT")
+	require.NoError(t, db.SetSetting(string(domain.SettingDeltaHeatCap), "0.5"))
+	addExposure := domain.StockExposure(domain.DirectionLong, 10, 100)
+
+	// Off by default
+	result, err := EvaluateHeat(db, 10000, "NVDA", "Semis", 50, addExposure)
+	require.NoError(t, err)
+	assert.Equal(t, domain.HeatModeDollar, result.Mode)
+	assert.Nil(t, result.Greeks)
+
+	// 2 × 20 shares at the last close of 104, plus $1,000 new, is over $5,000
+	require.NoError(t, db.SetSetting(string(domain.SettingHeatGreeks), "1"))
+	result, err = EvaluateHeat(db, 10000, "NVDA", "Semis", 50, addExposure)
+	require.NoError(t, err)
+	require.NotNil(t, result.Greeks)
+	assert.InDelta(t, 5160, result.Greeks.Portfolio.DeltaDollars, 1e-9)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, domain.HeatMeasureDelta, result.RejectedMeasure)
+}
@@ -0,0 +1,146 @@
+package marketdata
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// DBBarProvider serves daily bars from the price_bars table
+// Implements domain.BarProvider
+type DBBarProvider struct {
+	db *storage.DB
+}
+
+// NewDBBarProvider creates a bar provider backed by the price store
+func NewDBBarProvider(db *storage.DB) *DBBarProvider {
+	return &DBBarProvider{db: db}
+}
+
+// GetDailyBars returns every stored bar for a ticker, oldest first
+func (p *DBBarProvider) GetDailyBars(ticker string) ([]domain.Bar, error) {
+	stored, err := p.db.GetPriceBars(ticker, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("no stored price bars for %s (run import-prices first)", ticker)
+	}
+	return FromStorage(stored), nil
+}
+
+// FromStorage converts stored price bars to domain bars
+func FromStorage(stored []storage.PriceBar) []domain.Bar {
+	bars := make([]domain.Bar, len(stored))
+	for i, b := range stored {
+		bars[i] = domain.Bar{
+			Date:   b.Date,
+			Open:   b.Open,
+			High:   b.High,
+			Low:    b.Low,
+			Close:  b.Close,
+			Volume: b.Volume,
+		}
+	}
+	return bars
+}
+
+// ToStorage converts domain bars to storage rows tagged with their source file
+func ToStorage(ticker, source string, bars []domain.Bar) []storage.PriceBar {
+	stored := make([]storage.PriceBar, len(bars))
+	for i, b := range bars {
+		stored[i] = storage.PriceBar{
+			Ticker: ticker,
+			Date:   b.Date,
+			Open:   b.Open,
+			High:   b.High,
+			Low:    b.Low,
+			Close:  b.Close,
+			Volume: b.Volume,
+			Source: source,
+		}
+	}
+	return stored
+}
+
+// ImportResult summarizes one file loaded into the price store
+type ImportResult struct {
+	Ticker   string `json:"ticker"`
+	File     string `json:"file"`
+	Bars     int    `json:"bars"`
+	FirstBar string `json:"first_bar,omitempty"`
+	LastBar  string `json:"last_bar,omitempty"`
+}
+
+// ImportFile loads one <TICKER>.csv into the price store
+func ImportFile(db *storage.DB, path string) (*ImportResult, error) {
+	bars, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := TickerFromPath(path)
+	source := filepath.Base(path)
+	count, err := db.SavePriceBars(ticker, ToStorage(ticker, source, bars))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Ticker: ticker, File: source, Bars: count}
+	if len(bars) > 0 {
+		result.FirstBar = bars[0].Date
+		result.LastBar = bars[len(bars)-1].Date
+	}
+	return result, nil
+}
+
+// ImportDir loads every CSV file in a directory into the price store
+// Files are processed in name order; the first failure stops the import
+func ImportDir(db *storage.DB, dir string) ([]ImportResult, error) {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ImportResult, 0, len(files))
+	for _, path := range files {
+		result, err := ImportFile(db, path)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// CSVBarProvider serves daily bars straight from <TICKER>.csv files in a folder
+// Implements domain.BarProvider without importing into the database first
+type CSVBarProvider struct {
+	dir string
+}
+
+// NewCSVBarProvider creates a bar provider reading from a price folder
+func NewCSVBarProvider(dir string) *CSVBarProvider {
+	return &CSVBarProvider{dir: dir}
+}
+
+// GetDailyBars loads <dir>/<TICKER>.csv, oldest first
+func (p *CSVBarProvider) GetDailyBars(ticker string) ([]domain.Bar, error) {
+	return LoadFile(filepath.Join(p.dir, strings.ToUpper(ticker)+".csv"))
+}
+
+// Tickers lists the tickers that have a CSV file in the folder
+func (p *CSVBarProvider) Tickers() ([]string, error) {
+	files, err := ListFiles(p.dir)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]string, len(files))
+	for i, path := range files {
+		tickers[i] = TickerFromPath(path)
+	}
+	return tickers, nil
+}
@@ -0,0 +1,70 @@
+package marketdata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// EvaluateUnitLimits checks a new unit in ticker against the Turtle unit
+// limits in settings, counting the open positions' current units
+//
+// Closely correlated groups are the clusters of the open tickers and the
+// new one, from stored bars (a ticker without bars joins no cluster).
+func EvaluateUnitLimits(db *storage.DB, ticker, direction, bucket string) (*domain.UnitLimitResult, error) {
+	ticker = strings.ToUpper(ticker)
+
+	settings, err := db.GetAllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	limits, err := domain.UnitLimitsFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	threshold := domain.DefaultCorrelationThreshold
+	if value, ok := settings[string(domain.SettingCorrelationThreshold)]; ok {
+		if threshold, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("invalid CorrelationThreshold setting: %w", err)
+		}
+	}
+
+	positions, err := db.GetOpenPositions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open positions: %w", err)
+	}
+
+	tickers := []string{ticker}
+	open := make([]domain.UnitPosition, len(positions))
+	for i, p := range positions {
+		tickers = append(tickers, p.Ticker)
+		open[i] = domain.UnitPosition{
+			Ticker:    p.Ticker,
+			Bucket:    p.Bucket,
+			Direction: p.Direction,
+			Units:     p.CurrentUnits,
+			MaxUnits:  p.MaxUnits,
+		}
+	}
+
+	var clusters []domain.CorrelationCluster
+	if limits.Closely > 0 && len(positions) > 0 {
+		matrix, err := domain.CorrelateTickers(NewDBBarProvider(db), tickers, domain.DefaultCorrelationLookback)
+		if err != nil {
+			return nil, err
+		}
+		clusters = matrix.Clusters(threshold)
+	}
+
+	return domain.CheckUnitLimits(domain.UnitLimitRequest{
+		Ticker:        ticker,
+		Direction:     direction,
+		Bucket:        bucket,
+		Limits:        limits,
+		Clusters:      clusters,
+		OpenPositions: open,
+	})
+}
@@ -0,0 +1,195 @@
+// Package options prices option legs with Black-Scholes (Merton, with a
+// continuous dividend yield) and solves implied volatility from leg prices.
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Option types (match storage.OptionLeg.Type)
+const (
+	Call = "CALL"
+	Put  = "PUT"
+)
+
+// Pricing conventions
+const (
+	DaysPerYear         = 365.0
+	ContractMultiplier  = 100  // Shares per contract
+	DefaultRiskFreeRate = 0.04 // Continuously compounded, used when no rate is given
+)
+
+// Pricing errors
+var (
+	ErrInvalidType   = errors.New("option type must be CALL or PUT")
+	ErrInvalidInputs = errors.New("spot and strike must be positive")
+	ErrInvalidVol    = errors.New("volatility must be positive")
+	ErrNoIV          = errors.New("no implied volatility matches the price")
+)
+
+// Inputs are the Black-Scholes inputs for one option
+type Inputs struct {
+	Type     string  `json:"type"` // CALL or PUT
+	Spot     float64 `json:"spot"`
+	Strike   float64 `json:"strike"`
+	Years    float64 `json:"years"`     // Time to expiration; 0 or less = expired
+	Rate     float64 `json:"rate"`      // Risk-free rate, continuously compounded
+	DivYield float64 `json:"div_yield"` // Continuous dividend yield
+	Vol      float64 `json:"vol"`       // Annualized volatility (0.25 = 25%)
+}
+
+// Greeks are the value and sensitivities of an option (or a sum of options)
+type Greeks struct {
+	Price float64 `json:"price"`
+	Delta float64 `json:"delta"` // Per $1 move in the underlying
+	Gamma float64 `json:"gamma"` // Delta change per $1 move
+	Theta float64 `json:"theta"` // Per calendar day
+	Vega  float64 `json:"vega"`  // Per 1 point (0.01) of volatility
+}
+
+// ParseType normalizes an option type (CALL, C, PUT, P)
+func ParseType(s string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case Call, "C":
+		return Call, nil
+	case Put, "P":
+		return Put, nil
+	default:
+		return "", fmt.Errorf("%w: got '%s'", ErrInvalidType, s)
+	}
+}
+
+// normCDF is the standard normal cumulative distribution
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normPDF is the standard normal density
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+// Compute returns the Black-Scholes value and Greeks of one long option
+//
+//	d1 = (ln(S/K) + (r - q + σ²/2)T) / (σ√T),  d2 = d1 - σ√T
+//	Call = S·e^(-qT)·N(d1) - K·e^(-rT)·N(d2)
+//	Put  = K·e^(-rT)·N(-d2) - S·e^(-qT)·N(-d1)
+//
+// An expired option (Years <= 0) is worth its intrinsic value, with a
+// delta of ±1 in the money and 0 otherwise.
+func Compute(in Inputs) (*Greeks, error) {
+	optType, err := ParseType(in.Type)
+	if err != nil {
+		return nil, err
+	}
+	if in.Spot <= 0 || in.Strike <= 0 {
+		return nil, fmt.Errorf("%w: spot %.2f, strike %.2f", ErrInvalidInputs, in.Spot, in.Strike)
+	}
+
+	if in.Years <= 0 {
+		g := &Greeks{}
+		if optType == Call && in.Spot > in.Strike {
+			g.Price, g.Delta = in.Spot-in.Strike, 1
+		}
+		if optType == Put && in.Spot < in.Strike {
+			g.Price, g.Delta = in.Strike-in.Spot, -1
+		}
+		return g, nil
+	}
+	if in.Vol <= 0 {
+		return nil, fmt.Errorf("%w: got %.4f", ErrInvalidVol, in.Vol)
+	}
+
+	sqrtT := math.Sqrt(in.Years)
+	d1 := (math.Log(in.Spot/in.Strike) + (in.Rate-in.DivYield+in.Vol*in.Vol/2)*in.Years) / (in.Vol * sqrtT)
+	d2 := d1 - in.Vol*sqrtT
+	divDiscount := math.Exp(-in.DivYield * in.Years)
+	rateDiscount := math.Exp(-in.Rate * in.Years)
+
+	g := &Greeks{
+		Gamma: divDiscount * normPDF(d1) / (in.Spot * in.Vol * sqrtT),
+		Vega:  in.Spot * divDiscount * normPDF(d1) * sqrtT / 100,
+	}
+	decay := -in.Spot * divDiscount * normPDF(d1) * in.Vol / (2 * sqrtT)
+
+	if optType == Call {
+		g.Price = in.Spot*divDiscount*normCDF(d1) - in.Strike*rateDiscount*normCDF(d2)
+		g.Delta = divDiscount * normCDF(d1)
+		g.Theta = decay - in.Rate*in.Strike*rateDiscount*normCDF(d2) + in.DivYield*in.Spot*divDiscount*normCDF(d1)
+	} else {
+		g.Price = in.Strike*rateDiscount*normCDF(-d2) - in.Spot*divDiscount*normCDF(-d1)
+		g.Delta = -divDiscount * normCDF(-d1)
+		g.Theta = decay + in.Rate*in.Strike*rateDiscount*normCDF(-d2) - in.DivYield*in.Spot*divDiscount*normCDF(-d1)
+	}
+	g.Theta /= DaysPerYear
+
+	return g, nil
+}
+
+// Price returns the Black-Scholes value of one option
+func Price(in Inputs) (float64, error) {
+	g, err := Compute(in)
+	if err != nil {
+		return 0, err
+	}
+	return g.Price, nil
+}
+
+// ImpliedVol solves for the volatility at which the option is worth price
+//
+// Newton-Raphson on vega from a 30% guess, falling back to bisection over
+// 0.1%-500% when a step leaves the bracket. Prices outside the no-arbitrage
+// bounds return ErrNoIV.
+func ImpliedVol(in Inputs, price float64) (float64, error) {
+	const (
+		tolerance = 1e-8
+		lowVol    = 0.001
+		highVol   = 5.0
+	)
+
+	if in.Years <= 0 {
+		return 0, fmt.Errorf("%w: option has expired", ErrNoIV)
+	}
+	in.Vol = lowVol
+	low, err := Price(in)
+	if err != nil {
+		return 0, err
+	}
+	in.Vol = highVol
+	high, _ := Price(in)
+	if price < low-tolerance || price > high+tolerance {
+		return 0, fmt.Errorf("%w: %.4f is outside %.4f - %.4f", ErrNoIV, price, low, high)
+	}
+
+	lo, hi := lowVol, highVol
+	vol := 0.3
+	for i := 0; i < 100; i++ {
+		in.Vol = vol
+		g, err := Compute(in)
+		if err != nil {
+			return 0, err
+		}
+		diff := g.Price - price
+		if math.Abs(diff) < tolerance {
+			return vol, nil
+		}
+		if diff > 0 {
+			hi = vol
+		} else {
+			lo = vol
+		}
+
+		next := vol - diff/(g.Vega*100)
+		if g.Vega <= 0 || next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		if hi-lo < tolerance {
+			return next, nil
+		}
+		vol = next
+	}
+	return vol, nil
+}
@@ -0,0 +1,104 @@
+package options
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// atm is the textbook at-the-money example: S = K = 100, one year, r = 5%, σ = 20%
+func atm(optType string) Inputs {
+	return Inputs{Type: optType, Spot: 100, Strike: 100, Years: 1, Rate: 0.05, Vol: 0.2}
+}
+
+func TestCompute_Call(t *testing.T) {
+	g, err := Compute(atm(Call))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 10.4506, g.Price, 1e-4)
+	assert.InDelta(t, 0.6368, g.Delta, 1e-4)
+	assert.InDelta(t, 0.01876, g.Gamma, 1e-5)
+	assert.InDelta(t, 0.3752, g.Vega, 1e-4)      // Per vol point
+	assert.InDelta(t, -6.414/365, g.Theta, 1e-4) // Per day
+}
+
+func TestCompute_Put(t *testing.T) {
+	g, err := Compute(atm(Put))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 5.5735, g.Price, 1e-4)
+	assert.InDelta(t, -0.3632, g.Delta, 1e-4)
+	assert.InDelta(t, 0.01876, g.Gamma, 1e-5)
+	assert.InDelta(t, -1.658/365, g.Theta, 1e-4)
+}
+
+func TestCompute_PutCallParityWithDividends(t *testing.T) {
+	call, put := atm(Call), atm(Put)
+	call.DivYield, put.DivYield = 0.03, 0.03
+	call.Strike, put.Strike = 95, 95
+
+	c, err := Compute(call)
+	require.NoError(t, err)
+	p, err := Compute(put)
+	require.NoError(t, err)
+
+	// C - P = S·e^(-qT) - K·e^(-rT)
+	assert.InDelta(t, 100*math.Exp(-0.03)-95*math.Exp(-0.05), c.Price-p.Price, 1e-9)
+	assert.InDelta(t, math.Exp(-0.03), c.Delta-p.Delta, 1e-9)
+}
+
+func TestCompute_Expired(t *testing.T) {
+	in := atm(Put)
+	in.Years = 0
+	in.Spot = 92
+
+	g, err := Compute(in)
+	require.NoError(t, err)
+	assert.InDelta(t, 8, g.Price, 1e-9)
+	assert.Equal(t, -1.0, g.Delta)
+	assert.Zero(t, g.Gamma)
+}
+
+func TestCompute_InvalidInputs(t *testing.T) {
+	in := atm("STRADDLE")
+	_, err := Compute(in)
+	assert.ErrorIs(t, err, ErrInvalidType)
+
+	in = atm(Call)
+	in.Vol = 0
+	_, err = Compute(in)
+	assert.ErrorIs(t, err, ErrInvalidVol)
+
+	in = atm(Call)
+	in.Strike = 0
+	_, err = Compute(in)
+	assert.ErrorIs(t, err, ErrInvalidInputs)
+}
+
+func TestImpliedVol_RoundTrip(t *testing.T) {
+	for _, vol := range []float64{0.05, 0.2, 0.35, 0.9, 2.5} {
+		for _, optType := range []string{Call, Put} {
+			in := atm(optType)
+			in.Strike = 110
+			in.Years = 0.25
+			in.Vol = vol
+			price, err := Price(in)
+			require.NoError(t, err)
+
+			iv, err := ImpliedVol(in, price)
+			require.NoError(t, err)
+			assert.InDelta(t, vol, iv, 1e-6, "%s at %.2f", optType, vol)
+		}
+	}
+}
+
+func TestImpliedVol_OutsideBounds(t *testing.T) {
+	in := atm(Call)
+	_, err := ImpliedVol(in, 150) // More than the stock
+	assert.ErrorIs(t, err, ErrNoIV)
+
+	_, err = ImpliedVol(in, 0.01) // Less than the discounted intrinsic value
+	assert.ErrorIs(t, err, ErrNoIV)
+}
@@ -0,0 +1,160 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Leg is one option leg; fields match storage.OptionLeg, so a stored leg
+// converts directly with options.Leg(leg)
+type Leg struct {
+	Type   string  `json:"type"`   // CALL or PUT
+	Strike float64 `json:"strike"` // Strike price
+	Exp    string  `json:"exp"`    // Expiration date (YYYY-MM-DD)
+	Qty    int     `json:"qty"`    // Number of contracts
+	Action string  `json:"action"` // BUY or SELL
+	Price  float64 `json:"price"`  // Price per share; used to solve IV
+}
+
+// Market holds the pricing inputs shared by every leg of a position
+type Market struct {
+	Spot     float64 `json:"spot"`
+	Rate     float64 `json:"rate"`            // Risk-free rate, continuously compounded
+	DivYield float64 `json:"div_yield"`       // Continuous dividend yield
+	Vol      float64 `json:"vol,omitempty"`   // Use this volatility instead of solving IV from leg prices
+	AsOf     string  `json:"as_of,omitempty"` // Pricing date YYYY-MM-DD (default today)
+}
+
+// LegGreeks is the pricing of one leg
+type LegGreeks struct {
+	Leg      Leg     `json:"leg"`
+	DTE      int     `json:"dte"`
+	IV       float64 `json:"iv"`        // Volatility used (solved from Leg.Price unless Market.Vol is set)
+	PerShare Greeks  `json:"per_share"` // One long option
+	Position Greeks  `json:"position"`  // × Qty × 100, negative for SELL legs
+}
+
+// PositionGreeks is the pricing of every leg and their total
+type PositionGreeks struct {
+	Spot  float64     `json:"spot"`
+	AsOf  string      `json:"as_of"`
+	Legs  []LegGreeks `json:"legs"`
+	Total Greeks      `json:"total"` // Net value (credit negative) and share-equivalent Greeks
+}
+
+// Leg errors
+var (
+	ErrInvalidAction = errors.New("leg action must be BUY or SELL")
+	ErrNoVol         = errors.New("leg needs a price to solve IV, or a volatility")
+)
+
+// ActionSign is +1 for a BUY leg and -1 for a SELL leg
+func ActionSign(action string) (float64, error) {
+	switch strings.ToUpper(strings.TrimSpace(action)) {
+	case "BUY", "LONG", "":
+		return 1, nil
+	case "SELL", "SHORT":
+		return -1, nil
+	default:
+		return 0, fmt.Errorf("%w: got '%s'", ErrInvalidAction, action)
+	}
+}
+
+// DaysToExpiration counts calendar days from asOf to exp (both YYYY-MM-DD)
+func DaysToExpiration(exp, asOf string) (int, error) {
+	expDate, err := time.Parse("2006-01-02", exp)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expiration %q (use YYYY-MM-DD)", exp)
+	}
+	asOfDate, err := time.Parse("2006-01-02", asOf)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pricing date %q (use YYYY-MM-DD)", asOf)
+	}
+	return int(expDate.Sub(asOfDate).Hours() / 24), nil
+}
+
+// PriceLeg values one leg and returns its Greeks
+// Volatility is Market.Vol when set, otherwise the IV solved from Leg.Price.
+func PriceLeg(leg Leg, m Market) (*LegGreeks, error) {
+	optType, err := ParseType(leg.Type)
+	if err != nil {
+		return nil, err
+	}
+	sign, err := ActionSign(leg.Action)
+	if err != nil {
+		return nil, err
+	}
+	asOf := m.AsOf
+	if asOf == "" {
+		asOf = time.Now().Format("2006-01-02")
+	}
+	dte, err := DaysToExpiration(leg.Exp, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Inputs{
+		Type:     optType,
+		Spot:     m.Spot,
+		Strike:   leg.Strike,
+		Years:    float64(dte) / DaysPerYear,
+		Rate:     m.Rate,
+		DivYield: m.DivYield,
+		Vol:      m.Vol,
+	}
+	if in.Years > 0 && in.Vol <= 0 {
+		if leg.Price <= 0 {
+			return nil, fmt.Errorf("%w: %s %.2f %s", ErrNoVol, optType, leg.Strike, leg.Exp)
+		}
+		if in.Vol, err = ImpliedVol(in, leg.Price); err != nil {
+			return nil, fmt.Errorf("%s %.2f %s: %w", optType, leg.Strike, leg.Exp, err)
+		}
+	}
+
+	g, err := Compute(in)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := sign * float64(leg.Qty) * ContractMultiplier
+	return &LegGreeks{
+		Leg:      leg,
+		DTE:      dte,
+		IV:       in.Vol,
+		PerShare: *g,
+		Position: Greeks{
+			Price: g.Price * scale,
+			Delta: g.Delta * scale,
+			Gamma: g.Gamma * scale,
+			Theta: g.Theta * scale,
+			Vega:  g.Vega * scale,
+		},
+	}, nil
+}
+
+// PricePosition prices every leg and sums their position Greeks
+func PricePosition(legs []Leg, m Market) (*PositionGreeks, error) {
+	if len(legs) == 0 {
+		return nil, errors.New("position has no option legs")
+	}
+	if m.AsOf == "" {
+		m.AsOf = time.Now().Format("2006-01-02")
+	}
+
+	result := &PositionGreeks{Spot: m.Spot, AsOf: m.AsOf, Legs: make([]LegGreeks, 0, len(legs))}
+	for _, leg := range legs {
+		lg, err := PriceLeg(leg, m)
+		if err != nil {
+			return nil, err
+		}
+		result.Legs = append(result.Legs, *lg)
+		result.Total.Price += lg.Position.Price
+		result.Total.Delta += lg.Position.Delta
+		result.Total.Gamma += lg.Position.Gamma
+		result.Total.Theta += lg.Position.Theta
+		result.Total.Vega += lg.Position.Vega
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// oneYear prices with r = 5% on 2024-01-01; legs expiring 2024-12-31 have one year left
+var oneYear = Market{Spot: 100, Rate: 0.05, AsOf: "2024-01-01"}
+
+func TestPriceLeg_SolvesIVFromPrice(t *testing.T) {
+	leg := Leg{Type: "CALL", Strike: 100, Exp: "2024-12-31", Qty: 2, Action: "BUY", Price: 10.4506}
+
+	lg, err := PriceLeg(leg, oneYear)
+	require.NoError(t, err)
+
+	assert.Equal(t, 365, lg.DTE)
+	assert.InDelta(t, 0.2, lg.IV, 1e-4)
+	assert.InDelta(t, 0.6368, lg.PerShare.Delta, 1e-4)
+	assert.InDelta(t, 127.36, lg.Position.Delta, 1e-2) // 0.6368 × 2 × 100
+	assert.InDelta(t, 2090.12, lg.Position.Price, 1e-1)
+}
+
+func TestPriceLeg_SellLegFlipsSign(t *testing.T) {
+	m := oneYear
+	m.Vol = 0.2
+	leg := Leg{Type: "PUT", Strike: 100, Exp: "2024-12-31", Qty: 1, Action: "SELL"}
+
+	lg, err := PriceLeg(leg, m)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.2, lg.IV, 1e-12)
+	assert.InDelta(t, -0.3632, lg.PerShare.Delta, 1e-4)
+	assert.InDelta(t, 36.32, lg.Position.Delta, 1e-2) // Short put is long delta
+	assert.Less(t, lg.Position.Gamma, 0.0)
+	assert.Greater(t, lg.Position.Theta, 0.0) // Collects decay
+}
+
+func TestPriceLeg_Errors(t *testing.T) {
+	_, err := PriceLeg(Leg{Type: "CALL", Strike: 100, Exp: "2024-12-31", Action: "BUY"}, oneYear)
+	assert.ErrorIs(t, err, ErrNoVol)
+
+	_, err = PriceLeg(Leg{Type: "CALL", Strike: 100, Exp: "2024-12-31", Action: "HOLD", Price: 5}, oneYear)
+	assert.ErrorIs(t, err, ErrInvalidAction)
+
+	_, err = PriceLeg(Leg{Type: "CALL", Strike: 100, Exp: "12/31/2024", Action: "BUY", Price: 5}, oneYear)
+	assert.Error(t, err)
+}
+
+func TestPricePosition_BullCallSpread(t *testing.T) {
+	m := oneYear
+	m.Vol = 0.2
+	legs := []Leg{
+		{Type: "CALL", Strike: 100, Exp: "2024-12-31", Qty: 1, Action: "BUY"},
+		{Type: "CALL", Strike: 110, Exp: "2024-12-31", Qty: 1, Action: "SELL"},
+	}
+
+	pos, err := PricePosition(legs, m)
+	require.NoError(t, err)
+	require.Len(t, pos.Legs, 2)
+
+	long, short := pos.Legs[0].Position, pos.Legs[1].Position
+	assert.InDelta(t, long.Delta+short.Delta, pos.Total.Delta, 1e-9)
+	assert.InDelta(t, long.Price+short.Price, pos.Total.Price, 1e-9)
+	assert.Greater(t, pos.Total.Delta, 0.0)
+	assert.Less(t, pos.Total.Delta, long.Delta)
+	assert.Greater(t, pos.Total.Price, 0.0) // Net debit
+	assert.Less(t, pos.Total.Price, 1000.0) // Worth less than the $10 width
+
+	_, err = PricePosition(nil, m)
+	assert.Error(t, err)
+}
@@ -0,0 +1,341 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Stock is the leg type for shares held with the options (e.g. a covered
+// call). A stock leg's Qty is shares, its Price is the cost per share, and
+// its Strike is ignored.
+const Stock = "STOCK"
+
+// payoffGridPoints is how many evenly spaced prices the payoff scan adds to
+// the strikes
+const payoffGridPoints = 1000
+
+// Evaluator values a set of legs on one date
+//
+// Legs that have expired by the date are worth their intrinsic value;
+// legs still open are valued with Black-Scholes at the volatility solved
+// from their price (or Market.Vol).
+type Evaluator struct {
+	legs     []Leg
+	signs    []float64
+	years    []float64 // Time left on each leg at the date (0 = expired)
+	vols     []float64
+	rate     float64
+	divYield float64
+	date     string
+}
+
+// Payoff is the P&L profile of a set of legs, taken at the nearest expiration
+type Payoff struct {
+	EvalDate        string    `json:"eval_date"` // Nearest expiration; later legs are still open here
+	NetDebit        float64   `json:"net_debit"` // Negative for a credit
+	MaxProfit       float64   `json:"max_profit"`
+	MaxLoss         float64   `json:"max_loss"` // Positive dollars
+	UnlimitedProfit bool      `json:"unlimited_profit"`
+	UnlimitedLoss   bool      `json:"unlimited_loss"`
+	Breakevens      []float64 `json:"breakevens"` // Ascending underlying prices
+
+	profitAbove bool // A lone breakeven has its profit above it
+}
+
+// Payoff errors
+var ErrNoLegs = errors.New("position has no legs")
+
+// LegMultiplier is the shares per unit of Qty: 100 for options, 1 for stock
+func LegMultiplier(leg Leg) float64 {
+	if leg.Type == Stock {
+		return 1
+	}
+	return ContractMultiplier
+}
+
+// NetDebit is the cost of opening the legs in dollars (negative = credit)
+func NetDebit(legs []Leg) float64 {
+	net := 0.0
+	for _, leg := range legs {
+		sign, err := ActionSign(leg.Action)
+		if err != nil {
+			continue
+		}
+		net += sign * leg.Price * float64(leg.Qty) * LegMultiplier(leg)
+	}
+	return net
+}
+
+// NearestExpiration returns the earliest option expiration among the legs
+func NearestExpiration(legs []Leg) string {
+	nearest := ""
+	for _, leg := range legs {
+		if leg.Type == Stock || leg.Exp == "" {
+			continue
+		}
+		if nearest == "" || leg.Exp < nearest {
+			nearest = leg.Exp
+		}
+	}
+	return nearest
+}
+
+// NewEvaluator prepares the legs for valuation on date (YYYY-MM-DD)
+//
+// Volatility for legs still open on date is Market.Vol when set, otherwise
+// the IV solved from the leg's price as of Market.AsOf. Without a
+// Market.Spot the IV is solved as if the leg were at the money.
+func NewEvaluator(legs []Leg, m Market, date string) (*Evaluator, error) {
+	if len(legs) == 0 {
+		return nil, ErrNoLegs
+	}
+	if m.AsOf == "" {
+		m.AsOf = time.Now().Format("2006-01-02")
+	}
+	if date == "" {
+		date = m.AsOf
+	}
+
+	e := &Evaluator{
+		legs:     append([]Leg(nil), legs...), // Leg types are normalized below
+		signs:    make([]float64, len(legs)),
+		years:    make([]float64, len(legs)),
+		vols:     make([]float64, len(legs)),
+		rate:     m.Rate,
+		divYield: m.DivYield,
+		date:     date,
+	}
+	for i, leg := range legs {
+		sign, err := ActionSign(leg.Action)
+		if err != nil {
+			return nil, err
+		}
+		e.signs[i] = sign
+		if leg.Type == Stock {
+			continue
+		}
+		if e.legs[i].Type, err = ParseType(leg.Type); err != nil {
+			return nil, err
+		}
+
+		dte, err := DaysToExpiration(leg.Exp, date)
+		if err != nil {
+			return nil, err
+		}
+		if dte <= 0 {
+			continue
+		}
+		e.years[i] = float64(dte) / DaysPerYear
+
+		if e.vols[i] = m.Vol; e.vols[i] > 0 {
+			continue
+		}
+		spot := m.Spot
+		if spot <= 0 {
+			spot = leg.Strike
+		}
+		priced, err := PriceLeg(leg, Market{Spot: spot, Rate: m.Rate, DivYield: m.DivYield, AsOf: m.AsOf})
+		if err != nil {
+			return nil, err
+		}
+		if priced.IV <= 0 {
+			return nil, fmt.Errorf("%w: %s %.2f %s has expired as of %s", ErrNoVol, leg.Type, leg.Strike, leg.Exp, m.AsOf)
+		}
+		e.vols[i] = priced.IV
+	}
+	return e, nil
+}
+
+// Date is the valuation date
+func (e *Evaluator) Date() string {
+	return e.date
+}
+
+// PnL is the dollar P&L of the legs with the underlying at price
+func (e *Evaluator) PnL(price float64) float64 {
+	pnl := 0.0
+	for i, leg := range e.legs {
+		value := price
+		if leg.Type != Stock {
+			value = e.optionValue(i, price)
+		}
+		pnl += e.signs[i] * (value - leg.Price) * float64(leg.Qty) * LegMultiplier(leg)
+	}
+	return pnl
+}
+
+// optionValue is one share of leg i with the underlying at price
+func (e *Evaluator) optionValue(i int, price float64) float64 {
+	leg := e.legs[i]
+	if e.years[i] <= 0 || price <= 0 {
+		if leg.Type == Call {
+			return math.Max(price-leg.Strike, 0)
+		}
+		return math.Max(leg.Strike-price, 0)
+	}
+	value, err := Price(Inputs{
+		Type:     leg.Type,
+		Spot:     price,
+		Strike:   leg.Strike,
+		Years:    e.years[i],
+		Rate:     e.rate,
+		DivYield: e.divYield,
+		Vol:      e.vols[i],
+	})
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// PriceRange returns the highest price worth scanning: twice the largest
+// strike, stock cost or spot
+func PriceRange(legs []Leg, spot float64) float64 {
+	top := spot
+	for _, leg := range legs {
+		level := leg.Strike
+		if leg.Type == Stock {
+			level = leg.Price
+		}
+		top = math.Max(top, level)
+	}
+	return 2 * top
+}
+
+// AnalyzePayoff scans the P&L at the nearest expiration for max profit,
+// max loss and every breakeven
+//
+// Prices from zero to twice the highest strike are scanned, including
+// every strike, so single-expiration payoffs (straight lines between
+// strikes) are exact. Legs expiring later are valued with Black-Scholes.
+// Profit or loss is unlimited when the P&L keeps rising or falling past
+// the top of the scan; the matching MaxProfit or MaxLoss is then 0.
+func AnalyzePayoff(legs []Leg, m Market) (*Payoff, error) {
+	e, err := NewEvaluator(legs, m, NearestExpiration(legs))
+	if err != nil {
+		return nil, err
+	}
+
+	top := PriceRange(legs, m.Spot)
+	if top <= 0 {
+		return nil, fmt.Errorf("legs need a strike or price to scan")
+	}
+	prices := scanPrices(legs, top)
+
+	pnls := make([]float64, len(prices))
+	for i, price := range prices {
+		pnls[i] = e.PnL(price)
+	}
+
+	p := &Payoff{
+		EvalDate:  e.Date(),
+		NetDebit:  NetDebit(legs),
+		MaxProfit: math.Inf(-1),
+		MaxLoss:   math.Inf(-1),
+	}
+	for _, pnl := range pnls {
+		p.MaxProfit = math.Max(p.MaxProfit, pnl)
+		p.MaxLoss = math.Max(p.MaxLoss, -pnl)
+	}
+	p.MaxProfit, p.MaxLoss = roundCents(p.MaxProfit), roundCents(p.MaxLoss)
+
+	// Slope past the scan decides whether the tail is unbounded
+	tolerance := 1e-6 * math.Max(1, math.Abs(p.NetDebit))
+	tail := e.PnL(2*top) - pnls[len(pnls)-1]
+	if tail > tolerance {
+		p.UnlimitedProfit, p.MaxProfit = true, 0
+	}
+	if tail < -tolerance {
+		p.UnlimitedLoss, p.MaxLoss = true, 0
+	}
+
+	p.Breakevens = findBreakevens(e, prices, pnls)
+	if len(p.Breakevens) == 1 {
+		p.profitAbove = e.PnL(p.Breakevens[0]+0.01) > 0
+	}
+	return p, nil
+}
+
+// Range returns the lower and upper breakevens in the shape the trade
+// sessions store: the outermost two, or a lone breakeven as upper when
+// the profit is above it (long call) and lower otherwise (long put)
+func (p *Payoff) Range() (lower, upper float64) {
+	switch n := len(p.Breakevens); {
+	case n == 0:
+		return 0, 0
+	case n == 1 && p.profitAbove:
+		return 0, p.Breakevens[0]
+	case n == 1:
+		return p.Breakevens[0], 0
+	default:
+		return p.Breakevens[0], p.Breakevens[n-1]
+	}
+}
+
+// scanPrices is zero, every strike and an even grid up to top, ascending
+func scanPrices(legs []Leg, top float64) []float64 {
+	prices := make([]float64, 0, payoffGridPoints+len(legs)+1)
+	for i := 0; i <= payoffGridPoints; i++ {
+		prices = append(prices, top*float64(i)/payoffGridPoints)
+	}
+	for _, leg := range legs {
+		if leg.Type != Stock {
+			prices = append(prices, leg.Strike)
+		}
+	}
+	sort.Float64s(prices)
+
+	unique := prices[:1]
+	for _, price := range prices[1:] {
+		if price > unique[len(unique)-1] {
+			unique = append(unique, price)
+		}
+	}
+	return unique
+}
+
+// findBreakevens locates every price where the P&L changes sign
+//
+// A crossing between two scan points is refined by bisection. When the
+// P&L sits at exactly zero across several points, the first of them is
+// the breakeven.
+func findBreakevens(e *Evaluator, prices, pnls []float64) []float64 {
+	breakevens := []float64{}
+	last := -1 // Index of the last point with a non-zero P&L
+	for i, pnl := range pnls {
+		if pnl == 0 {
+			continue
+		}
+		if last >= 0 && (pnl > 0) != (pnls[last] > 0) {
+			if last == i-1 {
+				breakevens = append(breakevens, math.Round(bisect(e, prices[last], prices[i])*1e4)/1e4)
+			} else {
+				breakevens = append(breakevens, prices[last+1])
+			}
+		}
+		last = i
+	}
+	return breakevens
+}
+
+// roundCents rounds dollars to the cent
+func roundCents(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// bisect narrows a sign change of the P&L between lo and hi to a price
+func bisect(e *Evaluator, lo, hi float64) float64 {
+	loPositive := e.PnL(lo) > 0
+	for i := 0; i < 60 && hi-lo > 1e-9; i++ {
+		mid := (lo + hi) / 2
+		if (e.PnL(mid) > 0) == loPositive {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
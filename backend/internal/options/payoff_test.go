@@ -0,0 +1,169 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzePayoff_BullCallSpread(t *testing.T) {
+	legs := []Leg{
+		{Type: "CALL", Strike: 180, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 3.50},
+		{Type: "CALL", Strike: 185, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 1.25},
+	}
+
+	p, err := AnalyzePayoff(legs, Market{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-12-19", p.EvalDate)
+	assert.InDelta(t, 225.0, p.NetDebit, 1e-9)
+	assert.Equal(t, 275.0, p.MaxProfit)
+	assert.Equal(t, 225.0, p.MaxLoss)
+	assert.False(t, p.UnlimitedProfit)
+	assert.False(t, p.UnlimitedLoss)
+	assert.Equal(t, []float64{182.25}, p.Breakevens)
+
+	lower, upper := p.Range()
+	assert.Zero(t, lower)
+	assert.Equal(t, 182.25, upper)
+}
+
+func TestAnalyzePayoff_IronCondor(t *testing.T) {
+	legs := []Leg{
+		{Type: "PUT", Strike: 160, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 0.50},
+		{Type: "PUT", Strike: 165, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 1.20},
+		{Type: "CALL", Strike: 185, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 1.30},
+		{Type: "CALL", Strike: 190, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 0.60},
+	}
+
+	p, err := AnalyzePayoff(legs, Market{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 140.0, p.MaxProfit)
+	assert.Equal(t, 360.0, p.MaxLoss)
+	assert.Equal(t, []float64{163.6, 186.4}, p.Breakevens)
+}
+
+func TestAnalyzePayoff_CallRatioBackspread(t *testing.T) {
+	// Sell 1 100 call for $5, buy 2 110 calls for $2: $1 credit
+	legs := []Leg{
+		{Type: "CALL", Strike: 100, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 5},
+		{Type: "CALL", Strike: 110, Exp: "2025-12-19", Qty: 2, Action: "BUY", Price: 2},
+	}
+
+	p, err := AnalyzePayoff(legs, Market{})
+	require.NoError(t, err)
+
+	assert.True(t, p.UnlimitedProfit)
+	assert.Zero(t, p.MaxProfit)
+	assert.False(t, p.UnlimitedLoss)
+	assert.Equal(t, 900.0, p.MaxLoss) // At the long strike
+	assert.Equal(t, []float64{101, 119}, p.Breakevens)
+}
+
+func TestAnalyzePayoff_NakedCallUnlimitedLoss(t *testing.T) {
+	legs := []Leg{{Type: "CALL", Strike: 50, Exp: "2025-12-19", Qty: 2, Action: "SELL", Price: 1.5}}
+
+	p, err := AnalyzePayoff(legs, Market{})
+	require.NoError(t, err)
+
+	assert.True(t, p.UnlimitedLoss)
+	assert.Zero(t, p.MaxLoss)
+	assert.Equal(t, 300.0, p.MaxProfit)
+	assert.Equal(t, []float64{51.5}, p.Breakevens)
+
+	lower, upper := p.Range()
+	assert.Equal(t, 51.5, lower) // Profit is below it
+	assert.Zero(t, upper)
+}
+
+func TestAnalyzePayoff_CoveredCall(t *testing.T) {
+	legs := []Leg{
+		{Type: Stock, Qty: 100, Action: "BUY", Price: 100},
+		{Type: "CALL", Strike: 105, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 2},
+	}
+
+	p, err := AnalyzePayoff(legs, Market{})
+	require.NoError(t, err)
+
+	assert.InDelta(t, 9800.0, p.NetDebit, 1e-9)
+	assert.Equal(t, 700.0, p.MaxProfit) // Called away at 105
+	assert.Equal(t, 9800.0, p.MaxLoss)  // Stock to zero, premium kept
+	assert.Equal(t, []float64{98}, p.Breakevens)
+	assert.False(t, p.UnlimitedProfit)
+	assert.False(t, p.UnlimitedLoss)
+}
+
+func TestAnalyzePayoff_BrokenWingButterfly(t *testing.T) {
+	// 100/95/85 put butterfly for even money: no risk above 100
+	legs := []Leg{
+		{Type: "PUT", Strike: 100, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 6},
+		{Type: "PUT", Strike: 95, Exp: "2025-12-19", Qty: 2, Action: "SELL", Price: 3.5},
+		{Type: "PUT", Strike: 85, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 1},
+	}
+
+	p, err := AnalyzePayoff(legs, Market{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 500.0, p.MaxProfit)
+	assert.Equal(t, 500.0, p.MaxLoss)
+	assert.Equal(t, []float64{90}, p.Breakevens)
+
+	_, upper := p.Range()
+	assert.Equal(t, 90.0, upper)
+}
+
+func TestAnalyzePayoff_CalendarValuesFarLeg(t *testing.T) {
+	m := Market{Spot: 100, Vol: 0.3, AsOf: "2024-01-01"}
+	near, err := Price(Inputs{Type: Call, Spot: 100, Strike: 100, Years: 30 / DaysPerYear, Vol: 0.3})
+	require.NoError(t, err)
+	far, err := Price(Inputs{Type: Call, Spot: 100, Strike: 100, Years: 60 / DaysPerYear, Vol: 0.3})
+	require.NoError(t, err)
+
+	legs := []Leg{
+		{Type: "CALL", Strike: 100, Exp: "2024-01-31", Qty: 1, Action: "SELL", Price: near},
+		{Type: "CALL", Strike: 100, Exp: "2024-03-01", Qty: 1, Action: "BUY", Price: far},
+	}
+
+	p, err := AnalyzePayoff(legs, m)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2024-01-31", p.EvalDate)
+	assert.Greater(t, p.NetDebit, 0.0)
+
+	// Best at the strike, where the far call keeps the most time value
+	e, err := NewEvaluator(legs, m, p.EvalDate)
+	require.NoError(t, err)
+	assert.InDelta(t, e.PnL(100), p.MaxProfit, 0.01)
+	assert.Greater(t, p.MaxProfit, 0.0)
+
+	// Far away from the strike both legs converge and the debit is lost
+	assert.InDelta(t, p.NetDebit, p.MaxLoss, 1)
+	assert.False(t, p.UnlimitedProfit)
+	assert.False(t, p.UnlimitedLoss)
+
+	require.Len(t, p.Breakevens, 2)
+	assert.Less(t, p.Breakevens[0], 100.0)
+	assert.Greater(t, p.Breakevens[1], 100.0)
+	assert.InDelta(t, 0, e.PnL(p.Breakevens[0]), 0.01)
+	assert.InDelta(t, 0, e.PnL(p.Breakevens[1]), 0.01)
+}
+
+func TestAnalyzePayoff_Errors(t *testing.T) {
+	_, err := AnalyzePayoff(nil, Market{})
+	assert.ErrorIs(t, err, ErrNoLegs)
+
+	// A far leg without a price or volatility cannot be valued
+	legs := []Leg{
+		{Type: "PUT", Strike: 100, Exp: "2024-01-31", Qty: 1, Action: "SELL", Price: 2},
+		{Type: "PUT", Strike: 100, Exp: "2024-03-01", Qty: 1, Action: "BUY"},
+	}
+	_, err = AnalyzePayoff(legs, Market{AsOf: "2024-01-01"})
+	assert.ErrorIs(t, err, ErrNoVol)
+
+	// Nor can one whose IV would be solved after it expired
+	legs[1].Price = 3
+	_, err = AnalyzePayoff(legs, Market{AsOf: "2024-06-01"})
+	assert.ErrorIs(t, err, ErrNoVol)
+}
@@ -0,0 +1,151 @@
+package options
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultRiskGraphPoints is how many prices a risk graph curve is sampled at
+const DefaultRiskGraphPoints = 101
+
+// MaxRiskGraphPoints caps the samples per curve a request may ask for
+const MaxRiskGraphPoints = 1000
+
+// RiskGraphRequest describes the legs to chart and the price window
+type RiskGraphRequest struct {
+	Legs       []Leg   `json:"legs"`
+	Market     Market  `json:"market"`                // Spot is the current underlying
+	Stop       float64 `json:"stop,omitempty"`        // Stop level on the underlying, marked on the chart
+	FutureDate string  `json:"future_date,omitempty"` // Extra curve date (default: halfway to the nearest expiration)
+	Low        float64 `json:"low,omitempty"`         // Price window (default: ±30% around strikes and spot)
+	High       float64 `json:"high,omitempty"`
+	Points     int     `json:"points,omitempty"` // Samples per curve, 2 to 1000 (default 101)
+}
+
+// CurvePoint is the P&L with the underlying at Price
+type CurvePoint struct {
+	Price float64 `json:"price"`
+	PnL   float64 `json:"pnl"`
+}
+
+// RiskCurve is the P&L across the price window on one date
+type RiskCurve struct {
+	Label  string       `json:"label"` // expiry, today or future
+	Date   string       `json:"date"`
+	Points []CurvePoint `json:"points"`
+}
+
+// RiskGraph is the payoff diagram of a set of legs
+type RiskGraph struct {
+	Spot    float64     `json:"spot"`
+	Stop    float64     `json:"stop,omitempty"`
+	Low     float64     `json:"low"`
+	High    float64     `json:"high"`
+	Payoff  *Payoff     `json:"payoff"` // Max profit/loss and breakevens at expiry
+	Curves  []RiskCurve `json:"curves"` // Expiry, today and the future date
+	MinPnL  float64     `json:"min_pnl"`
+	MaxPnL  float64     `json:"max_pnl"`
+	Warning string      `json:"warning,omitempty"`
+}
+
+// BuildRiskGraph samples the P&L of the legs at the nearest expiration,
+// today (Market.AsOf) and a future date across a price window
+//
+// Options still open on a curve's date are valued with Black-Scholes at
+// the volatility solved from their price (or Market.Vol); at expiry every
+// leg expiring that day is worth its intrinsic value.
+func BuildRiskGraph(req RiskGraphRequest) (*RiskGraph, error) {
+	m := req.Market
+	if m.AsOf == "" {
+		m.AsOf = time.Now().Format("2006-01-02")
+	}
+	points := req.Points
+	if points == 0 {
+		points = DefaultRiskGraphPoints
+	}
+	if points < 2 || points > MaxRiskGraphPoints {
+		return nil, fmt.Errorf("points must be between 2 and %d, got %d", MaxRiskGraphPoints, req.Points)
+	}
+	expiry := NearestExpiration(req.Legs)
+	if expiry == "" {
+		return nil, fmt.Errorf("legs need an option expiration to chart")
+	}
+
+	payoff, err := AnalyzePayoff(req.Legs, m)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &RiskGraph{
+		Spot:   m.Spot,
+		Stop:   req.Stop,
+		Payoff: payoff,
+		MinPnL: math.Inf(1),
+		MaxPnL: math.Inf(-1),
+	}
+	graph.Low, graph.High = req.Low, req.High
+	if graph.Low <= 0 || graph.High <= graph.Low {
+		graph.Low, graph.High = priceWindow(req.Legs, m.Spot, req.Stop)
+	}
+
+	curves := []RiskCurve{{Label: "expiry", Date: expiry}}
+	if m.AsOf < expiry {
+		future := req.FutureDate
+		if future == "" {
+			future = midpointDate(m.AsOf, expiry)
+		}
+		curves = append(curves, RiskCurve{Label: "today", Date: m.AsOf})
+		if future > m.AsOf && future < expiry {
+			curves = append(curves, RiskCurve{Label: "future", Date: future})
+		}
+	} else {
+		graph.Warning = fmt.Sprintf("nearest expiration %s is not after %s: only the expiry curve is shown", expiry, m.AsOf)
+	}
+
+	for _, curve := range curves {
+		e, err := NewEvaluator(req.Legs, m, curve.Date)
+		if err != nil {
+			return nil, err
+		}
+		curve.Points = make([]CurvePoint, points)
+		for i := range curve.Points {
+			price := graph.Low + (graph.High-graph.Low)*float64(i)/float64(points-1)
+			pnl := roundCents(e.PnL(price))
+			curve.Points[i] = CurvePoint{Price: price, PnL: pnl}
+			graph.MinPnL = math.Min(graph.MinPnL, pnl)
+			graph.MaxPnL = math.Max(graph.MaxPnL, pnl)
+		}
+		graph.Curves = append(graph.Curves, curve)
+	}
+	return graph, nil
+}
+
+// priceWindow spans the strikes, spot and stop with 30% to spare
+func priceWindow(legs []Leg, spot, stop float64) (low, high float64) {
+	low, high = math.Inf(1), math.Inf(-1)
+	levels := []float64{spot, stop}
+	for _, leg := range legs {
+		if leg.Type == Stock {
+			levels = append(levels, leg.Price)
+		} else {
+			levels = append(levels, leg.Strike)
+		}
+	}
+	for _, level := range levels {
+		if level > 0 {
+			low, high = math.Min(low, level), math.Max(high, level)
+		}
+	}
+	return low * 0.7, high * 1.3
+}
+
+// midpointDate is the day halfway between two YYYY-MM-DD dates
+func midpointDate(from, to string) string {
+	start, err1 := time.Parse("2006-01-02", from)
+	end, err2 := time.Parse("2006-01-02", to)
+	if err1 != nil || err2 != nil {
+		return ""
+	}
+	return start.Add(end.Sub(start) / 2).Format("2006-01-02")
+}
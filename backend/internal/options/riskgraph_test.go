@@ -0,0 +1,90 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ironCondorLegs() []Leg {
+	return []Leg{
+		{Type: "PUT", Strike: 160, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 0.50},
+		{Type: "PUT", Strike: 165, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 1.20},
+		{Type: "CALL", Strike: 185, Exp: "2025-12-19", Qty: 1, Action: "SELL", Price: 1.30},
+		{Type: "CALL", Strike: 190, Exp: "2025-12-19", Qty: 1, Action: "BUY", Price: 0.60},
+	}
+}
+
+func TestBuildRiskGraph_IronCondor(t *testing.T) {
+	graph, err := BuildRiskGraph(RiskGraphRequest{
+		Legs:   ironCondorLegs(),
+		Market: Market{Spot: 175, Rate: 0.04, AsOf: "2025-11-01"},
+		Stop:   158,
+		Low:    100,
+		High:   250,
+		Points: 151, // $1 steps
+	})
+	require.NoError(t, err)
+
+	require.Len(t, graph.Curves, 3)
+	assert.Equal(t, "expiry", graph.Curves[0].Label)
+	assert.Equal(t, "2025-12-19", graph.Curves[0].Date)
+	assert.Equal(t, "today", graph.Curves[1].Label)
+	assert.Equal(t, "future", graph.Curves[2].Label)
+	assert.Equal(t, "2025-11-25", graph.Curves[2].Date) // Halfway to expiry
+	assert.Equal(t, 158.0, graph.Stop)
+	assert.Equal(t, []float64{163.6, 186.4}, graph.Payoff.Breakevens)
+
+	// At expiry: full credit between the short strikes, full loss past the wings
+	expiry := graph.Curves[0].Points
+	require.Len(t, expiry, 151)
+	assert.Equal(t, CurvePoint{Price: 175, PnL: 140}, expiry[75])
+	assert.Equal(t, -360.0, expiry[0].PnL)
+	assert.Equal(t, 140.0, graph.MaxPnL)
+	assert.Equal(t, -360.0, graph.MinPnL)
+
+	// Today the legs are worth what was paid for them at the spot they were priced at
+	assert.InDelta(t, 0, graph.Curves[1].Points[75].PnL, 0.01)
+
+	// Time decay: the future curve sits between today and expiry at the spot
+	future := graph.Curves[2].Points[75].PnL
+	assert.Greater(t, future, 0.0)
+	assert.Less(t, future, 140.0)
+}
+
+func TestBuildRiskGraph_DefaultWindow(t *testing.T) {
+	graph, err := BuildRiskGraph(RiskGraphRequest{
+		Legs:   ironCondorLegs(),
+		Market: Market{Spot: 175, AsOf: "2025-11-01"},
+	})
+	require.NoError(t, err)
+
+	assert.InDelta(t, 160*0.7, graph.Low, 1e-9)
+	assert.InDelta(t, 190*1.3, graph.High, 1e-9)
+	assert.Len(t, graph.Curves[0].Points, DefaultRiskGraphPoints)
+
+	for _, points := range []int{1, -5, MaxRiskGraphPoints + 1} {
+		_, err = BuildRiskGraph(RiskGraphRequest{
+			Legs:   ironCondorLegs(),
+			Market: Market{Spot: 175, AsOf: "2025-11-01"},
+			Points: points,
+		})
+		assert.ErrorContains(t, err, "points must be between 2 and 1000", points)
+	}
+}
+
+func TestBuildRiskGraph_ExpiredShowsOnlyExpiry(t *testing.T) {
+	graph, err := BuildRiskGraph(RiskGraphRequest{
+		Legs:   ironCondorLegs(),
+		Market: Market{Spot: 175, AsOf: "2026-01-02"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, graph.Curves, 1)
+	assert.Equal(t, "expiry", graph.Curves[0].Label)
+	assert.NotEmpty(t, graph.Warning)
+
+	_, err = BuildRiskGraph(RiskGraphRequest{Legs: []Leg{{Type: Stock, Qty: 100, Action: "BUY", Price: 50}}})
+	assert.Error(t, err)
+}
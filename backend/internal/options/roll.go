@@ -0,0 +1,43 @@
+package options
+
+import (
+	"fmt"
+	"time"
+)
+
+// NextMonthlyExpiration returns the standard monthly expiration (the third
+// Friday) of the month after exp
+func NextMonthlyExpiration(exp string) (string, error) {
+	date, err := time.Parse("2006-01-02", exp)
+	if err != nil {
+		return "", fmt.Errorf("invalid expiration %q (use YYYY-MM-DD)", exp)
+	}
+	return thirdFriday(date.Year(), date.Month()+1).Format("2006-01-02"), nil
+}
+
+// thirdFriday is the third Friday of a month (a month of 13 rolls into the next year)
+func thirdFriday(year int, month time.Month) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(time.Friday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+14)
+}
+
+// RollLegs splits legs for a roll to toExp: the option legs expiring at the
+// nearest expiration are closed and reopened at toExp (same type, strike,
+// quantity and side, priced at zero); later legs and stock are kept
+func RollLegs(legs []Leg, toExp string) (closing, opening, kept []Leg) {
+	nearest := NearestExpiration(legs)
+	for _, leg := range legs {
+		if leg.Type == Stock || leg.Exp != nearest {
+			kept = append(kept, leg)
+			continue
+		}
+		closing = append(closing, leg)
+
+		replacement := leg
+		replacement.Exp = toExp
+		replacement.Price = 0
+		opening = append(opening, replacement)
+	}
+	return closing, opening, kept
+}
@@ -0,0 +1,42 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextMonthlyExpiration(t *testing.T) {
+	tests := []struct {
+		exp, want string
+	}{
+		{"2025-01-17", "2025-02-21"},
+		{"2025-02-07", "2025-03-21"}, // A weekly rolls to the next month's monthly
+		{"2025-12-19", "2026-01-16"}, // Across the year end
+		{"2026-04-17", "2026-05-15"}, // May 1st is a Friday
+	}
+	for _, tt := range tests {
+		got, err := NextMonthlyExpiration(tt.exp)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.exp)
+	}
+
+	_, err := NextMonthlyExpiration("next month")
+	assert.Error(t, err)
+}
+
+func TestRollLegs_CalendarKeepsFarLeg(t *testing.T) {
+	legs := []Leg{
+		{Type: "CALL", Strike: 100, Exp: "2025-01-17", Qty: 2, Action: "SELL", Price: 2.10},
+		{Type: "CALL", Strike: 100, Exp: "2025-03-21", Qty: 2, Action: "BUY", Price: 4.60},
+	}
+
+	closing, opening, kept := RollLegs(legs, "2025-02-21")
+
+	require.Len(t, closing, 1)
+	assert.Equal(t, legs[0], closing[0])
+	require.Len(t, opening, 1)
+	assert.Equal(t, Leg{Type: "CALL", Strike: 100, Exp: "2025-02-21", Qty: 2, Action: "SELL"}, opening[0])
+	assert.Equal(t, legs[1:], kept)
+}
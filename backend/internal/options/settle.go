@@ -0,0 +1,70 @@
+package options
+
+import (
+	"fmt"
+	"math"
+)
+
+// Leg outcomes at expiration
+const (
+	SettleExpired   = "EXPIRED"   // Out of the money, expires worthless
+	SettleExercised = "EXERCISED" // Long leg in the money, exercised into stock
+	SettleAssigned  = "ASSIGNED"  // Short leg in the money, assigned into stock
+)
+
+// LegSettlement is what happens to one option leg at its expiration
+type LegSettlement struct {
+	Leg       Leg     `json:"leg"`
+	Outcome   string  `json:"outcome"`   // EXPIRED, EXERCISED or ASSIGNED
+	Intrinsic float64 `json:"intrinsic"` // Per share at the settlement price
+	Shares    int     `json:"shares"`    // Stock delivered: positive bought, negative sold
+}
+
+// SettleLegs settles the option legs expiring on exp at the underlying's
+// settlement price
+//
+// A leg in the money by a cent or more is exercised (BUY) or assigned
+// (SELL) and delivers Qty × 100 shares at its strike: a call buys the
+// stock for its holder, a put sells it. Everything else expires
+// worthless. Stock legs and legs expiring later are not settled.
+func SettleLegs(legs []Leg, exp string, settlement float64) ([]LegSettlement, error) {
+	if settlement <= 0 {
+		return nil, fmt.Errorf("settlement price must be positive, got %.2f", settlement)
+	}
+
+	var settled []LegSettlement
+	for _, leg := range legs {
+		if leg.Type == Stock || leg.Exp != exp {
+			continue
+		}
+		optType, err := ParseType(leg.Type)
+		if err != nil {
+			return nil, err
+		}
+		sign, err := ActionSign(leg.Action)
+		if err != nil {
+			return nil, err
+		}
+
+		// A call delivers stock to its holder, a put takes it
+		intrinsic, delivery := settlement-leg.Strike, 1.0
+		if optType == Put {
+			intrinsic, delivery = leg.Strike-settlement, -1.0
+		}
+
+		s := LegSettlement{Leg: leg, Outcome: SettleExpired}
+		if math.Round(intrinsic*100) >= 1 {
+			s.Intrinsic = intrinsic
+			s.Outcome = SettleExercised
+			if sign < 0 {
+				s.Outcome = SettleAssigned
+			}
+			s.Shares = int(sign*delivery) * leg.Qty * int(ContractMultiplier)
+		}
+		settled = append(settled, s)
+	}
+	if len(settled) == 0 {
+		return nil, fmt.Errorf("no option legs expire on %s", exp)
+	}
+	return settled, nil
+}
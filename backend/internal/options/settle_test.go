@@ -0,0 +1,59 @@
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettleLegs(t *testing.T) {
+	legs := []Leg{
+		{Type: "PUT", Strike: 90, Exp: "2025-01-17", Qty: 2, Action: "BUY", Price: 0.80},
+		{Type: "PUT", Strike: 95, Exp: "2025-01-17", Qty: 2, Action: "SELL", Price: 2.00},
+		{Type: "CALL", Strike: 92, Exp: "2025-01-17", Qty: 1, Action: "BUY", Price: 3.00},
+		{Type: "CALL", Strike: 100, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 1.00},
+		{Type: "CALL", Strike: 100, Exp: "2025-02-21", Qty: 1, Action: "BUY", Price: 2.50}, // Later, not settled
+		{Type: Stock, Qty: 100, Action: "BUY", Price: 93},
+	}
+
+	settled, err := SettleLegs(legs, "2025-01-17", 93)
+	require.NoError(t, err)
+	require.Len(t, settled, 4)
+
+	assert.Equal(t, SettleExpired, settled[0].Outcome)
+	assert.Zero(t, settled[0].Shares)
+
+	// Short put assigned: buys 200 shares at 95
+	assert.Equal(t, SettleAssigned, settled[1].Outcome)
+	assert.Equal(t, 200, settled[1].Shares)
+	assert.InDelta(t, 2.0, settled[1].Intrinsic, 1e-9)
+
+	// Long call exercised: buys 100 shares at 92
+	assert.Equal(t, SettleExercised, settled[2].Outcome)
+	assert.Equal(t, 100, settled[2].Shares)
+
+	assert.Equal(t, SettleExpired, settled[3].Outcome)
+}
+
+func TestSettleLegs_AssignedCallAndExercisedPutSell(t *testing.T) {
+	legs := []Leg{
+		{Type: "CALL", Strike: 105, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 2.00},
+		{Type: "PUT", Strike: 110, Exp: "2025-01-17", Qty: 1, Action: "BUY", Price: 4.00},
+	}
+
+	settled, err := SettleLegs(legs, "2025-01-17", 108)
+	require.NoError(t, err)
+	assert.Equal(t, -100, settled[0].Shares) // Called away
+	assert.Equal(t, -100, settled[1].Shares) // Put to the market
+
+	// At the money by less than a cent expires
+	settled, err = SettleLegs(legs[:1], "2025-01-17", 105.004)
+	require.NoError(t, err)
+	assert.Equal(t, SettleExpired, settled[0].Outcome)
+
+	_, err = SettleLegs(legs, "2025-02-21", 108)
+	assert.Error(t, err)
+	_, err = SettleLegs(legs, "2025-01-17", 0)
+	assert.Error(t, err)
+}
@@ -5,19 +5,25 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/yourusername/trading-engine/internal/domain"
 	"github.com/yourusername/trading-engine/internal/logx"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
 // DBGateChecker implements domain.GateChecker using database queries
 type DBGateChecker struct {
-	db     *storage.DB
-	log    *logrus.Entry
-	equity float64
+	db        *storage.DB
+	log       *logrus.Entry
+	equity    float64
+	system    string          // Breakout system of the trade (SYSTEM_1 runs the skip filter)
+	direction string          // Trade direction, for the per-direction unit limits
+	exposure  domain.Exposure // New trade's Greeks exposure, for the HeatGreeks caps
 }
 
 // CheckBannerGreen verifies the banner is GREEN for the ticker
@@ -56,38 +62,12 @@ func (c *DBGateChecker) CheckBucketCooldown(bucket string) error {
 	return c.db.CheckBucketCooldown(bucket)
 }
 
-// CheckHeatCaps verifies portfolio and bucket heat caps
-func (c *DBGateChecker) CheckHeatCaps(addRisk float64, bucket string) error {
-	c.log.WithField("gate", "heat_caps").WithField("add_risk", addRisk).WithField("bucket", bucket).Info("Checking heat caps gate")
+// CheckHeatCaps verifies portfolio, bucket and correlated-cluster heat caps
+// (and the Greeks caps with HeatGreeks set), counting the open positions
+func (c *DBGateChecker) CheckHeatCaps(ticker string, addRisk float64, bucket string) error {
+	c.log.WithField("gate", "heat_caps").WithField("ticker", ticker).WithField("add_risk", addRisk).WithField("bucket", bucket).Info("Checking heat caps gate")
 
-	// Get settings for heat caps
-	heatCapPctStr, err := c.db.GetSetting("HeatCap_H_pct")
-	if err != nil {
-		return fmt.Errorf("failed to get heat cap setting: %w", err)
-	}
-
-	bucketHeatCapPctStr, err := c.db.GetSetting("BucketHeatCap_pct")
-	if err != nil {
-		return fmt.Errorf("failed to get bucket heat cap setting: %w", err)
-	}
-
-	// Parse settings
-	var heatCapPct, bucketHeatCapPct float64
-	fmt.Sscanf(heatCapPctStr, "%f", &heatCapPct)
-	fmt.Sscanf(bucketHeatCapPctStr, "%f", &bucketHeatCapPct)
-
-	// Calculate heat request
-	req := domain.HeatRequest{
-		Equity:            c.equity,
-		HeatCapPct:        heatCapPct,
-		BucketHeatCapPct:  bucketHeatCapPct,
-		AddRiskDollars:    addRisk,
-		AddBucket:         bucket,
-		OpenPositions:     []domain.Position{}, // Empty for now - would query from DB
-	}
-
-	// Calculate heat
-	result, err := domain.CalculateHeat(req)
+	result, err := marketdata.EvaluateHeat(c.db, c.equity, ticker, bucket, addRisk, c.exposure)
 	if err != nil {
 		return fmt.Errorf("failed to calculate heat: %w", err)
 	}
@@ -106,6 +86,62 @@ func (c *DBGateChecker) CheckHeatCaps(addRisk float64, bucket string) error {
 			result.NewBucketHeat, result.BucketCap, overage)
 	}
 
+	// Check correlated-cluster and Greeks caps
+	if cluster := result.Cluster; cluster != nil && cluster.Exceeded {
+		return fmt.Errorf("correlated cluster [%s] heat ($%.2f) exceeds cap ($%.2f) by $%.2f",
+			strings.Join(cluster.Tickers, ", "), cluster.NewHeat, cluster.Cap, cluster.Overage)
+	}
+	if result.Greeks != nil {
+		for _, check := range result.Greeks.Checks {
+			if check.Exceeded {
+				return fmt.Errorf("%s net %s ($%.2f) exceeds cap ($%.2f)",
+					strings.ToLower(check.Scope), strings.ToLower(check.Measure), check.New, check.Cap)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckLastBreakoutFilter applies the System 1 "last breakout was a winner" skip rule
+func (c *DBGateChecker) CheckLastBreakoutFilter(ticker, date string) error {
+	if c.system != storage.SystemOne {
+		return nil
+	}
+	c.log.WithField("gate", "last_breakout_filter").WithField("ticker", ticker).Info("Checking System 1 skip filter")
+
+	k := domain.DefaultFilterStopMultiple
+	if kStr, err := c.db.GetSetting("StopMultiple_K"); err == nil {
+		fmt.Sscanf(kStr, "%f", &k)
+	}
+
+	result, err := marketdata.EvaluateLastBreakout(c.db, ticker, date, k)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate the System 1 skip filter: %w", err)
+	}
+
+	c.log.WithField("skip", result.Skip).WithField("reason", result.Reason).Info("System 1 skip filter evaluated")
+
+	if result.Skip {
+		return fmt.Errorf("%s", result.Reason)
+	}
+	return nil
+}
+
+// CheckUnitLimits applies the Turtle unit limits to one new unit
+func (c *DBGateChecker) CheckUnitLimits(ticker, bucket string) error {
+	c.log.WithField("gate", "unit_limits").WithField("ticker", ticker).WithField("bucket", bucket).Info("Checking unit limits gate")
+
+	result, err := marketdata.EvaluateUnitLimits(c.db, ticker, c.direction, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check unit limits: %w", err)
+	}
+
+	c.log.WithField("allowed", result.Allowed).WithField("checks", len(result.Checks)).Info("Unit limits evaluated")
+
+	if !result.Allowed {
+		return fmt.Errorf("%s", result.Reason)
+	}
 	return nil
 }
 
@@ -123,14 +159,20 @@ func (s *Server) sizeHandler(w http.ResponseWriter, r *http.Request) {
 	log := logx.WithCorrelationID(corrID)
 
 	var req struct {
-		Equity  float64 `json:"equity"`
-		RiskPct float64 `json:"risk_pct"`
-		Entry   float64 `json:"entry"`
-		ATR     float64 `json:"atr"`
-		K       int     `json:"k"`
-		Method  string  `json:"method"`
-		Delta   float64 `json:"delta,omitempty"`
-		MaxLoss float64 `json:"max_loss,omitempty"`
+		Ticker    string  `json:"ticker,omitempty"`
+		Direction string  `json:"direction,omitempty"` // LONG (default) or SHORT
+		Equity    float64 `json:"equity"`
+		RiskPct   float64 `json:"risk_pct"`
+		Entry     float64 `json:"entry"`
+		ATR       float64 `json:"atr"`
+		K         float64 `json:"k"`
+		Method    string  `json:"method"`
+		Delta     float64 `json:"delta,omitempty"`
+		MaxLoss   float64 `json:"max_loss,omitempty"`
+
+		// Option leg to derive delta from when delta is omitted
+		Leg     *options.Leg   `json:"leg,omitempty"`
+		Pricing options.Market `json:"pricing"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -141,13 +183,13 @@ func (s *Server) sizeHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Load settings from database if not provided
 	if req.Equity == 0 {
-		equityStr, err := s.db.GetSetting("Equity_E")
+		equity, err := marketdata.SizingEquity(s.db)
 		if err != nil {
-			log.WithError(err).Error("Failed to get Equity_E")
+			log.WithError(err).Error("Failed to get equity")
 			respondError(w, http.StatusInternalServerError, "Failed to get equity from database", corrID)
 			return
 		}
-		fmt.Sscanf(equityStr, "%f", &req.Equity)
+		req.Equity = equity
 	}
 
 	if req.RiskPct == 0 {
@@ -167,21 +209,30 @@ func (s *Server) sizeHandler(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusInternalServerError, "Failed to get K from database", corrID)
 			return
 		}
-		var kFloat float64
-		fmt.Sscanf(kStr, "%f", &kFloat)
-		req.K = int(kFloat)
+		fmt.Sscanf(kStr, "%f", &req.K)
 	}
 
 	// Build sizing request
 	sizingReq := domain.SizingRequest{
-		Equity:  req.Equity,
-		RiskPct: req.RiskPct,
-		Entry:   req.Entry,
-		ATR:     req.ATR,
-		K:       req.K,
-		Method:  req.Method,
-		Delta:   req.Delta,
-		MaxLoss: req.MaxLoss,
+		Equity:    req.Equity,
+		RiskPct:   req.RiskPct,
+		Entry:     req.Entry,
+		ATR:       req.ATR,
+		K:         req.K,
+		Method:    req.Method,
+		Delta:     req.Delta,
+		MaxLoss:   req.MaxLoss,
+		Ticker:    strings.ToUpper(req.Ticker),
+		Direction: req.Direction,
+		Leg:       req.Leg,
+		Pricing:   req.Pricing,
+	}
+
+	// Fill N from stored price bars when not supplied
+	if _, err := domain.ResolveSizingATR(marketdata.NewDBBarProvider(s.db), &sizingReq); err != nil {
+		log.WithError(err).Error("Failed to compute ATR from stored bars")
+		respondError(w, http.StatusBadRequest, err.Error(), corrID)
+		return
 	}
 
 	// Calculate position size
@@ -207,6 +258,9 @@ func (s *Server) sizeHandler(w http.ResponseWriter, r *http.Request) {
 		"contracts":      result.Contracts,
 		"actual_risk":    result.ActualRisk,
 		"method":         result.Method,
+		"direction":      result.Direction,
+		"atr_n":          result.ATR,
+		"atr_date":       result.ATRDate,
 		"correlation_id": corrID,
 	}
 
@@ -227,8 +281,9 @@ func (s *Server) checklistHandler(w http.ResponseWriter, r *http.Request) {
 	log := logx.WithCorrelationID(corrID)
 
 	var req struct {
-		Ticker string `json:"ticker"`
-		Checks struct {
+		Ticker    string `json:"ticker"`
+		Direction string `json:"direction,omitempty"` // LONG (default) or SHORT
+		Checks    struct {
 			FromPreset    bool `json:"from_preset"`
 			TrendPass     bool `json:"trend_pass"`
 			LiquidityPass bool `json:"liquidity_pass"`
@@ -236,6 +291,8 @@ func (s *Server) checklistHandler(w http.ResponseWriter, r *http.Request) {
 			EarningsOK    bool `json:"earnings_ok"`
 			JournalOK     bool `json:"journal_ok"`
 		} `json:"checks"`
+		AutoTrend bool   `json:"auto_trend,omitempty"` // Tick TrendPass from a stored-bar breakout
+		System    string `json:"system,omitempty"`     // SYSTEM_1 or SYSTEM_2 (default)
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -247,6 +304,7 @@ func (s *Server) checklistHandler(w http.ResponseWriter, r *http.Request) {
 	// Build checklist request
 	checklistReq := domain.ChecklistRequest{
 		Ticker:        req.Ticker,
+		Direction:     req.Direction,
 		FromPreset:    req.Checks.FromPreset,
 		TrendPass:     req.Checks.TrendPass,
 		LiquidityPass: req.Checks.LiquidityPass,
@@ -255,6 +313,18 @@ func (s *Server) checklistHandler(w http.ResponseWriter, r *http.Request) {
 		JournalOK:     req.Checks.JournalOK,
 	}
 
+	// Auto-tick TrendPass when stored bars show a breakout in the trade's direction
+	var breakout *domain.BreakoutSignal
+	if req.AutoTrend && !checklistReq.TrendPass {
+		signal, err := s.detectBreakout(req.Ticker, req.System)
+		if err != nil {
+			log.WithError(err).Warn("Breakout check failed, TrendPass left unchanged")
+		} else {
+			breakout = signal
+			domain.ApplyBreakoutToChecklist(&checklistReq, breakout)
+		}
+	}
+
 	// Evaluate checklist
 	result, err := domain.EvaluateChecklist(checklistReq)
 	if err != nil {
@@ -281,12 +351,29 @@ func (s *Server) checklistHandler(w http.ResponseWriter, r *http.Request) {
 		"missing_count":  result.MissingCount,
 		"missing_items":  result.MissingItems,
 		"allow_save":     result.AllowSave,
+		"breakout":       breakout,
 		"correlation_id": corrID,
 	}
 
 	respondJSON(w, http.StatusOK, response)
 }
 
+// detectBreakout checks a ticker's stored bars for a Donchian breakout
+func (s *Server) detectBreakout(ticker, system string) (*domain.BreakoutSignal, error) {
+	params, err := domain.GetBreakoutParams(system)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker = strings.ToUpper(ticker)
+	bars, err := marketdata.NewDBBarProvider(s.db).GetDailyBars(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.DetectBreakout(ticker, bars, params)
+}
+
 // decisionHandler handles saving trading decisions
 func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -301,15 +388,17 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 	log := logx.WithCorrelationID(corrID)
 
 	var req struct {
-		Ticker  string  `json:"ticker"`
-		Action  string  `json:"action"`
-		Entry   float64 `json:"entry"`
-		ATR     float64 `json:"atr"`
-		Method  string  `json:"method"`
-		Delta   float64 `json:"delta,omitempty"`
-		MaxLoss float64 `json:"max_loss,omitempty"`
-		Bucket  string  `json:"bucket"`
-		Reason  string  `json:"reason,omitempty"`
+		Ticker    string  `json:"ticker"`
+		Action    string  `json:"action"`
+		Direction string  `json:"direction,omitempty"` // LONG (default) or SHORT
+		Entry     float64 `json:"entry"`
+		ATR       float64 `json:"atr"`
+		Method    string  `json:"method"`
+		Delta     float64 `json:"delta,omitempty"`
+		MaxLoss   float64 `json:"max_loss,omitempty"`
+		Bucket    string  `json:"bucket"`
+		Reason    string  `json:"reason,omitempty"`
+		System    string  `json:"system,omitempty"` // SYSTEM_1 applies the last-breakout skip filter
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -318,19 +407,32 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Fill N from stored price bars when a GO decision omits it
+	var atrDate string
+	if req.Action == "GO" {
+		atrReq := domain.SizingRequest{Ticker: strings.ToUpper(req.Ticker), ATR: req.ATR, Method: req.Method}
+		if _, err := domain.ResolveSizingATR(marketdata.NewDBBarProvider(s.db), &atrReq); err != nil {
+			log.WithError(err).Error("Failed to compute ATR from stored bars")
+			respondError(w, http.StatusBadRequest, err.Error(), corrID)
+			return
+		}
+		req.ATR, atrDate = atrReq.ATR, atrReq.ATRDate
+	}
+
 	// Validate request
 	saveReq := domain.SaveDecisionRequest{
-		Ticker:  req.Ticker,
-		Action:  req.Action,
-		Entry:   req.Entry,
-		ATR:     req.ATR,
-		Method:  req.Method,
-		Delta:   req.Delta,
-		MaxLoss: req.MaxLoss,
-		Bucket:  req.Bucket,
-		Reason:  req.Reason,
-		Date:    time.Now().Format("2006-01-02"),
-		CorrID:  corrID,
+		Ticker:    req.Ticker,
+		Action:    req.Action,
+		Direction: req.Direction,
+		Entry:     req.Entry,
+		ATR:       req.ATR,
+		Method:    req.Method,
+		Delta:     req.Delta,
+		MaxLoss:   req.MaxLoss,
+		Bucket:    req.Bucket,
+		Reason:    req.Reason,
+		Date:      time.Now().Format("2006-01-02"),
+		CorrID:    corrID,
 	}
 
 	if err := domain.ValidateSaveDecisionRequest(saveReq); err != nil {
@@ -338,30 +440,30 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err.Error(), corrID)
 		return
 	}
+	direction, _ := domain.NormalizeDirection(req.Direction)
 
 	// For GO decisions, validate gates and calculate sizing
 	if req.Action == "GO" {
 		// Get settings
-		equityStr, _ := s.db.GetSetting("Equity_E")
+		equity, _ := marketdata.SizingEquity(s.db)
 		riskStr, _ := s.db.GetSetting("RiskPct_r")
 		kStr, _ := s.db.GetSetting("StopMultiple_K")
 
-		var equity, riskPct, kFloat float64
-		fmt.Sscanf(equityStr, "%f", &equity)
+		var riskPct, k float64
 		fmt.Sscanf(riskStr, "%f", &riskPct)
-		fmt.Sscanf(kStr, "%f", &kFloat)
-		k := int(kFloat)
+		fmt.Sscanf(kStr, "%f", &k)
 
 		// Calculate position size
 		sizingReq := domain.SizingRequest{
-			Equity:  equity,
-			RiskPct: riskPct,
-			Entry:   req.Entry,
-			ATR:     req.ATR,
-			K:       k,
-			Method:  req.Method,
-			Delta:   req.Delta,
-			MaxLoss: req.MaxLoss,
+			Direction: direction,
+			Equity:    equity,
+			RiskPct:   riskPct,
+			Entry:     req.Entry,
+			ATR:       req.ATR,
+			K:         k,
+			Method:    req.Method,
+			Delta:     req.Delta,
+			MaxLoss:   req.MaxLoss,
 		}
 
 		if sizingReq.Method == "" {
@@ -377,9 +479,15 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 
 		// Create gate checker
 		checker := &DBGateChecker{
-			db:     s.db,
-			log:    log,
-			equity: equity,
+			db:        s.db,
+			log:       log,
+			equity:    equity,
+			system:    strings.ToUpper(req.System),
+			direction: direction,
+		}
+		if sizingReq.Method == "stock" {
+			// An option trade's Greeks are only known once its legs are, in its options session
+			checker.exposure = domain.StockExposure(direction, sizing.Shares, req.Entry)
 		}
 
 		// Validate hard gates
@@ -412,8 +520,10 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 			Date:         time.Now().Format("2006-01-02"),
 			Ticker:       req.Ticker,
 			Action:       "GO",
+			Direction:    direction,
 			Entry:        req.Entry,
 			ATR:          req.ATR,
+			ATRDate:      atrDate,
 			Method:       sizingReq.Method,
 			Delta:        req.Delta,
 			MaxLoss:      req.MaxLoss,
@@ -447,6 +557,7 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 			"contracts":      sizing.Contracts,
 			"risk_dollars":   sizing.RiskDollars,
 			"initial_stop":   sizing.InitialStop,
+			"direction":      direction,
 			"correlation_id": corrID,
 		}
 
@@ -456,12 +567,13 @@ func (s *Server) decisionHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Save NO-GO decision
 	decision := storage.Decision{
-		Date:   time.Now().Format("2006-01-02"),
-		Ticker: req.Ticker,
-		Action: "NO-GO",
-		Reason: req.Reason,
-		Bucket: req.Bucket,
-		CorrID: corrID,
+		Date:      time.Now().Format("2006-01-02"),
+		Ticker:    req.Ticker,
+		Action:    "NO-GO",
+		Direction: direction,
+		Reason:    req.Reason,
+		Bucket:    req.Bucket,
+		CorrID:    corrID,
 	}
 
 	decisionID, err := s.db.SaveDecision(decision)
@@ -539,9 +651,9 @@ func (s *Server) heatHandler(w http.ResponseWriter, r *http.Request) {
 	log := logx.WithCorrelationID(corrID)
 
 	// Get settings
-	equityStr, err := s.db.GetSetting("Equity_E")
+	equity, err := marketdata.SizingEquity(s.db)
 	if err != nil {
-		log.WithError(err).Error("Failed to get Equity_E")
+		log.WithError(err).Error("Failed to get equity")
 		respondError(w, http.StatusInternalServerError, "Failed to get equity", corrID)
 		return
 	}
@@ -560,8 +672,7 @@ func (s *Server) heatHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var equity, heatCap, bucketHeatCap float64
-	fmt.Sscanf(equityStr, "%f", &equity)
+	var heatCap, bucketHeatCap float64
 	fmt.Sscanf(heatCapStr, "%f", &heatCap)
 	fmt.Sscanf(bucketHeatCapStr, "%f", &bucketHeatCap)
 
@@ -19,6 +19,17 @@ type BucketCooldown struct {
 // CooldownDuration is the mandatory 24-hour wait period after a loss
 const CooldownDuration = 24 * time.Hour
 
+// CooldownExpiresAt returns when a cooldown triggered by a loss at lossTime ends
+// Shared with the backtester so simulated and live cooldowns follow one rule
+func CooldownExpiresAt(lossTime time.Time) time.Time {
+	return lossTime.Add(CooldownDuration)
+}
+
+// CooldownActiveAt reports whether a cooldown ending at expiresAt still blocks entries at t
+func CooldownActiveAt(expiresAt, t time.Time) bool {
+	return t.Before(expiresAt)
+}
+
 // TriggerBucketCooldown creates or extends cooldown for a bucket
 // Called when a loss is recorded in a bucket
 func (db *DB) TriggerBucketCooldown(bucket, reason string) error {
@@ -27,7 +38,7 @@ func (db *DB) TriggerBucketCooldown(bucket, reason string) error {
 	}
 
 	now := time.Now()
-	expiresAt := now.Add(CooldownDuration)
+	expiresAt := CooldownExpiresAt(now)
 
 	// Check if cooldown already exists
 	existing, err := db.GetBucketCooldown(bucket)
@@ -95,7 +106,7 @@ func (db *DB) GetBucketCooldown(bucket string) (*BucketCooldown, error) {
 	cooldown.ExpiresAt = time.Unix(expiresUnix, 0)
 
 	// Check if expired
-	if time.Now().After(cooldown.ExpiresAt) {
+	if !CooldownActiveAt(cooldown.ExpiresAt, time.Now()) {
 		// Deactivate expired cooldown
 		_, _ = db.conn.Exec(`UPDATE bucket_cooldowns SET active = 0 WHERE id = ?`, cooldown.ID)
 		return nil, nil // Expired, return nil
@@ -137,7 +148,7 @@ func (db *DB) GetAllActiveCooldowns() ([]BucketCooldown, error) {
 		c.ExpiresAt = time.Unix(expiresUnix, 0)
 
 		// Only include if not expired
-		if now.Before(c.ExpiresAt) {
+		if CooldownActiveAt(c.ExpiresAt, now) {
 			cooldowns = append(cooldowns, c)
 		} else {
 			// Mark for deactivation
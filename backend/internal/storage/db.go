@@ -62,6 +62,15 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
+// Snapshot writes a consistent copy of the database to path, e.g. to try
+// changes on without touching the original
+func (db *DB) Snapshot(path string) error {
+	if _, err := db.conn.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
 // Initialize creates all tables and bootstraps default settings
 func (db *DB) Initialize() error {
 	// Create tables
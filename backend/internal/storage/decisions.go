@@ -11,7 +11,8 @@ type Decision struct {
 	ID           int       `json:"id"`
 	Date         string    `json:"date"`
 	Ticker       string    `json:"ticker"`
-	Action       string    `json:"action"` // GO or NO-GO
+	Action       string    `json:"action"`    // GO or NO-GO
+	Direction    string    `json:"direction"` // LONG or SHORT (empty saves as LONG)
 	Entry        float64   `json:"entry,omitempty"`
 	ATR          float64   `json:"atr,omitempty"`
 	StopDistance float64   `json:"stop_distance,omitempty"`
@@ -26,23 +27,30 @@ type Decision struct {
 	Bucket       string    `json:"bucket,omitempty"`
 	Reason       string    `json:"reason,omitempty"`
 	CorrID       string    `json:"corr_id,omitempty"`
+	ATRDate      string    `json:"atr_date,omitempty"` // Bar date of stored N used for sizing
 	CreatedAt    time.Time `json:"created_at"`
 }
 
 // SaveDecision stores a trading decision
 func (db *DB) SaveDecision(d Decision) (int, error) {
+	direction, err := ParseDirection(d.Direction)
+	if err != nil {
+		return 0, err
+	}
+
 	query := `
 		INSERT INTO decisions (
-			date, ticker, action, entry, atr, stop_distance,
+			date, ticker, action, direction, entry, atr, stop_distance,
 			initial_stop, shares, contracts, risk_dollars, banner,
-			method, delta, max_loss, bucket, reason, corr_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			method, delta, max_loss, bucket, reason, corr_id, atr_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.conn.Exec(query,
 		d.Date,
 		d.Ticker,
 		d.Action,
+		direction,
 		d.Entry,
 		d.ATR,
 		d.StopDistance,
@@ -57,6 +65,7 @@ func (db *DB) SaveDecision(d Decision) (int, error) {
 		d.Bucket,
 		d.Reason,
 		d.CorrID,
+		nullString(d.ATRDate),
 	)
 
 	if err != nil {
@@ -76,9 +85,10 @@ func (db *DB) GetDecisionForToday(ticker string) (*Decision, error) {
 // GetDecisionForDate retrieves a decision for a specific ticker and date
 func (db *DB) GetDecisionForDate(ticker, date string) (*Decision, error) {
 	query := `
-		SELECT id, date, ticker, action, entry, atr, stop_distance,
+		SELECT id, date, ticker, action, direction, entry, atr, stop_distance,
 		       initial_stop, shares, contracts, risk_dollars, banner,
-		       method, delta, max_loss, bucket, reason, corr_id, created_at
+		       method, delta, max_loss, bucket, reason, corr_id,
+		       COALESCE(atr_date, ''), created_at
 		FROM decisions
 		WHERE ticker = ? AND date = ?
 		LIMIT 1
@@ -91,6 +101,7 @@ func (db *DB) GetDecisionForDate(ticker, date string) (*Decision, error) {
 		&d.Date,
 		&d.Ticker,
 		&d.Action,
+		&d.Direction,
 		&d.Entry,
 		&d.ATR,
 		&d.StopDistance,
@@ -105,6 +116,7 @@ func (db *DB) GetDecisionForDate(ticker, date string) (*Decision, error) {
 		&d.Bucket,
 		&d.Reason,
 		&d.CorrID,
+		&d.ATRDate,
 		&d.CreatedAt,
 	)
 
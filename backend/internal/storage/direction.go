@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trade directions stored on decisions, sessions and positions
+const (
+	DirectionLong  = "LONG"
+	DirectionShort = "SHORT"
+)
+
+// ParseDirection parses a direction name; empty means LONG
+func ParseDirection(s string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", DirectionLong, "BUY":
+		return DirectionLong, nil
+	case DirectionShort, "SELL":
+		return DirectionShort, nil
+	default:
+		return "", fmt.Errorf("invalid direction %q (use LONG or SHORT)", s)
+	}
+}
+
+// DirectionForStrategy returns the direction a session strategy trades
+// CUSTOM sessions default to LONG.
+func DirectionForStrategy(strategy string) string {
+	if strategy == StrategyShortBreakout {
+		return DirectionShort
+	}
+	return DirectionLong
+}
+
+// directionSign is +1 for longs and -1 for shorts, so that
+// sign × (exit - entry) is P&L and sign × (entry - stop) is risk per share
+func directionSign(direction string) float64 {
+	if direction == DirectionShort {
+		return -1
+	}
+	return 1
+}
+
+// stopLoosens reports whether moving the stop from current to next would
+// add risk: down for a long, up for a short
+func stopLoosens(direction string, current, next float64) bool {
+	return directionSign(direction)*(next-current) < 0
+}
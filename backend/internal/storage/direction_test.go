@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// openShortTestPosition sells 25 AAPL short at $180 with a $183 stop (N = 1.5)
+func openShortTestPosition(t *testing.T, db *DB) *Position {
+	t.Helper()
+
+	_, err := db.SaveDecision(Decision{
+		Date:         time.Now().Format("2006-01-02"),
+		Ticker:       "AAPL",
+		Action:       "GO",
+		Direction:    DirectionShort,
+		Entry:        180.0,
+		ATR:          1.5,
+		StopDistance: 3.0,
+		InitialStop:  183.0,
+		Shares:       25,
+		RiskDollars:  75.0,
+		Banner:       "GREEN",
+		Method:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+
+	position, err := db.OpenPosition("AAPL")
+	if err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+	return position
+}
+
+func TestDecisionDirection(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	openShortTestPosition(t, db)
+
+	decision, err := db.GetDecisionForToday("AAPL")
+	if err != nil {
+		t.Fatalf("GetDecisionForToday failed: %v", err)
+	}
+	if decision.Direction != DirectionShort {
+		t.Errorf("Expected SHORT decision, got %q", decision.Direction)
+	}
+
+	stored, err := db.GetPositionByTicker("AAPL")
+	if err != nil {
+		t.Fatalf("GetPositionByTicker failed: %v", err)
+	}
+	if stored.Direction != DirectionShort {
+		t.Errorf("Expected SHORT position, got %q", stored.Direction)
+	}
+
+	_, err = db.SaveDecision(Decision{Date: "2024-01-02", Ticker: "MSFT", Action: "NO-GO", Direction: "SIDEWAYS", Banner: "RED"})
+	if err == nil {
+		t.Error("Expected error for an invalid direction")
+	}
+}
+
+func TestLongDecisionDefaultsToLong(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTestPosition(t, db)
+	if position.Direction != DirectionLong {
+		t.Errorf("Expected LONG position, got %q", position.Direction)
+	}
+}
+
+func TestShortUpdateStop(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openShortTestPosition(t, db)
+
+	if err := db.UpdateStop("AAPL", 184); err == nil {
+		t.Error("Expected error moving a short stop up")
+	}
+
+	if err := db.UpdateStop("AAPL", 181); err != nil {
+		t.Fatalf("UpdateStop failed: %v", err)
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	// 25 × (181 - 180)
+	if stored.CurrentStop != 181 || stored.RiskDollars != 25 {
+		t.Errorf("Expected stop 181 and risk 25, got %.2f and %.2f", stored.CurrentStop, stored.RiskDollars)
+	}
+}
+
+func TestShortAddUnitAndExits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openShortTestPosition(t, db)
+
+	if _, err := db.AddUnit(position.ID, UnitFill{Price: 178, Shares: 25, NewStop: 185}); err == nil {
+		t.Error("Expected error when the add-on would raise a short stop")
+	}
+
+	// Unit 2 sold at 178; both units stopped at 181
+	updated, err := db.AddUnit(position.ID, UnitFill{Price: 178, Shares: 25, NewStop: 181})
+	if err != nil {
+		t.Fatalf("AddUnit failed: %v", err)
+	}
+	// 25 × (181 - 180) + 25 × (181 - 178)
+	if updated.RiskDollars != 100 {
+		t.Errorf("Expected risk 100, got %.2f", updated.RiskDollars)
+	}
+
+	// Cover unit 1 at 175: 25 × (180 - 175)
+	result, err := db.PartialClose("AAPL", 25, 175, CostMethodFIFO)
+	if err != nil {
+		t.Fatalf("PartialClose failed: %v", err)
+	}
+	if result.RealizedPnL != 125 || result.RemainingRisk != 75 {
+		t.Errorf("Expected P&L 125 and remaining risk 75, got %.2f and %.2f", result.RealizedPnL, result.RemainingRisk)
+	}
+	// Initial risk of the shares covered: 25 × (183 - 180)
+	if result.InitialRisk != 75 {
+		t.Errorf("Expected initial risk 75, got %.2f", result.InitialRisk)
+	}
+
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	if heat != 75 {
+		t.Errorf("Expected heat 75, got %.2f", heat)
+	}
+
+	// Cover the rest at 179: 25 × (178 - 179) = -25, so the trade is +100
+	if err := db.ClosePosition("AAPL", 179, ""); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if math.Abs(stored.PnL-100) > 1e-9 || stored.Outcome != OutcomeWin {
+		t.Errorf("Expected P&L 100 and WIN, got %.2f and %s", stored.PnL, stored.Outcome)
+	}
+
+	trades, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trade_history rows, got %d", len(trades))
+	}
+	for _, trade := range trades {
+		if trade.Strategy != StrategyShortBreakout {
+			t.Errorf("Expected SHORT_BREAKOUT exit row, got %s", trade.Strategy)
+		}
+	}
+}
+
+func TestSessionDirectionFromStrategy(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	short, err := db.CreateSession("AAPL", StrategyShortBreakout)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if short.Direction != DirectionShort {
+		t.Errorf("Expected SHORT session, got %q", short.Direction)
+	}
+
+	long, err := db.CreateSession("MSFT", StrategyLongBreakout)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if long.Direction != DirectionLong {
+		t.Errorf("Expected LONG session, got %q", long.Direction)
+	}
+}
+
+func TestAllocateExitShort(t *testing.T) {
+	lots := []PositionLot{{ID: 1, UnitNum: 1, RemainingShares: 10, EntryPrice: 100}}
+
+	exits, err := AllocateExit(lots, 10, 95, CostMethodFIFO, DirectionShort)
+	if err != nil {
+		t.Fatalf("AllocateExit failed: %v", err)
+	}
+	if exits[0].RealizedPL != 50 { // 10 × (100 - 95)
+		t.Errorf("Expected short P&L 50, got %.2f", exits[0].RealizedPL)
+	}
+}
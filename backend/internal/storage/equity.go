@@ -0,0 +1,39 @@
+package storage
+
+import "fmt"
+
+// DailyPnL is the realized P&L booked on one exit date
+type DailyPnL struct {
+	Date string  `json:"date"` // YYYY-MM-DD
+	PnL  float64 `json:"pnl"`
+}
+
+// GetRealizedPnLByDate sums realized P&L per exit date, oldest first
+//
+// Every exit writes its own trade_history row (partial exits, final exits,
+// rolls and expirations), so summing the CLOSED and ROLLED rows counts each
+// realized dollar once.
+func (db *DB) GetRealizedPnLByDate() ([]DailyPnL, error) {
+	query := `
+		SELECT exit_date, SUM(pnl)
+		FROM trade_history
+		WHERE status IN ('CLOSED', 'ROLLED') AND exit_date IS NOT NULL AND pnl IS NOT NULL
+		GROUP BY exit_date
+		ORDER BY exit_date
+	`
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query realized P&L: %w", err)
+	}
+	defer rows.Close()
+
+	days := []DailyPnL{}
+	for rows.Next() {
+		var d DailyPnL
+		if err := rows.Scan(&d.Date, &d.PnL); err != nil {
+			return nil, fmt.Errorf("failed to scan realized P&L: %w", err)
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
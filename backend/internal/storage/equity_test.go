@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestGetRealizedPnLByDate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	entries := []TradeHistoryEntry{
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", EntryDate: "2025-01-02", ExitDate: "2025-01-10", Status: "CLOSED", PnL: 800, Outcome: "WIN"},
+		{Ticker: "MSFT", Strategy: "LONG_BREAKOUT", EntryDate: "2025-01-03", ExitDate: "2025-01-10", Status: "CLOSED", PnL: -300, Outcome: "LOSS"},
+		{Ticker: "SPY", Strategy: "LONG_BREAKOUT", EntryDate: "2025-01-02", ExitDate: "2025-01-08", Status: StatusRolled, PnL: 120, Outcome: "WIN"},
+		{Ticker: "SPY", Strategy: "LONG_BREAKOUT", EntryDate: "2025-01-08", Status: "OPEN"},
+	}
+	for i := range entries {
+		if err := db.AddTradeToHistory(&entries[i]); err != nil {
+			t.Fatalf("AddTradeToHistory failed: %v", err)
+		}
+	}
+
+	days, err := db.GetRealizedPnLByDate()
+	if err != nil {
+		t.Fatalf("GetRealizedPnLByDate failed: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 exit dates, got %d", len(days))
+	}
+	if days[0].Date != "2025-01-08" || days[0].PnL != 120 {
+		t.Errorf("Expected the roll first (2025-01-08, 120), got %s %.2f", days[0].Date, days[0].PnL)
+	}
+	if days[1].Date != "2025-01-10" || days[1].PnL != 500 {
+		t.Errorf("Expected 2025-01-10 exits summed to 500, got %s %.2f", days[1].Date, days[1].PnL)
+	}
+}
@@ -0,0 +1,25 @@
+package storage
+
+import "fmt"
+
+// SetPositionExcursion stores the lowest and highest price a position has
+// traded at since entry, and the date of the last bar or mark applied
+func (db *DB) SetPositionExcursion(positionID int, low, high float64, through string) error {
+	if low <= 0 || high < low {
+		return fmt.Errorf("invalid excursion range %.2f-%.2f", low, high)
+	}
+
+	result, err := db.conn.Exec(`
+		UPDATE positions
+		SET excursion_low = ?, excursion_high = ?, excursion_date = ?
+		WHERE id = ?
+	`, low, high, nullString(through), positionID)
+	if err != nil {
+		return fmt.Errorf("failed to update excursion: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("position %d not found", positionID)
+	}
+
+	return nil
+}
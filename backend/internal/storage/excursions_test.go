@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestSetPositionExcursion(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := createTestPosition(t, db, "AAPL", 100, 96, 50)
+
+	if err := db.SetPositionExcursion(position.ID, 97.5, 108, "2025-01-10"); err != nil {
+		t.Fatalf("SetPositionExcursion failed: %v", err)
+	}
+
+	got, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if got.ExcursionLow != 97.5 || got.ExcursionHigh != 108 || got.ExcursionDate != "2025-01-10" {
+		t.Errorf("Expected range 97.50-108.00 through 2025-01-10, got %.2f-%.2f through %s",
+			got.ExcursionLow, got.ExcursionHigh, got.ExcursionDate)
+	}
+
+	if err := db.SetPositionExcursion(position.ID, 110, 108, "2025-01-11"); err == nil {
+		t.Error("Expected an error for a low above the high")
+	}
+	if err := db.SetPositionExcursion(9999, 90, 100, ""); err == nil {
+		t.Error("Expected an error for an unknown position")
+	}
+}
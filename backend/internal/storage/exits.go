@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Trade outcomes recorded on closed positions and trade_history
+const (
+	OutcomeWin     = "WIN"
+	OutcomeLoss    = "LOSS"
+	OutcomeScratch = "SCRATCH"
+)
+
+// OutcomeForPnL classifies realized P&L as WIN, LOSS or SCRATCH (break-even to the cent)
+func OutcomeForPnL(pnl float64) string {
+	switch {
+	case math.Round(pnl*100) > 0:
+		return OutcomeWin
+	case math.Round(pnl*100) < 0:
+		return OutcomeLoss
+	default:
+		return OutcomeScratch
+	}
+}
+
+// SharesForPct converts a percentage of the open shares into a share count
+// Rounds to the nearest share, but always closes at least one.
+func SharesForPct(openShares int, pct float64) (int, error) {
+	if pct <= 0 || pct > 100 {
+		return 0, fmt.Errorf("percent to close must be between 0 and 100, got %.2f", pct)
+	}
+	shares := int(math.Round(float64(openShares) * pct / 100))
+	return max(1, min(shares, openShares)), nil
+}
+
+// PartialClose sells part of an open position (scale-out)
+//
+// The shares are taken from the position's lots by the given cost method.
+// The exit's P&L is realized on those lots, the remaining risk (and so
+// heat) is recomputed from the lots still open, and the exit is written to
+// trade_history. The position stays OPEN until its last share is sold; that
+// exit closes it with an outcome taken from its total P&L and starts the
+// bucket cooldown on a loss, like ClosePosition.
+func (db *DB) PartialClose(ticker string, shares int, exitPrice float64, method CostMethod) (*LotCloseResult, error) {
+	position, err := db.GetPositionByTicker(ticker)
+	if err != nil {
+		return nil, err
+	}
+	if shares <= 0 {
+		return nil, fmt.Errorf("shares to close must be positive, got %d", shares)
+	}
+	return db.exitPosition(position, shares, exitPrice, method, "", time.Now(), 0)
+}
+
+// ExitPositionFill sells shares of an open position at a broker fill
+//
+// Like PartialClose (FIFO lots, position closed when the last share goes)
+// but dated at the fill, with the fill's commission charged to the
+// position's P&L.
+func (db *DB) ExitPositionFill(positionID int, shares int, exitPrice, commission float64, filledAt time.Time) (*LotCloseResult, error) {
+	position, err := db.GetPosition(positionID)
+	if err != nil {
+		return nil, err
+	}
+	if position.Status != "OPEN" {
+		return nil, fmt.Errorf("position %d is %s, can only exit OPEN positions", positionID, position.Status)
+	}
+	if shares <= 0 {
+		return nil, fmt.Errorf("shares to close must be positive, got %d", shares)
+	}
+	return db.exitPosition(position, shares, exitPrice, CostMethodFIFO, "", filledAt, commission)
+}
+
+// exitPosition sells shares of an open position (0 = every remaining share)
+// at the time given, paying commission
+// If the exit takes the last share the position is closed with outcome,
+// or with the outcome of its total P&L when outcome is empty. The exit's
+// trade_history row is charged every commission not yet booked (the
+// entry's and add-ons' on the first exit).
+func (db *DB) exitPosition(position *Position, shares int, exitPrice float64, method CostMethod, outcome string, at time.Time, commission float64) (*LotCloseResult, error) {
+	if exitPrice <= 0 {
+		return nil, fmt.Errorf("exit price must be positive, got %.2f", exitPrice)
+	}
+	if outcome != "" && outcome != OutcomeWin && outcome != OutcomeLoss && outcome != OutcomeScratch {
+		return nil, fmt.Errorf("outcome must be WIN, LOSS, or SCRATCH, got: %s", outcome)
+	}
+	if !tracksLots(position) {
+		return nil, optionExitError(position)
+	}
+
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := 0
+	for _, lot := range lots {
+		remaining += lot.RemainingShares
+	}
+	if shares == 0 {
+		shares = remaining
+	}
+	if shares > remaining {
+		return nil, fmt.Errorf("cannot close %d shares of %s, only %d open", shares, position.Ticker, remaining)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if lots, err = storeLegacyLot(tx, lots); err != nil {
+		return nil, err
+	}
+
+	now := at
+	exitDate := now.Format("2006-01-02")
+
+	result := &LotCloseResult{PositionID: position.ID, Ticker: position.Ticker, Method: method, ExitPrice: exitPrice}
+	if shares > 0 {
+		if result, err = closeLots(tx, position, lots, shares, exitPrice, method, now); err != nil {
+			return nil, err
+		}
+	}
+	if result.Commissions, err = bookCommissions(tx, position.ID, commission); err != nil {
+		return nil, err
+	}
+	result.RealizedPnL -= result.Commissions
+	totals, err := syncPositionFromLots(tx, position.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.TotalPnL = totals.RealizedPnL
+
+	strategy, verb := StrategyLongBreakout, "sold"
+	if position.Direction == DirectionShort {
+		strategy, verb = StrategyShortBreakout, "covered"
+	}
+
+	exitOutcome := OutcomeForPnL(result.RealizedPnL)
+	notes := fmt.Sprintf("Position #%d: %s %d of %d shares (%s)", position.ID, verb, shares, remaining, method)
+
+	if shares == remaining {
+		if outcome == "" {
+			outcome = OutcomeForPnL(totals.RealizedPnL)
+		}
+		exitOutcome = outcome
+		notes = fmt.Sprintf("Position #%d: final exit of %d shares", position.ID, shares)
+
+		// Shares, entry and pnl were set from the lots
+		query := `
+			UPDATE positions
+			SET status = 'CLOSED',
+			    exit_price = ?,
+			    exit_date = ?,
+			    outcome = ?,
+			    risk_dollars = 0,
+			    closed_at = ?
+			WHERE id = ?
+		`
+		if _, err := tx.Exec(query, exitPrice, exitDate, outcome, now, position.ID); err != nil {
+			return nil, fmt.Errorf("failed to close position: %w", err)
+		}
+		result.Closed = true
+		result.Outcome = outcome
+	}
+
+	// One trade_history row per exit
+	if shares > 0 {
+		if err := addTradeToHistory(tx, &TradeHistoryEntry{
+			Ticker:         position.Ticker,
+			Strategy:       strategy,
+			BreakoutSystem: position.BreakoutSystem,
+			InstrumentType: "STOCK",
+			Sector:         position.Bucket, // Positions carry the bucket only
+			Bucket:         position.Bucket,
+			EntryDate:      position.TradeDate(),
+			ExitDate:       exitDate,
+			Status:         "CLOSED",
+			Shares:         shares,
+			RiskDollars:    result.InitialRisk,
+			EntryPrice:     result.CostBasis,
+			ExitPrice:      exitPrice,
+			PnL:            result.RealizedPnL,
+			Outcome:        exitOutcome,
+			Notes:          notes,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit exit: %w", err)
+	}
+
+	// Trigger cooldown if loss
+	if result.Closed && outcome == OutcomeLoss && position.Bucket != "" {
+		reason := fmt.Sprintf("Loss on %s", position.Ticker)
+		if err := db.TriggerBucketCooldown(position.Bucket, reason); err != nil {
+			return nil, fmt.Errorf("failed to trigger cooldown: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// optionExitError refuses a share exit (close, scale-out or broker fill) on
+// an option position: its legs' close is booked, with a trade_history row,
+// by roll or expire
+func optionExitError(p *Position) error {
+	return fmt.Errorf("position %d (%s) is an option position: close it with roll or expire", p.ID, p.Ticker)
+}
+
+// bookCommissions adds commission to the position and returns every
+// commission not yet charged to a trade_history row, marking it charged
+func bookCommissions(tx *sql.Tx, positionID int, commission float64) (float64, error) {
+	var paid, booked float64
+	if err := tx.QueryRow(`
+		SELECT COALESCE(commissions, 0), COALESCE(commissions_booked, 0) FROM positions WHERE id = ?
+	`, positionID).Scan(&paid, &booked); err != nil {
+		return 0, fmt.Errorf("failed to read commissions: %w", err)
+	}
+	paid += commission
+
+	if _, err := tx.Exec(`UPDATE positions SET commissions = ?, commissions_booked = ? WHERE id = ?`,
+		paid, paid, positionID); err != nil {
+		return 0, fmt.Errorf("failed to book commissions: %w", err)
+	}
+	return paid - booked, nil
+}
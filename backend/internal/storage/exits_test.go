@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPartialCloseKeepsPositionOpen(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTwoUnitPosition(t, db)
+
+	// Take off unit 1 (25 @ 180) at 185
+	result, err := db.PartialClose("AAPL", 25, 185, CostMethodFIFO)
+	if err != nil {
+		t.Fatalf("PartialClose failed: %v", err)
+	}
+	if result.Closed || result.RemainingShares != 25 {
+		t.Errorf("Expected position to stay open with 25 shares, got closed=%v remaining=%d",
+			result.Closed, result.RemainingShares)
+	}
+	// Unit 2 is left: 25 × (182 - 179)
+	if result.RemainingRisk != 75 {
+		t.Errorf("Expected remaining risk 75, got %.2f", result.RemainingRisk)
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.Status != "OPEN" {
+		t.Errorf("Expected OPEN, got %s", stored.Status)
+	}
+
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	if heat != 75 {
+		t.Errorf("Expected heat 75 after scale-out, got %.2f", heat)
+	}
+
+	trades, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("Expected 1 trade_history row, got %d", len(trades))
+	}
+	trade := trades[0]
+	if trade.Shares != 25 || trade.EntryPrice != 180 || trade.ExitPrice != 185 || trade.PnL != 125 {
+		t.Errorf("Unexpected exit row: %+v", trade)
+	}
+	// Initial risk of the shares sold: 25 × (180 - 177)
+	if trade.RiskDollars != 75 || trade.Outcome != OutcomeWin {
+		t.Errorf("Expected risk 75 and WIN, got %.2f and %s", trade.RiskDollars, trade.Outcome)
+	}
+}
+
+func TestPartialCloseOfLastShareClosesPosition(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTwoUnitPosition(t, db)
+
+	if _, err := db.PartialClose("AAPL", 25, 185, CostMethodFIFO); err != nil {
+		t.Fatalf("First PartialClose failed: %v", err)
+	}
+
+	// 25 × (176 - 182) = -150, so the trade is 125 - 150 = -25 overall
+	result, err := db.PartialClose("AAPL", 25, 176, CostMethodFIFO)
+	if err != nil {
+		t.Fatalf("Second PartialClose failed: %v", err)
+	}
+	if !result.Closed || result.Outcome != OutcomeLoss {
+		t.Errorf("Expected position closed as LOSS, got closed=%v outcome=%s", result.Closed, result.Outcome)
+	}
+	if math.Abs(result.RealizedPnL+150) > 1e-9 || math.Abs(result.TotalPnL+25) > 1e-9 {
+		t.Errorf("Expected exit P&L -150 and total -25, got %.2f and %.2f", result.RealizedPnL, result.TotalPnL)
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.Status != "CLOSED" || stored.Outcome != OutcomeLoss || math.Abs(stored.PnL+25) > 1e-9 {
+		t.Errorf("Unexpected closed position: status=%s outcome=%s pnl=%.2f", stored.Status, stored.Outcome, stored.PnL)
+	}
+
+	trades, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trade_history rows, got %d", len(trades))
+	}
+	if trades[0].PnL != -150 || trades[1].PnL != 125 {
+		t.Errorf("Expected exit rows of -150 and 125, got %.2f and %.2f", trades[0].PnL, trades[1].PnL)
+	}
+
+	if _, err := db.PartialClose("AAPL", 1, 180, CostMethodFIFO); err == nil {
+		t.Error("Expected error closing shares of a closed position")
+	}
+}
+
+func TestPartialCloseErrors(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	openTwoUnitPosition(t, db)
+
+	if _, err := db.PartialClose("AAPL", 51, 185, CostMethodFIFO); err == nil {
+		t.Error("Expected error closing more shares than are open")
+	}
+	if _, err := db.PartialClose("AAPL", 0, 185, CostMethodFIFO); err == nil {
+		t.Error("Expected error closing zero shares")
+	}
+	if _, err := db.PartialClose("AAPL", 10, 185, CostMethod("HIFO")); err == nil {
+		t.Error("Expected error for an unknown cost method")
+	}
+	if _, err := db.PartialClose("AAPL", 10, 0, CostMethodFIFO); err == nil {
+		t.Error("Expected error for a zero exit price")
+	}
+}
+
+func TestClosePositionWritesTradeHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	openTestPosition(t, db)
+
+	if err := db.ClosePosition("AAPL", 179, OutcomeScratch); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+
+	trades, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Shares != 25 || trades[0].Outcome != OutcomeScratch {
+		t.Errorf("Expected one 25-share SCRATCH exit, got %+v", trades)
+	}
+
+	if err := db.ClosePosition("AAPL", 179, "MAYBE"); err == nil {
+		t.Error("Expected error closing an already closed position")
+	}
+}
+
+func TestSharesForPct(t *testing.T) {
+	tests := []struct {
+		open int
+		pct  float64
+		want int
+	}{
+		{25, 50, 13}, // 12.5 rounds up
+		{50, 50, 25},
+		{25, 1, 1}, // Always at least one share
+		{25, 100, 25},
+	}
+	for _, tt := range tests {
+		got, err := SharesForPct(tt.open, tt.pct)
+		if err != nil {
+			t.Fatalf("SharesForPct(%d, %.0f) failed: %v", tt.open, tt.pct, err)
+		}
+		if got != tt.want {
+			t.Errorf("SharesForPct(%d, %.0f) = %d, want %d", tt.open, tt.pct, got, tt.want)
+		}
+	}
+
+	for _, pct := range []float64{0, -10, 101} {
+		if _, err := SharesForPct(25, pct); err == nil {
+			t.Errorf("Expected error for %.0f%%", pct)
+		}
+	}
+}
+
+func TestOutcomeForPnL(t *testing.T) {
+	if OutcomeForPnL(10) != OutcomeWin || OutcomeForPnL(-0.5) != OutcomeLoss || OutcomeForPnL(0.001) != OutcomeScratch {
+		t.Error("Unexpected outcome classification")
+	}
+}
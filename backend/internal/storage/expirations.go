@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// OptionExpiration is an option position settled at expiration
+type OptionExpiration struct {
+	SettlementDate  string         `json:"settlement_date"` // YYYY-MM-DD (default: the expiration)
+	SettlementPrice float64        `json:"settlement_price"`
+	RealizedPnL     float64        `json:"realized_pnl"` // Whole position, marked at the settlement price
+	Detail          string         `json:"detail"`       // Leg outcomes, for the trade_history notes
+	Stock           *StockDelivery `json:"stock,omitempty"`
+}
+
+// StockDelivery is stock left by exercised or assigned legs
+type StockDelivery struct {
+	Direction   string  `json:"direction"` // LONG or SHORT
+	Shares      int     `json:"shares"`
+	EntryPrice  float64 `json:"entry_price"` // The settlement price
+	Stop        float64 `json:"stop"`
+	RiskDollars float64 `json:"risk_dollars"`
+	CostBasis   float64 `json:"cost_basis"` // Net cash paid for the shares, premiums included
+}
+
+// ExpirePosition closes an option position at expiration
+//
+// The position is closed at the settlement price with the realized P&L
+// and its outcome, written to trade_history, and a loss starts the bucket
+// cooldown like any other exit. Stock left by exercised or assigned legs
+// opens a STOCK position in the same bucket (returned; nil when there is
+// none) that points back at the option position (converted_from_id).
+func (db *DB) ExpirePosition(positionID int, expiration OptionExpiration) (*Position, error) {
+	old, err := db.GetPosition(positionID)
+	if err != nil {
+		return nil, err
+	}
+	if old.Status != "OPEN" || old.InstrumentType != InstrumentOption {
+		return nil, fmt.Errorf("position %d is not an open option position", positionID)
+	}
+	if expiration.SettlementPrice <= 0 {
+		return nil, fmt.Errorf("settlement price must be positive, got %.2f", expiration.SettlementPrice)
+	}
+	if expiration.SettlementDate == "" {
+		expiration.SettlementDate = old.PrimaryExpirationDate
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var stock *Position
+	if delivery := expiration.Stock; delivery != nil {
+		direction, err := ParseDirection(delivery.Direction)
+		if err != nil {
+			return nil, err
+		}
+		if delivery.Shares <= 0 {
+			return nil, fmt.Errorf("delivered shares must be positive, got %d", delivery.Shares)
+		}
+
+		stock = &Position{
+			Ticker:          old.Ticker,
+			Direction:       direction,
+			EntryPrice:      delivery.EntryPrice,
+			CurrentStop:     delivery.Stop,
+			InitialStop:     delivery.Stop,
+			Shares:          delivery.Shares,
+			RiskDollars:     delivery.RiskDollars,
+			Bucket:          old.Bucket,
+			Status:          "OPEN",
+			DecisionID:      old.DecisionID,
+			OpenedAt:        now,
+			InstrumentType:  InstrumentStock,
+			EntryDate:       expiration.SettlementDate,
+			MaxUnits:        old.MaxUnits,
+			CurrentUnits:    1,
+			ExitLookback:    old.ExitLookback,
+			StopDate:        expiration.SettlementDate,
+			CostBasis:       delivery.CostBasis,
+			ConvertedFromID: old.ID,
+			InitialRisk:     delivery.RiskDollars,
+			BreakoutSystem:  old.BreakoutSystem,
+		}
+
+		query := `
+			INSERT INTO positions (
+				ticker, direction, entry_price, current_stop, initial_stop,
+				shares, risk_dollars, bucket, status, decision_id,
+				instrument_type, entry_date, max_units, current_units,
+				exit_lookback, stop_date, cost_basis, converted_from_id,
+				initial_risk, breakout_system, opened_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'OPEN', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		result, err := tx.Exec(query,
+			stock.Ticker, stock.Direction, stock.EntryPrice, stock.CurrentStop, stock.InitialStop,
+			stock.Shares, stock.RiskDollars, stock.Bucket, stock.DecisionID,
+			stock.InstrumentType, stock.EntryDate, stock.MaxUnits, stock.CurrentUnits,
+			stock.ExitLookback, stock.StopDate, stock.CostBasis, stock.ConvertedFromID,
+			nullFloat(stock.InitialRisk), nullString(stock.BreakoutSystem), stock.OpenedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open stock position: %w", err)
+		}
+		id, _ := result.LastInsertId()
+		stock.ID = int(id)
+
+		if err := insertLot(tx, PositionLot{
+			PositionID:  stock.ID,
+			UnitNum:     1,
+			Shares:      stock.Shares,
+			EntryPrice:  stock.EntryPrice,
+			StopPrice:   stock.CurrentStop,
+			RiskDollars: stock.RiskDollars,
+			FilledAt:    now,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	pnl := old.PnL + expiration.RealizedPnL
+	outcome := OutcomeForPnL(pnl)
+	query := `
+		UPDATE positions
+		SET status = 'CLOSED',
+		    exit_price = ?,
+		    exit_date = ?,
+		    outcome = ?,
+		    pnl = ?,
+		    risk_dollars = 0,
+		    closed_at = ?
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, expiration.SettlementPrice, expiration.SettlementDate, outcome, pnl, now, old.ID); err != nil {
+		return nil, fmt.Errorf("failed to close expired position: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE position_lots SET remaining_shares = 0, closed_at = ? WHERE position_id = ? AND remaining_shares > 0`,
+		now, old.ID); err != nil {
+		return nil, fmt.Errorf("failed to close expired lots: %w", err)
+	}
+
+	strategy := StrategyLongBreakout
+	if old.Direction == DirectionShort {
+		strategy = StrategyShortBreakout
+	}
+	notes := fmt.Sprintf("Position #%d expired: %s", old.ID, expiration.Detail)
+	if stock != nil {
+		notes += fmt.Sprintf("; opened stock position #%d", stock.ID)
+	}
+	history := []TradeHistoryEntry{{
+		Strategy:        strategy,
+		OptionsStrategy: old.OptionsStrategy,
+		InstrumentType:  InstrumentOption,
+		EntryDate:       old.TradeDate(),
+		ExpirationDate:  old.PrimaryExpirationDate,
+		ExitDate:        expiration.SettlementDate,
+		Status:          "CLOSED",
+		Contracts:       legContracts(old.LegsJSON),
+		RiskDollars:     old.RiskDollars,
+		EntryPrice:      old.UnderlyingAtEntry,
+		ExitPrice:       expiration.SettlementPrice,
+		PnL:             expiration.RealizedPnL,
+		Outcome:         outcome,
+		Notes:           notes,
+	}}
+	if stock != nil {
+		stockStrategy := StrategyLongBreakout
+		if stock.Direction == DirectionShort {
+			stockStrategy = StrategyShortBreakout
+		}
+		history = append(history, TradeHistoryEntry{
+			Strategy:       stockStrategy,
+			InstrumentType: InstrumentStock,
+			EntryDate:      expiration.SettlementDate,
+			Status:         "OPEN",
+			Shares:         stock.Shares,
+			RiskDollars:    stock.RiskDollars,
+			EntryPrice:     stock.EntryPrice,
+			Notes: fmt.Sprintf("Position #%d from option position #%d, cost basis $%.2f",
+				stock.ID, old.ID, stock.CostBasis),
+		})
+	}
+	for _, entry := range history {
+		entry.Ticker = old.Ticker
+		entry.BreakoutSystem = old.BreakoutSystem
+		entry.Sector = old.Bucket // Positions carry the bucket only
+		entry.Bucket = old.Bucket
+		if err := addTradeToHistory(tx, &entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit expiration: %w", err)
+	}
+
+	// Trigger cooldown if loss
+	if outcome == OutcomeLoss && old.Bucket != "" {
+		reason := fmt.Sprintf("Loss on %s", old.Ticker)
+		if err := db.TriggerBucketCooldown(old.Bucket, reason); err != nil {
+			return nil, fmt.Errorf("failed to trigger cooldown: %w", err)
+		}
+	}
+
+	return stock, nil
+}
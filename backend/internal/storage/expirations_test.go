@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestExpirePositionOpensAssignedStock(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	old := openTestPutSpread(t, db)
+
+	// Settled at 93: the short 95 put is assigned, the long 90 put expires
+	stock, err := db.ExpirePosition(old.ID, OptionExpiration{
+		SettlementPrice: 93,
+		RealizedPnL:     -80,
+		Detail:          "SELL PUT 95.00 ASSIGNED, BUY PUT 90.00 EXPIRED",
+		Stock: &StockDelivery{
+			Direction:   DirectionLong,
+			Shares:      100,
+			EntryPrice:  93,
+			Stop:        88,
+			RiskDollars: 500,
+			CostBasis:   9380,
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExpirePosition failed: %v", err)
+	}
+	if stock == nil {
+		t.Fatal("Expected a stock position")
+	}
+
+	closed, err := db.GetPosition(old.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if closed.Status != "CLOSED" || closed.PnL != -80 || closed.Outcome != OutcomeLoss || closed.ExitDate != old.PrimaryExpirationDate {
+		t.Errorf("Expected a CLOSED $-80 LOSS on %s, got %s $%.2f %s on %s",
+			old.PrimaryExpirationDate, closed.Status, closed.PnL, closed.Outcome, closed.ExitDate)
+	}
+
+	stored, err := db.GetPositionByTicker("AAPL")
+	if err != nil {
+		t.Fatalf("GetPositionByTicker failed: %v", err)
+	}
+	if stored.ID != stock.ID || stored.InstrumentType != InstrumentStock || stored.ConvertedFromID != old.ID {
+		t.Errorf("Expected stock position #%d from #%d, got %+v", stock.ID, old.ID, stored)
+	}
+	if stored.Shares != 100 || stored.EntryPrice != 93 || stored.CurrentStop != 88 || stored.CostBasis != 9380 || stored.Bucket != "Tech/Comm" {
+		t.Errorf("Unexpected stock position: %+v", stored)
+	}
+
+	// The stock's lot carries the heat now
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	if heat != 500 {
+		t.Errorf("Expected heat 500, got %.2f", heat)
+	}
+	lots, err := db.GetPositionLots(stock.ID)
+	if err != nil || len(lots) != 1 || lots[0].RemainingShares != 100 {
+		t.Errorf("Expected one open lot of 100 shares, got %+v (%v)", lots, err)
+	}
+
+	// The loss puts the bucket on cooldown
+	if err := db.CheckBucketCooldown("Tech/Comm"); err == nil {
+		t.Error("Expected a Tech/Comm cooldown after the loss")
+	}
+
+	trades, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(trades) != 1 || trades[0].PnL != -80 || trades[0].InstrumentType != InstrumentOption {
+		t.Errorf("Expected one closed option trade of $-80, got %+v", trades)
+	}
+}
+
+func TestExpirePositionWorthless(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	old := openTestPutSpread(t, db)
+
+	stock, err := db.ExpirePosition(old.ID, OptionExpiration{SettlementPrice: 101, RealizedPnL: 120})
+	if err != nil {
+		t.Fatalf("ExpirePosition failed: %v", err)
+	}
+	if stock != nil {
+		t.Errorf("Expected no stock position, got %+v", stock)
+	}
+
+	closed, err := db.GetPosition(old.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if closed.Status != "CLOSED" || closed.Outcome != OutcomeWin || closed.RiskDollars != 0 {
+		t.Errorf("Expected a closed WIN with no risk, got %+v", closed)
+	}
+
+	if _, err := db.ExpirePosition(old.ID, OptionExpiration{SettlementPrice: 101}); err == nil {
+		t.Error("Expected expiring a closed position to fail")
+	}
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Broker fill statuses
+const (
+	FillApplied   = "APPLIED"   // Opened, added to or exited a position
+	FillUnmatched = "UNMATCHED" // Needs review; retried on the next import
+	FillIgnored   = "IGNORED"   // Reviewed and deliberately left out
+	FillPending   = "PENDING"   // Being applied; left behind only by an interrupted import
+)
+
+// Broker fill actions
+const (
+	FillActionOpen  = "OPEN"  // Opened a position from a GO decision
+	FillActionAdd   = "ADD"   // Added a unit to an open position
+	FillActionExit  = "EXIT"  // Sold part of an open position
+	FillActionClose = "CLOSE" // Sold the rest of an open position
+)
+
+// BrokerFill is one execution imported from a broker report
+type BrokerFill struct {
+	ID         int        `json:"id"`
+	Broker     string     `json:"broker"`
+	ExecID     string     `json:"exec_id"` // Broker's execution id, unique per broker
+	Account    string     `json:"account,omitempty"`
+	Ticker     string     `json:"ticker"`
+	AssetClass string     `json:"asset_class,omitempty"`
+	Leg        *OptionLeg `json:"leg,omitempty"` // Option fills: the contract traded
+	Side       string     `json:"side"`          // BUY or SELL
+	Quantity   int        `json:"quantity"`
+	Price      float64    `json:"price"`
+	Commission float64    `json:"commission,omitempty"`
+	TradeDate  string     `json:"trade_date"`           // YYYY-MM-DD
+	TradeTime  string     `json:"trade_time,omitempty"` // HH:MM:SS
+	Status     string     `json:"status"`
+	Action     string     `json:"action,omitempty"`
+	PositionID int        `json:"position_id,omitempty"`
+	Detail     string     `json:"detail,omitempty"` // What was done, or why it was not matched
+	Source     string     `json:"source,omitempty"` // Report file name
+	ImportedAt time.Time  `json:"imported_at"`
+}
+
+const brokerFillColumns = `
+	id, broker, exec_id, COALESCE(account, ''), ticker, COALESCE(asset_class, ''),
+	COALESCE(option_leg, ''), side, quantity, price, COALESCE(commission, 0),
+	trade_date, COALESCE(trade_time, ''), status, COALESCE(action, ''),
+	COALESCE(position_id, 0), COALESCE(detail, ''), COALESCE(source, ''), imported_at
+`
+
+func scanBrokerFill(row interface{ Scan(...interface{}) error }) (*BrokerFill, error) {
+	var f BrokerFill
+	var leg string
+	err := row.Scan(
+		&f.ID, &f.Broker, &f.ExecID, &f.Account, &f.Ticker, &f.AssetClass,
+		&leg, &f.Side, &f.Quantity, &f.Price, &f.Commission, &f.TradeDate, &f.TradeTime,
+		&f.Status, &f.Action, &f.PositionID, &f.Detail,
+		&f.Source, &f.ImportedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if leg != "" {
+		f.Leg = &OptionLeg{}
+		if err := json.Unmarshal([]byte(leg), f.Leg); err != nil {
+			return nil, fmt.Errorf("invalid option leg: %w", err)
+		}
+	}
+	return &f, nil
+}
+
+// GetBrokerFill returns the fill imported with the broker's execution id,
+// or nil if it has never been imported
+func (db *DB) GetBrokerFill(broker, execID string) (*BrokerFill, error) {
+	f, err := scanBrokerFill(db.conn.QueryRow(
+		`SELECT `+brokerFillColumns+` FROM broker_fills WHERE broker = ? AND exec_id = ?`, broker, execID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broker fill: %w", err)
+	}
+	return f, nil
+}
+
+// SaveBrokerFill records a fill and how it was applied, replacing an earlier
+// import of the same execution id
+func (db *DB) SaveBrokerFill(f *BrokerFill) error {
+	if f.Side != "BUY" && f.Side != "SELL" {
+		return fmt.Errorf("invalid fill side %q (must be BUY or SELL)", f.Side)
+	}
+
+	var positionID interface{}
+	if f.PositionID > 0 {
+		positionID = f.PositionID
+	}
+	var leg string
+	if f.Leg != nil {
+		data, err := json.Marshal(f.Leg)
+		if err != nil {
+			return fmt.Errorf("failed to encode option leg: %w", err)
+		}
+		leg = string(data)
+	}
+	err := db.conn.QueryRow(`
+		INSERT INTO broker_fills (
+			broker, exec_id, account, ticker, asset_class, option_leg, side, quantity, price,
+			commission, trade_date, trade_time, status, action, position_id, detail, source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(broker, exec_id) DO UPDATE SET
+			status = excluded.status,
+			action = excluded.action,
+			position_id = excluded.position_id,
+			detail = excluded.detail,
+			source = excluded.source,
+			imported_at = CURRENT_TIMESTAMP
+		RETURNING id, imported_at
+	`,
+		f.Broker, f.ExecID, nullString(f.Account), f.Ticker, nullString(f.AssetClass), nullString(leg), f.Side, f.Quantity, f.Price,
+		f.Commission, f.TradeDate, nullString(f.TradeTime), f.Status, nullString(f.Action), positionID,
+		nullString(f.Detail), nullString(f.Source),
+	).Scan(&f.ID, &f.ImportedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save broker fill: %w", err)
+	}
+
+	return nil
+}
+
+// ListBrokerFills returns imported fills in trade order, optionally only
+// those with status (APPLIED, UNMATCHED, IGNORED or PENDING)
+func (db *DB) ListBrokerFills(status string) ([]BrokerFill, error) {
+	query := `SELECT ` + brokerFillColumns + ` FROM broker_fills`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY trade_date, trade_time, id`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list broker fills: %w", err)
+	}
+	defer rows.Close()
+
+	fills := []BrokerFill{}
+	for rows.Next() {
+		f, err := scanBrokerFill(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan broker fill: %w", err)
+		}
+		fills = append(fills, *f)
+	}
+
+	return fills, rows.Err()
+}
+
+// IgnoreBrokerFill marks an unmatched or pending fill as reviewed, so later
+// imports skip it instead of retrying it
+func (db *DB) IgnoreBrokerFill(id int, reason string) error {
+	result, err := db.conn.Exec(`
+		UPDATE broker_fills SET status = ?, detail = ?
+		WHERE id = ? AND status IN (?, ?)
+	`, FillIgnored, nullString(reason), id, FillUnmatched, FillPending)
+	if err != nil {
+		return fmt.Errorf("failed to ignore broker fill: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("no unmatched or pending fill with id %d", id)
+	}
+
+	return nil
+}
+
+// FindDecisionForFill returns the latest GO decision for ticker and
+// direction dated from..to (YYYY-MM-DD) that no position was opened from,
+// or nil if there is none
+func (db *DB) FindDecisionForFill(ticker, direction, from, to string) (*Decision, error) {
+	direction, err := ParseDirection(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	var date string
+	err = db.conn.QueryRow(`
+		SELECT d.date FROM decisions d
+		WHERE d.ticker = ? AND d.action = 'GO' AND COALESCE(d.direction, 'LONG') = ?
+		  AND d.date BETWEEN ? AND ?
+		  AND NOT EXISTS (SELECT 1 FROM positions p WHERE p.decision_id = d.id)
+		ORDER BY d.date DESC
+		LIMIT 1
+	`, ticker, direction, from, to).Scan(&date)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find decision: %w", err)
+	}
+
+	return db.GetDecisionForDate(ticker, date)
+}
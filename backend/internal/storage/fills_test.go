@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBrokerFills(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	fill := &BrokerFill{
+		Broker: "IBKR", ExecID: "0001", Ticker: "AAPL", Side: "BUY",
+		Quantity: 50, Price: 100.5, Commission: 1, TradeDate: "2025-01-02",
+		Status: FillUnmatched, Detail: "no GO decision",
+	}
+	if err := db.SaveBrokerFill(fill); err != nil {
+		t.Fatalf("SaveBrokerFill failed: %v", err)
+	}
+	if fill.ID == 0 {
+		t.Error("Expected SaveBrokerFill to set the id")
+	}
+
+	// Re-importing the same execution updates it in place
+	fill.Status, fill.Detail = FillApplied, ""
+	if err := db.SaveBrokerFill(fill); err != nil {
+		t.Fatalf("SaveBrokerFill (again) failed: %v", err)
+	}
+	fills, err := db.ListBrokerFills("")
+	if err != nil {
+		t.Fatalf("ListBrokerFills failed: %v", err)
+	}
+	if len(fills) != 1 || fills[0].Status != FillApplied || fills[0].Detail != "" {
+		t.Fatalf("Expected 1 APPLIED fill, got %+v", fills)
+	}
+
+	got, err := db.GetBrokerFill("IBKR", "0001")
+	if err != nil || got == nil || got.Price != 100.5 {
+		t.Fatalf("Expected the fill back, got %+v (%v)", got, err)
+	}
+	if got, _ := db.GetBrokerFill("IBKR", "9999"); got != nil {
+		t.Errorf("Expected nil for an unknown execution, got %+v", got)
+	}
+
+	// Only unmatched fills can be ignored
+	if err := db.IgnoreBrokerFill(fill.ID, "manual"); err == nil {
+		t.Error("Expected an error ignoring an applied fill")
+	}
+	other := &BrokerFill{
+		Broker: "IBKR", ExecID: "0002", Ticker: "MSFT", AssetClass: "OPT", Side: "SELL",
+		Leg:      &OptionLeg{Type: "CALL", Strike: 450, Exp: "2025-01-17", Qty: 1, Action: "SELL", Price: 3.2},
+		Quantity: 1, Price: 3.2, TradeDate: "2025-01-03", Status: FillUnmatched,
+	}
+	if err := db.SaveBrokerFill(other); err != nil {
+		t.Fatalf("SaveBrokerFill failed: %v", err)
+	}
+	if got, _ := db.GetBrokerFill("IBKR", "0002"); got == nil || got.Leg == nil || *got.Leg != *other.Leg {
+		t.Errorf("Expected the option leg back, got %+v", got)
+	}
+	if err := db.IgnoreBrokerFill(other.ID, "closed before tracking"); err != nil {
+		t.Fatalf("IgnoreBrokerFill failed: %v", err)
+	}
+	if unmatched, _ := db.ListBrokerFills(FillUnmatched); len(unmatched) != 0 {
+		t.Errorf("Expected no unmatched fills, got %d", len(unmatched))
+	}
+
+	if err := db.SaveBrokerFill(&BrokerFill{Broker: "IBKR", ExecID: "x", Side: "SHORT"}); err == nil {
+		t.Error("Expected an error for an invalid side")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	createTestPosition(t, db, "AAPL", 100, 96, 50)
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := db.Snapshot(path); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	snapshot, err := New(path)
+	if err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer snapshot.Close()
+
+	// Changes to the snapshot leave the original alone
+	if err := snapshot.ClosePosition("AAPL", 110, ""); err != nil {
+		t.Fatalf("ClosePosition on snapshot failed: %v", err)
+	}
+	if _, err := db.GetPositionByTicker("AAPL"); err != nil {
+		t.Errorf("Expected AAPL still open in the original: %v", err)
+	}
+}
+
+func TestOpenPositionFromFill(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// Planned: 50 AAPL at 100 with a 4.00 stop distance
+	if _, err := db.SaveDecision(Decision{
+		Date: "2025-01-02", Ticker: "AAPL", Action: "GO", Entry: 100, ATR: 2,
+		StopDistance: 4, InitialStop: 96, Shares: 50, RiskDollars: 200,
+		Banner: "GREEN", Method: "stock",
+	}); err != nil {
+		t.Fatalf("SaveDecision failed: %v", err)
+	}
+
+	decision, err := db.FindDecisionForFill("AAPL", "LONG", "2024-12-29", "2025-01-03")
+	if err != nil || decision == nil {
+		t.Fatalf("Expected the GO decision, got %+v (%v)", decision, err)
+	}
+	if d, _ := db.FindDecisionForFill("AAPL", "SHORT", "2024-12-29", "2025-01-03"); d != nil {
+		t.Errorf("Expected no SHORT decision, got %+v", d)
+	}
+
+	// Filled 40 shares at 101 the next morning
+	filledAt := time.Date(2025, 1, 3, 9, 35, 0, 0, time.UTC)
+	position, err := db.OpenPositionFromFill(decision, 40, 101, 1.25, filledAt)
+	if err != nil {
+		t.Fatalf("OpenPositionFromFill failed: %v", err)
+	}
+	if position.CurrentStop != 97 || math.Abs(position.RiskDollars-160) > 1e-9 {
+		t.Errorf("Expected stop 97.00 and risk 160.00, got %.2f and %.2f", position.CurrentStop, position.RiskDollars)
+	}
+
+	got, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if got.EntryDate != "2025-01-03" || got.Commissions != 1.25 || got.EntryPrice != 101 || got.Shares != 40 {
+		t.Errorf("Expected 40 @ 101.00 on 2025-01-03 with $1.25 commission, got %d @ %.2f on %s with $%.2f",
+			got.Shares, got.EntryPrice, got.EntryDate, got.Commissions)
+	}
+
+	// The decision is used up
+	if d, _ := db.FindDecisionForFill("AAPL", "LONG", "2024-12-29", "2025-01-03"); d != nil {
+		t.Errorf("Expected the decision to be used, got %+v", d)
+	}
+
+	// Closing at a fill charges its commission against the P&L
+	result, err := db.ExitPositionFill(position.ID, 40, 111, 1.25, filledAt.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("ExitPositionFill failed: %v", err)
+	}
+	if !result.Closed || math.Abs(result.RealizedPnL-397.5) > 1e-9 {
+		t.Errorf("Expected the position closed with $397.50 net, got closed=%v $%.2f", result.Closed, result.RealizedPnL)
+	}
+}
@@ -0,0 +1,518 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CostMethod selects which lots an exit is taken from
+type CostMethod string
+
+const (
+	CostMethodFIFO    CostMethod = "FIFO"    // Oldest lots first
+	CostMethodLIFO    CostMethod = "LIFO"    // Newest lots first
+	CostMethodAverage CostMethod = "AVERAGE" // Pro-rata across lots at the average cost
+)
+
+// ParseCostMethod parses a cost method name; empty means FIFO
+func ParseCostMethod(s string) (CostMethod, error) {
+	switch method := CostMethod(strings.ToUpper(strings.TrimSpace(s))); method {
+	case "":
+		return CostMethodFIFO, nil
+	case CostMethodFIFO, CostMethodLIFO, CostMethodAverage:
+		return method, nil
+	case "AVG":
+		return CostMethodAverage, nil
+	default:
+		return "", fmt.Errorf("invalid cost method %q (use FIFO, LIFO or AVERAGE)", s)
+	}
+}
+
+// PositionLot is one fill of a position: unit 1 or a pyramid add-on
+type PositionLot struct {
+	ID              int       `json:"id"`
+	PositionID      int       `json:"position_id"`
+	UnitNum         int       `json:"unit_num"`
+	Shares          int       `json:"shares"`           // Filled
+	RemainingShares int       `json:"remaining_shares"` // Still open
+	EntryPrice      float64   `json:"entry_price"`
+	StopPrice       float64   `json:"stop_price"`
+	RiskDollars     float64   `json:"risk_dollars"` // Risk on the remaining shares
+	InitialRisk     float64   `json:"initial_risk"` // 1R: all shares to the stop the lot was filled with
+	RealizedPnL     float64   `json:"realized_pnl"`
+	FilledAt        time.Time `json:"filled_at"`
+	ClosedAt        time.Time `json:"closed_at,omitempty"`
+}
+
+// UnitFill describes a pyramid add-on to record with AddUnit
+type UnitFill struct {
+	Price      float64   `json:"price"`
+	Shares     int       `json:"shares"`
+	NewStop    float64   `json:"new_stop"`             // Stop for every unit after the add
+	Commission float64   `json:"commission,omitempty"` // Broker commission on the fill
+	FilledAt   time.Time `json:"filled_at,omitempty"`  // Default: now
+}
+
+// LotExit is the part of an exit taken from one lot
+type LotExit struct {
+	LotID      int     `json:"lot_id"`
+	UnitNum    int     `json:"unit_num"`
+	Shares     int     `json:"shares"`
+	CostBasis  float64 `json:"cost_basis"` // Per share
+	RealizedPL float64 `json:"realized_pnl"`
+}
+
+// LotCloseResult summarizes an exit across lots
+type LotCloseResult struct {
+	PositionID      int        `json:"position_id"`
+	Ticker          string     `json:"ticker"`
+	Method          CostMethod `json:"method"`
+	Shares          int        `json:"shares"`
+	ExitPrice       float64    `json:"exit_price"`
+	CostBasis       float64    `json:"cost_basis"`            // Average cost per share of the shares sold
+	RealizedPnL     float64    `json:"realized_pnl"`          // On this exit, net of Commissions
+	TotalPnL        float64    `json:"total_pnl"`             // Realized over the position so far
+	InitialRisk     float64    `json:"initial_risk"`          // 1R of the shares sold, from each lot's initial risk
+	Commissions     float64    `json:"commissions,omitempty"` // Charged to this exit (its own and any unbooked entry commissions)
+	RemainingShares int        `json:"remaining_shares"`
+	RemainingRisk   float64    `json:"remaining_risk"`
+	Closed          bool       `json:"closed"`            // Last share gone, position CLOSED
+	Outcome         string     `json:"outcome,omitempty"` // Set when Closed
+	Lots            []LotExit  `json:"lots"`
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertLot records one fill; all of its shares start open
+// InitialRisk defaults to RiskDollars, the risk at the fill.
+func insertLot(ex execer, lot PositionLot) error {
+	if lot.InitialRisk == 0 {
+		lot.InitialRisk = lot.RiskDollars
+	}
+	query := `
+		INSERT INTO position_lots (position_id, unit_num, shares, remaining_shares, entry_price, stop_price, risk_dollars, initial_risk, filled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := ex.Exec(query, lot.PositionID, lot.UnitNum, lot.Shares, lot.Shares, lot.EntryPrice,
+		lot.StopPrice, lot.RiskDollars, lot.InitialRisk, lot.FilledAt)
+	if err != nil {
+		return fmt.Errorf("failed to record lot: %w", err)
+	}
+	return nil
+}
+
+// GetPositionLots returns a position's fills in unit order, including
+// lots that have been fully closed
+// Positions opened before lots were tracked return a single synthesized
+// unit 1 built from the position row (ID 0, not yet stored).
+func (db *DB) GetPositionLots(positionID int) ([]PositionLot, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, position_id, unit_num, shares, remaining_shares, entry_price, stop_price,
+		       risk_dollars, COALESCE(initial_risk, 0), realized_pnl, filled_at, closed_at
+		FROM position_lots
+		WHERE position_id = ?
+		ORDER BY unit_num
+	`, positionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lots: %w", err)
+	}
+	defer rows.Close()
+
+	lots := []PositionLot{}
+	for rows.Next() {
+		var lot PositionLot
+		var realized sql.NullFloat64
+		var closedAt sql.NullTime
+		if err := rows.Scan(&lot.ID, &lot.PositionID, &lot.UnitNum, &lot.Shares, &lot.RemainingShares,
+			&lot.EntryPrice, &lot.StopPrice, &lot.RiskDollars, &lot.InitialRisk, &realized, &lot.FilledAt, &closedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan lot: %w", err)
+		}
+		lot.RealizedPnL = realized.Float64
+		if closedAt.Valid {
+			lot.ClosedAt = closedAt.Time
+		}
+		lots = append(lots, lot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lots: %w", err)
+	}
+
+	if len(lots) == 0 {
+		position, err := db.GetPosition(positionID)
+		if err != nil {
+			return nil, err
+		}
+		lot := PositionLot{
+			PositionID:  position.ID,
+			UnitNum:     1,
+			Shares:      position.Shares,
+			EntryPrice:  position.EntryPrice,
+			StopPrice:   position.CurrentStop,
+			RiskDollars: position.RiskDollars,
+			InitialRisk: position.InitialRisk,
+			FilledAt:    position.OpenedAt,
+		}
+		if position.Status == "OPEN" {
+			lot.RemainingShares = position.Shares
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, nil
+}
+
+// tracksLots reports whether a position's shares are held in lots; option
+// positions carry their contracts in legs_json and hold no shares
+func tracksLots(p *Position) bool {
+	return p.InstrumentType != InstrumentOption
+}
+
+// openLots filters lots down to those with shares still open
+func openLots(lots []PositionLot) []PositionLot {
+	open := []PositionLot{}
+	for _, lot := range lots {
+		if lot.RemainingShares > 0 {
+			open = append(open, lot)
+		}
+	}
+	return open
+}
+
+// storeLegacyLot stores the synthesized unit 1 of a position opened before
+// lots were tracked, so later updates have a row to work on
+func storeLegacyLot(tx *sql.Tx, lots []PositionLot) ([]PositionLot, error) {
+	if len(lots) != 1 || lots[0].ID != 0 {
+		return lots, nil
+	}
+	if err := insertLot(tx, lots[0]); err != nil {
+		return nil, err
+	}
+	var id int
+	if err := tx.QueryRow(`SELECT last_insert_rowid()`).Scan(&id); err != nil {
+		return nil, fmt.Errorf("failed to read lot id: %w", err)
+	}
+	lots[0].ID = id
+	return lots, nil
+}
+
+// lotTotals are the position figures derived from its lots
+type lotTotals struct {
+	Shares      int
+	EntryPrice  float64
+	RiskDollars float64
+	Units       int
+	RealizedPnL float64
+}
+
+// syncPositionFromLots recomputes a position's shares, average entry,
+// risk_dollars, current_units and realized P&L from its lots
+//
+// While shares remain open the figures cover the remaining lots only; once
+// every lot is closed shares and entry revert to the totals of all fills so
+// the closed position still shows what was traded.
+func syncPositionFromLots(tx *sql.Tx, positionID int) (*lotTotals, error) {
+	var remaining, filled, units int
+	var remainingCost, filledCost, risk, realized, commissions float64
+	err := tx.QueryRow(`
+		SELECT
+			COALESCE(SUM(remaining_shares), 0),
+			COALESCE(SUM(remaining_shares * entry_price), 0),
+			COALESCE(SUM(shares), 0),
+			COALESCE(SUM(shares * entry_price), 0),
+			COALESCE(SUM(CASE WHEN remaining_shares > 0 THEN MAX(0, risk_dollars) ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN remaining_shares > 0 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(realized_pnl), 0),
+			(SELECT COALESCE(commissions, 0) FROM positions WHERE id = ?)
+		FROM position_lots
+		WHERE position_id = ?
+	`, positionID, positionID).Scan(&remaining, &remainingCost, &filled, &filledCost, &risk, &units, &realized, &commissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total lots: %w", err)
+	}
+	realized -= commissions // pnl is net of every commission paid so far
+	if filled == 0 {
+		return nil, fmt.Errorf("position %d has no lots", positionID)
+	}
+
+	totals := &lotTotals{Shares: remaining, RiskDollars: risk, Units: units, RealizedPnL: realized}
+	if remaining > 0 {
+		totals.EntryPrice = remainingCost / float64(remaining)
+	} else {
+		totals.Shares = filled
+		totals.EntryPrice = filledCost / float64(filled)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE positions
+		SET shares = ?, entry_price = ?, risk_dollars = ?, current_units = ?, pnl = ?
+		WHERE id = ?
+	`, totals.Shares, totals.EntryPrice, totals.RiskDollars, totals.Units, totals.RealizedPnL, positionID); err != nil {
+		return nil, fmt.Errorf("failed to update position from lots: %w", err)
+	}
+
+	return totals, nil
+}
+
+// setLotStops moves the stop of every open lot (and the position) to stop,
+// recomputes each lot's risk on its remaining shares and dates the move
+//
+// A lot whose stop is at or past its entry carries no risk: the profit it
+// has locked in never offsets another lot's or position's heat.
+func setLotStops(tx *sql.Tx, positionID int, direction string, stop float64) error {
+	if _, err := tx.Exec(`
+		UPDATE position_lots
+		SET stop_price = ?, risk_dollars = MAX(0, remaining_shares * ? * (entry_price - ?))
+		WHERE position_id = ? AND remaining_shares > 0
+	`, stop, directionSign(direction), stop, positionID); err != nil {
+		return fmt.Errorf("failed to move lot stops: %w", err)
+	}
+	stopDate := time.Now().Format("2006-01-02")
+	if _, err := tx.Exec(`UPDATE positions SET current_stop = ?, stop_date = ? WHERE id = ?`, stop, stopDate, positionID); err != nil {
+		return fmt.Errorf("failed to update stop: %w", err)
+	}
+	return nil
+}
+
+// AllocateExit splits an exit of `shares` across open lots
+//
+//   - FIFO takes from the oldest unit first, LIFO from the newest
+//   - AVERAGE takes from every lot in proportion to its remaining shares
+//     (rounding leftovers go to the oldest lots) and books P&L against the
+//     average cost of all open shares
+//
+// lots must be in unit order and contain only open lots. P&L is booked for
+// the position's direction: a short profits when exitPrice is below cost.
+func AllocateExit(lots []PositionLot, shares int, exitPrice float64, method CostMethod, direction string) ([]LotExit, error) {
+	total := 0
+	cost := 0.0
+	for _, lot := range lots {
+		total += lot.RemainingShares
+		cost += float64(lot.RemainingShares) * lot.EntryPrice
+	}
+	if shares <= 0 {
+		return nil, fmt.Errorf("shares to close must be positive, got %d", shares)
+	}
+	if shares > total {
+		return nil, fmt.Errorf("cannot close %d shares, only %d open", shares, total)
+	}
+
+	take := make([]int, len(lots))
+	switch method {
+	case CostMethodFIFO, CostMethodLIFO:
+		left := shares
+		for n := range lots {
+			i := n
+			if method == CostMethodLIFO {
+				i = len(lots) - 1 - n
+			}
+			take[i] = min(left, lots[i].RemainingShares)
+			left -= take[i]
+		}
+	case CostMethodAverage:
+		left := shares
+		for i, lot := range lots {
+			take[i] = shares * lot.RemainingShares / total
+			left -= take[i]
+		}
+		for i := 0; left > 0; i++ {
+			if take[i] < lots[i].RemainingShares {
+				take[i]++
+				left--
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid cost method %q", method)
+	}
+
+	avgCost := cost / float64(total)
+	exits := []LotExit{}
+	for i, lot := range lots {
+		if take[i] == 0 {
+			continue
+		}
+		basis := lot.EntryPrice
+		if method == CostMethodAverage {
+			basis = avgCost
+		}
+		exits = append(exits, LotExit{
+			LotID:      lot.ID,
+			UnitNum:    lot.UnitNum,
+			Shares:     take[i],
+			CostBasis:  basis,
+			RealizedPL: float64(take[i]) * directionSign(direction) * (exitPrice - basis),
+		})
+	}
+	return exits, nil
+}
+
+// closeLots applies an exit to the lots and re-syncs the position
+func closeLots(tx *sql.Tx, position *Position, lots []PositionLot, shares int, exitPrice float64, method CostMethod, now time.Time) (*LotCloseResult, error) {
+	exits, err := AllocateExit(openLots(lots), shares, exitPrice, method, position.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LotCloseResult{
+		PositionID: position.ID,
+		Ticker:     position.Ticker,
+		Method:     method,
+		Shares:     shares,
+		ExitPrice:  exitPrice,
+		Lots:       exits,
+	}
+
+	sign := directionSign(position.Direction)
+
+	// 1R per share of each lot: its own initial risk, or for lots recorded
+	// before that was kept, the distance from its entry to the initial stop
+	riskPerShare := make(map[int]float64, len(lots))
+	for _, lot := range lots {
+		if lot.InitialRisk > 0 && lot.Shares > 0 {
+			riskPerShare[lot.ID] = lot.InitialRisk / float64(lot.Shares)
+		} else {
+			riskPerShare[lot.ID] = sign * (lot.EntryPrice - position.InitialStop)
+		}
+	}
+
+	cost := 0.0
+	for _, exit := range exits {
+		if _, err := tx.Exec(`
+			UPDATE position_lots
+			SET remaining_shares = remaining_shares - ?,
+			    realized_pnl = COALESCE(realized_pnl, 0) + ?,
+			    risk_dollars = MAX(0, (remaining_shares - ?) * ? * (entry_price - stop_price)),
+			    closed_at = CASE WHEN remaining_shares - ? = 0 THEN ? ELSE closed_at END
+			WHERE id = ?
+		`, exit.Shares, exit.RealizedPL, exit.Shares, sign, exit.Shares, now, exit.LotID); err != nil {
+			return nil, fmt.Errorf("failed to close lot %d: %w", exit.UnitNum, err)
+		}
+		cost += float64(exit.Shares) * exit.CostBasis
+		result.RealizedPnL += exit.RealizedPL
+		result.InitialRisk += float64(exit.Shares) * riskPerShare[exit.LotID]
+	}
+	result.CostBasis = cost / float64(shares)
+
+	totals, err := syncPositionFromLots(tx, position.ID)
+	if err != nil {
+		return nil, err
+	}
+	if totals.Units > 0 {
+		result.RemainingShares = totals.Shares
+		result.RemainingRisk = totals.RiskDollars
+	}
+
+	return result, nil
+}
+
+// AddUnit records a pyramid add-on for an open position
+//
+// In one transaction it:
+//   - stores the fill as a new lot (unit N+1)
+//   - moves every open lot's stop to fill.NewStop and recomputes its risk
+//   - updates the position's shares, average entry, current_stop,
+//     risk_dollars and current_units from the lots
+//   - adds the new unit's risk at its fill to initial_risk
+//
+// The caller is responsible for the add-level and heat checks (see
+// domain.PlanAddUnit and domain.CheckAddUnitHeat). AddUnit still refuses
+// to exceed max_units or to loosen the stop (lower it for a long, raise it
+// for a short).
+func (db *DB) AddUnit(positionID int, fill UnitFill) (*Position, error) {
+	position, err := db.GetPosition(positionID)
+	if err != nil {
+		return nil, err
+	}
+	if position.Status != "OPEN" {
+		return nil, fmt.Errorf("position %d is %s, can only add to OPEN positions", positionID, position.Status)
+	}
+	if !tracksLots(position) {
+		return nil, fmt.Errorf("position %d is an option position, units are added to stock positions", positionID)
+	}
+	if fill.Price <= 0 || fill.Shares <= 0 {
+		return nil, fmt.Errorf("add-on needs a positive price and share count")
+	}
+	if stopLoosens(position.Direction, position.CurrentStop, fill.NewStop) {
+		return nil, fmt.Errorf("cannot loosen the %s stop when adding a unit (current: %.2f, new: %.2f)",
+			strings.ToLower(position.Direction), position.CurrentStop, fill.NewStop)
+	}
+
+	lots, err := db.GetPositionLots(positionID)
+	if err != nil {
+		return nil, err
+	}
+	maxUnits := position.MaxUnits
+	if maxUnits <= 0 {
+		maxUnits = 4
+	}
+	if open := len(openLots(lots)); open >= maxUnits {
+		return nil, fmt.Errorf("position already holds %d of %d units", open, maxUnits)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if lots, err = storeLegacyLot(tx, lots); err != nil {
+		return nil, err
+	}
+
+	filledAt := fill.FilledAt
+	if filledAt.IsZero() {
+		filledAt = time.Now()
+	}
+	// The add-on's 1R is its distance to the stop it was filled with
+	addRisk := float64(fill.Shares) * directionSign(position.Direction) * (fill.Price - fill.NewStop)
+	if err := insertLot(tx, PositionLot{
+		PositionID:  positionID,
+		UnitNum:     lots[len(lots)-1].UnitNum + 1,
+		Shares:      fill.Shares,
+		EntryPrice:  fill.Price,
+		StopPrice:   fill.NewStop,
+		InitialRisk: addRisk,
+		FilledAt:    filledAt,
+	}); err != nil {
+		return nil, err
+	}
+	if fill.Commission != 0 {
+		if _, err := tx.Exec(`UPDATE positions SET commissions = COALESCE(commissions, 0) + ? WHERE id = ?`,
+			fill.Commission, positionID); err != nil {
+			return nil, fmt.Errorf("failed to add commission: %w", err)
+		}
+	}
+
+	// Move every unit to the common stop
+	if err := setLotStops(tx, positionID, position.Direction, fill.NewStop); err != nil {
+		return nil, err
+	}
+
+	totals, err := syncPositionFromLots(tx, positionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`UPDATE positions SET initial_risk = COALESCE(initial_risk, 0) + ? WHERE id = ?`,
+		addRisk, positionID); err != nil {
+		return nil, fmt.Errorf("failed to update initial risk: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit add-on: %w", err)
+	}
+
+	position.Shares = totals.Shares
+	position.EntryPrice = totals.EntryPrice
+	position.CurrentStop = fill.NewStop
+	position.RiskDollars = totals.RiskDollars
+	position.CurrentUnits = totals.Units
+	position.PnL = totals.RealizedPnL
+	position.InitialRisk += addRisk
+	position.Commissions += fill.Commission
+	return position, nil
+}
@@ -0,0 +1,459 @@
+package storage
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestPosition opens a 25-share AAPL position at $180 with a $177 stop (N = 1.5)
+func openTestPosition(t *testing.T, db *DB) *Position {
+	t.Helper()
+
+	_, err := db.SaveDecision(Decision{
+		Date:         time.Now().Format("2006-01-02"),
+		Ticker:       "AAPL",
+		Action:       "GO",
+		Entry:        180.0,
+		ATR:          1.5,
+		StopDistance: 3.0,
+		InitialStop:  177.0,
+		Shares:       25,
+		RiskDollars:  75.0,
+		Banner:       "GREEN",
+		Method:       "stock",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+
+	position, err := db.OpenPosition("AAPL")
+	if err != nil {
+		t.Fatalf("Failed to open position: %v", err)
+	}
+	return position
+}
+
+func TestOpenPositionRecordsFirstLot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTestPosition(t, db)
+
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		t.Fatalf("GetPositionLots failed: %v", err)
+	}
+	if len(lots) != 1 || lots[0].ID == 0 {
+		t.Fatalf("Expected one stored lot, got %+v", lots)
+	}
+	if lots[0].UnitNum != 1 || lots[0].Shares != 25 || lots[0].EntryPrice != 180.0 {
+		t.Errorf("Unexpected unit 1: %+v", lots[0])
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.ATR != 1.5 || stored.CurrentUnits != 1 || stored.MaxUnits != 4 {
+		t.Errorf("Expected N 1.5, 1 of 4 units, got N %.2f, %d of %d", stored.ATR, stored.CurrentUnits, stored.MaxUnits)
+	}
+}
+
+func TestOpenPositionRollsBackWithoutLot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.conn.Exec(`DROP TABLE position_lots`); err != nil {
+		t.Fatalf("Failed to drop position_lots: %v", err)
+	}
+	_, err := db.SaveDecision(Decision{
+		Date: time.Now().Format("2006-01-02"), Ticker: "AAPL", Action: "GO",
+		Entry: 180.0, InitialStop: 177.0, Shares: 25, RiskDollars: 75.0, Banner: "GREEN",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+
+	if _, err := db.OpenPosition("AAPL"); err == nil {
+		t.Fatal("Expected OpenPosition to fail when unit 1 cannot be stored")
+	}
+	positions, err := db.GetAllPositions("")
+	if err != nil {
+		t.Fatalf("GetAllPositions failed: %v", err)
+	}
+	if len(positions) != 0 {
+		t.Errorf("Expected no position without its lot, got %d", len(positions))
+	}
+}
+
+func TestAddUnit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTestPosition(t, db)
+
+	// Unit 2 at 180.75 (+0.5N); stop raised to 180.75 - 2N = 177.75
+	updated, err := db.AddUnit(position.ID, UnitFill{Price: 180.75, Shares: 25, NewStop: 177.75})
+	if err != nil {
+		t.Fatalf("AddUnit failed: %v", err)
+	}
+
+	if updated.Shares != 50 || updated.CurrentUnits != 2 {
+		t.Errorf("Expected 50 shares in 2 units, got %d in %d", updated.Shares, updated.CurrentUnits)
+	}
+	if math.Abs(updated.EntryPrice-180.375) > 1e-9 {
+		t.Errorf("Expected average entry 180.375, got %.4f", updated.EntryPrice)
+	}
+	// 25 × (180 - 177.75) + 25 × (180.75 - 177.75) = 56.25 + 75
+	if math.Abs(updated.RiskDollars-131.25) > 1e-9 {
+		t.Errorf("Expected risk 131.25, got %.2f", updated.RiskDollars)
+	}
+	// 1R adds unit 2 at its fill: 75 + 25 × (180.75 - 177.75)
+	if math.Abs(updated.InitialRisk-150) > 1e-9 {
+		t.Errorf("Expected initial risk 150, got %.2f", updated.InitialRisk)
+	}
+
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		t.Fatalf("GetPositionLots failed: %v", err)
+	}
+	if len(lots) != 2 {
+		t.Fatalf("Expected 2 lots, got %d", len(lots))
+	}
+	for _, lot := range lots {
+		if lot.StopPrice != 177.75 {
+			t.Errorf("Unit %d stop not raised: %.2f", lot.UnitNum, lot.StopPrice)
+		}
+	}
+	if math.Abs(lots[0].RiskDollars-56.25) > 1e-9 {
+		t.Errorf("Expected unit 1 risk 56.25, got %.2f", lots[0].RiskDollars)
+	}
+
+	stored, err := db.GetPositionByTicker("AAPL")
+	if err != nil {
+		t.Fatalf("GetPositionByTicker failed: %v", err)
+	}
+	if stored.CurrentStop != 177.75 || stored.Shares != 50 {
+		t.Errorf("Position row not updated: stop %.2f, shares %d", stored.CurrentStop, stored.Shares)
+	}
+}
+
+func TestAddUnitRejectsLowerStopAndMaxUnits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTestPosition(t, db)
+
+	if _, err := db.AddUnit(position.ID, UnitFill{Price: 181, Shares: 25, NewStop: 170}); err == nil {
+		t.Error("Expected error when the add-on would lower the stop")
+	}
+
+	for i, price := range []float64{180.75, 181.5, 182.25} {
+		if _, err := db.AddUnit(position.ID, UnitFill{Price: price, Shares: 25, NewStop: price - 3}); err != nil {
+			t.Fatalf("Add-on %d failed: %v", i+2, err)
+		}
+	}
+
+	if _, err := db.AddUnit(position.ID, UnitFill{Price: 183, Shares: 25, NewStop: 180}); err == nil {
+		t.Error("Expected error when adding a fifth unit")
+	}
+}
+
+// openTwoUnitPosition holds 25 @ $180 and 25 @ $182, both stopped at $179
+func openTwoUnitPosition(t *testing.T, db *DB) *Position {
+	t.Helper()
+
+	position := openTestPosition(t, db)
+	if _, err := db.AddUnit(position.ID, UnitFill{Price: 182, Shares: 25, NewStop: 179}); err != nil {
+		t.Fatalf("AddUnit failed: %v", err)
+	}
+	return position
+}
+
+func TestPartialClose_FIFO(t *testing.T) {
+	// 25 × (185 - 180) + 5 × (185 - 182); 20 left @ 182 with 3/share at risk
+	checkPartialClose(t, CostMethodFIFO, 140, 182, 60, []int{0, 20})
+}
+
+func TestPartialClose_LIFO(t *testing.T) {
+	// 25 × (185 - 182) + 5 × (185 - 180); 20 left @ 180 with 1/share at risk
+	checkPartialClose(t, CostMethodLIFO, 100, 180, 20, []int{20, 0})
+}
+
+func TestPartialClose_Average(t *testing.T) {
+	// 30 × (185 - 181); 10 left in each unit
+	checkPartialClose(t, CostMethodAverage, 120, 181, 40, []int{10, 10})
+}
+
+// checkPartialClose sells 30 of the two-unit position's 50 shares at $185
+func checkPartialClose(t *testing.T, method CostMethod, wantPnL, wantEntry, wantRisk float64, wantRemaining []int) {
+	t.Helper()
+
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTwoUnitPosition(t, db)
+
+	result, err := db.PartialClose("AAPL", 30, 185, method)
+	if err != nil {
+		t.Fatalf("PartialClose failed: %v", err)
+	}
+	if math.Abs(result.RealizedPnL-wantPnL) > 1e-9 {
+		t.Errorf("Expected realized P&L %.2f, got %.2f", wantPnL, result.RealizedPnL)
+	}
+	if result.RemainingShares != 20 || math.Abs(result.RemainingRisk-wantRisk) > 1e-9 {
+		t.Errorf("Expected 20 shares with risk %.2f left, got %d with %.2f",
+			wantRisk, result.RemainingShares, result.RemainingRisk)
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.Status != "OPEN" || stored.Shares != 20 {
+		t.Errorf("Expected OPEN with 20 shares, got %s with %d", stored.Status, stored.Shares)
+	}
+	if math.Abs(stored.EntryPrice-wantEntry) > 1e-9 {
+		t.Errorf("Expected remaining entry %.2f, got %.4f", wantEntry, stored.EntryPrice)
+	}
+	if math.Abs(stored.RiskDollars-wantRisk) > 1e-9 || math.Abs(stored.PnL-wantPnL) > 1e-9 {
+		t.Errorf("Expected risk %.2f and P&L %.2f, got %.2f and %.2f",
+			wantRisk, wantPnL, stored.RiskDollars, stored.PnL)
+	}
+
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		t.Fatalf("GetPositionLots failed: %v", err)
+	}
+	for i, lot := range lots {
+		if lot.RemainingShares != wantRemaining[i] {
+			t.Errorf("Unit %d: expected %d remaining, got %d", lot.UnitNum, wantRemaining[i], lot.RemainingShares)
+		}
+	}
+
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	if math.Abs(heat-wantRisk) > 1e-9 {
+		t.Errorf("Expected heat %.2f from remaining lots, got %.2f", wantRisk, heat)
+	}
+}
+
+func TestClosePositionAfterPartialClose(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTwoUnitPosition(t, db)
+
+	if _, err := db.PartialClose("AAPL", 30, 185, CostMethodFIFO); err != nil {
+		t.Fatalf("PartialClose failed: %v", err)
+	}
+	if err := db.ClosePosition("AAPL", 186, "WIN"); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	// 140 from the partial close + 20 × (186 - 182)
+	if math.Abs(stored.PnL-220) > 1e-9 {
+		t.Errorf("Expected total P&L 220, got %.2f", stored.PnL)
+	}
+	if stored.Status != "CLOSED" || stored.Shares != 50 || stored.EntryPrice != 181 || stored.RiskDollars != 0 {
+		t.Errorf("Unexpected closed position: %+v", stored)
+	}
+}
+
+func TestPartialCloseInitialRiskFromLots(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	openTwoUnitPosition(t, db)
+
+	// Unit 2 was filled at 182 with a 179 stop, not the position's 177 entry stop:
+	// 25 × (182 - 179) + 5 × (180 - 177)
+	result, err := db.PartialClose("AAPL", 30, 185, CostMethodLIFO)
+	if err != nil {
+		t.Fatalf("PartialClose failed: %v", err)
+	}
+	if math.Abs(result.InitialRisk-90) > 1e-9 {
+		t.Errorf("Expected 1R of 90 on the shares sold, got %.2f", result.InitialRisk)
+	}
+}
+
+func TestUpdateStopRecomputesLotRisk(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTwoUnitPosition(t, db)
+
+	if err := db.UpdateStop("AAPL", 180); err != nil {
+		t.Fatalf("UpdateStop failed: %v", err)
+	}
+
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		t.Fatalf("GetPositionLots failed: %v", err)
+	}
+	if lots[0].RiskDollars != 0 || lots[1].RiskDollars != 50 {
+		t.Errorf("Expected lot risks 0 and 50, got %.2f and %.2f", lots[0].RiskDollars, lots[1].RiskDollars)
+	}
+
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.CurrentStop != 180 || stored.RiskDollars != 50 {
+		t.Errorf("Expected stop 180 and risk 50, got %.2f and %.2f", stored.CurrentStop, stored.RiskDollars)
+	}
+	// 75 at entry + 25 × (182 - 179) for unit 2; moving the stop keeps 1R
+	if stored.InitialRisk != 150 {
+		t.Errorf("Expected initial risk 150 after the stop moved, got %.2f", stored.InitialRisk)
+	}
+}
+
+func TestStopPastEntryAddsNoNegativeHeat(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	position := openTestPosition(t, db)
+	_, err := db.SaveDecision(Decision{
+		Date: time.Now().Format("2006-01-02"), Ticker: "MSFT", Action: "GO",
+		Entry: 400.0, InitialStop: 392.0, Shares: 10, RiskDollars: 80.0, Banner: "GREEN",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+	if _, err := db.OpenPosition("MSFT"); err != nil {
+		t.Fatalf("Failed to open MSFT: %v", err)
+	}
+
+	// AAPL's stop locks in 25 × (185 - 180): it adds nothing, and hides none of MSFT's 80
+	if err := db.UpdateStop("AAPL", 185); err != nil {
+		t.Fatalf("UpdateStop failed: %v", err)
+	}
+
+	lots, err := db.GetPositionLots(position.ID)
+	if err != nil {
+		t.Fatalf("GetPositionLots failed: %v", err)
+	}
+	if lots[0].RiskDollars != 0 {
+		t.Errorf("Expected no lot risk past entry, got %.2f", lots[0].RiskDollars)
+	}
+	stored, err := db.GetPosition(position.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.RiskDollars != 0 {
+		t.Errorf("Expected no position risk past entry, got %.2f", stored.RiskDollars)
+	}
+
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	bucketHeat, err := db.CalculateBucketHeat(stored.Bucket)
+	if err != nil {
+		t.Fatalf("CalculateBucketHeat failed: %v", err)
+	}
+	if heat != 80 || bucketHeat != 80 {
+		t.Errorf("Expected portfolio and bucket heat 80, got %.2f and %.2f", heat, bucketHeat)
+	}
+}
+
+// Option positions hold no shares: stops skip the lots, and closes go through roll or expire
+func TestOptionPositionStopAndClose(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	spread := openTestPutSpread(t, db)
+
+	var lots int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM position_lots WHERE position_id = ?`, spread.ID).Scan(&lots); err != nil {
+		t.Fatalf("Failed to count lots: %v", err)
+	}
+	if lots != 0 {
+		t.Errorf("Expected no lots on an option position, got %d", lots)
+	}
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	if heat != 380 {
+		t.Errorf("Expected heat of the 380 max loss, got %.2f", heat)
+	}
+
+	if err := db.UpdateStop("AAPL", 95); err != nil {
+		t.Fatalf("UpdateStop failed: %v", err)
+	}
+	moved, err := db.GetPosition(spread.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if moved.CurrentStop != 95 || moved.RiskDollars != 380 {
+		t.Errorf("Expected stop 95 with the 380 max loss kept, got %.2f and %.2f", moved.CurrentStop, moved.RiskDollars)
+	}
+
+	if _, err := db.AddUnit(spread.ID, UnitFill{Price: 101, Shares: 1, NewStop: 96}); err == nil {
+		t.Error("Expected an error adding a unit to an option position")
+	}
+
+	if _, err := db.PartialClose("AAPL", 1, 1.0, CostMethodFIFO); err == nil {
+		t.Error("Expected an error scaling out of an option position")
+	}
+	if _, err := db.ExitPositionFill(spread.ID, 1, 1.0, 0, time.Now()); err == nil {
+		t.Error("Expected an error exiting an option position at a fill")
+	}
+
+	// A close without the legs' prices would leave no P&L or trade_history row
+	if err := db.ClosePosition("AAPL", 1.0, OutcomeWin); err == nil || !strings.Contains(err.Error(), "roll or expire") {
+		t.Fatalf("Expected ClosePosition to send the option position to roll or expire, got %v", err)
+	}
+	open, err := db.GetPosition(spread.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if open.Status != "OPEN" || open.PnL != 0 || open.RiskDollars != 380 {
+		t.Errorf("Expected the spread still OPEN with no P&L and 380 at risk, got %s %.2f %.2f", open.Status, open.PnL, open.RiskDollars)
+	}
+	history, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected no closed trade_history row, got %d", len(history))
+	}
+}
+
+func TestAllocateExitAverageRounding(t *testing.T) {
+	lots := []PositionLot{
+		{ID: 1, UnitNum: 1, RemainingShares: 10, EntryPrice: 100},
+		{ID: 2, UnitNum: 2, RemainingShares: 10, EntryPrice: 102},
+		{ID: 3, UnitNum: 3, RemainingShares: 10, EntryPrice: 104},
+	}
+
+	exits, err := AllocateExit(lots, 10, 110, CostMethodAverage, DirectionLong)
+	if err != nil {
+		t.Fatalf("AllocateExit failed: %v", err)
+	}
+
+	total, pnl := 0, 0.0
+	for _, exit := range exits {
+		total += exit.Shares
+		pnl += exit.RealizedPL
+	}
+	if total != 10 || len(exits) != 3 || exits[0].Shares != 4 {
+		t.Errorf("Expected 4/3/3 split, got %+v", exits)
+	}
+	if math.Abs(pnl-80) > 1e-9 { // 10 × (110 - 102)
+		t.Errorf("Expected P&L 80 at average cost, got %.2f", pnl)
+	}
+
+	if _, err := AllocateExit(lots, 31, 110, CostMethodFIFO, DirectionLong); err == nil {
+		t.Error("Expected error when closing more shares than are open")
+	}
+}
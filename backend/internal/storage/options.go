@@ -1,16 +1,15 @@
 package storage
 
 import (
-	"fmt"
-	"math"
+	"github.com/yourusername/trading-engine/internal/options"
 )
 
 // OptionLeg represents a single leg in a multi-leg options strategy
 type OptionLeg struct {
-	Type   string  `json:"type"`   // CALL or PUT
+	Type   string  `json:"type"`   // CALL, PUT or STOCK (shares held with the options)
 	Strike float64 `json:"strike"` // Strike price
 	Exp    string  `json:"exp"`    // Expiration date (YYYY-MM-DD)
-	Qty    int     `json:"qty"`    // Number of contracts
+	Qty    int     `json:"qty"`    // Number of contracts (shares for STOCK)
 	Action string  `json:"action"` // BUY or SELL
 	Price  float64 `json:"price"`  // Price per contract (optional, for record keeping)
 }
@@ -67,6 +66,26 @@ const (
 	SystemCustom = "CUSTOM"   // Manual parameters
 )
 
+// BreakoutSystemForLookback names the breakout system of an entry lookback
+// (20 = SYSTEM_1, 55 = SYSTEM_2, any other positive lookback = CUSTOM)
+func BreakoutSystemForLookback(lookback int) string {
+	switch {
+	case lookback == 20:
+		return SystemOne
+	case lookback == 55:
+		return SystemTwo
+	case lookback > 0:
+		return SystemCustom
+	}
+	return ""
+}
+
+// DefaultExitLookback is the 10-bar Donchian exit shared by both systems
+const DefaultExitLookback = 10
+
+// DefaultRollThresholdDTE is the DTE at which an option position's time exit applies
+const DefaultRollThresholdDTE = 21
+
 // Time exit mode constants
 const (
 	TimeExitNone  = "None"  // Do not exit on time
@@ -518,185 +537,76 @@ func BuildButterfly(optionType string, lowerStrike, middleStrike, upperStrike fl
 	}
 }
 
+// BuildCoveredCall creates a covered call: 100 shares per contract plus a short call
+func BuildCoveredCall(stockPrice, strike float64, expiration string, contracts int, premium float64) []OptionLeg {
+	return []OptionLeg{
+		{
+			Type:   InstrumentStock,
+			Qty:    contracts * 100,
+			Action: "BUY",
+			Price:  stockPrice,
+		},
+		{
+			Type:   "CALL",
+			Strike: strike,
+			Exp:    expiration,
+			Qty:    contracts,
+			Action: "SELL",
+			Price:  premium,
+		},
+	}
+}
+
+// BuildRatioBackspread creates a 1×2 backspread (sell 1 near-the-money, buy 2 further out)
+func BuildRatioBackspread(optionType string, shortStrike, longStrike float64, expiration string, contracts int,
+	shortPremium, longPremium float64) []OptionLeg {
+
+	return []OptionLeg{
+		{
+			Type:   optionType,
+			Strike: shortStrike,
+			Exp:    expiration,
+			Qty:    contracts,
+			Action: "SELL",
+			Price:  shortPremium,
+		},
+		{
+			Type:   optionType,
+			Strike: longStrike,
+			Exp:    expiration,
+			Qty:    contracts * 2, // Buy 2x further strike
+			Action: "BUY",
+			Price:  longPremium,
+		},
+	}
+}
+
 // ========================================
 // Calculation Functions
 // ========================================
 
 // CalculateNetDebit computes net debit/credit from legs (negative = credit received)
 func CalculateNetDebit(legs []OptionLeg) float64 {
-	netDebit := 0.0
-	for _, leg := range legs {
-		if leg.Action == "BUY" {
-			netDebit += leg.Price * float64(leg.Qty) * 100 // Options are per 100 shares
-		} else if leg.Action == "SELL" {
-			netDebit -= leg.Price * float64(leg.Qty) * 100
-		}
-	}
-	return netDebit
+	return options.NetDebit(toPricingLegs(legs))
 }
 
-// CalculateMaxProfitLoss computes max profit and max loss for common strategies
-func CalculateMaxProfitLoss(strategyType string, legs []OptionLeg) (maxProfit, maxLoss float64, err error) {
-	netDebit := CalculateNetDebit(legs)
-
-	switch strategyType {
-	case StrategyLongCall, StrategyLongPut:
-		// Long single option: max loss = debit paid, max profit = unlimited (use large number)
-		return 999999.99, netDebit, nil
-
-	case StrategyBullCallSpread, StrategyBearPutSpread:
-		// Vertical debit spread: max profit = spread width - debit, max loss = debit
-		if len(legs) != 2 {
-			return 0, 0, fmt.Errorf("vertical spread requires exactly 2 legs, got %d", len(legs))
-		}
-		spreadWidth := math.Abs(legs[0].Strike-legs[1].Strike) * 100
-		maxProfit := spreadWidth - netDebit
-		maxLoss := netDebit
-		return maxProfit, maxLoss, nil
-
-	case StrategyBullPutSpread, StrategyBearCallSpread:
-		// Vertical credit spread: max profit = credit received, max loss = spread width - credit
-		if len(legs) != 2 {
-			return 0, 0, fmt.Errorf("vertical spread requires exactly 2 legs, got %d", len(legs))
-		}
-		spreadWidth := math.Abs(legs[0].Strike-legs[1].Strike) * 100
-		maxProfit := -netDebit // Credit received (netDebit is negative)
-		maxLoss := spreadWidth - maxProfit
-		return maxProfit, maxLoss, nil
-
-	case StrategyIronCondor:
-		// Iron Condor: max profit = credit, max loss = wider spread width - credit
-		if len(legs) != 4 {
-			return 0, 0, fmt.Errorf("iron condor requires exactly 4 legs, got %d", len(legs))
-		}
-		// Find the width of the wider spread
-		putSpreadWidth := math.Abs(legs[0].Strike-legs[1].Strike) * 100
-		callSpreadWidth := math.Abs(legs[2].Strike-legs[3].Strike) * 100
-		widerSpread := math.Max(putSpreadWidth, callSpreadWidth)
-		maxProfit := -netDebit
-		maxLoss := widerSpread - maxProfit
-		return maxProfit, maxLoss, nil
-
-	case StrategyIronButterfly:
-		// Iron Butterfly: max profit = credit, max loss = wing width - credit
-		if len(legs) != 4 {
-			return 0, 0, fmt.Errorf("iron butterfly requires exactly 4 legs, got %d", len(legs))
-		}
-		wingWidth := math.Abs(legs[0].Strike-legs[1].Strike) * 100
-		maxProfit := -netDebit
-		maxLoss := wingWidth - maxProfit
-		return maxProfit, maxLoss, nil
-
-	case StrategyStraddle, StrategyStrangle:
-		// Straddle/Strangle: max loss = debit, max profit = unlimited
-		return 999999.99, netDebit, nil
-
-	default:
-		// For other strategies, return conservative estimates
-		return 0, netDebit, fmt.Errorf("max profit/loss calculation not implemented for strategy %s", strategyType)
-	}
+// CalculatePayoff evaluates the legs' P&L at the nearest expiration and
+// returns max profit, max loss (with unlimited flags) and every breakeven.
+// It works from the legs alone, so every strategy is covered; legs of a
+// time spread that expire later are valued with Black-Scholes, using
+// underlying as the price their IV is solved at (0 = at the money).
+func CalculatePayoff(legs []OptionLeg, underlying float64) (*options.Payoff, error) {
+	return options.AnalyzePayoff(toPricingLegs(legs), options.Market{
+		Spot: underlying,
+		Rate: options.DefaultRiskFreeRate,
+	})
 }
 
-// CalculateBreakevens computes breakeven prices for common strategies
-func CalculateBreakevens(strategyType string, legs []OptionLeg) (lower, upper float64, err error) {
-	netDebit := CalculateNetDebit(legs)
-	debitPerContract := netDebit / 100 // Convert to per-share basis
-
-	switch strategyType {
-	case StrategyLongCall:
-		// Breakeven = strike + debit paid
-		be := legs[0].Strike + debitPerContract
-		return 0, be, nil
-
-	case StrategyLongPut:
-		// Breakeven = strike - debit paid
-		be := legs[0].Strike - debitPerContract
-		return be, 0, nil
-
-	case StrategyBullCallSpread:
-		// Breakeven = lower strike + debit paid
-		lowerStrike := math.Min(legs[0].Strike, legs[1].Strike)
-		be := lowerStrike + debitPerContract
-		return 0, be, nil
-
-	case StrategyBearPutSpread:
-		// Breakeven = upper strike - debit paid
-		upperStrike := math.Max(legs[0].Strike, legs[1].Strike)
-		be := upperStrike - debitPerContract
-		return be, 0, nil
-
-	case StrategyBullPutSpread:
-		// Breakeven = higher short strike - credit
-		upperStrike := math.Max(legs[0].Strike, legs[1].Strike)
-		be := upperStrike + debitPerContract // debitPerContract is negative (credit)
-		return be, 0, nil
-
-	case StrategyBearCallSpread:
-		// Breakeven = lower short strike + credit
-		lowerStrike := math.Min(legs[0].Strike, legs[1].Strike)
-		be := lowerStrike + debitPerContract // debitPerContract is negative (credit)
-		return 0, be, nil
-
-	case StrategyIronCondor:
-		// Two breakevens: put side and call side
-		if len(legs) != 4 {
-			return 0, 0, fmt.Errorf("iron condor requires exactly 4 legs")
-		}
-		creditPerShare := -debitPerContract
-		// Find the short strikes
-		var shortPutStrike, shortCallStrike float64
-		for _, leg := range legs {
-			if leg.Action == "SELL" && leg.Type == "PUT" {
-				shortPutStrike = leg.Strike
-			}
-			if leg.Action == "SELL" && leg.Type == "CALL" {
-				shortCallStrike = leg.Strike
-			}
-		}
-		lowerBE := shortPutStrike - creditPerShare
-		upperBE := shortCallStrike + creditPerShare
-		return lowerBE, upperBE, nil
-
-	case StrategyIronButterfly:
-		// Two breakevens around ATM
-		if len(legs) != 4 {
-			return 0, 0, fmt.Errorf("iron butterfly requires exactly 4 legs")
-		}
-		creditPerShare := -debitPerContract
-		// Find ATM strike (should be same for both short options)
-		var atmStrike float64
-		for _, leg := range legs {
-			if leg.Action == "SELL" {
-				atmStrike = leg.Strike
-				break
-			}
-		}
-		lowerBE := atmStrike - creditPerShare
-		upperBE := atmStrike + creditPerShare
-		return lowerBE, upperBE, nil
-
-	case StrategyStraddle:
-		// Two breakevens: strike ± debit
-		strike := legs[0].Strike // ATM strike
-		lowerBE := strike - debitPerContract
-		upperBE := strike + debitPerContract
-		return lowerBE, upperBE, nil
-
-	case StrategyStrangle:
-		// Two breakevens: put strike - debit, call strike + debit
-		var putStrike, callStrike float64
-		for _, leg := range legs {
-			if leg.Type == "PUT" {
-				putStrike = leg.Strike
-			} else if leg.Type == "CALL" {
-				callStrike = leg.Strike
-			}
-		}
-		lowerBE := putStrike - debitPerContract
-		upperBE := callStrike + debitPerContract
-		return lowerBE, upperBE, nil
-
-	default:
-		return 0, 0, fmt.Errorf("breakeven calculation not implemented for strategy %s", strategyType)
+// toPricingLegs converts stored legs to the pricing package's legs
+func toPricingLegs(legs []OptionLeg) []options.Leg {
+	converted := make([]options.Leg, len(legs))
+	for i, leg := range legs {
+		converted[i] = options.Leg(leg)
 	}
+	return converted
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"testing"
+	"time"
 )
 
 // TestBuildLongCall tests the long call builder
@@ -267,105 +268,126 @@ func TestCalculateNetDebit(t *testing.T) {
 	}
 }
 
-// TestCalculateMaxProfitLoss tests max profit/loss calculations
-func TestCalculateMaxProfitLoss(t *testing.T) {
+// TestCalculatePayoff tests max profit/loss and breakevens from the legs
+func TestCalculatePayoff(t *testing.T) {
 	// Test bull call spread
 	// Spread width = $5, Debit = $2.25, Max profit = $2.75, Max loss = $2.25
 	legs := BuildBullCallSpread(180.0, 185.0, "2025-12-19", 1, 3.50, 1.25)
-	maxProfit, maxLoss, err := CalculateMaxProfitLoss(StrategyBullCallSpread, legs)
+	payoff, err := CalculatePayoff(legs, 0)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expectedMaxProfit := 275.0  // ($5 - $2.25) * 100
-	expectedMaxLoss := 225.0    // $2.25 * 100
-
-	if maxProfit != expectedMaxProfit {
-		t.Errorf("Expected max profit $%.2f, got $%.2f", expectedMaxProfit, maxProfit)
+	if payoff.MaxProfit != 275.0 {
+		t.Errorf("Expected max profit $275.00, got $%.2f", payoff.MaxProfit)
+	}
+	if payoff.MaxLoss != 225.0 {
+		t.Errorf("Expected max loss $225.00, got $%.2f", payoff.MaxLoss)
 	}
-	if maxLoss != expectedMaxLoss {
-		t.Errorf("Expected max loss $%.2f, got $%.2f", expectedMaxLoss, maxLoss)
+	lower, upper := payoff.Range()
+	if lower != 0 || upper != 182.25 {
+		t.Errorf("Expected breakeven $182.25 (upper), got %.2f / %.2f", lower, upper)
 	}
 
 	// Test iron condor
+	// Credit = $1.40, Short Put = $165, Short Call = $185
+	// Lower BE = $165 - $1.40 = $163.60, Upper BE = $185 + $1.40 = $186.40
 	legs2 := BuildIronCondor(175.0, 5.0, 5.0, 10.0, "2025-12-19", 1,
 		0.50, 1.20, 1.30, 0.60)
-	maxProfit2, maxLoss2, err2 := CalculateMaxProfitLoss(StrategyIronCondor, legs2)
+	payoff2, err := CalculatePayoff(legs2, 175.0)
 
-	if err2 != nil {
-		t.Fatalf("Unexpected error: %v", err2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expectedMaxProfit2 := 140.0  // Credit received
-	expectedMaxLoss2 := 360.0    // $5 spread - $1.40 credit = $3.60 * 100
-
-	if maxProfit2 != expectedMaxProfit2 {
-		t.Errorf("Expected max profit $%.2f, got $%.2f", expectedMaxProfit2, maxProfit2)
+	if payoff2.MaxProfit != 140.0 {
+		t.Errorf("Expected max profit $140.00, got $%.2f", payoff2.MaxProfit)
 	}
-	if maxLoss2 != expectedMaxLoss2 {
-		t.Errorf("Expected max loss $%.2f, got $%.2f", expectedMaxLoss2, maxLoss2)
+	if payoff2.MaxLoss != 360.0 {
+		t.Errorf("Expected max loss $360.00, got $%.2f", payoff2.MaxLoss)
+	}
+	lower2, upper2 := payoff2.Range()
+	if lower2 != 163.60 || upper2 != 186.40 {
+		t.Errorf("Expected breakevens $163.60 / $186.40, got $%.2f / $%.2f", lower2, upper2)
 	}
-}
 
-// TestCalculateBreakevens tests breakeven calculations
-func TestCalculateBreakevens(t *testing.T) {
-	// Test long call
-	// Strike $180, Premium $3.50, Breakeven = $183.50
-	legs := BuildLongCall(180.0, "2025-12-19", 1, 3.50)
-	lower, upper, err := CalculateBreakevens(StrategyLongCall, legs)
+	// Test straddle
+	// ATM = $175, Total premium = $3.50 + $3.25 = $6.75
+	// Lower BE = $175 - $6.75 = $168.25, Upper BE = $175 + $6.75 = $181.75
+	legs3 := BuildStraddle(175.0, "2025-12-19", 1, 3.50, 3.25)
+	payoff3, err := CalculatePayoff(legs3, 175.0)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expectedBreakeven := 183.50
-	if upper != expectedBreakeven {
-		t.Errorf("Expected breakeven $%.2f, got $%.2f", expectedBreakeven, upper)
+	if !payoff3.UnlimitedProfit || payoff3.UnlimitedLoss {
+		t.Errorf("Straddle should have unlimited profit and limited loss")
 	}
-	if lower != 0 {
-		t.Errorf("Long call should have no lower breakeven, got $%.2f", lower)
+	if payoff3.MaxLoss != 675.0 {
+		t.Errorf("Expected max loss $675.00, got $%.2f", payoff3.MaxLoss)
 	}
-
-	// Test iron condor
-	// Credit = $1.40, Short Put = $165, Short Call = $185
-	// Lower BE = $165 - $1.40 = $163.60, Upper BE = $185 + $1.40 = $186.40
-	legs2 := BuildIronCondor(175.0, 5.0, 5.0, 10.0, "2025-12-19", 1,
-		0.50, 1.20, 1.30, 0.60)
-	lower2, upper2, err2 := CalculateBreakevens(StrategyIronCondor, legs2)
-
-	if err2 != nil {
-		t.Fatalf("Unexpected error: %v", err2)
+	lower3, upper3 := payoff3.Range()
+	if lower3 != 168.25 || upper3 != 181.75 {
+		t.Errorf("Expected breakevens $168.25 / $181.75, got $%.2f / $%.2f", lower3, upper3)
 	}
+}
 
-	expectedLowerBE := 163.60
-	expectedUpperBE := 186.40
-
-	if lower2 != expectedLowerBE {
-		t.Errorf("Expected lower breakeven $%.2f, got $%.2f", expectedLowerBE, lower2)
+// TestCalculatePayoff_OtherStrategies covers strategies the old per-strategy switch did not
+func TestCalculatePayoff_OtherStrategies(t *testing.T) {
+	// Covered call: 100 shares at $100, short $105 call for $2
+	covered, err := CalculatePayoff(BuildCoveredCall(100.0, 105.0, "2025-12-19", 1, 2.0), 100.0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
-	if upper2 != expectedUpperBE {
-		t.Errorf("Expected upper breakeven $%.2f, got $%.2f", expectedUpperBE, upper2)
+	if covered.MaxProfit != 700.0 || covered.MaxLoss != 9800.0 {
+		t.Errorf("Expected covered call max profit $700 / max loss $9800, got $%.2f / $%.2f",
+			covered.MaxProfit, covered.MaxLoss)
+	}
+	if len(covered.Breakevens) != 1 || covered.Breakevens[0] != 98.0 {
+		t.Errorf("Expected covered call breakeven $98, got %v", covered.Breakevens)
 	}
 
-	// Test straddle
-	// ATM = $175, Total premium = $3.50 + $3.25 = $6.75
-	// Lower BE = $175 - $6.75 = $168.25, Upper BE = $175 + $6.75 = $181.75
-	legs3 := BuildStraddle(175.0, "2025-12-19", 1, 3.50, 3.25)
-	lower3, upper3, err3 := CalculateBreakevens(StrategyStraddle, legs3)
-
-	if err3 != nil {
-		t.Fatalf("Unexpected error: %v", err3)
+	// Put ratio backspread: sell 1 $100 put for $5, buy 2 $90 puts for $2
+	// Max loss at $90: $10 - $1 credit = $900
+	backspread, err := CalculatePayoff(BuildRatioBackspread("PUT", 100.0, 90.0, "2025-12-19", 1, 5.0, 2.0), 100.0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if backspread.MaxLoss != 900.0 || backspread.UnlimitedLoss {
+		t.Errorf("Expected backspread max loss $900, got $%.2f", backspread.MaxLoss)
+	}
+	if len(backspread.Breakevens) != 2 || backspread.Breakevens[0] != 81.0 || backspread.Breakevens[1] != 99.0 {
+		t.Errorf("Expected backspread breakevens $81 / $99, got %v", backspread.Breakevens)
 	}
 
-	expectedLowerBE3 := 168.25
-	expectedUpperBE3 := 181.75
+	// Long call butterfly: 170/175/180 for $1.50 debit
+	butterfly, err := CalculatePayoff(BuildButterfly("CALL", 170.0, 175.0, 180.0, "2025-12-19", 1, 7.0, 4.0, 2.5), 175.0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if butterfly.MaxProfit != 350.0 || butterfly.MaxLoss != 150.0 {
+		t.Errorf("Expected butterfly max profit $350 / max loss $150, got $%.2f / $%.2f",
+			butterfly.MaxProfit, butterfly.MaxLoss)
+	}
 
-	if lower3 != expectedLowerBE3 {
-		t.Errorf("Expected lower breakeven $%.2f, got $%.2f", expectedLowerBE3, lower3)
+	// Calendar: the far leg is valued at the near expiration, so the loss is capped near the debit
+	nearExp := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+	farExp := time.Now().AddDate(0, 2, 0).Format("2006-01-02")
+	calendar, err := CalculatePayoff(BuildCalendarSpread("CALL", 175.0, nearExp, farExp, 1, 3.50, 5.00), 175.0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calendar.UnlimitedLoss || calendar.UnlimitedProfit {
+		t.Errorf("Calendar should have limited profit and loss")
+	}
+	if calendar.MaxProfit <= 0 || calendar.MaxLoss > calendar.NetDebit+1 {
+		t.Errorf("Unexpected calendar max profit $%.2f / max loss $%.2f (debit $%.2f)",
+			calendar.MaxProfit, calendar.MaxLoss, calendar.NetDebit)
 	}
-	if upper3 != expectedUpperBE3 {
-		t.Errorf("Expected upper breakeven $%.2f, got $%.2f", expectedUpperBE3, upper3)
+	if len(calendar.Breakevens) != 2 {
+		t.Errorf("Expected 2 calendar breakevens, got %v", calendar.Breakevens)
 	}
 }
 
@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -10,6 +11,7 @@ import (
 type Position struct {
 	ID          int       `json:"id"`
 	Ticker      string    `json:"ticker"`
+	Direction   string    `json:"direction"` // LONG or SHORT
 	EntryPrice  float64   `json:"entry_price"`
 	CurrentStop float64   `json:"current_stop"`
 	InitialStop float64   `json:"initial_stop"`
@@ -41,6 +43,117 @@ type Position struct {
 	MaxUnits              int     `json:"max_units,omitempty"`
 	CurrentUnits          int     `json:"current_units,omitempty"`
 	AddStepN              float64 `json:"add_step_n,omitempty"`
+	ATR                   float64 `json:"atr_n,omitempty"`       // N at entry (pyramid levels and stops)
+	AddPrice1             float64 `json:"add_price_1,omitempty"` // Planned add levels from sizing
+	AddPrice2             float64 `json:"add_price_2,omitempty"`
+	AddPrice3             float64 `json:"add_price_3,omitempty"`
+	ExitLookback          int     `json:"exit_lookback,omitempty"`      // Donchian exit bars (default 10)
+	StopDate              string  `json:"stop_date,omitempty"`          // Day current_stop was last set (empty = entry)
+	RollThresholdDTE      int     `json:"roll_threshold_dte,omitempty"` // DTE to roll/close (default 21)
+	TimeExitMode          string  `json:"time_exit_mode,omitempty"`     // None, Close, Roll
+	CostBasis             float64 `json:"cost_basis,omitempty"`         // Net debit including every roll, or what converted shares cost (negative = credit)
+	RolledFromID          int     `json:"rolled_from_id,omitempty"`     // Position this one replaced in a roll
+	RolledToID            int     `json:"rolled_to_id,omitempty"`       // Replacement opened when this one was rolled
+	ConvertedFromID       int     `json:"converted_from_id,omitempty"`  // Option position whose exercise or assignment opened this one
+	InitialRisk           float64 `json:"initial_risk,omitempty"`       // 1R: risk at entry plus each add-on's risk at its fill (never moved by stops)
+	BreakoutSystem        string  `json:"breakout_system,omitempty"`    // SYSTEM_1, SYSTEM_2, CUSTOM (empty = not recorded)
+	ExcursionLow          float64 `json:"excursion_low,omitempty"`      // Lowest price seen since entry (0 = not tracked)
+	ExcursionHigh         float64 `json:"excursion_high,omitempty"`     // Highest price seen since entry
+	ExcursionDate         string  `json:"excursion_date,omitempty"`     // Last bar or mark applied to the range
+	Commissions           float64 `json:"commissions,omitempty"`        // Broker commissions paid, deducted from pnl
+}
+
+// TradeDate is the position's entry date, or the day it was opened when no
+// entry date was recorded (YYYY-MM-DD)
+func (p *Position) TradeDate() string {
+	if p.EntryDate != "" {
+		return p.EntryDate
+	}
+	return p.OpenedAt.Format("2006-01-02")
+}
+
+// positionColumns is the column list read by scanPosition
+const positionColumns = `
+	id, ticker, direction, entry_price, current_stop, initial_stop,
+	shares, risk_dollars, bucket, status, exit_price, exit_date,
+	outcome, pnl, decision_id, opened_at, closed_at,
+	max_units, current_units, add_step_n, atr_n, add_price_1, add_price_2, add_price_3,
+	instrument_type, entry_date, primary_expiration_date, exit_lookback, stop_date,
+	options_strategy, legs_json, underlying_at_entry,
+	net_debit, roll_threshold_dte, time_exit_mode, cost_basis, rolled_from_id, rolled_to_id, converted_from_id,
+	initial_risk, breakout_system, excursion_low, excursion_high, excursion_date, commissions
+`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPosition scans one row selected with positionColumns
+func scanPosition(row rowScanner) (*Position, error) {
+	var p Position
+	var bucket, exitDate, outcome sql.NullString
+	var instrumentType, entryDate, expirationDate, stopDate, strategy, legsJSON, timeExitMode, breakoutSystem, excursionDate sql.NullString
+	var exitPrice, pnl, addStepN, atr, addPrice1, addPrice2, addPrice3, underlying, netDebit, costBasis, initialRisk sql.NullFloat64
+	var excursionLow, excursionHigh, commissions sql.NullFloat64
+	var maxUnits, currentUnits, exitLookback, rollThreshold, rolledFrom, rolledTo, convertedFrom sql.NullInt64
+	var closedAt sql.NullTime
+
+	err := row.Scan(
+		&p.ID, &p.Ticker, &p.Direction, &p.EntryPrice, &p.CurrentStop, &p.InitialStop,
+		&p.Shares, &p.RiskDollars, &bucket, &p.Status, &exitPrice, &exitDate,
+		&outcome, &pnl, &p.DecisionID, &p.OpenedAt, &closedAt,
+		&maxUnits, &currentUnits, &addStepN, &atr, &addPrice1, &addPrice2, &addPrice3,
+		&instrumentType, &entryDate, &expirationDate, &exitLookback, &stopDate,
+		&strategy, &legsJSON, &underlying,
+		&netDebit, &rollThreshold, &timeExitMode, &costBasis, &rolledFrom, &rolledTo, &convertedFrom,
+		&initialRisk, &breakoutSystem, &excursionLow, &excursionHigh, &excursionDate, &commissions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Bucket = bucket.String
+	p.ExitPrice = exitPrice.Float64
+	p.ExitDate = exitDate.String
+	p.Outcome = outcome.String
+	p.PnL = pnl.Float64
+	if closedAt.Valid {
+		p.ClosedAt = closedAt.Time
+	}
+	p.MaxUnits = int(maxUnits.Int64)
+	p.CurrentUnits = int(currentUnits.Int64)
+	p.AddStepN = addStepN.Float64
+	p.ATR = atr.Float64
+	p.AddPrice1 = addPrice1.Float64
+	p.AddPrice2 = addPrice2.Float64
+	p.AddPrice3 = addPrice3.Float64
+	p.InstrumentType = instrumentType.String
+	p.EntryDate = entryDate.String
+	p.PrimaryExpirationDate = expirationDate.String
+	p.ExitLookback = int(exitLookback.Int64)
+	p.StopDate = stopDate.String
+	p.OptionsStrategy = strategy.String
+	p.LegsJSON = legsJSON.String
+	p.UnderlyingAtEntry = underlying.Float64
+	p.NetDebit = netDebit.Float64
+	p.RollThresholdDTE = int(rollThreshold.Int64)
+	p.TimeExitMode = timeExitMode.String
+	p.CostBasis = netDebit.Float64 // Positions that were never rolled
+	if costBasis.Valid {
+		p.CostBasis = costBasis.Float64
+	}
+	p.RolledFromID = int(rolledFrom.Int64)
+	p.RolledToID = int(rolledTo.Int64)
+	p.ConvertedFromID = int(convertedFrom.Int64)
+	p.InitialRisk = initialRisk.Float64
+	p.BreakoutSystem = breakoutSystem.String
+	p.ExcursionLow = excursionLow.Float64
+	p.ExcursionHigh = excursionHigh.Float64
+	p.ExcursionDate = excursionDate.String
+	p.Commissions = commissions.Float64
+
+	return &p, nil
 }
 
 // OpenPosition creates a new position from a GO decision
@@ -54,13 +167,70 @@ func (db *DB) OpenPosition(ticker string) (*Position, error) {
 		return nil, fmt.Errorf("no decision found for %s", ticker)
 	}
 
+	return db.openPosition(decision, positionEntry{
+		Shares:      decision.Shares,
+		Price:       decision.Entry,
+		Stop:        decision.InitialStop,
+		RiskDollars: decision.RiskDollars,
+		OpenedAt:    time.Now(),
+	})
+}
+
+// OpenPositionFromFill opens the position a GO decision planned at a
+// broker fill: the fill's shares, price, date and commission
+//
+// The stop keeps the decision's stop distance from the fill price (the
+// decision's initial stop when it has none), so a fill away from the
+// planned entry moves the stop with it; the risk is taken at that stop.
+func (db *DB) OpenPositionFromFill(decision *Decision, shares int, price, commission float64, filledAt time.Time) (*Position, error) {
+	if shares <= 0 || price <= 0 {
+		return nil, fmt.Errorf("fill needs a positive price and share count")
+	}
+	direction, err := ParseDirection(decision.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := decision.InitialStop
+	if decision.StopDistance > 0 {
+		stop = price - directionSign(direction)*decision.StopDistance
+	}
+	risk := float64(shares) * directionSign(direction) * (price - stop)
+	if stop <= 0 || risk <= 0 {
+		return nil, fmt.Errorf("stop %.2f is not on the losing side of the %.2f fill", stop, price)
+	}
+
+	return db.openPosition(decision, positionEntry{
+		Shares:      shares,
+		Price:       price,
+		Stop:        stop,
+		RiskDollars: risk,
+		OpenedAt:    filledAt,
+		EntryDate:   filledAt.Format("2006-01-02"),
+		Commission:  commission,
+	})
+}
+
+// positionEntry is the first fill of a position
+type positionEntry struct {
+	Shares      int
+	Price       float64
+	Stop        float64
+	RiskDollars float64
+	OpenedAt    time.Time
+	EntryDate   string // Empty = not recorded (trade date taken from OpenedAt)
+	Commission  float64
+}
+
+// openPosition inserts an OPEN position for a GO decision with its first lot
+func (db *DB) openPosition(decision *Decision, entry positionEntry) (*Position, error) {
+	ticker := decision.Ticker
 	if decision.Action != "GO" {
 		return nil, fmt.Errorf("cannot open position for NO-GO decision")
 	}
 
 	// Get candidate to find bucket
-	today := time.Now().Format("2006-01-02")
-	candidates, err := db.GetCandidatesForDate(today)
+	candidates, err := db.GetCandidatesForDate(decision.Date)
 	if err != nil {
 		return nil, err
 	}
@@ -75,23 +245,43 @@ func (db *DB) OpenPosition(ticker string) (*Position, error) {
 		}
 	}
 
+	// Decisions saved before direction was tracked are longs
+	direction, err := ParseDirection(decision.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	// The position and its first lot are written together
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Create position
 	query := `
 		INSERT INTO positions (
-			ticker, entry_price, current_stop, initial_stop,
-			shares, risk_dollars, bucket, status, decision_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, 'OPEN', ?)
+			ticker, direction, entry_price, current_stop, initial_stop,
+			shares, risk_dollars, bucket, status, decision_id, atr_n, initial_risk,
+			entry_date, commissions, opened_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'OPEN', ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := db.conn.Exec(query,
+	result, err := tx.Exec(query,
 		ticker,
-		decision.Entry,
-		decision.InitialStop,
-		decision.InitialStop,
-		decision.Shares,
-		decision.RiskDollars,
+		direction,
+		entry.Price,
+		entry.Stop,
+		entry.Stop,
+		entry.Shares,
+		entry.RiskDollars,
 		bucket,
 		decision.ID,
+		decision.ATR,
+		entry.RiskDollars,
+		nullString(entry.EntryDate),
+		entry.Commission,
+		entry.OpenedAt,
 	)
 
 	if err != nil {
@@ -100,18 +290,49 @@ func (db *DB) OpenPosition(ticker string) (*Position, error) {
 
 	id, _ := result.LastInsertId()
 
+	// Unit 1 is the first lot
+	if err := insertLot(tx, PositionLot{
+		PositionID:  int(id),
+		UnitNum:     1,
+		Shares:      entry.Shares,
+		EntryPrice:  entry.Price,
+		StopPrice:   entry.Stop,
+		RiskDollars: entry.RiskDollars,
+		FilledAt:    entry.OpenedAt,
+	}); err != nil {
+		return nil, err
+	}
+
 	position := &Position{
-		ID:          int(id),
-		Ticker:      ticker,
-		EntryPrice:  decision.Entry,
-		CurrentStop: decision.InitialStop,
-		InitialStop: decision.InitialStop,
-		Shares:      decision.Shares,
-		RiskDollars: decision.RiskDollars,
-		Bucket:      bucket,
-		Status:      "OPEN",
-		DecisionID:  decision.ID,
-		OpenedAt:    time.Now(),
+		ID:           int(id),
+		Ticker:       ticker,
+		Direction:    direction,
+		EntryPrice:   entry.Price,
+		CurrentStop:  entry.Stop,
+		InitialStop:  entry.Stop,
+		Shares:       entry.Shares,
+		RiskDollars:  entry.RiskDollars,
+		Bucket:       bucket,
+		Status:       "OPEN",
+		DecisionID:   decision.ID,
+		OpenedAt:     entry.OpenedAt,
+		MaxUnits:     4,
+		CurrentUnits: 1,
+		ATR:          decision.ATR,
+		ExitLookback: DefaultExitLookback,
+		EntryDate:    entry.EntryDate,
+		InitialRisk:  entry.RiskDollars,
+		Commissions:  entry.Commission,
+	}
+	if entry.Commission != 0 {
+		position.PnL = -entry.Commission
+		if _, err := tx.Exec(`UPDATE positions SET pnl = ? WHERE id = ?`, position.PnL, id); err != nil {
+			return nil, fmt.Errorf("failed to record commission: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit position: %w", err)
 	}
 
 	return position, nil
@@ -119,86 +340,49 @@ func (db *DB) OpenPosition(ticker string) (*Position, error) {
 
 // GetPosition retrieves a position by ID
 func (db *DB) GetPosition(id int) (*Position, error) {
-	query := `
-		SELECT id, ticker, entry_price, current_stop, initial_stop,
-		       shares, risk_dollars, bucket, status, exit_price, exit_date,
-		       outcome, pnl, decision_id, opened_at, closed_at
-		FROM positions
-		WHERE id = ?
-	`
-
-	var p Position
-	var bucket, exitDate, outcome sql.NullString
-	var exitPrice, pnl sql.NullFloat64
-	var closedAt sql.NullTime
-
-	err := db.conn.QueryRow(query, id).Scan(
-		&p.ID, &p.Ticker, &p.EntryPrice, &p.CurrentStop, &p.InitialStop,
-		&p.Shares, &p.RiskDollars, &bucket, &p.Status, &exitPrice, &exitDate,
-		&outcome, &pnl, &p.DecisionID, &p.OpenedAt, &closedAt,
-	)
+	query := `SELECT ` + positionColumns + ` FROM positions WHERE id = ?`
 
+	p, err := scanPosition(db.conn.QueryRow(query, id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get position: %w", err)
 	}
 
-	if bucket.Valid {
-		p.Bucket = bucket.String
-	}
-	if exitPrice.Valid {
-		p.ExitPrice = exitPrice.Float64
-	}
-	if exitDate.Valid {
-		p.ExitDate = exitDate.String
-	}
-	if outcome.Valid {
-		p.Outcome = outcome.String
-	}
-	if pnl.Valid {
-		p.PnL = pnl.Float64
+	return p, nil
+}
+
+// GetPositionByTicker retrieves an open position for a ticker
+func (db *DB) GetPositionByTicker(ticker string) (*Position, error) {
+	p, err := db.FindOpenPosition(ticker)
+	if err != nil {
+		return nil, err
 	}
-	if closedAt.Valid {
-		p.ClosedAt = closedAt.Time
+	if p == nil {
+		return nil, fmt.Errorf("no open position found for %s", ticker)
 	}
 
-	return &p, nil
+	return p, nil
 }
 
-// GetPositionByTicker retrieves an open position for a ticker
-func (db *DB) GetPositionByTicker(ticker string) (*Position, error) {
+// FindOpenPosition returns the open position for a ticker, or nil if there
+// is none
+func (db *DB) FindOpenPosition(ticker string) (*Position, error) {
 	query := `
-		SELECT id, ticker, entry_price, current_stop, initial_stop,
-		       shares, risk_dollars, bucket, status, exit_price, exit_date,
-		       outcome, pnl, decision_id, opened_at, closed_at
+		SELECT ` + positionColumns + `
 		FROM positions
 		WHERE ticker = ? AND status = 'OPEN'
 		ORDER BY opened_at DESC
 		LIMIT 1
 	`
 
-	var p Position
-	var bucket, exitDate, outcome sql.NullString
-	var exitPrice, pnl sql.NullFloat64
-	var closedAt sql.NullTime
-
-	err := db.conn.QueryRow(query, ticker).Scan(
-		&p.ID, &p.Ticker, &p.EntryPrice, &p.CurrentStop, &p.InitialStop,
-		&p.Shares, &p.RiskDollars, &bucket, &p.Status, &exitPrice, &exitDate,
-		&outcome, &pnl, &p.DecisionID, &p.OpenedAt, &closedAt,
-	)
-
+	p, err := scanPosition(db.conn.QueryRow(query, ticker))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("no open position found for %s", ticker)
+		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get position: %w", err)
 	}
 
-	if bucket.Valid {
-		p.Bucket = bucket.String
-	}
-
-	return &p, nil
+	return p, nil
 }
 
 // UpdateStop updates the stop price for a position
@@ -209,79 +393,73 @@ func (db *DB) UpdateStop(ticker string, newStop float64) error {
 	}
 
 	// Validate stop movement (cannot move against position)
-	// Assume LONG positions for now (can extend later for SHORT)
-	if newStop < position.CurrentStop {
-		return fmt.Errorf("cannot move stop down for long position (current: %.2f, new: %.2f)",
-			position.CurrentStop, newStop)
+	if stopLoosens(position.Direction, position.CurrentStop, newStop) {
+		move := "down"
+		if position.Direction == DirectionShort {
+			move = "up"
+		}
+		return fmt.Errorf("cannot move stop %s for %s position (current: %.2f, new: %.2f)",
+			move, strings.ToLower(position.Direction), position.CurrentStop, newStop)
 	}
 
-	// Recalculate risk based on new stop
-	newRisk := float64(position.Shares) * (position.EntryPrice - newStop)
-
-	query := `
-		UPDATE positions
-		SET current_stop = ?, risk_dollars = ?
-		WHERE id = ?
-	`
-
-	_, err = db.conn.Exec(query, newStop, newRisk, position.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update stop: %w", err)
+	// Option risk is the spread's max loss, not shares to a stop: only the
+	// stop on the underlying moves
+	if !tracksLots(position) {
+		stopDate := time.Now().Format("2006-01-02")
+		if _, err := db.conn.Exec(`UPDATE positions SET current_stop = ?, stop_date = ? WHERE id = ?`,
+			newStop, stopDate, position.ID); err != nil {
+			return fmt.Errorf("failed to update stop: %w", err)
+		}
+		return nil
 	}
 
-	return nil
-}
-
-// ClosePosition closes a position and calculates P&L
-func (db *DB) ClosePosition(ticker string, exitPrice float64, outcome string) error {
-	position, err := db.GetPositionByTicker(ticker)
+	lots, err := db.GetPositionLots(position.ID)
 	if err != nil {
 		return err
 	}
 
-	// Calculate P&L (assume LONG)
-	pnl := float64(position.Shares) * (exitPrice - position.EntryPrice)
-
-	// Update position
-	query := `
-		UPDATE positions
-		SET status = 'CLOSED',
-		    exit_price = ?,
-		    exit_date = ?,
-		    outcome = ?,
-		    pnl = ?,
-		    closed_at = ?
-		WHERE id = ?
-	`
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	now := time.Now()
-	exitDate := now.Format("2006-01-02")
+	if _, err := storeLegacyLot(tx, lots); err != nil {
+		return err
+	}
 
-	_, err = db.conn.Exec(query, exitPrice, exitDate, outcome, pnl, now, position.ID)
-	if err != nil {
-		return fmt.Errorf("failed to close position: %w", err)
+	// Move every open lot to the new stop, then re-derive risk from the lots
+	if err := setLotStops(tx, position.ID, position.Direction, newStop); err != nil {
+		return err
+	}
+	if _, err := syncPositionFromLots(tx, position.ID); err != nil {
+		return err
 	}
 
-	// Trigger cooldown if loss
-	if outcome == "LOSS" && position.Bucket != "" {
-		reason := fmt.Sprintf("Loss on %s", ticker)
-		err = db.TriggerBucketCooldown(position.Bucket, reason)
-		if err != nil {
-			return fmt.Errorf("failed to trigger cooldown: %w", err)
-		}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stop update: %w", err)
 	}
 
 	return nil
 }
 
+// ClosePosition closes every remaining share of a position
+// P&L is the sum of the realized P&L of all lots, including earlier
+// partial closes (see PartialClose). The exit is written to trade_history.
+// Option positions have no lots; see closeOptionPosition.
+func (db *DB) ClosePosition(ticker string, exitPrice float64, outcome string) error {
+	position, err := db.GetPositionByTicker(ticker)
+	if err != nil {
+		return err
+	}
+	// Every remaining share goes, so the cost method does not change the P&L
+	_, err = db.exitPosition(position, 0, exitPrice, CostMethodFIFO, outcome, time.Now(), 0)
+	return err
+}
+
 // GetAllPositions retrieves all positions, optionally filtered by status
 func (db *DB) GetAllPositions(status string) ([]Position, error) {
-	query := `
-		SELECT id, ticker, entry_price, current_stop, initial_stop,
-		       shares, risk_dollars, bucket, status, exit_price, exit_date,
-		       outcome, pnl, decision_id, opened_at, closed_at
-		FROM positions
-	`
+	query := `SELECT ` + positionColumns + ` FROM positions`
 
 	args := []interface{}{}
 	if status != "" {
@@ -300,41 +478,11 @@ func (db *DB) GetAllPositions(status string) ([]Position, error) {
 	positions := []Position{}
 
 	for rows.Next() {
-		var p Position
-		var bucket, exitDate, outcome sql.NullString
-		var exitPrice, pnl sql.NullFloat64
-		var closedAt sql.NullTime
-
-		err := rows.Scan(
-			&p.ID, &p.Ticker, &p.EntryPrice, &p.CurrentStop, &p.InitialStop,
-			&p.Shares, &p.RiskDollars, &bucket, &p.Status, &exitPrice, &exitDate,
-			&outcome, &pnl, &p.DecisionID, &p.OpenedAt, &closedAt,
-		)
-
+		p, err := scanPosition(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan position: %w", err)
 		}
-
-		if bucket.Valid {
-			p.Bucket = bucket.String
-		}
-		if exitPrice.Valid {
-			p.ExitPrice = exitPrice.Float64
-		}
-		if exitDate.Valid {
-			p.ExitDate = exitDate.String
-		}
-		if outcome.Valid {
-			p.Outcome = outcome.String
-		}
-		if pnl.Valid {
-			p.PnL = pnl.Float64
-		}
-		if closedAt.Valid {
-			p.ClosedAt = closedAt.Time
-		}
-
-		positions = append(positions, p)
+		positions = append(positions, *p)
 	}
 
 	return positions, nil
@@ -345,40 +493,40 @@ func (db *DB) GetOpenPositions() ([]Position, error) {
 	return db.GetAllPositions("OPEN")
 }
 
-// CalculatePortfolioHeat calculates total risk from all open positions
+// openRiskQuery totals the risk of open positions from their remaining
+// lots, falling back to the position row for positions without lots; no lot
+// or position counts for less than zero. Option positions are always taken
+// from the row (their max loss), ignoring lots older versions recorded.
+const openRiskQuery = `
+	SELECT COALESCE(SUM(MAX(0, CASE WHEN p.instrument_type = 'OPTION' THEN p.risk_dollars ELSE COALESCE(
+		(SELECT SUM(MAX(0, l.risk_dollars)) FROM position_lots l
+		 WHERE l.position_id = p.id AND l.remaining_shares > 0),
+		p.risk_dollars
+	) END)), 0)
+	FROM positions p
+	WHERE p.status = 'OPEN'
+`
+
+// CalculatePortfolioHeat calculates total risk from the remaining lots of all open positions
 func (db *DB) CalculatePortfolioHeat() (float64, error) {
-	positions, err := db.GetOpenPositions()
+	var heat float64
+	err := db.conn.QueryRow(openRiskQuery).Scan(&heat)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("failed to calculate portfolio heat: %w", err)
 	}
 
-	var totalHeat float64
-	for _, p := range positions {
-		totalHeat += p.RiskDollars
-	}
-
-	return totalHeat, nil
+	return heat, nil
 }
 
-// CalculateBucketHeat calculates total risk for a specific bucket from open positions
+// CalculateBucketHeat calculates total risk for a specific bucket from the remaining lots of open positions
 func (db *DB) CalculateBucketHeat(bucket string) (float64, error) {
-	query := `
-		SELECT SUM(risk_dollars)
-		FROM positions
-		WHERE bucket = ? AND status = 'OPEN'
-	`
-
-	var heat sql.NullFloat64
-	err := db.conn.QueryRow(query, bucket).Scan(&heat)
+	var heat float64
+	err := db.conn.QueryRow(openRiskQuery+` AND p.bucket = ?`, bucket).Scan(&heat)
 	if err != nil {
 		return 0, fmt.Errorf("failed to calculate bucket heat: %w", err)
 	}
 
-	if !heat.Valid {
-		return 0, nil
-	}
-
-	return heat.Float64, nil
+	return heat, nil
 }
 
 // CreatePositionFromSession creates a new position from a completed session with GO decision
@@ -398,23 +546,57 @@ func (db *DB) CreatePositionFromSession(session *TradeSession) (*Position, error
 	// Create position
 	query := `
 		INSERT INTO positions (
-			ticker, entry_price, current_stop, initial_stop,
+			ticker, direction, entry_price, current_stop, initial_stop,
 			shares, risk_dollars, bucket, status, decision_id,
 			instrument_type, options_strategy, entry_date, primary_expiration_date,
 			dte, legs_json, net_debit, max_profit, max_loss,
 			breakeven_lower, breakeven_upper, underlying_at_entry,
-			max_units, current_units, add_step_n, opened_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, 'OPEN', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			max_units, current_units, add_step_n, atr_n, add_price_1, add_price_2, add_price_3,
+			exit_lookback, roll_threshold_dte, time_exit_mode, cost_basis, initial_risk, breakout_system, opened_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'OPEN', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	// A position with shares always holds at least unit 1
+	currentUnits := session.CurrentUnits
+	if currentUnits < 1 {
+		currentUnits = 1
+	}
+
 	// Decision ID from session (may be 0 if not linked to old decisions table)
 	decisionID := 0
 	if session.EntryDecisionID != nil {
 		decisionID = *session.EntryDecisionID
 	}
 
-	result, err := db.conn.Exec(query,
+	direction, err := ParseDirection(session.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	exitLookback := session.ExitLookback
+	if exitLookback <= 0 {
+		exitLookback = DefaultExitLookback
+	}
+
+	rollThreshold := session.RollThresholdDTE
+	if rollThreshold <= 0 {
+		rollThreshold = DefaultRollThresholdDTE
+	}
+	timeExitMode := session.TimeExitMode
+	if timeExitMode == "" {
+		timeExitMode = TimeExitClose
+	}
+
+	// The position and its first lot are written together
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query,
 		session.Ticker,
+		direction,
 		session.SizingEntryPrice,
 		session.SizingInitialStop,
 		session.SizingInitialStop,
@@ -435,8 +617,18 @@ func (db *DB) CreatePositionFromSession(session *TradeSession) (*Position, error
 		session.BreakevenUpper,
 		session.UnderlyingAtEntry,
 		session.MaxUnits,
-		session.CurrentUnits,
+		currentUnits,
 		session.AddStepN,
+		session.SizingATR,
+		session.AddPrice1,
+		session.AddPrice2,
+		session.AddPrice3,
+		exitLookback,
+		rollThreshold,
+		timeExitMode,
+		session.NetDebit,
+		session.SizingRiskDollars,
+		nullString(BreakoutSystemForLookback(session.EntryLookback)),
 		time.Now(),
 	)
 
@@ -446,9 +638,30 @@ func (db *DB) CreatePositionFromSession(session *TradeSession) (*Position, error
 
 	id, _ := result.LastInsertId()
 
+	// Unit 1 is the first lot; option positions hold no shares, their risk
+	// is the position's max loss
+	if session.InstrumentType != InstrumentOption {
+		if err := insertLot(tx, PositionLot{
+			PositionID:  int(id),
+			UnitNum:     1,
+			Shares:      session.SizingShares,
+			EntryPrice:  session.SizingEntryPrice,
+			StopPrice:   session.SizingInitialStop,
+			RiskDollars: session.SizingRiskDollars,
+			FilledAt:    time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit position: %w", err)
+	}
+
 	position := &Position{
 		ID:                    int(id),
 		Ticker:                session.Ticker,
+		Direction:             direction,
 		EntryPrice:            session.SizingEntryPrice,
 		CurrentStop:           session.SizingInitialStop,
 		InitialStop:           session.SizingInitialStop,
@@ -470,8 +683,18 @@ func (db *DB) CreatePositionFromSession(session *TradeSession) (*Position, error
 		BreakevenUpper:        session.BreakevenUpper,
 		UnderlyingAtEntry:     session.UnderlyingAtEntry,
 		MaxUnits:              session.MaxUnits,
-		CurrentUnits:          session.CurrentUnits,
+		CurrentUnits:          currentUnits,
 		AddStepN:              session.AddStepN,
+		ATR:                   session.SizingATR,
+		AddPrice1:             session.AddPrice1,
+		AddPrice2:             session.AddPrice2,
+		AddPrice3:             session.AddPrice3,
+		ExitLookback:          exitLookback,
+		RollThresholdDTE:      rollThreshold,
+		TimeExitMode:          timeExitMode,
+		CostBasis:             session.NetDebit,
+		InitialRisk:           session.SizingRiskDollars,
+		BreakoutSystem:        BreakoutSystemForLookback(session.EntryLookback),
 		OpenedAt:              time.Now(),
 	}
 
@@ -155,6 +155,34 @@ func TestUpdateStop(t *testing.T) {
 	}
 }
 
+func TestUpdateStop_RecordsStopDate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	createTestPosition(t, db, "AAPL", 180.0, 177.0, 25)
+
+	position, err := db.GetPositionByTicker("AAPL")
+	if err != nil {
+		t.Fatalf("GetPositionByTicker failed: %v", err)
+	}
+	// Stop set at entry; exits use the default 10-bar channel
+	if position.StopDate != "" || position.ExitLookback != DefaultExitLookback {
+		t.Errorf("Expected no stop date and lookback 10, got %q and %d", position.StopDate, position.ExitLookback)
+	}
+
+	if err := db.UpdateStop("AAPL", 179.0); err != nil {
+		t.Fatalf("UpdateStop failed: %v", err)
+	}
+
+	updated, err := db.GetPositionByTicker("AAPL")
+	if err != nil {
+		t.Fatalf("GetPositionByTicker failed: %v", err)
+	}
+	if today := time.Now().Format("2006-01-02"); updated.StopDate != today {
+		t.Errorf("Expected stop date %s, got %q", today, updated.StopDate)
+	}
+}
+
 func TestUpdateStop_CannotMoveDown(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -466,3 +494,16 @@ func createTestPosition(t *testing.T, db *DB, ticker string, entry, stop float64
 
 	return position
 }
+
+func TestPositionTradeDate(t *testing.T) {
+	opened := time.Date(2025, 1, 6, 15, 30, 0, 0, time.Local)
+
+	p := Position{OpenedAt: opened}
+	if got := p.TradeDate(); got != "2025-01-06" {
+		t.Errorf("Expected the day it was opened, got %s", got)
+	}
+	p.EntryDate = "2025-01-03"
+	if got := p.TradeDate(); got != "2025-01-03" {
+		t.Errorf("Expected the recorded entry date, got %s", got)
+	}
+}
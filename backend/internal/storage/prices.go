@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PriceBar represents one stored daily OHLCV bar for a ticker
+type PriceBar struct {
+	Ticker string  `json:"ticker"`
+	Date   string  `json:"date"` // YYYY-MM-DD
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
+	Source string  `json:"source,omitempty"` // File the bar was imported from
+}
+
+// SavePriceBars upserts daily bars for a ticker
+// Re-importing the same file is safe: existing dates are overwritten
+func (db *DB) SavePriceBars(ticker string, bars []PriceBar) (int, error) {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	if ticker == "" {
+		return 0, fmt.Errorf("ticker is required")
+	}
+	if len(bars) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO price_bars (ticker, date, open, high, low, close, volume, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ticker, date) DO UPDATE SET
+			open = excluded.open,
+			high = excluded.high,
+			low = excluded.low,
+			close = excluded.close,
+			volume = excluded.volume,
+			source = excluded.source,
+			imported_at = CURRENT_TIMESTAMP
+	`
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, b := range bars {
+		if b.Date == "" {
+			return 0, fmt.Errorf("price bar for %s is missing a date", ticker)
+		}
+		_, err := stmt.Exec(ticker, b.Date, b.Open, b.High, b.Low, b.Close, b.Volume, nullString(b.Source))
+		if err != nil {
+			return 0, fmt.Errorf("failed to save price bar %s %s: %w", ticker, b.Date, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(bars), nil
+}
+
+// GetPriceBars retrieves stored bars for a ticker, oldest first
+// If limit > 0, only the most recent `limit` bars are returned
+func (db *DB) GetPriceBars(ticker string, limit int) ([]PriceBar, error) {
+	query := `
+		SELECT ticker, date, open, high, low, close, volume, COALESCE(source, '')
+		FROM price_bars
+		WHERE ticker = ?
+		ORDER BY date DESC
+	`
+
+	args := []interface{}{strings.ToUpper(ticker)}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price bars: %w", err)
+	}
+	defer rows.Close()
+
+	bars := []PriceBar{}
+	for rows.Next() {
+		var b PriceBar
+		if err := rows.Scan(&b.Ticker, &b.Date, &b.Open, &b.High, &b.Low, &b.Close, &b.Volume, &b.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan price bar: %w", err)
+		}
+		bars = append(bars, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price bars: %w", err)
+	}
+
+	// Reverse to oldest first
+	for i, j := 0, len(bars)-1; i < j; i, j = i+1, j-1 {
+		bars[i], bars[j] = bars[j], bars[i]
+	}
+
+	return bars, nil
+}
+
+// GetPriceTickers returns every ticker that has stored bars
+func (db *DB) GetPriceTickers() ([]string, error) {
+	rows, err := db.conn.Query(`SELECT DISTINCT ticker FROM price_bars ORDER BY ticker`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price tickers: %w", err)
+	}
+	defer rows.Close()
+
+	tickers := []string{}
+	for rows.Next() {
+		var ticker string
+		if err := rows.Scan(&ticker); err != nil {
+			return nil, fmt.Errorf("failed to scan price ticker: %w", err)
+		}
+		tickers = append(tickers, ticker)
+	}
+
+	return tickers, rows.Err()
+}
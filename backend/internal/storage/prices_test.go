@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestSaveAndGetPriceBars(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	bars := []PriceBar{
+		{Date: "2025-01-03", Open: 101, High: 103, Low: 100, Close: 102, Volume: 1200},
+		{Date: "2025-01-02", Open: 100, High: 102, Low: 99, Close: 101, Volume: 1000},
+	}
+
+	count, err := db.SavePriceBars("aapl", bars)
+	if err != nil {
+		t.Fatalf("SavePriceBars failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 bars saved, got %d", count)
+	}
+
+	got, err := db.GetPriceBars("AAPL", 0)
+	if err != nil {
+		t.Fatalf("GetPriceBars failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 bars, got %d", len(got))
+	}
+	if got[0].Date != "2025-01-02" || got[1].Date != "2025-01-03" {
+		t.Errorf("Expected oldest first, got %s then %s", got[0].Date, got[1].Date)
+	}
+	if got[0].Ticker != "AAPL" {
+		t.Errorf("Expected ticker uppercased, got %s", got[0].Ticker)
+	}
+
+	// Limit returns the most recent bars, still oldest first
+	latest, err := db.GetPriceBars("AAPL", 1)
+	if err != nil {
+		t.Fatalf("GetPriceBars with limit failed: %v", err)
+	}
+	if len(latest) != 1 || latest[0].Date != "2025-01-03" {
+		t.Errorf("Expected only 2025-01-03, got %+v", latest)
+	}
+}
+
+func TestSavePriceBars_UpsertsExistingDates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.SavePriceBars("MSFT", []PriceBar{{Date: "2025-01-02", Open: 1, High: 2, Low: 1, Close: 2}}); err != nil {
+		t.Fatalf("SavePriceBars failed: %v", err)
+	}
+	if _, err := db.SavePriceBars("MSFT", []PriceBar{{Date: "2025-01-02", Open: 1, High: 3, Low: 1, Close: 2.5}}); err != nil {
+		t.Fatalf("SavePriceBars re-import failed: %v", err)
+	}
+
+	got, err := db.GetPriceBars("MSFT", 0)
+	if err != nil {
+		t.Fatalf("GetPriceBars failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected re-import to overwrite, got %d bars", len(got))
+	}
+	if got[0].High != 3 || got[0].Close != 2.5 {
+		t.Errorf("Expected updated bar, got %+v", got[0])
+	}
+
+	tickers, err := db.GetPriceTickers()
+	if err != nil {
+		t.Fatalf("GetPriceTickers failed: %v", err)
+	}
+	if len(tickers) != 1 || tickers[0] != "MSFT" {
+		t.Errorf("Expected [MSFT], got %v", tickers)
+	}
+}
+
+func TestSavePriceBars_RequiresTicker(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.SavePriceBars(" ", []PriceBar{{Date: "2025-01-02"}}); err == nil {
+		t.Error("Expected error for blank ticker")
+	}
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/trading-engine/internal/options"
+)
+
+// StatusRolled marks a position (and its trade_history row) closed by a roll
+const StatusRolled = "ROLLED"
+
+// OptionRoll is a roll to record: the expiring legs closed and replacements
+// opened at a later expiration
+type OptionRoll struct {
+	RollDate        string          `json:"roll_date"` // YYYY-MM-DD (default today)
+	UnderlyingPrice float64         `json:"underlying_price"`
+	Legs            []OptionLeg     `json:"legs"`         // Every leg of the replacement position
+	RealizedPnL     float64         `json:"realized_pnl"` // On the closed legs
+	RollDebit       float64         `json:"roll_debit"`   // Net paid for the roll (negative = credit)
+	RiskDollars     float64         `json:"risk_dollars"` // Risk of the replacement (heat-checked by the caller)
+	Payoff          *options.Payoff `json:"payoff,omitempty"`
+}
+
+// RollPosition closes an open option position and opens its replacement as
+// a linked pair
+//
+// The old position is marked ROLLED with the realized P&L of the closed
+// legs and points at its replacement (rolled_to_id); the replacement points
+// back (rolled_from_id), carries the stop, bucket and time exit settings,
+// and its cost basis is the old one plus the roll's net debit. Both sides
+// are written to trade_history. A roll is not an exit, so no loss cooldown
+// is started. Payoff is the new legs' payoff; it is evaluated at
+// UnderlyingPrice when nil.
+func (db *DB) RollPosition(positionID int, roll OptionRoll) (*Position, error) {
+	old, err := db.GetPosition(positionID)
+	if err != nil {
+		return nil, err
+	}
+	if old.Status != "OPEN" {
+		return nil, fmt.Errorf("position %d is %s, only open positions can be rolled", positionID, old.Status)
+	}
+	if len(roll.Legs) == 0 {
+		return nil, fmt.Errorf("a roll needs the replacement legs")
+	}
+	if roll.RollDate == "" {
+		roll.RollDate = time.Now().Format("2006-01-02")
+	}
+	payoff := roll.Payoff
+	if payoff == nil {
+		if payoff, err = CalculatePayoff(roll.Legs, roll.UnderlyingPrice); err != nil {
+			return nil, fmt.Errorf("failed to evaluate the new legs: %w", err)
+		}
+	}
+	legsJSON, err := json.Marshal(roll.Legs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode legs: %w", err)
+	}
+
+	pricingLegs := toPricingLegs(roll.Legs)
+	expiration := options.NearestExpiration(pricingLegs)
+	dte, err := options.DaysToExpiration(expiration, roll.RollDate)
+	if err != nil {
+		return nil, err
+	}
+	lowerBE, upperBE := payoff.Range()
+
+	next := *old
+	next.ID = 0
+	next.Status = "OPEN"
+	next.RiskDollars = roll.RiskDollars
+	next.EntryDate = roll.RollDate
+	next.PrimaryExpirationDate = expiration
+	next.DTE = dte
+	next.LegsJSON = string(legsJSON)
+	next.NetDebit = options.NetDebit(pricingLegs)
+	next.MaxProfit = payoff.MaxProfit
+	next.MaxLoss = payoff.MaxLoss
+	next.BreakevenLower = lowerBE
+	next.BreakevenUpper = upperBE
+	next.UnderlyingAtEntry = roll.UnderlyingPrice
+	next.CostBasis = old.CostBasis + roll.RollDebit
+	next.RolledFromID = old.ID
+	next.RolledToID = 0
+	next.PnL = 0
+	next.OpenedAt = time.Now()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO positions (
+			ticker, direction, entry_price, current_stop, initial_stop,
+			shares, risk_dollars, bucket, status, decision_id,
+			instrument_type, options_strategy, entry_date, primary_expiration_date,
+			dte, legs_json, net_debit, max_profit, max_loss,
+			breakeven_lower, breakeven_upper, underlying_at_entry,
+			max_units, current_units, add_step_n, atr_n, add_price_1, add_price_2, add_price_3,
+			exit_lookback, stop_date, roll_threshold_dte, time_exit_mode, cost_basis, rolled_from_id,
+			initial_risk, breakout_system, opened_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'OPEN', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(query,
+		next.Ticker, next.Direction, next.EntryPrice, next.CurrentStop, next.InitialStop,
+		next.Shares, next.RiskDollars, next.Bucket, next.DecisionID,
+		next.InstrumentType, next.OptionsStrategy, next.EntryDate, next.PrimaryExpirationDate,
+		next.DTE, next.LegsJSON, next.NetDebit, next.MaxProfit, next.MaxLoss,
+		next.BreakevenLower, next.BreakevenUpper, next.UnderlyingAtEntry,
+		next.MaxUnits, next.CurrentUnits, next.AddStepN, next.ATR, next.AddPrice1, next.AddPrice2, next.AddPrice3,
+		next.ExitLookback, nullString(next.StopDate), next.RollThresholdDTE, nullString(next.TimeExitMode),
+		next.CostBasis, next.RolledFromID,
+		nullFloat(next.InitialRisk), nullString(next.BreakoutSystem), next.OpenedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rolled position: %w", err)
+	}
+	id, _ := result.LastInsertId()
+	next.ID = int(id)
+
+	// Like every option position the replacement holds no lots: its risk is
+	// the row's risk_dollars
+
+	pnl := old.PnL + roll.RealizedPnL
+	outcome := OutcomeForPnL(roll.RealizedPnL)
+	query = `
+		UPDATE positions
+		SET status = 'ROLLED',
+		    exit_price = ?,
+		    exit_date = ?,
+		    outcome = ?,
+		    pnl = ?,
+		    risk_dollars = 0,
+		    rolled_to_id = ?,
+		    closed_at = ?
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, nullFloat(roll.UnderlyingPrice), roll.RollDate, outcome, pnl, next.ID, next.OpenedAt, old.ID); err != nil {
+		return nil, fmt.Errorf("failed to close rolled position: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE position_lots SET remaining_shares = 0, closed_at = ? WHERE position_id = ? AND remaining_shares > 0`,
+		next.OpenedAt, old.ID); err != nil {
+		return nil, fmt.Errorf("failed to close rolled lots: %w", err)
+	}
+
+	// The close and the open are written to trade_history as a pair
+	strategy := StrategyLongBreakout
+	if old.Direction == DirectionShort {
+		strategy = StrategyShortBreakout
+	}
+	rollCost := fmt.Sprintf("$%.2f debit", roll.RollDebit)
+	if roll.RollDebit < 0 {
+		rollCost = fmt.Sprintf("$%.2f credit", -roll.RollDebit)
+	}
+	history := []TradeHistoryEntry{
+		{
+			Status:         StatusRolled,
+			EntryDate:      old.TradeDate(),
+			ExpirationDate: old.PrimaryExpirationDate,
+			ExitDate:       roll.RollDate,
+			Contracts:      legContracts(old.LegsJSON),
+			RiskDollars:    old.RiskDollars,
+			EntryPrice:     old.UnderlyingAtEntry,
+			ExitPrice:      roll.UnderlyingPrice,
+			PnL:            roll.RealizedPnL,
+			Outcome:        outcome,
+			Notes: fmt.Sprintf("Position #%d rolled to #%d: %s → %s for %s",
+				old.ID, next.ID, old.PrimaryExpirationDate, expiration, rollCost),
+		},
+		{
+			Status:         "OPEN",
+			EntryDate:      roll.RollDate,
+			ExpirationDate: expiration,
+			DTE:            dte,
+			Contracts:      legContracts(next.LegsJSON),
+			RiskDollars:    next.RiskDollars,
+			EntryPrice:     roll.UnderlyingPrice,
+			Notes: fmt.Sprintf("Position #%d rolled from #%d, cost basis $%.2f",
+				next.ID, old.ID, next.CostBasis),
+		},
+	}
+	for _, entry := range history {
+		entry.Ticker = old.Ticker
+		entry.Strategy = strategy
+		entry.BreakoutSystem = old.BreakoutSystem
+		entry.OptionsStrategy = old.OptionsStrategy
+		entry.InstrumentType = InstrumentOption
+		entry.Sector = old.Bucket // Positions carry the bucket only
+		entry.Bucket = old.Bucket
+		if err := addTradeToHistory(tx, &entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit roll: %w", err)
+	}
+
+	return &next, nil
+}
+
+// legContracts is the largest option quantity among the legs (a 1-lot
+// spread is 1 contract)
+func legContracts(legsJSON string) int {
+	var legs []OptionLeg
+	if err := json.Unmarshal([]byte(legsJSON), &legs); err != nil {
+		return 0
+	}
+	contracts := 0
+	for _, leg := range legs {
+		if leg.Type != InstrumentStock {
+			contracts = max(contracts, leg.Qty)
+		}
+	}
+	return contracts
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// openTestPutSpread opens a 1-lot 95/90 bull put spread on AAPL for a $1.20
+// credit, risking the $380 max loss, with time exits set to Roll
+func openTestPutSpread(t *testing.T, db *DB) *Position {
+	t.Helper()
+
+	decisionID, err := db.SaveDecision(Decision{
+		Date:        time.Now().Format("2006-01-02"),
+		Ticker:      "AAPL",
+		Action:      "GO",
+		Entry:       100.0,
+		InitialStop: 94.0,
+		RiskDollars: 380.0,
+		Banner:      "GREEN",
+		Method:      "options",
+	})
+	if err != nil {
+		t.Fatalf("Failed to save decision: %v", err)
+	}
+
+	exp := time.Now().AddDate(0, 0, 20).Format("2006-01-02")
+	legs := BuildBullPutSpread(90, 95, exp, 1, 0.80, 2.00)
+	legsJSON, err := json.Marshal(legs)
+	if err != nil {
+		t.Fatalf("Failed to encode legs: %v", err)
+	}
+
+	position, err := db.CreatePositionFromSession(&TradeSession{
+		Ticker:                "AAPL",
+		Direction:             DirectionLong,
+		InstrumentType:        InstrumentOption,
+		OptionsStrategy:       StrategyBullPutSpread,
+		EntryDate:             time.Now().AddDate(0, 0, -25).Format("2006-01-02"),
+		PrimaryExpirationDate: exp,
+		RollThresholdDTE:      21,
+		TimeExitMode:          TimeExitRoll,
+		LegsJSON:              string(legsJSON),
+		NetDebit:              CalculateNetDebit(legs),
+		MaxLoss:               380,
+		UnderlyingAtEntry:     100,
+		SizingCompleted:       true,
+		SizingEntryPrice:      100,
+		SizingInitialStop:     94,
+		SizingRiskDollars:     380,
+		HeatBucket:            "Tech/Comm",
+		EntryDecision:         "GO",
+		EntryDecisionID:       &decisionID,
+	})
+	if err != nil {
+		t.Fatalf("CreatePositionFromSession failed: %v", err)
+	}
+	return position
+}
+
+func TestRollPositionLinksCloseAndOpen(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	old := openTestPutSpread(t, db)
+	if old.CostBasis != -120 || old.TimeExitMode != TimeExitRoll || old.RollThresholdDTE != 21 {
+		t.Fatalf("Expected cost basis -120 in Roll mode at 21 DTE, got %+v", old)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	newExp := time.Now().AddDate(0, 0, 55).Format("2006-01-02")
+	next, err := db.RollPosition(old.ID, OptionRoll{
+		RollDate:        today,
+		UnderlyingPrice: 101,
+		Legs:            BuildBullPutSpread(90, 95, newExp, 1, 0.70, 1.90),
+		RealizedPnL:     75,
+		RollDebit:       -75,
+		RiskDollars:     380,
+	})
+	if err != nil {
+		t.Fatalf("RollPosition failed: %v", err)
+	}
+
+	if next.RolledFromID != old.ID || next.PrimaryExpirationDate != newExp || next.DTE != 55 {
+		t.Errorf("Unexpected replacement: %+v", next)
+	}
+	if next.CostBasis != -195 || next.NetDebit != -120 {
+		t.Errorf("Expected cost basis -195 and net debit -120, got %.2f and %.2f", next.CostBasis, next.NetDebit)
+	}
+	// Width 5 less the 1.20 credit of the new legs
+	if next.MaxLoss != 380 || next.CurrentStop != 94 || next.Bucket != "Tech/Comm" || next.TimeExitMode != TimeExitRoll {
+		t.Errorf("Expected max loss 380 with the stop, bucket and mode carried over, got %+v", next)
+	}
+
+	closed, err := db.GetPosition(old.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if closed.Status != StatusRolled || closed.RolledToID != next.ID || closed.PnL != 75 || closed.Outcome != OutcomeWin {
+		t.Errorf("Expected ROLLED to #%d with $75 WIN, got %s to #%d with $%.2f %s",
+			next.ID, closed.Status, closed.RolledToID, closed.PnL, closed.Outcome)
+	}
+
+	stored, err := db.GetPosition(next.ID)
+	if err != nil {
+		t.Fatalf("GetPosition failed: %v", err)
+	}
+	if stored.Status != "OPEN" || stored.CostBasis != -195 || stored.RolledFromID != old.ID {
+		t.Errorf("Unexpected stored replacement: %+v", stored)
+	}
+
+	// Only the replacement carries heat
+	heat, err := db.CalculatePortfolioHeat()
+	if err != nil {
+		t.Fatalf("CalculatePortfolioHeat failed: %v", err)
+	}
+	if heat != 380 {
+		t.Errorf("Expected heat 380 after the roll, got %.2f", heat)
+	}
+
+	history, err := db.GetCalendarView("2000-01-01", "2100-01-01", "")
+	if err != nil {
+		t.Fatalf("GetCalendarView failed: %v", err)
+	}
+	statuses := map[string]int{}
+	for _, entry := range history {
+		statuses[entry.Status]++
+		if entry.Status == StatusRolled && (entry.PnL != 75 || entry.Contracts != 1) {
+			t.Errorf("Unexpected ROLLED row: %+v", entry)
+		}
+	}
+	if statuses[StatusRolled] != 1 || statuses["OPEN"] != 1 {
+		t.Errorf("Expected a ROLLED and an OPEN trade_history row, got %v", statuses)
+	}
+}
+
+func TestRollPositionRejectsClosedPosition(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	old := openTestPutSpread(t, db)
+	roll := OptionRoll{
+		UnderlyingPrice: 101,
+		Legs:            BuildBullPutSpread(90, 95, time.Now().AddDate(0, 0, 55).Format("2006-01-02"), 1, 0.70, 1.90),
+		RiskDollars:     380,
+	}
+	if _, err := db.RollPosition(old.ID, roll); err != nil {
+		t.Fatalf("First roll failed: %v", err)
+	}
+	if _, err := db.RollPosition(old.ID, roll); err == nil {
+		t.Error("Expected rolling a ROLLED position to fail")
+	}
+	if _, err := db.RollPosition(old.ID+1, OptionRoll{}); err == nil {
+		t.Error("Expected a roll without legs to fail")
+	}
+}
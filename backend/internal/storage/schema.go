@@ -51,6 +51,7 @@ CREATE TABLE IF NOT EXISTS decisions (
 	date TEXT NOT NULL,
 	ticker TEXT NOT NULL,
 	action TEXT NOT NULL,
+	direction TEXT NOT NULL DEFAULT 'LONG' CHECK (direction IN ('LONG', 'SHORT')),
 	entry REAL,
 	atr REAL,
 	stop_distance REAL,
@@ -65,6 +66,7 @@ CREATE TABLE IF NOT EXISTS decisions (
 	bucket TEXT,
 	reason TEXT,
 	corr_id TEXT,
+	atr_date TEXT,
 	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 	UNIQUE(date, ticker)
 );
@@ -77,6 +79,7 @@ CREATE INDEX IF NOT EXISTS idx_decisions_created_at ON decisions(created_at DESC
 CREATE TABLE IF NOT EXISTS positions (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
 	ticker TEXT NOT NULL,
+	direction TEXT NOT NULL DEFAULT 'LONG' CHECK (direction IN ('LONG', 'SHORT')),
 	entry_price REAL NOT NULL,
 	current_stop REAL NOT NULL,
 	initial_stop REAL NOT NULL,
@@ -104,9 +107,31 @@ CREATE TABLE IF NOT EXISTS positions (
 	max_units INTEGER DEFAULT 4,
 	current_units INTEGER DEFAULT 1,
 	add_step_n REAL DEFAULT 0.5,
+	atr_n REAL,
+	add_price_1 REAL,
+	add_price_2 REAL,
+	add_price_3 REAL,
+	exit_lookback INTEGER DEFAULT 10,
+	stop_date TEXT,
+	roll_threshold_dte INTEGER DEFAULT 21,
+	time_exit_mode TEXT DEFAULT 'Close' CHECK (time_exit_mode IN ('None', 'Close', 'Roll')),
+	cost_basis REAL,
+	rolled_from_id INTEGER,
+	rolled_to_id INTEGER,
+	converted_from_id INTEGER,
+	initial_risk REAL,
+	breakout_system TEXT,
+	excursion_low REAL,
+	excursion_high REAL,
+	excursion_date TEXT,
+	commissions REAL DEFAULT 0,
+	commissions_booked REAL DEFAULT 0,
 	opened_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 	closed_at DATETIME,
-	FOREIGN KEY (decision_id) REFERENCES decisions(id)
+	FOREIGN KEY (decision_id) REFERENCES decisions(id),
+	FOREIGN KEY (rolled_from_id) REFERENCES positions(id),
+	FOREIGN KEY (rolled_to_id) REFERENCES positions(id),
+	FOREIGN KEY (converted_from_id) REFERENCES positions(id)
 );
 
 CREATE INDEX IF NOT EXISTS idx_positions_ticker ON positions(ticker);
@@ -114,6 +139,71 @@ CREATE INDEX IF NOT EXISTS idx_positions_status ON positions(status);
 CREATE INDEX IF NOT EXISTS idx_positions_bucket ON positions(bucket);
 CREATE INDEX IF NOT EXISTS idx_positions_status_opened ON positions(status, opened_at DESC);
 
+-- Position lots table: one row per fill (unit 1 plus each pyramid add-on)
+-- remaining_shares and realized_pnl track partial closes per lot
+CREATE TABLE IF NOT EXISTS position_lots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	position_id INTEGER NOT NULL,
+	unit_num INTEGER NOT NULL,
+	shares INTEGER NOT NULL,
+	remaining_shares INTEGER NOT NULL DEFAULT 0,
+	entry_price REAL NOT NULL,
+	stop_price REAL NOT NULL,
+	risk_dollars REAL NOT NULL,
+	initial_risk REAL,
+	realized_pnl REAL DEFAULT 0,
+	filled_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	closed_at DATETIME,
+	FOREIGN KEY (position_id) REFERENCES positions(id),
+	UNIQUE(position_id, unit_num)
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_lots_position ON position_lots(position_id);
+
+-- Price bars table: daily OHLCV history imported from CSV (source of ATR/N)
+CREATE TABLE IF NOT EXISTS price_bars (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ticker TEXT NOT NULL,
+	date TEXT NOT NULL,
+	open REAL NOT NULL,
+	high REAL NOT NULL,
+	low REAL NOT NULL,
+	close REAL NOT NULL,
+	volume INTEGER DEFAULT 0,
+	source TEXT,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(ticker, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_bars_ticker_date ON price_bars(ticker, date);
+
+-- Broker fills table: executions imported from broker reports, matched to positions
+CREATE TABLE IF NOT EXISTS broker_fills (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	broker TEXT NOT NULL,
+	exec_id TEXT NOT NULL,
+	account TEXT,
+	ticker TEXT NOT NULL,
+	asset_class TEXT,
+	option_leg TEXT,
+	side TEXT NOT NULL CHECK (side IN ('BUY', 'SELL')),
+	quantity INTEGER NOT NULL,
+	price REAL NOT NULL,
+	commission REAL DEFAULT 0,
+	trade_date TEXT NOT NULL,
+	trade_time TEXT,
+	status TEXT NOT NULL CHECK (status IN ('APPLIED', 'UNMATCHED', 'IGNORED', 'PENDING')),
+	action TEXT,
+	position_id INTEGER,
+	detail TEXT,
+	source TEXT,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(broker, exec_id),
+	FOREIGN KEY (position_id) REFERENCES positions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_broker_fills_status ON broker_fills(status);
+
 -- Impulse timers table: 2-minute brake enforcement
 CREATE TABLE IF NOT EXISTS impulse_timers (
 	id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -145,6 +235,7 @@ CREATE TABLE IF NOT EXISTS trade_sessions (
     session_num INTEGER GENERATED ALWAYS AS (id) STORED UNIQUE,
     ticker TEXT NOT NULL,
     strategy TEXT NOT NULL,
+    direction TEXT NOT NULL DEFAULT 'LONG' CHECK (direction IN ('LONG', 'SHORT')),
     source TEXT NOT NULL DEFAULT 'MANUAL' CHECK (source IN ('MANUAL', 'PRESET', 'CUSTOM')),
     candidate_id INTEGER,
     preset_id INTEGER,
@@ -190,6 +281,7 @@ CREATE TABLE IF NOT EXISTS trade_sessions (
     sizing_contracts INTEGER,
     sizing_risk_dollars REAL,
     sizing_delta REAL,
+    sizing_atr_date TEXT,
     sizing_completed_at DATETIME,
     heat_completed INTEGER NOT NULL DEFAULT 0 CHECK (heat_completed IN (0,1)),
     heat_status TEXT,
@@ -12,7 +12,8 @@ type TradeSession struct {
 	ID         int    `json:"id"`
 	SessionNum int    `json:"session_num"`
 	Ticker     string `json:"ticker"`
-	Strategy   string `json:"strategy"` // LONG_BREAKOUT, SHORT_BREAKOUT, CUSTOM
+	Strategy   string `json:"strategy"`  // LONG_BREAKOUT, SHORT_BREAKOUT, CUSTOM
+	Direction  string `json:"direction"` // LONG or SHORT (from strategy)
 
 	// Provenance from FINVIZ/candidates
 	Source       string `json:"source"`        // MANUAL, PRESET, CUSTOM
@@ -72,6 +73,7 @@ type TradeSession struct {
 	SizingContracts    int        `json:"sizing_contracts,omitempty"`
 	SizingRiskDollars  float64    `json:"sizing_risk_dollars,omitempty"`
 	SizingDelta        float64    `json:"sizing_delta,omitempty"`
+	SizingATRDate      string     `json:"sizing_atr_date,omitempty"` // Bar date of stored N (empty if typed in)
 	SizingCompletedAt  *time.Time `json:"sizing_completed_at,omitempty"`
 
 	// Gate 3: Heat Check
@@ -133,14 +135,15 @@ func (db *DB) CreateSession(ticker, strategy string) (*TradeSession, error) {
 
 	query := `
 		INSERT INTO trade_sessions (
-			ticker, strategy, source, status, current_step,
+			ticker, strategy, direction, source, status, current_step,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.conn.Exec(query,
 		ticker,
 		strategy,
+		DirectionForStrategy(strategy),
 		"MANUAL", // default source
 		StatusDraft,
 		StepChecklist,
@@ -166,14 +169,15 @@ func (db *DB) CreateSessionFromPreset(ticker, strategy string, candidateID, pres
 
 	query := `
 		INSERT INTO trade_sessions (
-			ticker, strategy, source, candidate_id, preset_id, preset_name, scan_date,
+			ticker, strategy, direction, source, candidate_id, preset_id, preset_name, scan_date,
 			status, current_step, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.conn.Exec(query,
 		ticker,
 		strategy,
+		DirectionForStrategy(strategy),
 		"PRESET",
 		candidateID,
 		presetID,
@@ -206,19 +210,20 @@ func (db *DB) CreateSessionWithOptions(ticker, strategy, instrumentType, options
 
 	query := `
 		INSERT INTO trade_sessions (
-			ticker, strategy, source, status, current_step,
+			ticker, strategy, direction, source, status, current_step,
 			instrument_type, options_strategy, entry_date, primary_expiration_date,
 			dte, roll_threshold_dte, time_exit_mode, legs_json,
 			net_debit, max_profit, max_loss, breakeven_lower, breakeven_upper, underlying_at_entry,
 			max_units, add_step_n, current_units,
 			entry_lookback, exit_lookback,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.conn.Exec(query,
 		ticker,
 		strategy,
+		DirectionForStrategy(strategy),
 		"MANUAL",
 		StatusDraft,
 		StepChecklist,
@@ -260,7 +265,7 @@ func (db *DB) CreateSessionWithOptions(ticker, strategy, instrumentType, options
 func (db *DB) GetSession(id int) (*TradeSession, error) {
 	query := `
 		SELECT
-			id, session_num, ticker, strategy, source,
+			id, session_num, ticker, strategy, direction, source,
 			candidate_id, preset_id, preset_name, scan_date,
 			status, current_step,
 			instrument_type, options_strategy, entry_date, primary_expiration_date,
@@ -273,7 +278,7 @@ func (db *DB) GetSession(id int) (*TradeSession, error) {
 			sizing_completed, sizing_method, sizing_entry_price, sizing_atr,
 			sizing_k_multiple, sizing_stop_distance, sizing_initial_stop,
 			sizing_shares, sizing_contracts, sizing_risk_dollars, sizing_delta,
-			sizing_completed_at,
+			sizing_atr_date, sizing_completed_at,
 			heat_completed, heat_status,
 			heat_portfolio_current, heat_portfolio_new, heat_portfolio_cap,
 			heat_bucket, heat_bucket_current, heat_bucket_new, heat_bucket_cap,
@@ -288,7 +293,7 @@ func (db *DB) GetSession(id int) (*TradeSession, error) {
 
 	session := &TradeSession{}
 	var candidateID, presetID, entryDecisionID sql.NullInt64
-	var presetName, scanDate, checklistBanner, sizingMethod sql.NullString
+	var presetName, scanDate, checklistBanner, sizingMethod, sizingATRDate sql.NullString
 	var heatStatus, heatBucket, entryDecision sql.NullString
 	var checklistCompletedAt, sizingCompletedAt, heatCompletedAt, entryCompletedAt, completedAt sql.NullTime
 	var sizingEntryPrice, sizingATR, sizingKMultiple, sizingStopDistance, sizingInitialStop sql.NullFloat64
@@ -308,7 +313,7 @@ func (db *DB) GetSession(id int) (*TradeSession, error) {
 	var addStepN, addPrice1, addPrice2, addPrice3 sql.NullFloat64
 
 	err := db.conn.QueryRow(query, id).Scan(
-		&session.ID, &session.SessionNum, &session.Ticker, &session.Strategy, &session.Source,
+		&session.ID, &session.SessionNum, &session.Ticker, &session.Strategy, &session.Direction, &session.Source,
 		&candidateID, &presetID, &presetName, &scanDate,
 		&session.Status, &session.CurrentStep,
 		&instrumentType, &optionsStrategy, &entryDate, &primaryExpirationDate,
@@ -321,7 +326,7 @@ func (db *DB) GetSession(id int) (*TradeSession, error) {
 		&sizingCompleted, &sizingMethod, &sizingEntryPrice, &sizingATR,
 		&sizingKMultiple, &sizingStopDistance, &sizingInitialStop,
 		&sizingShares, &sizingContracts, &sizingRiskDollars, &sizingDelta,
-		&sizingCompletedAt,
+		&sizingATRDate, &sizingCompletedAt,
 		&heatCompleted, &heatStatus,
 		&heatPortfolioCurrent, &heatPortfolioNew, &heatPortfolioCap,
 		&heatBucket, &heatBucketCurrent, &heatBucketNew, &heatBucketCap,
@@ -396,6 +401,9 @@ func (db *DB) GetSession(id int) (*TradeSession, error) {
 	if sizingDelta.Valid {
 		session.SizingDelta = sizingDelta.Float64
 	}
+	if sizingATRDate.Valid {
+		session.SizingATRDate = sizingATRDate.String
+	}
 	if sizingCompletedAt.Valid {
 		session.SizingCompletedAt = &sizingCompletedAt.Time
 	}
@@ -652,6 +660,16 @@ func (db *DB) UpdateSessionSizingWithPyramid(id int, method string, entryPrice,
 	return nil
 }
 
+// UpdateSessionSizingATRDate records which stored bar date the sizing N came from
+// Pass an empty date when N was entered by hand
+func (db *DB) UpdateSessionSizingATRDate(id int, atrDate string) error {
+	query := `UPDATE trade_sessions SET sizing_atr_date = ? WHERE id = ?`
+	if _, err := db.conn.Exec(query, nullString(atrDate), id); err != nil {
+		return fmt.Errorf("failed to update session sizing ATR date: %w", err)
+	}
+	return nil
+}
+
 // UpdateSessionHeat updates the heat check gate completion for a session
 func (db *DB) UpdateSessionHeat(id int, status, bucket string,
 	portfolioCurrent, portfolioNew, portfolioCap,
@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -36,6 +37,11 @@ type TradeHistoryEntry struct {
 
 // AddTradeToHistory creates a new entry in the trade_history table
 func (db *DB) AddTradeToHistory(entry *TradeHistoryEntry) error {
+	return addTradeToHistory(db.conn, entry)
+}
+
+// addTradeToHistory inserts a trade_history row on a connection or transaction
+func addTradeToHistory(ex execer, entry *TradeHistoryEntry) error {
 	query := `
 		INSERT INTO trade_history (
 			session_id, ticker, strategy, breakout_system, options_strategy,
@@ -50,7 +56,7 @@ func (db *DB) AddTradeToHistory(entry *TradeHistoryEntry) error {
 		sessionID = *entry.SessionID
 	}
 
-	_, err := db.conn.Exec(query,
+	_, err := ex.Exec(query,
 		sessionID,
 		entry.Ticker,
 		entry.Strategy,
@@ -302,6 +308,92 @@ func (db *DB) GetTradeHistoryBySector(sector, startDate, endDate string) ([]Trad
 	return entries, nil
 }
 
+// GetClosedTradesForTicker retrieves closed trades for a ticker, most recent exit first
+// If breakoutSystem is non-empty, only trades for that system are returned
+func (db *DB) GetClosedTradesForTicker(ticker, breakoutSystem string) ([]TradeHistoryEntry, error) {
+	query := `
+		SELECT
+			id, session_id, ticker, strategy, breakout_system, options_strategy,
+			instrument_type, sector, bucket, entry_date, expiration_date,
+			exit_date, status, dte, contracts, shares, risk_dollars,
+			entry_price, exit_price, pnl, outcome, notes,
+			created_at, updated_at
+		FROM trade_history
+		WHERE ticker = ? AND status = 'CLOSED' AND exit_date IS NOT NULL
+	`
+
+	args := []interface{}{strings.ToUpper(ticker)}
+	if breakoutSystem != "" {
+		query += " AND breakout_system = ?"
+		args = append(args, breakoutSystem)
+	}
+	query += " ORDER BY exit_date DESC, id DESC"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed trades: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTradeHistoryRows(rows)
+}
+
+// scanTradeHistoryRows converts trade_history rows selected with the standard column list
+func scanTradeHistoryRows(rows *sql.Rows) ([]TradeHistoryEntry, error) {
+	entries := []TradeHistoryEntry{}
+
+	for rows.Next() {
+		var entry TradeHistoryEntry
+		var sessionID sql.NullInt64
+		var breakoutSystem, optionsStrategy, instrumentType sql.NullString
+		var sector, bucket, expirationDate, exitDate sql.NullString
+		var dte, contracts, shares sql.NullInt64
+		var riskDollars, entryPrice, exitPrice, pnl sql.NullFloat64
+		var outcome, notes sql.NullString
+
+		err := rows.Scan(
+			&entry.ID, &sessionID, &entry.Ticker, &entry.Strategy,
+			&breakoutSystem, &optionsStrategy, &instrumentType,
+			&sector, &bucket, &entry.EntryDate, &expirationDate,
+			&exitDate, &entry.Status, &dte, &contracts, &shares,
+			&riskDollars, &entryPrice, &exitPrice, &pnl,
+			&outcome, &notes, &entry.CreatedAt, &entry.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade history entry: %w", err)
+		}
+
+		if sessionID.Valid {
+			id := int(sessionID.Int64)
+			entry.SessionID = &id
+		}
+		entry.BreakoutSystem = breakoutSystem.String
+		entry.OptionsStrategy = optionsStrategy.String
+		entry.InstrumentType = instrumentType.String
+		entry.Sector = sector.String
+		entry.Bucket = bucket.String
+		entry.ExpirationDate = expirationDate.String
+		entry.ExitDate = exitDate.String
+		entry.DTE = int(dte.Int64)
+		entry.Contracts = int(contracts.Int64)
+		entry.Shares = int(shares.Int64)
+		entry.RiskDollars = riskDollars.Float64
+		entry.EntryPrice = entryPrice.Float64
+		entry.ExitPrice = exitPrice.Float64
+		entry.PnL = pnl.Float64
+		entry.Outcome = outcome.String
+		entry.Notes = notes.String
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trade history: %w", err)
+	}
+
+	return entries, nil
+}
+
 // UpdateTradeHistory updates an existing trade history entry
 func (db *DB) UpdateTradeHistory(id int, exitDate, exitPrice, pnl, outcome *string) error {
 	query := `
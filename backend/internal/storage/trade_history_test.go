@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestGetClosedTradesForTicker(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	entries := []TradeHistoryEntry{
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", BreakoutSystem: SystemOne, EntryDate: "2025-01-02", ExitDate: "2025-01-10", Status: "CLOSED", EntryPrice: 100, ExitPrice: 108, PnL: 800, Outcome: "WIN"},
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", BreakoutSystem: SystemOne, EntryDate: "2025-02-03", ExitDate: "2025-02-12", Status: "CLOSED", EntryPrice: 110, ExitPrice: 105, PnL: -500, Outcome: "LOSS"},
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", BreakoutSystem: SystemTwo, EntryDate: "2025-03-03", ExitDate: "2025-03-20", Status: "CLOSED", EntryPrice: 112, ExitPrice: 120, PnL: 800, Outcome: "WIN"},
+		{Ticker: "AAPL", Strategy: "LONG_BREAKOUT", BreakoutSystem: SystemOne, EntryDate: "2025-04-01", Status: "OPEN", EntryPrice: 125},
+		{Ticker: "MSFT", Strategy: "LONG_BREAKOUT", BreakoutSystem: SystemOne, EntryDate: "2025-01-02", ExitDate: "2025-01-10", Status: "CLOSED", EntryPrice: 400, ExitPrice: 410, PnL: 100},
+	}
+	for i := range entries {
+		if err := db.AddTradeToHistory(&entries[i]); err != nil {
+			t.Fatalf("AddTradeToHistory failed: %v", err)
+		}
+	}
+
+	trades, err := db.GetClosedTradesForTicker("aapl", SystemOne)
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 closed System 1 trades, got %d", len(trades))
+	}
+	if trades[0].ExitDate != "2025-02-12" {
+		t.Errorf("Expected most recent exit first, got %s", trades[0].ExitDate)
+	}
+	if trades[0].Outcome != "LOSS" || trades[0].PnL != -500 {
+		t.Errorf("Expected LOSS with -500 PnL, got %s %.2f", trades[0].Outcome, trades[0].PnL)
+	}
+
+	all, err := db.GetClosedTradesForTicker("AAPL", "")
+	if err != nil {
+		t.Fatalf("GetClosedTradesForTicker without system failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 closed trades across systems, got %d", len(all))
+	}
+}
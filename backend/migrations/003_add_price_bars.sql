@@ -0,0 +1,22 @@
+-- Migration: Add stored daily price bars and ATR provenance
+-- Purpose: Compute N (20-day Wilder ATR) from imported OHLCV bars instead of typing it
+
+CREATE TABLE IF NOT EXISTS price_bars (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ticker TEXT NOT NULL,
+	date TEXT NOT NULL,
+	open REAL NOT NULL,
+	high REAL NOT NULL,
+	low REAL NOT NULL,
+	close REAL NOT NULL,
+	volume INTEGER DEFAULT 0,
+	source TEXT,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(ticker, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_bars_ticker_date ON price_bars(ticker, date);
+
+-- Record which bar date the sizing N came from
+ALTER TABLE decisions ADD COLUMN atr_date TEXT;
+ALTER TABLE trade_sessions ADD COLUMN sizing_atr_date TEXT;
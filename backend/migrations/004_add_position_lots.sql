@@ -0,0 +1,23 @@
+-- Migration: Add per-fill position lots and pyramid add levels on positions
+-- Purpose: Let AddUnit record each pyramid add-on as its own lot
+
+CREATE TABLE IF NOT EXISTS position_lots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	position_id INTEGER NOT NULL,
+	unit_num INTEGER NOT NULL,
+	shares INTEGER NOT NULL,
+	entry_price REAL NOT NULL,
+	stop_price REAL NOT NULL,
+	risk_dollars REAL NOT NULL,
+	filled_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (position_id) REFERENCES positions(id),
+	UNIQUE(position_id, unit_num)
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_lots_position ON position_lots(position_id);
+
+-- N at entry and the planned add levels, copied from the sizing session
+ALTER TABLE positions ADD COLUMN atr_n REAL;
+ALTER TABLE positions ADD COLUMN add_price_1 REAL;
+ALTER TABLE positions ADD COLUMN add_price_2 REAL;
+ALTER TABLE positions ADD COLUMN add_price_3 REAL;
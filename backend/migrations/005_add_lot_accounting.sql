@@ -0,0 +1,25 @@
+-- Migration: Track remaining shares and realized P&L per position lot
+-- Purpose: Partial closes by FIFO/LIFO/average cost, with P&L and risk taken from the remaining lots
+
+ALTER TABLE position_lots ADD COLUMN remaining_shares INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE position_lots ADD COLUMN realized_pnl REAL DEFAULT 0;
+ALTER TABLE position_lots ADD COLUMN closed_at DATETIME;
+
+-- Lots of open positions are still fully held (closed_at guards re-runs)
+UPDATE position_lots
+SET remaining_shares = shares
+WHERE remaining_shares = 0
+  AND closed_at IS NULL
+  AND position_id IN (SELECT id FROM positions WHERE status = 'OPEN');
+
+-- Positions opened before lots were tracked get their unit 1
+INSERT INTO position_lots (position_id, unit_num, shares, remaining_shares, entry_price, stop_price, risk_dollars, realized_pnl, filled_at, closed_at)
+SELECT id, 1, shares,
+       CASE WHEN status = 'OPEN' THEN shares ELSE 0 END,
+       entry_price, current_stop,
+       CASE WHEN status = 'OPEN' THEN risk_dollars ELSE 0 END,
+       CASE WHEN status = 'OPEN' THEN 0 ELSE COALESCE(pnl, 0) END,
+       opened_at,
+       CASE WHEN status = 'OPEN' THEN NULL ELSE closed_at END
+FROM positions
+WHERE id NOT IN (SELECT position_id FROM position_lots);
@@ -0,0 +1,9 @@
+-- Migration: Trade direction on decisions, sessions and positions
+-- Purpose: Size, stop and close SHORT trades (existing rows are longs)
+
+ALTER TABLE decisions ADD COLUMN direction TEXT NOT NULL DEFAULT 'LONG' CHECK (direction IN ('LONG', 'SHORT'));
+ALTER TABLE trade_sessions ADD COLUMN direction TEXT NOT NULL DEFAULT 'LONG' CHECK (direction IN ('LONG', 'SHORT'));
+ALTER TABLE positions ADD COLUMN direction TEXT NOT NULL DEFAULT 'LONG' CHECK (direction IN ('LONG', 'SHORT'));
+
+-- Sessions already record their side in the strategy
+UPDATE trade_sessions SET direction = 'SHORT' WHERE strategy = 'SHORT_BREAKOUT';
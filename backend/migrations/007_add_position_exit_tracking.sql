@@ -0,0 +1,6 @@
+-- Migration: Exit tracking on positions
+-- Purpose: Daily reconciliation checks bars against the stop since it was
+-- last moved, and the Donchian exit of the system the trade was entered on
+
+ALTER TABLE positions ADD COLUMN exit_lookback INTEGER DEFAULT 10;
+ALTER TABLE positions ADD COLUMN stop_date TEXT;
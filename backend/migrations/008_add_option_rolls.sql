@@ -0,0 +1,18 @@
+-- Migration: Option roll tracking on positions
+-- Purpose: Time exits act on the session's roll threshold and mode, and a
+-- roll closes a position and opens its replacement as a linked pair whose
+-- cumulative net debit (cost_basis) carries across every roll
+
+ALTER TABLE positions ADD COLUMN roll_threshold_dte INTEGER DEFAULT 21;
+ALTER TABLE positions ADD COLUMN time_exit_mode TEXT DEFAULT 'Close' CHECK (time_exit_mode IN ('None', 'Close', 'Roll'));
+ALTER TABLE positions ADD COLUMN cost_basis REAL;
+ALTER TABLE positions ADD COLUMN rolled_from_id INTEGER REFERENCES positions(id);
+ALTER TABLE positions ADD COLUMN rolled_to_id INTEGER REFERENCES positions(id);
+
+-- Positions opened from a session take its time exit settings
+UPDATE positions SET
+    roll_threshold_dte = COALESCE((SELECT s.roll_threshold_dte FROM trade_sessions s WHERE s.entry_decision_id = positions.decision_id), 21),
+    time_exit_mode = COALESCE((SELECT s.time_exit_mode FROM trade_sessions s WHERE s.entry_decision_id = positions.decision_id), 'Close')
+WHERE instrument_type = 'OPTION';
+
+UPDATE positions SET cost_basis = net_debit WHERE cost_basis IS NULL AND net_debit IS NOT NULL;
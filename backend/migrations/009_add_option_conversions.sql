@@ -0,0 +1,6 @@
+-- Migration: Stock from exercised or assigned options
+-- Purpose: An option position settled at expiration can leave shares behind
+-- (an assigned put, an exercised call). Those shares open a stock position
+-- that points back at the option position it came from.
+
+ALTER TABLE positions ADD COLUMN converted_from_id INTEGER REFERENCES positions(id);
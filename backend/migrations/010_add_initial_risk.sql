@@ -0,0 +1,25 @@
+-- Migration: Initial risk (1R) and breakout system on positions
+-- Purpose: risk_dollars follows the stop and goes to 0 at close, so the
+-- risk a trade was sized with is kept separately for R-multiple analytics
+
+ALTER TABLE positions ADD COLUMN initial_risk REAL;
+ALTER TABLE positions ADD COLUMN breakout_system TEXT;
+
+-- Stock positions: every lot's shares at its distance to the initial stop
+UPDATE positions SET initial_risk = (
+    SELECT SUM(l.shares * ABS(l.entry_price - positions.initial_stop))
+    FROM position_lots l WHERE l.position_id = positions.id
+)
+WHERE initial_risk IS NULL AND COALESCE(instrument_type, 'STOCK') = 'STOCK';
+
+-- Options (and stock without lots): the risk still on the row, else the max loss
+UPDATE positions SET initial_risk = COALESCE(NULLIF(risk_dollars, 0), max_loss)
+WHERE initial_risk IS NULL OR initial_risk = 0;
+
+-- Positions opened from a session take its breakout system
+UPDATE positions SET breakout_system = (
+    SELECT CASE s.entry_lookback WHEN 20 THEN 'SYSTEM_1' WHEN 55 THEN 'SYSTEM_2' ELSE 'CUSTOM' END
+    FROM trade_sessions s
+    WHERE s.entry_decision_id = positions.decision_id AND s.entry_lookback > 0
+)
+WHERE breakout_system IS NULL;
@@ -0,0 +1,8 @@
+-- Migration: Price excursion (MAE/MFE) tracking on positions
+-- Purpose: keep the lowest and highest price seen since entry, from
+-- imported daily bars or manual marks, to measure how far each trade
+-- went against and in favour of its entry
+
+ALTER TABLE positions ADD COLUMN excursion_low REAL;
+ALTER TABLE positions ADD COLUMN excursion_high REAL;
+ALTER TABLE positions ADD COLUMN excursion_date TEXT;
@@ -0,0 +1,33 @@
+-- Migration: Broker fill import
+-- Purpose: record executions imported from broker reports (IBKR Flex) so
+-- re-imports are skipped and unmatched fills can be reviewed, and charge
+-- their commissions to the positions they open, add to or close
+
+CREATE TABLE IF NOT EXISTS broker_fills (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	broker TEXT NOT NULL,
+	exec_id TEXT NOT NULL,
+	account TEXT,
+	ticker TEXT NOT NULL,
+	asset_class TEXT,
+	side TEXT NOT NULL CHECK (side IN ('BUY', 'SELL')),
+	quantity INTEGER NOT NULL,
+	price REAL NOT NULL,
+	commission REAL DEFAULT 0,
+	trade_date TEXT NOT NULL,
+	trade_time TEXT,
+	status TEXT NOT NULL CHECK (status IN ('APPLIED', 'UNMATCHED', 'IGNORED')),
+	action TEXT,
+	position_id INTEGER,
+	detail TEXT,
+	source TEXT,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(broker, exec_id),
+	FOREIGN KEY (position_id) REFERENCES positions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_broker_fills_status ON broker_fills(status);
+
+-- Commissions paid on a position, and how much of it trade_history has been charged
+ALTER TABLE positions ADD COLUMN commissions REAL DEFAULT 0;
+ALTER TABLE positions ADD COLUMN commissions_booked REAL DEFAULT 0;
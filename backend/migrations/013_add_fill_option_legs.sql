@@ -0,0 +1,6 @@
+-- Migration: Option legs on imported broker fills
+-- Purpose: keep the option leg (type, strike, expiration) parsed from the
+-- option symbol of an imported fill, so option fills can be reconciled
+-- with the legs of option positions
+
+ALTER TABLE broker_fills ADD COLUMN option_leg TEXT;
@@ -0,0 +1,13 @@
+-- Migration: Initial risk (1R) per lot
+-- Purpose: an add-on's 1R is measured to the stop it was filled with, not
+-- the position's initial stop, so each exit's trade_history risk is taken
+-- from the lots it sold
+
+ALTER TABLE position_lots ADD COLUMN initial_risk REAL;
+
+-- Unit 1 was filled with the initial stop; older add-ons keep NULL and
+-- fall back to their distance to the initial stop
+UPDATE position_lots SET initial_risk = shares * ABS(entry_price - (
+    SELECT p.initial_stop FROM positions p WHERE p.id = position_lots.position_id
+))
+WHERE initial_risk IS NULL AND unit_num = 1;
@@ -0,0 +1,44 @@
+-- Migration: Pending broker fills
+-- Purpose: fills are logged as PENDING before they are applied, so one
+-- interrupted while its position was being changed is held for review
+-- instead of being applied again; SQLite cannot alter a CHECK constraint,
+-- so the table is rebuilt with the new status
+
+CREATE TABLE IF NOT EXISTS broker_fills_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	broker TEXT NOT NULL,
+	exec_id TEXT NOT NULL,
+	account TEXT,
+	ticker TEXT NOT NULL,
+	asset_class TEXT,
+	option_leg TEXT,
+	side TEXT NOT NULL CHECK (side IN ('BUY', 'SELL')),
+	quantity INTEGER NOT NULL,
+	price REAL NOT NULL,
+	commission REAL DEFAULT 0,
+	trade_date TEXT NOT NULL,
+	trade_time TEXT,
+	status TEXT NOT NULL CHECK (status IN ('APPLIED', 'UNMATCHED', 'IGNORED', 'PENDING')),
+	action TEXT,
+	position_id INTEGER,
+	detail TEXT,
+	source TEXT,
+	imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE(broker, exec_id),
+	FOREIGN KEY (position_id) REFERENCES positions(id)
+);
+
+INSERT INTO broker_fills_new (
+	id, broker, exec_id, account, ticker, asset_class, option_leg, side, quantity, price,
+	commission, trade_date, trade_time, status, action, position_id, detail, source, imported_at
+)
+SELECT
+	id, broker, exec_id, account, ticker, asset_class, option_leg, side, quantity, price,
+	commission, trade_date, trade_time, status, action, position_id, detail, source, imported_at
+FROM broker_fills;
+
+DROP TABLE broker_fills;
+
+ALTER TABLE broker_fills_new RENAME TO broker_fills;
+
+CREATE INDEX IF NOT EXISTS idx_broker_fills_status ON broker_fills(status);
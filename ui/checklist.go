@@ -11,6 +11,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/marketdata"
 )
 
 func buildChecklistScreen(state *AppState) fyne.CanvasObject {
@@ -108,6 +109,17 @@ func buildChecklistScreen(state *AppState) fyne.CanvasObject {
 	})
 	trendHelp.Importance = widget.HighImportance
 
+	// Auto-tick trend from stored bars when the ticker actually broke out
+	if !state.sampleMode && activeSession.Status != "COMPLETED" {
+		if signal := detectSessionBreakout(state, activeSession.Ticker, activeSession.EntryLookback, activeSession.ExitLookback); signal != nil {
+			req := domain.ChecklistRequest{Direction: activeSession.Direction}
+			if domain.ApplyBreakoutToChecklist(&req, signal) {
+				trendCheck.SetChecked(true)
+				trendCheck.SetText(fmt.Sprintf("Trend Confirmed (RISK_REQ) - %d-bar breakout %s", signal.EntryLookback, signal.Date))
+			}
+		}
+	}
+
 	liquidityCheck := widget.NewCheck("Liquidity OK (OPT_REQ)", nil)
 	liquidityCheck.SetChecked(false)
 	liquidityHelp := widget.NewButtonWithIcon("", theme.InfoIcon(), func() {
@@ -452,3 +464,27 @@ func buildChecklistScreen(state *AppState) fyne.CanvasObject {
 
 	return container.NewScroll(content)
 }
+
+// detectSessionBreakout checks stored bars for a Donchian breakout using the
+// session's lookbacks (System 2 when unset). Returns nil if bars are missing.
+func detectSessionBreakout(state *AppState, ticker string, entryLookback, exitLookback int) *domain.BreakoutSignal {
+	params, _ := domain.GetBreakoutParams(domain.BreakoutSystemTwo)
+	if entryLookback == 20 {
+		params, _ = domain.GetBreakoutParams(domain.BreakoutSystemOne)
+	} else if entryLookback > 0 {
+		params.EntryLookback = entryLookback
+	}
+	if exitLookback > 0 {
+		params.ExitLookback = exitLookback
+	}
+
+	bars, err := marketdata.NewDBBarProvider(state.db).GetDailyBars(ticker)
+	if err != nil {
+		return nil
+	}
+	signal, err := domain.DetectBreakout(ticker, bars, params)
+	if err != nil {
+		return nil
+	}
+	return signal
+}
@@ -29,6 +29,7 @@ func buildDashboardScreen(state *AppState) fyne.CanvasObject {
 	positionsContainer := container.NewStack()
 	heatContainer := container.NewStack()
 	candidatesContainer := container.NewStack()
+	equityCurveContainer := container.NewStack()
 
 	// Function to rebuild all cards (declare var first for closure)
 	var refreshDashboard func()
@@ -38,16 +39,18 @@ func buildDashboardScreen(state *AppState) fyne.CanvasObject {
 		positionsContainer.Objects = []fyne.CanvasObject{buildPositionsCard(state)}
 		heatContainer.Objects = []fyne.CanvasObject{buildHeatCard(state)}
 		candidatesContainer.Objects = []fyne.CanvasObject{buildCandidatesCard(state, refreshDashboard)}
+		equityCurveContainer.Objects = []fyne.CanvasObject{buildEquityCurveCard(state)}
 		settingsContainer.Refresh()
 		positionsContainer.Refresh()
 		heatContainer.Refresh()
 		candidatesContainer.Refresh()
+		equityCurveContainer.Refresh()
 	}
 
 	// Build initial cards
 	refreshDashboard()
 
-	// Layout: 2x2 grid of cards
+	// Layout: 2x2 grid of cards with the equity curve across the bottom
 	topRow := container.NewGridWithColumns(2,
 		settingsContainer,
 		heatContainer,
@@ -62,6 +65,7 @@ func buildDashboardScreen(state *AppState) fyne.CanvasObject {
 		container.NewPadded(title),
 		topRow,
 		bottomRow,
+		equityCurveContainer,
 		layout.NewSpacer(),
 	)
 
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/yourusername/trading-engine/internal/analytics"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+)
+
+// equityCurveChart draws the account's daily equity with its running peak,
+// and marks the max drawdown's peak and trough
+type equityCurveChart struct {
+	widget.BaseWidget
+	curve *analytics.EquityCurve
+}
+
+// newEquityCurveChart creates a chart for curve (nil draws an empty chart)
+func newEquityCurveChart(curve *analytics.EquityCurve) *equityCurveChart {
+	chart := &equityCurveChart{curve: curve}
+	chart.ExtendBaseWidget(chart)
+	return chart
+}
+
+// CreateRenderer implements fyne.Widget
+func (c *equityCurveChart) CreateRenderer() fyne.WidgetRenderer {
+	return &equityCurveRenderer{chart: c}
+}
+
+// equityCurveRenderer rebuilds the chart's lines and labels on every layout
+type equityCurveRenderer struct {
+	chart   *equityCurveChart
+	objects []fyne.CanvasObject
+}
+
+func (r *equityCurveRenderer) Layout(size fyne.Size) {
+	r.objects = r.draw(size)
+}
+
+func (r *equityCurveRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(520, 240)
+}
+
+func (r *equityCurveRenderer) Refresh() {
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *equityCurveRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *equityCurveRenderer) Destroy() {}
+
+// draw lays out the chart for size
+func (r *equityCurveRenderer) draw(size fyne.Size) []fyne.CanvasObject {
+	background := canvas.NewRectangle(theme.InputBackgroundColor())
+	background.Resize(size)
+	objects := []fyne.CanvasObject{background}
+
+	curve := r.chart.curve
+	plotW := size.Width - riskGraphMarginLeft - riskGraphMarginRight
+	plotH := size.Height - riskGraphMarginTop - riskGraphMarginBottom
+	if curve == nil || len(curve.Daily) < 2 || plotW <= 0 || plotH <= 0 {
+		return objects
+	}
+
+	minEquity, maxEquity := curve.Starting, curve.Starting
+	for _, d := range curve.Daily {
+		minEquity = math.Min(minEquity, d.Equity)
+		maxEquity = math.Max(maxEquity, d.Peak)
+	}
+	pad := (maxEquity - minEquity) * 0.05
+	if pad == 0 {
+		pad = 1
+	}
+	minEquity, maxEquity = minEquity-pad, maxEquity+pad
+
+	last := len(curve.Daily) - 1
+	x := func(i int) float32 {
+		return riskGraphMarginLeft + float32(i)/float32(last)*plotW
+	}
+	y := func(equity float64) float32 {
+		return riskGraphMarginTop + float32((maxEquity-equity)/(maxEquity-minEquity))*plotH
+	}
+	bottom := riskGraphMarginTop + plotH
+
+	// Starting equity line and axis labels
+	objects = append(objects, riskGraphLine(x(0), y(curve.Starting), x(last), y(curve.Starting), riskGraphGridColor, 1))
+	objects = append(objects,
+		riskGraphText(fmt.Sprintf("$%.0f", maxEquity), 4, y(maxEquity), theme.ForegroundColor()),
+		riskGraphText(fmt.Sprintf("$%.0f", curve.Starting), 4, y(curve.Starting)-riskGraphTextSize, theme.ForegroundColor()),
+		riskGraphText(fmt.Sprintf("$%.0f", minEquity), 4, y(minEquity)-riskGraphTextSize-4, theme.ForegroundColor()),
+		riskGraphText(curve.Daily[0].Date, x(0), bottom+4, theme.ForegroundColor()),
+		riskGraphText(curve.Daily[last].Date, x(last)-64, bottom+4, theme.ForegroundColor()),
+	)
+
+	// Peak, then equity on top of it
+	for i := 1; i <= last; i++ {
+		from, to := curve.Daily[i-1], curve.Daily[i]
+		objects = append(objects, riskGraphLine(x(i-1), y(from.Peak), x(i), y(to.Peak), riskGraphGridColor, 1))
+	}
+	for i := 1; i <= last; i++ {
+		from, to := curve.Daily[i-1], curve.Daily[i]
+		objects = append(objects, riskGraphLine(x(i-1), y(from.Equity), x(i), y(to.Equity), BritishRacingGreen, 2))
+	}
+
+	// Max drawdown: drop from its peak to the trough
+	if curve.MaxDrawdown > 0 {
+		for i, d := range curve.Daily {
+			if d.Date != curve.MaxDrawdownTrough {
+				continue
+			}
+			objects = append(objects,
+				riskGraphLine(x(i), y(d.Peak), x(i), y(d.Equity), BannerRed, 1),
+				riskGraphText(fmt.Sprintf("Max DD -%.1f%%", curve.MaxDrawdownPct), x(i)+3, y(d.Equity), BannerRed),
+			)
+			break
+		}
+	}
+
+	legend := riskGraphText("— Equity", riskGraphMarginLeft, 4, BritishRacingGreen)
+	objects = append(objects, legend,
+		riskGraphText("— Peak", riskGraphMarginLeft+legend.MinSize().Width+16, 4, riskGraphGridColor))
+
+	return objects
+}
+
+// buildEquityCurveCard shows the account's equity curve with its drawdown
+// and this year's return
+func buildEquityCurveCard(state *AppState) fyne.CanvasObject {
+	title := widget.NewLabelWithStyle("Equity Curve", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	var curve *analytics.EquityCurve
+	var err error
+	if state.sampleMode {
+		curve = CreateSampleEquityCurve()
+	} else {
+		curve, err = analytics.LoadEquityCurve(state.db, analytics.EquityCurveOptions{
+			MarkToMarket: true,
+			Provider:     marketdata.NewCSVBarProvider(marketdata.DefaultPriceDir),
+		})
+		if err != nil {
+			return container.NewVBox(
+				title,
+				widget.NewLabel("Error loading equity curve"),
+			)
+		}
+	}
+
+	if len(curve.Daily) < 2 {
+		return container.NewPadded(
+			container.NewVBox(
+				title,
+				widget.NewSeparator(),
+				widget.NewLabel("No trading history yet"),
+			),
+		)
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("$%.2f → $%.2f (%+.2f%%)   Max drawdown: $%.2f (%.2f%%)",
+		curve.Starting, curve.Final, curve.TotalReturnPct, curve.MaxDrawdown, curve.MaxDrawdownPct))
+	details := fmt.Sprintf("Longest drawdown: %d days", curve.LongestDrawdownDays)
+	if curve.CurrentDrawdownDays > 0 {
+		details += fmt.Sprintf("   Current drawdown: %d days", curve.CurrentDrawdownDays)
+	}
+	if n := len(curve.Monthly); n > 0 {
+		details += fmt.Sprintf("   %s: %+.2f%%", curve.Monthly[n-1].Period, curve.Monthly[n-1].ReturnPct)
+	}
+
+	card := container.NewVBox(
+		title,
+		widget.NewSeparator(),
+		summary,
+		widget.NewLabel(details),
+		newEquityCurveChart(curve),
+	)
+
+	return container.NewPadded(card)
+}
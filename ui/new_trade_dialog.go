@@ -302,8 +302,8 @@ func createOptionsSession(state *AppState, ticker, direction, system string, res
 				"Strategy: %s\n"+
 				"Expiration: %s (%d DTE)\n"+
 				"Net Debit: $%.2f\n"+
-				"Max Profit: $%.2f\n"+
-				"Max Loss: $%.2f\n\n"+
+				"%s\n"+
+				"%s\n\n"+
 				"Navigated to Checklist tab to begin evaluation.",
 			session.SessionNum,
 			ticker,
@@ -312,8 +312,8 @@ func createOptionsSession(state *AppState, ticker, direction, system string, res
 			result.PrimaryExpirationDate,
 			result.DTE,
 			result.NetDebit,
-			result.MaxProfit,
-			result.MaxLoss,
+			formatPayoffLimit("Max Profit", result.MaxProfit, result.MaxProfitUnlimited),
+			formatPayoffLimit("Max Loss", result.MaxLoss, result.MaxLossUnlimited),
 		),
 		state.window,
 	)
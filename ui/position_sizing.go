@@ -10,6 +10,8 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/yourusername/trading-engine/internal/domain"
+	"github.com/yourusername/trading-engine/internal/marketdata"
+	"github.com/yourusername/trading-engine/internal/options"
 )
 
 func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
@@ -80,6 +82,20 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 		atrEntry.SetText("2.45") // Sample data
 	}
 
+	// Auto-fill N from stored price bars (20-day Wilder ATR)
+	var storedATR *domain.ATRResult
+	storedATRText := ""
+	if !state.sampleMode {
+		if bars, err := marketdata.NewDBBarProvider(state.db).GetDailyBars(activeSession.Ticker); err == nil {
+			if result, err := domain.CalculateATR(bars, domain.DefaultATRPeriod); err == nil {
+				storedATR = result
+				storedATRText = fmt.Sprintf("%.4f", result.ATR)
+				atrEntry.SetText(storedATRText)
+				atrLabel.SetText(fmt.Sprintf("ATR (N): stored bars as of %s", result.AsOfDate))
+			}
+		}
+	}
+
 	kLabel := widget.NewLabel("K Multiple (Stop Distance):")
 	kEntry := widget.NewEntry()
 	kEntry.SetText("2.0")
@@ -87,7 +103,7 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 	// Options-specific inputs (hidden by default)
 	deltaLabel := widget.NewLabel("Delta:")
 	deltaEntry := widget.NewEntry()
-	deltaEntry.SetPlaceHolder("0.70")
+	deltaEntry.SetPlaceHolder("0.70 (blank = from option legs)")
 
 	contractPriceLabel := widget.NewLabel("Contract Price:")
 	contractPriceEntry := widget.NewEntry()
@@ -209,10 +225,18 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 			return
 		}
 
-		atr, err := strconv.ParseFloat(atrEntry.Text, 64)
-		if err != nil {
-			resultsLabel.SetText("❌ Invalid ATR")
-			return
+		// Use the stored N unless it was overwritten by hand
+		var atr float64
+		atrDate := ""
+		if storedATR != nil && atrEntry.Text == storedATRText {
+			atr = storedATR.ATR
+			atrDate = storedATR.AsOfDate
+		} else {
+			atr, err = strconv.ParseFloat(atrEntry.Text, 64)
+			if err != nil {
+				resultsLabel.SetText("❌ Invalid ATR")
+				return
+			}
 		}
 
 		k, err := strconv.ParseFloat(kEntry.Text, 64)
@@ -257,11 +281,13 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 		method := methodSelect.Selected
 
 		sizingReq := domain.SizingRequest{
-			Equity:  equity,
-			RiskPct: riskPctDecimal,
-			Entry:   entry,
-			ATR:     atr,
-			K:       int(k),
+			Direction: activeSession.Direction,
+			Equity:    equity,
+			RiskPct:   riskPctDecimal,
+			Entry:     entry,
+			ATR:       atr,
+			ATRDate:   atrDate,
+			K:         k,
 		}
 
 		// Set method and optional params
@@ -270,12 +296,23 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 			sizingReq.Method = "stock"
 
 		case "Options (Delta-ATR)":
+			sizingReq.Method = "opt-delta-atr"
+			if deltaEntry.Text == "" {
+				// Derive delta from the session's long option leg
+				leg := sizingLeg(activeSession.LegsJSON)
+				if leg == nil {
+					resultsLabel.SetText("❌ Enter a delta or build the option legs first")
+					return
+				}
+				sizingReq.Leg = leg
+				sizingReq.Pricing = options.Market{Rate: options.DefaultRiskFreeRate}
+				break
+			}
 			delta, err := strconv.ParseFloat(deltaEntry.Text, 64)
 			if err != nil {
 				resultsLabel.SetText("❌ Invalid delta")
 				return
 			}
-			sizingReq.Method = "opt-delta-atr"
 			sizingReq.Delta = delta
 
 		case "Options (Contracts)":
@@ -296,20 +333,24 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 			return
 		}
 
-		// Calculate add-on prices (up to 3 additional units)
-		addPrice1 := entry + (addStep * atr)
-		addPrice2 := entry + (addStep * 2.0 * atr)
-		addPrice3 := entry + (addStep * 3.0 * atr)
+		// Calculate add-on prices (up to 3 additional units); shorts add below entry
+		sign, op := domain.DirectionSign(result.Direction), "+"
+		if sign < 0 {
+			op = "-"
+		}
+		addPrice1 := entry + sign*(addStep*atr)
+		addPrice2 := entry + sign*(addStep*2.0*atr)
+		addPrice3 := entry + sign*(addStep*3.0*atr)
 
 		addOnText := fmt.Sprintf("📊 Add-On Prices (Every %.1f × N):\n", addStep)
 		if maxUnits > 1 {
-			addOnText += fmt.Sprintf("  Add 1: $%.2f (Entry + %.1fN)\n", addPrice1, addStep)
+			addOnText += fmt.Sprintf("  Add 1: $%.2f (Entry %s %.1fN)\n", addPrice1, op, addStep)
 		}
 		if maxUnits > 2 {
-			addOnText += fmt.Sprintf("  Add 2: $%.2f (Entry + %.1fN)\n", addPrice2, addStep*2.0)
+			addOnText += fmt.Sprintf("  Add 2: $%.2f (Entry %s %.1fN)\n", addPrice2, op, addStep*2.0)
 		}
 		if maxUnits > 3 {
-			addOnText += fmt.Sprintf("  Add 3: $%.2f (Entry + %.1fN)\n", addPrice3, addStep*3.0)
+			addOnText += fmt.Sprintf("  Add 3: $%.2f (Entry %s %.1fN)\n", addPrice3, op, addStep*3.0)
 		}
 		addOnText += fmt.Sprintf("\nCurrent Units: %d / %d", currentUnits, maxUnits)
 		addOnPricesLabel.SetText(addOnText)
@@ -318,8 +359,8 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 		if !state.sampleMode {
 			methodStr := result.Method
 			deltaValue := 0.0
-			if method == "Options (Delta-ATR)" && sizingReq.Delta > 0 {
-				deltaValue = sizingReq.Delta
+			if method == "Options (Delta-ATR)" && result.Delta > 0 {
+				deltaValue = result.Delta
 			}
 			err = state.db.UpdateSessionSizingWithPyramid(
 				activeSession.ID,
@@ -344,6 +385,11 @@ func buildPositionSizingScreen(state *AppState) fyne.CanvasObject {
 				return
 			}
 
+			if err := state.db.UpdateSessionSizingATRDate(activeSession.ID, atrDate); err != nil {
+				resultsLabel.SetText(fmt.Sprintf("❌ Failed to save session: %v", err))
+				return
+			}
+
 			// Reload session to get updated state
 			updatedSession, err := state.db.GetSession(activeSession.ID)
 			if err != nil {
@@ -365,10 +411,19 @@ Initial Stop: $%.2f
 `,
 			result.Method, ticker, result.RiskDollars, result.StopDistance, result.InitialStop)
 
+		if result.ATRDate != "" {
+			resultsText += fmt.Sprintf("N: %.4f (stored bars as of %s)\n\n", result.ATR, result.ATRDate)
+		}
+
 		if result.Shares > 0 {
 			resultsText += fmt.Sprintf("SHARES TO BUY: %d shares\n\n", result.Shares)
 		}
 
+		if result.IV > 0 {
+			resultsText += fmt.Sprintf("Delta: %.2f (from %s %.2f leg, IV %.1f%%)\n\n",
+				result.Delta, sizingReq.Leg.Type, sizingReq.Leg.Strike, result.IV*100)
+		}
+
 		if result.Contracts > 0 {
 			resultsText += fmt.Sprintf("CONTRACTS TO BUY: %d contracts\n\n", result.Contracts)
 		}
@@ -423,6 +478,21 @@ Initial Stop: $%.2f
 	return container.NewScroll(content)
 }
 
+// sizingLeg returns the first BUY leg stored on the session, or nil
+func sizingLeg(legsJSON string) *options.Leg {
+	legs, err := DeserializeLegs(legsJSON)
+	if err != nil {
+		return nil
+	}
+	for _, leg := range legs {
+		if leg.Action == "BUY" {
+			l := options.Leg(leg)
+			return &l
+		}
+	}
+	return nil
+}
+
 // getMethodExplanation returns the explanation text for each position sizing method
 func getMethodExplanation(method string) string {
 	switch method {
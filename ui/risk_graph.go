@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/yourusername/trading-engine/internal/options"
+	"github.com/yourusername/trading-engine/internal/storage"
+)
+
+// Risk graph colors
+var (
+	riskGraphTodayColor  = color.RGBA{R: 0x19, G: 0x76, B: 0xD2, A: 0xFF} // #1976D2 - Blue
+	riskGraphFutureColor = color.RGBA{R: 0xF5, G: 0x7C, B: 0x00, A: 0xFF} // #F57C00 - Orange
+	riskGraphGridColor   = color.RGBA{R: 0x9E, G: 0x9E, B: 0x9E, A: 0xFF} // #9E9E9E - Gray
+)
+
+// Risk graph plot margins (room for the axis labels)
+const (
+	riskGraphMarginLeft   = 70
+	riskGraphMarginRight  = 12
+	riskGraphMarginTop    = 24
+	riskGraphMarginBottom = 24
+	riskGraphTextSize     = 11
+)
+
+// riskGraphChart draws P&L against the underlying price for a set of legs:
+// one line per curve (expiry, today, future date) with the zero line,
+// breakevens, current underlying and stop marked
+type riskGraphChart struct {
+	widget.BaseWidget
+	graph *options.RiskGraph
+}
+
+// newRiskGraphChart creates a chart for graph (nil draws an empty chart)
+func newRiskGraphChart(graph *options.RiskGraph) *riskGraphChart {
+	chart := &riskGraphChart{graph: graph}
+	chart.ExtendBaseWidget(chart)
+	return chart
+}
+
+// SetGraph replaces the graph being drawn
+func (c *riskGraphChart) SetGraph(graph *options.RiskGraph) {
+	c.graph = graph
+	c.Refresh()
+}
+
+// CreateRenderer implements fyne.Widget
+func (c *riskGraphChart) CreateRenderer() fyne.WidgetRenderer {
+	return &riskGraphRenderer{chart: c}
+}
+
+// riskGraphRenderer rebuilds the chart's lines and labels on every layout
+type riskGraphRenderer struct {
+	chart   *riskGraphChart
+	objects []fyne.CanvasObject
+}
+
+func (r *riskGraphRenderer) Layout(size fyne.Size) {
+	r.objects = r.draw(size)
+}
+
+func (r *riskGraphRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(520, 320)
+}
+
+func (r *riskGraphRenderer) Refresh() {
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *riskGraphRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *riskGraphRenderer) Destroy() {}
+
+// draw lays out the chart for size
+func (r *riskGraphRenderer) draw(size fyne.Size) []fyne.CanvasObject {
+	background := canvas.NewRectangle(theme.InputBackgroundColor())
+	background.Resize(size)
+	objects := []fyne.CanvasObject{background}
+
+	graph := r.chart.graph
+	plotW := size.Width - riskGraphMarginLeft - riskGraphMarginRight
+	plotH := size.Height - riskGraphMarginTop - riskGraphMarginBottom
+	if graph == nil || len(graph.Curves) == 0 || plotW <= 0 || plotH <= 0 || graph.High <= graph.Low {
+		return objects
+	}
+
+	// Keep the zero line in view and leave a little headroom
+	minPnL, maxPnL := math.Min(graph.MinPnL, 0), math.Max(graph.MaxPnL, 0)
+	pad := (maxPnL - minPnL) * 0.05
+	if pad == 0 {
+		pad = 1
+	}
+	minPnL, maxPnL = minPnL-pad, maxPnL+pad
+
+	x := func(price float64) float32 {
+		return riskGraphMarginLeft + float32((price-graph.Low)/(graph.High-graph.Low))*plotW
+	}
+	y := func(pnl float64) float32 {
+		return riskGraphMarginTop + float32((maxPnL-pnl)/(maxPnL-minPnL))*plotH
+	}
+	top, bottom := float32(riskGraphMarginTop), riskGraphMarginTop+plotH
+
+	// Zero line and P&L axis labels
+	objects = append(objects, riskGraphLine(x(graph.Low), y(0), x(graph.High), y(0), riskGraphGridColor, 1))
+	objects = append(objects,
+		riskGraphText(fmt.Sprintf("$%.0f", maxPnL), 4, y(maxPnL), theme.ForegroundColor()),
+		riskGraphText("$0", 4, y(0)-riskGraphTextSize, theme.ForegroundColor()),
+		riskGraphText(fmt.Sprintf("$%.0f", minPnL), 4, y(minPnL)-riskGraphTextSize-4, theme.ForegroundColor()),
+	)
+
+	// Price axis labels
+	objects = append(objects,
+		riskGraphText(fmt.Sprintf("%.2f", graph.Low), x(graph.Low), bottom+4, theme.ForegroundColor()),
+		riskGraphText(fmt.Sprintf("%.2f", graph.High), x(graph.High)-40, bottom+4, theme.ForegroundColor()),
+	)
+
+	// Markers: breakevens, current underlying and stop
+	marker := func(price float64, label string, c color.Color, labelY float32) {
+		if price < graph.Low || price > graph.High {
+			return
+		}
+		objects = append(objects,
+			riskGraphLine(x(price), top, x(price), bottom, c, 1),
+			riskGraphText(label, x(price)+3, labelY, c),
+		)
+	}
+	if graph.Payoff != nil {
+		for _, be := range graph.Payoff.Breakevens {
+			marker(be, fmt.Sprintf("BE %.2f", be), riskGraphGridColor, bottom-riskGraphTextSize-4)
+		}
+	}
+	if graph.Spot > 0 {
+		marker(graph.Spot, fmt.Sprintf("Spot %.2f", graph.Spot), riskGraphTodayColor, top)
+	}
+	if graph.Stop > 0 {
+		marker(graph.Stop, fmt.Sprintf("Stop %.2f", graph.Stop), BannerRed, top+riskGraphTextSize+4)
+	}
+
+	// Curves, with the legend along the top
+	legendX := float32(riskGraphMarginLeft)
+	for _, curve := range graph.Curves {
+		c := riskGraphCurveColor(curve.Label)
+		for i := 1; i < len(curve.Points); i++ {
+			from, to := curve.Points[i-1], curve.Points[i]
+			objects = append(objects, riskGraphLine(x(from.Price), y(from.PnL), x(to.Price), y(to.PnL), c, 2))
+		}
+
+		legend := riskGraphText(fmt.Sprintf("— %s %s", strings.ToUpper(curve.Label[:1])+curve.Label[1:], curve.Date), legendX, 4, c)
+		objects = append(objects, legend)
+		legendX += legend.MinSize().Width + 16
+	}
+
+	return objects
+}
+
+// riskGraphCurveColor picks the line color for a curve label
+func riskGraphCurveColor(label string) color.Color {
+	switch label {
+	case "today":
+		return riskGraphTodayColor
+	case "future":
+		return riskGraphFutureColor
+	default:
+		return BritishRacingGreen
+	}
+}
+
+// riskGraphLine creates a line between two points
+func riskGraphLine(x1, y1, x2, y2 float32, c color.Color, width float32) *canvas.Line {
+	line := canvas.NewLine(c)
+	line.StrokeWidth = width
+	line.Position1 = fyne.NewPos(x1, y1)
+	line.Position2 = fyne.NewPos(x2, y2)
+	return line
+}
+
+// riskGraphText creates a small label at a position
+func riskGraphText(text string, x, y float32, c color.Color) *canvas.Text {
+	label := canvas.NewText(text, c)
+	label.TextSize = riskGraphTextSize
+	label.Move(fyne.NewPos(x, y))
+	label.Resize(label.MinSize())
+	return label
+}
+
+// riskGraphPreview holds the legs a strategy builder last previewed so its
+// Risk Graph button charts what is on screen
+type riskGraphPreview struct {
+	legs       []storage.OptionLeg
+	underlying float64
+}
+
+// set records the previewed legs
+func (p *riskGraphPreview) set(legs []storage.OptionLeg, underlying float64) {
+	p.legs, p.underlying = legs, underlying
+}
+
+// button creates the builder's Risk Graph button
+func (p *riskGraphPreview) button(parent fyne.Window) *widget.Button {
+	return newRiskGraphButton(parent, func() ([]storage.OptionLeg, float64) {
+		return p.legs, p.underlying
+	})
+}
+
+// newRiskGraphButton creates a Risk Graph button for a builder without a
+// live preview; current builds the legs from the form when tapped
+func newRiskGraphButton(parent fyne.Window, current func() ([]storage.OptionLeg, float64)) *widget.Button {
+	return widget.NewButton("📈 Risk Graph", func() {
+		legs, underlying := current()
+		showRiskGraphDialog(legs, underlying, 0, parent)
+	})
+}
+
+// showRiskGraphDialog charts the legs' P&L at expiry, today and a chosen
+// future date, marking the breakevens, underlying and stop
+func showRiskGraphDialog(legs []storage.OptionLeg, underlying, stop float64, parent fyne.Window) {
+	if len(legs) == 0 {
+		dialog.ShowError(fmt.Errorf("enter the strategy's strikes and expiration first"), parent)
+		return
+	}
+
+	pricingLegs := make([]options.Leg, len(legs))
+	for i, leg := range legs {
+		pricingLegs[i] = options.Leg(leg)
+	}
+
+	futureDateEntry := widget.NewEntry()
+	futureDateEntry.SetPlaceHolder("YYYY-MM-DD (default: halfway to expiration)")
+	ivEntry := widget.NewEntry()
+	ivEntry.SetPlaceHolder("from leg prices")
+
+	chart := newRiskGraphChart(nil)
+	summaryLabel := widget.NewLabel("")
+	summaryLabel.Wrapping = fyne.TextWrapWord
+
+	update := func() {
+		iv, _ := strconv.ParseFloat(ivEntry.Text, 64)
+		graph, err := options.BuildRiskGraph(options.RiskGraphRequest{
+			Legs: pricingLegs,
+			Market: options.Market{
+				Spot: underlying,
+				Rate: options.DefaultRiskFreeRate,
+				Vol:  iv / 100,
+			},
+			Stop:       stop,
+			FutureDate: strings.TrimSpace(futureDateEntry.Text),
+		})
+		if err != nil {
+			chart.SetGraph(nil)
+			summaryLabel.SetText(fmt.Sprintf("Cannot chart these legs: %v", err))
+			return
+		}
+		chart.SetGraph(graph)
+		summaryLabel.SetText(formatRiskGraphSummary(graph))
+	}
+	futureDateEntry.OnSubmitted = func(string) { update() }
+	ivEntry.OnSubmitted = func(string) { update() }
+	update()
+
+	controls := container.NewBorder(nil, nil, nil,
+		widget.NewButton("Update", update),
+		container.NewGridWithColumns(4,
+			widget.NewLabel("Future Date:"), futureDateEntry,
+			widget.NewLabel("IV % (all legs):"), ivEntry,
+		),
+	)
+
+	d := dialog.NewCustom("Risk Graph", "Close",
+		container.NewBorder(controls, summaryLabel, nil, nil, chart),
+		parent)
+	d.Resize(fyne.NewSize(760, 560))
+	d.Show()
+}
+
+// formatRiskGraphSummary describes the payoff under the chart
+func formatRiskGraphSummary(graph *options.RiskGraph) string {
+	summary := ""
+	if graph.Payoff != nil {
+		summary = fmt.Sprintf("%s   %s",
+			formatPayoffLimit("Max Profit", graph.Payoff.MaxProfit, graph.Payoff.UnlimitedProfit),
+			formatPayoffLimit("Max Loss", graph.Payoff.MaxLoss, graph.Payoff.UnlimitedLoss))
+		if len(graph.Payoff.Breakevens) > 0 {
+			breakevens := make([]string, len(graph.Payoff.Breakevens))
+			for i, be := range graph.Payoff.Breakevens {
+				breakevens[i] = fmt.Sprintf("$%.2f", be)
+			}
+			summary += "   Breakevens: " + strings.Join(breakevens, " / ")
+		}
+	}
+	if graph.Warning != "" {
+		summary += "\n⚠️ " + graph.Warning
+	}
+	return summary
+}
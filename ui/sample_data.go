@@ -3,6 +3,7 @@ package main
 import (
 	"time"
 
+	"github.com/yourusername/trading-engine/internal/analytics"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -235,3 +236,28 @@ func CreateSampleSettings() map[string]string {
 		"bucket_cap":     "1.5",
 	}
 }
+
+// CreateSampleEquityCurve creates a sample equity curve over the last 120 days
+func CreateSampleEquityCurve() *analytics.EquityCurve {
+	now := time.Now()
+	day := func(daysAgo int) string {
+		return now.AddDate(0, 0, -daysAgo).Format("2006-01-02")
+	}
+
+	curve, _ := analytics.BuildEquityCurve(analytics.EquityCurveRequest{
+		Starting: 100000,
+		Start:    day(120),
+		End:      day(0),
+		Realized: []storage.DailyPnL{
+			{Date: day(105), PnL: 1850},
+			{Date: day(92), PnL: -720},
+			{Date: day(80), PnL: 3400},
+			{Date: day(66), PnL: -1480},
+			{Date: day(58), PnL: -2210},
+			{Date: day(41), PnL: 4975},
+			{Date: day(27), PnL: -640},
+			{Date: day(12), PnL: 2630},
+		},
+	})
+	return curve
+}
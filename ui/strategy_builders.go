@@ -11,6 +11,7 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/yourusername/trading-engine/internal/options"
 	"github.com/yourusername/trading-engine/internal/storage"
 )
 
@@ -24,6 +25,8 @@ type StrategyBuilderResult struct {
 	NetDebit              float64
 	MaxProfit             float64
 	MaxLoss               float64
+	MaxProfitUnlimited    bool
+	MaxLossUnlimited      bool
 	BreakevenLower        float64
 	BreakevenUpper        float64
 	UnderlyingAtEntry     float64
@@ -108,6 +111,8 @@ func showSingleLegBuilder(optionType, strategyType string, parentWindow fyne.Win
 	breakevenLabel := widget.NewLabel("Breakeven: $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	// Update preview function
 	updatePreview := func() {
 		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
@@ -130,16 +135,17 @@ func showSingleLegBuilder(optionType, strategyType string, parentWindow fyne.Win
 
 		// Calculate metrics
 		netDebit := storage.CalculateNetDebit(legs)
-		_, maxLoss, _ := storage.CalculateMaxProfitLoss(strategyType, legs)
-		lowerBE, upperBE, _ := storage.CalculateBreakevens(strategyType, legs)
+		payoff := builderPayoff(legs, underlying)
+		preview.set(legs, underlying)
+		lowerBE, upperBE := payoff.Range()
 
 		// Calculate DTE
 		dte := calculateDTE(expiration)
 
 		// Update labels
 		netDebitLabel.SetText(fmt.Sprintf("Net Debit: $%.2f", netDebit))
-		maxProfitLabel.SetText("Max Profit: Unlimited")
-		maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+		maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+		maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 
 		if optionType == "CALL" {
 			breakevenLabel.SetText(fmt.Sprintf("Breakeven: $%.2f", upperBE))
@@ -198,6 +204,7 @@ func showSingleLegBuilder(optionType, strategyType string, parentWindow fyne.Win
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	// Create dialog
@@ -257,8 +264,8 @@ func showSingleLegBuilder(optionType, strategyType string, parentWindow fyne.Win
 
 			// Calculate metrics
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(strategyType, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(strategyType, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			// Create result
@@ -269,8 +276,10 @@ func showSingleLegBuilder(optionType, strategyType string, parentWindow fyne.Win
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -334,6 +343,8 @@ func showVerticalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 	breakevenLabel := widget.NewLabel("Breakeven: $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	// Update preview
 	updatePreview := func() {
 		lowerStrike, _ := strconv.ParseFloat(lowerStrikeEntry.Text, 64)
@@ -357,14 +368,16 @@ func showVerticalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 
 		// Calculate metrics
 		netDebit := storage.CalculateNetDebit(legs)
-		maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(strategyType, legs)
-		lowerBE, upperBE, _ := storage.CalculateBreakevens(strategyType, legs)
+		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+		payoff := builderPayoff(legs, underlying)
+		preview.set(legs, underlying)
+		lowerBE, upperBE := payoff.Range()
 		dte := calculateDTE(expiration)
 
 		// Update labels
 		netDebitLabel.SetText(fmt.Sprintf("Net Debit: $%.2f", netDebit))
-		maxProfitLabel.SetText(fmt.Sprintf("Max Profit: $%.2f", maxProfit))
-		maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+		maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+		maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 
 		if isBullCall {
 			breakevenLabel.SetText(fmt.Sprintf("Breakeven: $%.2f", upperBE))
@@ -376,6 +389,7 @@ func showVerticalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 	}
 
 	// Attach listeners
+	underlyingEntry.OnChanged = func(string) { updatePreview() }
 	lowerStrikeEntry.OnChanged = func(string) { updatePreview() }
 	upperStrikeEntry.OnChanged = func(string) { updatePreview() }
 	expirationEntry.OnChanged = func(string) { updatePreview() }
@@ -425,6 +439,7 @@ func showVerticalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	// Create dialog
@@ -463,8 +478,8 @@ func showVerticalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 
 			// Calculate metrics
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(strategyType, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(strategyType, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -474,8 +489,10 @@ func showVerticalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -538,6 +555,8 @@ func showCreditSpreadBuilder(strategyType string, parentWindow fyne.Window, onCo
 	breakevenLabel := widget.NewLabel("Breakeven: $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	updatePreview := func() {
 		lowerStrike, _ := strconv.ParseFloat(lowerStrikeEntry.Text, 64)
 		upperStrike, _ := strconv.ParseFloat(upperStrikeEntry.Text, 64)
@@ -558,13 +577,15 @@ func showCreditSpreadBuilder(strategyType string, parentWindow fyne.Window, onCo
 		}
 
 		netDebit := storage.CalculateNetDebit(legs)
-		maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(strategyType, legs)
-		lowerBE, upperBE, _ := storage.CalculateBreakevens(strategyType, legs)
+		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+		payoff := builderPayoff(legs, underlying)
+		preview.set(legs, underlying)
+		lowerBE, upperBE := payoff.Range()
 		dte := calculateDTE(expiration)
 
 		netCreditLabel.SetText(fmt.Sprintf("Net Credit: $%.2f", -netDebit))
-		maxProfitLabel.SetText(fmt.Sprintf("Max Profit: $%.2f", maxProfit))
-		maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+		maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+		maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 
 		if isBullPut {
 			breakevenLabel.SetText(fmt.Sprintf("Breakeven: $%.2f", lowerBE))
@@ -575,6 +596,7 @@ func showCreditSpreadBuilder(strategyType string, parentWindow fyne.Window, onCo
 		dteLabel.SetText(fmt.Sprintf("DTE: %d", dte))
 	}
 
+	underlyingEntry.OnChanged = func(string) { updatePreview() }
 	lowerStrikeEntry.OnChanged = func(string) { updatePreview() }
 	upperStrikeEntry.OnChanged = func(string) { updatePreview() }
 	expirationEntry.OnChanged = func(string) { updatePreview() }
@@ -623,6 +645,7 @@ func showCreditSpreadBuilder(strategyType string, parentWindow fyne.Window, onCo
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -657,8 +680,8 @@ func showCreditSpreadBuilder(strategyType string, parentWindow fyne.Window, onCo
 			}
 
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(strategyType, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(strategyType, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -668,8 +691,10 @@ func showCreditSpreadBuilder(strategyType string, parentWindow fyne.Window, onCo
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -737,6 +762,8 @@ func showIronCondorBuilder(parentWindow fyne.Window, onComplete func(*StrategyBu
 	breakevenLabel := widget.NewLabel("Breakevens: $0.00 / $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	updatePreview := func() {
 		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
 		putSpreadWidth, _ := strconv.ParseFloat(putSpreadWidthEntry.Text, 64)
@@ -769,13 +796,14 @@ func showIronCondorBuilder(parentWindow fyne.Window, onComplete func(*StrategyBu
 
 			// Calculate metrics
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyIronCondor, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyIronCondor, legs)
+			payoff := builderPayoff(legs, underlying)
+			preview.set(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			netCreditLabel.SetText(fmt.Sprintf("Net Credit: $%.2f", -netDebit))
-			maxProfitLabel.SetText(fmt.Sprintf("Max Profit: $%.2f", maxProfit))
-			maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+			maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+			maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 			breakevenLabel.SetText(fmt.Sprintf("Breakevens: $%.2f / $%.2f", lowerBE, upperBE))
 			dteLabel.SetText(fmt.Sprintf("DTE: %d", dte))
 		}
@@ -846,6 +874,7 @@ func showIronCondorBuilder(parentWindow fyne.Window, onComplete func(*StrategyBu
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -879,8 +908,8 @@ func showIronCondorBuilder(parentWindow fyne.Window, onComplete func(*StrategyBu
 				expiration, contracts, buyPutPremium, sellPutPremium, sellCallPremium, buyCallPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyIronCondor, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyIronCondor, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -890,8 +919,10 @@ func showIronCondorBuilder(parentWindow fyne.Window, onComplete func(*StrategyBu
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -953,6 +984,8 @@ func showIronButterflyBuilder(parentWindow fyne.Window, onComplete func(*Strateg
 	breakevenLabel := widget.NewLabel("Breakevens: $0.00 / $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	updatePreview := func() {
 		atmStrike, _ := strconv.ParseFloat(atmStrikeEntry.Text, 64)
 		wingWidth, _ := strconv.ParseFloat(wingWidthEntry.Text, 64)
@@ -978,18 +1011,21 @@ func showIronButterflyBuilder(parentWindow fyne.Window, onComplete func(*Strateg
 				buyPutPremium, sellPutPremium, sellCallPremium, buyCallPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyIronButterfly, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyIronButterfly, legs)
+			underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+			payoff := builderPayoff(legs, underlying)
+			preview.set(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			netCreditLabel.SetText(fmt.Sprintf("Net Credit: $%.2f", -netDebit))
-			maxProfitLabel.SetText(fmt.Sprintf("Max Profit: $%.2f", maxProfit))
-			maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+			maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+			maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 			breakevenLabel.SetText(fmt.Sprintf("Breakevens: $%.2f / $%.2f", lowerBE, upperBE))
 			dteLabel.SetText(fmt.Sprintf("DTE: %d", dte))
 		}
 	}
 
+	underlyingEntry.OnChanged = func(string) { updatePreview() }
 	atmStrikeEntry.OnChanged = func(string) { updatePreview() }
 	wingWidthEntry.OnChanged = func(string) { updatePreview() }
 	expirationEntry.OnChanged = func(string) { updatePreview() }
@@ -1050,6 +1086,7 @@ func showIronButterflyBuilder(parentWindow fyne.Window, onComplete func(*Strateg
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -1082,8 +1119,8 @@ func showIronButterflyBuilder(parentWindow fyne.Window, onComplete func(*Strateg
 				buyPutPremium, sellPutPremium, sellCallPremium, buyCallPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyIronButterfly, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyIronButterfly, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -1093,8 +1130,10 @@ func showIronButterflyBuilder(parentWindow fyne.Window, onComplete func(*Strateg
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -1146,6 +1185,8 @@ func showStraddleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 	breakevenLabel := widget.NewLabel("Breakevens: $0.00 / $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	updatePreview := func() {
 		atmStrike, _ := strconv.ParseFloat(atmStrikeEntry.Text, 64)
 		contracts, _ := strconv.Atoi(contractsEntry.Text)
@@ -1161,18 +1202,21 @@ func showStraddleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 			legs := storage.BuildStraddle(atmStrike, expiration, contracts, callPremium, putPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			_, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyStraddle, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyStraddle, legs)
+			underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+			payoff := builderPayoff(legs, underlying)
+			preview.set(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			netDebitLabel.SetText(fmt.Sprintf("Net Debit: $%.2f", netDebit))
-			maxProfitLabel.SetText("Max Profit: Unlimited")
-			maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+			maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+			maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 			breakevenLabel.SetText(fmt.Sprintf("Breakevens: $%.2f / $%.2f", lowerBE, upperBE))
 			dteLabel.SetText(fmt.Sprintf("DTE: %d", dte))
 		}
 	}
 
+	underlyingEntry.OnChanged = func(string) { updatePreview() }
 	atmStrikeEntry.OnChanged = func(string) { updatePreview() }
 	expirationEntry.OnChanged = func(string) { updatePreview() }
 	contractsEntry.OnChanged = func(string) { updatePreview() }
@@ -1217,6 +1261,7 @@ func showStraddleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -1245,8 +1290,8 @@ func showStraddleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 			legs := storage.BuildStraddle(atmStrike, expiration, contracts, callPremium, putPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyStraddle, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyStraddle, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -1256,8 +1301,10 @@ func showStraddleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -1312,6 +1359,8 @@ func showStrangleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 	breakevenLabel := widget.NewLabel("Breakevens: $0.00 / $0.00")
 	dteLabel := widget.NewLabel("DTE: 0")
 
+	preview := &riskGraphPreview{}
+
 	updatePreview := func() {
 		callStrike, _ := strconv.ParseFloat(callStrikeEntry.Text, 64)
 		putStrike, _ := strconv.ParseFloat(putStrikeEntry.Text, 64)
@@ -1328,18 +1377,21 @@ func showStrangleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 			legs := storage.BuildStrangle(callStrike, putStrike, expiration, contracts, callPremium, putPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			_, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyStrangle, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyStrangle, legs)
+			underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+			payoff := builderPayoff(legs, underlying)
+			preview.set(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			netDebitLabel.SetText(fmt.Sprintf("Net Debit: $%.2f", netDebit))
-			maxProfitLabel.SetText("Max Profit: Unlimited")
-			maxLossLabel.SetText(fmt.Sprintf("Max Loss: $%.2f", maxLoss))
+			maxProfitLabel.SetText(formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit))
+			maxLossLabel.SetText(formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 			breakevenLabel.SetText(fmt.Sprintf("Breakevens: $%.2f / $%.2f", lowerBE, upperBE))
 			dteLabel.SetText(fmt.Sprintf("DTE: %d", dte))
 		}
 	}
 
+	underlyingEntry.OnChanged = func(string) { updatePreview() }
 	callStrikeEntry.OnChanged = func(string) { updatePreview() }
 	putStrikeEntry.OnChanged = func(string) { updatePreview() }
 	expirationEntry.OnChanged = func(string) { updatePreview() }
@@ -1388,6 +1440,7 @@ func showStrangleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 		maxLossLabel,
 		breakevenLabel,
 		dteLabel,
+		preview.button(parentWindow),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -1417,8 +1470,8 @@ func showStrangleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 			legs := storage.BuildStrangle(callStrike, putStrike, expiration, contracts, callPremium, putPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
-			maxProfit, maxLoss, _ := storage.CalculateMaxProfitLoss(storage.StrategyStrangle, legs)
-			lowerBE, upperBE, _ := storage.CalculateBreakevens(storage.StrategyStrangle, legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -1428,8 +1481,10 @@ func showStrangleBuilder(parentWindow fyne.Window, onComplete func(*StrategyBuil
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             maxProfit,
-				MaxLoss:               maxLoss,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
 				BreakevenLower:        lowerBE,
 				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
@@ -1487,6 +1542,19 @@ func showCalendarSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 	netDebitLabel := widget.NewLabel("Net Debit: $0.00")
 	infoLabel := widget.NewLabel("Calendar spreads profit from time decay.")
 
+	currentLegs := func() ([]storage.OptionLeg, float64) {
+		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+		strike, _ := strconv.ParseFloat(strikeEntry.Text, 64)
+		contracts, _ := strconv.Atoi(contractsEntry.Text)
+		nearPremium, _ := strconv.ParseFloat(nearPremiumEntry.Text, 64)
+		farPremium, _ := strconv.ParseFloat(farPremiumEntry.Text, 64)
+		if strike <= 0 || contracts <= 0 {
+			return nil, underlying
+		}
+		return storage.BuildCalendarSpread(optionType, strike, nearExpirationEntry.Text, farExpirationEntry.Text,
+			contracts, nearPremium, farPremium), underlying
+	}
+
 	form := container.NewVBox(
 		widget.NewLabel(fmt.Sprintf("=== %s Builder ===", storage.GetStrategyDisplayName(strategyType))),
 		widget.NewSeparator(),
@@ -1525,6 +1593,7 @@ func showCalendarSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 		widget.NewLabel("=== Preview ==="),
 		netDebitLabel,
 		infoLabel,
+		newRiskGraphButton(parentWindow, currentLegs),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -1554,6 +1623,8 @@ func showCalendarSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 			legs := storage.BuildCalendarSpread(optionType, strike, nearExpiration, farExpiration, contracts, nearPremium, farPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(nearExpiration)
 
 			result := &StrategyBuilderResult{
@@ -1563,8 +1634,12 @@ func showCalendarSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 				PrimaryExpirationDate: nearExpiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             0, // Complex calculation
-				MaxLoss:               netDebit,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
+				BreakevenLower:        lowerBE,
+				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
 				RollThresholdDTE:      rollThreshold,
 				TimeExitMode:          timeExitModeSelect.Selected,
@@ -1622,6 +1697,20 @@ func showDiagonalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 
 	netDebitLabel := widget.NewLabel("Net Debit: $0.00")
 
+	currentLegs := func() ([]storage.OptionLeg, float64) {
+		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+		nearStrike, _ := strconv.ParseFloat(nearStrikeEntry.Text, 64)
+		farStrike, _ := strconv.ParseFloat(farStrikeEntry.Text, 64)
+		contracts, _ := strconv.Atoi(contractsEntry.Text)
+		nearPremium, _ := strconv.ParseFloat(nearPremiumEntry.Text, 64)
+		farPremium, _ := strconv.ParseFloat(farPremiumEntry.Text, 64)
+		if nearStrike <= 0 || farStrike <= 0 || contracts <= 0 {
+			return nil, underlying
+		}
+		return storage.BuildDiagonalSpread(optionType, nearStrike, farStrike, nearExpirationEntry.Text, farExpirationEntry.Text,
+			contracts, nearPremium, farPremium), underlying
+	}
+
 	form := container.NewVBox(
 		widget.NewLabel(fmt.Sprintf("=== %s Builder ===", storage.GetStrategyDisplayName(strategyType))),
 		widget.NewSeparator(),
@@ -1662,6 +1751,7 @@ func showDiagonalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 		widget.NewSeparator(),
 		widget.NewLabel("=== Preview ==="),
 		netDebitLabel,
+		newRiskGraphButton(parentWindow, currentLegs),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -1692,6 +1782,8 @@ func showDiagonalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 			legs := storage.BuildDiagonalSpread(optionType, nearStrike, farStrike, nearExpiration, farExpiration, contracts, nearPremium, farPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(nearExpiration)
 
 			result := &StrategyBuilderResult{
@@ -1701,8 +1793,12 @@ func showDiagonalSpreadBuilder(strategyType string, parentWindow fyne.Window, on
 				PrimaryExpirationDate: nearExpiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             0, // Complex
-				MaxLoss:               netDebit,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
+				BreakevenLower:        lowerBE,
+				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
 				RollThresholdDTE:      rollThreshold,
 				TimeExitMode:          timeExitModeSelect.Selected,
@@ -1764,6 +1860,22 @@ func showButterflyBuilder(strategyType string, parentWindow fyne.Window, onCompl
 	netDebitLabel := widget.NewLabel("Net Debit: $0.00")
 	infoLabel := widget.NewLabel("1-2-1 ratio: Buy 1 low, Sell 2 middle, Buy 1 high")
 
+	currentLegs := func() ([]storage.OptionLeg, float64) {
+		underlying, _ := strconv.ParseFloat(underlyingEntry.Text, 64)
+		lowerStrike, _ := strconv.ParseFloat(lowerStrikeEntry.Text, 64)
+		middleStrike, _ := strconv.ParseFloat(middleStrikeEntry.Text, 64)
+		upperStrike, _ := strconv.ParseFloat(upperStrikeEntry.Text, 64)
+		contracts, _ := strconv.Atoi(contractsEntry.Text)
+		lowerPremium, _ := strconv.ParseFloat(lowerPremiumEntry.Text, 64)
+		middlePremium, _ := strconv.ParseFloat(middlePremiumEntry.Text, 64)
+		upperPremium, _ := strconv.ParseFloat(upperPremiumEntry.Text, 64)
+		if lowerStrike <= 0 || middleStrike <= 0 || upperStrike <= 0 || contracts <= 0 {
+			return nil, underlying
+		}
+		return storage.BuildButterfly(optionType, lowerStrike, middleStrike, upperStrike, expirationEntry.Text, contracts,
+			lowerPremium, middlePremium, upperPremium), underlying
+	}
+
 	form := container.NewVBox(
 		widget.NewLabel(fmt.Sprintf("=== %s Builder ===", storage.GetStrategyDisplayName(strategyType))),
 		widget.NewSeparator(),
@@ -1808,6 +1920,7 @@ func showButterflyBuilder(strategyType string, parentWindow fyne.Window, onCompl
 		widget.NewLabel("=== Preview ==="),
 		netDebitLabel,
 		infoLabel,
+		newRiskGraphButton(parentWindow, currentLegs),
 	)
 
 	d := dialog.NewCustomConfirm(
@@ -1840,6 +1953,8 @@ func showButterflyBuilder(strategyType string, parentWindow fyne.Window, onCompl
 				lowerPremium, middlePremium, upperPremium)
 
 			netDebit := storage.CalculateNetDebit(legs)
+			payoff := builderPayoff(legs, underlying)
+			lowerBE, upperBE := payoff.Range()
 			dte := calculateDTE(expiration)
 
 			result := &StrategyBuilderResult{
@@ -1849,8 +1964,12 @@ func showButterflyBuilder(strategyType string, parentWindow fyne.Window, onCompl
 				PrimaryExpirationDate: expiration,
 				DTE:                   dte,
 				NetDebit:              netDebit,
-				MaxProfit:             0, // Complex
-				MaxLoss:               netDebit,
+				MaxProfit:             payoff.MaxProfit,
+				MaxLoss:               payoff.MaxLoss,
+				MaxProfitUnlimited:    payoff.UnlimitedProfit,
+				MaxLossUnlimited:      payoff.UnlimitedLoss,
+				BreakevenLower:        lowerBE,
+				BreakevenUpper:        upperBE,
 				UnderlyingAtEntry:     underlying,
 				RollThresholdDTE:      rollThreshold,
 				TimeExitMode:          timeExitModeSelect.Selected,
@@ -1891,6 +2010,24 @@ func calculateDTE(expirationDate string) int {
 	return days
 }
 
+// builderPayoff evaluates the legs' payoff for a builder; if the legs
+// cannot be valued yet (e.g. incomplete inputs) the figures stay zero
+func builderPayoff(legs []storage.OptionLeg, underlying float64) *options.Payoff {
+	payoff, err := storage.CalculatePayoff(legs, underlying)
+	if err != nil {
+		return &options.Payoff{NetDebit: storage.CalculateNetDebit(legs)}
+	}
+	return payoff
+}
+
+// formatPayoffLimit formats a max profit or max loss, which may be unlimited
+func formatPayoffLimit(label string, value float64, unlimited bool) string {
+	if unlimited {
+		return label + ": Unlimited"
+	}
+	return fmt.Sprintf("%s: $%.2f", label, value)
+}
+
 // FormatLegsForDisplay formats legs array for display in UI
 func FormatLegsForDisplay(legs []storage.OptionLeg) string {
 	if len(legs) == 0 {
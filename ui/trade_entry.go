@@ -7,7 +7,10 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/yourusername/trading-engine/internal/options"
 )
 
 func buildTradeEntryScreen(state *AppState) fyne.CanvasObject {
@@ -133,6 +136,12 @@ func buildTradeEntryScreen(state *AppState) fyne.CanvasObject {
 
 	// Add options information if this is an options trade
 	if state.currentSession.InstrumentType == "option" && state.currentSession.OptionsStrategy != "" {
+		// Unlimited profit or loss is not stored, so re-evaluate the legs
+		payoff := &options.Payoff{MaxProfit: state.currentSession.MaxProfit, MaxLoss: state.currentSession.MaxLoss}
+		if legs, err := DeserializeLegs(state.currentSession.LegsJSON); err == nil && len(legs) > 0 {
+			payoff = builderPayoff(legs, state.currentSession.UnderlyingAtEntry)
+		}
+
 		summaryStr += fmt.Sprintf("\n\n📊 Options Details:\n"+
 			"Strategy: %s\n"+
 			"Expiration: %s (%d DTE)\n"+
@@ -140,8 +149,8 @@ func buildTradeEntryScreen(state *AppState) fyne.CanvasObject {
 			"Roll at: %d DTE\n"+
 			"Time Exit Mode: %s\n"+
 			"Net Debit: $%.2f\n"+
-			"Max Profit: $%.2f\n"+
-			"Max Loss: $%.2f",
+			"%s\n"+
+			"%s",
 			state.currentSession.OptionsStrategy,
 			state.currentSession.PrimaryExpirationDate,
 			state.currentSession.DTE,
@@ -149,8 +158,8 @@ func buildTradeEntryScreen(state *AppState) fyne.CanvasObject {
 			state.currentSession.RollThresholdDTE,
 			state.currentSession.TimeExitMode,
 			state.currentSession.NetDebit,
-			state.currentSession.MaxProfit,
-			state.currentSession.MaxLoss)
+			formatPayoffLimit("Max Profit", payoff.MaxProfit, payoff.UnlimitedProfit),
+			formatPayoffLimit("Max Loss", payoff.MaxLoss, payoff.UnlimitedLoss))
 
 		// Add breakevens if available
 		if state.currentSession.BreakevenLower > 0 || state.currentSession.BreakevenUpper > 0 {
@@ -179,6 +188,19 @@ func buildTradeEntryScreen(state *AppState) fyne.CanvasObject {
 	summaryText := widget.NewLabel(summaryStr)
 	summaryText.Wrapping = fyne.TextWrapWord
 
+	// Risk graph of the option legs, with the sizing stop marked
+	riskGraphBtn := widget.NewButton("📈 Show Risk Graph", func() {
+		legs, err := DeserializeLegs(state.currentSession.LegsJSON)
+		if err != nil {
+			dialog.ShowError(err, state.window)
+			return
+		}
+		showRiskGraphDialog(legs, state.currentSession.UnderlyingAtEntry, state.currentSession.SizingInitialStop, state.window)
+	})
+	if state.currentSession.InstrumentType != "option" || state.currentSession.LegsJSON == "" {
+		riskGraphBtn.Hide()
+	}
+
 	// 5-Gate Status display
 	gatesLabel := widget.NewLabelWithStyle("🚦 5-Gate Status Check", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 
@@ -393,6 +415,7 @@ func buildTradeEntryScreen(state *AppState) fyne.CanvasObject {
 		widget.NewSeparator(),
 		summaryLabel,
 		container.NewPadded(summaryText),
+		riskGraphBtn,
 		widget.NewSeparator(),
 		banner,
 		widget.NewSeparator(),